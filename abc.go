@@ -8,6 +8,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,8 +18,10 @@ import (
 	"github.com/abetterchoice/go-sdk/internal"
 	"github.com/abetterchoice/go-sdk/internal/cache"
 	"github.com/abetterchoice/go-sdk/internal/client"
-	mp "github.com/abetterchoice/go-sdk/plugin/metrics"
-	_ "github.com/abetterchoice/metrics-pubsub" // metrics-pubsub TODO
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/abetterchoice/go-sdk/internal/random"
+	"github.com/abetterchoice/go-sdk/internal/tracing"
+	"github.com/abetterchoice/go-sdk/plugin/log"
 	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
 	"github.com/pkg/errors"
 )
@@ -32,7 +37,7 @@ import (
 func Init(ctx context.Context, projectIDList []string, opts ...InitOption) (err error) {
 	defer func(start time.Time) {
 		manualInitEvent(projectIDList, time.Since(start), err)
-		if err != nil {
+		if err != nil && !isPartialInitSuccess(err) {
 			Release()
 		}
 	}(time.Now())
@@ -50,34 +55,133 @@ func Init(ctx context.Context, projectIDList []string, opts ...InitOption) (err
 				return
 			}
 		}
+		err = validateProxies(c)
+		if err != nil {
+			return
+		}
 		internal.C = c
+		if c.IsSimulation {
+			random.Seed(c.SimulationSeed)
+		}
+		tracing.SetTracerProvider(c.TracerProvider)
+		setInvokePathDepth(c)
+		initEndpointGroups(c)
 		if !c.IsCustomCacheClient {
-			client.RegisterCacheClient(client.NewTABCacheClient(client.WithEnvType(c.EnvType)))
+			client.RegisterCacheClient(client.NewTABCacheClient(cacheClientOptions(c)...))
 		}
+		registerRelayClient(c)
+		registerHandoffClient(c)
 		if !c.IsCustomDMPClient {
-			client.RegisterDMPClient(client.NewDMPClient(client.WithEnvTypeOption(c.EnvType)))
+			client.RegisterDMPClient(client.NewDMPClient(dmpClientOptions(c)...))
 		}
 		initExposureConsumer()
+		initLossReporter()
+		initRedelivery()
+		initRevisionReporter()
+		initGuardrail()
+		initAssignmentExporter()
+		initSRMDetector()
+		initUsageReporter()
+		initUnusedVariantReporter()
 		err = initCustomMetricsPlugin(ctx, c)
 		if err != nil {
 			return
 		}
-		err = cache.InitLocalCache(ctx, projectIDList)
-		if err != nil {
-			return
+		var initErr *InitError
+		if c.IsPartialInit {
+			initErr = newInitError(projectIDList, cache.InitLocalCacheIsolated(ctx, projectIDList))
+			if initErr != nil && len(initErr.Loaded) == 0 {
+				err = initErr
+				return
+			}
+		} else {
+			err = cache.InitLocalCache(ctx, projectIDList)
+			if err != nil {
+				return
+			}
 		}
 		err = initMetricsPlugin(ctx, c)
 		if err != nil {
 			return
 		}
+		replaySpool(takeRestoredSpool())
+		if initErr != nil {
+			err = initErr
+		}
 		return
 	})
 	return err
 }
 
+// InitError The error returned by Init when partial init is enabled and some projectIDs fail to load.
+// The projectIDs in Loaded can serve immediately, the projectIDs in Errors keep retrying in the background
+type InitError struct {
+	// Successfully loaded projectID
+	Loaded []string
+	// key is the failed projectID, value is the error
+	Errors map[string]error
+}
+
+func newInitError(projectIDList []string, errs map[string]error) *InitError {
+	if len(errs) == 0 {
+		return nil
+	}
+	result := &InitError{Errors: errs}
+	for _, projectID := range projectIDList {
+		if _, ok := errs[projectID]; !ok {
+			result.Loaded = append(result.Loaded, projectID)
+		}
+	}
+	return result
+}
+
+// Error implements error
+func (e *InitError) Error() string {
+	failed := make([]string, 0, len(e.Errors))
+	for projectID := range e.Errors {
+		failed = append(failed, projectID)
+	}
+	sort.Strings(failed)
+	var builder strings.Builder
+	for i, projectID := range failed {
+		if i > 0 {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(fmt.Sprintf("[projectID=%v]%v", projectID, e.Errors[projectID]))
+	}
+	return fmt.Sprintf("%d of %d projects init fail:%s", len(e.Errors), len(e.Errors)+len(e.Loaded),
+		builder.String())
+}
+
+// isPartialInitSuccess Whether err is the InitError with at least one projectID loaded, the SDK is not released
+func isPartialInitSuccess(err error) bool {
+	initErr, ok := err.(*InitError)
+	return ok && len(initErr.Loaded) != 0
+}
+
 // Release local cache, concurrency is not safe
 func Release() {
 	cache.Release()
+	experiment.ResetPrimedDMP()
+	experiment.ResetMemo()
+	resetHandoff()
+	resetRedelivery()
+	internal.ResetCredentials()
+	internal.ResetTokens()
+	internal.ResetClock()
+	internal.ResetProjectOptions()
+	internal.ResetRecentErrors()
+	internal.ResetLoss()
+	internal.ResetUsage()
+	internal.ResetGuardrail()
+	internal.ResetRateLimits()
+	internal.ResetBlobs()
+	resetAliasStore()
+	resetSRM()
+	resetVariants()
+	random.Reset()
+	tracing.SetTracerProvider(nil)
+	env.SetInvokePathDepth(env.DefaultInvokePathDepth)
 	once = sync.Once{}
 	internal.C = &internal.GlobalConfig{}
 }
@@ -86,52 +190,46 @@ var (
 	once sync.Once
 )
 
-// initCustomMetricsPlugin TODO
-// initialize the registered monitoring and reporting plug-ins one by one
-func initCustomMetricsPlugin(ctx context.Context, config *internal.GlobalConfig) error {
-	// traverse all registered monitoring and reporting plug-ins
-	return mp.WalkFunc(func(name string, client mp.Client) error {
-		initConfig, ok := config.MetricsPluginInitConfig[name]
-		if !ok {
-			return nil
-		}
-		// If the user explicitly passes in the initialization parameters,
-		// the user-defined passed parameters will be used directly.
-		err := client.Init(ctx, initConfig)
-		if err != nil {
-			return errors.Wrapf(err, "init metrics plugin [%v]", name)
-		}
-		return nil
-	})
+// cacheClientOptions The options of the default cache service client
+func cacheClientOptions(c *internal.GlobalConfig) []client.Option {
+	opts := []client.Option{client.WithEnvType(c.EnvType)}
+	if c.HTTPClient != nil {
+		opts = append(opts, client.WithHTTPClient(c.HTTPClient))
+	}
+	if proxy, _ := internal.ConfigProxy(c); proxy != nil { // validated by Init
+		opts = append(opts, client.WithProxy(proxy))
+	}
+	if !c.Egress.IsEmpty() {
+		opts = append(opts, client.WithDialer(c.Egress.DialContext))
+	}
+	middlewares := transportMiddlewares(c)
+	if c.ConfigEndpoints != nil { // innermost, the retries to the other endpoints are below the custom middlewares
+		middlewares = append(middlewares, c.ConfigEndpoints.RoundTripper)
+	}
+	return append(opts, client.WithMiddleware(middlewares...))
 }
 
-// initMetricsPlugin TODO
-// Initialize monitoring plugins provided by remote configuration.
-func initMetricsPlugin(ctx context.Context, config *internal.GlobalConfig) error {
-	return mp.WalkFunc(func(name string, client mp.Client) error {
-		// traverse all projectIDs and initialize related metrics plugin
-		// different projectIDs may have the same metrics plugin, and the same plugin may be initialized multiple times.
-		// it is necessary to ensure that the monitoring and reporting initConfig of projectIDList is consistent.
-		// if inconsistent, the initConfig will be randomly initialized.
-		for _, projectID := range internal.C.ProjectIDList {
-			application := cache.GetApplication(projectID)
-			if application == nil {
-				continue
-			}
-			for pluginName, initConfig := range application.MetricsPluginInitConfigIndex {
-				if name != pluginName {
-					continue
-				}
-				_, ok := config.MetricsPluginInitConfig[pluginName]
-				if ok { // The custom plug-in has been initialized and does not need to be initialized again.
-					continue
-				}
-				// Initialize the client according to the initialization parameters of the remote configuration
-				return client.Init(ctx, initConfig)
-			}
-		}
-		return nil
-	})
+// dmpClientOptions The options of the default dmp client
+func dmpClientOptions(c *internal.GlobalConfig) []client.DMPOption {
+	opts := []client.DMPOption{client.WithEnvTypeOption(c.EnvType)}
+	if c.HTTPClient != nil {
+		opts = append(opts, client.WithDMPHTTPClient(c.HTTPClient))
+	}
+	if proxy, _ := internal.ConfigProxy(c); proxy != nil {
+		opts = append(opts, client.WithDMPProxy(proxy))
+	}
+	if !c.Egress.IsEmpty() {
+		opts = append(opts, client.WithDMPDialer(c.Egress.DialContext))
+	}
+	return append(opts, client.WithDMPMiddleware(transportMiddlewares(c)...))
+}
+
+func transportMiddlewares(c *internal.GlobalConfig) []client.Middleware {
+	result := make([]client.Middleware, 0, len(c.TransportMiddlewares))
+	for _, middleware := range c.TransportMiddlewares {
+		result = append(result, middleware)
+	}
+	return result
 }
 
 // InitOption Initialization Option is used to customize and control more fine-grained configurations,
@@ -139,21 +237,6 @@ func initMetricsPlugin(ctx context.Context, config *internal.GlobalConfig) error
 // socket5 proxy, etc.
 type InitOption func(config *internal.GlobalConfig) error
 
-// WithRegisterMetricsPlugin register monitoring and reporting plug-in
-func WithRegisterMetricsPlugin(client mp.Client, initConfig *protoccacheserver.MetricsInitConfig) InitOption {
-	return func(config *internal.GlobalConfig) error {
-		if client == nil {
-			return fmt.Errorf("client should not be nil")
-		}
-		mp.RegisterClient(client)
-		// it is used for subsequent initialization.
-		// some initialization may rely on cached data,
-		// so the monitoring and reporting component is initialized after the cache is successfully pulled.
-		config.MetricsPluginInitConfig[client.Name()] = initConfig
-		return nil
-	}
-}
-
 // WithRegisterCacheClient register the background cache service interface implementation,
 // which can replace the default TAB background cache service
 func WithRegisterCacheClient(c client.Client) InitOption {
@@ -161,7 +244,7 @@ func WithRegisterCacheClient(c client.Client) InitOption {
 		if c == nil {
 			return errors.Errorf("client is required")
 		}
-		client.CacheClient = c
+		client.RegisterCacheClient(c)
 		config.IsCustomCacheClient = true
 		return nil
 	}
@@ -188,6 +271,52 @@ func WithSecretKey(secretKey string) InitOption {
 	}
 }
 
+// WithGuardrail set the CPU and memory budgets of the reporting pipeline, zero disables the budget.
+// cpuBudget is the busy fraction of one CPU that the exposure consumers may take, memoryBudget is the heap bytes of
+// the process, the SDK cannot attribute the heap to its own goroutines. When a budget is exceeded, the sampling
+// intervals are doubled and the queues are halved every second, up to 64 times, and restored step by step once the
+// usage falls below half of the budgets. The shed exposures are counted into the loss with the reason shed
+func WithGuardrail(cpuBudget float64, memoryBudget uint64) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if cpuBudget < 0 {
+			return errors.Errorf("invalid cpuBudget:%v", cpuBudget)
+		}
+		config.GuardrailCPUBudget = cpuBudget
+		config.GuardrailMemoryBudget = memoryBudget
+		return nil
+	}
+}
+
+// WithAdaptiveFlush Tune the experiment exposure flushes by the latency of the metrics plugin. The consumers merge
+// up to maxBatchSize pending exposures of a project into one plugin call and limit the concurrent calls, the batch
+// size grows while the p99 latency of the recent flushes is within targetP99, then the concurrency grows, and both
+// are halved once the p99 exceeds it (AIMD). The state is reported by GetExposureStats
+func WithAdaptiveFlush(targetP99 time.Duration, maxBatchSize int) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if targetP99 <= 0 {
+			return errors.Errorf("invalid targetP99:%v", targetP99)
+		}
+		if maxBatchSize < 1 {
+			return errors.Errorf("invalid maxBatchSize:%v", maxBatchSize)
+		}
+		config.AdaptiveFlushTarget = targetP99
+		config.AdaptiveFlushMaxBatch = maxBatchSize
+		return nil
+	}
+}
+
+// WithSimulation Run in the deterministic simulation mode for the integration tests and the traffic replays.
+// The sampling decisions and any other randomness of the SDK are driven by a PRNG seeded by seed, and the exposures
+// of all units are flushed by a single consumer in the enqueueing order, so that the same sequence of calls with the
+// same seed reports the same exposures and events. The assignments are deterministic by the hashing regardless
+func WithSimulation(seed int64) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.IsSimulation = true
+		config.SimulationSeed = seed
+		return nil
+	}
+}
+
 // WithEnvType set environment, default official environment
 func WithEnvType(envType env.Type) InitOption {
 	return func(config *internal.GlobalConfig) error {
@@ -204,6 +333,26 @@ func WithDisableReport(isDisable bool) InitOption {
 	}
 }
 
+// WithDisableMessagePool disable the reuse of the exposure and monitor event messages. The messages are only reused
+// for the plugins declaring that they do not retain them, see metrics.MessageRetainer, disable it when debugging
+// a plugin that is suspected of retaining the messages
+func WithDisableMessagePool(isDisable bool) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.IsDisableMessagePool = isDisable
+		return nil
+	}
+}
+
+// WithEventID enable the unique event_id in the extended details of each exposure and monitor event, the expanded
+// data of the remote config exposures included. The plugins may deliver the same message more than once after a
+// retry, the downstream drops the duplicates by the event_id to get exactly-once analytics
+func WithEventID(isEnable bool) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.IsEnableEventID = isEnable
+		return nil
+	}
+}
+
 // WithRegionCode set region code to support different regions delivering different configurations
 func WithRegionCode(regionCode string) InitOption {
 	return func(config *internal.GlobalConfig) error {
@@ -212,6 +361,185 @@ func WithRegionCode(regionCode string) InitOption {
 	}
 }
 
+// WithHTTPClient set the http client of the control-plane requests, such as fetching configuration and dmp,
+// to customize timeout, proxy, etc. The default client times out in 10s. Not effective for custom clients
+// registered by WithRegisterCacheClient and WithRegisterDMPClient
+func WithHTTPClient(httpClient *http.Client) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if httpClient == nil {
+			return errors.Errorf("httpClient is required")
+		}
+		config.HTTPClient = httpClient
+		return nil
+	}
+}
+
+// WithTransportMiddleware wrap the transport of the control-plane requests, such as adding corporate proxies,
+// retries, request signing and distributed tracing headers. The first middleware is the outermost,
+// can be called multiple times. The http client passed in WithHTTPClient is not modified
+func WithTransportMiddleware(middlewares ...func(next http.RoundTripper) http.RoundTripper) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.TransportMiddlewares = append(config.TransportMiddlewares, middlewares...)
+		return nil
+	}
+}
+
+// WithPartialInit allow partial success of Init. If enabled, each projectID is loaded independently,
+// Init returns *InitError when some projectIDs fail, the successfully loaded projectIDs can serve immediately,
+// and the failed projectIDs keep retrying in the background. The SDK is released only if all projectIDs fail.
+func WithPartialInit(enable bool) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.IsPartialInit = enable
+		return nil
+	}
+}
+
+// WithOnBackgroundError set the callback of background errors, such as local cache refresh failure,
+// and panic of the refresh or reporting goroutine, which is recovered and restarted with an exponential backoff.
+// The callback is invoked synchronously in the background goroutine and should return quickly.
+func WithOnBackgroundError(handler func(task string, err error)) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.OnBackgroundError = handler
+		return nil
+	}
+}
+
+// Scheduler The scheduler of the background tasks of the SDK, see WithScheduler
+type Scheduler = internal.Scheduler
+
+// WithScheduler set the scheduler of the background tasks, such as the local cache refresher and the exposure
+// consumers, for the hosts without threads or timers, such as the envoy wasm filters. The default runs each task in
+// its own goroutine, or rejects all tasks if built with the build tag abc_wasm, see README
+func WithScheduler(scheduler Scheduler) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.Scheduler = scheduler
+		return nil
+	}
+}
+
+// WithOnError set the callback of the errors that lose data silently otherwise, such as the exposure of a projectID
+// whose local cache is not loaded, the full reporting queue and the reporting failure of the metrics plugin.
+// The callback may be invoked in the calling goroutine of the API and should return quickly.
+func WithOnError(handler func(reason ErrorReason, projectID string, err error)) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.OnError = handler
+		return nil
+	}
+}
+
+// WithOnWarning set the callback of the misconfiguration that does not fail the API,
+// such as the missing exposure reporting config and the unregistered metrics plugin.
+// The callback may be invoked for every exposure and should return quickly.
+func WithOnWarning(handler func(reason ErrorReason, projectID string, err error)) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.OnWarning = handler
+		return nil
+	}
+}
+
+// WithOnConfigApplied set the callback of the configuration revision applied to the local cache, including the first
+// load, with the keys of the changed layers and feature flags, such as invalidating the caches of the dependent
+// systems keyed off the experiment parameters, see NewConfigWebhook.
+// The callback is invoked synchronously in the refresh goroutine after the revision is served
+// and should return quickly.
+func WithOnConfigApplied(handler func(change *ConfigChange)) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.OnConfigApplied = handler
+		return nil
+	}
+}
+
+// WithLossReportInterval set the interval of the exposure loss report event. The exposures discarded by the sampling,
+// the full reporting queue and the reporting failure are counted by projectID, and the increment is reported
+// periodically as a monitor event named loss, so that the known undercounting can be corrected in the analysis.
+// The default interval is 1 minute, negative disables the report event, the counting is not affected.
+func WithLossReportInterval(interval time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.LossReportInterval = interval
+		return nil
+	}
+}
+
+// WithInvokePathDepth set the number of stack frames captured as the call site of the monitor events,
+// the default is 1 and the maximum is 8, zero or negative disables the capture.
+// The frames are resolved once per call site and cached, the cost of the capture is mainly the stack walk.
+func WithInvokePathDepth(depth int) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.IsDisableInvokePath = depth <= 0
+		config.InvokePathDepth = depth
+		return nil
+	}
+}
+
+func setInvokePathDepth(c *internal.GlobalConfig) {
+	switch {
+	case c.IsDisableInvokePath:
+		env.SetInvokePathDepth(0)
+	case c.InvokePathDepth > 0:
+		env.SetInvokePathDepth(c.InvokePathDepth)
+	default:
+		env.SetInvokePathDepth(env.DefaultInvokePathDepth)
+	}
+}
+
+// WithSlowOpThreshold set the latency threshold of the slow operation detection, the evaluations and exposure flushes
+// exceeding it emit a slow_op monitor event with the latency breakdown, such as the cache lookup, rule evaluation,
+// hashing and plugin send, to localize latency regressions. Zero disables the detection, default disabled.
+func WithSlowOpThreshold(threshold time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.SlowOpThreshold = threshold
+		return nil
+	}
+}
+
+// WithConfigRevisionReportInterval set the interval of the config revision report event. Each replica periodically
+// reports the revision hash and the version of the configuration served by each projectID as a monitor event named
+// config_revision, so that the replicas serving a configuration far behind the others can be alerted.
+// Zero disables the report event, default disabled, see GetConfigRevision.
+func WithConfigRevisionReportInterval(interval time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.ConfigRevisionReportInterval = interval
+		return nil
+	}
+}
+
+// WithRemoteConfigCacheSize set the maximum number of the cached results of the feature flags and remote configs
+// whose conditions only depend on the attributes, such as the flags targeting the country and the app version without
+// a percentage rollout. The repeated evaluations of the same attribute values skip the rule evaluation, the cache of
+// each configuration is dropped when the configuration is refreshed. Zero uses the default 10000, negative disables it
+func WithRemoteConfigCacheSize(size int) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.RemoteConfigCacheSize = size
+		return nil
+	}
+}
+
+// WithEvaluationMemo set the process-level memo of the experiment evaluations, a bounded LRU of at most size
+// evaluations keyed by the unit and the layer, so that the repeated evaluations of the same active users across the
+// requests are served without being evaluated for the ttl. Only the layers whose targeting only depends on the unit
+// and the attributes are memoized, the attributes the layer targets are part of the key, the layers with dmp tags,
+// time-window tags or holdouts are always evaluated. The refresh of the configuration to a new revision invalidates
+// the memo. Default disabled
+func WithEvaluationMemo(size int, ttl time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if size <= 0 || ttl <= 0 {
+			return errors.Errorf("size and ttl must be positive")
+		}
+		config.EvaluationMemoSize, config.EvaluationMemoTTL = size, ttl
+		return nil
+	}
+}
+
+// TaskInfo The snapshot of an SDK-owned background task, see Tasks
+type TaskInfo = internal.TaskInfo
+
+// Tasks lists all SDK-owned background tasks, such as the local cache refreshers and the exposure consumers,
+// with their start time, last activity time and restart count, sorted by start order.
+// A task whose LastActive stops moving is stuck, and tasks that are still listed after Release are leaked.
+func Tasks() []*TaskInfo {
+	return internal.Tasks()
+}
+
 // GetGlobalConfig returns the global configuration object,
 // including the projectID passed in Init, whether to enable exposure reporting, etc., deep copy
 // modifying the returned globalConfig will not update the global configuration, it is only used as a data query
@@ -236,3 +564,42 @@ func deepCopyGlobalConfig(source *internal.GlobalConfig) (*internal.GlobalConfig
 func RegisterProjectIDs(ctx context.Context, projectIDList []string) error {
 	return cache.InitLocalCache(ctx, projectIDList)
 }
+
+// credentialFields The names of the replaced credential fields, the values are never logged
+func credentialFields(credentials *Credentials) string {
+	var fields []string
+	if len(credentials.SecretKey) > 0 {
+		fields = append(fields, "secretKey")
+	}
+	if len(credentials.EventToken) > 0 {
+		fields = append(fields, "eventToken")
+	}
+	return strings.Join(fields, ",")
+}
+
+// Credentials The credentials of a projectID, including the secretKey used by the control plane and
+// the token used by the reporting plugins. Empty fields keep using the Init configuration.
+type Credentials = internal.Credentials
+
+// SetCredentials atomically rotates the credentials of the specified projectID,
+// used for scheduled key rotation without restarting the service. The empty fields keep the credentials rotated
+// before, so that the secretKey and the eventToken can be rotated separately. An invalid secretKey is rejected.
+// The next configuration refresh, dmp request and exposure reporting of the projectID use the new credentials,
+// in-flight requests are not affected. Concurrent and safe.
+func SetCredentials(projectID string, credentials *Credentials) error {
+	if len(projectID) == 0 {
+		return errors.Errorf("projectID is required")
+	}
+	if credentials == nil || (len(credentials.SecretKey) == 0 && len(credentials.EventToken) == 0) {
+		return errors.Errorf("credentials is required")
+	}
+	if len(credentials.SecretKey) != 0 {
+		if err := client.ValidateSecretKey(credentials.SecretKey); err != nil {
+			return errors.Wrap(err, "secretKey")
+		}
+	}
+	internal.SetCredentials(projectID, credentials)
+	log.Infof("[projectID=%v]credentials rotated", projectID)
+	internal.RecordAudit(1, internal.AuditActionRotateCredentials, projectID, "", credentialFields(credentials))
+	return nil
+}
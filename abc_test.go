@@ -3,13 +3,18 @@ package abc
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/abetterchoice/go-sdk/env"
 	"github.com/abetterchoice/go-sdk/internal"
 	"github.com/abetterchoice/go-sdk/internal/client"
 	"github.com/abetterchoice/go-sdk/testdata"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 )
 
 var (
@@ -193,3 +198,144 @@ func TestInit(t *testing.T) {
 		})
 	}
 }
+
+// testSecretKey A secretKey of the valid format, whose accessKey is ak
+const testSecretKey = "header.eyJ0b2tlbk5hbWUiOiJhayJ9.signature"
+
+func TestSetCredentials(t *testing.T) {
+	defer internal.ResetCredentials()
+	type args struct {
+		projectID   string
+		credentials *Credentials
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantErr        bool
+		wantSecretKey  string
+		wantEventToken string
+	}{
+		{
+			name:    "projectID is required",
+			args:    args{projectID: "", credentials: &Credentials{SecretKey: "a.b.c"}},
+			wantErr: true,
+		},
+		{
+			name:    "credentials is required",
+			args:    args{projectID: projectID, credentials: &Credentials{}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid secretKey",
+			args:    args{projectID: projectID, credentials: &Credentials{SecretKey: "a.b.c"}},
+			wantErr: true,
+		},
+		{
+			name:           "rotate secretKey",
+			args:           args{projectID: projectID, credentials: &Credentials{SecretKey: testSecretKey}},
+			wantSecretKey:  testSecretKey,
+			wantEventToken: "remote token",
+		},
+		{
+			name:           "rotate eventToken",
+			args:           args{projectID: projectID, credentials: &Credentials{EventToken: "token"}},
+			wantSecretKey:  testSecretKey, // kept
+			wantEventToken: "token",
+		},
+		{
+			name:           "rotate secretKey again",
+			args:           args{projectID: projectID, credentials: &Credentials{SecretKey: testSecretKey + "2"}},
+			wantSecretKey:  testSecretKey + "2",
+			wantEventToken: "token", // kept
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetCredentials(tt.args.projectID, tt.args.credentials)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetCredentials() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got := internal.SecretKey(tt.args.projectID); got != tt.wantSecretKey {
+				t.Errorf("SecretKey() = %v, want %v", got, tt.wantSecretKey)
+			}
+			if got := internal.EventToken(tt.args.projectID, "remote token"); got != tt.wantEventToken {
+				t.Errorf("EventToken() = %v, want %v", got, tt.wantEventToken)
+			}
+		})
+	}
+}
+
+func TestInitPartial(t *testing.T) {
+	normalClient := testdata.MockCacheClient(t) // only projectID can be loaded
+	mockClient := client.NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().GetTabConfigData(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *protoccacheserver.GetTabConfigReq) (*protoccacheserver.GetTabConfigResp, error) {
+			if req.ProjectId != projectID {
+				return nil, errors.New("mock err")
+			}
+			return normalClient.GetTabConfigData(ctx, req)
+		}).AnyTimes()
+	mockClient.EXPECT().BatchGetExperimentBucketInfo(gomock.Any(), gomock.Any()).
+		DoAndReturn(normalClient.BatchGetExperimentBucketInfo).AnyTimes()
+	mockClient.EXPECT().BatchGetGroupBucketInfo(gomock.Any(), gomock.Any()).
+		DoAndReturn(normalClient.BatchGetGroupBucketInfo).AnyTimes()
+	tests := []struct {
+		name          string
+		projectIDList []string
+		wantLoaded    []string
+		wantFailed    int
+		wantReleased  bool
+	}{
+		{
+			name:          "partial success",
+			projectIDList: []string{projectID, "mock456"},
+			wantLoaded:    []string{projectID},
+			wantFailed:    1,
+		},
+		{
+			name:          "all fail",
+			projectIDList: []string{"mock456", "mock789"},
+			wantFailed:    2,
+			wantReleased:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer Release()
+			err := Init(context.Background(), tt.projectIDList, WithPartialInit(true),
+				WithRegisterCacheClient(mockClient), WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+			initErr, ok := err.(*InitError)
+			if !ok {
+				t.Fatalf("Init() error = %v, want *InitError", err)
+			}
+			assert.Equal(t, tt.wantLoaded, initErr.Loaded)
+			assert.Equal(t, tt.wantFailed, len(initErr.Errors))
+			assert.Equal(t, tt.wantReleased, len(internal.C.ProjectIDList) == 0)
+			for _, loaded := range tt.wantLoaded {
+				_, err = NewUserContext("12345").GetExperiments(context.Background(), loaded)
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestGetConfigRevision(t *testing.T) {
+	defer Release()
+	_, err := GetConfigRevision(projectID)
+	assert.NotNil(t, err)
+	err = Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithConfigRevisionReportInterval(time.Minute))
+	assert.Nil(t, err)
+	got, err := GetConfigRevision(projectID)
+	assert.Nil(t, err)
+	assert.Equal(t, projectID, got.ProjectID)
+	assert.NotEmpty(t, got.Revision)
+	assert.False(t, got.UpdateTime.IsZero())
+	summary, err := GetConfigSummary(projectID)
+	assert.Nil(t, err)
+	assert.Equal(t, got.Revision, summary.Revision)
+}
@@ -0,0 +1,311 @@
+// Package abctest An in-memory test double of the SDK, see Client
+package abctest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/pkg/errors"
+)
+
+// Exposure An exposure logged to the test double
+type Exposure struct {
+	ProjectID string
+	UnitID    string
+	// The layer, experiment and group of the experiment exposure, empty for the feature flag exposure
+	LayerKey      string
+	ExperimentKey string
+	GroupKey      string
+	// The key of the feature flag exposure, empty for the experiment exposure
+	FeatureFlagKey string
+	// Whether logged automatically by the evaluation, otherwise by the exposure logging API
+	Automatic bool
+	// The time of the clock of the client when the exposure is logged
+	Time time.Time
+}
+
+// Client The test double of the SDK bound to a projectID. The assignments are programmed by ForceVariant and the
+// exposures are kept in memory, so that the code under test can be evaluated without Init, the network or the global
+// state of the SDK. The methods mirror abc.Client, and the user contexts created by the client implement abc.Context.
+// Concurrent and safe
+type Client struct {
+	projectID string
+	clock     *Clock
+
+	lock sync.Mutex
+	// key is unitID, then layerKey
+	variants map[string]map[string]*abc.Group
+	// The groups of the units not forced, key is layerKey
+	defaults map[string]*abc.Group
+	// The values of the feature flags, key is the feature flag key
+	featureFlags map[string][]byte
+	// The unitID of the results returned by the user contexts, which the manual exposures are logged with
+	owners    map[interface{}]string
+	exposures []Exposure
+}
+
+// Option NewClient related options
+type Option func(c *Client)
+
+// WithClock Use the clock shared with the other test doubles, a clock stopped at the creation time by default
+func WithClock(clock *Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// NewClient Create a test double serving the projectID without any assignment
+func NewClient(projectID string, opts ...Option) *Client {
+	c := &Client{
+		projectID:    projectID,
+		variants:     map[string]map[string]*abc.Group{},
+		defaults:     map[string]*abc.Group{},
+		featureFlags: map[string][]byte{},
+		owners:       map[interface{}]string{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.clock == nil {
+		c.clock = NewClock(time.Now())
+	}
+	return c
+}
+
+// ProjectID The projectID served by the client
+func (c *Client) ProjectID() string {
+	return c.projectID
+}
+
+// Clock The clock of the exposure time
+func (c *Client) Clock() *Clock {
+	return c.clock
+}
+
+// ForceVariant Assign the unitID to the group in the layer, the group is usually created by abc.NewGroup
+func (c *Client) ForceVariant(unitID string, layerKey string, group *abc.Group) {
+	g := *group
+	g.LayerKey = layerKey
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.variants[unitID] == nil {
+		c.variants[unitID] = map[string]*abc.Group{}
+	}
+	c.variants[unitID][layerKey] = &g
+}
+
+// ClearVariant Remove the assignment of the unitID in the layer
+func (c *Client) ClearVariant(unitID string, layerKey string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.variants[unitID], layerKey)
+}
+
+// SetDefault Set the default group of the layer returned to the units not forced, such as the default parameters
+// of the layer. GetExperiment returns nil for the layer without the default group
+func (c *Client) SetDefault(layerKey string, params map[string]string) {
+	g := abc.NewGroup(layerKey, "", params)
+	g.IsDefault = true
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.defaults[layerKey] = g
+}
+
+// SetFeatureFlag Set the value of the feature flag for all units
+func (c *Client) SetFeatureFlag(key string, value string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.featureFlags[key] = []byte(value)
+}
+
+// Exposures The exposures logged so far, in the logging order
+func (c *Client) Exposures() []Exposure {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	result := make([]Exposure, len(c.exposures))
+	copy(result, c.exposures)
+	return result
+}
+
+// ResetExposures Drop the exposures logged so far
+func (c *Client) ResetExposures() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.exposures = nil
+}
+
+// NewUserContext Create a user context evaluated by the client, the attributions are ignored since the
+// assignments are programmed, see abc.NewUserContext
+func (c *Client) NewUserContext(unitID string, opts ...abc.Attribution) abc.Context {
+	return &userContext{client: c, unitID: unitID}
+}
+
+// GetExperiment Get the experiment of the layerKey, see abc.Client.GetExperiment
+func (c *Client) GetExperiment(ctx context.Context, userCtx abc.Context, layerKey string,
+	opts ...abc.ExperimentOption) (*abc.ExperimentResult, error) {
+	return userCtx.GetExperiment(ctx, c.projectID, layerKey, opts...)
+}
+
+// GetExperiments Get the experiments of all layers, see abc.Client.GetExperiments
+func (c *Client) GetExperiments(ctx context.Context, userCtx abc.Context,
+	opts ...abc.ExperimentOption) (*abc.ExperimentList, error) {
+	return userCtx.GetExperiments(ctx, c.projectID, opts...)
+}
+
+// GetExperimentsByKeyPattern Get the experiments of the layers matching the pattern,
+// see abc.Client.GetExperimentsByKeyPattern
+func (c *Client) GetExperimentsByKeyPattern(ctx context.Context, userCtx abc.Context, pattern string,
+	opts ...abc.ExperimentOption) (*abc.ExperimentList, error) {
+	return userCtx.GetExperimentsByKeyPattern(ctx, c.projectID, pattern, opts...)
+}
+
+// GetValueByVariantKey Get the parameter value, see abc.Client.GetValueByVariantKey
+func (c *Client) GetValueByVariantKey(ctx context.Context, userCtx abc.Context, key string,
+	opts ...abc.ExperimentOption) (*abc.ValueResult, error) {
+	return userCtx.GetValueByVariantKey(ctx, c.projectID, key, opts...)
+}
+
+// GetFeatureFlag Get the feature flag, see abc.Client.GetFeatureFlag
+func (c *Client) GetFeatureFlag(ctx context.Context, userCtx abc.Context, key string,
+	opts ...abc.ConfigOption) (*abc.FeatureFlag, error) {
+	return userCtx.GetFeatureFlag(ctx, c.projectID, key, opts...)
+}
+
+// LogExperimentExposure Log the exposure of the result returned by the client, see abc.LogExperimentExposure
+func (c *Client) LogExperimentExposure(ctx context.Context, result *abc.ExperimentResult) error {
+	if result == nil || result.Group == nil {
+		return nil
+	}
+	unitID, err := c.owner(result)
+	if err != nil {
+		return err
+	}
+	c.logExperiments(c.projectID, unitID, []*abc.Group{result.Group}, false)
+	return nil
+}
+
+// LogExperimentsExposure Log the exposures of the list returned by the client, see abc.LogExperimentsExposure
+func (c *Client) LogExperimentsExposure(ctx context.Context, list *abc.ExperimentList) error {
+	if list == nil {
+		return nil
+	}
+	unitID, err := c.owner(list)
+	if err != nil {
+		return err
+	}
+	c.logExperiments(c.projectID, unitID, sortedGroups(list.Data), false)
+	return nil
+}
+
+// LogFeatureFlagExposure Log the exposure of the feature flag returned by the client,
+// see abc.LogFeatureFlagExposure
+func (c *Client) LogFeatureFlagExposure(ctx context.Context, featureFlag *abc.FeatureFlag) error {
+	if featureFlag == nil || featureFlag.ConfigResult == nil {
+		return nil
+	}
+	unitID, err := c.owner(featureFlag)
+	if err != nil {
+		return err
+	}
+	c.logFeatureFlag(c.projectID, unitID, featureFlag.Key, false)
+	return nil
+}
+
+func (c *Client) owner(result interface{}) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	unitID, ok := c.owners[result]
+	if !ok {
+		return "", errors.Errorf("the result is not returned by the test double")
+	}
+	return unitID, nil
+}
+
+func (c *Client) own(result interface{}, unitID string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.owners[result] = unitID
+}
+
+// logExperiments Log the exposures of the groups, the default groups are not in any experiment and not logged
+func (c *Client) logExperiments(projectID string, unitID string, groups []*abc.Group, automatic bool) {
+	now := c.clock.Now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, group := range groups {
+		if group.IsDefault {
+			continue
+		}
+		c.exposures = append(c.exposures, Exposure{ProjectID: projectID, UnitID: unitID, LayerKey: group.LayerKey,
+			ExperimentKey: group.ExperimentKey, GroupKey: group.Key, Automatic: automatic, Time: now})
+	}
+}
+
+func (c *Client) logFeatureFlag(projectID string, unitID string, key string, automatic bool) {
+	now := c.clock.Now()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.exposures = append(c.exposures, Exposure{ProjectID: projectID, UnitID: unitID, FeatureFlagKey: key,
+		Automatic: automatic, Time: now})
+}
+
+// groups The groups of the unit in the layers, the forced ones take precedence over the defaults.
+// layerKeys filters the layers if not empty
+func (c *Client) groups(unitID string, layerKeys map[string]bool, patterns []string) map[string]*abc.Group {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	result := make(map[string]*abc.Group, len(c.defaults)+len(c.variants[unitID]))
+	for layerKey, group := range c.defaults {
+		result[layerKey] = group
+	}
+	for layerKey, group := range c.variants[unitID] {
+		result[layerKey] = group
+	}
+	if len(layerKeys) > 0 || len(patterns) > 0 {
+		for layerKey := range result {
+			if !layerKeys[layerKey] && !experiment.MatchLayerKeyPatterns(patterns, layerKey) {
+				delete(result, layerKey)
+			}
+		}
+	}
+	return result
+}
+
+func (c *Client) featureFlag(key string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	value, ok := c.featureFlags[key]
+	return value, ok
+}
+
+// sortedGroups The groups in the order of the layerKey, so that the exposures are logged in a stable order
+func sortedGroups(data map[string]*abc.Group) []*abc.Group {
+	layerKeys := make([]string, 0, len(data))
+	for layerKey := range data {
+		layerKeys = append(layerKeys, layerKey)
+	}
+	sort.Strings(layerKeys)
+	groups := make([]*abc.Group, 0, len(layerKeys))
+	for _, layerKey := range layerKeys {
+		groups = append(groups, data[layerKey])
+	}
+	return groups
+}
+
+// applyOptions Apply the options over the defaults of the SDK, the exposures are logged automatically by default
+func applyOptions(opts []abc.ExperimentOption) (*experiment.Options, error) {
+	options := &experiment.Options{IsExposureLoggingAutomatic: true}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
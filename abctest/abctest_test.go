@@ -0,0 +1,86 @@
+// Package abctest ...
+package abctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	clock := NewClock(time.Unix(1000, 0))
+	client := NewClient("123", WithClock(clock))
+	client.ForceVariant("u1", "layer", abc.NewGroup("", "treatment", map[string]string{"color": "red"}))
+	client.SetDefault("layer", map[string]string{"color": "blue"})
+	client.SetFeatureFlag("flag", "true")
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		unitID    string
+		opts      []abc.ExperimentOption
+		wantColor string
+		wantLog   bool
+	}{
+		{name: "forced", unitID: "u1", wantColor: "red", wantLog: true},
+		{name: "forced without automatic exposure", unitID: "u1", opts: []abc.ExperimentOption{abc.WithAutomatic(false)},
+			wantColor: "red"},
+		{name: "default group is not logged", unitID: "u2", wantColor: "blue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.ResetExposures()
+			result, err := client.GetExperiment(ctx, client.NewUserContext(tt.unitID), "layer", tt.opts...)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantColor, result.MustGetString("color"))
+			assert.Equal(t, tt.wantLog, len(client.Exposures()) == 1)
+		})
+	}
+
+	client.ResetExposures()
+	userCtx := client.NewUserContext("u1")
+	result, err := client.GetExperiment(ctx, userCtx, "layer", abc.WithAutomatic(false))
+	assert.Nil(t, err)
+	clock.Advance(time.Minute)
+	assert.Nil(t, client.LogExperimentExposure(ctx, result))
+	assert.Equal(t, []Exposure{{ProjectID: "123", UnitID: "u1", LayerKey: "layer", GroupKey: "treatment",
+		Time: time.Unix(1060, 0)}}, client.Exposures())
+	assert.NotNil(t, client.LogExperimentExposure(ctx, &abc.ExperimentResult{Group: result.Group}))
+
+	result, err = client.GetExperiment(ctx, userCtx, "unknown")
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+	_, err = userCtx.GetExperiment(ctx, "456", "layer")
+	assert.NotNil(t, err)
+
+	list, err := client.GetExperiments(ctx, userCtx, abc.WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list.Data))
+	list, err = client.GetExperimentsByKeyPattern(ctx, userCtx, "lay*", abc.WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list.Data))
+	list, err = client.GetExperimentsByKeyPattern(ctx, userCtx, "recs.*", abc.WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Empty(t, list.Data)
+	value, err := client.GetValueByVariantKey(ctx, userCtx, "color", abc.WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Equal(t, "red", value.String())
+	featureFlag, err := client.GetFeatureFlag(ctx, userCtx, "flag")
+	assert.Nil(t, err)
+	assert.True(t, featureFlag.MustGetBool())
+	_, err = client.GetFeatureFlag(ctx, userCtx, "unknown")
+	assert.NotNil(t, err)
+	assert.Nil(t, client.LogFeatureFlagExposure(ctx, featureFlag))
+	exposures := client.Exposures()
+	assert.Equal(t, 3, len(exposures)) // manual experiment, automatic and manual feature flag
+	assert.Equal(t, "flag", exposures[2].FeatureFlagKey)
+	assert.True(t, exposures[1].Automatic)
+	assert.False(t, exposures[2].Automatic)
+
+	client.ClearVariant("u1", "layer")
+	result, err = client.GetExperiment(ctx, userCtx, "layer")
+	assert.Nil(t, err)
+	assert.True(t, result.IsDefault)
+}
@@ -0,0 +1,40 @@
+// Package abctest An in-memory test double of the SDK, see Client
+package abctest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock A manual clock of the test double, the time only moves by Advance and Set, concurrent and safe
+type Clock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+// NewClock Create a clock stopped at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now The current time of the clock
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Advance Move the clock forward by d and return the new time
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set Move the clock to now
+func (c *Clock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = now
+}
@@ -0,0 +1,137 @@
+// Package abctest An in-memory test double of the SDK, see Client
+package abctest
+
+import (
+	"context"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/pkg/errors"
+)
+
+// userContext The user context evaluated by the test double, see abc.Context
+type userContext struct {
+	client *Client
+	unitID string
+}
+
+func (u *userContext) check(projectID string) error {
+	if len(u.unitID) == 0 {
+		return errors.Wrap(abc.ErrInvalidUnitID, "unitID is required")
+	}
+	if projectID != u.client.projectID {
+		return errors.Errorf("projectID [%s] is not served by the test double of [%s]", projectID,
+			u.client.projectID)
+	}
+	return nil
+}
+
+// GetExperiment The group forced in the layer, otherwise the default group of the layer, nil if neither is set
+func (u *userContext) GetExperiment(ctx context.Context, projectID string, layerKey string,
+	opts ...abc.ExperimentOption) (*abc.ExperimentResult, error) {
+	if err := u.check(projectID); err != nil {
+		return nil, err
+	}
+	options, err := applyOptions(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "opt")
+	}
+	group, ok := u.client.groups(u.unitID, map[string]bool{layerKey: true}, nil)[layerKey]
+	if !ok {
+		return nil, nil
+	}
+	result := &abc.ExperimentResult{Group: group}
+	u.client.own(result, u.unitID)
+	if options.IsExposureLoggingAutomatic {
+		u.client.logExperiments(projectID, u.unitID, []*abc.Group{group}, true)
+	}
+	return result, nil
+}
+
+// GetExperiments The groups of all layers with a forced or default group, filtered by abc.WithLayerKeyList
+func (u *userContext) GetExperiments(ctx context.Context, projectID string,
+	opts ...abc.ExperimentOption) (*abc.ExperimentList, error) {
+	if err := u.check(projectID); err != nil {
+		return nil, err
+	}
+	options, err := applyOptions(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "opt")
+	}
+	list := &abc.ExperimentList{Data: u.client.groups(u.unitID, options.LayerKeys, options.LayerKeyPatterns)}
+	u.client.own(list, u.unitID)
+	if options.IsExposureLoggingAutomatic {
+		u.client.logExperiments(projectID, u.unitID, sortedGroups(list.Data), true)
+	}
+	return list, nil
+}
+
+// GetExperimentsByKeyPattern The groups of GetExperiments of the layers matching the pattern
+func (u *userContext) GetExperimentsByKeyPattern(ctx context.Context, projectID string, pattern string,
+	opts ...abc.ExperimentOption) (*abc.ExperimentList, error) {
+	return u.GetExperiments(ctx, projectID, append(opts, abc.WithLayerKeyPattern(pattern))...)
+}
+
+// GetFeatureFlag The value set by SetFeatureFlag, error if it is not set
+func (u *userContext) GetFeatureFlag(ctx context.Context, projectID string, key string,
+	opts ...abc.ConfigOption) (*abc.FeatureFlag, error) {
+	configResult, err := u.GetRemoteConfig(ctx, projectID, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	featureFlag := &abc.FeatureFlag{ConfigResult: configResult}
+	u.client.own(featureFlag, u.unitID)
+	return featureFlag, nil
+}
+
+// GetValueByVariantKey The parameter of the groups of the unit in the order of the layerKey,
+// then the feature flag of the key
+func (u *userContext) GetValueByVariantKey(ctx context.Context, projectID string, key string,
+	opts ...abc.ExperimentOption) (*abc.ValueResult, error) {
+	if err := u.check(projectID); err != nil {
+		return nil, err
+	}
+	options, err := applyOptions(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "opt")
+	}
+	for _, group := range sortedGroups(u.client.groups(u.unitID, nil, nil)) {
+		value, ok := group.GetBytes(key)
+		if !ok {
+			continue
+		}
+		if options.IsExposureLoggingAutomatic {
+			u.client.logExperiments(projectID, u.unitID, []*abc.Group{group}, true)
+		}
+		return &abc.ValueResult{Value: abc.NewValue(value)}, nil
+	}
+	value, ok := u.client.featureFlag(key)
+	if !ok {
+		return nil, errors.Errorf("variant key [%s] not found", key)
+	}
+	if options.IsExposureLoggingAutomatic {
+		u.client.logFeatureFlag(projectID, u.unitID, key, true)
+	}
+	return &abc.ValueResult{Value: abc.NewValue(value)}, nil
+}
+
+// GetRemoteConfig The value set by SetFeatureFlag, error if it is not set
+//
+// Deprecated: GetRemoteConfig is deprecated, please use GetFeatureFlag instead.
+func (u *userContext) GetRemoteConfig(ctx context.Context, projectID string, key string,
+	opts ...abc.ConfigOption) (*abc.ConfigResult, error) {
+	if err := u.check(projectID); err != nil {
+		return nil, err
+	}
+	options, err := applyOptions(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "opt")
+	}
+	value, ok := u.client.featureFlag(key)
+	if !ok {
+		return nil, errors.Errorf("feature flag [%s] not found", key)
+	}
+	if options.IsExposureLoggingAutomatic {
+		u.client.logFeatureFlag(projectID, u.unitID, key, true)
+	}
+	return &abc.ConfigResult{Config: &abc.Config{Value: abc.NewValue(value), Key: key}}, nil
+}
@@ -0,0 +1,213 @@
+// Package abctest An in-memory test double of the SDK, see Client
+package abctest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UpdateGoldenEnv The environment variable which makes AssertGolden rewrite the golden files instead of comparing,
+// such as ABC_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "ABC_UPDATE_GOLDEN"
+
+// GoldenOption RenderExposures and RenderMonitorEvents related options
+type GoldenOption func(o *goldenOptions)
+
+type goldenOptions struct {
+	masks map[string]bool
+}
+
+// WithMaskedFields Render the fields as <masked>, such as the time and the ip varying across the runs. A field is
+// named by its proto name at any depth, such as "time", or by the dotted path from the payload, such as
+// "extra_data.invoke_path" for an entry of a map
+func WithMaskedFields(fields ...string) GoldenOption {
+	return func(o *goldenOptions) {
+		for _, field := range fields {
+			o.masks[field] = true
+		}
+	}
+}
+
+// RenderExposures Render the exposures logged to the metrics plugin in a stable textual form, one line per field
+// in the order of the field numbers, and the map entries in the order of the keys. The unset fields are rendered
+// with the zero values, so that a field added to or removed from the schema changes the rendering
+func RenderExposures(group *protoc_event_server.ExposureGroup, opts ...GoldenOption) string {
+	var b strings.Builder
+	for i, exposure := range group.GetExposures() {
+		renderPayload(&b, fmt.Sprintf("exposure[%d]", i), exposure, opts)
+	}
+	return b.String()
+}
+
+// RenderMonitorEvents Render the monitor events logged to the metrics plugin, see RenderExposures
+func RenderMonitorEvents(group *protoc_event_server.MonitorEventGroup, opts ...GoldenOption) string {
+	var b strings.Builder
+	for i, event := range group.GetEvents() {
+		renderPayload(&b, fmt.Sprintf("monitor_event[%d]", i), event, opts)
+	}
+	return b.String()
+}
+
+// RenderSendData Render the rows sent to the metrics plugin, one line per row with the quoted columns
+func RenderSendData(data [][]string) string {
+	var b strings.Builder
+	for i, row := range data {
+		columns := make([]string, len(row))
+		for j, column := range row {
+			columns[j] = strconv.Quote(column)
+		}
+		fmt.Fprintf(&b, "row[%d]: [%s]\n", i, strings.Join(columns, ", "))
+	}
+	return b.String()
+}
+
+// AssertGolden Compare the rendering with the golden file, the file is rewritten instead if UpdateGoldenEnv is set.
+// The first different line is reported on the mismatch
+func AssertGolden(t testing.TB, path string, got string) bool {
+	t.Helper()
+	if len(os.Getenv(UpdateGoldenEnv)) > 0 {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Errorf("mkdir golden file %s: %v", path, err)
+			return false
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Errorf("write golden file %s: %v", path, err)
+			return false
+		}
+		return true
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("read golden file %s: %v, run with %s=1 to create it", path, err, UpdateGoldenEnv)
+		return false
+	}
+	if string(want) == got {
+		return true
+	}
+	wantLines, gotLines := strings.Split(string(want), "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var wantLine, gotLine string
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if wantLine != gotLine {
+			t.Errorf("golden file %s mismatch at line %d:\nwant: %s\n got: %s\nrun with %s=1 to update it",
+				path, i+1, wantLine, gotLine, UpdateGoldenEnv)
+			break
+		}
+	}
+	return false
+}
+
+func renderPayload(b *strings.Builder, name string, message proto.Message, opts []GoldenOption) {
+	options := &goldenOptions{masks: map[string]bool{}}
+	for _, opt := range opts {
+		opt(options)
+	}
+	fmt.Fprintf(b, "%s {\n", name)
+	renderMessage(b, proto.MessageReflect(message), "  ", "", options.masks)
+	b.WriteString("}\n")
+}
+
+func renderMessage(b *strings.Builder, m protoreflect.Message, indent string, path string, masks map[string]bool) {
+	fields := m.Descriptor().Fields()
+	sorted := make([]protoreflect.FieldDescriptor, fields.Len())
+	for i := range sorted {
+		sorted[i] = fields.Get(i)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Number() < sorted[j].Number()
+	})
+	for _, fd := range sorted {
+		name := string(fd.Name())
+		fieldPath := name
+		if len(path) > 0 {
+			fieldPath = path + "." + name
+		}
+		switch {
+		case masks[name] || masks[fieldPath]:
+			fmt.Fprintf(b, "%s%s: <masked>\n", indent, name)
+		case fd.IsMap():
+			renderMap(b, m.Get(fd).Map(), fd, indent, fieldPath, masks)
+		case fd.IsList():
+			list := m.Get(fd).List()
+			if list.Len() == 0 {
+				fmt.Fprintf(b, "%s%s: []\n", indent, name)
+				continue
+			}
+			fmt.Fprintf(b, "%s%s: [\n", indent, name)
+			for i := 0; i < list.Len(); i++ {
+				renderValue(b, fd, list.Get(i), indent+"  ", "", fieldPath, masks)
+			}
+			fmt.Fprintf(b, "%s]\n", indent)
+		case fd.Message() != nil && !m.Has(fd):
+			fmt.Fprintf(b, "%s%s: null\n", indent, name)
+		default:
+			renderValue(b, fd, m.Get(fd), indent, name+": ", fieldPath, masks)
+		}
+	}
+}
+
+func renderMap(b *strings.Builder, entries protoreflect.Map, fd protoreflect.FieldDescriptor, indent string,
+	path string, masks map[string]bool) {
+	name := string(fd.Name())
+	if entries.Len() == 0 {
+		fmt.Fprintf(b, "%s%s: {}\n", indent, name)
+		return
+	}
+	keys := make([]protoreflect.MapKey, 0, entries.Len())
+	entries.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	fmt.Fprintf(b, "%s%s: {\n", indent, name)
+	for _, key := range keys {
+		prefix := strconv.Quote(key.String()) + ": "
+		if masks[path+"."+key.String()] {
+			fmt.Fprintf(b, "%s  %s<masked>\n", indent, prefix)
+			continue
+		}
+		renderValue(b, fd.MapValue(), entries.Get(key), indent+"  ", prefix, path+"."+key.String(), masks)
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// renderValue Render a single value of the field, the repeated and the map values included
+func renderValue(b *strings.Builder, fd protoreflect.FieldDescriptor, v protoreflect.Value, indent string,
+	prefix string, path string, masks map[string]bool) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		fmt.Fprintf(b, "%s%s{\n", indent, prefix)
+		renderMessage(b, v.Message(), indent+"  ", path, masks)
+		fmt.Fprintf(b, "%s}\n", indent)
+	case protoreflect.StringKind:
+		fmt.Fprintf(b, "%s%s%s\n", indent, prefix, strconv.Quote(v.String()))
+	case protoreflect.BytesKind:
+		fmt.Fprintf(b, "%s%s%q\n", indent, prefix, v.Bytes())
+	case protoreflect.EnumKind:
+		enum := fd.Enum().Values().ByNumber(v.Enum())
+		if enum == nil {
+			fmt.Fprintf(b, "%s%s%d\n", indent, prefix, v.Enum())
+			return
+		}
+		fmt.Fprintf(b, "%s%s%s\n", indent, prefix, enum.Name())
+	default:
+		fmt.Fprintf(b, "%s%s%v\n", indent, prefix, v.Interface())
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+package abctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+// volatileFields The fields of the exposures varying across the runs and the hosts
+var volatileFields = []string{"time", "sdk_version", "extra_data.ip", "extra_data.hostname"}
+
+// TestExposureGolden The exposures logged by the SDK, which changes once the schema of the payloads changes
+func TestExposureGolden(t *testing.T) {
+	defer abc.Release()
+	recorder := NewMetricsRecorder("pubsub") // the default experiment metrics plugin of the test data
+	metrics.RegisterClient(recorder)
+	err := abc.Init(context.Background(), []string{"123"}, abc.WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		abc.WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	result, err := abc.NewUserContext("12345").GetExperiment(context.Background(), "123", "overrideLayer",
+		abc.WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Nil(t, abc.LogExperimentExposure(context.Background(), "123", result))
+	assert.Eventually(t, func() bool {
+		return len(recorder.Exposures().Exposures) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	AssertGolden(t, "testdata/exposure.golden", RenderExposures(recorder.Exposures(),
+		WithMaskedFields(volatileFields...)))
+}
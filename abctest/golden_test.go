@@ -0,0 +1,23 @@
+package abctest
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	exposures := &protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{
+		{UnitId: "u1", GroupId: 1, ProjectId: "p", Time: 1700000000, LayerKey: "l",
+			ExposureType: protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL,
+			Device:       &protoc_event_server.Device{}, ExtraData: map[string]string{"b": "2", "a": "1"}},
+	}}
+	AssertGolden(t, "testdata/render_exposures.golden",
+		RenderExposures(exposures, WithMaskedFields("time", "extra_data.b")))
+	monitorEvents := &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{ProjectId: "p", EventName: "init", Latency: 1.5},
+	}}
+	AssertGolden(t, "testdata/render_monitor_events.golden", RenderMonitorEvents(monitorEvents))
+	assert.Equal(t, "row[0]: [\"a\", \"b\\tc\"]\nrow[1]: []\n", RenderSendData([][]string{{"a", "b\tc"}, {}}))
+}
@@ -0,0 +1,112 @@
+// Package abctest An in-memory test double of the SDK, see Client
+package abctest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+// MetricsRecorder The metrics plugin keeping the payloads logged by the SDK in memory, register it under the name of
+// the plugin of the project, then render the payloads for the golden files. Concurrent and safe
+type MetricsRecorder struct {
+	name string
+
+	lock           sync.Mutex
+	exposureGroups []*protoc_event_server.ExposureGroup
+	monitorGroups  []*protoc_event_server.MonitorEventGroup
+	data           [][]string
+}
+
+// NewMetricsRecorder Create the recorder registered as the plugin of the name, see metrics.RegisterClient
+func NewMetricsRecorder(name string) *MetricsRecorder {
+	return &MetricsRecorder{name: name}
+}
+
+// Name see metrics.Client
+func (r *MetricsRecorder) Name() string {
+	return r.name
+}
+
+// Init see metrics.Client
+func (r *MetricsRecorder) Init(ctx context.Context, config *protoc_cache_server.MetricsInitConfig) error {
+	return nil
+}
+
+// LogExposure Record the exposures
+func (r *MetricsRecorder) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.exposureGroups = append(r.exposureGroups, exposureGroup)
+	return nil
+}
+
+// LogEvent The events are not recorded
+func (r *MetricsRecorder) LogEvent(ctx context.Context, metadata *metrics.Metadata,
+	eventGroup *protoc_event_server.EventGroup) error {
+	return nil
+}
+
+// LogMonitorEvent Record the monitor events
+func (r *MetricsRecorder) LogMonitorEvent(ctx context.Context, metadata *metrics.Metadata,
+	monitorEventGroup *protoc_event_server.MonitorEventGroup) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.monitorGroups = append(r.monitorGroups, monitorEventGroup)
+	return nil
+}
+
+// SendData Record the rows
+func (r *MetricsRecorder) SendData(ctx context.Context, metadata *metrics.Metadata, data [][]string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.data = append(r.data, data...)
+	return nil
+}
+
+// RetainsMessages The recorded messages are kept, so that the SDK does not reuse them, see metrics.MessageRetainer
+func (r *MetricsRecorder) RetainsMessages() bool {
+	return true
+}
+
+// Exposures The exposures recorded so far in a single group, in the logging order
+func (r *MetricsRecorder) Exposures() *protoc_event_server.ExposureGroup {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := &protoc_event_server.ExposureGroup{}
+	for _, group := range r.exposureGroups {
+		result.Exposures = append(result.Exposures, group.Exposures...)
+	}
+	return result
+}
+
+// MonitorEvents The monitor events recorded so far in a single group, in the logging order
+func (r *MetricsRecorder) MonitorEvents() *protoc_event_server.MonitorEventGroup {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := &protoc_event_server.MonitorEventGroup{}
+	for _, group := range r.monitorGroups {
+		result.Events = append(result.Events, group.Events...)
+	}
+	return result
+}
+
+// Data The rows recorded so far, in the sending order
+func (r *MetricsRecorder) Data() [][]string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := make([][]string, len(r.data))
+	copy(result, r.data)
+	return result
+}
+
+// Reset Drop the payloads recorded so far
+func (r *MetricsRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.exposureGroups, r.monitorGroups, r.data = nil, nil, nil
+}
@@ -0,0 +1,152 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+// AliasStore The store of the anonymous-to-known identity aliases, see WithAliasStore
+type AliasStore = internal.AliasStore
+
+// defaultAliasStoreSize The maximum number of the aliases kept by the default in-memory store
+const defaultAliasStoreSize = 100000
+
+// WithAliasStore set the store of the aliases recorded by Alias, such as a store backed by redis shared by all
+// instances. The default store keeps the aliases of the process in memory only
+func WithAliasStore(store AliasStore) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.AliasStore = store
+		return nil
+	}
+}
+
+var (
+	defaultAliasStoreLock sync.Mutex
+	defaultAliasStore     AliasStore
+)
+
+func aliasStore() AliasStore {
+	if internal.C.AliasStore != nil {
+		return internal.C.AliasStore
+	}
+	defaultAliasStoreLock.Lock()
+	defer defaultAliasStoreLock.Unlock()
+	if defaultAliasStore == nil {
+		defaultAliasStore = NewMemoryAliasStore(defaultAliasStoreSize)
+	}
+	return defaultAliasStore
+}
+
+// resetAliasStore Clear the default store, called by Release
+func resetAliasStore() {
+	defaultAliasStoreLock.Lock()
+	defer defaultAliasStoreLock.Unlock()
+	defaultAliasStore = nil
+}
+
+// Alias Record that the anonymousID, such as the cookie before the login, and the userID are the same unit, so that
+// the assignments of the anonymousID stick after the login, see NewAliasedUserContext. The first alias of the userID
+// is kept
+func Alias(ctx context.Context, anonymousID string, userID string) error {
+	if len(anonymousID) == 0 || len(userID) == 0 {
+		return errors.Errorf("anonymousID and userID are required")
+	}
+	if anonymousID == userID {
+		return nil
+	}
+	return errors.Wrap(aliasStore().SetAlias(ctx, userID, anonymousID), "setAlias")
+}
+
+// NewAliasedUserContext Create the user context of the userID through its alias. If the userID is aliased, the
+// anonymousID is the unitID so that the assignments stick, and the userID is the newUnitID carried by the exposures
+// as new_id, so that the exposures before and after the login are reconciled. The layers migrated to the new unitID
+// type on the platform are assigned by the userID instead. If not aliased or the store fails, it is the same as
+// NewUserContext(userID)
+func NewAliasedUserContext(ctx context.Context, userID string, opts ...Attribution) Context {
+	if len(userID) == 0 {
+		return NewUserContext(userID, opts...)
+	}
+	anonymousID, err := aliasStore().GetAlias(ctx, userID)
+	if err != nil {
+		log.LimitedErrorf("getAlias", "get alias of the user fail:%v", err)
+	}
+	if err != nil || len(anonymousID) == 0 {
+		return NewUserContext(userID, opts...)
+	}
+	attributions := make([]Attribution, 0, len(opts)+1)
+	attributions = append(attributions, WithNewUnitID(userID))
+	return NewUserContext(anonymousID, append(attributions, opts...)...)
+}
+
+// memoryAliasStore The in-memory store, the oldest aliases are evicted once full
+type memoryAliasStore struct {
+	lock    sync.RWMutex
+	size    int
+	aliases map[string]string
+	// the userIDs in the order of the insertion, a ring of the size
+	order []string
+	next  int
+}
+
+// NewMemoryAliasStore Create the in-memory store keeping at most size aliases, for the tests and the single instance
+// services
+func NewMemoryAliasStore(size int) AliasStore {
+	if size <= 0 {
+		size = defaultAliasStoreSize
+	}
+	return &memoryAliasStore{size: size, aliases: map[string]string{}}
+}
+
+// SetAlias Implement AliasStore
+func (s *memoryAliasStore) SetAlias(ctx context.Context, userID string, anonymousID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.aliases[userID]; ok {
+		return nil
+	}
+	if len(s.order) < s.size {
+		s.order = append(s.order, userID)
+	} else {
+		delete(s.aliases, s.order[s.next])
+		s.order[s.next] = userID
+		s.next = (s.next + 1) % s.size
+	}
+	s.aliases[userID] = anonymousID
+	return nil
+}
+
+// GetAlias Implement AliasStore
+func (s *memoryAliasStore) GetAlias(ctx context.Context, userID string) (string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.aliases[userID], nil
+}
+
+// aliasList The aliases as the pairs of the userID and the anonymousID, the oldest first, see WithStateHandoff
+func (s *memoryAliasStore) aliasList() [][]string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	list := make([][]string, 0, len(s.order))
+	for i := range s.order {
+		userID := s.order[(s.next+i)%len(s.order)]
+		list = append(list, []string{userID, s.aliases[userID]})
+	}
+	return list
+}
+
+// restoreAliases Record the aliases of aliasList, the oldest first so that the same ones are evicted
+func (s *memoryAliasStore) restoreAliases(list [][]string) {
+	for _, alias := range list {
+		if len(alias) != 2 || len(alias[0]) == 0 || len(alias[1]) == 0 {
+			continue
+		}
+		_ = s.SetAlias(context.Background(), alias[0], alias[1])
+	}
+}
@@ -0,0 +1,70 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlias(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	ctx := context.Background()
+	before, err := NewUserContext("12345").GetExperiment(ctx, projectID, "overrideLayer", WithAutomatic(false))
+	assert.Nil(t, err)
+
+	userCtx := NewAliasedUserContext(ctx, "user1").(*userContext) // not aliased
+	assert.Equal(t, "user1", userCtx.unitID)
+	assert.Equal(t, "user1", userCtx.newUnitID)
+
+	assert.Nil(t, Alias(ctx, "12345", "user1"))
+	assert.Nil(t, Alias(ctx, "54321", "user1")) // the first alias is kept
+	assert.Nil(t, Alias(ctx, "user1", "user1"))
+	assert.NotNil(t, Alias(ctx, "", "user1"))
+	c, err := NewClient(projectID, WithClientAttributions(WithTagKV("city", "sz")))
+	assert.Nil(t, err)
+	aliased := c.NewAliasedUserContext(ctx, "user1")
+	userCtx = aliased.(*userContext)
+	assert.Equal(t, "12345", userCtx.unitID)
+	assert.Equal(t, "12345", userCtx.decisionID)
+	assert.Equal(t, "user1", userCtx.newUnitID)
+	assert.Equal(t, []string{"sz"}, userCtx.tags["city"])
+	after, err := aliased.GetExperiment(ctx, projectID, "overrideLayer", WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Equal(t, before.ID, after.ID)
+
+	assert.NotNil(t, NewAliasedUserContext(ctx, "").(*userContext).err)
+}
+
+func TestMemoryAliasStore(t *testing.T) {
+	store := NewMemoryAliasStore(2)
+	ctx := context.Background()
+	for _, userID := range []string{"u1", "u2", "u3"} {
+		assert.Nil(t, store.SetAlias(ctx, userID, "a-"+userID))
+	}
+	got, err := store.GetAlias(ctx, "u1")
+	assert.Nil(t, err)
+	assert.Empty(t, got) // evicted
+	got, err = store.GetAlias(ctx, "u3")
+	assert.Nil(t, err)
+	assert.Equal(t, "a-u3", got)
+
+	// handed off in the order of the insertion, so that the same ones are evicted
+	list := store.(*memoryAliasStore).aliasList()
+	assert.Equal(t, [][]string{{"u2", "a-u2"}, {"u3", "a-u3"}}, list)
+	restored := NewMemoryAliasStore(2)
+	restored.(*memoryAliasStore).restoreAliases(append(list, []string{"invalid"}))
+	assert.Nil(t, restored.SetAlias(ctx, "u4", "a-u4"))
+	got, err = restored.GetAlias(ctx, "u2")
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+	got, err = restored.GetAlias(ctx, "u3")
+	assert.Nil(t, err)
+	assert.Equal(t, "a-u3", got)
+}
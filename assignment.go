@@ -0,0 +1,50 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"hash/fnv"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// Assignment An assignment decision exported to the AssignmentSink, see WithAssignmentExport
+type Assignment = internal.Assignment
+
+// AssignmentSink The sink of the exported assignment decisions, such as a file or a message queue of the offline
+// analysis
+type AssignmentSink = internal.AssignmentSink
+
+// defaultAssignmentSamplingInterval The default sampling interval of the assignment export, one of every 100 units
+const defaultAssignmentSamplingInterval = 100
+
+// WithAssignmentExport Export the assignment decisions of every evaluation of the experiments, exposed or not,
+// to the sink in batches, so that the data scientists can audit the sample ratio mismatch from the SDK side by
+// comparing them with the exposures. The units are sampled by the hash of the unitID, one of every
+// samplingInterval units has all its assignments exported, zero uses the default 100. The export is asynchronous,
+// the assignments are discarded if the sink falls behind. Compiled out in the lite build mode
+func WithAssignmentExport(sink AssignmentSink, samplingInterval uint32) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if sink == nil {
+			return errors.Errorf("sink is required")
+		}
+		if samplingInterval == 0 {
+			samplingInterval = defaultAssignmentSamplingInterval
+		}
+		config.AssignmentSink = sink
+		config.AssignmentSamplingInterval = samplingInterval
+		return nil
+	}
+}
+
+// isAssignmentSampled Whether the assignments of the unit are exported, the same unit is always or never sampled
+func isAssignmentSampled(unitID string, samplingInterval uint32) bool {
+	if samplingInterval <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(unitID))
+	return h.Sum32()%samplingInterval == 0
+}
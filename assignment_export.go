@@ -0,0 +1,105 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+const (
+	// assignmentChanSize The capacity of the pending assignments, the assignments are discarded when full
+	assignmentChanSize = 10000
+	// assignmentBatchSize The maximum number of the assignments of an Export call
+	assignmentBatchSize = 500
+	// assignmentFlushInterval The assignments are exported at least once per interval
+	assignmentFlushInterval = time.Second
+)
+
+var (
+	assignmentExporterOnce sync.Once
+	assignmentChan         = make(chan *Assignment, assignmentChanSize)
+)
+
+// initAssignmentExporter Start the exporter, only one exporter is started across Init and Release,
+// which exports to the sink of the current global configuration
+func initAssignmentExporter() {
+	if internal.C.AssignmentSink == nil {
+		return
+	}
+	assignmentExporterOnce.Do(func() {
+		internal.Go("assignmentExporter", func(task *internal.Task) {
+			ticker := time.NewTicker(assignmentFlushInterval)
+			defer ticker.Stop()
+			batch := make([]*Assignment, 0, assignmentBatchSize)
+			for {
+				select {
+				case assignment := <-assignmentChan:
+					batch = append(batch, assignment)
+					if len(batch) < assignmentBatchSize {
+						continue
+					}
+				case <-ticker.C:
+					task.Heartbeat()
+					if len(batch) == 0 {
+						continue
+					}
+				}
+				flushAssignments(batch)
+				batch = make([]*Assignment, 0, assignmentBatchSize) // owned by the sink
+			}
+		})
+	})
+}
+
+func flushAssignments(batch []*Assignment) {
+	sink := internal.C.AssignmentSink
+	if sink == nil { // released
+		return
+	}
+	if err := sink.Export(context.Background(), batch); err != nil {
+		log.LimitedErrorf("exportAssignments", "export %d assignments fail:%v", len(batch), err)
+	}
+}
+
+// exportAssignments Queue the assignments of the list if the unit is sampled, see WithAssignmentExport
+func exportAssignments(projectID string, list *ExperimentList) {
+	samplingInterval := internal.C.AssignmentSamplingInterval
+	if internal.C.AssignmentSink == nil || list == nil || list.userCtx == nil ||
+		!isAssignmentSampled(list.userCtx.unitID, samplingInterval) {
+		return
+	}
+	now := time.Now()
+	unitID := hashUnitID(projectID, list.userCtx.unitID)
+	for _, group := range list.Data {
+		if group == nil {
+			continue
+		}
+		assignment := &Assignment{
+			ProjectID:        projectID,
+			UnitID:           unitID,
+			LayerKey:         group.LayerKey,
+			ExperimentKey:    group.ExperimentKey,
+			GroupID:          group.ID,
+			IsDefault:        group.IsDefault,
+			IsControl:        group.IsControl,
+			IsOverrideList:   group.IsOverrideList,
+			Time:             now,
+			SamplingInterval: samplingInterval,
+		}
+		select {
+		case assignmentChan <- assignment:
+		default:
+			log.LimitedErrorf("assignmentChanFull", "[projectID=%v]assignment export queue is full", projectID)
+			return
+		}
+	}
+}
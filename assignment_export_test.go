@@ -0,0 +1,70 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+type assignmentRecorder struct {
+	lock        sync.Mutex
+	assignments []*Assignment
+}
+
+func (r *assignmentRecorder) Export(ctx context.Context, assignments []*Assignment) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.assignments = append(r.assignments, assignments...)
+	return nil
+}
+
+func (r *assignmentRecorder) len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.assignments)
+}
+
+func TestAssignmentExport(t *testing.T) {
+	Release()
+	defer Release()
+	recorder := &assignmentRecorder{}
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithAssignmentExport(recorder, 1))
+	assert.Nil(t, err)
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		return recorder.len() == len(list.Data)
+	}, 3*time.Second, 10*time.Millisecond)
+	recorder.lock.Lock()
+	assignment := recorder.assignments[0]
+	recorder.lock.Unlock()
+	assert.Equal(t, projectID, assignment.ProjectID)
+	assert.Equal(t, "12345", assignment.UnitID)
+	assert.Equal(t, uint32(1), assignment.SamplingInterval)
+	assert.Equal(t, list.Data[assignment.LayerKey].ID, assignment.GroupID)
+
+	assert.NotNil(t, WithAssignmentExport(nil, 1)(internal.C))
+}
+
+func TestIsAssignmentSampled(t *testing.T) {
+	sampled := 0
+	for i := 0; i < 10000; i++ {
+		if isAssignmentSampled(strconv.Itoa(i), 100) {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 100, sampled, 50)
+	assert.Equal(t, isAssignmentSampled("u1", 100), isAssignmentSampled("u1", 100))
+	assert.True(t, isAssignmentSampled("u1", 1))
+}
@@ -0,0 +1,54 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"strings"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// AttributeDeriver Derive a normalized attribute of the user context from its other attributes, see
+// internal.AttributeDeriver and WithAttributeDerivers
+type AttributeDeriver = internal.AttributeDeriver
+
+// WithAttributeDerivers register the derivers of the attributes, run in order once per user context when it is
+// created by NewUserContext, before any rule is evaluated, so that the targeting rules on the derived attributes,
+// such as the age_bucket or the app_major_version, are consistent across the services without preprocessing the
+// attributes at each call site. A deriver sees the attributes derived by the previous ones, the attribute passed
+// by the caller is not derived again. The user contexts created before Init are not derived
+func WithAttributeDerivers(derivers ...AttributeDeriver) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		for i := range derivers {
+			if len(derivers[i].Key) == 0 || derivers[i].Derive == nil {
+				return errors.Errorf("invalid deriver %d, key and derive are required", i)
+			}
+		}
+		config.AttributeDerivers = append(config.AttributeDerivers, derivers...)
+		return nil
+	}
+}
+
+// DeriveMajorVersion The deriver of the major version of the version attribute of the source, such as the
+// app_major_version 7 from the app_version v7.3.1, the versions without a numeric major are not derived
+func DeriveMajorVersion(key string, source string) AttributeDeriver {
+	return AttributeDeriver{
+		Key: key,
+		Derive: func(attributes map[string][]string) []string {
+			value := attributes[source]
+			if len(value) == 0 {
+				return nil
+			}
+			version := strings.TrimLeft(strings.TrimSpace(value[0]), "vV")
+			if i := strings.IndexAny(version, ".-+"); i >= 0 {
+				version = version[:i]
+			}
+			if len(version) == 0 || strings.Trim(version, "0123456789") != "" {
+				return nil
+			}
+			return []string{version}
+		},
+	}
+}
@@ -0,0 +1,59 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeDerivers(t *testing.T) {
+	Release()
+	defer Release()
+	assert.NotNil(t, WithAttributeDerivers(AttributeDeriver{Key: "age_bucket"})(&internal.GlobalConfig{}))
+	ageBucket := AttributeDeriver{Key: "age_bucket", Derive: func(attributes map[string][]string) []string {
+		age, err := strconv.Atoi(attributes["age"][0]) // panics without the age, recovered
+		if err != nil {
+			return nil
+		}
+		if age < 18 {
+			return []string{"minor"}
+		}
+		return []string{"adult"}
+	}}
+	age := AttributeDeriver{Key: "age", Derive: func(attributes map[string][]string) []string {
+		if len(attributes["birth_year"]) == 0 {
+			return nil
+		}
+		year, err := strconv.Atoi(attributes["birth_year"][0])
+		if err != nil {
+			return nil
+		}
+		return []string{strconv.Itoa(2026 - year)}
+	}}
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient),
+		WithAttributeDerivers(age, ageBucket, DeriveMajorVersion("app_major_version", "app_version")))
+	assert.Nil(t, err)
+
+	tags := map[string][]string{"birth_year": {"2000"}, "app_version": {"v7.3.1"}}
+	userCtx := NewUserContext("12345", WithTags(tags)).(*userContext)
+	assert.Equal(t, []string{"26"}, userCtx.tags["age"])
+	assert.Equal(t, []string{"adult"}, userCtx.tags["age_bucket"]) // derived from the derived age
+	assert.Equal(t, []string{"7"}, userCtx.tags["app_major_version"])
+	assert.Len(t, tags, 2) // the attributes of the caller are not modified
+
+	userCtx = NewUserContext("12345", WithTagKV("age", "12"), WithTagKV("app_major_version", "8")).(*userContext)
+	assert.Equal(t, []string{"minor"}, userCtx.tags["age_bucket"])
+	assert.Equal(t, []string{"8"}, userCtx.tags["app_major_version"]) // passed by the caller
+
+	userCtx = NewUserContext("12345", WithTagKV("app_version", "beta")).(*userContext)
+	assert.Equal(t, map[string][]string{"app_version": {"beta"}}, userCtx.tags)
+	assert.Equal(t, "AttributeDeriver", RecentErrors()[0].Source)
+	_, err = userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,43 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// ErrBackpressure The exposure reporting is saturated, the exposure is not reported. The errors of the full or shed
+// queues wrap it, errors.Is(err, ErrBackpressure) tells them apart from the plugin failures
+var ErrBackpressure = errors.New("exposure reporting is saturated")
+
+// backpressureWatermark The fraction of the queue capacity above which the reporting is considered saturated
+const backpressureWatermark = 0.9
+
+// WithBackpressure set whether the Log* APIs of the projectID return ErrBackpressure without reporting while the
+// exposure reporting is saturated, see IsBackpressured, so that the request handlers can shed the load or degrade
+// the features instead of the exposures being dropped silently. Default false
+func WithBackpressure(isEnable bool) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		options.IsBackpressure = isEnable
+		return nil
+	}
+}
+
+// checkReport ErrReportDisabled if the exposure reporting of the projectID is disabled, otherwise see
+// checkBackpressure
+func checkReport(projectID string) error {
+	if internal.IsReportDisabled(projectID) {
+		return ErrReportDisabled
+	}
+	return checkBackpressure(projectID)
+}
+
+// checkBackpressure ErrBackpressure if enabled for the projectID and the reporting is saturated
+func checkBackpressure(projectID string) error {
+	if !internal.IsBackpressureEnabled(projectID) || !IsBackpressured(projectID) {
+		return nil
+	}
+	return ErrBackpressure
+}
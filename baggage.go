@@ -0,0 +1,111 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// baggageKeyPrefix The prefix of the baggage member key of the assignments of a projectID, see BaggageKey
+const baggageKeyPrefix = "abc-"
+
+// baggageGroupSeparator Separate the groupIDs of the member value, which is a valid baggage value octet
+const baggageGroupSeparator = "."
+
+// BaggageKey The key of the W3C baggage member carrying the assignments of the projectID
+func BaggageKey(projectID string) string {
+	return baggageKeyPrefix + projectID
+}
+
+// EncodeBaggage Encode the assignments of the list as the W3C baggage member of the projectID, such as
+// abc-123=100001001.200002001, so that the downstream services of the call chain see the same assignments without
+// re-evaluating, see DecodeBaggage. Only the groupIDs are carried, the groups built by the SDK, such as the default
+// system group, are left out
+func EncodeBaggage(projectID string, list *ExperimentList) (baggage.Member, error) {
+	var groupIDs []int64
+	if list != nil {
+		groupIDs = make([]int64, 0, len(list.Data))
+		for _, group := range list.Data {
+			if group != nil && group.ID > 0 {
+				groupIDs = append(groupIDs, group.ID)
+			}
+		}
+	}
+	sort.Slice(groupIDs, func(i, j int) bool { return groupIDs[i] < groupIDs[j] })
+	parts := make([]string, len(groupIDs))
+	for i, groupID := range groupIDs {
+		parts[i] = strconv.FormatInt(groupID, 10)
+	}
+	member, err := baggage.NewMember(BaggageKey(projectID), strings.Join(parts, baggageGroupSeparator))
+	if err != nil {
+		return baggage.Member{}, errors.Wrap(err, "newMember")
+	}
+	return member, nil
+}
+
+// DecodeBaggage Decode the assignments of the projectID from the W3C baggage, such as the baggage header.
+// The groups are resolved from the local cache of the projectID, the groups no longer in the configuration are
+// skipped. The exposures have been logged by the service encoding them, so the result can not be logged again:
+// LogExperimentsExposure ignores it. Return nil if the baggage does not carry the projectID
+func DecodeBaggage(projectID string, b baggage.Baggage) (*ExperimentList, error) {
+	member := b.Member(BaggageKey(projectID))
+	if len(member.Key()) == 0 {
+		return nil, nil
+	}
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, errors.Errorf("projectID [%s] config is not loaded", projectID)
+	}
+	result := &ExperimentList{Data: map[string]*Group{}, isPropagated: true}
+	for _, part := range strings.Split(member.Value(), baggageGroupSeparator) {
+		if len(part) == 0 {
+			continue
+		}
+		groupID, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid groupID %s", part)
+		}
+		template := cache.GetExposureTemplate(application, groupID)
+		if template == nil {
+			continue
+		}
+		layer, ok := application.LayerIndex[template.LayerKey]
+		if !ok || layer.GroupIndex[groupID] == nil {
+			continue
+		}
+		result.Data[template.LayerKey] = convertGroup2Experiment(&experiment.Experiment{
+			Group: layer.GroupIndex[groupID]})
+	}
+	return result, nil
+}
+
+// ContextWithBaggage Add the assignments of the list to the baggage of the ctx, which is propagated to the
+// downstream services by the OpenTelemetry baggage propagator, see EncodeBaggage
+func ContextWithBaggage(ctx context.Context, projectID string, list *ExperimentList) (context.Context, error) {
+	member, err := EncodeBaggage(projectID, list)
+	if err != nil {
+		return ctx, err
+	}
+	b, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, errors.Wrap(err, "setMember")
+	}
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// ExperimentsFromBaggage Decode the assignments of the projectID from the baggage of the ctx, see DecodeBaggage
+func ExperimentsFromBaggage(ctx context.Context, projectID string) (*ExperimentList, error) {
+	return DecodeBaggage(projectID, baggage.FromContext(ctx))
+}
@@ -0,0 +1,60 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestBaggage(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID)
+	assert.Nil(t, err)
+	ctx, err := ContextWithBaggage(context.Background(), projectID, list)
+	assert.Nil(t, err)
+
+	// the downstream service
+	header := baggage.FromContext(ctx).String()
+	b, err := baggage.Parse(header + ",other=1")
+	assert.Nil(t, err)
+	got, err := DecodeBaggage(projectID, b)
+	assert.Nil(t, err)
+	for layerKey, group := range list.Data {
+		if group.ID <= 0 {
+			assert.Nil(t, got.Data[layerKey])
+			continue
+		}
+		assert.Equal(t, group.ID, got.Data[layerKey].ID)
+		assert.Equal(t, group.params, got.Data[layerKey].params)
+	}
+	assert.Nil(t, LogExperimentsExposure(ctx, projectID, got)) // not logged again
+	got, err = ExperimentsFromBaggage(ctx, projectID)
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, len(got.Data))
+
+	got, err = ExperimentsFromBaggage(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.Nil(t, got)
+	_, err = ExperimentsFromBaggage(ctx, "notExist")
+	assert.Nil(t, err)
+	b, err = baggage.Parse(BaggageKey(projectID) + "=1.x")
+	assert.Nil(t, err)
+	_, err = DecodeBaggage(projectID, b)
+	assert.NotNil(t, err)
+	b, err = baggage.Parse(BaggageKey(projectID) + "=999.")
+	assert.Nil(t, err)
+	got, err = DecodeBaggage(projectID, b)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(got.Data))
+}
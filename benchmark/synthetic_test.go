@@ -0,0 +1,116 @@
+// Package benchmark ...
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	tab "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+)
+
+// syntheticProjectID The projectID served by the mock cache client
+const syntheticProjectID = "123"
+
+// syntheticSizes The synthetic configurations of the baselines, see README.md
+var syntheticSizes = []struct {
+	layers int
+	groups int
+	rules  int
+}{
+	{layers: 10, groups: 10, rules: 0},
+	{layers: 50, groups: 20, rules: 3},
+	{layers: 200, groups: 20, rules: 5},
+}
+
+func syntheticName(layers, groups, rules int) string {
+	return fmt.Sprintf("layers=%d/groups=%d/rules=%d", layers, groups, rules)
+}
+
+// syntheticUserContext The user hitting all the targeting rules of the synthetic groups
+func syntheticUserContext(unitID string, rules int) tab.Context {
+	var opts = make([]tab.Attribution, 0, rules)
+	for i := 0; i < rules; i++ {
+		opts = append(opts, tab.WithTagKV(testdata.SyntheticTagKey(i), testdata.SyntheticTagValue))
+	}
+	return tab.NewUserContext(unitID, opts...)
+}
+
+// BenchmarkSyntheticGetExperiments Evaluate all layers without exposure
+func BenchmarkSyntheticGetExperiments(b *testing.B) {
+	for _, size := range syntheticSizes {
+		size := size
+		b.Run(syntheticName(size.layers, size.groups, size.rules), func(b *testing.B) {
+			defer tab.Release()
+			err := tab.Init(context.Background(), []string{syntheticProjectID},
+				tab.WithRegisterCacheClient(testdata.MockSyntheticCacheClient(b, size.layers, size.groups, size.rules)),
+				tab.WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+			if err != nil {
+				b.Fatal(err)
+			}
+			userCtx := syntheticUserContext("12345", size.rules)
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				list, err := userCtx.GetExperiments(ctx, syntheticProjectID, tab.WithAutomatic(false))
+				if err != nil || len(list.Data) != size.layers {
+					b.Fatalf("GetExperiments fail:%v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSyntheticGetExperiment Evaluate one layer without exposure
+func BenchmarkSyntheticGetExperiment(b *testing.B) {
+	for _, size := range syntheticSizes {
+		size := size
+		b.Run(syntheticName(size.layers, size.groups, size.rules), func(b *testing.B) {
+			defer tab.Release()
+			err := tab.Init(context.Background(), []string{syntheticProjectID},
+				tab.WithRegisterCacheClient(testdata.MockSyntheticCacheClient(b, size.layers, size.groups, size.rules)),
+				tab.WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+			if err != nil {
+				b.Fatal(err)
+			}
+			userCtx := syntheticUserContext("12345", size.rules)
+			ctx := context.Background()
+			layerKey := testdata.SyntheticLayerKey(size.layers / 2)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				result, err := userCtx.GetExperiment(ctx, syntheticProjectID, layerKey, tab.WithAutomatic(false))
+				if err != nil || result == nil {
+					b.Fatalf("GetExperiment fail:%v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSyntheticRefresh Fetch and parse the whole configuration, the time of the local cache refresh
+func BenchmarkSyntheticRefresh(b *testing.B) {
+	defer func() {
+		client.RegisterCacheClient(nil)
+	}()
+	for _, size := range syntheticSizes {
+		size := size
+		b.Run(syntheticName(size.layers, size.groups, size.rules), func(b *testing.B) {
+			defer cache.Release()
+			client.RegisterCacheClient(testdata.MockSyntheticCacheClient(b, size.layers, size.groups, size.rules))
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Release() // parse from scratch instead of the incremental refresh
+				if _, err := cache.NewAndSetApplication(ctx, syntheticProjectID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// BlobPointerKey The only key of the JSON object of a remote config value pointing to a large object, such as
+// {"$blob":{"url":"https://cdn.example.com/model.bin","sha256":"...","size":1048576}}
+const BlobPointerKey = internal.BlobPointerKey
+
+// BlobPointer The pointer to a large object stored outside the configuration
+type BlobPointer = internal.BlobPointer
+
+// BlobFetcher Fetch the content of the url of the large object, see WithBlobFetcher
+type BlobFetcher = internal.BlobFetcher
+
+// ErrBlobIntegrity The content of the large object does not match the checksum or the size of its pointer
+var ErrBlobIntegrity = internal.ErrBlobIntegrity
+
+// WithBlobFetcher set how the large objects of the remote configs are fetched, such as from the object storage with
+// the credentials. The content is verified against the checksum of the pointer after fetching.
+// An http GET by default
+func WithBlobFetcher(fetcher BlobFetcher) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if fetcher == nil {
+			return errors.Errorf("fetcher is required")
+		}
+		config.BlobFetcher = fetcher
+		return nil
+	}
+}
+
+// WithBlobLimits set the maximum size of a large object and the maximum total size of the cached large objects, the
+// least recently accessed ones are evicted beyond it. Zero uses the default 64MB and 256MB
+func WithBlobLimits(maxSize int64, cacheSize int64) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if maxSize < 0 || cacheSize < 0 {
+			return errors.Errorf("invalid blob limits:%v,%v", maxSize, cacheSize)
+		}
+		config.MaxBlobSize = maxSize
+		config.BlobCacheSize = cacheSize
+		return nil
+	}
+}
+
+// resolveBlob The content of the large object if the remote config value is a blob pointer, fetched and cached on
+// the first access, with the original value as the pointer. Otherwise the value itself and a nil pointer
+func resolveBlob(ctx context.Context, data []byte) ([]byte, []byte, error) {
+	pointer, ok := internal.ParseBlobPointer(data)
+	if !ok {
+		return data, nil, nil
+	}
+	content, err := internal.FetchBlob(ctx, pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, data, nil
+}
+
+// exposedValue The value reported in the exposures and the events, the pointer instead of the large object
+func (c *Config) exposedValue() string {
+	if c.blobPointer != nil {
+		return string(c.blobPointer)
+	}
+	return string(c.data)
+}
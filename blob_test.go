@@ -0,0 +1,56 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveBlob(t *testing.T) {
+	defer Release()
+	content := []byte(`{"weights":[0.1,0.2,0.3]}`)
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		if r.URL.Path == "/tampered" {
+			_, _ = w.Write([]byte("tampered"))
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+	assert.NotNil(t, WithBlobFetcher(nil)(&internal.GlobalConfig{}))
+	assert.NotNil(t, WithBlobLimits(-1, 0)(&internal.GlobalConfig{}))
+	sum := sha256.Sum256(content)
+	pointer := []byte(fmt.Sprintf(`{"%s":{"url":"%s/model","sha256":"%s"}}`, BlobPointerKey, server.URL,
+		hex.EncodeToString(sum[:])))
+	for i := 0; i < 2; i++ {
+		data, original, err := resolveBlob(context.Background(), pointer)
+		assert.Nil(t, err)
+		assert.Equal(t, content, data)
+		config := &Config{Value: &Value{data: data}, blobPointer: original}
+		assert.Equal(t, 0.2, config.MustGetJSONMap()["weights"].([]interface{})[1])
+		assert.Equal(t, string(pointer), config.exposedValue()) // the exposures carry the pointer
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	data, original, err := resolveBlob(context.Background(), []byte("plain"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("plain"), data)
+	assert.Nil(t, original)
+
+	tampered := []byte(fmt.Sprintf(`{"%s":{"url":"%s/tampered","sha256":"%s"}}`, BlobPointerKey, server.URL,
+		hex.EncodeToString(make([]byte, sha256.Size))))
+	_, _, err = resolveBlob(context.Background(), tampered)
+	assert.True(t, errors.Is(err, ErrBlobIntegrity))
+}
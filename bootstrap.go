@@ -0,0 +1,222 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/pkg/errors"
+)
+
+// BootstrapFormatVersion The version of the bootstrap payload format, bumped on the incompatible changes
+const BootstrapFormatVersion = 1
+
+// Bootstrap The assignments and the feature flag values of a unit, generated by the server through GenerateBootstrap
+// and hydrated by the web and mobile clients without their own evaluation. The field names are short to keep the
+// payload compact
+type Bootstrap struct {
+	// Format version, see BootstrapFormatVersion
+	FormatVersion int    `json:"v"`
+	ProjectID     string `json:"p"`
+	UnitID        string `json:"u"`
+	// The version of the configuration the assignments are evaluated on
+	ConfigVersion string `json:"cv,omitempty"`
+	// Unix seconds of the generation
+	IssuedAt int64 `json:"iat"`
+	// Unix seconds after which the payload should not be used, 0 if it never expires, see WithBootstrapTTL
+	ExpiresAt int64 `json:"exp,omitempty"`
+	// key is layerKey
+	Experiments map[string]*BootstrapGroup `json:"e,omitempty"`
+	// key is the feature flag key
+	FeatureFlags map[string]*BootstrapFeatureFlag `json:"f,omitempty"`
+}
+
+// BootstrapGroup The group a unit gets in a layer
+type BootstrapGroup struct {
+	ID            int64             `json:"id"`
+	Key           string            `json:"k"`
+	ExperimentKey string            `json:"x,omitempty"`
+	IsControl     bool              `json:"c,omitempty"`
+	IsDefault     bool              `json:"d,omitempty"`
+	Params        map[string]string `json:"pa,omitempty"`
+}
+
+// BootstrapFeatureFlag The value of a feature flag a unit gets
+type BootstrapFeatureFlag struct {
+	Value string `json:"v"`
+	// The group of the experiment bound to the feature flag, 0 if none
+	GroupID   int64 `json:"g,omitempty"`
+	IsDefault bool  `json:"d,omitempty"`
+}
+
+// signedBootstrap The signed envelope, the signature is the HMAC-SHA256 of the raw payload bytes
+type signedBootstrap struct {
+	Payload   json.RawMessage `json:"d"`
+	Signature string          `json:"s"`
+}
+
+// BootstrapOption GenerateBootstrap related options
+type BootstrapOption func(o *bootstrapOptions)
+
+type bootstrapOptions struct {
+	signingKey []byte
+	ttl        time.Duration
+	// The options of the evaluation, the exposures are never logged automatically
+	experimentOptions []ExperimentOption
+}
+
+// WithBootstrapSigningKey The key of the HMAC-SHA256 signature, the secretKey of the projectID by default
+func WithBootstrapSigningKey(key []byte) BootstrapOption {
+	return func(o *bootstrapOptions) {
+		o.signingKey = key
+	}
+}
+
+// WithBootstrapTTL Mark the payload expired after the ttl, the clients fall back to their defaults or to a new
+// payload once expired
+func WithBootstrapTTL(ttl time.Duration) BootstrapOption {
+	return func(o *bootstrapOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithBootstrapExperimentOptions The options of the evaluation, such as WithSceneIDList to limit the layers
+func WithBootstrapExperimentOptions(opts ...ExperimentOption) BootstrapOption {
+	return func(o *bootstrapOptions) {
+		o.experimentOptions = append(o.experimentOptions, opts...)
+	}
+}
+
+// GenerateBootstrap Evaluate all layers and feature flags of the projectID for the user and return the compact, signed
+// and versioned JSON for the web and mobile clients to hydrate, see Bootstrap. The exposures are not logged, the
+// clients log them once the assignments are actually used
+func GenerateBootstrap(ctx context.Context, projectID string, userCtx Context,
+	opts ...BootstrapOption) ([]byte, error) {
+	options := &bootstrapOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	signingKey := options.signingKey
+	if len(signingKey) == 0 {
+		signingKey = []byte(internal.SecretKey(projectID))
+	}
+	if len(signingKey) == 0 {
+		return nil, errors.Errorf("signing key of [%s] is required", projectID)
+	}
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	experimentOptions := make([]ExperimentOption, 0, len(options.experimentOptions)+1)
+	experimentOptions = append(experimentOptions, options.experimentOptions...)
+	experimentOptions = append(experimentOptions, WithAutomatic(false))
+	list, err := userCtx.GetExperiments(ctx, projectID, experimentOptions...)
+	if err != nil {
+		return nil, errors.Wrap(err, "getExperiments")
+	}
+	now := time.Now()
+	bootstrap := &Bootstrap{
+		FormatVersion: BootstrapFormatVersion,
+		ProjectID:     projectID,
+		ConfigVersion: application.Version,
+		IssuedAt:      now.Unix(),
+	}
+	if options.ttl > 0 {
+		bootstrap.ExpiresAt = now.Add(options.ttl).Unix()
+	}
+	if list != nil {
+		if list.userCtx != nil {
+			bootstrap.UnitID = list.userCtx.unitID
+		}
+		for layerKey, group := range list.Data {
+			if group == nil {
+				continue
+			}
+			if bootstrap.Experiments == nil {
+				bootstrap.Experiments = make(map[string]*BootstrapGroup, len(list.Data))
+			}
+			bootstrap.Experiments[layerKey] = &BootstrapGroup{
+				ID:            group.ID,
+				Key:           group.Key,
+				ExperimentKey: group.ExperimentKey,
+				IsControl:     group.IsControl,
+				IsDefault:     group.IsDefault,
+				Params:        group.Params(),
+			}
+		}
+	}
+	for _, key := range featureFlagKeys(application) {
+		featureFlag, err := userCtx.GetFeatureFlag(ctx, projectID, key, experimentOptions...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getFeatureFlag [%s]", key)
+		}
+		if featureFlag == nil || featureFlag.ConfigResult == nil || featureFlag.Config == nil {
+			continue
+		}
+		if bootstrap.FeatureFlags == nil {
+			bootstrap.FeatureFlags = map[string]*BootstrapFeatureFlag{}
+		}
+		value := &BootstrapFeatureFlag{IsDefault: featureFlag.IsDefault}
+		if featureFlag.Value != nil {
+			value.Value = featureFlag.Config.String()
+		}
+		if featureFlag.Experiment != nil {
+			value.GroupID = featureFlag.Experiment.ID
+		}
+		bootstrap.FeatureFlags[key] = value
+	}
+	payload, err := json.Marshal(bootstrap)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal")
+	}
+	return json.Marshal(&signedBootstrap{Payload: payload, Signature: signBootstrap(signingKey, payload)})
+}
+
+// VerifyBootstrap Verify the signature of the payload generated by GenerateBootstrap and decode it, such as when the
+// clients send the payload back. The expiration is not checked, see Bootstrap.ExpiresAt
+func VerifyBootstrap(data []byte, signingKey []byte) (*Bootstrap, error) {
+	envelope := &signedBootstrap{}
+	if err := json.Unmarshal(data, envelope); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	expected := signBootstrap(signingKey, envelope.Payload)
+	if !hmac.Equal([]byte(expected), []byte(envelope.Signature)) {
+		return nil, errors.Errorf("invalid signature")
+	}
+	bootstrap := &Bootstrap{}
+	if err := json.Unmarshal(envelope.Payload, bootstrap); err != nil {
+		return nil, errors.Wrap(err, "unmarshal payload")
+	}
+	if bootstrap.FormatVersion != BootstrapFormatVersion {
+		return nil, errors.Errorf("unsupported bootstrap format version %d", bootstrap.FormatVersion)
+	}
+	return bootstrap, nil
+}
+
+func signBootstrap(key []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// featureFlagKeys The sorted keys of the feature flags of the application
+func featureFlagKeys(application *cache.Application) []string {
+	if application.TabConfig == nil || application.TabConfig.ConfigData == nil {
+		return nil
+	}
+	result := make([]string, 0, len(application.TabConfig.ConfigData.RemoteConfigIndex))
+	for key := range application.TabConfig.ConfigData.RemoteConfigIndex {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
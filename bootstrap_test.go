@@ -0,0 +1,67 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateBootstrap(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithSecretKey("secret"))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	data, err := GenerateBootstrap(context.Background(), projectID, userCtx, WithBootstrapTTL(time.Hour),
+		WithBootstrapExperimentOptions(WithIsDisableDMP(true)))
+	assert.Nil(t, err)
+
+	bootstrap, err := VerifyBootstrap(data, []byte("secret"))
+	assert.Nil(t, err)
+	assert.Equal(t, BootstrapFormatVersion, bootstrap.FormatVersion)
+	assert.Equal(t, projectID, bootstrap.ProjectID)
+	assert.Equal(t, "12345", bootstrap.UnitID)
+	assert.Equal(t, bootstrap.IssuedAt+3600, bootstrap.ExpiresAt)
+	experiment, err := userCtx.GetExperiment(context.Background(), projectID, "overrideLayer",
+		WithAutomatic(false), WithIsDisableDMP(true))
+	assert.Nil(t, err)
+	assert.Equal(t, experiment.ID, bootstrap.Experiments["overrideLayer"].ID)
+	assert.Equal(t, experiment.Params(), bootstrap.Experiments["overrideLayer"].Params)
+	featureFlag, err := userCtx.GetFeatureFlag(context.Background(), projectID, "remoteConfig1",
+		WithAutomatic(false), WithIsDisableDMP(true))
+	assert.Nil(t, err)
+	assert.Equal(t, featureFlag.String(), bootstrap.FeatureFlags["remoteConfig1"].Value)
+
+	_, err = VerifyBootstrap(data, []byte("other"))
+	assert.NotNil(t, err)
+	tampered := &signedBootstrap{}
+	assert.Nil(t, json.Unmarshal(data, tampered))
+	tampered.Payload = json.RawMessage(`{"v":1,"p":"123","u":"54321"}`)
+	data, err = json.Marshal(tampered)
+	assert.Nil(t, err)
+	_, err = VerifyBootstrap(data, []byte("secret"))
+	assert.NotNil(t, err)
+
+	// the bound client
+	c, err := NewClient(projectID, WithClientExperimentOptions(WithIsDisableDMP(true)))
+	assert.Nil(t, err)
+	data, err = c.GenerateBootstrap(context.Background(), c.NewUserContext("12345"),
+		WithBootstrapSigningKey([]byte("key")))
+	assert.Nil(t, err)
+	got, err := VerifyBootstrap(data, []byte("key"))
+	assert.Nil(t, err)
+	// evaluated in the random order of its groups
+	delete(bootstrap.Experiments, "doubleHashLayerCityTag")
+	delete(got.Experiments, "doubleHashLayerCityTag")
+	assert.Equal(t, bootstrap.Experiments, got.Experiments)
+	assert.Equal(t, bootstrap.FeatureFlags, got.FeatureFlags)
+
+	_, err = GenerateBootstrap(context.Background(), "unknown", userCtx)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,53 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/hashutil"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// HashMethod The hash function of the bucketing, see BucketSpec
+type HashMethod = protoccacheserver.HashMethod
+
+// BucketSpec The hashing parameters of a layer, a domain or a double-hash experiment, all SDK languages must assign
+// the same bucket under the same parameters:
+//
+//	source = salt + unitID                        // bytes, salt is empty unless the unitID type is salted
+//	bucket = hash(HashMethod, source, Seed) % BucketSize + 1
+//
+// The hashes are those of github.com/abetterchoice/hashutil, BKDR is the only seeded one, and the unknown methods,
+// MURMUR3 included, fall back to BKDR. The result is in [1, BucketSize]
+type BucketSpec struct {
+	HashMethod HashMethod `json:"hashMethod"`
+	Seed       int64      `json:"seed"`
+	BucketSize int64      `json:"bucketSize"`
+}
+
+// ComputeBucket Compute the bucket of the unitID under the spec, the salt is the one of the unitID type, see
+// WithUnitIDType. It is the reference implementation of the bucketing, checked against the cross-language vectors
+// in testdata/bucket_vectors.json
+func ComputeBucket(unitID string, spec BucketSpec, salt string) (int64, error) {
+	if spec.BucketSize <= 0 {
+		return 0, errors.Errorf("invalid bucket size %d", spec.BucketSize)
+	}
+	return hashutil.GetBucketNum(spec.HashMethod, salt+unitID, spec.Seed, spec.BucketSize), nil
+}
+
+// GetLayerBucketSpec Get the hashing parameters of the layer in the local cache of the projectID, see ComputeBucket
+func GetLayerBucketSpec(projectID string, layerKey string) (*BucketSpec, error) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	layer, ok := application.LayerIndex[layerKey]
+	if !ok || layer.Metadata == nil {
+		return nil, errors.Wrapf(ErrLayerNotFound, "layerKey [%s]", layerKey)
+	}
+	return &BucketSpec{HashMethod: layer.Metadata.HashMethod, Seed: layer.Metadata.HashSeed,
+		BucketSize: layer.Metadata.BucketSize}, nil
+}
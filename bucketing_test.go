@@ -0,0 +1,62 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+// bucketVector A case of the cross-language bucketing vectors, the SDKs of the other languages run the same file
+type bucketVector struct {
+	UnitID     string `json:"unitId"`
+	Salt       string `json:"salt"`
+	HashMethod string `json:"hashMethod"`
+	Seed       int64  `json:"seed"`
+	BucketSize int64  `json:"bucketSize"`
+	Bucket     int64  `json:"bucket"`
+}
+
+func TestComputeBucketVectors(t *testing.T) {
+	body, err := os.ReadFile("testdata/bucket_vectors.json")
+	assert.Nil(t, err)
+	var file struct {
+		Vectors []*bucketVector `json:"vectors"`
+	}
+	assert.Nil(t, json.Unmarshal(body, &file))
+	assert.NotEmpty(t, file.Vectors)
+	for _, vector := range file.Vectors {
+		method, ok := protoccacheserver.HashMethod_value[vector.HashMethod]
+		assert.True(t, ok, vector.HashMethod)
+		bucket, err := ComputeBucket(vector.UnitID, BucketSpec{HashMethod: HashMethod(method), Seed: vector.Seed,
+			BucketSize: vector.BucketSize}, vector.Salt)
+		assert.Nil(t, err)
+		assert.Equal(t, vector.Bucket, bucket, "%+v", vector)
+	}
+	_, err = ComputeBucket("12345", BucketSpec{}, "")
+	assert.NotNil(t, err)
+}
+
+func TestGetLayerBucketSpec(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	spec, err := GetLayerBucketSpec(projectID, "overrideLayer")
+	assert.Nil(t, err)
+	assert.Less(t, int64(0), spec.BucketSize)
+	bucket, err := ComputeBucket("12345", *spec, "")
+	assert.Nil(t, err)
+	assert.True(t, bucket >= 1 && bucket <= spec.BucketSize)
+	_, err = GetLayerBucketSpec(projectID, "notFound")
+	assert.True(t, errors.Is(err, ErrLayerNotFound))
+	_, err = GetLayerBucketSpec("notFound", "overrideLayer")
+	assert.NotNil(t, err)
+}
@@ -7,6 +7,8 @@ package abc
 import (
 	"context"
 	"fmt"
+
+	"github.com/abetterchoice/go-sdk/internal"
 )
 
 // Context // This interface offers the primary APIs for retrieving the results of experiment splitting and
@@ -39,6 +41,11 @@ type Context interface {
 	// Instead, you may need to use the exposure logging API to manually log the exposures.
 	GetExperiments(ctx context.Context, projectID string, opts ...ExperimentOption) (*ExperimentList, error)
 
+	// GetExperimentsByKeyPattern evaluates all layers whose key matches the glob pattern in one call, such as
+	// "recs.*" for the layers created dynamically per surface, see WithLayerKeyPattern
+	GetExperimentsByKeyPattern(ctx context.Context, projectID string, pattern string,
+		opts ...ExperimentOption) (*ExperimentList, error)
+
 	// GetFeatureFlag evaluates the feature flag with the specified key within the given project.
 	// The unit ID is extracted from the provided context.
 	// Users can use the strongly-typed parameter retrieval APIs of the FeatureFlag object
@@ -108,6 +115,9 @@ type userContext struct {
 	// This information will be logged as an additional field in the exposure table,
 	// in a format similar to k1=v1; k1=v2.
 	expandedData map[string]string
+
+	// The well-known typed fields of the extended details, see WithAppVersion
+	extension extensionFields
 }
 
 // Attribution Pass in each option as needed, including but not limited to setting label information, etc.
@@ -122,6 +132,7 @@ type Attribution func(c *userContext)
 //	NewUserContext("123456xA").GetExperiment(context.TODO(), "layerKey_AABB")
 //
 // The web platform supports whitelisting. If the unitID is in the whitelist, it will take effect.
+// The attributes are then derived by the derivers of WithAttributeDerivers.
 func NewUserContext(unitID string, opts ...Attribution) Context {
 	userCtx := &userContext{
 		unitID: unitID,
@@ -130,12 +141,13 @@ func NewUserContext(unitID string, opts ...Attribution) Context {
 	for _, opt := range opts {
 		opt(userCtx)
 	}
+	userCtx.tags = internal.DeriveAttributes(userCtx.tags)
 	return settingNewUnitIDAndNewDecisionID(userCtx)
 }
 
 func settingNewUnitIDAndNewDecisionID(userCtx *userContext) *userContext {
 	if len(userCtx.unitID) == 0 { // The exposure logging ID cannot be empty
-		userCtx.err = fmt.Errorf("unitID is required")
+		userCtx.err = fmt.Errorf("unitID is required: %w", ErrInvalidUnitID)
 		return userCtx
 	}
 	if len(userCtx.decisionID) == 0 { // if the traffic splitting ID is empty, the exposure logging ID will be used
@@ -74,6 +74,26 @@ func (mr *MockContextMockRecorder) GetExperiments(ctx, projectID interface{}, op
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExperiments", reflect.TypeOf((*MockContext)(nil).GetExperiments), varargs...)
 }
 
+// GetExperimentsByKeyPattern mocks base method.
+func (m *MockContext) GetExperimentsByKeyPattern(ctx context.Context, projectID, pattern string, opts ...ExperimentOption) (*ExperimentList, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, projectID, pattern}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetExperimentsByKeyPattern", varargs...)
+	ret0, _ := ret[0].(*ExperimentList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExperimentsByKeyPattern indicates an expected call of GetExperimentsByKeyPattern.
+func (mr *MockContextMockRecorder) GetExperimentsByKeyPattern(ctx, projectID, pattern interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, projectID, pattern}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExperimentsByKeyPattern", reflect.TypeOf((*MockContext)(nil).GetExperimentsByKeyPattern), varargs...)
+}
+
 // GetFeatureFlag mocks base method.
 func (m *MockContext) GetFeatureFlag(ctx context.Context, projectID, key string, opts ...ConfigOption) (*FeatureFlag, error) {
 	m.ctrl.T.Helper()
@@ -29,7 +29,7 @@ func TestNewUserContext(t *testing.T) {
 				opts:   nil,
 			},
 			want: &userContext{
-				err:  fmt.Errorf("unitID is required"),
+				err:  fmt.Errorf("unitID is required: %w", ErrInvalidUnitID),
 				tags: map[string][]string{},
 			},
 		},
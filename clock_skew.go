@@ -0,0 +1,31 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// NowAttributeKey The attribute key of the current unix seconds for the time-window targeting, such as a number tag
+// of the key in a closed-open interval configured on the platform. The SDK fills it with the time corrected by the
+// clock skew unless it is passed by WithTagKV
+const NowAttributeKey = internal.NowAttributeKey
+
+// WithClockSkewTolerance set the tolerance of the clock skew. The offset of the local clock is estimated from the Date
+// headers of the control plane responses in the NTP fashion, once it exceeds the tolerance, the time-window targeting
+// of NowAttributeKey and the signatures of the control plane requests use the corrected time, so that the drifted
+// hosts still serve the scheduled windows on time. The Date header has the precision of a second, a tolerance of a
+// few seconds is recommended. Zero disables the correction, the skew is still estimated, see GetClockSkew
+func WithClockSkewTolerance(tolerance time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if tolerance < 0 {
+			return errors.Errorf("invalid tolerance:%v", tolerance)
+		}
+		config.ClockSkewTolerance = tolerance
+		return nil
+	}
+}
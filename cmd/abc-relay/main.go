@@ -0,0 +1,117 @@
+// Command abc-relay Serve the config snapshots of the projects from a single poller of the control plane to the SDK
+// instances of a fleet, and accept their forwarded exposure batches, so that the load on the control plane does not
+// grow with the number of instances.
+//
+// Usage:
+//
+//	abc-relay -projects 123,456 -secret xxx [-listen :8080] [-grpc :9090] [-snapshot-dir /var/lib/abc-relay]
+//	          [-key-file key]
+//
+// The instances reach the relay as the cache service, see abc.WithRelay, and forward the exposures to the gRPC
+// address through plugin/metrics/forward.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/pkg/errors"
+)
+
+// envSecretKey The environment variable of the secret key if -secret is not set, which keeps it out of the process list
+const envSecretKey = "ABC_SECRET_KEY"
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stderr))
+}
+
+// run Serve the relay of the args until ctx is done, the exit code is returned
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	cfg, err := parseConfig(args, stderr)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "abc-relay: %v\n", err)
+		return 2
+	}
+	r := newRelay(cfg, client.NewTABCacheClient(client.WithEnvType(cfg.envType)), stderr)
+	if err = r.serve(ctx); err != nil {
+		fmt.Fprintf(stderr, "abc-relay: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// config The flags of the relay
+type config struct {
+	listen          string
+	grpcListen      string
+	projectIDList   []string
+	secretKey       string
+	envType         string
+	snapshotDir     string
+	persistInterval time.Duration
+	retryInterval   time.Duration
+	// the AES key of the persisted snapshots, nil persists them in plaintext
+	key []byte
+}
+
+func parseConfig(args []string, output io.Writer) (*config, error) {
+	flags := flag.NewFlagSet("abc-relay", flag.ContinueOnError)
+	flags.SetOutput(output)
+	cfg := &config{}
+	projects := flags.String("projects", "", "comma separated projectIDs to relay")
+	flags.StringVar(&cfg.listen, "listen", ":8080", "address of the config snapshots served to the instances")
+	flags.StringVar(&cfg.grpcListen, "grpc", ":9090", "address of the forwarded exposure batches, empty to disable")
+	flags.StringVar(&cfg.secretKey, "secret", os.Getenv(envSecretKey),
+		"secret key of the projects, also required from the instances, defaults to $"+envSecretKey)
+	flags.StringVar(&cfg.envType, "env", env.TypePrd, "environment of the control plane")
+	flags.StringVar(&cfg.snapshotDir, "snapshot-dir", "",
+		"dir to persist the snapshots, served on restart until the control plane is reached, empty to disable")
+	keyFile := flags.String("key-file", "",
+		"file of the AES key encrypting the persisted snapshots, in hex, base64 or raw, empty to disable")
+	flags.DurationVar(&cfg.persistInterval, "persist-interval", time.Minute, "interval of persisting the snapshots")
+	flags.DurationVar(&cfg.retryInterval, "retry-interval", 30*time.Second,
+		"interval of retrying the control plane if it is not reached on start")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	for _, projectID := range strings.Split(*projects, ",") {
+		if projectID = strings.TrimSpace(projectID); len(projectID) > 0 {
+			cfg.projectIDList = append(cfg.projectIDList, projectID)
+		}
+	}
+	if len(cfg.projectIDList) == 0 {
+		return nil, errors.Errorf("-projects is required")
+	}
+	if len(cfg.listen) == 0 {
+		return nil, errors.Errorf("-listen is required")
+	}
+	if len(*keyFile) > 0 {
+		text, err := ioutil.ReadFile(*keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.key, err = abc.ParseEncryptionKey(text); err != nil {
+			return nil, errors.Wrap(err, *keyFile)
+		}
+	}
+	if cfg.persistInterval <= 0 || cfg.retryInterval <= 0 {
+		return nil, errors.Errorf("-persist-interval and -retry-interval should be positive")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelay(t *testing.T) {
+	cfg := &config{projectIDList: []string{"123"}, secretKey: "secret", snapshotDir: t.TempDir()}
+	r := newRelay(cfg, testdata.MockCacheClient(t), ioutil.Discard)
+	assert.Nil(t, r.init(context.Background()))
+	server := httptest.NewServer(r.handler())
+	defer server.Close()
+
+	// the instances reach the relay as the cache service, with the secret key of the SDK in this process
+	downstream := client.NewTABCacheClient(client.WithAddr(server.URL))
+	resp, err := downstream.GetTabConfigData(context.Background(), &protoctabcacheserver.GetTabConfigReq{
+		ProjectId: "123"})
+	assert.Nil(t, err)
+	assert.Equal(t, protoctabcacheserver.Code_CODE_SUCCESS, resp.Code)
+	version := resp.TabConfigManager.Version
+	assert.Equal(t, snapshotVersion(r.snapshot("123")), version)
+	_, err = downstream.BatchGetExperimentBucketInfo(context.Background(),
+		&protoctabcacheserver.BatchGetExperimentBucketReq{ProjectId: "123"})
+	assert.Nil(t, err)
+	_, err = downstream.GetTabConfigData(context.Background(), &protoctabcacheserver.GetTabConfigReq{ProjectId: "456"})
+	assert.NotNil(t, err) // not relayed
+
+	// without the token
+	httpResp, err := http.Post(server.URL+"/opensource.tab.cache_server.APIServer/GetTabConfig",
+		"application/x-protobuf", bytes.NewReader(nil))
+	assert.Nil(t, err)
+	httpResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, httpResp.StatusCode)
+	httpResp, err = http.Get(server.URL + snapshotPath + "123")
+	assert.Nil(t, err)
+	httpResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, httpResp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+snapshotPath+"123", nil)
+	req.Header.Set(client.KeyToken, "secret")
+	httpResp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	data, _ := ioutil.ReadAll(httpResp.Body)
+	httpResp.Body.Close()
+	snapshot, err := client.UnmarshalSnapshot(data)
+	assert.Nil(t, err)
+	assert.Equal(t, version, snapshotVersion(snapshot))
+	httpResp, err = http.Get(server.URL + "/healthz")
+	assert.Nil(t, err)
+	httpResp.Body.Close()
+	assert.Equal(t, http.StatusOK, httpResp.StatusCode)
+
+	r.persist()
+	abc.Release()
+	_, err = ioutil.ReadFile(filepath.Join(cfg.snapshotDir, "123.json"))
+	assert.Nil(t, err)
+
+	// the next run serves the persisted snapshot until the control plane is reached
+	restarted := newRelay(cfg, nil, ioutil.Discard)
+	assert.Nil(t, restarted.load())
+	assert.Equal(t, version, snapshotVersion(restarted.snapshot("123")))
+	recorded := httptest.NewRecorder()
+	restarted.handler().ServeHTTP(recorded, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorded.Code)
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig([]string{"-projects", " 123, ,456", "-secret", "s", "-grpc", ""}, ioutil.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"123", "456"}, cfg.projectIDList)
+	assert.Equal(t, ":8080", cfg.listen)
+	assert.Empty(t, cfg.grpcListen)
+	_, err = parseConfig(nil, ioutil.Discard)
+	assert.EqualError(t, err, "-projects is required")
+	assert.Equal(t, 0, run(context.Background(), []string{"-h"}, ioutil.Discard))
+	assert.Equal(t, 2, run(context.Background(), []string{"-projects", "123", "-persist-interval", "0s"},
+		ioutil.Discard))
+}
+
+func TestRelayEncryption(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	cfg := &config{projectIDList: []string{"123"}, snapshotDir: t.TempDir(), key: key}
+	r := newRelay(cfg, testdata.MockCacheClient(t), ioutil.Discard)
+	assert.Nil(t, r.init(context.Background()))
+	r.persist()
+	abc.Release()
+	data, err := ioutil.ReadFile(filepath.Join(cfg.snapshotDir, "123.json"))
+	assert.Nil(t, err)
+	assert.True(t, abc.IsEncrypted(data))
+
+	assert.Nil(t, newRelay(cfg, nil, ioutil.Discard).load())
+	plaintext := &config{projectIDList: cfg.projectIDList, snapshotDir: cfg.snapshotDir}
+	assert.NotNil(t, newRelay(plaintext, nil, ioutil.Discard).load())
+	wrongKey := &config{projectIDList: cfg.projectIDList, snapshotDir: cfg.snapshotDir, key: key[:16]}
+	assert.NotNil(t, newRelay(wrongKey, nil, ioutil.Discard).load())
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/plugin/metrics/forward"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// snapshotPath The path of the snapshots served by the relay, followed by the projectID
+const snapshotPath = "/v1/snapshots/"
+
+// shutdownTimeout The timeout of the in-flight requests once ctx is done
+const shutdownTimeout = 5 * time.Second
+
+// relay The SDK polling the control plane for the projects, and the cache service serving the last responses of the
+// poller to the instances. Until a project is fetched, the snapshot persisted by the previous run is served.
+// Concurrent and safe
+type relay struct {
+	cfg      *config
+	recorder *client.RecordingClient
+	stderr   io.Writer
+
+	lock  sync.RWMutex
+	saved map[string]*client.Snapshot
+	// the versions of the tab config persisted, a snapshot is persisted again only once the version changes
+	persisted map[string]string
+}
+
+// newRelay Create the relay polling upstream, which is the cache service of the control plane, logged to stderr
+func newRelay(cfg *config, upstream client.Client, stderr io.Writer) *relay {
+	return &relay{
+		cfg:       cfg,
+		recorder:  client.NewRecordingClient(upstream),
+		stderr:    stderr,
+		saved:     map[string]*client.Snapshot{},
+		persisted: map[string]string{},
+	}
+}
+
+func (r *relay) logf(format string, args ...interface{}) {
+	fmt.Fprintf(r.stderr, "abc-relay: "+format+"\n", args...)
+}
+
+// serve Poll the control plane and serve the instances until ctx is done
+func (r *relay) serve(ctx context.Context) error {
+	if err := r.load(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() { // the SDK is released once the retries and the persists are done
+		cancel()
+		wg.Wait()
+		abc.Release()
+	}()
+	if err := r.init(ctx); err != nil {
+		if len(r.saved) == 0 {
+			return err
+		}
+		r.logf("%v, serving the saved snapshots, retry in %v", err, r.cfg.retryInterval)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.retryInit(ctx)
+		}()
+	}
+	server := &http.Server{Addr: r.cfg.listen, Handler: r.handler()}
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- errors.Wrap(server.ListenAndServe(), "http")
+	}()
+	if len(r.cfg.grpcListen) > 0 {
+		go func() {
+			errCh <- errors.Wrap(forward.ListenAndServe(ctx, r.cfg.grpcListen), "grpc")
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.persistLoop(ctx)
+	}()
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errCh:
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	_ = server.Shutdown(shutdownCtx)
+	r.persist()
+	return err
+}
+
+// init Init the SDK polling the projects, the projects failed to load keep retrying in the background
+func (r *relay) init(ctx context.Context) error {
+	err := abc.Init(ctx, r.cfg.projectIDList, abc.WithSecretKey(r.cfg.secretKey), abc.WithEnvType(r.cfg.envType),
+		abc.WithRegisterCacheClient(r.recorder), abc.WithPartialInit(true))
+	if initErr, ok := err.(*abc.InitError); ok && len(initErr.Loaded) > 0 {
+		r.logf("%v", initErr)
+		return nil
+	}
+	return errors.Wrap(err, "init")
+}
+
+func (r *relay) retryInit(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := r.init(ctx)
+		if err == nil {
+			return
+		}
+		r.logf("%v, retry in %v", err, r.cfg.retryInterval)
+	}
+}
+
+// snapshot The last responses of the poller for the projectID, or the saved snapshot until the project is fetched
+func (r *relay) snapshot(projectID string) *client.Snapshot {
+	if snapshot := r.recorder.Snapshot(projectID); snapshot != nil {
+		return snapshot
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.saved[projectID]
+}
+
+func (r *relay) client(projectID string) (client.Client, error) {
+	snapshot := r.snapshot(projectID)
+	if snapshot == nil {
+		return nil, errors.Errorf("projectID [%s] is not loaded", projectID)
+	}
+	return client.NewSnapshotClient(snapshot), nil
+}
+
+// GetTabConfigData Implement client.Client, the same version responses are served for the up to date instances
+func (r *relay) GetTabConfigData(ctx context.Context, req *protoctabcacheserver.GetTabConfigReq) (
+	*protoctabcacheserver.GetTabConfigResp, error) {
+	c, err := r.client(req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetTabConfigData(ctx, req)
+}
+
+// BatchGetExperimentBucketInfo Implement client.Client
+func (r *relay) BatchGetExperimentBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetExperimentBucketReq) (*protoctabcacheserver.BatchGetExperimentBucketResp, error) {
+	c, err := r.client(req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	return c.BatchGetExperimentBucketInfo(ctx, req)
+}
+
+// BatchGetGroupBucketInfo Implement client.Client
+func (r *relay) BatchGetGroupBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetGroupBucketReq) (*protoctabcacheserver.BatchGetGroupBucketResp, error) {
+	c, err := r.client(req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	return c.BatchGetGroupBucketInfo(ctx, req)
+}
+
+// authorize The instances send the secret key of the relay as the token, like to the cache service
+func (r *relay) authorize(req *http.Request, projectID string) error {
+	if len(r.cfg.secretKey) == 0 {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Header.Get(client.KeyToken)), []byte(r.cfg.secretKey)) != 1 {
+		return errors.Errorf("invalid token of projectID [%s]", projectID)
+	}
+	return nil
+}
+
+// handler The protocol of the cache service, the snapshots in the format of abcctl and the health check, which
+// fails until all the projects are loaded
+func (r *relay) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", client.NewHandler(r, r.authorize))
+	mux.HandleFunc(snapshotPath, r.serveSnapshot)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+		for _, projectID := range r.cfg.projectIDList {
+			if r.snapshot(projectID) == nil {
+				http.Error(w, fmt.Sprintf("projectID [%s] is not loaded", projectID), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	return mux
+}
+
+func (r *relay) serveSnapshot(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	projectID := strings.TrimPrefix(req.URL.Path, snapshotPath)
+	if err := r.authorize(req, projectID); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	snapshot := r.snapshot(projectID)
+	if snapshot == nil {
+		http.Error(w, fmt.Sprintf("projectID [%s] is not loaded", projectID), http.StatusNotFound)
+		return
+	}
+	data, err := client.MarshalSnapshot(snapshot)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func (r *relay) snapshotFile(projectID string) string {
+	return filepath.Join(r.cfg.snapshotDir, projectID+".json")
+}
+
+// load Load the snapshots persisted by the previous run, the projects never persisted are skipped
+func (r *relay) load() error {
+	if len(r.cfg.snapshotDir) == 0 {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, projectID := range r.cfg.projectIDList {
+		data, err := ioutil.ReadFile(r.snapshotFile(projectID))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		snapshot, err := r.decodeSnapshot(data)
+		if err != nil {
+			return errors.Wrap(err, r.snapshotFile(projectID))
+		}
+		r.saved[projectID] = snapshot
+		r.persisted[projectID] = snapshotVersion(snapshot)
+	}
+	return nil
+}
+
+func (r *relay) persistLoop(ctx context.Context) {
+	if len(r.cfg.snapshotDir) == 0 {
+		return
+	}
+	ticker := time.NewTicker(r.cfg.persistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.persist()
+		}
+	}
+}
+
+// persist Persist the snapshots fetched since the last persist, written to a temporary file then renamed so that a
+// crash never leaves a truncated snapshot
+func (r *relay) persist() {
+	if len(r.cfg.snapshotDir) == 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, projectID := range r.cfg.projectIDList {
+		snapshot := r.recorder.Snapshot(projectID)
+		if version, ok := r.persisted[projectID]; snapshot == nil || ok && version == snapshotVersion(snapshot) {
+			continue
+		}
+		if err := r.writeSnapshot(r.snapshotFile(projectID), snapshot); err != nil {
+			r.logf("persist projectID [%s]: %v", projectID, err)
+			continue
+		}
+		r.persisted[projectID] = snapshotVersion(snapshot)
+	}
+}
+
+// decodeSnapshot Decode the persisted snapshot, decrypted by the key of -key-file if set
+func (r *relay) decodeSnapshot(data []byte) (*client.Snapshot, error) {
+	var err error
+	if r.cfg.key != nil {
+		if data, err = abc.Decrypt(r.cfg.key, data); err != nil {
+			return nil, err
+		}
+	} else if abc.IsEncrypted(data) {
+		return nil, errors.Errorf("snapshot is encrypted, -key-file is required")
+	}
+	return client.UnmarshalSnapshot(data)
+}
+
+// writeSnapshot Write the snapshot, encrypted by the key of -key-file if set
+func (r *relay) writeSnapshot(path string, snapshot *client.Snapshot) error {
+	data, err := client.MarshalSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	if r.cfg.key != nil {
+		if data, err = abc.Encrypt(r.cfg.key, data); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func snapshotVersion(snapshot *client.Snapshot) string {
+	return snapshot.TabConfig.GetTabConfigManager().GetVersion()
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// runDiff Print the layers, groups and feature flags added, removed or changed from the old snapshot to the new one
+func runDiff(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	keyFile := flags.String("key-file", "", keyFileUsage)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errors.Errorf("want the old and the new snapshot files")
+	}
+	key, err := readKey(*keyFile)
+	if err != nil {
+		return err
+	}
+	var applications [2]*cache.Application
+	for i, path := range flags.Args() {
+		snapshot, err := readSnapshot(path, key)
+		if err != nil {
+			return err
+		}
+		if applications[i], err = loadApplication(ctx, snapshot); err != nil {
+			return errors.Wrap(err, path)
+		}
+	}
+	lines := diffApplication(applications[0], applications[1])
+	if len(lines) == 0 {
+		_, err := fmt.Fprintln(stdout, "no difference")
+		return err
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffApplication The differences of the local caches, one line per difference prefixed by +, - or ~,
+// in the order of the keys
+func diffApplication(before *cache.Application, after *cache.Application) []string {
+	var lines []string
+	for _, layerKey := range unionKeys(layerKeys(before), layerKeys(after)) {
+		oldLayer, newLayer := before.LayerIndex[layerKey], after.LayerIndex[layerKey]
+		switch {
+		case oldLayer == nil:
+			lines = append(lines, "+ layer "+layerKey)
+		case newLayer == nil:
+			lines = append(lines, "- layer "+layerKey)
+		default:
+			lines = append(lines, diffLayer(layerKey, before, oldLayer, after, newLayer)...)
+		}
+	}
+	oldConfigs, newConfigs := remoteConfigIndex(before), remoteConfigIndex(after)
+	for _, key := range unionKeys(configKeys(oldConfigs), configKeys(newConfigs)) {
+		oldConfig, newConfig := oldConfigs[key], newConfigs[key]
+		switch {
+		case oldConfig == nil:
+			lines = append(lines, "+ feature flag "+key)
+		case newConfig == nil:
+			lines = append(lines, "- feature flag "+key)
+		case oldConfig.Version != newConfig.Version:
+			lines = append(lines, fmt.Sprintf("~ feature flag %s version %s -> %s", key, oldConfig.Version,
+				newConfig.Version))
+		}
+	}
+	return lines
+}
+
+func diffLayer(layerKey string, before *cache.Application, oldLayer *protoctabcacheserver.Layer,
+	after *cache.Application, newLayer *protoctabcacheserver.Layer) []string {
+	groupIDs := map[int64]bool{}
+	for groupID := range oldLayer.GroupIndex {
+		groupIDs[groupID] = true
+	}
+	for groupID := range newLayer.GroupIndex {
+		groupIDs[groupID] = true
+	}
+	sortedIDs := make([]int64, 0, len(groupIDs))
+	for groupID := range groupIDs {
+		sortedIDs = append(sortedIDs, groupID)
+	}
+	sort.Slice(sortedIDs, func(i, j int) bool {
+		return sortedIDs[i] < sortedIDs[j]
+	})
+	var lines []string
+	for _, groupID := range sortedIDs {
+		oldGroup, newGroup := oldLayer.GroupIndex[groupID], newLayer.GroupIndex[groupID]
+		switch {
+		case oldGroup == nil:
+			lines = append(lines, fmt.Sprintf("+ group %s/%s (%d)", layerKey, newGroup.GroupKey, groupID))
+		case newGroup == nil:
+			lines = append(lines, fmt.Sprintf("- group %s/%s (%d)", layerKey, oldGroup.GroupKey, groupID))
+		default:
+			name := fmt.Sprintf("%s/%s (%d)", layerKey, newGroup.GroupKey, groupID)
+			lines = append(lines, diffGroup(name, oldGroup, newGroup)...)
+			if !equalBucket(before.GroupIDBucketInfoIndex[groupID], after.GroupIDBucketInfoIndex[groupID]) {
+				lines = append(lines, fmt.Sprintf("~ group %s bucket", name))
+			}
+		}
+	}
+	return lines
+}
+
+func diffGroup(name string, before *protoctabcacheserver.Group, after *protoctabcacheserver.Group) []string {
+	var lines []string
+	if before.GroupKey != after.GroupKey {
+		lines = append(lines, fmt.Sprintf("~ group %s key %s -> %s", name, before.GroupKey, after.GroupKey))
+	}
+	if before.ExperimentKey != after.ExperimentKey {
+		lines = append(lines, fmt.Sprintf("~ group %s experiment %s -> %s", name, before.ExperimentKey,
+			after.ExperimentKey))
+	}
+	if before.IsDefault != after.IsDefault || before.IsControl != after.IsControl {
+		lines = append(lines, fmt.Sprintf("~ group %s default %v -> %v, control %v -> %v", name, before.IsDefault,
+			after.IsDefault, before.IsControl, after.IsControl))
+	}
+	for _, key := range unionKeys(paramKeys(before.Params), paramKeys(after.Params)) {
+		oldValue, oldOK := before.Params[key]
+		newValue, newOK := after.Params[key]
+		switch {
+		case !oldOK:
+			lines = append(lines, fmt.Sprintf("~ group %s param %s + %q", name, key, newValue))
+		case !newOK:
+			lines = append(lines, fmt.Sprintf("~ group %s param %s - %q", name, key, oldValue))
+		case oldValue != newValue:
+			lines = append(lines, fmt.Sprintf("~ group %s param %s %q -> %q", name, key, oldValue, newValue))
+		}
+	}
+	return lines
+}
+
+// equalBucket Whether the buckets cover the same traffic, the versions are not compared
+func equalBucket(before *protoctabcacheserver.BucketInfo, after *protoctabcacheserver.BucketInfo) bool {
+	if before == nil || after == nil {
+		return before == after
+	}
+	if before.BucketType != after.BucketType || !bytes.Equal(before.Bitmap, after.Bitmap) {
+		return false
+	}
+	if before.TrafficRange == nil || after.TrafficRange == nil {
+		return before.TrafficRange == after.TrafficRange
+	}
+	return before.TrafficRange.Left == after.TrafficRange.Left &&
+		before.TrafficRange.Right == after.TrafficRange.Right
+}
+
+func remoteConfigIndex(application *cache.Application) map[string]*protoctabcacheserver.RemoteConfig {
+	if application.TabConfig == nil || application.TabConfig.ConfigData == nil {
+		return nil
+	}
+	return application.TabConfig.ConfigData.RemoteConfigIndex
+}
+
+func layerKeys(application *cache.Application) []string {
+	keys := make([]string, 0, len(application.LayerIndex))
+	for key := range application.LayerIndex {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func configKeys(index map[string]*protoctabcacheserver.RemoteConfig) []string {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func paramKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// unionKeys The sorted keys in either list
+func unionKeys(a []string, b []string) []string {
+	set := make(map[string]bool, len(a)+len(b))
+	for _, key := range a {
+		set[key] = true
+	}
+	for _, key := range b {
+		set[key] = true
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
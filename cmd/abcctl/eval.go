@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"strings"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/pkg/errors"
+)
+
+// tagFlags The repeated -tag k=v flags, the values of the same key are accumulated
+type tagFlags map[string][]string
+
+// String see flag.Value
+func (t tagFlags) String() string {
+	return ""
+}
+
+// Set see flag.Value
+func (t tagFlags) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i <= 0 {
+		return errors.Errorf("invalid tag %q, want k=v", value)
+	}
+	t[value[:i]] = append(t[value[:i]], value[i+1:])
+	return nil
+}
+
+// evalGroup The group printed by eval
+type evalGroup struct {
+	ID            int64             `json:"id"`
+	Key           string            `json:"key"`
+	ExperimentKey string            `json:"experimentKey"`
+	IsDefault     bool              `json:"isDefault"`
+	IsControl     bool              `json:"isControl"`
+	Params        map[string]string `json:"params"`
+}
+
+// runEval Print the groups of the unitID by layerKey, all layers if -layer is not set
+func runEval(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	path := flags.String("snapshot", "", "snapshot file saved by fetch")
+	unitID := flags.String("unit", "", "unitID to evaluate")
+	layerKey := flags.String("layer", "", "layerKey to evaluate, all layers if empty")
+	keyFile := flags.String("key-file", "", keyFileUsage)
+	tags := tagFlags{}
+	flags.Var(tags, "tag", "attribute of the unit as k=v, repeatable")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*path) == 0 || len(*unitID) == 0 {
+		return errors.Errorf("-snapshot and -unit are required")
+	}
+	key, err := readKey(*keyFile)
+	if err != nil {
+		return err
+	}
+	snapshot, err := readSnapshot(*path, key)
+	if err != nil {
+		return err
+	}
+	if err = initSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	defer abc.Release()
+	groups, err := evaluate(ctx, snapshot.ProjectID, *unitID, *layerKey, tags)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(groups)
+}
+
+// evaluate The groups of the unitID in the layers of the initialized project, keyed by layerKey.
+// The dmp tags are not evaluated since the unit is not known by the dmp service
+func evaluate(ctx context.Context, projectID string, unitID string, layerKey string,
+	tags map[string][]string) (map[string]*evalGroup, error) {
+	userCtx := abc.NewUserContext(unitID, abc.WithTags(tags))
+	opts := []abc.ExperimentOption{abc.WithAutomatic(false), abc.WithIsDisableDMP(true)}
+	result := map[string]*evalGroup{}
+	if len(layerKey) > 0 {
+		experiment, err := userCtx.GetExperiment(ctx, projectID, layerKey, opts...)
+		if err != nil {
+			return nil, errors.Wrap(err, "getExperiment")
+		}
+		if experiment != nil && experiment.Group != nil {
+			result[layerKey] = newEvalGroup(experiment.Group)
+		}
+		return result, nil
+	}
+	list, err := userCtx.GetExperiments(ctx, projectID, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "getExperiments")
+	}
+	for key, group := range list.Data {
+		result[key] = newEvalGroup(group)
+	}
+	return result, nil
+}
+
+func newEvalGroup(group *abc.Group) *evalGroup {
+	return &evalGroup{ID: group.ID, Key: group.Key, ExperimentKey: group.ExperimentKey,
+		IsDefault: group.IsDefault, IsControl: group.IsControl, Params: group.Params()}
+}
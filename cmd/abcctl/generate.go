@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"io/ioutil"
+
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/pkg/errors"
+)
+
+// runGenerate Save the snapshot of the synthetic configuration, the input of the load tests and the fuzzing
+func runGenerate(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	projectID := flags.String("project", "synthetic", "projectID of the snapshot")
+	spec := testdata.SyntheticSpec{}
+	flags.IntVar(&spec.Layers, "layers", 10, "number of the layers")
+	flags.IntVar(&spec.Groups, "groups", 4, "number of the groups of each layer")
+	flags.IntVar(&spec.Rules, "rules", 0, "number of the targeting rules of each group")
+	flags.IntVar(&spec.Scenes, "scenes", 0, "number of the scenes the layers are bound to")
+	flags.IntVar(&spec.FeatureFlags, "flags", 0, "number of the feature flags")
+	flags.Int64Var(&spec.Seed, "seed", 0, "seed of the random rules and traffic, 0 for the fixed shape")
+	output := flags.String("o", "", "file to save the snapshot, stdout if empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if spec.Layers < 0 || spec.Groups < 1 || spec.Rules < 0 || spec.Scenes < 0 || spec.FeatureFlags < 0 {
+		return errors.Errorf("invalid shape %+v", spec)
+	}
+	data, err := client.MarshalSnapshot(testdata.SyntheticSnapshot(*projectID, spec))
+	if err != nil {
+		return err
+	}
+	if len(*output) == 0 {
+		_, err = stdout.Write(append(data, '\n'))
+		return err
+	}
+	return ioutil.WriteFile(*output, data, 0644)
+}
@@ -0,0 +1,61 @@
+// Command abcctl Evaluate the experiments of a config snapshot locally, with the same evaluation code as the SDK.
+//
+// Usage:
+//
+//	abcctl fetch -project 123 -secret xxx -o 123.json [-key-file key]
+//	abcctl eval -snapshot 123.json -unit u1 [-layer layerKey] [-tag k=v ...] [-key-file key]
+//	abcctl diff [-key-file key] old.json new.json
+//	abcctl generate -layers 100 -groups 10 -rules 5 -o synthetic.json
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `abcctl evaluates the experiments of a config snapshot locally.
+
+Commands:
+  fetch    save the config snapshot of a project from the control plane
+  eval     print the groups a unitID gets in the snapshot
+  diff     print the differences of two snapshots
+  generate save the snapshot of a synthetic configuration for the load tests
+
+Run "abcctl <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run Run the command of the args, the exit code is returned
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "fetch":
+		err = runFetch(ctx, args[1:], stdout)
+	case "eval":
+		err = runEval(ctx, args[1:], stdout)
+	case "diff":
+		err = runDiff(ctx, args[1:], stdout)
+	case "generate":
+		err = runGenerate(ctx, args[1:], stdout)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "abcctl %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeSnapshot Record the snapshot of the test data and save it into the dir
+func writeSnapshot(t *testing.T, dir string, name string,
+	modify func(snapshot *client.Snapshot)) string {
+	recorder := client.NewRecordingClient(testdata.MockCacheClient(t))
+	err := abc.Init(context.Background(), []string{"123"}, abc.WithRegisterCacheClient(recorder),
+		abc.WithDisableReport(true))
+	assert.Nil(t, err)
+	abc.Release()
+	data, err := client.MarshalSnapshot(recorder.Snapshot("123"))
+	assert.Nil(t, err)
+	if modify != nil { // decoded into a copy, the test data is shared
+		snapshot, err := client.UnmarshalSnapshot(data)
+		assert.Nil(t, err)
+		modify(snapshot)
+		data, err = client.MarshalSnapshot(snapshot)
+		assert.Nil(t, err)
+	}
+	path := filepath.Join(dir, name)
+	assert.Nil(t, ioutil.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestEval(t *testing.T) {
+	path := writeSnapshot(t, t.TempDir(), "123.json", nil)
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, run(context.Background(), []string{"eval", "-snapshot", path, "-unit", "u1"},
+		&stdout, &stderr), stderr.String())
+	all := map[string]*evalGroup{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &all))
+	assert.NotEmpty(t, all)
+
+	for layerKey, group := range all {
+		if layerKey == "doubleHashLayerCityTag" { // evaluated in the random order of its groups
+			continue
+		}
+		stdout.Reset()
+		assert.Equal(t, 0, run(context.Background(), []string{"eval", "-snapshot", path, "-unit", "u1",
+			"-layer", layerKey}, &stdout, &stderr), stderr.String())
+		one := map[string]*evalGroup{}
+		assert.Nil(t, json.Unmarshal(stdout.Bytes(), &one))
+		assert.Equal(t, group, one[layerKey])
+	}
+	assert.Equal(t, 1, run(context.Background(), []string{"eval", "-snapshot", path}, &stdout, &stderr))
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	before := writeSnapshot(t, dir, "before.json", nil)
+	after := writeSnapshot(t, dir, "after.json", func(snapshot *client.Snapshot) {
+		configData := snapshot.TabConfig.TabConfigManager.TabConfig.ConfigData
+		configData.RemoteConfigIndex["abcctl_flag"] = &protoctabcacheserver.RemoteConfig{Key: "abcctl_flag"}
+	})
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, run(context.Background(), []string{"diff", before, before}, &stdout, &stderr),
+		stderr.String())
+	assert.Equal(t, "no difference\n", stdout.String())
+	stdout.Reset()
+	assert.Equal(t, 0, run(context.Background(), []string{"diff", before, after}, &stdout, &stderr),
+		stderr.String())
+	assert.Equal(t, "+ feature flag abcctl_flag\n", stdout.String())
+	assert.Equal(t, 1, run(context.Background(), []string{"diff", before}, &stdout, &stderr))
+}
+
+func TestDiffEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	path := writeSnapshot(t, dir, "123.json", nil)
+	key := bytes.Repeat([]byte{7}, 32)
+	keyFile := filepath.Join(dir, "key")
+	assert.Nil(t, ioutil.WriteFile(keyFile, []byte(hex.EncodeToString(key)), 0600))
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	encrypted, err := abc.Encrypt(key, data)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(path, encrypted, 0644))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 1, run(context.Background(), []string{"diff", path, path}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-key-file is required")
+	assert.Equal(t, 0, run(context.Background(), []string{"diff", "-key-file", keyFile, path, path}, &stdout,
+		&stderr), stderr.String())
+	assert.Equal(t, "no difference\n", stdout.String())
+}
+
+func TestDiffGroup(t *testing.T) {
+	before := &protoctabcacheserver.Group{GroupKey: "g", ExperimentKey: "e", Params: map[string]string{
+		"a": "1", "b": "2"}}
+	after := &protoctabcacheserver.Group{GroupKey: "g", ExperimentKey: "e", IsControl: true,
+		Params: map[string]string{"b": "3", "c": "4"}}
+	assert.Equal(t, []string{
+		"~ group l/g (1) default false -> false, control false -> true",
+		`~ group l/g (1) param a - "1"`,
+		`~ group l/g (1) param b "2" -> "3"`,
+		`~ group l/g (1) param c + "4"`,
+	}, diffGroup("l/g (1)", before, after))
+	assert.Empty(t, diffGroup("l/g (1)", before, before))
+}
+
+func TestGenerate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synthetic.json")
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, run(context.Background(), []string{"generate", "-layers", "3", "-groups", "2",
+		"-rules", "1", "-o", path}, &stdout, &stderr), stderr.String())
+	assert.Equal(t, 0, run(context.Background(), []string{"eval", "-snapshot", path, "-unit", "u1",
+		"-tag", testdata.SyntheticTagKey(0) + "=" + testdata.SyntheticTagValue}, &stdout, &stderr), stderr.String())
+	groups := map[string]*evalGroup{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &groups))
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, 1, run(context.Background(), []string{"generate", "-groups", "0"}, &stdout, &stderr))
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 2, run(context.Background(), nil, &stdout, &stderr))
+	assert.Equal(t, 2, run(context.Background(), []string{"unknown"}, &stdout, &stderr))
+	assert.Equal(t, 0, run(context.Background(), []string{"help"}, &stdout, &stderr))
+}
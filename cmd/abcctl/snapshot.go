@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"io/ioutil"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/pkg/errors"
+)
+
+// runFetch Load the project from the control plane like Init, then save the responses as the snapshot
+func runFetch(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	projectID := flags.String("project", "", "projectID to fetch")
+	secretKey := flags.String("secret", "", "secret key of the project")
+	envType := flags.String("env", env.TypePrd, "environment of the control plane")
+	output := flags.String("o", "", "file to save the snapshot, stdout if empty")
+	keyFile := flags.String("key-file", "", keyFileUsage+", the snapshot is saved encrypted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*projectID) == 0 {
+		return errors.Errorf("-project is required")
+	}
+	key, err := readKey(*keyFile)
+	if err != nil {
+		return err
+	}
+	recorder := client.NewRecordingClient(client.NewTABCacheClient(client.WithEnvType(*envType)))
+	err = abc.Init(ctx, []string{*projectID}, abc.WithSecretKey(*secretKey), abc.WithEnvType(*envType),
+		abc.WithRegisterCacheClient(recorder), abc.WithDisableReport(true))
+	if err != nil {
+		return errors.Wrap(err, "init")
+	}
+	defer abc.Release()
+	snapshot := recorder.Snapshot(*projectID)
+	if snapshot == nil {
+		return errors.Errorf("projectID [%s] is not fetched", *projectID)
+	}
+	data, err := client.MarshalSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		if data, err = abc.Encrypt(key, data); err != nil {
+			return errors.Wrap(err, "encrypt")
+		}
+	}
+	if len(*output) == 0 {
+		_, err = stdout.Write(append(data, '\n'))
+		return err
+	}
+	return ioutil.WriteFile(*output, data, 0644)
+}
+
+// keyFileUsage The usage of the -key-file flag of the commands reading or writing the snapshots
+const keyFileUsage = "file of the AES key of the encrypted snapshots, in hex, base64 or raw"
+
+// readKey The key of the key file, nil if path is empty
+func readKey(path string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := abc.ParseEncryptionKey(text)
+	return key, errors.Wrap(err, path)
+}
+
+// readSnapshot Read the snapshot file, decrypted by the key if not nil
+func readSnapshot(path string, key []byte) (*client.Snapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		if data, err = abc.Decrypt(key, data); err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+	} else if abc.IsEncrypted(data) {
+		return nil, errors.Errorf("%s is encrypted, -key-file is required", path)
+	}
+	snapshot, err := client.UnmarshalSnapshot(data)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return snapshot, nil
+}
+
+// initSnapshot Init the SDK serving the snapshot, nothing is reported. Release must be called once done
+func initSnapshot(ctx context.Context, snapshot *client.Snapshot) error {
+	err := abc.Init(ctx, []string{snapshot.ProjectID}, abc.WithRegisterCacheClient(client.NewSnapshotClient(snapshot)),
+		abc.WithDisableReport(true))
+	return errors.Wrap(err, "init")
+}
+
+// loadApplication The local cache built from the snapshot, which outlives the SDK released afterwards
+func loadApplication(ctx context.Context, snapshot *client.Snapshot) (*cache.Application, error) {
+	if err := initSnapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	defer abc.Release()
+	application := cache.GetApplication(snapshot.ProjectID)
+	if application == nil {
+		return nil, errors.Errorf("projectID [%s] is not loaded", snapshot.ProjectID)
+	}
+	return application, nil
+}
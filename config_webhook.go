@@ -0,0 +1,100 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+// ConfigChange The configuration revision applied to the local cache, passed to the callback of WithOnConfigApplied
+type ConfigChange = internal.ConfigChange
+
+// defaultWebhookTimeout The default timeout of a webhook request
+const defaultWebhookTimeout = 5 * time.Second
+
+// WebhookOption NewConfigWebhook related options
+type WebhookOption func(w *configWebhook)
+
+// WithWebhookHTTPClient The http client of the webhook requests, http.DefaultClient by default
+func WithWebhookHTTPClient(client *http.Client) WebhookOption {
+	return func(w *configWebhook) {
+		w.client = client
+	}
+}
+
+// WithWebhookHeader Add the header to the webhook requests, such as the authorization
+func WithWebhookHeader(key string, value string) WebhookOption {
+	return func(w *configWebhook) {
+		w.header.Add(key, value)
+	}
+}
+
+// WithWebhookTimeout The timeout of a webhook request, 5 seconds by default
+func WithWebhookTimeout(timeout time.Duration) WebhookOption {
+	return func(w *configWebhook) {
+		w.timeout = timeout
+	}
+}
+
+type configWebhook struct {
+	url     string
+	client  *http.Client
+	header  http.Header
+	timeout time.Duration
+}
+
+// NewConfigWebhook Create the callback of WithOnConfigApplied posting the ConfigChange as JSON to the url.
+// The request is sent in its own goroutine so that the refresh is not blocked, the failures are logged and not retried
+func NewConfigWebhook(url string, opts ...WebhookOption) func(change *ConfigChange) {
+	w := &configWebhook{url: url, client: http.DefaultClient, header: http.Header{},
+		timeout: defaultWebhookTimeout}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return func(change *ConfigChange) {
+		go func() {
+			if err := w.post(change); err != nil {
+				log.LimitedErrorf("configWebhook"+change.ProjectID, "[projectID=%v]post config webhook fail:%v",
+					change.ProjectID, err)
+			}
+		}()
+	}
+}
+
+func (w *configWebhook) post(change *ConfigChange) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "newRequest")
+	}
+	for key, values := range w.header {
+		request.Header[key] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := w.client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "do")
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfigWebhook(t *testing.T) {
+	changes := make(chan *ConfigChange, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		change := &ConfigChange{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(change))
+		changes <- change
+	}))
+	defer server.Close()
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient),
+		WithOnConfigApplied(NewConfigWebhook(server.URL, WithWebhookHeader("Authorization", "Bearer token"),
+			WithWebhookHTTPClient(server.Client()), WithWebhookTimeout(time.Second))))
+	assert.Nil(t, err)
+	revision, err := GetConfigRevision(projectID)
+	assert.Nil(t, err)
+	select {
+	case change := <-changes: // the first load
+		assert.Equal(t, projectID, change.ProjectID)
+		assert.Equal(t, revision.Revision, change.Revision)
+		assert.Empty(t, change.PreviousRevision)
+		assert.Contains(t, change.ChangedLayerKeys, "overrideLayer")
+		assert.Contains(t, change.ChangedFeatureFlagKeys, "remoteConfig1")
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+}
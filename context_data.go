@@ -0,0 +1,63 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// ContextExtractor Extract the values carried by the ctx, such as the trace ID and the tenant ID, see
+// WithContextExtractor
+type ContextExtractor = internal.ContextExtractor
+
+// WithContextExtractor Register an extractor of the values of the ctx, the values are merged into the ExtraData of
+// the exposures and the ExtInfo of the monitor events produced under the ctx, that is, the ctx of the evaluation
+// for the automatic exposures and the ctx of LogExperimentsExposure and the like for the manual ones. The values are
+// extracted when the exposures are produced, before they are queued. The keys set by WithExpandedData, the extensions
+// and the SDK, such as new_id and event_id, take precedence. Can be called multiple times, the later extractors
+// override the keys of the earlier ones
+func WithContextExtractor(extractor ContextExtractor) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if extractor == nil {
+			return errors.Errorf("extractor is required")
+		}
+		config.ContextExtractors = append(config.ContextExtractors, extractor)
+		return nil
+	}
+}
+
+// withContextData The list carrying the values extracted from the ctx, see internal.ContextData. A shallow copy so
+// that the list returned to the caller is not modified, the list itself if there is nothing extracted
+func withContextData(list *ExperimentList, data map[string]string) *ExperimentList {
+	if list == nil || len(data) == 0 {
+		return list
+	}
+	return &ExperimentList{userCtx: list.userCtx, Data: list.Data, isPropagated: list.isPropagated, contextData: data}
+}
+
+// withConfigContextData The config carrying the values extracted from the ctx, see withContextData
+func withConfigContextData(config *ConfigResult, data map[string]string) *ConfigResult {
+	if config == nil || len(data) == 0 {
+		return config
+	}
+	return &ConfigResult{userCtx: config.userCtx, Config: config.Config, contextData: data}
+}
+
+// mergeContextData Add the values extracted from the ctx to the data without overriding the existing keys, the data
+// is modified, nil if both are empty
+func mergeContextData(data map[string]string, contextData map[string]string) map[string]string {
+	if len(contextData) == 0 {
+		return data
+	}
+	if data == nil {
+		data = make(map[string]string, len(contextData))
+	}
+	for key, value := range contextData {
+		if _, ok := data[key]; !ok {
+			data[key] = value
+		}
+	}
+	return data
+}
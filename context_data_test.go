@@ -0,0 +1,88 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+type traceIDKey struct{}
+
+func TestContextExtractor(t *testing.T) {
+	Release()
+	defer Release()
+	assert.NotNil(t, WithContextExtractor(nil)(&internal.GlobalConfig{}))
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient),
+		WithContextExtractor(func(ctx context.Context) map[string]string {
+			traceID, _ := ctx.Value(traceIDKey{}).(string)
+			return map[string]string{"trace_id": traceID, "tenant": "t1", newIDKey: "ignored"}
+		}),
+		WithContextExtractor(func(ctx context.Context) map[string]string {
+			panic("extractor")
+		}),
+		WithContextExtractor(func(ctx context.Context) map[string]string {
+			return map[string]string{"tenant": "t2"}
+		}))
+	assert.Nil(t, err)
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "trace1")
+	assert.Equal(t, map[string]string{"trace_id": "trace1", "tenant": "t2", newIDKey: "ignored"},
+		internal.ContextData(ctx))
+
+	userCtx := NewUserContext("12345", WithNewUnitID("user1"))
+	list, err := userCtx.GetExperiments(ctx, projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Nil(t, list.contextData)
+	withData := withContextData(list, internal.ContextData(ctx))
+	assert.Nil(t, list.contextData)
+	_, exposures := convertExperimentList(projectID, withData, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL, nil)
+	assert.NotEqual(t, 0, len(exposures.Exposures))
+	for _, exposure := range exposures.Exposures {
+		assert.Equal(t, "trace1", exposure.ExtraData["trace_id"])
+		assert.Equal(t, "t2", exposure.ExtraData["tenant"])
+		assert.Equal(t, "user1", exposure.ExtraData[newIDKey])
+	}
+	_, exposures = convertExperimentList(projectID, list, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL, nil)
+	assert.NotContains(t, exposures.Exposures[0].ExtraData, "trace_id")
+	assert.Equal(t, list, withContextData(list, nil))
+
+	config := withConfigContextData(&ConfigResult{userCtx: userCtx.(*userContext), Config: &Config{Value: &Value{},
+		remoteConfig: &protoc_cache_server.RemoteConfig{}}}, internal.ContextData(ctx))
+	data := convertRemoteConfig(projectID, config, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
+	assert.Equal(t, "new_id=user1;tenant=t2;trace_id=trace1", data[10])
+	assert.Nil(t, withConfigContextData(nil, internal.ContextData(ctx)))
+	assert.Nil(t, LogExperimentsExposure(ctx, projectID, list))
+}
+
+func TestSystemMetadata(t *testing.T) {
+	Release()
+	defer Release()
+	defer env.RegisterSystemMetadataProvider(nil)
+	env.RegisterSystemMetadataProvider(env.SystemMetadataProviderFunc(func() map[string]string {
+		return map[string]string{env.MetadataKeyPodName: "pod-1", env.MetadataKeyAvailabilityZone: "zone-a",
+			newIDKey: "ignored"}
+	}))
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	list, err := NewUserContext("12345", WithNewUnitID("user1")).GetExperiments(context.Background(), projectID,
+		WithAutomatic(false))
+	assert.Nil(t, err)
+	_, exposures := convertExperimentList(projectID, list, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL, nil)
+	assert.NotEqual(t, 0, len(exposures.Exposures))
+	for _, exposure := range exposures.Exposures {
+		assert.Equal(t, "pod-1", exposure.ExtraData[env.MetadataKeyPodName])
+		assert.Equal(t, "zone-a", exposure.ExtraData[env.MetadataKeyAvailabilityZone])
+		assert.Equal(t, "user1", exposure.ExtraData[newIDKey]) // the keys of the SDK take precedence
+	}
+}
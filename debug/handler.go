@@ -0,0 +1,156 @@
+// Package debug Optional http.Handler exposing the runtime state of the SDK, such as the cached configuration summary,
+// the assignments of a unitID, the exposure pipeline statistics and the recent errors.
+// It is intended to be mounted under an internal admin route and has no authentication, for example:
+//
+//	http.Handle("/debug/abc/", debug.NewHandler())
+//
+// The endpoint is selected by the last path segment, so it can be mounted under any prefix:
+//
+//	/config?projectID=xxx                          summary of the cached configuration, all projects if projectID is empty
+//	/assignments?projectID=xxx&unitID=yyy          assignments of every layer, exposures are not logged
+//	/explain?projectID=xxx&unitID=yyy&layerKey=zzz evaluation trace of the layer
+//	/exposure                                      exposure pipeline statistics
+//	/errors                                        recent errors
+//	/tasks                                         background goroutines
+//	/audit                                         runtime mutations, the oldest first
+//	/dump                                          the whole diagnostics bundle, see abc.Dump
+//
+// assignments and explain accept the optional newUnitID and the repeated tag=key:value parameters
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/pkg/errors"
+)
+
+// endpoints Endpoint names, listed by the index
+var endpoints = []string{"config", "assignments", "explain", "exposure", "errors", "tasks", "audit", "dump"}
+
+type handler struct{}
+
+// NewHandler Create the debug handler, the SDK should be initialized through abc.Init. Concurrent and safe
+func NewHandler() http.Handler {
+	return &handler{}
+}
+
+// ServeHTTP Implement http.Handler
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	switch path.Base(r.URL.Path) {
+	case "config":
+		h.config(w, r)
+	case "assignments":
+		h.assignments(w, r)
+	case "explain":
+		h.explain(w, r)
+	case "exposure":
+		writeJSON(w, http.StatusOK, abc.GetExposureStats())
+	case "errors":
+		writeJSON(w, http.StatusOK, abc.RecentErrors())
+	case "tasks":
+		writeJSON(w, http.StatusOK, abc.Tasks())
+	case "audit":
+		writeJSON(w, http.StatusOK, abc.AuditLog())
+	case "dump":
+		w.Header().Set("Content-Type", "application/json")
+		_ = abc.Dump(r.Context(), w)
+	default:
+		writeJSON(w, http.StatusOK, map[string][]string{"endpoints": endpoints})
+	}
+}
+
+func (h *handler) config(w http.ResponseWriter, r *http.Request) {
+	projectIDList := []string{r.URL.Query().Get("projectID")}
+	if len(projectIDList[0]) == 0 {
+		config, err := abc.GetGlobalConfig()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		projectIDList = config.ProjectIDList
+	}
+	result := make([]*abc.ConfigSummary, 0, len(projectIDList))
+	for _, projectID := range projectIDList {
+		summary, err := abc.GetConfigSummary(projectID)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		result = append(result, summary)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *handler) assignments(w http.ResponseWriter, r *http.Request) {
+	projectID, userCtx, err := parseUser(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	list, err := userCtx.GetExperiments(r.Context(), projectID, abc.WithAutomatic(false))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list.Data)
+}
+
+func (h *handler) explain(w http.ResponseWriter, r *http.Request) {
+	projectID, userCtx, err := parseUser(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	layerKey := r.URL.Query().Get("layerKey")
+	if len(layerKey) == 0 {
+		writeError(w, http.StatusBadRequest, errors.Errorf("layerKey is required"))
+		return
+	}
+	// the failure is explained by the trace itself
+	_, trace, _ := abc.GetExperimentWithTrace(r.Context(), userCtx, projectID, layerKey)
+	writeJSON(w, http.StatusOK, trace)
+}
+
+// parseUser Parse the projectID and the user context from the query parameters
+func parseUser(r *http.Request) (string, abc.Context, error) {
+	query := r.URL.Query()
+	projectID, unitID := query.Get("projectID"), query.Get("unitID")
+	if len(projectID) == 0 {
+		return "", nil, errors.Errorf("projectID is required")
+	}
+	if len(unitID) == 0 {
+		return "", nil, errors.Errorf("unitID is required")
+	}
+	var attributions []abc.Attribution
+	if newUnitID := query.Get("newUnitID"); len(newUnitID) > 0 {
+		attributions = append(attributions, abc.WithNewUnitID(newUnitID))
+	}
+	for _, tag := range query["tag"] {
+		index := strings.Index(tag, ":")
+		if index <= 0 {
+			return "", nil, errors.Errorf("invalid tag %s, the format is key:value", tag)
+		}
+		attributions = append(attributions, abc.WithTagKV(tag[:index], tag[index+1:]))
+	}
+	return projectID, abc.NewUserContext(unitID, attributions...), nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,75 @@
+// Package debug ...
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	defer abc.Release()
+	err := abc.Init(context.Background(), []string{"123"}, abc.WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		abc.WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	h := NewHandler()
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantKey    string // a key of the JSON object response, empty for array responses
+	}{
+		{name: "index", method: http.MethodGet, target: "/debug/abc/", wantStatus: http.StatusOK,
+			wantKey: "endpoints"},
+		{name: "method not allowed", method: http.MethodPost, target: "/debug/abc/config",
+			wantStatus: http.StatusMethodNotAllowed, wantKey: "error"},
+		{name: "config", method: http.MethodGet, target: "/debug/abc/config", wantStatus: http.StatusOK},
+		{name: "config not found", method: http.MethodGet, target: "/debug/abc/config?projectID=notExist",
+			wantStatus: http.StatusNotFound, wantKey: "error"},
+		{name: "assignments", method: http.MethodGet,
+			target: "/debug/abc/assignments?projectID=123&unitID=12345&tag=city:sz", wantStatus: http.StatusOK,
+			wantKey: "multiLayer2"},
+		{name: "assignments unitID is required", method: http.MethodGet,
+			target: "/debug/abc/assignments?projectID=123", wantStatus: http.StatusBadRequest, wantKey: "error"},
+		{name: "invalid tag", method: http.MethodGet,
+			target: "/debug/abc/assignments?projectID=123&unitID=12345&tag=city", wantStatus: http.StatusBadRequest,
+			wantKey: "error"},
+		{name: "explain", method: http.MethodGet,
+			target: "/debug/abc/explain?projectID=123&unitID=12345&layerKey=multiLayer2", wantStatus: http.StatusOK,
+			wantKey: "steps"},
+		{name: "explain layerKey is required", method: http.MethodGet,
+			target: "/debug/abc/explain?projectID=123&unitID=12345", wantStatus: http.StatusBadRequest,
+			wantKey: "error"},
+		{name: "exposure", method: http.MethodGet, target: "/debug/abc/exposure", wantStatus: http.StatusOK,
+			wantKey: "queues"},
+		{name: "errors", method: http.MethodGet, target: "/debug/abc/errors", wantStatus: http.StatusOK},
+		{name: "tasks", method: http.MethodGet, target: "/debug/abc/tasks", wantStatus: http.StatusOK},
+		{name: "audit", method: http.MethodGet, target: "/debug/abc/audit", wantStatus: http.StatusOK},
+		{name: "dump", method: http.MethodGet, target: "/debug/abc/dump", wantStatus: http.StatusOK,
+			wantKey: "goroutines"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			if len(tt.wantKey) == 0 {
+				var body []interface{}
+				assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				return
+			}
+			var body map[string]interface{}
+			assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			_, ok := body[tt.wantKey]
+			assert.True(t, ok, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,333 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/pkg/errors"
+)
+
+// ConfigSummary Summary of the locally cached configuration of a projectID
+type ConfigSummary struct {
+	ProjectID         string   `json:"projectId"`
+	Version           string   `json:"version"`
+	Revision          string   `json:"revision"`
+	LayerCount        int      `json:"layerCount"`
+	FullFlowLayerKeys []string `json:"fullFlowLayerKeys,omitempty"`
+	LayerKeys         []string `json:"layerKeys"`
+	RemoteConfigCount int      `json:"remoteConfigCount"`
+	ExperimentCount   int      `json:"experimentCount"`
+	GroupCount        int      `json:"groupCount"`
+}
+
+// GetConfigSummary Get the summary of the locally cached configuration of the projectID
+func GetConfigSummary(projectID string) (*ConfigSummary, error) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	summary := &ConfigSummary{
+		ProjectID:       projectID,
+		Version:         application.Version,
+		Revision:        application.Revision,
+		LayerCount:      len(application.LayerIndex),
+		ExperimentCount: len(application.ExperimentIDBucketInfoIndex),
+		GroupCount:      len(application.GroupIDBucketInfoIndex),
+	}
+	for layerKey := range application.LayerIndex {
+		summary.LayerKeys = append(summary.LayerKeys, layerKey)
+	}
+	for layerKey := range application.FullFlowLayerIndex {
+		summary.FullFlowLayerKeys = append(summary.FullFlowLayerKeys, layerKey)
+	}
+	sort.Strings(summary.LayerKeys)
+	sort.Strings(summary.FullFlowLayerKeys)
+	if application.TabConfig != nil && application.TabConfig.ConfigData != nil {
+		summary.RemoteConfigCount = len(application.TabConfig.ConfigData.RemoteConfigIndex)
+	}
+	return summary, nil
+}
+
+// ConfigRevision The revision of the configuration served by the local cache of a projectID
+type ConfigRevision struct {
+	ProjectID string `json:"projectId"`
+	Version   string `json:"version"`
+	// Content hash of the served configuration, the replicas serving the same configuration have the same revision
+	Revision string `json:"revision"`
+	// The time when the configuration of the revision was loaded
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// GetConfigRevision Get the revision of the configuration served by the local cache of the projectID,
+// compare it across replicas to detect the replicas stuck on an old configuration
+func GetConfigRevision(projectID string) (*ConfigRevision, error) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	return &ConfigRevision{
+		ProjectID:  projectID,
+		Version:    application.Version,
+		Revision:   application.Revision,
+		UpdateTime: application.UpdateTime,
+	}, nil
+}
+
+// ExposureQueueStats Statistics of a single asynchronous reporting queue
+type ExposureQueueStats struct {
+	Name string `json:"name"`
+	// Pending and Capacity are the sum of all shards
+	Pending  int `json:"pending"`
+	Capacity int `json:"capacity"`
+	// The number of shards of the queue, each shard is drained by its own consumer
+	Shards int `json:"shards"`
+}
+
+// ExposureStats Statistics of the asynchronous exposure reporting pipeline
+type ExposureStats struct {
+	Queues    []*ExposureQueueStats `json:"queues"`
+	Consumers int                   `json:"consumers"`
+	// Cumulative number of the discarded exposures by reason since Init, key is projectID
+	Loss map[string]map[LossReason]uint64 `json:"loss,omitempty"`
+	// State of the load shedding guardrail, nil if the guardrail is not enabled
+	Guardrail *GuardrailStats `json:"guardrail,omitempty"`
+	// State of the adaptive flush of the experiment exposures, nil if the adaptive flush is not enabled
+	Flush *FlushStats `json:"flush,omitempty"`
+	// Number of the exposure batches of the at-least-once projectIDs waiting for the redelivery
+	Undelivered int `json:"undelivered,omitempty"`
+}
+
+// FlushStats The state of the adaptive flush, see WithAdaptiveFlush
+type FlushStats struct {
+	// The current maximum number of the exposures of a flush
+	BatchSize int `json:"batchSize"`
+	// The current maximum number of the concurrent flushes
+	Concurrency int `json:"concurrency"`
+	// The p99 latency of the flushes measured in the last round
+	P99 time.Duration `json:"p99"`
+}
+
+// GuardrailStats The state of the load shedding guardrail, see WithGuardrail
+type GuardrailStats struct {
+	// The sampling intervals are multiplied by 2^ShedLevel and the queues are shrunk to 1/2^ShedLevel,
+	// 0 means no shedding
+	ShedLevel int `json:"shedLevel"`
+	// The busy fraction of one CPU of the exposure consumers measured in the last round
+	CPU float64 `json:"cpu"`
+	// The heap bytes measured in the last round
+	HeapBytes uint64 `json:"heapBytes"`
+}
+
+// LossReason The reason why the exposure is not reported
+type LossReason = internal.LossReason
+
+// const ...
+const (
+	LossReasonSampling  = internal.LossReasonSampling
+	LossReasonRateLimit = internal.LossReasonRateLimit
+	LossReasonQueueFull = internal.LossReasonQueueFull
+	LossReasonFailure   = internal.LossReasonFailure
+	LossReasonShed      = internal.LossReasonShed
+)
+
+// DeliveryGuarantee How hard the reporting of the exposures of a projectID tries before giving up, see
+// WithDeliveryGuarantee
+type DeliveryGuarantee = internal.DeliveryGuarantee
+
+// const ...
+const (
+	DeliveryBestEffort  = internal.DeliveryBestEffort
+	DeliveryAtLeastOnce = internal.DeliveryAtLeastOnce
+	DeliveryBlocking    = internal.DeliveryBlocking
+)
+
+// GetLossCounts Get the cumulative number of the discarded exposures of the projectID by reason since Init,
+// the reasons without loss are omitted
+func GetLossCounts(projectID string) map[LossReason]uint64 {
+	return internal.LossCounts(projectID)
+}
+
+// ClockSkew The estimated offset of the local clock from the control plane, see WithClockSkewTolerance
+type ClockSkew = internal.ClockSkew
+
+// GetClockSkew Get the offset of the local clock estimated from the Date headers of the control plane responses,
+// the median of the latest samples
+func GetClockSkew() *ClockSkew {
+	return internal.CurrentClockSkew()
+}
+
+// ErrorRecord An error happened inside the SDK, such as the refresh failure and the reporting failure
+type ErrorRecord = internal.ErrorRecord
+
+// RecentErrors The errors recently happened inside the SDK, the newest first, at most 100 errors are kept
+func RecentErrors() []*ErrorRecord {
+	return internal.RecentErrors()
+}
+
+// ErrorReason The typed reason passed to the callbacks registered by WithOnError and WithOnWarning
+type ErrorReason = internal.ErrorReason
+
+// const ...
+const (
+	ReasonConfigNotLoaded       = internal.ReasonConfigNotLoaded
+	ReasonMetricsConfigMissing  = internal.ReasonMetricsConfigMissing
+	ReasonMetricsPluginNotFound = internal.ReasonMetricsPluginNotFound
+	ReasonQueueFull             = internal.ReasonQueueFull
+	ReasonReportFailure         = internal.ReasonReportFailure
+	ReasonTokenFailure          = internal.ReasonTokenFailure
+	ReasonEndpointFailover      = internal.ReasonEndpointFailover
+	ReasonRuleTimeout           = internal.ReasonRuleTimeout
+	ReasonRateLimit             = internal.ReasonRateLimit
+	ReasonRelayFallback         = internal.ReasonRelayFallback
+	ReasonHandoffFallback       = internal.ReasonHandoffFallback
+)
+
+// AuditRecord A runtime mutation made in-process, such as UpdateOptions and SetCredentials
+type AuditRecord = internal.AuditRecord
+
+// AuditAction The kind of the runtime mutation
+type AuditAction = internal.AuditAction
+
+// const ...
+const (
+	AuditActionUpdateOptions     = internal.AuditActionUpdateOptions
+	AuditActionKillSwitch        = internal.AuditActionKillSwitch
+	AuditActionRotateCredentials = internal.AuditActionRotateCredentials
+	AuditActionFreeze            = internal.AuditActionFreeze
+)
+
+// AuditLog The runtime mutations made in-process, the oldest first, at most 256 records are kept.
+// The records survive Release, so that the incident review can reconstruct who changed what and when
+func AuditLog() []*AuditRecord {
+	return internal.AuditLog()
+}
+
+// maskedValue Replace the credentials in the diagnostics bundle
+const maskedValue = "******"
+
+// SharedContentStats The statistics of the config payloads and the compiled targeting rules deduplicated across the
+// projectIDs by their content, the identical fragments of the configurations of the projectIDs are held once
+type SharedContentStats = cache.SharedContentStats
+
+// DiagnosticsBundle The self-diagnostics of the SDK written by Dump
+type DiagnosticsBundle struct {
+	Time       time.Time              `json:"time"`
+	SDKVersion string                 `json:"sdkVersion"`
+	GoVersion  string                 `json:"goVersion"`
+	Host       string                 `json:"host"`
+	Config     *internal.GlobalConfig `json:"config"`
+	Projects   []*ProjectDiagnostics  `json:"projects"`
+	Exposure   *ExposureStats         `json:"exposure"`
+	Errors     []*ErrorRecord         `json:"errors"`
+	Audit      []*AuditRecord         `json:"audit"`
+	Tasks      []*TaskInfo            `json:"tasks"`
+	Clock      *ClockSkew             `json:"clock"`
+	Content    *SharedContentStats    `json:"sharedContent"`
+	Goroutines *GoroutineDiagnostics  `json:"goroutines"`
+	Failures   map[string]string      `json:"failures,omitempty"` // sections failed to collect, key is the section
+}
+
+// ProjectDiagnostics The diagnostics of a projectID
+type ProjectDiagnostics struct {
+	ProjectID string         `json:"projectId"`
+	Summary   *ConfigSummary `json:"summary,omitempty"`
+	Options   ProjectOptions `json:"options"`
+	// Whether the cached configuration is pinned by FreezeAssignments
+	Frozen bool `json:"frozen,omitempty"`
+}
+
+// GoroutineDiagnostics The goroutines of the process, the stacks are grouped by the identical stack
+type GoroutineDiagnostics struct {
+	Count  int    `json:"count"`
+	Stacks string `json:"stacks"`
+}
+
+// Dump Write the diagnostics bundle in indented JSON to w for attaching to the support ticket, including the
+// sanitized global configuration, the summary of the cached configuration of each projectID, the exposure pipeline
+// statistics, the recent errors, the audit log, the clock skew, the config content shared across the projectIDs and
+// the goroutines. The credentials are masked.
+// The sections failed to collect are recorded in the failures field instead of failing the whole bundle
+func Dump(ctx context.Context, w io.Writer) error {
+	if w == nil {
+		return errors.Errorf("writer is required")
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	bundle := &DiagnosticsBundle{
+		Time:       time.Now(),
+		SDKVersion: env.Version,
+		GoVersion:  runtime.Version(),
+		Host:       env.LocalIP(),
+		Exposure:   GetExposureStats(),
+		Errors:     RecentErrors(),
+		Audit:      AuditLog(),
+		Tasks:      Tasks(),
+		Clock:      GetClockSkew(),
+		Content:    cache.GetSharedContentStats(),
+		Failures:   map[string]string{},
+	}
+	config, err := sanitizedGlobalConfig()
+	if err != nil {
+		bundle.Failures["config"] = err.Error()
+	}
+	bundle.Config = config
+	for _, projectID := range internal.C.ProjectIDList {
+		project := &ProjectDiagnostics{ProjectID: projectID, Options: GetOptions(projectID),
+			Frozen: cache.IsFrozen(projectID)}
+		project.Summary, err = GetConfigSummary(projectID)
+		if err != nil {
+			bundle.Failures["project:"+projectID] = err.Error()
+		}
+		bundle.Projects = append(bundle.Projects, project)
+	}
+	bundle.Goroutines, err = goroutineDiagnostics()
+	if err != nil {
+		bundle.Failures["goroutines"] = err.Error()
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return errors.Wrap(encoder.Encode(bundle), "encode")
+}
+
+// sanitizedGlobalConfig Copy the global configuration and mask the credentials,
+// the kv of the metrics plugin init config may contain the plugin credentials and is masked as a whole
+func sanitizedGlobalConfig() (*internal.GlobalConfig, error) {
+	config, err := deepCopyGlobalConfig(internal.C)
+	if err != nil {
+		return nil, errors.Wrap(err, "deepCopyGlobalConfig")
+	}
+	if len(config.SecretKey) > 0 {
+		config.SecretKey = maskedValue
+	}
+	for _, initConfig := range config.MetricsPluginInitConfig {
+		if initConfig == nil {
+			continue
+		}
+		for key := range initConfig.Kv {
+			initConfig.Kv[key] = maskedValue
+		}
+	}
+	return config, nil
+}
+
+func goroutineDiagnostics() (*GoroutineDiagnostics, error) {
+	var stacks bytes.Buffer
+	err := pprof.Lookup("goroutine").WriteTo(&stacks, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "goroutine profile")
+	}
+	return &GoroutineDiagnostics{Count: runtime.NumGoroutine(), Stacks: stacks.String()}, nil
+}
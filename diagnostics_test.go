@@ -0,0 +1,33 @@
+// Package abc ...
+package abc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithSecretKey("mockSecretKey"))
+	assert.Nil(t, err)
+	assert.NotNil(t, Dump(context.Background(), nil))
+	var buf bytes.Buffer
+	err = Dump(context.Background(), &buf)
+	assert.Nil(t, err)
+	assert.NotContains(t, buf.String(), "mockSecretKey")
+	var got DiagnosticsBundle
+	err = json.Unmarshal(buf.Bytes(), &got)
+	assert.Nil(t, err)
+	assert.Equal(t, maskedValue, got.Config.SecretKey)
+	assert.Equal(t, 1, len(got.Projects))
+	assert.Equal(t, projectID, got.Projects[0].ProjectID)
+	assert.NotNil(t, got.Projects[0].Summary)
+	assert.True(t, got.Goroutines.Count > 0)
+	assert.Equal(t, 0, len(got.Failures))
+}
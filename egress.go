@@ -0,0 +1,87 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// WithEndpointPin pin the endpoint of the control-plane requests, such as the cache service, to an explicit address
+// instead of resolving it through DNS. The endpoint is the host or host:port of the backend url, the address is the
+// IP, host, IP:port or host:port dialed instead, the port of the endpoint is kept if the address has none.
+// TLS still verifies the host of the endpoint. Can be called multiple times
+func WithEndpointPin(endpoint string, address string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if len(endpoint) == 0 || len(address) == 0 {
+			return errors.Errorf("endpoint and address are required")
+		}
+		egress := egressPolicy(config)
+		if egress.Pins == nil {
+			egress.Pins = map[string]string{}
+		}
+		egress.Pins[endpoint] = address
+		return nil
+	}
+}
+
+// WithEgressAllowlist only allow the control-plane connections to the IPs in the networks, each is a CIDR such as
+// 10.0.0.0/8 or a single IP. The connection fails closed if the resolved or pinned IPs of the endpoint are all
+// outside the allowlist. Can be called multiple times, the networks are merged
+func WithEgressAllowlist(networks ...string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if len(networks) == 0 {
+			return errors.Errorf("networks are required")
+		}
+		egress := egressPolicy(config)
+		for _, network := range networks {
+			ipNet, err := parseNetwork(network)
+			if err != nil {
+				return err
+			}
+			egress.Allowlist = append(egress.Allowlist, ipNet)
+		}
+		return nil
+	}
+}
+
+// DialEgress Dial through the endpoint pins and the egress allowlist of Init, for the connections outside the SDK
+// that are subject to the same egress control, such as the event server of a metrics plugin.
+// It dials as usual if no egress control is set
+func DialEgress(ctx context.Context, network, addr string) (net.Conn, error) {
+	return internal.C.Egress.DialContext(ctx, network, addr)
+}
+
+func egressPolicy(config *internal.GlobalConfig) *internal.EgressPolicy {
+	if config.Egress == nil {
+		config.Egress = &internal.EgressPolicy{}
+	}
+	return config.Egress
+}
+
+// parseNetwork Parse the CIDR or the single IP
+func parseNetwork(network string) (*net.IPNet, error) {
+	if strings.Contains(network, "/") {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid network %s", network)
+		}
+		return ipNet, nil
+	}
+	ip := net.ParseIP(network)
+	if ip == nil {
+		return nil, errors.Errorf("invalid network %s", network)
+	}
+	bits := 8 * net.IPv4len
+	if ip.To4() == nil {
+		bits = 8 * net.IPv6len
+	} else {
+		ip = ip.To4()
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -0,0 +1,23 @@
+// Package abc ...
+package abc
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEgressOptions(t *testing.T) {
+	config := &internal.GlobalConfig{}
+	assert.Nil(t, WithEndpointPin("cache.abetterchoice.ai", "10.0.0.1")(config))
+	assert.Nil(t, WithEgressAllowlist("10.0.0.0/8", "192.168.1.1", "fd00::1")(config))
+	assert.Equal(t, map[string]string{"cache.abetterchoice.ai": "10.0.0.1"}, config.Egress.Pins)
+	assert.Equal(t, 3, len(config.Egress.Allowlist))
+	assert.Equal(t, "192.168.1.1/32", config.Egress.Allowlist[1].String())
+	assert.Equal(t, "fd00::1/128", config.Egress.Allowlist[2].String())
+	assert.NotNil(t, WithEgressAllowlist("10.0.0.0/33")(config))
+	assert.NotNil(t, WithEgressAllowlist("host")(config))
+	assert.NotNil(t, WithEgressAllowlist()(config))
+	assert.NotNil(t, WithEndpointPin("", "10.0.0.1")(config))
+}
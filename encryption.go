@@ -0,0 +1,95 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"bytes"
+	"encoding/base64"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// KeySource Resolve the AES key of the data at rest, such as from the keychain of the OS or a secret manager,
+// see WithEncryptionKeySource
+type KeySource = internal.KeySource
+
+// WithEncryptionKey encrypt the data written at rest by the SDK with AES-GCM under the key, 16, 24 or 32 bytes for
+// AES-128, AES-192 or AES-256, since they contain the user identifiers. The sampled traces of WithTraceSampling are
+// written one encrypted record per line, see DecryptTraceLine. Default disabled
+func WithEncryptionKey(key []byte) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		c, err := internal.NewCipher(key)
+		if err != nil {
+			return errors.Wrap(err, "encryption key")
+		}
+		config.Cipher = c
+		return nil
+	}
+}
+
+// WithEncryptionKeySource same as WithEncryptionKey, the key is resolved from the source once by Init, such as from
+// the keychain of the OS, so that the key is not kept in the configuration of the service. Init fails if the key
+// cannot be resolved
+func WithEncryptionKeySource(source KeySource) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if source == nil {
+			return errors.Errorf("source is required")
+		}
+		key, err := source()
+		if err != nil {
+			return errors.Wrap(err, "resolve encryption key")
+		}
+		return WithEncryptionKey(key)(config)
+	}
+}
+
+// ParseEncryptionKey Decode the key of a key file or an environment variable, in hex, in base64 or raw
+func ParseEncryptionKey(text []byte) ([]byte, error) {
+	return internal.ParseKey(text)
+}
+
+// Encrypt Encrypt the data with AES-GCM under the key, such as a snapshot saved to the disk, see Decrypt
+func Encrypt(key []byte, data []byte) ([]byte, error) {
+	c, err := internal.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.Encrypt(data)
+}
+
+// Decrypt Decrypt the data encrypted by Encrypt, by `abcctl fetch -key-file` or by the relay server, the data
+// modified or encrypted by another key is rejected
+func Decrypt(key []byte, data []byte) ([]byte, error) {
+	c, err := internal.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.Decrypt(data)
+}
+
+// IsEncrypted Whether the data is encrypted by Encrypt
+func IsEncrypted(data []byte) bool {
+	return internal.IsEncrypted(data)
+}
+
+// DecryptTraceLine Decrypt a line of the sampled traces written under WithEncryptionKey into the SampledTrace in JSON
+func DecryptTraceLine(key []byte, line []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(line)))
+	if err != nil {
+		return nil, errors.Wrap(err, "base64")
+	}
+	return Decrypt(key, data)
+}
+
+// encryptLine The line of the encrypted record, in base64 so that the records stay delimited by the newlines
+func encryptLine(c *internal.Cipher, record []byte) ([]byte, error) {
+	data, err := c.Encrypt(record)
+	if err != nil {
+		return nil, err
+	}
+	line := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(line, data)
+	return line, nil
+}
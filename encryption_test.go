@@ -0,0 +1,64 @@
+// Package abc ...
+package abc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedTraces(t *testing.T) {
+	Release()
+	defer Release()
+	key := bytes.Repeat([]byte{7}, 32)
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithEncryptionKey([]byte("short"))))
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithEncryptionKeySource(func() ([]byte, error) {
+		return nil, errors.New("keychain is locked")
+	})))
+	buffer := &bytes.Buffer{}
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithTraceSampling(1, buffer),
+		WithEncryptionKeySource(func() ([]byte, error) { return key, nil }))
+	assert.Nil(t, err)
+	_, err = NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.False(t, bytes.Contains(buffer.Bytes(), []byte("12345")))
+	scanner := bufio.NewScanner(buffer)
+	assert.True(t, scanner.Scan())
+	_, err = DecryptTraceLine(bytes.Repeat([]byte{8}, 32), scanner.Bytes())
+	assert.NotNil(t, err)
+	line, err := DecryptTraceLine(key, scanner.Bytes())
+	assert.Nil(t, err)
+	trace := &SampledTrace{}
+	assert.Nil(t, json.Unmarshal(line, trace))
+	assert.Equal(t, "12345", trace.UnitID)
+}
+
+func TestParseEncryptedConfigSnapshot(t *testing.T) {
+	Release()
+	defer Release()
+	recorder := client.NewRecordingClient(testdata.MockCacheClient(t))
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(recorder),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	data, err := client.MarshalSnapshot(recorder.Snapshot(projectID))
+	assert.Nil(t, err)
+	key := bytes.Repeat([]byte{7}, 16)
+	encrypted, err := Encrypt(key, data)
+	assert.Nil(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	_, err = ParseConfigSnapshot(encrypted)
+	assert.EqualError(t, err, "snapshot is encrypted, see ParseEncryptedConfigSnapshot")
+	config, err := ParseEncryptedConfigSnapshot(encrypted, key)
+	assert.Nil(t, err)
+	assert.Equal(t, projectID, config.ProjectID())
+	_, err = ParseEncryptedConfigSnapshot(data, key)
+	assert.NotNil(t, err)
+}
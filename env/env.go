@@ -1,11 +1,6 @@
 // Package env Related enumeration value definitions, etc.
 package env
 
-import (
-	"fmt"
-	"runtime"
-)
-
 // Type Environment Type
 type Type = string
 
@@ -37,9 +32,3 @@ const (
 	// [If the control group or experimental group is not hit, the default experiment may be hit.]
 	DefaultGlobalGroupKey = "defaultSystemGroupKey"
 )
-
-// InvokePath Call Path
-func InvokePath(skip int) string {
-	_, file, line, _ := runtime.Caller(skip)
-	return fmt.Sprintf("%s:%d", file, line)
-}
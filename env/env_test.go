@@ -2,11 +2,15 @@
 package env
 
 import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/abetterchoice/protoc_cache_server"
-	"github.com/abetterchoice/protoc_event_server"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestInvokePath(t *testing.T) {
@@ -196,35 +200,6 @@ func TestErrMsg(t *testing.T) {
 	}
 }
 
-func TestEventStatus(t *testing.T) {
-	type args struct {
-		err error
-	}
-	tests := []struct {
-		name string
-		args args
-		want protoc_event_server.MonitorEventStatus
-	}{
-		{
-			name: "normal",
-			args: args{err: errors.Errorf("mock err")},
-			want: protoc_event_server.MonitorEvent_STATUS_UNEXPECTED,
-		},
-		{
-			name: "normal",
-			args: args{err: nil},
-			want: protoc_event_server.MonitorEvent_STATUS_SUCCESS,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := EventStatus(tt.args.err); got != tt.want {
-				t.Errorf("EventStatus() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestJSONString(t *testing.T) {
 	type args struct {
 		source interface{}
@@ -295,3 +270,61 @@ func TestSamplingInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestInvokePathDepth(t *testing.T) {
+	defer SetInvokePathDepth(DefaultInvokePathDepth)
+	_, file, line, _ := runtime.Caller(0)
+	assert.Equal(t, fmt.Sprintf("%s:%d", file, line+1), InvokePath(1))
+	SetInvokePathDepth(2)
+	assert.Equal(t, 2, len(strings.Split(InvokePath(1), ";")))
+	SetInvokePathDepth(MaxInvokePathDepth + 1)
+	assert.Equal(t, MaxInvokePathDepth, InvokePathDepth())
+	SetInvokePathDepth(0)
+	assert.Equal(t, "", InvokePath(1))
+}
+
+func BenchmarkInvokePath(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		InvokePath(1)
+	}
+}
+
+func TestRegisterIPResolver(t *testing.T) {
+	defer RegisterIPResolver(nil)
+	assert.NotEmpty(t, LocalIP())
+	RegisterIPResolver(IPResolverFunc(func() string {
+		return "10.0.0.1"
+	}))
+	assert.Equal(t, "10.0.0.1", LocalIP())
+	RegisterIPResolver(IPResolverFunc(func() string {
+		return ""
+	}))
+	assert.Equal(t, DefaultLocalIP, LocalIP())
+}
+
+func TestRegisterSystemMetadataProvider(t *testing.T) {
+	defer RegisterSystemMetadataProvider(nil)
+	assert.Nil(t, SystemMetadata())
+	calls := 0
+	RegisterSystemMetadataProvider(SystemMetadataProviderFunc(func() map[string]string {
+		calls++
+		return map[string]string{MetadataKeyPodName: "pod-1", MetadataKeyImageTag: "", "": "ignored"}
+	}))
+	assert.Equal(t, map[string]string{MetadataKeyPodName: "pod-1"}, SystemMetadata())
+	assert.Equal(t, map[string]string{MetadataKeyPodName: "pod-1"}, SystemMetadata())
+	assert.Equal(t, 1, calls) // resolved once
+
+	t.Setenv("POD_NAME", "pod-2")
+	t.Setenv("AVAILABILITY_ZONE", "zone-a")
+	t.Setenv("IMAGE_TAG", "")
+	RegisterSystemMetadataProvider(EnvironmentMetadataProvider)
+	metadata := SystemMetadata()
+	assert.Equal(t, "pod-2", metadata[MetadataKeyPodName])
+	assert.Equal(t, "zone-a", metadata[MetadataKeyAvailabilityZone])
+	assert.NotContains(t, metadata, MetadataKeyImageTag)
+	hostname, err := os.Hostname()
+	if err == nil {
+		assert.Equal(t, hostname, metadata[MetadataKeyHostname])
+	}
+}
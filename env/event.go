@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 
 	"github.com/abetterchoice/protoc_cache_server"
-	"github.com/abetterchoice/protoc_event_server"
 )
 
 // SamplingInterval Select sampling interval based on error
@@ -32,11 +31,3 @@ func JSONString(source interface{}) string {
 	data, _ := json.Marshal(source)
 	return string(data)
 }
-
-// EventStatus Event Status
-func EventStatus(err error) protoc_event_server.MonitorEventStatus {
-	if err != nil {
-		return protoc_event_server.MonitorEvent_STATUS_UNEXPECTED
-	}
-	return protoc_event_server.MonitorEvent_STATUS_SUCCESS
-}
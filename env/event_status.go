@@ -0,0 +1,15 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package env TODO
+package env
+
+import "github.com/abetterchoice/protoc_event_server"
+
+// EventStatus Event Status
+func EventStatus(err error) protoc_event_server.MonitorEventStatus {
+	if err != nil {
+		return protoc_event_server.MonitorEvent_STATUS_UNEXPECTED
+	}
+	return protoc_event_server.MonitorEvent_STATUS_SUCCESS
+}
@@ -0,0 +1,41 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package env ...
+package env
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/pkg/errors"
+)
+
+func TestEventStatus(t *testing.T) {
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		args args
+		want protoc_event_server.MonitorEventStatus
+	}{
+		{
+			name: "normal",
+			args: args{err: errors.Errorf("mock err")},
+			want: protoc_event_server.MonitorEvent_STATUS_UNEXPECTED,
+		},
+		{
+			name: "normal",
+			args: args{err: nil},
+			want: protoc_event_server.MonitorEvent_STATUS_SUCCESS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EventStatus(tt.args.err); got != tt.want {
+				t.Errorf("EventStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package env Related enumeration value definitions, etc.
+package env
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultInvokePathDepth The default number of frames captured by InvokePath
+	DefaultInvokePathDepth = 1
+	// MaxInvokePathDepth The maximum number of frames captured by InvokePath
+	MaxInvokePathDepth = 8
+)
+
+var (
+	// invokePathDepth The number of frames captured by InvokePath, 0 disables the capture
+	invokePathDepth int32 = DefaultInvokePathDepth
+	// invokePathCache key is the program counter, value is file:line. The program counters of a binary are finite,
+	// so the cache does not need eviction
+	invokePathCache = make(map[uintptr]string)
+	invokePathLock  sync.RWMutex
+)
+
+// SetInvokePathDepth Set the number of frames captured by InvokePath, 0 disables the capture and
+// InvokePath returns empty, the depth is capped to MaxInvokePathDepth. Concurrent and safe
+func SetInvokePathDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	if depth > MaxInvokePathDepth {
+		depth = MaxInvokePathDepth
+	}
+	atomic.StoreInt32(&invokePathDepth, int32(depth))
+}
+
+// InvokePathDepth The number of frames captured by InvokePath
+func InvokePathDepth() int {
+	return int(atomic.LoadInt32(&invokePathDepth))
+}
+
+// InvokePath Call Path, skip is the same as runtime.Caller. The frames are separated by ;
+// and resolved once per program counter, so the repeated call sites only cost a stack walk
+func InvokePath(skip int) string {
+	depth := atomic.LoadInt32(&invokePathDepth)
+	if depth == 0 {
+		return ""
+	}
+	var pcs [MaxInvokePathDepth]uintptr
+	n := runtime.Callers(skip+1, pcs[:depth]) // +1 skips runtime.Callers itself, the same as runtime.Caller
+	if n == 0 {
+		return ":0"
+	}
+	if n == 1 {
+		return resolvePC(pcs[0])
+	}
+	frames := make([]string, n)
+	for i := 0; i < n; i++ {
+		frames[i] = resolvePC(pcs[i])
+	}
+	return strings.Join(frames, ";")
+}
+
+// resolvePC Resolve the program counter returned by runtime.Callers to file:line through the cache
+func resolvePC(pc uintptr) string {
+	invokePathLock.RLock()
+	path, ok := invokePathCache[pc]
+	invokePathLock.RUnlock()
+	if ok {
+		return path
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	path = frame.File + ":" + strconv.Itoa(frame.Line)
+	invokePathLock.Lock()
+	invokePathCache[pc] = path
+	invokePathLock.Unlock()
+	return path
+}
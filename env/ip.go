@@ -2,88 +2,132 @@ package env
 
 import (
 	"net"
-	"strconv"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // DefaultLocalIP Use this IP after failing to obtain the local IP
 const DefaultLocalIP = "127.0.0.1"
 
-var localIP string
-
-func init() {
-	localIP = DefaultLocalIP
-	ips := getLocalIplist(1)
-	if len(ips) > 0 {
-		localIP = ips[0]
-	}
+// IPResolver Resolve the local IP carried by the monitor events, such as the IP of the pod injected by the platform.
+// The default resolves the first intranet address of the network interfaces, or DefaultLocalIP in the wasm mode
+type IPResolver interface {
+	LocalIP() string
 }
 
-// LocalIP local ip
-func LocalIP() string {
-	return localIP
+// IPResolverFunc Adapt the function to IPResolver
+type IPResolverFunc func() string
+
+// LocalIP Implement IPResolver
+func (f IPResolverFunc) LocalIP() string {
+	return f()
 }
 
-func getLocalIplist(limit int) []string {
-	addrs, err := net.InterfaceAddrs()
-	if err != nil {
-		return nil
-	}
-	ipList := getIpListFromAddr(limit, addrs)
-	if len(ipList) == 0 {
-		ipList = append(ipList, DefaultLocalIP)
-	}
-	return ipList
+// AddressFamily The address family of the local IP, see IPConfig
+type AddressFamily int
+
+// const The address families
+const (
+	AddressFamilyIPv4 AddressFamily = 4
+	AddressFamilyIPv6 AddressFamily = 6
+)
+
+// defaultAddressFamilies The IPv4 is preferred, the IPv6 address is reported on the IPv6 only hosts
+var defaultAddressFamilies = []AddressFamily{AddressFamilyIPv4, AddressFamilyIPv6}
+
+// IPConfig The selection of the local IP of NewIPResolver, such as on the dual-stack kubernetes clusters. The sources
+// are tried in order, Override, OverrideEnv then the network interfaces, DefaultLocalIP is reported if none has an
+// address
+type IPConfig struct {
+	// The IP reported as is
+	Override string
+	// The environment variable of the IPs, comma separated, such as POD_IPS set to status.podIPs by the downward API,
+	// the first IP of the preferred family is reported
+	OverrideEnv string
+	// The names of the network interfaces in the order of preference, such as eth0, which take precedence over the
+	// families. Empty means all interfaces, the loopback, link-local and unspecified addresses are skipped, and the
+	// IPv4 addresses must be intranet addresses
+	Interfaces []string
+	// The address families in the order of preference, empty means IPv4 then IPv6
+	Families []AddressFamily
+	// Whether to fall back to all interfaces if none of Interfaces has an address
+	FallbackToAnyInterface bool
 }
 
-func getIpListFromAddr(limit int, addrs []net.Addr) []string {
-	count := 0
-	var ipList []string
-	for _, address := range addrs {
-		ipNet, ok := address.(*net.IPNet)
-		if !ok {
-			continue
+// NewIPResolver Create the resolver of the local IP selected by the config, see RegisterIPResolver. The interfaces
+// are not resolved in the wasm mode
+func NewIPResolver(config IPConfig) IPResolver {
+	if len(config.Families) == 0 {
+		config.Families = defaultAddressFamilies
+	}
+	return IPResolverFunc(func() string {
+		if len(config.Override) > 0 {
+			return config.Override
 		}
-		if !isAddrOK(ipNet) {
-			continue
+		if len(config.OverrideEnv) > 0 {
+			if ip := selectIP(strings.Split(os.Getenv(config.OverrideEnv), ","), config.Families); len(ip) > 0 {
+				return ip
+			}
 		}
-		ipList = append(ipList, ipNet.IP.String())
-		count++
-		if count >= limit {
-			break
+		return interfaceIP(config)
+	})
+}
+
+// selectIP The first valid IP of the preferred family
+func selectIP(ips []string, families []AddressFamily) string {
+	for _, family := range families {
+		for _, text := range ips {
+			ip := net.ParseIP(strings.TrimSpace(text))
+			if ip != nil && isFamily(ip, family) {
+				return ip.String()
+			}
 		}
 	}
-	return ipList
+	return ""
 }
 
-func isAddrOK(ipNet *net.IPNet) bool {
-	if ipNet.IP.IsLoopback() {
-		return false
+func isFamily(ip net.IP, family AddressFamily) bool {
+	if ip.To4() != nil {
+		return family == AddressFamilyIPv4
 	}
-	if ipNet.IP.To4() != nil {
-		if isInnerIp(ipNet.IP.String()) {
-			return true
-		}
-	} else if ipNet.IP.To16() != nil {
-		return true
+	return family == AddressFamilyIPv6
+}
+
+var (
+	ipResolverLock sync.Mutex
+	ipResolver     IPResolver = IPResolverFunc(defaultLocalIP)
+	// localIP the resolved string, empty until the first LocalIP
+	localIP atomic.Value
+)
+
+// RegisterIPResolver Replace the resolver of LocalIP, nil restores the default. Set before SDK Init to take effect
+func RegisterIPResolver(resolver IPResolver) {
+	ipResolverLock.Lock()
+	defer ipResolverLock.Unlock()
+	if resolver == nil {
+		resolver = IPResolverFunc(defaultLocalIP)
 	}
-	return false
+	ipResolver = resolver
+	localIP.Store("")
 }
 
-// isInnerIp Here we determine whether the URL is an intranet IP
-func isInnerIp(ipv4 string) bool {
-	temp := strings.Split(ipv4, ".")
-	firstNum, _ := strconv.Atoi(temp[0])
-	// 100 172 192 The beginning can be regarded as the intranet ip
-	inValues := []int{100, 172, 192}
-	for i := 0; i < len(inValues); i++ {
-		if firstNum == inValues[i] {
-			return true
-		}
+// LocalIP local ip, resolved on first use instead of on import, so that importing the SDK does not touch the network
+// interfaces
+func LocalIP() string {
+	if ip, _ := localIP.Load().(string); len(ip) > 0 {
+		return ip
+	}
+	ipResolverLock.Lock()
+	defer ipResolverLock.Unlock()
+	if ip, _ := localIP.Load().(string); len(ip) > 0 {
+		return ip
 	}
-	// The number starts from 1 to 15, and can also be an intranet IP.
-	if firstNum >= 1 && firstNum <= 15 {
-		return true
+	ip := ipResolver.LocalIP()
+	if len(ip) == 0 {
+		ip = DefaultLocalIP
 	}
-	return false
+	localIP.Store(ip)
+	return ip
 }
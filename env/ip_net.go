@@ -0,0 +1,133 @@
+//go:build !abc_wasm
+// +build !abc_wasm
+
+package env
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// netInterface The addresses of a network interface
+type netInterface struct {
+	name  string
+	addrs []net.Addr
+}
+
+// netInterfaces The network interfaces in the order of the system, replaced by the tests
+var netInterfaces = func() ([]netInterface, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]netInterface, 0, len(interfaces))
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		result = append(result, netInterface{name: iface.Name, addrs: addrs})
+	}
+	return result, nil
+}
+
+// defaultLocalIP The first intranet IPv4 address of the network interfaces, or the first IPv6 address on the IPv6
+// only hosts
+func defaultLocalIP() string {
+	ip := interfaceIP(IPConfig{Families: defaultAddressFamilies})
+	if len(ip) == 0 {
+		return DefaultLocalIP
+	}
+	return ip
+}
+
+// interfaceIP The address of the interfaces of the config, empty if none
+func interfaceIP(config IPConfig) string {
+	interfaces, err := netInterfaces()
+	if err != nil {
+		return ""
+	}
+	if len(config.Interfaces) == 0 {
+		return selectInterfaceIP(interfaces, config.Families, false)
+	}
+	var named []netInterface
+	for _, name := range config.Interfaces {
+		for _, iface := range interfaces {
+			if iface.name == name {
+				named = append(named, iface)
+			}
+		}
+	}
+	if ip := selectInterfaceIP(named, config.Families, true); len(ip) > 0 || !config.FallbackToAnyInterface {
+		return ip
+	}
+	return selectInterfaceIP(interfaces, config.Families, false)
+}
+
+// selectInterfaceIP The first address of the preferred family, of the interfaces in order if isNamed. The IPv4
+// addresses of the interfaces not named must be intranet addresses
+func selectInterfaceIP(interfaces []netInterface, families []AddressFamily, isNamed bool) string {
+	if isNamed {
+		for _, iface := range interfaces {
+			for _, family := range families {
+				if ip := selectAddr(iface.addrs, family, false); len(ip) > 0 {
+					return ip
+				}
+			}
+		}
+		return ""
+	}
+	for _, family := range families {
+		for _, iface := range interfaces {
+			if ip := selectAddr(iface.addrs, family, true); len(ip) > 0 {
+				return ip
+			}
+		}
+	}
+	return ""
+}
+
+func selectAddr(addrs []net.Addr, family AddressFamily, isIntranetOnly bool) string {
+	for _, address := range addrs {
+		ipNet, ok := address.(*net.IPNet)
+		if !ok || !isFamily(ipNet.IP, family) || !isAddrOK(ipNet.IP, isIntranetOnly) {
+			continue
+		}
+		return ipNet.IP.String()
+	}
+	return ""
+}
+
+// isAddrOK Whether the address identifies the host, the loopback, link-local, multicast and unspecified addresses do
+// not
+func isAddrOK(ip net.IP, isIntranetOnly bool) bool {
+	if !ip.IsGlobalUnicast() {
+		return false
+	}
+	if ip.To4() != nil && isIntranetOnly {
+		return isInnerIp(ip.String())
+	}
+	return true
+}
+
+// isInnerIp Here we determine whether the URL is an intranet IP
+func isInnerIp(ipv4 string) bool {
+	temp := strings.Split(ipv4, ".")
+	firstNum, _ := strconv.Atoi(temp[0])
+	// 100 172 192 The beginning can be regarded as the intranet ip
+	inValues := []int{100, 172, 192}
+	for i := 0; i < len(inValues); i++ {
+		if firstNum == inValues[i] {
+			return true
+		}
+	}
+	// The number starts from 1 to 15, and can also be an intranet IP.
+	if firstNum >= 1 && firstNum <= 15 {
+		return true
+	}
+	return false
+}
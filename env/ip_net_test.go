@@ -0,0 +1,51 @@
+//go:build !abc_wasm
+// +build !abc_wasm
+
+// Package env ...
+package env
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIPResolver(t *testing.T) {
+	defer func(f func() ([]netInterface, error)) { netInterfaces = f }(netInterfaces)
+	addr := func(cidr string) net.Addr {
+		ip, ipNet, err := net.ParseCIDR(cidr)
+		assert.Nil(t, err)
+		ipNet.IP = ip
+		return ipNet
+	}
+	netInterfaces = func() ([]netInterface, error) {
+		return []netInterface{
+			{name: "lo", addrs: []net.Addr{addr("127.0.0.1/8"), addr("::1/128")}},
+			{name: "eth0", addrs: []net.Addr{addr("fe80::1/64"), addr("fd00::10/64"), addr("10.0.0.10/24")}},
+			{name: "eth1", addrs: []net.Addr{addr("203.0.113.5/24")}},
+			{name: "eth2", addrs: []net.Addr{addr("2001:db8::20/64")}},
+		}, nil
+	}
+	assert.Equal(t, "10.0.0.10", defaultLocalIP()) // IPv4 preferred over the first address
+	assert.Equal(t, "10.0.0.10", NewIPResolver(IPConfig{}).LocalIP())
+	assert.Equal(t, "fd00::10", NewIPResolver(IPConfig{Families: []AddressFamily{AddressFamilyIPv6}}).LocalIP())
+	// the named interfaces take precedence over the families, the public IPv4 is reported
+	assert.Equal(t, "203.0.113.5", NewIPResolver(IPConfig{Interfaces: []string{"eth1", "eth0"},
+		Families: []AddressFamily{AddressFamilyIPv6, AddressFamilyIPv4}}).LocalIP())
+	assert.Equal(t, "2001:db8::20", NewIPResolver(IPConfig{Interfaces: []string{"eth2", "eth0"}}).LocalIP())
+	assert.Equal(t, "", NewIPResolver(IPConfig{Interfaces: []string{"lo", "eth9"}}).LocalIP())
+	assert.Equal(t, "10.0.0.10", NewIPResolver(IPConfig{Interfaces: []string{"eth9"},
+		FallbackToAnyInterface: true}).LocalIP())
+
+	t.Setenv("POD_IPS", "fd00::99, 10.1.2.3,invalid")
+	assert.Equal(t, "10.1.2.3", NewIPResolver(IPConfig{OverrideEnv: "POD_IPS"}).LocalIP())
+	assert.Equal(t, "fd00::99", NewIPResolver(IPConfig{OverrideEnv: "POD_IPS",
+		Families: []AddressFamily{AddressFamilyIPv6}}).LocalIP())
+	assert.Equal(t, "10.0.0.10", NewIPResolver(IPConfig{OverrideEnv: "NOT_SET_POD_IPS"}).LocalIP())
+	assert.Equal(t, "192.168.0.1", NewIPResolver(IPConfig{Override: "192.168.0.1", OverrideEnv: "POD_IPS"}).LocalIP())
+
+	defer RegisterIPResolver(nil)
+	RegisterIPResolver(NewIPResolver(IPConfig{Interfaces: []string{"lo"}}))
+	assert.Equal(t, DefaultLocalIP, LocalIP())
+}
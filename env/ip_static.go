@@ -0,0 +1,14 @@
+//go:build abc_wasm
+// +build abc_wasm
+
+package env
+
+// defaultLocalIP The wasm hosts expose no network interfaces, register an IPResolver to report the IP of the host
+func defaultLocalIP() string {
+	return DefaultLocalIP
+}
+
+// interfaceIP The wasm hosts expose no network interfaces
+func interfaceIP(config IPConfig) string {
+	return ""
+}
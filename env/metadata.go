@@ -0,0 +1,97 @@
+package env
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// const The keys of the system metadata of the EnvironmentMetadataProvider
+const (
+	MetadataKeyHostname         = "hostname"
+	MetadataKeyPodName          = "pod_name"
+	MetadataKeyAvailabilityZone = "availability_zone"
+	MetadataKeyImageTag         = "image_tag"
+)
+
+// SystemMetadataProvider Provide the deployment context of the process, such as the hostname, the pod name, the
+// availability zone and the image tag, which is added to the ExtraData of the exposures and the ExtInfo of the monitor
+// events, so that the events are attributed to the deployment beyond the IP of LocalIP
+type SystemMetadataProvider interface {
+	SystemMetadata() map[string]string
+}
+
+// SystemMetadataProviderFunc Adapt the function to SystemMetadataProvider
+type SystemMetadataProviderFunc func() map[string]string
+
+// SystemMetadata Implement SystemMetadataProvider
+func (f SystemMetadataProviderFunc) SystemMetadata() map[string]string {
+	return f()
+}
+
+// EnvironmentMetadataProvider The provider of the hostname of the OS, and of the pod name, the availability zone and
+// the image tag of the environment variables POD_NAME, AVAILABILITY_ZONE and IMAGE_TAG, such as injected by the
+// downward API of kubernetes. The empty values are omitted
+var EnvironmentMetadataProvider SystemMetadataProvider = SystemMetadataProviderFunc(environmentMetadata)
+
+func environmentMetadata() map[string]string {
+	metadata := make(map[string]string, 4)
+	if hostname, err := os.Hostname(); err == nil && len(hostname) > 0 {
+		metadata[MetadataKeyHostname] = hostname
+	}
+	for key, name := range map[string]string{MetadataKeyPodName: "POD_NAME",
+		MetadataKeyAvailabilityZone: "AVAILABILITY_ZONE", MetadataKeyImageTag: "IMAGE_TAG"} {
+		if value := os.Getenv(name); len(value) > 0 {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+var (
+	metadataProviderLock sync.Mutex
+	metadataProvider     SystemMetadataProvider
+	// systemMetadata *resolvedMetadata, nil until the first SystemMetadata
+	systemMetadata atomic.Value
+)
+
+// resolvedMetadata The metadata resolved by the provider, data is nil if there is no provider
+type resolvedMetadata struct {
+	data map[string]string
+}
+
+// RegisterSystemMetadataProvider Register the provider of SystemMetadata, such as EnvironmentMetadataProvider, nil
+// disables the system metadata, the default. Set before SDK Init to take effect
+func RegisterSystemMetadataProvider(provider SystemMetadataProvider) {
+	metadataProviderLock.Lock()
+	defer metadataProviderLock.Unlock()
+	metadataProvider = provider
+	systemMetadata.Store((*resolvedMetadata)(nil))
+}
+
+// SystemMetadata The metadata of the registered provider, resolved on first use and kept for the lifetime of the
+// process, nil if no provider is registered. The result is shared and must not be modified
+func SystemMetadata() map[string]string {
+	if resolved, _ := systemMetadata.Load().(*resolvedMetadata); resolved != nil {
+		return resolved.data
+	}
+	metadataProviderLock.Lock()
+	defer metadataProviderLock.Unlock()
+	if resolved, _ := systemMetadata.Load().(*resolvedMetadata); resolved != nil {
+		return resolved.data
+	}
+	resolved := &resolvedMetadata{}
+	if metadataProvider != nil {
+		for key, value := range metadataProvider.SystemMetadata() {
+			if len(key) == 0 || len(value) == 0 {
+				continue
+			}
+			if resolved.data == nil {
+				resolved.data = make(map[string]string)
+			}
+			resolved.data[key] = value
+		}
+	}
+	systemMetadata.Store(resolved)
+	return resolved.data
+}
@@ -0,0 +1,63 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/pkg/errors"
+)
+
+// The errors of the public APIs, the returned errors wrap them with the details, so that the callers can branch on
+// errors.Is(err, ErrProjectUnknown) instead of the messages. See also ErrBackpressure and ErrLayerNotFound
+var (
+	// ErrNotInitialized Init has not been called, or the SDK has been released
+	ErrNotInitialized = internal.ErrNotInitialized
+	// ErrProjectUnknown The configuration of the projectID is not loaded, usually it is not passed in Init.
+	// The error is a *ProjectError
+	ErrProjectUnknown = internal.ErrProjectUnknown
+	// ErrConfigStale The configuration of the projectID has not been refreshed within WithMaxConfigAge.
+	// The error is a *ProjectError
+	ErrConfigStale = internal.ErrConfigStale
+	// ErrReportDisabled The exposure is not reported as the reporting of the projectID is disabled, see
+	// WithDisableReport and WithReportDisabled
+	ErrReportDisabled = errors.New("exposure reporting is disabled")
+	// ErrInvalidUnitID The unitID of NewUserContext is empty
+	ErrInvalidUnitID = errors.New("invalid unitID")
+	// ErrRateLimited The evaluation or the exposure exceeds the limits of the projectID, see WithEvaluationQPS,
+	// WithMaxConcurrentEvaluations and WithExposureQPS
+	ErrRateLimited = internal.ErrRateLimited
+)
+
+// ProjectError The error of a projectID, errors.As(err, &projectErr) gets the projectID of ErrProjectUnknown and
+// ErrConfigStale
+type ProjectError = internal.ProjectError
+
+// WithMaxConfigAge set the maximum age of the configuration served by the evaluations. The evaluations of the
+// projectIDs whose configuration has not been refreshed successfully within it fail with ErrConfigStale, so that the
+// callers can fall back on their own instead of serving the assignments of a long-partitioned replica.
+// The stateless evaluations are not checked. Zero disables the check, default disabled
+func WithMaxConfigAge(age time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if age < 0 {
+			return errors.Errorf("invalid age:%v", age)
+		}
+		config.MaxConfigAge = age
+		return nil
+	}
+}
+
+// checkConfigAge ErrConfigStale if the configuration of the projectID is older than WithMaxConfigAge
+func checkConfigAge(projectID string) error {
+	if internal.C.MaxConfigAge <= 0 {
+		return nil
+	}
+	syncTime := cache.SyncTime(projectID)
+	if syncTime.IsZero() || time.Since(syncTime) <= internal.C.MaxConfigAge {
+		return nil // the unknown projectID fails in the evaluation
+	}
+	return errors.WithStack(&ProjectError{ProjectID: projectID, Err: ErrConfigStale})
+}
@@ -0,0 +1,48 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedErrors(t *testing.T) {
+	Release()
+	defer Release()
+	_, err := NewUserContext("").GetExperiment(context.Background(), projectID, "layer")
+	assert.True(t, errors.Is(err, ErrInvalidUnitID))
+	_, err = NewUserContext("12345").GetExperiments(context.Background(), projectID)
+	assert.True(t, errors.Is(err, ErrNotInitialized))
+	_, err = SelfTest(context.Background(), projectID)
+	assert.True(t, errors.Is(err, ErrNotInitialized))
+
+	err = Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	_, err = userCtx.GetRemoteConfig(context.Background(), "notExist", "config")
+	assert.True(t, errors.Is(err, ErrProjectUnknown))
+	var projectErr *ProjectError
+	if assert.True(t, errors.As(err, &projectErr)) {
+		assert.Equal(t, "notExist", projectErr.ProjectID)
+	}
+
+	assert.NotNil(t, WithMaxConfigAge(-time.Second)(&internal.GlobalConfig{}))
+	internal.C.MaxConfigAge = time.Hour
+	_, err = userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	internal.C.MaxConfigAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	_, err = userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.True(t, errors.Is(err, ErrConfigStale))
+	if assert.True(t, errors.As(err, &projectErr)) {
+		assert.Equal(t, projectID, projectErr.ProjectID)
+	}
+	internal.C.MaxConfigAge = 0
+}
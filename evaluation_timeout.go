@@ -0,0 +1,61 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"strings"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/pkg/errors"
+)
+
+// WithEvaluationTimeout set the time budget of each evaluation, so that the pathological targeting rules, such as a
+// huge regex or a giant allowlist, cannot stall the hot path. The rules are checked against the budget between the
+// layers and the targeting conditions, the layers and the remote configs not evaluated within it are served the
+// default group or value, and a rule_timeout warning is passed to WithOnWarning.
+// Zero disables the budget, default disabled, see WithEvaluationBudget
+func WithEvaluationTimeout(timeout time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if timeout < 0 {
+			return errors.Errorf("invalid timeout:%v", timeout)
+		}
+		config.EvaluationTimeout = timeout
+		return nil
+	}
+}
+
+// WithEvaluationBudget set the time budget of this evaluation, overriding WithEvaluationTimeout, zero is unlimited
+func WithEvaluationBudget(budget time.Duration) ExperimentOption {
+	return func(options *experiment.Options) error {
+		if budget < 0 {
+			return errors.Errorf("invalid budget:%v", budget)
+		}
+		options.Deadline = time.Time{}
+		if budget > 0 {
+			options.Deadline = time.Now().Add(budget)
+		}
+		return nil
+	}
+}
+
+// setEvaluationDeadline Set the deadline of the evaluation starting now from WithEvaluationTimeout
+func setEvaluationDeadline(options *experiment.Options) {
+	if internal.C.EvaluationTimeout > 0 {
+		options.Deadline = time.Now().Add(internal.C.EvaluationTimeout)
+	}
+}
+
+// reportRuleTimeouts Record and report the layers or remote configs of the evaluation that fell back to the default
+// as the time budget was exceeded
+func reportRuleTimeouts(projectID string, op string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	err := errors.Errorf("%s exceeded the evaluation time budget, [%s] fell back to the default", op,
+		strings.Join(keys, ","))
+	internal.RecordError(string(internal.ReasonRuleTimeout)+":"+projectID, err)
+	internal.ReportWarning(internal.ReasonRuleTimeout, projectID, err)
+}
@@ -0,0 +1,44 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluationTimeout(t *testing.T) {
+	Release()
+	defer Release()
+	var reasons []ErrorReason
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithEvaluationTimeout(time.Nanosecond),
+		WithOnWarning(func(reason ErrorReason, projectID string, err error) {
+			reasons = append(reasons, reason)
+		}))
+	assert.Nil(t, err)
+	assert.NotNil(t, WithEvaluationTimeout(-time.Second)(&internal.GlobalConfig{}))
+	userCtx := NewUserContext("12345")
+	list, err := userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, list.Data)
+	for _, group := range list.Data {
+		assert.True(t, group.IsDefault)
+	}
+	assert.Equal(t, []ErrorReason{ReasonRuleTimeout}, reasons)
+
+	// the budget of the call overrides the one of Init
+	list, err = userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false),
+		WithEvaluationBudget(0))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reasons))
+	var hit bool
+	for _, group := range list.Data {
+		hit = hit || !group.IsDefault
+	}
+	assert.True(t, hit)
+}
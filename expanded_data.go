@@ -0,0 +1,96 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// ExpandedDataEncoding The encoding of the expanded data in the remote config exposures, see WithExpandedDataEncoding
+type ExpandedDataEncoding string
+
+// The supported encodings of the expanded data
+const (
+	// ExpandedDataEncodingLegacy key=value;key=value sorted by the key, the \, ; and = in the keys and the values
+	// are escaped by \. The default
+	ExpandedDataEncodingLegacy ExpandedDataEncoding = "legacy"
+	// ExpandedDataEncodingJSON A JSON object of the string values, such as {"k1":"v1","k2":"v2"}
+	ExpandedDataEncodingJSON ExpandedDataEncoding = "json"
+	// ExpandedDataEncodingURL The URL query encoding sorted by the key, such as k1=v1&k2=v%3D2
+	ExpandedDataEncodingURL ExpandedDataEncoding = "url"
+)
+
+// WithExpandedDataEncoding set the encoding of the expanded data column of the remote config exposures, such as
+// ExpandedDataEncodingJSON, legacy by default. The downstream parsing the column should switch together
+func WithExpandedDataEncoding(encoding ExpandedDataEncoding) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		switch encoding {
+		case ExpandedDataEncodingLegacy, ExpandedDataEncodingJSON, ExpandedDataEncodingURL:
+			config.ExpandedDataEncoding = string(encoding)
+			return nil
+		}
+		return errors.Errorf("invalid expanded data encoding [%s]", encoding)
+	}
+}
+
+// encodeExpandedData Encode the expanded data in the configured encoding, empty if the data is empty
+func encodeExpandedData(data map[string]string) string {
+	if len(data) == 0 {
+		return ""
+	}
+	switch ExpandedDataEncoding(internal.C.ExpandedDataEncoding) {
+	case ExpandedDataEncodingJSON:
+		body, err := json.Marshal(data) // the keys are sorted
+		if err != nil {
+			return ""
+		}
+		return string(body)
+	case ExpandedDataEncodingURL:
+		values := make(url.Values, len(data))
+		for key, value := range data {
+			values.Set(key, value)
+		}
+		return values.Encode()
+	}
+	return encodeLegacyExpandedData(data)
+}
+
+// encodeLegacyExpandedData Encode the data as key=value;key=value sorted by the key, with the separators escaped
+func encodeLegacyExpandedData(data map[string]string) string {
+	var keys = make([]string, 0, len(data))
+	size := 0
+	for key, value := range data {
+		keys = append(keys, key)
+		size += len(key) + len(value) + 2
+	}
+	sort.Strings(keys)
+	var sb bytes.Buffer
+	sb.Grow(size)
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteByte(';')
+		}
+		writeEscapedExpandedData(&sb, key)
+		sb.WriteByte('=')
+		writeEscapedExpandedData(&sb, data[key])
+	}
+	return sb.String()
+}
+
+// writeEscapedExpandedData Write s with the \, ; and = escaped by \
+func writeEscapedExpandedData(sb *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\', ';', '=':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+}
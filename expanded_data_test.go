@@ -0,0 +1,29 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandedDataEncoding(t *testing.T) {
+	defer Release()
+	userCtx := NewUserContext("12345", WithNewUnitID("user1"),
+		WithExpandedData(map[string]string{"k": "a=b;c", "b\\": "1"})).(*userContext)
+	assert.Equal(t, `b\\=1;k=a\=b\;c;new_id=user1`, marshalExpandedData(projectID, userCtx))
+
+	assert.Nil(t, WithExpandedDataEncoding(ExpandedDataEncodingJSON)(internal.C))
+	assert.Equal(t, `{"b\\":"1","k":"a=b;c","new_id":"user1"}`, marshalExpandedData(projectID, userCtx))
+
+	assert.Nil(t, WithExpandedDataEncoding(ExpandedDataEncodingURL)(internal.C))
+	assert.Equal(t, `b%5C=1&k=a%3Db%3Bc&new_id=user1`, marshalExpandedData(projectID, userCtx))
+
+	assert.NotNil(t, WithExpandedDataEncoding("xml")(internal.C))
+	assert.Equal(t, ExpandedDataEncodingURL, ExpandedDataEncoding(internal.C.ExpandedDataEncoding))
+	assert.Empty(t, encodeExpandedData(nil))
+}
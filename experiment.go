@@ -5,13 +5,15 @@ package abc
 
 import (
 	"context"
+	"path"
+	"sync"
 	"time"
 
-	"github.com/abetterchoice/go-sdk/env"
 	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
 	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/abetterchoice/go-sdk/internal/tracing"
 	"github.com/abetterchoice/go-sdk/plugin/log"
-	"github.com/abetterchoice/protoc_event_server"
 	"github.com/pkg/errors"
 )
 
@@ -25,8 +27,67 @@ var (
 		IsPreparedDMPTag:           false,
 		IsDisableDMP:               false,
 	}
+	// experimentOptionsPool Reuse the options and the maps owned by them across the evaluations,
+	// so that a cache-hit evaluation does not allocate them, see getExperimentOptions
+	experimentOptionsPool = sync.Pool{New: func() interface{} { return &experiment.Options{} }}
 )
 
+// getExperimentOptions Get the options initialized to defaultExperimentOptions from the pool, the maps owned by the
+// options, which are only created by the ExperimentOption and fillOption, are cleared and reused, so are the hash batch
+// and the layer buffers of the executor.
+// The options must be returned by putExperimentOptions after the evaluation and its events are done
+func getExperimentOptions() *experiment.Options {
+	options := experimentOptionsPool.Get().(*experiment.Options)
+	sceneIDs, layerKeys, experimentKeys := options.SceneIDs, options.LayerKeys, options.ExperimentKeys
+	experimentTags := options.ExperimentTags
+	dmpTagResult, holdoutLayerResult, hashes := options.DMPTagResult, options.HoldoutLayerResult, options.Hashes
+	hitLayers, layerResult := options.HitLayers, options.LayerResult
+	*options = defaultExperimentOptions
+	options.SceneIDs, options.LayerKeys, options.ExperimentKeys = sceneIDs, layerKeys, experimentKeys
+	options.ExperimentTags = experimentTags
+	options.DMPTagResult, options.HoldoutLayerResult, options.Hashes = dmpTagResult, holdoutLayerResult, hashes
+	options.HitLayers, options.LayerResult = hitLayers, layerResult
+	return options
+}
+
+// putExperimentOptions Clear the owned maps and return the options to the pool,
+// an empty filter map means no filter like a nil one
+func putExperimentOptions(options *experiment.Options) {
+	for key := range options.SceneIDs {
+		delete(options.SceneIDs, key)
+	}
+	for key := range options.LayerKeys {
+		delete(options.LayerKeys, key)
+	}
+	for key := range options.ExperimentKeys {
+		delete(options.ExperimentKeys, key)
+	}
+	for key := range options.ExperimentTags {
+		delete(options.ExperimentTags, key)
+	}
+	for key := range options.DMPTagResult {
+		delete(options.DMPTagResult, key)
+	}
+	for key := range options.HoldoutLayerResult {
+		delete(options.HoldoutLayerResult, key)
+	}
+	for key := range options.LayerResult {
+		delete(options.LayerResult, key)
+	}
+	for i := range options.HitLayers {
+		options.HitLayers[i] = nil
+	}
+	options.HitLayers = options.HitLayers[:0]
+	// release the references to the cache and the user context
+	options.Application, options.AttributeTag, options.OverrideList = nil, nil, nil
+	options.StaticApplication = nil
+	options.ExperimentTagIndex, options.Panics = nil, nil
+	options.Deadline, options.TimedOut = time.Time{}, nil
+	options.LayerKeyPatterns = nil
+	options.Trace, options.Timing = nil, nil
+	experimentOptionsPool.Put(options)
+}
+
 // GetExperiment Assignment is the recommended method for retrieving experiment assignments.
 // This API checks which experiment and group the unit ID from the provided context belongs to,
 // under a specified layer within a given projectID. If the unit ID is part of a specific experiment group,
@@ -39,14 +100,29 @@ var (
 // Note: You can also specify the layerKey in the input parameter opts. However, if layerKey is directly inputted,
 // it will take precedence over the one defined in opts.
 //
-// For more filter conditions, refer to the ExperimentOption definition.
+// For more filter conditions, refer to the ExperimentOption definition. What is returned on a missing layer or
+// projectID, or when no group is hit, is set by WithMissBehavior.
 func (c *userContext) GetExperiment(ctx context.Context, projectID string, layerKey string,
-	opts ...ExperimentOption) (*ExperimentResult, error) {
+	opts ...ExperimentOption) (result *ExperimentResult, err error) {
+	ctx, span := tracing.StartWithKey(ctx, "abc.GetExperiment", projectID, tracing.KeyLayerKey, layerKey)
+	defer func() {
+		if span.IsRecording() {
+			var groupID int64
+			if result != nil && result.Group != nil {
+				groupID = result.ID
+			}
+			span.SetAttributes(tracing.KeyGroupID.Int64(groupID),
+				tracing.KeyConfigLoaded.Bool(cache.GetApplication(projectID) != nil))
+		}
+		tracing.End(span, err)
+	}()
 	// if the layerKey is specified, the layerKeys in options will also be integrated.
 	// this will integrate layerKeys, sceneIDs, experimentKeys in options, and relationships
-	opts = append(opts, WithLayerKey(layerKey))
 	// the underlying implementation is based on GetExperiments
-	experimentList, err := c.GetExperiments(ctx, projectID, opts...)
+	experimentList, err := c.getExperiments(ctx, projectID, layerKey, true, opts)
+	if isMiss(err) {
+		return missExperiment(projectID, layerKey, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +133,7 @@ func (c *userContext) GetExperiment(ctx context.Context, projectID string, layer
 			Group:   e,
 		}, nil
 	}
-	return nil, nil
+	return missExperiment(projectID, layerKey, nil)
 }
 
 // GetExperiments is a batch version of GetExperiment(). It returns the experiment assignments
@@ -69,31 +145,93 @@ func (c *userContext) GetExperiment(ctx context.Context, projectID string, layer
 // Instead, you may need to use the exposure logging API to manually log the exposures.
 func (c *userContext) GetExperiments(ctx context.Context, projectID string,
 	opts ...ExperimentOption) (result *ExperimentList, err error) {
-	options := defaultExperimentOptions // copy, defaultExperimentOptions as template remains unchanged
+	return c.getExperiments(ctx, projectID, "", false, opts)
+}
+
+// GetExperimentsByKeyPattern evaluates all layers whose key matches the glob pattern in one call, such as "recs.*"
+// for the layers created dynamically per surface, see path.Match for the syntax. It behaves like GetExperiments
+// filtered by WithLayerKeyPattern, the exposures are not logged automatically by default
+func (c *userContext) GetExperimentsByKeyPattern(ctx context.Context, projectID string, pattern string,
+	opts ...ExperimentOption) (*ExperimentList, error) {
+	return c.GetExperiments(ctx, projectID, append(opts, WithLayerKeyPattern(pattern))...)
+}
+
+// getExperiments The implementation of GetExperiments, the layerKey of GetExperiment is added to the layer filter
+// after the options if isLayerKey, without allocating the ExperimentOption like WithLayerKey
+func (c *userContext) getExperiments(ctx context.Context, projectID string, layerKey string, isLayerKey bool,
+	opts []ExperimentOption) (result *ExperimentList, err error) {
+	options := getExperimentOptions()
+	defer putExperimentOptions(options) // after the events below, which read the options
+	var sampled bool
 	defer func(startTime time.Time) {
 		latency := time.Since(startTime)
-		if options.IsExposureLoggingAutomatic && !internal.C.IsDisableReport {
-			exposureErr := asyncExposureExperiments(projectID, result, protoc_event_server.ExposureType_EXPOSURE_TYPE_AUTOMATIC)
+		if options.Timing != nil && latency >= internal.C.SlowOpThreshold {
+			asyncSlowOp(newEvaluationSlowOp(projectID, "GetExperiments", latency, options.Timing))
+		}
+		if sampled {
+			writeSampledTrace(projectID, options, result, latency, err)
+		}
+		contextData := internal.ContextData(ctx)
+		if automatic := automaticExperiments(projectID, result, options); automatic != nil &&
+			!internal.IsReportDisabled(projectID) {
+			exposureErr := asyncExposureExperiments(projectID, withContextData(automatic, contextData),
+				automaticExposure)
 			if exposureErr != nil {
-				log.Errorf("[projectID=%v]asyncExposureExperiments fail:%v", projectID, exposureErr)
+				log.LimitedError(ctx, "asyncExposureExperiments"+projectID, "queue exposure fail",
+					log.Any("projectID", projectID), log.Err(exposureErr))
 			}
 		}
-		exposureErr := asyncExposureExperimentEvent(projectID, result, latency, env.JSONString(&options), err)
+		exportAssignments(projectID, result)
+		recordSRM(projectID, result)
+		recordVariants(projectID, result)
+		exposureErr := asyncExposureExperimentEvent(projectID, withContextData(result, contextData), latency, options, err)
 		if exposureErr != nil {
-			log.Errorf("[projectID=%v]asyncExposureExperimentEvent fail:%v", projectID, exposureErr)
+			log.LimitedError(ctx, "asyncExposureExperimentEvent"+projectID, "queue evaluation event fail",
+				log.Any("projectID", projectID), log.Err(exposureErr))
 		}
 	}(time.Now())
+	defer recoverEvaluation(projectID, "GetExperiments", &err)
 	if c.err != nil {
 		return nil, c.err
 	}
-	c.fillOption(&options)
+	if err := checkConfigAge(projectID); err != nil {
+		return nil, err
+	}
+	acquired, err := acquireEvaluation(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if acquired {
+		defer internal.ReleaseEvaluation(projectID)
+	}
+	c.fillOption(options)
+	setEvaluationDeadline(options)
+	if internal.C.SlowOpThreshold > 0 {
+		options.Timing = &experiment.Timing{}
+	}
 	for _, opt := range opts {
-		err := opt(&options)
+		err := opt(options)
 		if err != nil {
 			return nil, errors.Wrap(err, "opt")
 		}
 	}
-	experimentList, err := experiment.Executor.GetExperiments(ctx, projectID, &options)
+	if isLayerKey {
+		if options.LayerKeys == nil {
+			options.LayerKeys = make(map[string]bool, 1)
+		}
+		options.LayerKeys[layerKey] = true
+	}
+	if len(options.ExperimentTags) > 0 {
+		options.ExperimentTagIndex = internal.ExperimentTags(projectID)
+	}
+	if sampled = isTraceSampled(); sampled && options.Trace == nil {
+		options.Trace = &experiment.Trace{}
+	}
+	experimentList, err := experiment.Executor.GetExperiments(ctx, projectID, options)
+	if len(options.Panics) > 0 {
+		reportPanics(projectID, "GetExperiments", options.Panics)
+	}
+	reportRuleTimeouts(projectID, "GetExperiments", options.TimedOut)
 	if err != nil {
 		return nil, err // the error here does not need to be wrapped, it is all GetExperiments
 	}
@@ -124,8 +262,12 @@ func (c *userContext) fillOption(options *experiment.Options) {
 	options.DecisionID = c.decisionID
 	options.NewUnitID = c.newUnitID
 	options.NewDecisionID = c.newDecisionID
-	options.DMPTagResult = make(map[string]bool)
-	options.HoldoutLayerResult = make(map[string]*experiment.Experiment)
+	if options.DMPTagResult == nil { // reused if pooled, see getExperimentOptions
+		options.DMPTagResult = make(map[string]bool)
+	}
+	if options.HoldoutLayerResult == nil {
+		options.HoldoutLayerResult = make(map[string]*experiment.Experiment)
+	}
 	options.IsDisableDMP = internal.C.IsDisableDMP
 }
 
@@ -235,6 +377,22 @@ func WithLayerKeyList(layerKeyList []string) ExperimentOption {
 	}
 }
 
+// WithExperimentTags Set filtering by experiment tag, it only focuses on the hits of experiments in the layers
+// having an experiment carrying one of the tags, so that the services skip the evaluation of the unrelated layers.
+// The tags of the experiments are set by WithExperimentTagIndex, no layer passes if none is set.
+// Like WithExperimentKeys, if the user hits another experiment in such a layer, it is returned too
+func WithExperimentTags(tags ...string) ExperimentOption {
+	return func(options *experiment.Options) error {
+		if options.ExperimentTags == nil {
+			options.ExperimentTags = make(map[string]bool, len(tags))
+		}
+		for _, tag := range tags {
+			options.ExperimentTags[tag] = true
+		}
+		return nil
+	}
+}
+
 // WithSceneID is the same as WithSceneIDList. This is convenient for use with only one scene.
 func WithSceneID(sceneID int64) ExperimentOption {
 	return func(options *experiment.Options) error {
@@ -258,12 +416,92 @@ func WithLayerKey(layerKey string) ExperimentOption {
 	}
 }
 
+// WithLayerKeyPattern set filtering by the glob pattern of the layer key, such as "recs.*", see path.Match for the
+// syntax. The layers matching it are added to the layers of WithLayerKey, no layer is returned if none matches
+func WithLayerKeyPattern(pattern string) ExperimentOption {
+	return func(options *experiment.Options) error {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid pattern %s", pattern)
+		}
+		options.LayerKeyPatterns = append(options.LayerKeyPatterns, pattern)
+		return nil
+	}
+}
+
 // WithAutomatic sets whether TAB automatically records exposure
 func WithAutomatic(isAutomatic bool) ExperimentOption {
-	return func(options *experiment.Options) error {
-		options.IsExposureLoggingAutomatic = isAutomatic
+	if isAutomatic {
+		return automaticOn
+	}
+	return automaticOff
+}
+
+// automaticOn automaticOff The options of WithAutomatic, shared to avoid allocating a closure per call
+var (
+	automaticOn ExperimentOption = func(options *experiment.Options) error {
+		options.IsExposureLoggingAutomatic = true
+		options.IsExposureLoggingAutomaticSet = true
+		return nil
+	}
+	automaticOff ExperimentOption = func(options *experiment.Options) error {
+		options.IsExposureLoggingAutomatic = false
+		options.IsExposureLoggingAutomaticSet = true
+		return nil
+	}
+)
+
+// automaticExperiments The experiments of the list logging the exposures automatically, nil if none. Unless
+// specified by WithAutomatic, the automatic exposure of the scenes of the projectID overrides the default one,
+// see WithSceneAutomatic
+func automaticExperiments(projectID string, list *ExperimentList, options *experiment.Options) *ExperimentList {
+	if list == nil {
+		return nil
+	}
+	sceneAutomatic := internal.SceneAutomatic(projectID)
+	if options.IsExposureLoggingAutomaticSet || len(sceneAutomatic) == 0 {
+		if options.IsExposureLoggingAutomatic {
+			return list
+		}
+		return nil
+	}
+	var result *ExperimentList
+	for layerKey, group := range list.Data {
+		if !isSceneAutomatic(group, sceneAutomatic, options.IsExposureLoggingAutomatic) {
+			if result == nil { // copy on the first manual experiment
+				result = &ExperimentList{userCtx: list.userCtx, Data: make(map[string]*Group, len(list.Data))}
+				for key, value := range list.Data {
+					result.Data[key] = value
+				}
+			}
+			delete(result.Data, layerKey)
+		}
+	}
+	if result == nil {
+		result = list
+	}
+	if len(result.Data) == 0 {
 		return nil
 	}
+	return result
+}
+
+// isSceneAutomatic Whether the group logs the exposure automatically by its scenes, any disabled scene disables it
+func isSceneAutomatic(group *Group, sceneAutomatic map[int64]bool, isDefault bool) bool {
+	if group == nil {
+		return isDefault
+	}
+	isAutomatic := isDefault
+	for _, sceneID := range group.sceneIDList {
+		isSceneAutomatic, ok := sceneAutomatic[sceneID]
+		if !ok {
+			continue
+		}
+		if !isSceneAutomatic {
+			return false
+		}
+		isAutomatic = true
+	}
+	return isAutomatic
 }
 
 // WithIsPreparedDMPTag sets whether to preprocess DMP tags
@@ -0,0 +1,104 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"sort"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// ExperimentMetadata The read-only metadata of an experiment in the locally cached configuration, for the tooling
+// displaying the experiment context alongside the assignments. The configuration served to the SDK carries the
+// targeting and the traffic of the experiments only, the description, owner and schedule are kept by the platform
+type ExperimentMetadata struct {
+	ExperimentID  int64  `json:"experimentId"`
+	ExperimentKey string `json:"experimentKey"`
+	LayerKey      string `json:"layerKey"`
+	// The issue type of the experiment, such as ISSUE_TYPE_PERCENTAGE or ISSUE_TYPE_TAG
+	IssueType string `json:"issueType"`
+	// The fraction of the traffic of the layer allocated to the experiment, in [0, 1]
+	TrafficPercent float64 `json:"trafficPercent"`
+	// The revision of the configuration the metadata is read from, see GetConfigRevision
+	Revision string `json:"revision"`
+	// The groups of the experiment in the order of the group ID
+	Groups []*GroupMetadata `json:"groups"`
+}
+
+// GroupMetadata The read-only metadata of a group of the experiment
+type GroupMetadata struct {
+	ID          int64                        `json:"id"`
+	Key         string                       `json:"key"`
+	IsControl   bool                         `json:"isControl"`
+	SceneIDList []int64                      `json:"sceneIdList,omitempty"`
+	UnitIDType  protoccacheserver.UnitIDType `json:"unitIdType"`
+	// The fraction of the traffic of the experiment allocated to the group, in [0, 1]
+	TrafficPercent float64 `json:"trafficPercent"`
+}
+
+// GetExperimentMetadata Get the metadata of the experiment of the layer from the locally cached configuration
+func GetExperimentMetadata(projectID string, layerKey string, experimentKey string) (*ExperimentMetadata, error) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	layer, ok := application.LayerIndex[layerKey]
+	if !ok {
+		return nil, errors.Wrapf(ErrLayerNotFound, "layerKey [%s]", layerKey)
+	}
+	var experiment *protoccacheserver.Experiment
+	for _, candidate := range layer.ExperimentIndex {
+		if candidate.Key == experimentKey {
+			experiment = candidate
+			break
+		}
+	}
+	if experiment == nil {
+		return nil, errors.Errorf("experimentKey [%s] not found in the layer [%s]", experimentKey, layerKey)
+	}
+	metadata := &ExperimentMetadata{
+		ExperimentID:  experiment.Id,
+		ExperimentKey: experiment.Key,
+		LayerKey:      layerKey,
+		IssueType:     experiment.IssueType.String(),
+		Revision:      application.Revision,
+	}
+	if layer.Metadata != nil && layer.Metadata.BucketSize > 0 {
+		metadata.TrafficPercent = float64(bucketCount(application.ExperimentIDBucketInfoIndex[experiment.Id],
+			application.ExperimentIDRoaringBitmapIndex[experiment.Id])) / float64(layer.Metadata.BucketSize)
+	}
+	for groupID := range experiment.GroupIdIndex {
+		group, ok := layer.GroupIndex[groupID]
+		if !ok {
+			continue
+		}
+		groupMetadata := &GroupMetadata{
+			ID:          group.Id,
+			Key:         group.GroupKey,
+			IsControl:   group.IsControl,
+			SceneIDList: append([]int64(nil), group.SceneIdList...),
+			UnitIDType:  group.UnitIdType,
+		}
+		if experiment.BucketSize > 0 {
+			groupMetadata.TrafficPercent = groupWeight(application, groupID) / float64(experiment.BucketSize)
+		}
+		metadata.Groups = append(metadata.Groups, groupMetadata)
+	}
+	sort.Slice(metadata.Groups, func(i, j int) bool {
+		return metadata.Groups[i].ID < metadata.Groups[j].ID
+	})
+	return metadata, nil
+}
+
+// Metadata Get the metadata of the experiment of the group from the locally cached configuration of the projectID,
+// see GetExperimentMetadata. The default groups belong to no experiment
+func (g *Group) Metadata(projectID string) (*ExperimentMetadata, error) {
+	if g == nil || g.IsDefault || len(g.ExperimentKey) == 0 {
+		return nil, errors.Errorf("the group belongs to no experiment")
+	}
+	return GetExperimentMetadata(projectID, g.LayerKey, g.ExperimentKey)
+}
@@ -0,0 +1,41 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExperimentMetadata(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	metadata, err := GetExperimentMetadata(projectID, "doubleHashLayerTag", "301001")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(301001), metadata.ExperimentID)
+	assert.Equal(t, "ISSUE_TYPE_TAG", metadata.IssueType)
+	assert.Equal(t, 1.0, metadata.TrafficPercent)
+	assert.Equal(t, 2, len(metadata.Groups))
+	assert.Equal(t, int64(301001001), metadata.Groups[0].ID)
+	assert.Equal(t, 0.5, metadata.Groups[0].TrafficPercent)
+
+	group := &Group{ExperimentKey: "301001", LayerKey: "doubleHashLayerTag"}
+	got, err := group.Metadata(projectID)
+	assert.Nil(t, err)
+	assert.Equal(t, metadata, got)
+
+	_, err = (&Group{IsDefault: true, LayerKey: "doubleHashLayerTag"}).Metadata(projectID)
+	assert.NotNil(t, err)
+	_, err = GetExperimentMetadata(projectID, "doubleHashLayerTag", "notExist")
+	assert.NotNil(t, err)
+	_, err = GetExperimentMetadata(projectID, "notExist", "301001")
+	assert.True(t, errors.Is(err, ErrLayerNotFound))
+	_, err = GetExperimentMetadata("notExist", "doubleHashLayerTag", "301001")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,87 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// MultiProjectResult The results of EvaluateAcrossProjects, keyed by the projectID
+type MultiProjectResult struct {
+	// The assignments of the projects, nil if the user is not in the layer of the project
+	Results map[string]*ExperimentResult
+	// The errors of the projects failing to evaluate, such as the project not being initialized
+	Errors map[string]error
+}
+
+// EvaluateAcrossProjects Evaluate the same layerKey of the user in every project in a single call, for the platform
+// teams running the same experiment across several projects. The projects are evaluated concurrently and each
+// independently, the same as GetExperiment(ctx, projectID, layerKey, opts...), so the automatic exposures are routed
+// to the metrics plugin of their own projects. The failure of one project does not fail the others, the error is
+// returned only if all the projects fail
+func EvaluateAcrossProjects(ctx context.Context, projectIDs []string, userCtx Context, layerKey string,
+	opts ...ExperimentOption) (*MultiProjectResult, error) {
+	if len(projectIDs) == 0 {
+		return nil, errors.Errorf("projectIDs are required")
+	}
+	if userCtx == nil {
+		return nil, errors.Errorf("userCtx is required")
+	}
+	result := &MultiProjectResult{
+		Results: make(map[string]*ExperimentResult, len(projectIDs)),
+		Errors:  map[string]error{},
+	}
+	var (
+		lock sync.Mutex
+		wg   sync.WaitGroup
+	)
+	for _, projectID := range projectIDs {
+		lock.Lock()
+		_, ok := result.Results[projectID]
+		if !ok {
+			result.Results[projectID] = nil // deduplicate
+		}
+		lock.Unlock()
+		if ok {
+			continue
+		}
+		wg.Add(1)
+		go func(projectID string) {
+			defer wg.Done()
+			experiment, err := userCtx.GetExperiment(ctx, projectID, layerKey, opts...)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				delete(result.Results, projectID)
+				result.Errors[projectID] = err
+				return
+			}
+			result.Results[projectID] = experiment
+		}(projectID)
+	}
+	wg.Wait()
+	if len(result.Results) == 0 {
+		for projectID, err := range result.Errors {
+			return result, errors.Wrapf(err, "all projects fail, such as %s", projectID)
+		}
+	}
+	return result, nil
+}
+
+// LogExposure Log the exposures of the results to their own projects, used with WithAutomatic(false)
+func (r *MultiProjectResult) LogExposure(ctx context.Context) error {
+	var lastErr error
+	for projectID, experiment := range r.Results {
+		if experiment == nil {
+			continue
+		}
+		if err := LogExperimentExposure(ctx, projectID, experiment); err != nil {
+			lastErr = errors.Wrapf(err, "project %s", projectID)
+		}
+	}
+	return lastErr
+}
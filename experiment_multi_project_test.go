@@ -0,0 +1,34 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateAcrossProjects(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	ctx := context.Background()
+	userCtx := NewUserContext("12345")
+	result, err := EvaluateAcrossProjects(ctx, []string{projectID, "notExist", projectID}, userCtx,
+		"overrideLayer", WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result.Results))
+	assert.NotNil(t, result.Results[projectID])
+	assert.Equal(t, int64(100001001), result.Results[projectID].ID)
+	assert.NotNil(t, result.Errors["notExist"])
+	assert.Nil(t, result.LogExposure(ctx))
+
+	result, err = EvaluateAcrossProjects(ctx, []string{"notExist"}, userCtx, "overrideLayer")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(result.Errors))
+	_, err = EvaluateAcrossProjects(ctx, nil, userCtx, "overrideLayer")
+	assert.NotNil(t, err)
+}
@@ -4,12 +4,20 @@ package abc
 import (
 	"context"
 	"math"
+	"math/rand"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
 	"github.com/abetterchoice/go-sdk/testdata"
 	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/protobuf/proto"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -325,3 +333,216 @@ func equalWithRange(actual, want int64, total int, r float64) bool {
 	}
 	return math.Abs(float64(actual-want))/float64(total) < r || math.Abs(float64(actual-want))/float64(want) < 0.08
 }
+
+// Benchmark the cache-hit evaluation without exposure, the monitor event sampling is disabled,
+// its budget of 8 allocs/op is guarded by TestGetExperimentAllocs
+func BenchmarkGetExperiment(b *testing.B) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(b)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err = UpdateOptions(projectID, WithEventSamplingInterval(math.MaxUint32)); err != nil {
+		b.Fatal(err)
+	}
+	userCtx := NewUserContext("12345")
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = userCtx.GetExperiment(ctx, projectID, "multiLayer2", WithAutomatic(false))
+	}
+}
+
+// TestGetExperimentAllocs Guard the allocations of the cache-hit evaluation of BenchmarkGetExperiment, the budget is
+// the returned result, list and group, the default group of the layer and the WithAutomatic option of the caller
+func TestGetExperimentAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the pooled options are dropped by the race detector")
+	}
+	Release() // initialized by the previous tests
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	assert.Nil(t, UpdateOptions(projectID, WithEventSamplingInterval(math.MaxUint32)))
+	userCtx := NewUserContext("12345")
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = userCtx.GetExperiment(ctx, projectID, "multiLayer2", WithAutomatic(false))
+	})
+	assert.LessOrEqual(t, allocs, float64(8))
+}
+
+// TestSyntheticFuzz Evaluate the random configurations of the generator with the random units,
+// neither the parsing nor the evaluation may fail
+func TestSyntheticFuzz(t *testing.T) {
+	Release() // initialized by the previous tests
+	defer Release()
+	for seed := int64(1); seed <= 20; seed++ {
+		spec := testdata.SyntheticSpec{Layers: 5, Groups: 4, Rules: 3, Scenes: 2, FeatureFlags: 3, Seed: seed}
+		tabConfig, groupBucketInfo := testdata.GenerateSyntheticTabConfig(spec)
+		again, _ := testdata.GenerateSyntheticTabConfig(spec)
+		assert.True(t, proto.Equal(tabConfig, again), "seed %d", seed)
+		err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClientWithData(t,
+			tabConfig, map[int64]*protoc_cache_server.BucketInfo{}, groupBucketInfo)),
+			WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+		assert.Nil(t, err, "seed %d", seed)
+		r := rand.New(rand.NewSource(seed))
+		for i := 0; i < 50; i++ {
+			tags := make(map[string][]string, spec.Rules)
+			for j := 0; j < spec.Rules; j++ {
+				tags[testdata.SyntheticTagKey(j)] = []string{
+					testdata.SyntheticFuzzValues[r.Intn(len(testdata.SyntheticFuzzValues))]}
+			}
+			userCtx := NewUserContext(strconv.Itoa(r.Int()), WithTags(tags))
+			_, err = userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false),
+				WithSceneIDList([]int64{testdata.SyntheticSceneID(i % spec.Scenes)}))
+			assert.Nil(t, err, "seed %d", seed)
+			_, err = userCtx.GetFeatureFlag(context.Background(), projectID,
+				testdata.SyntheticFeatureFlagKey(i%spec.FeatureFlags), WithAutomatic(false))
+			assert.Nil(t, err, "seed %d", seed)
+		}
+		Release()
+	}
+}
+
+func TestAutomaticExperiments(t *testing.T) {
+	defer Release()
+	list := &ExperimentList{Data: map[string]*Group{
+		"layer1": {ID: 1, sceneIDList: []int64{1}},
+		"layer2": {ID: 2, sceneIDList: []int64{1, 2}},
+		"layer3": {ID: 3, sceneIDList: []int64{3}},
+	}}
+	automatic := getExperimentOptions()
+	defer putExperimentOptions(automatic)
+	manual := getExperimentOptions()
+	defer putExperimentOptions(manual)
+	assert.Nil(t, automaticOff(manual))
+	assert.Equal(t, list, automaticExperiments(projectID, list, automatic))
+	assert.Nil(t, automaticExperiments(projectID, list, manual))
+
+	assert.Nil(t, UpdateOptions(projectID, WithSceneAutomatic(map[int64]bool{1: true, 2: false})))
+	got := automaticExperiments(projectID, list, automatic)
+	assert.Equal(t, []string{"layer1", "layer3"}, sortedLayerKeys(got))
+	assert.Equal(t, 3, len(list.Data))                           // not modified
+	assert.Nil(t, automaticExperiments(projectID, list, manual)) // WithAutomatic takes precedence
+
+	// the scenes override the default
+	defaultManual := getExperimentOptions()
+	defer putExperimentOptions(defaultManual)
+	defaultManual.IsExposureLoggingAutomatic = false
+	assert.Equal(t, []string{"layer1"}, sortedLayerKeys(automaticExperiments(projectID, list, defaultManual)))
+
+	assert.Nil(t, UpdateOptions(projectID, WithSceneAutomatic(map[int64]bool{1: false, 3: false})))
+	assert.Nil(t, automaticExperiments(projectID, list, automatic))
+	assert.Nil(t, UpdateOptions(projectID, WithSceneAutomatic(nil)))
+	assert.Equal(t, list, automaticExperiments(projectID, list, automatic))
+}
+
+func sortedLayerKeys(list *ExperimentList) []string {
+	if list == nil {
+		return nil
+	}
+	var result []string
+	for layerKey := range list.Data {
+		result = append(result, layerKey)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestWithExperimentTags(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	list, err := userCtx.GetExperiments(context.Background(), projectID, WithExperimentTags("checkout"),
+		WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Empty(t, list.Data) // no experiment is tagged
+
+	assert.Nil(t, UpdateOptions(projectID, WithExperimentTagIndex(map[string][]string{
+		"100002": {"checkout", "mobile"},
+		"301001": {"search"},
+	})))
+	list, err = userCtx.GetExperiments(context.Background(), projectID, WithExperimentTags("checkout"),
+		WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"overrideLayer"}, sortedLayerKeys(list))
+	list, err = userCtx.GetExperiments(context.Background(), projectID, WithExperimentTags("mobile", "search"),
+		WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"doubleHashLayerTag", "overrideLayer"}, sortedLayerKeys(list))
+	// AND with the layer filter
+	list, err = userCtx.GetExperiments(context.Background(), projectID, WithExperimentTags("checkout"),
+		WithLayerKey("doubleHashLayerTag"), WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Empty(t, list.Data)
+}
+
+func TestGetExperimentsByKeyPattern(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	list, err := userCtx.GetExperimentsByKeyPattern(context.Background(), projectID, "doubleHash*",
+		WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, list.Data)
+	for layerKey := range list.Data {
+		assert.True(t, strings.HasPrefix(layerKey, "doubleHash"))
+	}
+	// merged with the layer filter
+	list, err = userCtx.GetExperimentsByKeyPattern(context.Background(), projectID, "doubleHash*",
+		WithLayerKey("overrideLayer"), WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Contains(t, sortedLayerKeys(list), "overrideLayer")
+	list, err = userCtx.GetExperimentsByKeyPattern(context.Background(), projectID, "recs.*", WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Empty(t, list.Data)
+	_, err = userCtx.GetExperimentsByKeyPattern(context.Background(), projectID, "[", WithAutomatic(false))
+	assert.NotNil(t, err)
+}
+
+func TestWithEvaluationMemo(t *testing.T) {
+	Release()
+	defer Release()
+	assert.NotNil(t, WithEvaluationMemo(0, time.Minute)(&internal.GlobalConfig{}))
+	assert.NotNil(t, WithEvaluationMemo(10, 0)(&internal.GlobalConfig{}))
+	evaluate := func() map[string]map[string]int64 {
+		results := make(map[string]map[string]int64)
+		for i := 0; i < 50; i++ {
+			unitID := strconv.Itoa(i)
+			list, err := NewUserContext(unitID).GetExperiments(context.Background(), projectID, WithAutomatic(false))
+			assert.Nil(t, err)
+			results[unitID] = make(map[string]int64)
+			for layerKey, group := range list.Data {
+				results[unitID][layerKey] = group.ID
+			}
+			// evaluated in the random order of its groups, not memoized as it has dmp tags
+			delete(results[unitID], "doubleHashLayerCityTag")
+		}
+		return results
+	}
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	expected := evaluate()
+	assert.Equal(t, 0, experiment.MemoLen())
+	Release()
+
+	err = Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithEvaluationMemo(100, time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, evaluate())
+	assert.Equal(t, 100, experiment.MemoLen()) // bounded
+	assert.Equal(t, expected, evaluate())      // served from the memo
+	Release()
+	assert.Equal(t, 0, experiment.MemoLen())
+}
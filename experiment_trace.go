@@ -0,0 +1,82 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+)
+
+// TraceStep A single step of the evaluation, see experiment.TraceStep
+type TraceStep = experiment.TraceStep
+
+// TraceStepType Type of the evaluation trace step
+type TraceStepType = experiment.TraceStepType
+
+// TraceRange Closed bucket range of the trace step
+type TraceRange = experiment.TraceRange
+
+// const ...
+const (
+	TraceStepLayerLookup = experiment.TraceStepLayerLookup
+	TraceStepDomain      = experiment.TraceStepDomain
+	TraceStepHoldout     = experiment.TraceStepHoldout
+	TraceStepOverride    = experiment.TraceStepOverride
+	TraceStepFilter      = experiment.TraceStepFilter
+	TraceStepHash        = experiment.TraceStepHash
+	TraceStepBucket      = experiment.TraceStepBucket
+	TraceStepRule        = experiment.TraceStepRule
+	TraceStepDefault     = experiment.TraceStepDefault
+	TraceStepResult      = experiment.TraceStepResult
+)
+
+// EvaluationTrace Step-by-step explanation of an assignment, such as the layer lookup, the holdout check,
+// the rule evaluations with pass or fail, the hash value and the bucket range. It can be serialized to JSON directly,
+// which is used to power a debug endpoint
+type EvaluationTrace struct {
+	ProjectID string       `json:"projectId"`
+	LayerKey  string       `json:"layerKey"`
+	UnitID    string       `json:"unitId"`
+	Version   string       `json:"version"` // version of the local cache used by the evaluation
+	Steps     []*TraceStep `json:"steps"`
+	Error     string       `json:"error,omitempty"`
+
+	trace *experiment.Trace // filled by the evaluation
+}
+
+// GetExperimentWithTrace Get the experiment of the layerKey like Context.GetExperiment,
+// and return the trace of the evaluation. The exposure is not logged automatically unless WithAutomatic(true)
+// is passed in opts. The trace is returned even if the evaluation fails, with the steps recorded before the failure.
+// It records every step and allocates, do not use it in the hot path
+func GetExperimentWithTrace(ctx context.Context, userCtx Context, projectID string, layerKey string,
+	opts ...ExperimentOption) (*ExperimentResult, *EvaluationTrace, error) {
+	trace := &EvaluationTrace{ProjectID: projectID, LayerKey: layerKey}
+	if application := cache.GetApplication(projectID); application != nil {
+		trace.Version = application.Version
+	}
+	options := make([]ExperimentOption, 0, len(opts)+2)
+	options = append(options, WithAutomatic(false))
+	options = append(options, opts...)
+	options = append(options, withTrace(trace))
+	result, err := userCtx.GetExperiment(ctx, projectID, layerKey, options...)
+	if trace.trace != nil {
+		trace.Steps = trace.trace.Steps
+	}
+	if err != nil {
+		trace.Error = err.Error()
+	}
+	return result, trace, err
+}
+
+// withTrace Record the evaluation steps into trace
+func withTrace(trace *EvaluationTrace) ExperimentOption {
+	return func(options *experiment.Options) error {
+		trace.UnitID = options.UnitID
+		trace.trace = &experiment.Trace{}
+		options.Trace = trace.trace
+		return nil
+	}
+}
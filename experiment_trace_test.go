@@ -0,0 +1,43 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExperimentWithTrace(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	result, trace, err := GetExperimentWithTrace(context.Background(), userCtx, projectID, "multiLayer2")
+	assert.Nil(t, err)
+	assert.Equal(t, projectID, trace.ProjectID)
+	assert.Equal(t, "12345", trace.UnitID)
+	if !assert.NotEmpty(t, trace.Steps) {
+		return
+	}
+	assert.Equal(t, TraceStepLayerLookup, trace.Steps[0].Type)
+	last := trace.Steps[len(trace.Steps)-1]
+	assert.Equal(t, TraceStepResult, last.Type)
+	assert.Equal(t, "multiLayer2", last.LayerKey)
+	if result != nil {
+		assert.Equal(t, result.ID, last.GroupID)
+	}
+	_, err = json.Marshal(trace)
+	assert.Nil(t, err)
+
+	_, trace, err = GetExperimentWithTrace(context.Background(), userCtx, projectID, "notExist")
+	assert.NotNil(t, err)
+	assert.NotEmpty(t, trace.Error)
+	if assert.Equal(t, 1, len(trace.Steps)) {
+		assert.Equal(t, TraceStepLayerLookup, trace.Steps[0].Type)
+		assert.False(t, trace.Steps[0].Passed)
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
 // Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
 // It also encompasses functionalities such as traffic distribution for A/B experiments,
 // user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
@@ -7,16 +10,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"sort"
 	"strconv"
 	"time"
 
 	"github.com/abetterchoice/go-sdk/env"
 	"github.com/abetterchoice/go-sdk/internal"
 	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/tracing"
 	"github.com/abetterchoice/go-sdk/plugin/log"
 	"github.com/abetterchoice/go-sdk/plugin/metrics"
 	"github.com/abetterchoice/protoc_event_server"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -32,8 +36,17 @@ const (
 // // managing exposure logging in this manner can assist in preventing the potential over-exposure issue
 // that may arise from automatic exposure logging.
 func LogExperimentsExposure(ctx context.Context, projectID string, list *ExperimentList) error {
+	if err := checkReport(projectID); err != nil {
+		return err
+	}
+	if list != nil {
+		if err := checkExposureRate(projectID, len(list.Data)); err != nil {
+			return err
+		}
+	}
 	// User records exposure manually
-	return exposureExperiments(ctx, projectID, list, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
+	return exposureExperiments(ctx, projectID, withContextData(list, internal.ContextData(ctx)),
+		protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
 }
 
 // LogExperimentExposure When automatic exposure-logging is disabled,
@@ -45,22 +58,91 @@ func LogExperimentExposure(ctx context.Context, projectID string, result *Experi
 	if result == nil || result.userCtx == nil || result.Group == nil {
 		return nil
 	}
+	if err := checkReport(projectID); err != nil {
+		return err
+	}
+	if err := checkExposureRate(projectID, 1); err != nil {
+		return err
+	}
 	return exposureExperiments(ctx, projectID, &ExperimentList{
 		userCtx: result.userCtx,
 		Data: map[string]*Group{
 			result.LayerKey: result.Group,
 		},
+		contextData: internal.ContextData(ctx),
 	}, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
 }
 
 // LogFeatureFlagExposure The incoming featureFlag is generated by GetFeatureFlag.
 func LogFeatureFlagExposure(ctx context.Context, projectID string, featureFlag *FeatureFlag) error {
+	if err := checkReport(projectID); err != nil {
+		return err
+	}
+	if featureFlag != nil {
+		if err := checkExposureRate(projectID, 1); err != nil {
+			return err
+		}
+		featureFlag = &FeatureFlag{
+			ConfigResult: withConfigContextData(featureFlag.ConfigResult, internal.ContextData(ctx)),
+		}
+	}
 	return exposureFeatureFlag(ctx, projectID, featureFlag, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
 }
 
 // LogRemoteConfigExposure The incoming config is generated by GetRemoteConfig.
 func LogRemoteConfigExposure(ctx context.Context, projectID string, config *ConfigResult) error {
-	return exposureRemoteConfig(ctx, projectID, config, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
+	if err := checkReport(projectID); err != nil {
+		return err
+	}
+	if config != nil {
+		if err := checkExposureRate(projectID, 1); err != nil {
+			return err
+		}
+	}
+	return exposureRemoteConfig(ctx, projectID, withConfigContextData(config, internal.ContextData(ctx)),
+		protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
+}
+
+// LogRolloutExposure Log the exposure of the unitID to the rollout of the key as a manual exposure of the layer of
+// the key, in the experiment RolloutGroupKey or HoldbackGroupKey with the group ID 0, so that the rollout can be
+// analyzed like an experiment. It is reported through the default experiment metrics config of the projectID
+func LogRolloutExposure(ctx context.Context, projectID string, key string, unitID string, inRollout bool) error {
+	userCtx := settingNewUnitIDAndNewDecisionID(&userContext{unitID: unitID, tags: map[string][]string{}})
+	if userCtx.err != nil {
+		return userCtx.err
+	}
+	if err := checkReport(projectID); err != nil {
+		return err
+	}
+	if err := checkExposureRate(projectID, 1); err != nil {
+		return err
+	}
+	group := &Group{Key: HoldbackGroupKey, ExperimentKey: HoldbackGroupKey, LayerKey: key, IsControl: !inRollout}
+	if inRollout {
+		group.Key, group.ExperimentKey = RolloutGroupKey, RolloutGroupKey
+	}
+	return exposureExperiments(ctx, projectID, &ExperimentList{
+		userCtx:     userCtx,
+		Data:        map[string]*Group{key: group},
+		contextData: internal.ContextData(ctx),
+	}, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
+}
+
+// isEventSampled Whether the evaluation event of the projectID passes the sampling, the frequency of event reporting is
+// not high, sampling first in the calling goroutine avoids building and enqueueing the unsampled events
+func isEventSampled(projectID string, err error) bool {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return false
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return false
+	}
+	if err == nil && !internal.ShedSampled() { // the error events are never shed
+		return false
+	}
+	return metrics.SamplingResult(eventSamplingInterval(projectID, metricsConfig, err))
 }
 
 // exposureExperimentEvent experimental diversion events
@@ -75,33 +157,34 @@ func exposureExperimentEvent(ctx context.Context, projectID string, list *Experi
 	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
 		return nil
 	}
-	// Sampling first, the frequency of event reporting is not high, sampling first improves efficiency
-	if !metrics.SamplingResult(env.SamplingInterval(metricsConfig, err)) {
-		return nil // 采样不通过
-	}
+	// sampled before enqueueing, see isEventSampled
+	event := newMonitorEventMessage()
+	event.Time = time.Now().Unix()
+	event.Ip = env.LocalIP()
+	event.ProjectId = projectID
+	event.EventName = "exp"
+	event.Latency = float32(latency.Microseconds()) // us
+	event.StatusCode = env.EventStatus(err)
+	event.Message = env.ErrMsg(err)
+	event.SdkType = env.SDKType
+	event.SdkVersion = env.Version
+	event.InvokePath = env.InvokePath(4) // 跳过 4 层调用栈
+	event.InputData = optionStr
+	event.OutputData = experimentIDList(list)
+	var contextData map[string]string
+	if list != nil {
+		contextData = mergeContextData(nil, list.contextData) // copied, the list is shared by the exposures
+	}
+	event.ExtInfo = internal.EventExtInfo(contextData)
+	group := &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{event}}
+	defer releaseMonitorEventGroup(group, metricsConfig.PluginName)
 	return metrics.LogMonitorEvent(ctx, &metrics.Metadata{
 		MetricsPluginName: metricsConfig.PluginName,
 		TableName:         metricsConfig.Metadata.Name,
 		TableID:           metricsConfig.Metadata.Id,
-		Token:             metricsConfig.Metadata.Token,
+		Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
 		SamplingInterval:  1, // 已经先采样了，这里恒上报
-	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
-		{
-			Time:       time.Now().Unix(),
-			Ip:         env.LocalIP(),
-			ProjectId:  projectID,
-			EventName:  "exp",
-			Latency:    float32(latency.Microseconds()), // us
-			StatusCode: env.EventStatus(err),
-			Message:    env.ErrMsg(err),
-			SdkType:    env.SDKType,
-			SdkVersion: env.Version,
-			InvokePath: env.InvokePath(4), // 跳过 4 层调用栈
-			InputData:  optionStr,
-			OutputData: experimentIDList(list),
-			ExtInfo:    nil,
-		},
-	}})
+	}, group)
 }
 
 // exposureRemoteConfigEvent Report remote configuration acquisition events
@@ -116,37 +199,39 @@ func exposureRemoteConfigEvent(ctx context.Context, projectID string, config *Co
 	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
 		return nil
 	}
-	if !metrics.SamplingResult(env.SamplingInterval(metricsConfig, err)) {
-		return nil
-	}
+	// sampled before enqueueing, see isEventSampled
 	// Report data
 	var resultData string
 	if config != nil {
-		resultData = string(config.data)
+		resultData = config.exposedValue()
+	}
+	event := newMonitorEventMessage()
+	event.Time = time.Now().Unix()
+	event.Ip = env.LocalIP()
+	event.ProjectId = projectID
+	event.EventName = "rc"
+	event.Latency = float32(latency.Microseconds()) // us
+	event.StatusCode = env.EventStatus(err)
+	event.Message = env.ErrMsg(err)
+	event.SdkType = env.SDKType
+	event.SdkVersion = env.Version
+	event.InvokePath = env.InvokePath(4) // 跳过 4 层调用栈
+	event.InputData = optionStr
+	event.OutputData = resultData
+	var contextData map[string]string
+	if config != nil {
+		contextData = mergeContextData(nil, config.contextData) // copied, the config is shared by the exposures
 	}
+	event.ExtInfo = internal.EventExtInfo(contextData)
+	group := &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{event}}
+	defer releaseMonitorEventGroup(group, metricsConfig.PluginName)
 	return metrics.LogMonitorEvent(ctx, &metrics.Metadata{
 		MetricsPluginName: metricsConfig.PluginName,
 		TableName:         metricsConfig.Metadata.Name,
 		TableID:           metricsConfig.Metadata.Id,
-		Token:             metricsConfig.Metadata.Token,
+		Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
 		SamplingInterval:  1, // 已经先采样了，这里恒上报
-	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
-		{
-			Time:       time.Now().Unix(),
-			Ip:         env.LocalIP(),
-			ProjectId:  projectID,
-			EventName:  "rc",
-			Latency:    float32(latency.Microseconds()), // us
-			StatusCode: env.EventStatus(err),
-			Message:    env.ErrMsg(err),
-			SdkType:    env.SDKType,
-			SdkVersion: env.Version,
-			InvokePath: env.InvokePath(4), // 跳过 4 层调用栈
-			InputData:  optionStr,
-			OutputData: resultData,
-			ExtInfo:    nil,
-		},
-	}})
+	}, group)
 }
 
 // experimentIDList of experimental group IDs, separated by ; sign
@@ -165,80 +250,144 @@ func experimentIDList(list *ExperimentList) string {
 // Specific implementation of experimental exposure reporting
 func exposureExperiments(ctx context.Context, projectID string, list *ExperimentList,
 	exposureType protoc_event_server.ExposureType) error {
+	if list == nil || len(list.Data) == 0 { // 没有数据
+		return nil
+	}
+	if list.isPropagated { // logged by the upstream, see DecodeBaggage
+		return nil
+	}
+	return exposureExperimentBatch(ctx, projectID, []*experimentExposure{{projectID: projectID, list: list,
+		et: exposureType}})
+}
+
+// exposureExperimentBatch Report the exposures of the lists of the projectID, the exposures of the same scene
+// are merged into one plugin call, see WithAdaptiveFlush
+func exposureExperimentBatch(ctx context.Context, projectID string, batch []*experimentExposure) (err error) {
 	// Whether to disable
-	if internal.C.IsDisableReport {
+	if internal.IsReportDisabled(projectID) {
 		return nil
 	}
-	if list == nil || len(list.Data) == 0 { // 没有数据
+	count := 0
+	for _, item := range batch {
+		count += len(item.list.Data)
+	}
+	if count == 0 { // 没有数据
 		return nil
 	}
+	ctx, span := startExposureSpan(ctx, "abc.exposureExperiments", projectID, count)
+	defer func() {
+		tracing.End(span, err)
+	}()
 	// Get local cache
 	application := cache.GetApplication(projectID)
 	if application == nil { // 理论上不为 nil
+		internal.ReportError(internal.ReasonConfigNotLoaded, projectID, errConfigNotLoaded(projectID))
 		return nil
 	}
 	experimentMetricsConfigList := application.TabConfig.ControlData.ExperimentMetricsConfig
 	defaultExperimentMetricsConfig := application.TabConfig.ControlData.DefaultExperimentMetricsConfig
 	if len(experimentMetricsConfigList) == 0 && defaultExperimentMetricsConfig == nil { // 没有监控上报配置
+		if internal.IsWarningHandled() {
+			internal.ReportWarning(internal.ReasonMetricsConfigMissing, projectID,
+				fmt.Errorf("%s exposure metrics config is missing", "experiment"))
+		}
 		return nil
 	}
 	ignoreReportGroupID := application.TabConfig.ControlData.IgnoreReportGroupId
 	// Get reported data
-	sceneDataList, defaultDataList := convertExperimentList(projectID, list, exposureType, ignoreReportGroupID)
+	sceneDataList, defaultDataList := convertExperimentBatch(projectID, batch, ignoreReportGroupID)
 	for sceneID, dataList := range sceneDataList {
 		metricsConfig, ok := experimentMetricsConfigList[sceneID]
 		if !ok || metricsConfig == nil {
 			defaultDataList.Exposures = append(defaultDataList.Exposures, dataList.Exposures...)
+			dataList.Exposures = nil // moved, released with the default group
 			continue
 		}
 		if !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+			releaseExposureGroup(dataList, "")
 			continue
 		}
-		err := metrics.LogExposure(ctx, &metrics.Metadata{
+		err := reportExposureGroup(ctx, projectID, &metrics.Metadata{
 			MetricsPluginName: metricsConfig.PluginName,
 			TableName:         metricsConfig.Metadata.Name,
 			TableID:           metricsConfig.Metadata.Id,
-			Token:             metricsConfig.Metadata.Token,
-			SamplingInterval:  metricsConfig.SamplingInterval,
+			Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+			SamplingInterval:  internal.ExposureSamplingInterval(projectID, metricsConfig.SamplingInterval),
 		}, dataList)
+		releaseExposureGroup(dataList, metricsConfig.PluginName)
 		if err != nil {
-			log.Errorf("sendData fail:%v", err)
+			log.LimitedError(ctx, "sendData", "send exposure fail", log.Any("projectID", projectID),
+				log.Any("plugin", metricsConfig.PluginName), log.Err(err))
 			return err
 		}
 	}
 	if defaultExperimentMetricsConfig == nil || !defaultExperimentMetricsConfig.IsEnable ||
 		defaultExperimentMetricsConfig.Metadata == nil {
+		releaseExposureGroup(defaultDataList, "")
 		return nil
 	}
-	return metrics.LogExposure(ctx, &metrics.Metadata{
+	defer releaseExposureGroup(defaultDataList, defaultExperimentMetricsConfig.PluginName)
+	return reportExposureGroup(ctx, projectID, &metrics.Metadata{
 		MetricsPluginName: defaultExperimentMetricsConfig.PluginName,
 		TableName:         defaultExperimentMetricsConfig.Metadata.Name,
 		TableID:           defaultExperimentMetricsConfig.Metadata.Id,
-		Token:             defaultExperimentMetricsConfig.Metadata.Token,
-		SamplingInterval:  defaultExperimentMetricsConfig.SamplingInterval,
+		Token:             internal.EventToken(projectID, defaultExperimentMetricsConfig.Metadata.Token),
+		SamplingInterval:  internal.ExposureSamplingInterval(projectID, defaultExperimentMetricsConfig.SamplingInterval),
 	}, defaultDataList)
 }
 
+// convertExperimentBatch Merge the data to be reported of the lists by scenario, see convertExperimentList
+func convertExperimentBatch(projectID string, batch []*experimentExposure,
+	ignoreReportGroupID map[int64]bool) (map[int64]*protoc_event_server.ExposureGroup,
+	*protoc_event_server.ExposureGroup) {
+	if len(batch) == 1 {
+		return convertExperimentList(projectID, batch[0].list, batch[0].et, ignoreReportGroupID)
+	}
+	var result = make(map[int64]*protoc_event_server.ExposureGroup)
+	var defaultDataList = &protoc_event_server.ExposureGroup{}
+	for _, item := range batch {
+		sceneDataList, itemDefaultDataList := convertExperimentList(projectID, item.list, item.et, ignoreReportGroupID)
+		for sceneID, dataList := range sceneDataList {
+			if result[sceneID] == nil {
+				result[sceneID] = dataList
+				continue
+			}
+			result[sceneID].Exposures = append(result[sceneID].Exposures, dataList.Exposures...)
+		}
+		defaultDataList.Exposures = append(defaultDataList.Exposures, itemDefaultDataList.Exposures...)
+	}
+	return result, defaultDataList
+}
+
 // exposureFeatureFlag TODO
 // Specific implementation of remote configuration exposure reporting
 func exposureFeatureFlag(ctx context.Context, projectID string, featureFlag *FeatureFlag,
-	exposureType protoc_event_server.ExposureType) error {
+	exposureType protoc_event_server.ExposureType) (err error) {
 	// Whether to disable
-	if internal.C.IsDisableReport {
+	if internal.IsReportDisabled(projectID) {
 		return nil
 	}
 	if featureFlag == nil || featureFlag.ConfigResult == nil { // 没有数据
 		return nil
 	}
+	ctx, span := startExposureSpan(ctx, "abc.exposureFeatureFlag", projectID, 1)
+	defer func() {
+		tracing.End(span, err)
+	}()
 	config := featureFlag.ConfigResult
 	// Get local cache
 	application := cache.GetApplication(projectID)
 	if application == nil { // 理论上不为 nil
+		internal.ReportError(internal.ReasonConfigNotLoaded, projectID, errConfigNotLoaded(projectID))
 		return nil
 	}
 	metricsConfigList := application.TabConfig.ControlData.FeatureFlagMetricsConfig
 	defaultMetricsConfig := application.TabConfig.ControlData.DefaultFeatureFlagMetricsConfig
 	if len(metricsConfigList) == 0 && defaultMetricsConfig == nil {
+		if internal.IsWarningHandled() {
+			internal.ReportWarning(internal.ReasonMetricsConfigMissing, projectID,
+				fmt.Errorf("%s exposure metrics config is missing", "feature flag"))
+		}
 		return nil
 	}
 	// Whether it has been reported through the specified scenario
@@ -252,15 +401,17 @@ func exposureFeatureFlag(ctx context.Context, projectID string, featureFlag *Fea
 		if !metricsConfig.IsEnable {
 			continue
 		}
-		err := metrics.SendData(ctx, &metrics.Metadata{
+		metadata := &metrics.Metadata{
 			MetricsPluginName: metricsConfig.PluginName,
 			TableName:         metricsConfig.Metadata.Name,
 			TableID:           metricsConfig.Metadata.Id,
-			Token:             metricsConfig.Metadata.Token,
-			SamplingInterval:  metricsConfig.SamplingInterval,
-		}, [][]string{data})
+			Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+			SamplingInterval:  internal.ExposureSamplingInterval(projectID, metricsConfig.SamplingInterval),
+		}
+		err := reportExposureData(ctx, projectID, metadata, remoteConfigRows(projectID, config, metadata, data))
 		if err != nil {
-			log.Errorf("sendData fail:%v", err)
+			log.LimitedError(ctx, "sendData", "send exposure fail", log.Any("projectID", projectID),
+				log.Any("plugin", metricsConfig.PluginName), log.Err(err))
 			return err
 		}
 		// If you have reported through specified scenarios, you will no longer need to use default metrics to report.
@@ -269,33 +420,43 @@ func exposureFeatureFlag(ctx context.Context, projectID string, featureFlag *Fea
 	if isSent || defaultMetricsConfig == nil || !defaultMetricsConfig.IsEnable || defaultMetricsConfig.Metadata == nil {
 		return nil
 	}
-	return metrics.SendData(ctx, &metrics.Metadata{
+	metadata := &metrics.Metadata{
 		MetricsPluginName: defaultMetricsConfig.PluginName,
 		TableName:         defaultMetricsConfig.Metadata.Name,
 		TableID:           defaultMetricsConfig.Metadata.Id,
-		Token:             defaultMetricsConfig.Metadata.Token,
-		SamplingInterval:  defaultMetricsConfig.SamplingInterval,
-	}, [][]string{data})
+		Token:             internal.EventToken(projectID, defaultMetricsConfig.Metadata.Token),
+		SamplingInterval:  internal.ExposureSamplingInterval(projectID, defaultMetricsConfig.SamplingInterval),
+	}
+	return reportExposureData(ctx, projectID, metadata, remoteConfigRows(projectID, config, metadata, data))
 }
 
 // exposureRemoteConfig 远程配置曝光上报具体实现
 func exposureRemoteConfig(ctx context.Context, projectID string, config *ConfigResult,
-	exposureType protoc_event_server.ExposureType) error {
+	exposureType protoc_event_server.ExposureType) (err error) {
 	// Whether to disable
-	if internal.C.IsDisableReport {
+	if internal.IsReportDisabled(projectID) {
 		return nil
 	}
 	if config == nil { // 没有数据
 		return nil
 	}
+	ctx, span := startExposureSpan(ctx, "abc.exposureRemoteConfig", projectID, 1)
+	defer func() {
+		tracing.End(span, err)
+	}()
 	// Get local cache
 	application := cache.GetApplication(projectID)
 	if application == nil { // 理论上不为 nil
+		internal.ReportError(internal.ReasonConfigNotLoaded, projectID, errConfigNotLoaded(projectID))
 		return nil
 	}
 	metricsConfigList := application.TabConfig.ControlData.RemoteConfigMetricsConfig
 	defaultMetricsConfig := application.TabConfig.ControlData.DefaultRemoteConfigMetricsConfig
 	if len(metricsConfigList) == 0 && defaultMetricsConfig == nil { // 没有监控上报配置
+		if internal.IsWarningHandled() {
+			internal.ReportWarning(internal.ReasonMetricsConfigMissing, projectID,
+				fmt.Errorf("%s exposure metrics config is missing", "remote config"))
+		}
 		return nil
 	}
 	// get reported data
@@ -309,15 +470,17 @@ func exposureRemoteConfig(ctx context.Context, projectID string, config *ConfigR
 		if !metricsConfig.IsEnable {
 			continue
 		}
-		err := metrics.SendData(ctx, &metrics.Metadata{
+		metadata := &metrics.Metadata{
 			MetricsPluginName: metricsConfig.PluginName,
 			TableName:         metricsConfig.Metadata.Name,
 			TableID:           metricsConfig.Metadata.Id,
-			SamplingInterval:  metricsConfig.SamplingInterval,
-			Token:             metricsConfig.Metadata.Token,
-		}, [][]string{data})
+			SamplingInterval:  internal.ExposureSamplingInterval(projectID, metricsConfig.SamplingInterval),
+			Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+		}
+		err := reportExposureData(ctx, projectID, metadata, remoteConfigRows(projectID, config, metadata, data))
 		if err != nil {
-			log.Errorf("sendData fail:%v", err)
+			log.LimitedError(ctx, "sendData", "send exposure fail", log.Any("projectID", projectID),
+				log.Any("plugin", metricsConfig.PluginName), log.Err(err))
 			return err
 		}
 		isSent = true
@@ -325,13 +488,118 @@ func exposureRemoteConfig(ctx context.Context, projectID string, config *ConfigR
 	if isSent || defaultMetricsConfig == nil || !defaultMetricsConfig.IsEnable || defaultMetricsConfig.Metadata == nil {
 		return nil
 	}
-	return metrics.SendData(ctx, &metrics.Metadata{
+	metadata := &metrics.Metadata{
 		MetricsPluginName: defaultMetricsConfig.PluginName,
 		TableName:         defaultMetricsConfig.Metadata.Name,
 		TableID:           defaultMetricsConfig.Metadata.Id,
-		Token:             defaultMetricsConfig.Metadata.Token,
-		SamplingInterval:  defaultMetricsConfig.SamplingInterval,
-	}, [][]string{data})
+		Token:             internal.EventToken(projectID, defaultMetricsConfig.Metadata.Token),
+		SamplingInterval:  internal.ExposureSamplingInterval(projectID, defaultMetricsConfig.SamplingInterval),
+	}
+	return reportExposureData(ctx, projectID, metadata, remoteConfigRows(projectID, config, metadata, data))
+}
+
+// reportExposureGroup Sample and report the exposure group, the exposures discarded by the sampling
+// or the failure are counted into the loss of the projectID. The failed exposures of an at-least-once projectID are
+// kept for the redelivery instead, see keepUndelivered
+func reportExposureGroup(ctx context.Context, projectID string, metadata *metrics.Metadata,
+	group *protoc_event_server.ExposureGroup) error {
+	if group == nil || len(group.Exposures) == 0 {
+		return nil
+	}
+	if !metrics.SamplingResult(metadata.SamplingInterval) {
+		internal.RecordLoss(projectID, internal.LossReasonSampling, len(group.Exposures))
+		return nil
+	}
+	if !internal.IsDeliveryGuaranteed(projectID) && !internal.ShedSampled() {
+		internal.RecordLoss(projectID, internal.LossReasonShed, len(group.Exposures))
+		return nil
+	}
+	if metadata.SampledInterval == 0 { // sampled already if handed off by the previous process
+		metadata.SampledInterval = metadata.SamplingInterval
+	}
+	metadata.SamplingInterval = 1 // sampled before, always report here, the plugins weight by SampledInterval
+	// handed off to the next process or kept for the redelivery, see HandoffState and keepUndelivered
+	if spool := spoolOf(ctx); spool != nil {
+		return spool.addGroup(projectID, metadata, group)
+	}
+	checkMetricsPlugin(projectID, metadata)
+	defer timeSend(ctx, time.Now())
+	err := deliver(ctx, projectID, func() error {
+		return metrics.LogExposure(ctx, metadata, group)
+	})
+	if err != nil {
+		internal.ReportError(internal.ReasonReportFailure, projectID, err)
+		if keepUndelivered(projectID, func(spool *exposureSpool) error {
+			return spool.addGroup(projectID, metadata, group)
+		}) {
+			return nil
+		}
+		internal.RecordLoss(projectID, internal.LossReasonFailure, len(group.Exposures))
+	}
+	return err
+}
+
+// reportExposureData Sample and report the exposure data, see reportExposureGroup
+func reportExposureData(ctx context.Context, projectID string, metadata *metrics.Metadata, data [][]string) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if !metrics.SamplingResult(metadata.SamplingInterval) {
+		internal.RecordLoss(projectID, internal.LossReasonSampling, len(data))
+		return nil
+	}
+	if !internal.IsDeliveryGuaranteed(projectID) && !internal.ShedSampled() {
+		internal.RecordLoss(projectID, internal.LossReasonShed, len(data))
+		return nil
+	}
+	if metadata.SampledInterval == 0 { // sampled already if handed off by the previous process
+		metadata.SampledInterval = metadata.SamplingInterval
+	}
+	metadata.SamplingInterval = 1 // sampled before, always report here, the plugins weight by SampledInterval
+	// handed off to the next process or kept for the redelivery, see HandoffState and keepUndelivered
+	if spool := spoolOf(ctx); spool != nil {
+		return spool.addRows(projectID, metadata, data)
+	}
+	checkMetricsPlugin(projectID, metadata)
+	defer timeSend(ctx, time.Now())
+	err := deliver(ctx, projectID, func() error {
+		return metrics.SendData(ctx, metadata, data)
+	})
+	if err != nil {
+		internal.ReportError(internal.ReasonReportFailure, projectID, err)
+		if keepUndelivered(projectID, func(spool *exposureSpool) error {
+			return spool.addRows(projectID, metadata, data)
+		}) {
+			return nil
+		}
+		internal.RecordLoss(projectID, internal.LossReasonFailure, len(data))
+	}
+	return err
+}
+
+// checkMetricsPlugin Warn if the metrics plugin named by the reporting config is not registered,
+// the plugin call is skipped silently in this case
+func checkMetricsPlugin(projectID string, metadata *metrics.Metadata) {
+	if !internal.IsWarningHandled() {
+		return
+	}
+	if _, ok := metrics.GetClient(metadata.MetricsPluginName); !ok {
+		internal.ReportWarning(internal.ReasonMetricsPluginNotFound, projectID,
+			fmt.Errorf("metrics plugin [%s] is not registered", metadata.MetricsPluginName))
+	}
+}
+
+// errConfigNotLoaded The error of the projectID whose local cache is not loaded
+func errConfigNotLoaded(projectID string) error {
+	return fmt.Errorf("%w, check whether it is passed in Init", internal.UnknownProjectError(projectID))
+}
+
+// startExposureSpan Create the span of exposure reporting, the attributes are only built if tracing is enabled
+func startExposureSpan(ctx context.Context, name string, projectID string, count int) (context.Context, trace.Span) {
+	if !tracing.Enabled() {
+		return tracing.Start(ctx, name)
+	}
+	return tracing.Start(ctx, name, tracing.KeyProjectID.String(projectID), tracing.KeyExposureCount.Int(count))
 }
 
 // convertExperimentList TODO
@@ -342,6 +610,14 @@ func convertExperimentList(projectID string, list *ExperimentList, exposureType
 	var result = make(map[int64]*protoc_event_server.ExposureGroup)
 	var defaultDataList = &protoc_event_server.ExposureGroup{}
 	uploadTime := time.Now().Unix()
+	// the exposures of a list share the extra data of the same user, it is only read by the plugins
+	extraData := extraDataFromUserCtx(list.userCtx)
+	if _, overridden := list.userCtx.expandedData[newIDKey]; len(list.userCtx.newUnitID) != 0 && !overridden {
+		extraData[newIDKey] = hashUnitID(projectID, list.userCtx.newUnitID)
+	}
+	extraData = mergeContextData(extraData, list.contextData)
+	extraData = internal.WithSystemMetadata(extraData)
+	application := cache.GetApplication(projectID)
 	for _, e := range list.Data {
 		// Filter experimental groups that are not reported
 		if flag, ok := ignoreReportGroupID[e.ID]; ok && flag { // Filter and ignore reported experimental group IDs
@@ -349,7 +625,7 @@ func convertExperimentList(projectID string, list *ExperimentList, exposureType
 		}
 		if len(e.sceneIDList) == 0 {
 			defaultDataList.Exposures = append(defaultDataList.Exposures, convertExperimentV2(projectID, e, list.userCtx,
-				exposureType, uploadTime))
+				exposureType, uploadTime, extraData, cache.GetExposureTemplate(application, e.ID)))
 			continue
 		}
 		for _, sceneID := range e.sceneIDList {
@@ -357,84 +633,99 @@ func convertExperimentList(projectID string, list *ExperimentList, exposureType
 				result[sceneID] = &protoc_event_server.ExposureGroup{}
 			}
 			result[sceneID].Exposures = append(result[sceneID].Exposures, convertExperimentV2(projectID, e, list.userCtx,
-				exposureType, uploadTime))
+				exposureType, uploadTime, extraData, cache.GetExposureTemplate(application, e.ID)))
 		}
 	}
 	return result, defaultDataList
 }
 
+// convertExperimentV2 Fill the fields of the user, the group fields are taken from the template pre-computed when
+// the configuration is loaded, template is nil for the groups not in the layers
 func convertExperimentV2(projectID string, experiment *Group, userCtx *userContext,
-	exposureType protoc_event_server.ExposureType, uploadTime int64) *protoc_event_server.Exposure {
-	return &protoc_event_server.Exposure{
-		UnitId:       userCtx.unitID,
-		GroupId:      experiment.ID,
-		ProjectId:    projectID,
-		Time:         uploadTime,
-		LayerKey:     experiment.LayerKey,
-		ExpKey:       experiment.ExperimentKey,
-		UnitType:     strconv.FormatInt(int64(experiment.UnitIDType), 10),
-		ClusterId:    userCtx.decisionID,
-		SdkType:      env.SDKType,
-		SdkVersion:   env.Version,
-		ExposureType: exposureType,
-		ExtraData:    extraDataFromUserCtx(userCtx),
+	exposureType protoc_event_server.ExposureType, uploadTime int64,
+	extraData map[string]string, template *cache.ExposureTemplate) *protoc_event_server.Exposure {
+	if template == nil || template.LayerKey != experiment.LayerKey {
+		template = &cache.ExposureTemplate{
+			GroupID:       experiment.ID,
+			LayerKey:      experiment.LayerKey,
+			ExperimentKey: experiment.ExperimentKey,
+			UnitType:      internal.UnitIDTypeString(int32(experiment.UnitIDType)),
+		}
 	}
+	exposure := newExposureMessage()
+	exposure.UnitId = hashUnitID(projectID, userCtx.unitID)
+	exposure.GroupId = template.GroupID
+	exposure.ProjectId = projectID
+	exposure.Time = uploadTime
+	exposure.LayerKey = template.LayerKey
+	exposure.ExpKey = template.ExperimentKey
+	exposure.UnitType = template.UnitType
+	exposure.ClusterId = hashUnitID(projectID, userCtx.decisionID)
+	exposure.SdkType = env.SDKType
+	exposure.SdkVersion = env.Version
+	exposure.ExposureType = exposureType
+	exposure.ExtraData = internal.ExtraDataWithEventID(extraData)
+	return exposure
 }
 
 func convertRemoteConfig(projectID string, config *ConfigResult,
 	exposureType protoc_event_server.ExposureType) []string {
 	return []string{
-		config.userCtx.unitID,                    // unitID
+		hashUnitID(projectID, config.userCtx.unitID), // unitID
 		projectID,                                // Business unique identifier
 		config.Key,                               // Configuration name
 		env.SDKVersion,                           // sdk version information
-		string(config.data),                      // configuration value
+		config.exposedValue(),                    // configuration value
 		time.Now().Format("2006-01-02 15:04:05"), // upload time
 		internal.C.EnvType,                       // environmental information
-		fmt.Sprintf("%v", config.unitIDType),     // unitID type
+		remoteConfigUnitIDType(config.unitIDType),           // unitID type
 		int64ListJoin(config.remoteConfig.SceneIdList, "#"), // Scene ID list
 		exposureType.String(),                               // Recording exposure mode: manual, automatic
-		marshalExpandedData(config.userCtx),                 // Expand information
+		marshalConfigExpandedData(projectID, config),        // Expand information
 	}
 }
 
-func marshalExpandedData(userCtx *userContext) string {
-	if len(userCtx.expandedData) == 0 && len(userCtx.newUnitID) == 0 {
-		return ""
-	}
-	var expandedData = make(map[string]string, len(userCtx.expandedData)+1)
-	if len(userCtx.newUnitID) != 0 {
-		expandedData[newIDKey] = userCtx.newUnitID
-	}
-	for key, value := range userCtx.expandedData {
-		expandedData[key] = value
-	}
-	var keys = make([]string, 0, len(expandedData))
-	for key := range expandedData {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-	sb := bytes.Buffer{}
-	for _, key := range keys {
-		if sb.Len() > 0 {
-			sb.WriteString(";")
-		}
-		sb.WriteString(key + "=" + expandedData[key])
+// marshalExpandedData The expanded data column of the remote config exposures, in the encoding of
+// WithExpandedDataEncoding
+func marshalExpandedData(projectID string, userCtx *userContext) string {
+	return encodeExpandedData(internal.EventExtInfo(expandedDataOf(projectID, userCtx)))
+}
+
+// expandedDataOf The expanded data of the remote config exposures without the event ID, may be nil
+func expandedDataOf(projectID string, userCtx *userContext) map[string]string {
+	data := extraDataFromUserCtx(userCtx)
+	if _, overridden := userCtx.expandedData[newIDKey]; len(userCtx.newUnitID) != 0 && !overridden {
+		data[newIDKey] = hashUnitID(projectID, userCtx.newUnitID)
 	}
-	return sb.String()
+	return data
+}
+
+// marshalConfigExpandedData The expanded data column of the remote config exposures of the config, see
+// marshalExpandedData
+func marshalConfigExpandedData(projectID string, config *ConfigResult) string {
+	return encodeExpandedData(internal.EventExtInfo(configExpandedData(projectID, config)))
+}
+
+// configExpandedData The expanded data of the remote config exposures of the config without the event ID, including
+// the values extracted from the ctx, may be nil
+func configExpandedData(projectID string, config *ConfigResult) map[string]string {
+	return mergeContextData(expandedDataOf(projectID, config.userCtx), config.contextData)
 }
 
 func extraDataFromUserCtx(userCtx *userContext) map[string]string {
-	if len(userCtx.expandedData) == 0 && len(userCtx.newUnitID) == 0 {
+	if len(userCtx.expandedData) == 0 && len(userCtx.newUnitID) == 0 && userCtx.extension.isEmpty() {
 		return nil
 	}
-	var extraData = make(map[string]string, len(userCtx.expandedData)+1)
+	var extraData = make(map[string]string, len(userCtx.expandedData)+5)
 	if len(userCtx.newUnitID) != 0 {
 		extraData[newIDKey] = userCtx.newUnitID
 	}
 	for key, value := range userCtx.expandedData {
 		extraData[key] = value
 	}
+	userCtx.extension.each(func(key string, value string) {
+		extraData[key] = value
+	})
 	return extraData
 }
 
@@ -464,15 +755,12 @@ func manualInitEvent(projectIDList []string, latency time.Duration, err error) {
 		if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
 			continue
 		}
-		interval := metricsConfig.SamplingInterval
-		if err != nil {
-			interval = metricsConfig.ErrSamplingInterval
-		}
+		interval := eventSamplingInterval(projectID, metricsConfig, err)
 		sendDataErr := metrics.LogMonitorEvent(context.Background(), &metrics.Metadata{
 			MetricsPluginName: metricsConfig.PluginName,
 			TableName:         metricsConfig.Metadata.Name,
 			TableID:           metricsConfig.Metadata.Id,
-			Token:             metricsConfig.Metadata.Token,
+			Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
 			SamplingInterval:  interval,
 		}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
 			{
@@ -488,11 +776,11 @@ func manualInitEvent(projectIDList []string, latency time.Duration, err error) {
 				InvokePath: env.InvokePath(4),
 				InputData:  "",
 				OutputData: "",
-				ExtInfo:    nil,
+				ExtInfo:    internal.EventExtInfo(nil),
 			},
 		}})
 		if sendDataErr != nil {
-			log.Errorf("sendData fail:%v", sendDataErr)
+			log.LimitedErrorf("sendEvent", "sendData fail:%v", sendDataErr)
 		}
 	}
 }
@@ -0,0 +1,101 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+)
+
+var (
+	flushControllerLock sync.Mutex
+	// flushController The control of the current global configuration, replaced when Init changes the options
+	flushController *internal.FlushController
+)
+
+// currentFlushController The control of the adaptive flush, nil if it is not enabled
+func currentFlushController() *internal.FlushController {
+	target, maxBatch := internal.C.AdaptiveFlushTarget, internal.C.AdaptiveFlushMaxBatch
+	if target <= 0 {
+		return nil
+	}
+	flushControllerLock.Lock()
+	defer flushControllerLock.Unlock()
+	if flushController == nil || !flushController.IsConfiguredWith(target, maxBatch) {
+		flushController = internal.NewFlushController(target, maxBatch, len(exposureShards))
+	}
+	return flushController
+}
+
+// flushExperimentExposures Flush the exposure together with the exposures pending in the shard, up to the batch
+// size of the adaptive flush, one flush per projectID in the order of the first exposure of each projectID. The
+// exposure is flushed alone if the adaptive flush is not enabled
+func flushExperimentExposures(shard *exposureShard, first *experimentExposure) {
+	controller := currentFlushController()
+	if controller == nil {
+		flushExperimentBatch(first.projectID, []*experimentExposure{first})
+		return
+	}
+	batches, projectIDList := drainExperimentExposures(shard, first, controller.BatchSize())
+	for _, projectID := range projectIDList {
+		flushAdaptive(controller, projectID, batches[projectID])
+	}
+}
+
+func flushAdaptive(controller *internal.FlushController, projectID string, batch []*experimentExposure) {
+	controller.Acquire()
+	start := time.Now()
+	defer func() { // released even if the plugin panics
+		controller.Release(time.Since(start))
+	}()
+	flushExperimentBatch(projectID, batch)
+}
+
+// drainExperimentExposures Take the pending exposures of the shard without waiting, grouped by projectID
+func drainExperimentExposures(shard *exposureShard, first *experimentExposure,
+	batchSize int) (map[string][]*experimentExposure, []string) {
+	batches := map[string][]*experimentExposure{first.projectID: {first}}
+	projectIDList := []string{first.projectID}
+	for n := 1; n < batchSize; n++ {
+		var eExposure *experimentExposure
+		select {
+		case eExposure = <-shard.experimentExposureChan:
+		default:
+			return batches, projectIDList
+		}
+		if eExposure == nil || eExposure.list == nil || len(eExposure.list.Data) == 0 {
+			continue
+		}
+		if _, ok := batches[eExposure.projectID]; !ok {
+			projectIDList = append(projectIDList, eExposure.projectID)
+		}
+		batches[eExposure.projectID] = append(batches[eExposure.projectID], eExposure)
+	}
+	return batches, projectIDList
+}
+
+// flushExperimentBatch Report the batch of the projectID, the failures are recorded and passed to OnError by
+// reportExposureGroup
+func flushExperimentBatch(projectID string, batch []*experimentExposure) {
+	_ = timedFlush(projectID, "exposureExperiments", func(ctx context.Context) error {
+		return exposureExperimentBatch(ctx, projectID, batch)
+	})
+}
+
+// flushStats The state of the adaptive flush, nil if it is not enabled
+func flushStats() *FlushStats {
+	controller := currentFlushController()
+	if controller == nil {
+		return nil
+	}
+	stats := &FlushStats{}
+	stats.BatchSize, stats.Concurrency, stats.P99 = controller.Stats()
+	return stats
+}
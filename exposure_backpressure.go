@@ -0,0 +1,24 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+// IsBackpressured Whether the exposure reporting of the projectID is saturated: a queue is above 90% of its capacity,
+// reaches the runtime queue size of the projectID, or is shrunk by the guardrail. Concurrent and safe, cheap enough
+// for every request
+func IsBackpressured(projectID string) bool {
+	pending, capacity := pendingExperimentExposures()
+	if isQueueSaturated(projectID, pending, capacity) {
+		return true
+	}
+	pending, capacity = pendingRemoteConfigExposures()
+	return isQueueSaturated(projectID, pending, capacity)
+}
+
+func isQueueSaturated(projectID string, pending int, capacity int) bool {
+	return float64(pending) >= backpressureWatermark*float64(capacity) || isQueueFull(projectID, pending) ||
+		isQueueShed(pending, capacity)
+}
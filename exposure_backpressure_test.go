@@ -0,0 +1,42 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackpressure(t *testing.T) {
+	defer internal.ResetProjectOptions()
+	defer internal.ResetLoss()
+	list := &ExperimentList{Data: map[string]*Group{"layer": {}}}
+	assert.False(t, IsBackpressured(projectID))
+	assert.Nil(t, UpdateOptions(projectID, WithQueueSize(1)))
+	shards := exposureShards
+	exposureShards = newExposureShards(1) // not consumed by the consumers of the other tests
+	defer func() {
+		exposureShards = shards
+	}()
+	exposureShards[0].experimentExposureChan <- &experimentExposure{projectID: projectID}
+	assert.True(t, IsBackpressured(projectID))
+	assert.False(t, isQueueSaturated(projectID, 0, 10))
+	assert.True(t, isQueueSaturated(projectID, 9, 10))
+
+	// not enabled, reported as usual
+	assert.Nil(t, LogExperimentsExposure(context.Background(), projectID, &ExperimentList{}))
+	assert.Nil(t, UpdateOptions(projectID, WithBackpressure(true)))
+	assert.Equal(t, ErrBackpressure, LogExperimentsExposure(context.Background(), projectID, list))
+	assert.Equal(t, ErrBackpressure, LogRemoteConfigExposure(context.Background(), projectID, &ConfigResult{}))
+	assert.True(t, errors.Is(asyncExposureExperiments(projectID, list, 0), ErrBackpressure))
+	assert.Nil(t, LogExperimentsExposure(context.Background(), "notSaturated", list))
+	// the kill switch takes precedence
+	assert.Nil(t, UpdateOptions(projectID, WithReportDisabled(true)))
+	assert.Equal(t, ErrReportDisabled, LogExperimentsExposure(context.Background(), projectID, list))
+}
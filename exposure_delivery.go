@@ -0,0 +1,138 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+const (
+	// deliveryAttempts The number of the plugin calls of a guaranteed delivery before giving up
+	deliveryAttempts = 3
+	// maxUndelivered The maximum number of the undelivered exposure batches kept for the redelivery, the batches
+	// beyond it are counted into the loss
+	maxUndelivered = 1 << 16
+)
+
+var (
+	// deliveryBackoff The wait before the first retry of a failed plugin call, doubled on each retry
+	deliveryBackoff = 100 * time.Millisecond
+	// deliveryEnqueueTimeout How long an at-least-once automatic exposure waits for the room of the full queue
+	deliveryEnqueueTimeout = time.Second
+	// redeliveryInterval The interval of the redelivery of the undelivered exposures
+	redeliveryInterval = 5 * time.Second
+	redeliveryOnce     sync.Once
+	// undelivered The exposures of the at-least-once projectIDs that failed all the attempts or found the queue
+	// still full, sampled already, redelivered in the background and handed off by HandoffState
+	undelivered = &exposureSpool{limit: maxUndelivered}
+)
+
+// keepUndelivered Keep the exposures into the undelivered spool through keep if the projectID is at-least-once,
+// false if they are not kept and must be counted into the loss
+func keepUndelivered(projectID string, keep func(spool *exposureSpool) error) bool {
+	if internal.Delivery(projectID) != internal.DeliveryAtLeastOnce {
+		return false
+	}
+	if err := keep(undelivered); err != nil {
+		log.LimitedError(context.Background(), "keepUndelivered", "keep undelivered exposure fail",
+			log.Any("projectID", projectID), log.Err(err))
+		return false
+	}
+	return true
+}
+
+// initRedelivery Start the redelivery of the undelivered exposures, only one is started across Init and Release
+func initRedelivery() {
+	redeliveryOnce.Do(func() {
+		internal.Go("redelivery", func(task *internal.Task) {
+			for {
+				task.Heartbeat()
+				time.Sleep(redeliveryInterval)
+				redeliver(context.Background(), task)
+			}
+		})
+	})
+}
+
+// redeliver Report the undelivered exposures again, the ones failing again are kept for the next round
+func redeliver(ctx context.Context, task *internal.Task) {
+	for _, exposure := range undelivered.take() {
+		task.Heartbeat()
+		if err := replayExposure(ctx, exposure); err != nil {
+			log.LimitedError(ctx, "redeliver", "redeliver exposure fail", log.Any("projectID", exposure.ProjectID),
+				log.Err(err))
+		}
+	}
+}
+
+// resetRedelivery Called by Release, the undelivered exposures not handed off by HandoffState are discarded
+func resetRedelivery() {
+	undelivered.take()
+}
+
+// deliver Call send, the failures are retried with the exponential backoff if the delivery of the projectID is
+// guaranteed, see WithDeliveryGuarantee. The retries stop once ctx is done
+func deliver(ctx context.Context, projectID string, send func() error) error {
+	err := send()
+	if err == nil || !internal.IsDeliveryGuaranteed(projectID) {
+		return err
+	}
+	backoff := deliveryBackoff
+	for attempt := 1; attempt < deliveryAttempts; attempt++ {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if err = send(); err == nil {
+			return nil
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// enqueueExperimentExposure Wait up to deliveryEnqueueTimeout for the room of the queue, false if still full
+func enqueueExperimentExposure(queue chan *experimentExposure, item *experimentExposure) bool {
+	select {
+	case queue <- item:
+		return true
+	default:
+	}
+	timer := time.NewTimer(deliveryEnqueueTimeout)
+	defer timer.Stop()
+	select {
+	case queue <- item:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// enqueueRemoteConfigExposure Wait up to deliveryEnqueueTimeout for the room of the queue, false if still full
+func enqueueRemoteConfigExposure(queue chan *remoteConfigExposure, item *remoteConfigExposure) bool {
+	select {
+	case queue <- item:
+		return true
+	default:
+	}
+	timer := time.NewTimer(deliveryEnqueueTimeout)
+	defer timer.Stop()
+	select {
+	case queue <- item:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,132 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliver(t *testing.T) {
+	defer internal.ResetProjectOptions()
+	backoff := deliveryBackoff
+	deliveryBackoff = time.Millisecond
+	defer func() {
+		deliveryBackoff = backoff
+	}()
+	calls := 0
+	failTwice := func() error {
+		calls++
+		if calls <= 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}
+	assert.NotNil(t, deliver(context.Background(), projectID, failTwice)) // best effort, not retried
+	assert.Equal(t, 1, calls)
+
+	assert.Nil(t, UpdateOptions(projectID, WithDeliveryGuarantee(DeliveryAtLeastOnce)))
+	calls = 0
+	assert.Nil(t, deliver(context.Background(), projectID, failTwice))
+	assert.Equal(t, 3, calls)
+	calls = -10
+	assert.NotNil(t, deliver(context.Background(), projectID, failTwice))
+	assert.Equal(t, -10+deliveryAttempts, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	assert.NotNil(t, deliver(ctx, projectID, failTwice))
+	assert.Equal(t, 1, calls)
+
+	assert.NotNil(t, UpdateOptions(projectID, WithDeliveryGuarantee("exactly_once")))
+	assert.Equal(t, DeliveryAtLeastOnce, internal.Delivery(projectID))
+	assert.Nil(t, UpdateOptions(projectID, WithDeliveryGuarantee("")))
+	assert.Equal(t, DeliveryBestEffort, internal.Delivery(projectID))
+}
+
+func TestEnqueueExposure(t *testing.T) {
+	timeout := deliveryEnqueueTimeout
+	deliveryEnqueueTimeout = 10 * time.Millisecond
+	defer func() {
+		deliveryEnqueueTimeout = timeout
+	}()
+	queue := make(chan *experimentExposure, 1)
+	assert.True(t, enqueueExperimentExposure(queue, &experimentExposure{}))
+	assert.False(t, enqueueExperimentExposure(queue, &experimentExposure{})) // timed out
+	go func() {
+		time.Sleep(time.Millisecond)
+		<-queue
+	}()
+	assert.True(t, enqueueExperimentExposure(queue, &experimentExposure{})) // waited for the room
+
+	configQueue := make(chan *remoteConfigExposure)
+	assert.False(t, enqueueRemoteConfigExposure(configQueue, &remoteConfigExposure{}))
+}
+
+func TestRedelivery(t *testing.T) {
+	defer Release()
+	backoff, timeout := deliveryBackoff, deliveryEnqueueTimeout
+	deliveryBackoff, deliveryEnqueueTimeout = time.Millisecond, time.Millisecond
+	defer func() {
+		deliveryBackoff, deliveryEnqueueTimeout = backoff, timeout
+	}()
+	failing := &failLogMetricsClient{recordMetricsClient{Client: testdata.EmptyMetricsClient}}
+	metrics.RegisterClient(failing)
+	recorder := &recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true}
+	defer metrics.RegisterClient(recorder)
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	internal.ResetLoss()
+	metadata := &metrics.Metadata{MetricsPluginName: failing.Name(), SamplingInterval: 1}
+	group := func(unitID string) *protoc_event_server.ExposureGroup {
+		return &protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{{UnitId: unitID}}}
+	}
+	// best effort, lost
+	assert.NotNil(t, reportExposureGroup(context.Background(), projectID, metadata, group("1")))
+	assert.Equal(t, 0, undelivered.size())
+	assert.Equal(t, map[LossReason]uint64{LossReasonFailure: 1}, GetLossCounts(projectID))
+
+	// at least once, kept until the plugin recovers
+	assert.Nil(t, UpdateOptions(projectID, WithDeliveryGuarantee(DeliveryAtLeastOnce)))
+	metadata = &metrics.Metadata{MetricsPluginName: failing.Name(), SamplingInterval: 1}
+	assert.Nil(t, reportExposureGroup(context.Background(), projectID, metadata, group("2")))
+	assert.Equal(t, 1, GetExposureStats().Undelivered)
+	redeliver(context.Background(), nil)
+	assert.Equal(t, 1, undelivered.size()) // failed again
+	metrics.RegisterClient(recorder)
+	redeliver(context.Background(), nil)
+	assert.Equal(t, 0, undelivered.size())
+	recorder.lock.Lock()
+	assert.Equal(t, 1, len(recorder.exposures))
+	assert.Equal(t, "2", recorder.exposures[0].UnitId)
+	recorder.lock.Unlock()
+	assert.Equal(t, map[LossReason]uint64{LossReasonFailure: 1}, GetLossCounts(projectID))
+
+	// the queue stays full
+	shards := exposureShards
+	defer func() {
+		exposureShards = shards
+	}()
+	exposureShards = newExposureShards(1) // not drained by the consumers
+	for len(exposureShards[0].experimentExposureChan) < cap(exposureShards[0].experimentExposureChan) {
+		exposureShards[0].experimentExposureChan <- &experimentExposure{}
+	}
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.Nil(t, asyncExposureExperiments(projectID, list, automaticExposure))
+	assert.Equal(t, 1, undelivered.size())
+	assert.NotEmpty(t, spoolPendingExposures(context.Background())) // handed off with the pending exposures
+	assert.Equal(t, 0, undelivered.size())
+}
@@ -0,0 +1,80 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+const (
+	// failoverEventName Event name of the endpoint failover report
+	failoverEventName = "failover"
+	// failoverChanSize The capacity of the failover queue, the failovers are discarded when it is full
+	failoverChanSize = 1 << 6
+)
+
+var failoverChan = make(chan *FailoverEvent, failoverChanSize)
+
+// asyncFailover Push the failover to the reporting queue, discarded if the queue is full
+func asyncFailover(event *FailoverEvent) {
+	select {
+	case failoverChan <- event:
+	default:
+	}
+}
+
+// reportFailover Report the failover as a monitor event of each projectID, the failover is not specific to any
+func reportFailover(ctx context.Context, event *FailoverEvent) {
+	for _, projectID := range internal.C.ProjectIDList {
+		application := cache.GetApplication(projectID)
+		if application == nil {
+			continue
+		}
+		metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+		if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+			continue
+		}
+		extInfo := map[string]string{
+			"group":  event.Group,
+			"from":   event.From,
+			"to":     event.To,
+			"reason": event.Reason,
+		}
+		err := metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+			MetricsPluginName: metricsConfig.PluginName,
+			TableName:         metricsConfig.Metadata.Name,
+			TableID:           metricsConfig.Metadata.Id,
+			Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+			SamplingInterval:  1, // rare, never sampled
+		}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+			{
+				Time:       time.Now().Unix(),
+				Ip:         env.LocalIP(),
+				ProjectId:  projectID,
+				EventName:  failoverEventName,
+				StatusCode: env.EventStatus(event.Err),
+				Message:    env.ErrMsg(event.Err),
+				SdkType:    env.SDKType,
+				SdkVersion: env.Version,
+				InputData:  event.From,
+				OutputData: event.To,
+				ExtInfo:    internal.EventExtInfo(extInfo),
+			},
+		}})
+		if err != nil {
+			log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+		}
+	}
+}
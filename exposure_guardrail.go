@@ -0,0 +1,87 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+const (
+	// guardrailInterval The interval of the guardrail rounds, the shed level moves by one at most per round
+	guardrailInterval = time.Second
+	// heapObjectsMetric The runtime metric of the heap bytes, read without stopping the world
+	heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+)
+
+var (
+	guardrailOnce sync.Once
+	// lastGuardrailUsage internal.GuardrailUsage measured by the last round
+	lastGuardrailUsage atomic.Value
+)
+
+// initGuardrail Start the guardrail, only one guardrail is started across Init and Release,
+// which reads the budgets of the current global configuration every round
+func initGuardrail() {
+	guardrailOnce.Do(func() {
+		internal.Go("guardrail", func(task *internal.Task) {
+			last := time.Now()
+			for {
+				task.Heartbeat()
+				time.Sleep(guardrailInterval)
+				now := time.Now()
+				busy := internal.TakeBusy()
+				elapsed := now.Sub(last)
+				last = now
+				if !internal.IsGuardrailEnabled() {
+					internal.ResetGuardrail()
+					continue
+				}
+				usage := internal.GuardrailUsage{CPU: float64(busy) / float64(elapsed), HeapBytes: heapBytes()}
+				lastGuardrailUsage.Store(usage)
+				before := internal.ShedLevel()
+				after := internal.UpdateShedLevel(usage)
+				if after != before {
+					log.Warnf("guardrail shed level %d -> %d, cpu=%.3f, heapBytes=%d", before, after,
+						usage.CPU, usage.HeapBytes)
+				}
+			}
+		})
+	})
+}
+
+func heapBytes() uint64 {
+	samples := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return samples[0].Value.Uint64()
+}
+
+// guardrailStats The state of the guardrail, nil if it is not enabled
+func guardrailStats() *GuardrailStats {
+	if !internal.IsGuardrailEnabled() {
+		return nil
+	}
+	stats := &GuardrailStats{ShedLevel: internal.ShedLevel()}
+	if usage, ok := lastGuardrailUsage.Load().(internal.GuardrailUsage); ok {
+		stats.CPU, stats.HeapBytes = usage.CPU, usage.HeapBytes
+	}
+	return stats
+}
+
+// isQueueShed Whether the pending items exceed the capacity of the queue shrunk by the guardrail
+func isQueueShed(pending int, capacity int) bool {
+	limit := internal.ShedCapacity(capacity)
+	return limit < capacity && pending >= limit
+}
@@ -0,0 +1,162 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// spooledExposure The exposures of a logging call taken from the queues by HandoffState, sampled already and ready to
+// be sent to the metrics plugin of Metadata
+type spooledExposure struct {
+	ProjectID string            `json:"projectId"`
+	Metadata  *metrics.Metadata `json:"metadata"`
+	// The ExposureGroup in the wire format, empty for the rows of the remote config exposures
+	Exposures []byte     `json:"exposures,omitempty"`
+	Rows      [][]string `json:"rows,omitempty"`
+}
+
+// spoolKey The context key of the exposureSpool
+type spoolKey struct{}
+
+// exposureSpool Collect the exposures reported under its context instead of sending them to the metrics plugins,
+// see spoolPendingExposures
+type exposureSpool struct {
+	lock      sync.Mutex
+	exposures []*spooledExposure
+	// The maximum number of the exposures kept, 0 is unlimited
+	limit int
+}
+
+// errSpoolFull The spool has reached its limit, the exposure is not kept
+var errSpoolFull = errors.New("exposure spool is full")
+
+// spoolOf The spool of the ctx, nil if the exposures are sent as usual
+func spoolOf(ctx context.Context) *exposureSpool {
+	spool, _ := ctx.Value(spoolKey{}).(*exposureSpool)
+	return spool
+}
+
+// withSpool The context whose exposures are collected into the spool instead of being sent
+func withSpool(spool *exposureSpool) context.Context {
+	return context.WithValue(context.Background(), spoolKey{}, spool)
+}
+
+func (s *exposureSpool) addGroup(projectID string, metadata *metrics.Metadata,
+	group *protoc_event_server.ExposureGroup) error {
+	data, err := proto.Marshal(group)
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	return s.add(&spooledExposure{ProjectID: projectID, Metadata: metadata, Exposures: data})
+}
+
+func (s *exposureSpool) addRows(projectID string, metadata *metrics.Metadata, rows [][]string) error {
+	return s.add(&spooledExposure{ProjectID: projectID, Metadata: metadata, Rows: rows})
+}
+
+func (s *exposureSpool) add(exposure *spooledExposure) error {
+	metadata := *exposure.Metadata // owned by the caller
+	exposure.Metadata = &metadata
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.limit > 0 && len(s.exposures) >= s.limit {
+		return errSpoolFull
+	}
+	s.exposures = append(s.exposures, exposure)
+	return nil
+}
+
+// take Take all the exposures kept
+func (s *exposureSpool) take() []*spooledExposure {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	exposures := s.exposures
+	s.exposures = nil
+	return exposures
+}
+
+// size The number of the exposures kept
+func (s *exposureSpool) size() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.exposures)
+}
+
+// spoolPendingExposures Take the exposures pending in the queues of all shards through the reporting pipeline into
+// the spool, the exposures are converted and sampled as usual but not sent. The undelivered exposures waiting for the
+// redelivery are taken as well
+func spoolPendingExposures(ctx context.Context) []*spooledExposure {
+	spool := &exposureSpool{}
+	ctx = context.WithValue(ctx, spoolKey{}, spool)
+	for _, shard := range exposureShards {
+		for drained := false; !drained; {
+			select {
+			case eExposure := <-shard.experimentExposureChan:
+				if eExposure == nil || eExposure.list == nil || len(eExposure.list.Data) == 0 {
+					continue
+				}
+				err := exposureExperimentBatch(ctx, eExposure.projectID, []*experimentExposure{eExposure})
+				if err != nil {
+					log.LimitedErrorf("spoolExposure", "[projectID=%v]spool exposure fail:%v", eExposure.projectID, err)
+				}
+			case cExposure := <-shard.remoteConfigExposureChan:
+				if cExposure == nil || cExposure.configResult == nil {
+					continue
+				}
+				err := exposureRemoteConfig(ctx, cExposure.projectID, cExposure.configResult, cExposure.et)
+				if err != nil {
+					log.LimitedErrorf("spoolExposure", "[projectID=%v]spool exposure fail:%v", cExposure.projectID, err)
+				}
+			default:
+				drained = true
+			}
+		}
+	}
+	return append(spool.take(), undelivered.take()...)
+}
+
+// replaySpool Report the exposures handed off by the previous process in the background, see WithStateHandoff
+func replaySpool(spool []*spooledExposure) {
+	if len(spool) == 0 {
+		return
+	}
+	internal.Go("handoffReplay", func(task *internal.Task) {
+		for _, exposure := range spool {
+			task.Heartbeat()
+			if err := replayExposure(context.Background(), exposure); err != nil {
+				log.LimitedErrorf("handoffReplay", "[projectID=%v]report handed off exposure fail:%v",
+					exposure.ProjectID, err)
+			}
+		}
+	})
+}
+
+func replayExposure(ctx context.Context, exposure *spooledExposure) error {
+	if exposure.Metadata == nil {
+		return errors.Errorf("metadata is required")
+	}
+	if internal.IsReportDisabled(exposure.ProjectID) {
+		return nil
+	}
+	if len(exposure.Exposures) == 0 {
+		return reportExposureData(ctx, exposure.ProjectID, exposure.Metadata, exposure.Rows)
+	}
+	group := &protoc_event_server.ExposureGroup{}
+	if err := proto.Unmarshal(exposure.Exposures, group); err != nil {
+		return errors.Wrap(err, "unmarshal")
+	}
+	return reportExposureGroup(ctx, exposure.ProjectID, exposure.Metadata, group)
+}
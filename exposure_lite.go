@@ -0,0 +1,168 @@
+//go:build abc_lite || abc_wasm
+// +build abc_lite abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval,
+// user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+// The lite build mode, enabled by the build tag abc_lite, compiles out the exposure and monitor event
+// reporting pipeline, the metrics plugins and the OpenTelemetry tracing, for edge binaries where exposures are handled
+// elsewhere, so that plugin/metrics, protoc_event_server and OpenTelemetry are not linked. The exposure APIs keep the
+// same signatures, do nothing and return nil. WithTracerProvider and the baggage APIs are not available.
+//
+//	go build -tags abc_lite
+//
+// The wasm mode, enabled by the build tag abc_wasm, implies the lite build mode, and also rejects the background tasks
+// and resolves no network interfaces by default, see WithScheduler and env.RegisterIPResolver.
+//
+//	tinygo build -target wasi -tags abc_wasm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// metricsPlugin The metrics plugins accepted by WithRegisterMetricsPlugin in the lite build mode, implemented by
+// metrics.Client, so that the callers build in both modes
+type metricsPlugin interface {
+	Name() string
+}
+
+// WithRegisterMetricsPlugin Compiled out in the lite build mode, the plugin is ignored
+func WithRegisterMetricsPlugin(client metricsPlugin, initConfig *protoccacheserver.MetricsInitConfig) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if client == nil {
+			return fmt.Errorf("client should not be nil")
+		}
+		return nil
+	}
+}
+
+// LogExperimentsExposure Compiled out in the lite build mode, do nothing
+func LogExperimentsExposure(ctx context.Context, projectID string, list *ExperimentList) error {
+	return nil
+}
+
+// LogExperimentExposure Compiled out in the lite build mode, do nothing
+func LogExperimentExposure(ctx context.Context, projectID string, result *ExperimentResult) error {
+	return nil
+}
+
+// LogRolloutExposure Compiled out in the lite build mode, do nothing
+func LogRolloutExposure(ctx context.Context, projectID string, key string, unitID string, inRollout bool) error {
+	return nil
+}
+
+// LogFeatureFlagExposure Compiled out in the lite build mode, do nothing
+func LogFeatureFlagExposure(ctx context.Context, projectID string, featureFlag *FeatureFlag) error {
+	return nil
+}
+
+// LogRemoteConfigExposure Compiled out in the lite build mode, do nothing
+func LogRemoteConfigExposure(ctx context.Context, projectID string, config *ConfigResult) error {
+	return nil
+}
+
+// IsBackpressured Compiled out in the lite build mode, never saturated
+func IsBackpressured(projectID string) bool {
+	return false
+}
+
+// automaticExposure The exposure type of the automatic exposures, unused in the lite build mode
+const automaticExposure int32 = 0
+
+func initCustomMetricsPlugin(ctx context.Context, config *internal.GlobalConfig) error {
+	return nil
+}
+
+func initMetricsPlugin(ctx context.Context, config *internal.GlobalConfig) error {
+	return nil
+}
+
+func manualInitEvent(projectIDList []string, latency time.Duration, err error) {}
+
+func initExposureConsumer() {}
+
+// spooledExposure The exposures handed off by the previous process, ignored in the lite build mode
+type spooledExposure struct{}
+
+func spoolPendingExposures(ctx context.Context) []*spooledExposure {
+	return nil
+}
+
+func replaySpool(spool []*spooledExposure) {}
+
+func asyncExposureExperiments(projectID string, list *ExperimentList,
+	exposureType int32) error {
+	return nil
+}
+
+func asyncExposureExperimentEvent(projectID string, list *ExperimentList,
+	latency time.Duration, options *experiment.Options, err error) error {
+	return nil
+}
+
+func asyncExposureRemoteConfig(projectID string, configResult *ConfigResult,
+	exposureType int32) error {
+	return nil
+}
+
+func asyncExposureRemoteConfigEvent(projectID string, configResult *ConfigResult,
+	latency time.Duration, options *experiment.Options, err error) error {
+	return nil
+}
+
+func validateExposure(ctx context.Context, report *ProjectSetupReport, list *ExperimentList,
+	sandboxTable *exposureTable) bool {
+	report.Steps = append(report.Steps, &SetupStep{Name: SetupStepExposure, Passed: true, Skipped: true,
+		Message: "reporting is compiled out in the lite build mode"})
+	return true
+}
+
+func verifyExposure(ctx context.Context, report *ProjectSetupReport) bool {
+	report.Steps = append(report.Steps, &SetupStep{Name: SetupStepVerify, Passed: true, Skipped: true,
+		Message: "reporting is compiled out in the lite build mode"})
+	return true
+}
+
+// GetExposureStats Compiled out in the lite build mode, return empty statistics
+func GetExposureStats() *ExposureStats {
+	return &ExposureStats{}
+}
+
+func initLossReporter() {}
+
+func initRedelivery() {}
+
+func resetRedelivery() {}
+
+func initRevisionReporter() {}
+
+func initGuardrail() {}
+
+func initAssignmentExporter() {}
+
+func exportAssignments(projectID string, list *ExperimentList) {}
+
+func asyncSlowOp(op *slowOp) {}
+
+func asyncFailover(event *FailoverEvent) {}
+
+func initSRMDetector() {}
+
+func recordSRM(projectID string, list *ExperimentList) {}
+
+func resetSRM() {}
+
+func initUsageReporter() {}
+
+func initUnusedVariantReporter() {}
+
+func asyncEvaluationPanic(p *evaluationPanic) {}
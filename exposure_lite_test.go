@@ -0,0 +1,55 @@
+//go:build abc_lite || abc_wasm
+// +build abc_lite abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/tracing"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteExposure(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithRegisterMetricsPlugin(testdata.EmptyMetricsClient, nil))
+	assert.Nil(t, err)
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, list.Data)
+	assert.Nil(t, LogExperimentsExposure(context.Background(), projectID, list))
+	config, err := NewUserContext("12345").GetRemoteConfig(context.Background(), projectID, "remoteConfig1")
+	assert.Nil(t, err)
+	assert.Nil(t, LogRemoteConfigExposure(context.Background(), projectID, config))
+	assert.Equal(t, &ExposureStats{}, GetExposureStats())
+	assert.False(t, IsBackpressured(projectID))
+	assert.False(t, tracing.Enabled())
+}
+
+func TestLiteSelfTest(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	report, err := SelfTest(context.Background(), projectID, WithValidateUnitID("12345"))
+	assert.Nil(t, err)
+	assert.True(t, report.Passed)
+	var skipped []string
+	for _, step := range report.Projects[projectID].Steps {
+		assert.True(t, step.Passed, step.Message)
+		if step.Skipped {
+			skipped = append(skipped, step.Name)
+		}
+	}
+	assert.Equal(t, []string{SetupStepExposure, SetupStepVerify}, skipped)
+}
+
+func TestLiteWithRegisterMetricsPlugin(t *testing.T) {
+	defer Release()
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithRegisterMetricsPlugin(nil, nil)))
+}
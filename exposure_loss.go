@@ -0,0 +1,120 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+const (
+	// defaultLossReportInterval The default interval of the exposure loss report event
+	defaultLossReportInterval = time.Minute
+	// lossEventName Event name of the exposure loss report
+	lossEventName = "loss"
+)
+
+var (
+	lossReporterOnce sync.Once
+	// lastLossCounts The cumulative loss counts already reported, key is projectID, only used by the reporter
+	lastLossCounts = make(map[string]map[internal.LossReason]uint64)
+)
+
+// initLossReporter Start the loss reporter, only one reporter is started across Init and Release,
+// which reads the projectIDs and the interval of the current global configuration every round
+func initLossReporter() {
+	lossReporterOnce.Do(func() {
+		internal.Go("lossReporter", func(task *internal.Task) {
+			for {
+				task.Heartbeat()
+				time.Sleep(lossReportInterval())
+				if internal.C.LossReportInterval < 0 {
+					continue
+				}
+				for _, projectID := range internal.C.ProjectIDList {
+					reportLoss(context.Background(), projectID)
+				}
+			}
+		})
+	})
+}
+
+func lossReportInterval() time.Duration {
+	if internal.C.LossReportInterval > 0 {
+		return internal.C.LossReportInterval
+	}
+	return defaultLossReportInterval
+}
+
+// lossIncrement The loss counts since the last report, the counts are cleared by Release and restart from zero
+func lossIncrement(projectID string) map[internal.LossReason]uint64 {
+	counts := internal.LossCounts(projectID)
+	last := lastLossCounts[projectID]
+	increment := make(map[internal.LossReason]uint64, len(counts))
+	for reason, count := range counts {
+		if count > last[reason] {
+			increment[reason] = count - last[reason]
+		} else if count < last[reason] {
+			increment[reason] = count
+		}
+	}
+	lastLossCounts[projectID] = counts
+	return increment
+}
+
+// reportLoss Report the loss increment of the projectID as a monitor event, nothing is reported without loss
+func reportLoss(ctx context.Context, projectID string) {
+	increment := lossIncrement(projectID)
+	if len(increment) == 0 {
+		return
+	}
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return
+	}
+	extInfo := make(map[string]string, len(increment))
+	for reason, count := range increment {
+		extInfo[string(reason)] = strconv.FormatUint(count, 10)
+	}
+	err := metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  1, // aggregated, never sampled
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         env.LocalIP(),
+			ProjectId:  projectID,
+			EventName:  lossEventName,
+			StatusCode: env.EventStatus(nil),
+			Message:    "exposure loss report",
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InputData:  lossReportInterval().String(),
+			OutputData: env.JSONString(extInfo),
+			ExtInfo:    internal.EventExtInfo(extInfo),
+		},
+	}})
+	if err != nil {
+		log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportExposureLoss(t *testing.T) {
+	internal.ResetLoss()
+	defer internal.ResetLoss()
+	group := &protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{{}, {}}}
+	// interval 0 means never report
+	err := reportExposureGroup(context.Background(), projectID, &metrics.Metadata{SamplingInterval: 0}, group)
+	assert.Nil(t, err)
+	err = reportExposureData(context.Background(), projectID, &metrics.Metadata{SamplingInterval: 0},
+		[][]string{{"a"}})
+	assert.Nil(t, err)
+	// the plugin is not registered, report nothing and no failure
+	err = reportExposureGroup(context.Background(), projectID, &metrics.Metadata{SamplingInterval: 1}, group)
+	assert.Nil(t, err)
+	assert.NotNil(t, experimentExposureQueueFull(projectID, &ExperimentList{Data: map[string]*Group{"layer": {}}}))
+	assert.Equal(t, map[LossReason]uint64{LossReasonSampling: 3, LossReasonQueueFull: 1}, GetLossCounts(projectID))
+}
+
+func TestLossIncrement(t *testing.T) {
+	internal.ResetLoss()
+	defer internal.ResetLoss()
+	delete(lastLossCounts, projectID)
+	assert.Equal(t, map[LossReason]uint64{}, lossIncrement(projectID))
+	internal.RecordLoss(projectID, LossReasonFailure, 3)
+	assert.Equal(t, map[LossReason]uint64{LossReasonFailure: 3}, lossIncrement(projectID))
+	assert.Equal(t, map[LossReason]uint64{}, lossIncrement(projectID))
+	internal.RecordLoss(projectID, LossReasonFailure, 2)
+	assert.Equal(t, map[LossReason]uint64{LossReasonFailure: 2}, lossIncrement(projectID))
+	internal.ResetLoss() // released and restarted from zero
+	internal.RecordLoss(projectID, LossReasonFailure, 1)
+	assert.Equal(t, map[LossReason]uint64{LossReasonFailure: 1}, lossIncrement(projectID))
+}
@@ -0,0 +1,80 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+// The exposure and monitor event messages are taken from the pools and put back after the logging call returns,
+// only when the plugin declares that it does not retain them, see metrics.IsMessageReusable.
+// The messages of the other plugins are left to the GC as before
+var (
+	exposureMessagePool = sync.Pool{New: func() interface{} {
+		return &protoc_event_server.Exposure{}
+	}}
+	monitorEventMessagePool = sync.Pool{New: func() interface{} {
+		return &protoc_event_server.MonitorEvent{}
+	}}
+)
+
+// newExposureMessage Get an empty exposure message
+func newExposureMessage() *protoc_event_server.Exposure {
+	if internal.C.IsDisableMessagePool {
+		return &protoc_event_server.Exposure{}
+	}
+	return exposureMessagePool.Get().(*protoc_event_server.Exposure)
+}
+
+// newMonitorEventMessage Get an empty monitor event message
+func newMonitorEventMessage() *protoc_event_server.MonitorEvent {
+	if internal.C.IsDisableMessagePool {
+		return &protoc_event_server.MonitorEvent{}
+	}
+	return monitorEventMessagePool.Get().(*protoc_event_server.MonitorEvent)
+}
+
+// isMessageReusable Whether the messages logged to the plugin can be put back, pluginName is empty for the messages
+// that are not logged, such as the exposures of the disabled metrics config
+func isMessageReusable(pluginName string) bool {
+	if internal.C.IsDisableMessagePool {
+		return false
+	}
+	return len(pluginName) == 0 || metrics.IsMessageReusable(pluginName)
+}
+
+// releaseExposureGroup Put back the exposures of the group after the logging call returns,
+// the group must not be used afterwards
+func releaseExposureGroup(group *protoc_event_server.ExposureGroup, pluginName string) {
+	if group == nil || !isMessageReusable(pluginName) {
+		return
+	}
+	for i, exposure := range group.Exposures {
+		exposure.Reset() // drop the references to the shared extra data and the strings
+		exposureMessagePool.Put(exposure)
+		group.Exposures[i] = nil
+	}
+	group.Exposures = nil
+}
+
+// releaseMonitorEventGroup Put back the events of the group after the logging call returns,
+// the group must not be used afterwards
+func releaseMonitorEventGroup(group *protoc_event_server.MonitorEventGroup, pluginName string) {
+	if group == nil || !isMessageReusable(pluginName) {
+		return
+	}
+	for i, event := range group.Events {
+		event.Reset()
+		monitorEventMessagePool.Put(event)
+		group.Events[i] = nil
+	}
+	group.Events = nil
+}
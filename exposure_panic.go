@@ -0,0 +1,78 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/pkg/errors"
+)
+
+const (
+	// panicEventName Event name of the evaluation panic report
+	panicEventName = "evaluation_panic"
+	// panicChanSize The capacity of the evaluation panic queue, the panics are discarded when it is full
+	panicChanSize = 1 << 8
+)
+
+var panicChan = make(chan *evaluationPanic, panicChanSize)
+
+// asyncEvaluationPanic Push the panic to the reporting queue, discarded if the queue is full
+func asyncEvaluationPanic(p *evaluationPanic) {
+	select {
+	case panicChan <- p:
+	default:
+	}
+}
+
+// reportEvaluationPanic Report the panic as a monitor event, the message is the panic value and the stack
+func reportEvaluationPanic(ctx context.Context, p *evaluationPanic) {
+	application := cache.GetApplication(p.projectID)
+	if application == nil {
+		return
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return
+	}
+	extInfo := map[string]string{
+		"op":        p.op,
+		"layer_key": p.panic.LayerKey,
+		"revision":  application.Revision,
+	}
+	err := metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(p.projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  1, // rare, never sampled
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         env.LocalIP(),
+			ProjectId:  p.projectID,
+			EventName:  panicEventName,
+			StatusCode: env.EventStatus(errors.New(p.panic.Value)),
+			Message:    p.panic.Value + "\n" + p.panic.Stack,
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InputData:  p.op,
+			OutputData: env.JSONString(extInfo),
+			ExtInfo:    internal.EventExtInfo(extInfo),
+		},
+	}})
+	if err != nil {
+		log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
 // Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
 // It also encompasses functionalities such as traffic distribution for A/B experiments,
 // user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
@@ -9,8 +12,12 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
 	"github.com/abetterchoice/go-sdk/plugin/log"
 	"github.com/abetterchoice/protoc_event_server"
+	"github.com/pkg/errors"
 )
 
 type experimentExposure struct {
@@ -42,25 +49,27 @@ type remoteConfigEvent struct {
 }
 
 var (
-	// ExperimentExposureChanSize TODO
+	// ExperimentExposureChanSize The total capacity of the experiment exposure queue, split evenly between the shards
 	ExperimentExposureChanSize = 1 << 19
 	// ExperimentEventChanSize TODO
 	ExperimentEventChanSize = 1 << 19
-	// RemoteConfigExposureChanSize TODO
+	// RemoteConfigExposureChanSize The total capacity of the remote config exposure queue, split evenly between the
+	// shards
 	RemoteConfigExposureChanSize = 1 << 19
 	// RemoteConfigEventChanSize TODO
 	RemoteConfigEventChanSize = 1 << 19
 )
 
 var (
-	experimentExposureChan   = make(chan *experimentExposure, ExperimentExposureChanSize)
-	experimentEventChan      = make(chan *experimentEvent, ExperimentEventChanSize)
-	remoteConfigExposureChan = make(chan *remoteConfigExposure, RemoteConfigExposureChanSize)
-	remoteConfigEventChan    = make(chan *remoteConfigEvent, RemoteConfigEventChanSize)
+	experimentEventChan   = make(chan *experimentEvent, ExperimentEventChanSize)
+	remoteConfigEventChan = make(chan *remoteConfigEvent, RemoteConfigEventChanSize)
 )
 
 var (
 	defaultMaxParallelism = 4
+	// consumerHeartbeatInterval The heartbeat of the idle exposure consumers waiting on the queues, so that the
+	// LastActive of Tasks only stops moving when the consumer is stuck inside a plugin call
+	consumerHeartbeatInterval = time.Second
 )
 
 func maxParallelism() int {
@@ -71,33 +80,153 @@ func maxParallelism() int {
 	return defaultMaxParallelism
 }
 
-// initExposureConsumer Initialize exposure reporting consumer
+// exposureShard A shard of the exposure queues, so that the evaluations at high QPS do not contend on a single
+// channel. Each shard is drained by its own consumer, the exposures of a unitID always go to the same shard,
+// which keeps the reporting order of the user. The low volume event queues are shared by all consumers
+type exposureShard struct {
+	experimentExposureChan   chan *experimentExposure
+	remoteConfigExposureChan chan *remoteConfigExposure
+}
+
+// exposureShards One shard per consumer, the capacity of the queues is split evenly between the shards
+var exposureShards = newExposureShards(maxParallelism())
+
+func newExposureShards(n int) []*exposureShard {
+	shards := make([]*exposureShard, n)
+	for i := range shards {
+		shards[i] = &exposureShard{
+			experimentExposureChan:   make(chan *experimentExposure, shardCapacity(ExperimentExposureChanSize, n)),
+			remoteConfigExposureChan: make(chan *remoteConfigExposure, shardCapacity(RemoteConfigExposureChanSize, n)),
+		}
+	}
+	return shards
+}
+
+func shardCapacity(size int, n int) int {
+	return (size + n - 1) / n
+}
+
+// exposureShardOf The shard of the unitID by the FNV-1a hash, all units share the first shard in the simulation mode
+// so that the exposures are flushed in the enqueueing order
+func exposureShardOf(unitID string) *exposureShard {
+	if internal.C.IsSimulation {
+		return exposureShards[0]
+	}
+	var hash uint32 = 2166136261
+	for i := 0; i < len(unitID); i++ {
+		hash ^= uint32(unitID[i])
+		hash *= 16777619
+	}
+	return exposureShards[hash%uint32(len(exposureShards))]
+}
+
+// pendingExperimentExposures Merge the pending experiment exposures and the capacity of all shards
+func pendingExperimentExposures() (pending int, capacity int) {
+	for _, shard := range exposureShards {
+		pending += len(shard.experimentExposureChan)
+		capacity += cap(shard.experimentExposureChan)
+	}
+	return pending, capacity
+}
+
+// pendingRemoteConfigExposures Merge the pending remote config exposures and the capacity of all shards
+func pendingRemoteConfigExposures() (pending int, capacity int) {
+	for _, shard := range exposureShards {
+		pending += len(shard.remoteConfigExposureChan)
+		capacity += cap(shard.remoteConfigExposureChan)
+	}
+	return pending, capacity
+}
+
+// initExposureConsumer Initialize exposure reporting consumer, one per shard
 func initExposureConsumer() {
-	for i := 0; i < maxParallelism(); i++ {
-		go watchData()
+	for i, shard := range exposureShards {
+		shard := shard
+		internal.Go(fmt.Sprintf("exposureConsumer:%d", i), func(task *internal.Task) {
+			watchData(task, shard)
+		})
 	}
 }
 
+// automaticExposure The exposure type of the automatic exposures of the evaluations
+const automaticExposure = protoc_event_server.ExposureType_EXPOSURE_TYPE_AUTOMATIC
+
 // asyncExposureExperiments asynchronous push
 // Record exposure data. If passive exposure is not enabled, you can use the Exposure API for manual exposure
 // Manual exposure can avoid the overexposure problem that may be caused by passive exposure. Users can use manual exposure to report the exposure of the experiment they hit
 func asyncExposureExperiments(projectID string, list *ExperimentList,
 	exposureType protoc_event_server.ExposureType) error {
+	if list != nil {
+		if err := checkExposureRate(projectID, len(list.Data)); err != nil {
+			return err
+		}
+	}
+	item := &experimentExposure{projectID: projectID, list: list, et: exposureType}
+	delivery := internal.Delivery(projectID)
+	if delivery == internal.DeliveryBlocking {
+		return timedFlush(projectID, "exposureExperiments", func(ctx context.Context) error {
+			return exposureExperimentBatch(ctx, projectID, []*experimentExposure{item})
+		})
+	}
+	if delivery == internal.DeliveryBestEffort && (internal.QueueSize(projectID) > 0 || internal.ShedLevel() > 0) {
+		pending, capacity := pendingExperimentExposures() // the limit is on all shards
+		if isQueueFull(projectID, pending) {
+			return experimentExposureQueueFull(projectID, list)
+		}
+		if isQueueShed(pending, capacity) {
+			return experimentExposureQueueShed(projectID, list)
+		}
+	}
+	var unitID string
+	if list != nil && list.userCtx != nil {
+		unitID = list.userCtx.unitID
+	}
+	queue := exposureShardOf(unitID).experimentExposureChan
+	if delivery == internal.DeliveryAtLeastOnce {
+		if !enqueueExperimentExposure(queue, item) && !keepUndelivered(projectID, func(spool *exposureSpool) error {
+			return exposureExperimentBatch(withSpool(spool), projectID, []*experimentExposure{item})
+		}) {
+			return experimentExposureQueueFull(projectID, list)
+		}
+		return nil
+	}
 	select {
-	case experimentExposureChan <- &experimentExposure{
-		projectID: projectID,
-		list:      list,
-		et:        exposureType,
-	}:
+	case queue <- item:
 		return nil
 	default:
-		return fmt.Errorf("experimentExposureChan is full")
+		return experimentExposureQueueFull(projectID, list)
 	}
 }
 
-// asyncExposureExperimentEvent async exposure
+// experimentExposureQueueFull Count the discarded exposures into the loss and return the error
+func experimentExposureQueueFull(projectID string, list *ExperimentList) error {
+	if list != nil {
+		internal.RecordLoss(projectID, internal.LossReasonQueueFull, len(list.Data))
+	}
+	err := errors.Wrap(ErrBackpressure, "experimentExposureChan is full")
+	internal.ReportError(internal.ReasonQueueFull, projectID, err)
+	return err
+}
+
+// experimentExposureQueueShed Count the exposures discarded by the shrunk queue into the loss and return the error
+func experimentExposureQueueShed(projectID string, list *ExperimentList) error {
+	if list != nil {
+		internal.RecordLoss(projectID, internal.LossReasonShed, len(list.Data))
+	}
+	return errors.Wrap(ErrBackpressure, "experimentExposureChan is shed")
+}
+
+// asyncExposureExperimentEvent async exposure, the event is sampled before enqueueing so that the unsampled
+// evaluations do not pay for serializing the options
 func asyncExposureExperimentEvent(projectID string, list *ExperimentList,
-	latency time.Duration, optionStr string, err error) error {
+	latency time.Duration, options *experiment.Options, err error) error {
+	if !isEventSampled(projectID, err) {
+		return nil
+	}
+	optionStr := env.JSONString(options) // only serialized for the sampled events
+	if isQueueFull(projectID, len(experimentEventChan)) || isQueueShed(len(experimentEventChan), cap(experimentEventChan)) {
+		return fmt.Errorf("experimentEventChan is full")
+	}
 	select {
 	case experimentEventChan <- &experimentEvent{
 		projectID: projectID,
@@ -115,21 +244,65 @@ func asyncExposureExperimentEvent(projectID string, list *ExperimentList,
 // asyncExposureRemoteConfig async exposure
 func asyncExposureRemoteConfig(projectID string, configResult *ConfigResult,
 	exposureType protoc_event_server.ExposureType) error {
+	if err := checkExposureRate(projectID, 1); err != nil {
+		return err
+	}
+	delivery := internal.Delivery(projectID)
+	if delivery == internal.DeliveryBlocking {
+		return timedFlush(projectID, "exposureRemoteConfig", func(ctx context.Context) error {
+			return exposureRemoteConfig(ctx, projectID, configResult, exposureType)
+		})
+	}
+	if delivery == internal.DeliveryBestEffort && (internal.QueueSize(projectID) > 0 || internal.ShedLevel() > 0) {
+		pending, capacity := pendingRemoteConfigExposures() // the limit is on all shards
+		if isQueueFull(projectID, pending) {
+			return remoteConfigExposureQueueFull(projectID)
+		}
+		if isQueueShed(pending, capacity) {
+			internal.RecordLoss(projectID, internal.LossReasonShed, 1)
+			return errors.Wrap(ErrBackpressure, "remoteConfigExposureChan is shed")
+		}
+	}
+	var unitID string
+	if configResult != nil && configResult.userCtx != nil {
+		unitID = configResult.userCtx.unitID
+	}
+	item := &remoteConfigExposure{projectID: projectID, configResult: configResult, et: exposureType}
+	queue := exposureShardOf(unitID).remoteConfigExposureChan
+	if delivery == internal.DeliveryAtLeastOnce {
+		if !enqueueRemoteConfigExposure(queue, item) && !keepUndelivered(projectID, func(spool *exposureSpool) error {
+			return exposureRemoteConfig(withSpool(spool), projectID, configResult, exposureType)
+		}) {
+			return remoteConfigExposureQueueFull(projectID)
+		}
+		return nil
+	}
 	select {
-	case remoteConfigExposureChan <- &remoteConfigExposure{
-		projectID:    projectID,
-		configResult: configResult,
-		et:           exposureType,
-	}:
+	case queue <- item:
 		return nil
 	default:
-		return fmt.Errorf("remoteConfigExposureChan is full")
+		return remoteConfigExposureQueueFull(projectID)
 	}
 }
 
+// remoteConfigExposureQueueFull Count the discarded exposure into the loss and return the error
+func remoteConfigExposureQueueFull(projectID string) error {
+	internal.RecordLoss(projectID, internal.LossReasonQueueFull, 1)
+	err := errors.Wrap(ErrBackpressure, "remoteConfigExposureChan is full")
+	internal.ReportError(internal.ReasonQueueFull, projectID, err)
+	return err
+}
+
 // asyncExposureRemoteConfigEvent async exposure
 func asyncExposureRemoteConfigEvent(projectID string, configResult *ConfigResult,
-	latency time.Duration, optionStr string, err error) error {
+	latency time.Duration, options *experiment.Options, err error) error {
+	if !isEventSampled(projectID, err) {
+		return nil
+	}
+	optionStr := env.JSONString(options) // only serialized for the sampled events
+	if isQueueFull(projectID, len(remoteConfigEventChan)) || isQueueShed(len(remoteConfigEventChan), cap(remoteConfigEventChan)) {
+		return fmt.Errorf("remoteConfigEventChan is full")
+	}
 	select {
 	case remoteConfigEventChan <- &remoteConfigEvent{
 		projectID:    projectID,
@@ -144,31 +317,35 @@ func asyncExposureRemoteConfigEvent(projectID string, configResult *ConfigResult
 	}
 }
 
-func watchData() {
+func watchData(task *internal.Task, shard *exposureShard) {
+	ticker := time.NewTicker(consumerHeartbeatInterval)
+	defer ticker.Stop()
 	for {
-		logExposure()
+		task.Heartbeat()
+		logExposure(shard, ticker.C)
 	}
 }
 
-func logExposure() {
+// logExposure Report one exposure or event of the queues, return on the heartbeat if the queues are empty
+func logExposure(shard *exposureShard, heartbeat <-chan time.Time) {
 	defer func() {
 		recoverErr := recover() // Prevent third-party monitoring reporting plugins from panicking
 		if recoverErr != nil {
 			body := make([]byte, 1<<10)
 			runtime.Stack(body, false)
-			log.Errorf("recoverErr:%v\n%s", recoverErr, body)
+			log.Log(context.TODO(), log.ErrorLevel, "exposure panic", log.Any("recover", recoverErr),
+				log.Any("stack", string(body)))
+			internal.ReportBackgroundError("exposure", fmt.Errorf("recoverErr:%v\n%s", recoverErr, body))
 			return
 		}
 	}()
 	select {
-	case eExposure := <-experimentExposureChan:
+	case <-heartbeat:
+	case eExposure := <-shard.experimentExposureChan:
 		if eExposure == nil || eExposure.list == nil || len(eExposure.list.Data) == 0 {
 			return
 		}
-		err := exposureExperiments(context.TODO(), eExposure.projectID, eExposure.list, eExposure.et)
-		if err != nil {
-			// log.Errorf("exposureExperiments fail:%v", err)
-		}
+		flushExperimentExposures(shard, eExposure)
 	case eEvent := <-experimentEventChan:
 		if eEvent == nil || eEvent.list == nil || len(eEvent.list.Data) == 0 {
 			return
@@ -176,16 +353,27 @@ func logExposure() {
 		err := exposureExperimentEvent(context.TODO(), eEvent.projectID, eEvent.list, eEvent.latency, eEvent.optionStr,
 			eEvent.err)
 		if err != nil {
-			// log.Errorf("exposureExperimentEvent fail:%v", err)
+			internal.RecordError("exposureExperimentEvent:"+eEvent.projectID, err)
+			log.LimitedError(context.TODO(), "exposureExperimentEvent", "exposure experiment event fail",
+				log.Any("projectID", eEvent.projectID), log.Err(err))
 		}
-	case cExposure := <-remoteConfigExposureChan:
+	case cExposure := <-shard.remoteConfigExposureChan:
 		if cExposure == nil || cExposure.configResult == nil {
 			return
 		}
-		err := exposureRemoteConfig(context.TODO(), cExposure.projectID, cExposure.configResult, cExposure.et)
+		err := timedFlush(cExposure.projectID, "exposureRemoteConfig", func(ctx context.Context) error {
+			return exposureRemoteConfig(ctx, cExposure.projectID, cExposure.configResult, cExposure.et)
+		})
 		if err != nil {
-			log.Errorf("exposureRemoteConfig fail:%v", err)
+			log.LimitedError(context.TODO(), "exposureRemoteConfig", "exposure remote config fail",
+				log.Any("projectID", cExposure.projectID), log.Err(err))
 		}
+	case op := <-slowOpChan:
+		reportSlowOp(context.TODO(), op)
+	case event := <-failoverChan:
+		reportFailover(context.TODO(), event)
+	case p := <-panicChan:
+		reportEvaluationPanic(context.TODO(), p)
 	case cEvent := <-remoteConfigEventChan:
 		if cEvent == nil || cEvent.configResult == nil {
 			return
@@ -193,7 +381,35 @@ func logExposure() {
 		err := exposureRemoteConfigEvent(context.TODO(), cEvent.projectID, cEvent.configResult, cEvent.latency,
 			cEvent.optionStr, cEvent.err)
 		if err != nil {
-			log.Errorf("exposureRemoteConfig fail:%v", err)
+			internal.RecordError("exposureRemoteConfigEvent:"+cEvent.projectID, err)
+			log.LimitedError(context.TODO(), "exposureRemoteConfigEvent", "evaluation event fail",
+				log.Any("projectID", cEvent.projectID), log.Err(err))
 		}
 	}
 }
+
+// GetExposureStats Get the statistics of the asynchronous exposure reporting pipeline, concurrent and safe
+func GetExposureStats() *ExposureStats {
+	experimentPending, experimentCapacity := pendingExperimentExposures()
+	remoteConfigPending, remoteConfigCapacity := pendingRemoteConfigExposures()
+	stats := &ExposureStats{
+		Queues: []*ExposureQueueStats{
+			{Name: "experimentExposure", Pending: experimentPending, Capacity: experimentCapacity,
+				Shards: len(exposureShards)},
+			{Name: "experimentEvent", Pending: len(experimentEventChan), Capacity: cap(experimentEventChan), Shards: 1},
+			{Name: "remoteConfigExposure", Pending: remoteConfigPending, Capacity: remoteConfigCapacity,
+				Shards: len(exposureShards)},
+			{Name: "remoteConfigEvent", Pending: len(remoteConfigEventChan), Capacity: cap(remoteConfigEventChan),
+				Shards: 1},
+		},
+		Consumers:   len(exposureShards),
+		Loss:        make(map[string]map[LossReason]uint64, len(internal.C.ProjectIDList)),
+		Guardrail:   guardrailStats(),
+		Flush:       flushStats(),
+		Undelivered: undelivered.size(),
+	}
+	for _, projectID := range internal.C.ProjectIDList {
+		stats.Loss[projectID] = internal.LossCounts(projectID)
+	}
+	return stats
+}
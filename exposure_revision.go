@@ -0,0 +1,94 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+const (
+	// revisionEventName Event name of the config revision report
+	revisionEventName = "config_revision"
+	// idleRevisionReportInterval The polling interval of the reporter while the report is disabled
+	idleRevisionReportInterval = time.Minute
+)
+
+var revisionReporterOnce sync.Once
+
+// initRevisionReporter Start the config revision reporter, only one reporter is started across Init and Release,
+// which reads the projectIDs and the interval of the current global configuration every round
+func initRevisionReporter() {
+	revisionReporterOnce.Do(func() {
+		internal.Go("revisionReporter", func(task *internal.Task) {
+			for {
+				task.Heartbeat()
+				interval := internal.C.ConfigRevisionReportInterval
+				if interval <= 0 {
+					time.Sleep(idleRevisionReportInterval)
+					continue
+				}
+				time.Sleep(interval)
+				for _, projectID := range internal.C.ProjectIDList {
+					reportRevision(context.Background(), projectID)
+				}
+			}
+		})
+	})
+}
+
+// reportRevision Report the revision of the configuration served by the projectID as a monitor event,
+// the age is the seconds since the revision was loaded
+func reportRevision(ctx context.Context, projectID string) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return
+	}
+	extInfo := map[string]string{
+		"revision":   application.Revision,
+		"version":    application.Version,
+		"updateTime": strconv.FormatInt(application.UpdateTime.Unix(), 10),
+		"age":        strconv.FormatInt(int64(time.Since(application.UpdateTime).Seconds()), 10),
+	}
+	err := metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  1, // one event per replica and interval, never sampled
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         env.LocalIP(),
+			ProjectId:  projectID,
+			EventName:  revisionEventName,
+			StatusCode: env.EventStatus(nil),
+			Message:    "config revision report",
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InputData:  application.Version,
+			OutputData: application.Revision,
+			ExtInfo:    internal.EventExtInfo(extInfo),
+		},
+	}})
+	if err != nil {
+		log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+	}
+}
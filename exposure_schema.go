@@ -0,0 +1,32 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+)
+
+// remoteConfigExpandedDataColumn The index of the expanded data column of the remote config rows, see
+// convertRemoteConfig
+const remoteConfigExpandedDataColumn = 10
+
+// remoteConfigRows The rows of the remote config exposure passed to the plugin of the metadata, data is the row of
+// the current schema version. The row is downconverted if the plugin only supports an older schema, the exposures
+// and the monitor events are downconverted by the metrics package, see metrics.NegotiateSchemaVersion
+func remoteConfigRows(projectID string, config *ConfigResult, metadata *metrics.Metadata,
+	data []string) [][]string {
+	version := metrics.NegotiateSchemaVersion(metadata.MetricsPluginName, metrics.CurrentSchemaVersion)
+	metadata.SchemaVersion = version
+	if version >= metrics.SchemaVersion2 {
+		return [][]string{data}
+	}
+	row := make([]string, len(data))
+	copy(row, data)
+	// SchemaVersion1: the legacy encoding without the event ID
+	row[remoteConfigExpandedDataColumn] = encodeLegacyExpandedData(configExpandedData(projectID, config))
+	return [][]string{row}
+}
@@ -0,0 +1,45 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteConfigRows(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithEventID(true),
+		WithExpandedDataEncoding(ExpandedDataEncodingJSON))
+	assert.Nil(t, err)
+	metrics.RegisterSchemaVersion("legacy", metrics.SchemaVersion1)
+	defer metrics.RegisterSchemaVersion("legacy", 0)
+	userCtx := NewUserContext("12345", WithExpandedData(map[string]string{"k": "v"})).(*userContext)
+	config := &ConfigResult{userCtx: userCtx,
+		Config: &Config{Value: &Value{}, remoteConfig: &protoc_cache_server.RemoteConfig{}}}
+	data := convertRemoteConfig(projectID, config, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
+	assert.Contains(t, data[remoteConfigExpandedDataColumn], internal.EventIDKey)
+
+	metadata := &metrics.Metadata{MetricsPluginName: "current"}
+	rows := remoteConfigRows(projectID, config, metadata, data)
+	assert.Equal(t, [][]string{data}, rows)
+	assert.Equal(t, metrics.CurrentSchemaVersion, metadata.SchemaVersion)
+
+	metadata = &metrics.Metadata{MetricsPluginName: "legacy"}
+	rows = remoteConfigRows(projectID, config, metadata, data)
+	assert.Equal(t, metrics.SchemaVersion1, metadata.SchemaVersion)
+	assert.Equal(t, "k=v;new_id=12345", rows[0][remoteConfigExpandedDataColumn])
+	assert.Equal(t, data[:remoteConfigExpandedDataColumn], rows[0][:remoteConfigExpandedDataColumn])
+	assert.Contains(t, data[remoteConfigExpandedDataColumn], internal.EventIDKey) // not modified
+}
@@ -0,0 +1,114 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+const (
+	// slowOpEventName Event name of the slow operation report
+	slowOpEventName = "slow_op"
+	// slowOpChanSize The capacity of the slow operation queue, the slow operations are discarded when it is full
+	slowOpChanSize = 1 << 10
+)
+
+var slowOpChan = make(chan *slowOp, slowOpChanSize)
+
+// asyncSlowOp Push the slow operation to the reporting queue, discarded if the queue is full
+func asyncSlowOp(op *slowOp) {
+	select {
+	case slowOpChan <- op:
+	default:
+	}
+}
+
+// sendTiming The time spent in the metrics plugin during an exposure flush
+type sendTiming struct {
+	send time.Duration
+}
+
+type sendTimingContextKey struct{}
+
+// timeSend Add the time spent in the metrics plugin since start to the send timing of ctx, if any
+func timeSend(ctx context.Context, start time.Time) {
+	timing, ok := ctx.Value(sendTimingContextKey{}).(*sendTiming)
+	if ok {
+		timing.send += time.Since(start)
+	}
+}
+
+// timedFlush Run the exposure flush, and report it as a slow operation if the slow operation threshold is exceeded.
+// The flush time is counted into the busy time of the guardrail
+func timedFlush(projectID string, op string, flush func(ctx context.Context) error) error {
+	if internal.IsGuardrailEnabled() {
+		defer internal.RecordBusy(time.Now())
+	}
+	threshold := internal.C.SlowOpThreshold
+	if threshold <= 0 {
+		return flush(context.TODO())
+	}
+	timing := &sendTiming{}
+	start := time.Now()
+	err := flush(context.WithValue(context.TODO(), sendTimingContextKey{}, timing))
+	latency := time.Since(start)
+	if latency >= threshold {
+		asyncSlowOp(&slowOp{projectID: projectID, op: op, latency: latency,
+			breakdown: map[string]time.Duration{slowOpPluginSend: timing.send}})
+	}
+	return err
+}
+
+// reportSlowOp Report the slow operation as a monitor event, the breakdown is in microseconds
+func reportSlowOp(ctx context.Context, op *slowOp) {
+	application := cache.GetApplication(op.projectID)
+	if application == nil {
+		return
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return
+	}
+	extInfo := make(map[string]string, len(op.breakdown))
+	for key, latency := range op.breakdown {
+		extInfo[key] = strconv.FormatInt(latency.Microseconds(), 10)
+	}
+	err := metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(op.projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  1, // rare, never sampled
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         env.LocalIP(),
+			ProjectId:  op.projectID,
+			EventName:  slowOpEventName,
+			Latency:    float32(op.latency.Microseconds()), // us
+			StatusCode: env.EventStatus(nil),
+			Message:    op.op,
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InputData:  internal.C.SlowOpThreshold.String(),
+			OutputData: env.JSONString(extInfo),
+			ExtInfo:    internal.EventExtInfo(extInfo),
+		},
+	}})
+	if err != nil {
+		log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+	}
+}
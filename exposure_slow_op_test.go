@@ -0,0 +1,59 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimedFlush(t *testing.T) {
+	defer func(threshold time.Duration) {
+		internal.C.SlowOpThreshold = threshold
+	}(internal.C.SlowOpThreshold)
+	flushErr := errors.New("flush fail")
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		wantTimed bool
+	}{
+		{name: "disabled", threshold: 0, wantTimed: false},
+		{name: "enabled", threshold: time.Hour, wantTimed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			internal.C.SlowOpThreshold = tt.threshold
+			err := timedFlush(projectID, "test", func(ctx context.Context) error {
+				_, ok := ctx.Value(sendTimingContextKey{}).(*sendTiming)
+				assert.Equal(t, tt.wantTimed, ok)
+				timeSend(ctx, time.Now())
+				return flushErr
+			})
+			assert.Equal(t, flushErr, err)
+		})
+	}
+}
+
+func TestTimeSend(t *testing.T) {
+	timing := &sendTiming{}
+	ctx := context.WithValue(context.Background(), sendTimingContextKey{}, timing)
+	timeSend(ctx, time.Now().Add(-time.Millisecond))
+	assert.True(t, timing.send >= time.Millisecond)
+	timeSend(context.Background(), time.Now()) // no timing, nothing happens
+}
+
+func TestNewEvaluationSlowOp(t *testing.T) {
+	op := newEvaluationSlowOp(projectID, "GetExperiments", time.Second, &experiment.Timing{
+		CacheLookup: time.Millisecond, RuleEval: 2 * time.Millisecond, Hashing: 3 * time.Millisecond})
+	assert.Equal(t, map[string]time.Duration{slowOpCacheLookup: time.Millisecond, slowOpRuleEval: 2 * time.Millisecond,
+		slowOpHashing: 3 * time.Millisecond}, op.breakdown)
+	assert.Equal(t, time.Second, op.latency)
+}
@@ -0,0 +1,221 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+const (
+	srmEventName = "srm_alert"
+	// srmSlots The number of the slots of the sliding window, the window slides by one slot
+	srmSlots = 10
+)
+
+var (
+	srmDetectorOnce sync.Once
+	// srmCounters The counters of the experiments, key is srmCounterKey
+	srmCounters sync.Map
+)
+
+// srmCounter The realized group counts of an experiment in the slots of the sliding window
+type srmCounter struct {
+	projectID     string
+	layerKey      string
+	experimentKey string
+
+	lock sync.Mutex
+	// The counts of the slots, key is groupID
+	slots [srmSlots]map[int64]uint64
+	// The sequence number of the period counted by each slot
+	periods   [srmSlots]int64
+	lastAlert time.Time
+}
+
+func srmCounterKey(projectID string, layerKey string, experimentKey string) string {
+	return projectID + "\x00" + layerKey + "\x00" + experimentKey
+}
+
+// srmSlotDuration The duration of a slot of the window, the released detector checks once per second
+func srmSlotDuration() time.Duration {
+	if internal.C.SRMWindow <= 0 {
+		return time.Second
+	}
+	slot := internal.C.SRMWindow / srmSlots
+	if slot <= 0 {
+		slot = time.Millisecond
+	}
+	return slot
+}
+
+// initSRMDetector Start the detector, only one detector is started across Init and Release,
+// which checks the window of the current global configuration
+func initSRMDetector() {
+	if internal.C.SRMWindow <= 0 {
+		return
+	}
+	srmDetectorOnce.Do(func() {
+		internal.Go("srmDetector", func(task *internal.Task) {
+			for {
+				time.Sleep(srmSlotDuration())
+				task.Heartbeat()
+				if internal.C.SRMWindow <= 0 { // released
+					continue
+				}
+				checkSRM(context.Background(), time.Now())
+			}
+		})
+	})
+}
+
+// resetSRM Clear the counters, called by Release
+func resetSRM() {
+	srmCounters.Range(func(key, value interface{}) bool {
+		srmCounters.Delete(key)
+		return true
+	})
+}
+
+// recordSRM Count the groups assigned by the evaluation, the default groups and the override lists are not drawn
+// by the traffic weights and are skipped
+func recordSRM(projectID string, list *ExperimentList) {
+	if internal.C.SRMWindow <= 0 || list == nil {
+		return
+	}
+	now := time.Now()
+	for layerKey, group := range list.Data {
+		if group == nil || group.IsDefault || group.IsOverrideList || len(group.ExperimentKey) == 0 {
+			continue
+		}
+		key := srmCounterKey(projectID, layerKey, group.ExperimentKey)
+		value, ok := srmCounters.Load(key)
+		if !ok {
+			value, _ = srmCounters.LoadOrStore(key, &srmCounter{projectID: projectID, layerKey: layerKey,
+				experimentKey: group.ExperimentKey})
+		}
+		value.(*srmCounter).add(now, group.ID)
+	}
+}
+
+func (c *srmCounter) add(now time.Time, groupID int64) {
+	period := now.UnixNano() / int64(srmSlotDuration())
+	slot := period % srmSlots
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.periods[slot] != period || c.slots[slot] == nil {
+		c.periods[slot] = period
+		c.slots[slot] = make(map[int64]uint64, 2)
+	}
+	c.slots[slot][groupID]++
+}
+
+// counts The counts of the window ending at now, key is groupID
+func (c *srmCounter) counts(now time.Time) map[int64]uint64 {
+	period := now.UnixNano() / int64(srmSlotDuration())
+	counts := make(map[int64]uint64)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for slot, slotCounts := range c.slots {
+		if c.periods[slot] <= period-srmSlots {
+			continue // outside the window
+		}
+		for groupID, count := range slotCounts {
+			counts[groupID] += count
+		}
+	}
+	return counts
+}
+
+// isAlerting Whether to emit the alert, at most once per window
+func (c *srmCounter) isAlerting(now time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.lastAlert.IsZero() && now.Sub(c.lastAlert) < internal.C.SRMWindow {
+		return false
+	}
+	c.lastAlert = now
+	return true
+}
+
+// checkSRM Test the counts of every experiment against the weights of the current configuration, the groups not
+// assigned in the window count zero
+func checkSRM(ctx context.Context, now time.Time) {
+	srmCounters.Range(func(key, value interface{}) bool {
+		counter := value.(*srmCounter)
+		application := cache.GetApplication(counter.projectID)
+		if application == nil {
+			return true
+		}
+		weights := experimentWeights(application, counter.layerKey, counter.experimentKey)
+		counts := counter.counts(now)
+		if len(counts) == 0 {
+			srmCounters.Delete(key) // the experiment is no longer evaluated
+			return true
+		}
+		result, ok := srmTest(counts, weights)
+		if !ok || result.pValue >= internal.C.SRMThreshold || !counter.isAlerting(now) {
+			return true
+		}
+		reportSRM(ctx, application, counter, counts, result)
+		return true
+	})
+}
+
+// reportSRM Report the mismatch as a monitor event, the observed and the expected counts of the groups are in
+// the extInfo keyed by observed_<groupID> and expected_<groupID>
+func reportSRM(ctx context.Context, application *cache.Application, counter *srmCounter,
+	counts map[int64]uint64, result *srmResult) {
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return
+	}
+	extInfo := map[string]string{
+		"layer_key":      counter.layerKey,
+		"experiment_key": counter.experimentKey,
+		"chi_square":     strconv.FormatFloat(result.chiSquare, 'g', 6, 64),
+		"p_value":        strconv.FormatFloat(result.pValue, 'g', 6, 64),
+	}
+	for groupID, expected := range result.expected {
+		id := strconv.FormatInt(groupID, 10)
+		extInfo["observed_"+id] = strconv.FormatUint(counts[groupID], 10)
+		extInfo["expected_"+id] = strconv.FormatFloat(expected, 'f', 1, 64)
+	}
+	err := metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(counter.projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  1, // rare, never sampled
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         env.LocalIP(),
+			ProjectId:  counter.projectID,
+			EventName:  srmEventName,
+			StatusCode: env.EventStatus(nil),
+			Message:    counter.experimentKey,
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InputData:  internal.C.SRMWindow.String(),
+			OutputData: env.JSONString(extInfo),
+			ExtInfo:    internal.EventExtInfo(extInfo),
+		},
+	}})
+	if err != nil {
+		log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSRMCounter(t *testing.T) {
+	defer func(window time.Duration) {
+		internal.C.SRMWindow = window
+	}(internal.C.SRMWindow)
+	internal.C.SRMWindow = 10 * time.Second
+	counter := &srmCounter{}
+	now := time.Unix(1000, 0)
+	counter.add(now, 1)
+	counter.add(now.Add(time.Second), 1)
+	counter.add(now.Add(time.Second), 2)
+	assert.Equal(t, map[int64]uint64{1: 2, 2: 1}, counter.counts(now.Add(time.Second)))
+	// the first slot slides out of the window
+	assert.Equal(t, map[int64]uint64{1: 1, 2: 1}, counter.counts(now.Add(10*time.Second)))
+	assert.Equal(t, map[int64]uint64{}, counter.counts(now.Add(time.Minute)))
+	// the slot is reused by a later period
+	counter.add(now.Add(10*time.Second), 2)
+	assert.Equal(t, map[int64]uint64{1: 1, 2: 2}, counter.counts(now.Add(10*time.Second)))
+
+	assert.True(t, counter.isAlerting(now))
+	assert.False(t, counter.isAlerting(now.Add(time.Second)))
+	assert.True(t, counter.isAlerting(now.Add(10*time.Second)))
+}
+
+func TestCheckSRM(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithSRMDetector(time.Hour, 0))
+	assert.Nil(t, err)
+	application := cache.GetApplication(projectID)
+	assert.Equal(t, map[int64]float64{100002001: 1000, 100002002: 1000},
+		experimentWeights(application, "overrideLayer", "100002"))
+
+	list := &ExperimentList{Data: map[string]*Group{
+		"overrideLayer": {ID: 100002001, ExperimentKey: "100002", LayerKey: "overrideLayer"},
+		"multiLayer2":   {ID: 101002001, ExperimentKey: "101002", LayerKey: "multiLayer2", IsOverrideList: true},
+	}}
+	for i := 0; i < 20; i++ {
+		recordSRM(projectID, list)
+	}
+	_, ok := srmCounters.Load(srmCounterKey(projectID, "multiLayer2", "101002"))
+	assert.False(t, ok) // the override lists are not drawn by the weights
+	value, ok := srmCounters.Load(srmCounterKey(projectID, "overrideLayer", "100002"))
+	assert.True(t, ok)
+	counter := value.(*srmCounter)
+	now := time.Now()
+	checkSRM(context.Background(), now)
+	assert.False(t, counter.isAlerting(now)) // alerted, all assigned to one of the two groups
+
+	resetSRM()
+	_, ok = srmCounters.Load(srmCounterKey(projectID, "overrideLayer", "100002"))
+	assert.False(t, ok)
+}
@@ -1,13 +1,24 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
 // Package abc ...
 package abc
 
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
 	"github.com/abetterchoice/go-sdk/testdata"
 	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -292,3 +303,366 @@ func BenchmarkInt64Join(b *testing.B) {
 		}
 	})
 }
+
+func TestExposureOnError(t *testing.T) {
+	defer Release()
+	var reasons []ErrorReason
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient),
+		WithOnError(func(reason ErrorReason, projectID string, err error) {
+			if projectID != "not_loaded" { // the consumers may still flush the exposures queued by the previous tests
+				return
+			}
+			reasons = append(reasons, reason)
+		}))
+	assert.Nil(t, err)
+	list := &ExperimentList{Data: map[string]*Group{"layer": {ID: 1, LayerKey: "layer"}}}
+	err = LogExperimentsExposure(context.Background(), "not_loaded", list)
+	assert.Nil(t, err)
+	assert.Equal(t, []ErrorReason{ReasonConfigNotLoaded}, reasons)
+}
+
+func TestConvertExperimentV2Template(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	list, err := userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, len(list.Data))
+	application := cache.GetApplication(projectID)
+	for _, group := range list.Data {
+		template := cache.GetExposureTemplate(application, group.ID)
+		if template == nil {
+			continue
+		}
+		// the exposure built from the template is the same as the one built from the group
+		got := convertExperimentV2(projectID, group, list.userCtx, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL,
+			1, nil, template)
+		want := convertExperimentV2(projectID, group, list.userCtx, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL,
+			1, nil, nil)
+		assert.Equal(t, want.String(), got.String())
+	}
+}
+
+// BenchmarkConvertExperimentList Convert the evaluation of all synthetic layers to exposures, see benchmark/README.md
+func BenchmarkConvertExperimentList(b *testing.B) {
+	defer Release()
+	err := Init(context.Background(), projectIDList,
+		WithRegisterCacheClient(testdata.MockSyntheticCacheClient(b, 50, 20, 0)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	if err != nil {
+		b.Fatal(err)
+	}
+	list, err := NewUserContext("12345", WithExpandedData(map[string]string{"k": "v"})).
+		GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = convertExperimentList(projectID, list, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL, nil)
+	}
+}
+
+func TestExposureShardOf(t *testing.T) {
+	assert.Equal(t, maxParallelism(), len(exposureShards))
+	assert.Equal(t, exposureShardOf("12345"), exposureShardOf("12345")) // the same user, the same shard
+	used := make(map[*exposureShard]bool)
+	for i := 0; i < 1000; i++ {
+		used[exposureShardOf(fmt.Sprintf("unit%d", i))] = true
+	}
+	assert.Equal(t, len(exposureShards), len(used))
+	stats := GetExposureStats()
+	assert.Equal(t, len(exposureShards), stats.Consumers)
+	for _, queue := range stats.Queues {
+		if queue.Name == "experimentExposure" {
+			assert.Equal(t, len(exposureShards), queue.Shards)
+			assert.True(t, queue.Capacity >= ExperimentExposureChanSize)
+		}
+	}
+}
+
+func TestLogExposureHeartbeat(t *testing.T) {
+	shard := newExposureShards(1)[0]
+	heartbeat := make(chan time.Time, 1)
+	heartbeat <- time.Now()
+	done := make(chan struct{})
+	go func() {
+		logExposure(shard, heartbeat)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the idle consumer is blocked on the queues")
+	}
+}
+
+func TestExposureGuardrail(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithGuardrail(0.1, 0))
+	assert.Nil(t, err)
+	for i := 0; i < internal.MaxShedLevel; i++ {
+		internal.UpdateShedLevel(internal.GuardrailUsage{CPU: 1})
+	}
+	stats := GetExposureStats()
+	assert.NotNil(t, stats.Guardrail)
+	// the guardrail round may lower the level by one in between
+	assert.True(t, stats.Guardrail.ShedLevel >= internal.MaxShedLevel-1)
+	assert.True(t, isQueueShed(ExperimentExposureChanSize>>internal.MaxShedLevel, ExperimentExposureChanSize))
+	assert.False(t, isQueueShed(0, ExperimentExposureChanSize))
+	for i := 0; i < 100; i++ {
+		group := &protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{{UnitId: "12345"}}}
+		assert.Nil(t, reportExposureGroup(context.Background(), projectID, &metrics.Metadata{SamplingInterval: 1},
+			group))
+	}
+	assert.NotEqual(t, uint64(0), GetLossCounts(projectID)[LossReasonShed])
+}
+
+func TestExposureSampledInterval(t *testing.T) {
+	group := &protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{{UnitId: "12345"}}}
+	sampled := 0
+	for i := 0; i < 100; i++ {
+		metadata := &metrics.Metadata{SamplingInterval: 2}
+		assert.Nil(t, reportExposureGroup(context.Background(), projectID, metadata, group))
+		if metadata.SampledInterval != 0 {
+			assert.Equal(t, &metrics.Metadata{SamplingInterval: 1, SampledInterval: 2}, metadata)
+			sampled++
+		}
+	}
+	assert.True(t, sampled > 0 && sampled < 100)
+	metadata := &metrics.Metadata{SamplingInterval: 1, SampledInterval: 10} // handed off by the previous process
+	assert.Nil(t, reportExposureData(context.Background(), projectID, metadata, [][]string{{"a"}}))
+	assert.Equal(t, &metrics.Metadata{SamplingInterval: 1, SampledInterval: 10}, metadata)
+}
+
+// recordMetricsClient Record the exposures logged, see metrics.MessageRetainer
+type recordMetricsClient struct {
+	metrics.Client
+	lock      sync.Mutex
+	retained  bool
+	calls     int
+	exposures []*protoc_event_server.Exposure
+}
+
+func (r *recordMetricsClient) Name() string {
+	return "pubsub" // the default experiment metrics plugin of the test data
+}
+
+func (r *recordMetricsClient) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	group *protoc_event_server.ExposureGroup) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls++
+	r.exposures = append(r.exposures, group.Exposures...)
+	return nil
+}
+
+func (r *recordMetricsClient) RetainsMessages() bool {
+	return r.retained
+}
+
+// failLogMetricsClient Fail every exposure logged
+type failLogMetricsClient struct {
+	recordMetricsClient
+}
+
+func (f *failLogMetricsClient) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	group *protoc_event_server.ExposureGroup) error {
+	return fmt.Errorf("log exposure fail")
+}
+
+func TestExposureErrorRecordedOnce(t *testing.T) {
+	defer Release()
+	metrics.RegisterClient(&failLogMetricsClient{recordMetricsClient{Client: testdata.EmptyMetricsClient}})
+	defer metrics.RegisterClient(&recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true})
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	internal.ResetRecentErrors()
+	flushExperimentBatch(projectID, []*experimentExposure{{projectID: projectID, list: list,
+		et: protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL}})
+	var records int
+	for _, record := range internal.RecentErrors() {
+		if strings.Contains(record.Message, "log exposure fail") {
+			records++
+		}
+	}
+	assert.Equal(t, 1, records)
+}
+
+func TestExposureMessagePool(t *testing.T) {
+	tests := []struct {
+		name      string
+		retained  bool
+		isDisable bool
+		wantReset bool
+	}{
+		{name: "released", retained: false, isDisable: false, wantReset: true},
+		{name: "retained", retained: true, isDisable: false, wantReset: false},
+		{name: "disabled", retained: false, isDisable: true, wantReset: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer Release()
+			client := &recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: tt.retained}
+			metrics.RegisterClient(client)
+			defer metrics.RegisterClient(&recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true})
+			err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+				WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithDisableMessagePool(tt.isDisable))
+			assert.Nil(t, err)
+			list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+			assert.Nil(t, err)
+			assert.Nil(t, exposureExperiments(context.Background(), projectID, list,
+				protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL))
+			client.lock.Lock()
+			defer client.lock.Unlock()
+			assert.NotEqual(t, 0, len(client.exposures))
+			for _, exposure := range client.exposures {
+				assert.Equal(t, tt.wantReset, len(exposure.UnitId) == 0)
+			}
+		})
+	}
+}
+
+func TestAdaptiveFlush(t *testing.T) {
+	defer Release()
+	client := &recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true}
+	metrics.RegisterClient(client)
+	defer metrics.RegisterClient(&recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true})
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithAdaptiveFlush(0, 1)))
+	Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithAdaptiveFlush(time.Second, 8))
+	assert.Nil(t, err)
+	var batch []*experimentExposure
+	for i := 0; i < 4; i++ {
+		list, err := NewUserContext(strconv.Itoa(i)).GetExperiments(context.Background(), projectID,
+			WithAutomatic(false))
+		assert.Nil(t, err)
+		batch = append(batch, &experimentExposure{projectID: projectID, list: list,
+			et: protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL})
+	}
+	assert.Nil(t, exposureExperimentBatch(context.Background(), projectID, batch[:1]))
+	client.lock.Lock()
+	calls, exposures := client.calls, len(client.exposures)
+	client.calls, client.exposures = 0, nil
+	client.lock.Unlock()
+	assert.NotEqual(t, 0, calls)
+
+	shard := newExposureShards(1)[0]
+	for _, item := range batch[1:] {
+		shard.experimentExposureChan <- item
+	}
+	batches, projectIDs := drainExperimentExposures(shard, batch[0], 3)
+	assert.Equal(t, []string{projectID}, projectIDs)
+	assert.Equal(t, 3, len(batches[projectID]))
+	assert.Equal(t, 1, len(shard.experimentExposureChan))
+	assert.Nil(t, exposureExperimentBatch(context.Background(), projectID, batches[projectID]))
+	client.lock.Lock()
+	assert.Equal(t, calls, client.calls) // merged into the same plugin calls
+	assert.True(t, len(client.exposures) > exposures)
+	client.lock.Unlock()
+
+	flushExperimentExposures(shard, <-shard.experimentExposureChan)
+	stats := GetExposureStats()
+	assert.NotNil(t, stats.Flush)
+	assert.Equal(t, 1, stats.Flush.BatchSize)
+	assert.Equal(t, len(exposureShards), stats.Flush.Concurrency)
+}
+
+func TestSimulation(t *testing.T) {
+	defer Release()
+	samples := func() []bool {
+		result := make([]bool, 100)
+		for i := range result {
+			result[i] = metrics.SamplingResult(4)
+		}
+		return result
+	}
+	var runs [][]bool
+	for i := 0; i < 2; i++ {
+		err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+			WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithSimulation(42))
+		assert.Nil(t, err)
+		assert.Equal(t, exposureShards[0], exposureShardOf("12345"))
+		assert.Equal(t, exposureShards[0], exposureShardOf("54321"))
+		runs = append(runs, samples())
+		Release()
+	}
+	assert.Equal(t, runs[0], runs[1])
+	assert.Contains(t, runs[0], true)
+	assert.Contains(t, runs[0], false)
+}
+
+func TestUnitIDHashSalt(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithUnitIDHashSalt(projectID, "salt"))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345", WithNewUnitID("user1"), WithDecisionID("d1"))
+	list, err := userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	_, exposures := convertExperimentList(projectID, list, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL, nil)
+	assert.NotEqual(t, 0, len(exposures.Exposures))
+	exposure := exposures.Exposures[0]
+	assert.Equal(t, 64, len(exposure.UnitId))
+	assert.Equal(t, hashUnitID(projectID, "12345"), exposure.UnitId)
+	assert.Equal(t, hashUnitID(projectID, "d1"), exposure.ClusterId)
+	assert.Equal(t, hashUnitID(projectID, "user1"), exposure.ExtraData[newIDKey])
+	assert.NotEqual(t, hashUnitID(projectID, "12345"), hashUnitID(projectID, "12346"))
+	assert.Equal(t, "12345", hashUnitID("notHashed", "12345"))
+
+	data := convertRemoteConfig(projectID, &ConfigResult{userCtx: list.userCtx, Config: &Config{Value: &Value{},
+		remoteConfig: &protoc_cache_server.RemoteConfig{}}}, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL)
+	assert.Equal(t, hashUnitID(projectID, "12345"), data[0])
+	assert.Equal(t, newIDKey+"="+hashUnitID(projectID, "user1"), data[10])
+
+	assert.NotNil(t, WithUnitIDHashSalt(projectID, "")(internal.C))
+}
+
+func TestExposureEventID(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithEventID(true))
+	assert.Nil(t, err)
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	_, exposures := convertExperimentList(projectID, list, protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL, nil)
+	assert.Less(t, 1, len(exposures.Exposures))
+	ids := make(map[string]bool)
+	for _, exposure := range exposures.Exposures {
+		assert.Equal(t, "12345", exposure.ExtraData[newIDKey])
+		assert.Len(t, exposure.ExtraData[internal.EventIDKey], 36)
+		ids[exposure.ExtraData[internal.EventIDKey]] = true
+	}
+	assert.Equal(t, len(exposures.Exposures), len(ids))
+	assert.Contains(t, marshalExpandedData(projectID, list.userCtx), internal.EventIDKey+"=")
+}
+
+func TestLogRolloutExposure(t *testing.T) {
+	defer Release()
+	client := &recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true}
+	metrics.RegisterClient(client)
+	defer metrics.RegisterClient(&recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true})
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	assert.NotNil(t, LogRolloutExposure(context.Background(), projectID, "new_checkout", "", true))
+	assert.Nil(t, LogRolloutExposure(context.Background(), projectID, "new_checkout", "12345", true))
+	assert.Nil(t, LogRolloutExposure(context.Background(), projectID, "new_checkout", "67890", false))
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	assert.Len(t, client.exposures, 2)
+	assert.Equal(t, "new_checkout", client.exposures[0].LayerKey)
+	assert.Equal(t, RolloutGroupKey, client.exposures[0].ExpKey)
+	assert.Equal(t, HoldbackGroupKey, client.exposures[1].ExpKey)
+}
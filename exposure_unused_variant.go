@@ -0,0 +1,107 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+const (
+	// unusedVariantEventName Event name of the unused variant report
+	unusedVariantEventName = "unused_variant"
+	// idleUnusedVariantInterval The polling interval of the reporter while the detection is disabled
+	idleUnusedVariantInterval = 10 * time.Second
+	// maxUnusedVariantEventGroups The maximum number of the groups listed by an event
+	maxUnusedVariantEventGroups = 100
+)
+
+var unusedVariantReporterOnce sync.Once
+
+// initUnusedVariantReporter Start the unused variant reporter, only one reporter is started across Init and Release,
+// which reads the projectIDs and the window of the current global configuration every round
+func initUnusedVariantReporter() {
+	unusedVariantReporterOnce.Do(func() {
+		internal.Go("unusedVariantReporter", func(task *internal.Task) {
+			for {
+				task.Heartbeat()
+				window := internal.C.UnusedVariantWindow
+				if window <= 0 {
+					time.Sleep(idleUnusedVariantInterval)
+					continue
+				}
+				time.Sleep(window)
+				for _, projectID := range internal.C.ProjectIDList {
+					reportUnusedVariants(context.Background(), projectID)
+				}
+			}
+		})
+	})
+}
+
+// reportUnusedVariants Report the unused groups of the projectID as a monitor event, nothing if none, the groups are
+// listed as layerKey/experimentKey/groupID
+func reportUnusedVariants(ctx context.Context, projectID string) {
+	variants, err := GetUnusedVariants(projectID)
+	if err != nil || len(variants) == 0 {
+		return
+	}
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return
+	}
+	groups := make([]string, 0, len(variants))
+	for i, variant := range variants {
+		if i == maxUnusedVariantEventGroups {
+			break
+		}
+		groups = append(groups, variant.LayerKey+"/"+variant.ExperimentKey+"/"+strconv.FormatInt(variant.GroupID, 10))
+	}
+	extInfo := map[string]string{
+		"revision": application.Revision,
+		"count":    strconv.Itoa(len(variants)),
+		"groups":   strings.Join(groups, ","),
+	}
+	err = metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  1, // at most one event per replica and window, never sampled
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         env.LocalIP(),
+			ProjectId:  projectID,
+			EventName:  unusedVariantEventName,
+			StatusCode: env.EventStatus(nil),
+			Message:    "unused variant report",
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InputData:  internal.C.UnusedVariantWindow.String(),
+			OutputData: env.JSONString(extInfo),
+			ExtInfo:    internal.EventExtInfo(extInfo),
+		},
+	}})
+	if err != nil {
+		log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+	}
+}
@@ -0,0 +1,113 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+const (
+	// usageEventName Event name of the flag usage report
+	usageEventName = "flag_usage"
+	// idleUsageReportInterval The polling interval of the reporter while the report is disabled
+	idleUsageReportInterval = 10 * time.Second
+	// maxUsageEventKeys The maximum number of the unread and the unknown keys listed by an event each
+	maxUsageEventKeys = 100
+)
+
+var usageReporterOnce sync.Once
+
+// initUsageReporter Start the flag usage reporter, only one reporter is started across Init and Release,
+// which reads the projectIDs and the interval of the current global configuration every round
+func initUsageReporter() {
+	usageReporterOnce.Do(func() {
+		internal.Go("usageReporter", func(task *internal.Task) {
+			for {
+				task.Heartbeat()
+				interval := internal.C.UsageReportInterval
+				if interval <= 0 {
+					time.Sleep(idleUsageReportInterval)
+					continue
+				}
+				time.Sleep(interval)
+				for _, projectID := range internal.C.ProjectIDList {
+					reportUsage(context.Background(), projectID)
+				}
+			}
+		})
+	})
+}
+
+// reportUsage Report the usage of the keys of the projectID as a monitor event, at most maxUsageEventKeys unread
+// and unknown keys are listed, see GetUsageReport for all
+func reportUsage(ctx context.Context, projectID string) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable || metricsConfig.Metadata == nil {
+		return
+	}
+	report, err := GetUsageReport(projectID)
+	if err != nil {
+		return
+	}
+	unknown := make([]string, 0, len(report.Unknown))
+	for _, keyUsage := range report.Unknown {
+		unknown = append(unknown, keyUsage.Key)
+	}
+	extInfo := map[string]string{
+		"revision":     report.Revision,
+		"read_count":   strconv.Itoa(len(report.Read)),
+		"unread_count": strconv.Itoa(len(report.Unread)),
+		"unread":       strings.Join(truncateKeys(report.Unread), ","),
+		"unknown":      strings.Join(truncateKeys(unknown), ","),
+	}
+	err = metrics.LogMonitorEvent(ctx, &metrics.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  1, // one event per replica and interval, never sampled
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         env.LocalIP(),
+			ProjectId:  projectID,
+			EventName:  usageEventName,
+			StatusCode: env.EventStatus(nil),
+			Message:    "flag usage report",
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InputData:  internal.C.UsageReportInterval.String(),
+			OutputData: env.JSONString(extInfo),
+			ExtInfo:    internal.EventExtInfo(extInfo),
+		},
+	}})
+	if err != nil {
+		log.LimitedErrorf("sendEvent", "sendData fail:%v", err)
+	}
+}
+
+func truncateKeys(keys []string) []string {
+	if len(keys) > maxUsageEventKeys {
+		return keys[:maxUsageEventKeys]
+	}
+	return keys
+}
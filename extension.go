@@ -0,0 +1,114 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// The keys of the well-known typed fields in the extended details of the exposures, the downstream schemas can read
+// them as columns instead of parsing the expanded data. They take precedence over WithExpandedData of the same keys
+const (
+	ExtraDataKeyAppVersion = "app_version"
+	ExtraDataKeyPlatform   = "platform"
+	ExtraDataKeyRegion     = "region"
+	ExtraDataKeySessionID  = "session_id"
+)
+
+// The well-known platforms, see WithPlatform
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+	PlatformServer  = "server"
+)
+
+// extensionValuePattern The values of the typed fields, without the separators of the expanded data
+var extensionValuePattern = regexp.MustCompile(`^[^;=]{1,128}$`)
+
+// extensionFields The well-known typed fields of the extended details, empty means not set
+type extensionFields struct {
+	appVersion string
+	platform   string
+	region     string
+	sessionID  string
+}
+
+// isEmpty Whether none of the fields is set
+func (e *extensionFields) isEmpty() bool {
+	return len(e.appVersion) == 0 && len(e.platform) == 0 && len(e.region) == 0 && len(e.sessionID) == 0
+}
+
+// each Call f with the key and the value of each field set, in the order of the key
+func (e *extensionFields) each(f func(key string, value string)) {
+	if len(e.appVersion) > 0 {
+		f(ExtraDataKeyAppVersion, e.appVersion)
+	}
+	if len(e.platform) > 0 {
+		f(ExtraDataKeyPlatform, e.platform)
+	}
+	if len(e.region) > 0 {
+		f(ExtraDataKeyRegion, e.region)
+	}
+	if len(e.sessionID) > 0 {
+		f(ExtraDataKeySessionID, e.sessionID)
+	}
+}
+
+// value The value of the field of the key, false if the key is not a field or not set
+func (e *extensionFields) value(key string) (string, bool) {
+	var value string
+	switch key {
+	case ExtraDataKeyAppVersion:
+		value = e.appVersion
+	case ExtraDataKeyPlatform:
+		value = e.platform
+	case ExtraDataKeyRegion:
+		value = e.region
+	case ExtraDataKeySessionID:
+		value = e.sessionID
+	}
+	return value, len(value) > 0
+}
+
+// setExtension Validate and set the field, the values containing the separators ; and = are illegal
+func setExtension(c *userContext, key string, value string, field *string) {
+	if !extensionValuePattern.MatchString(value) {
+		c.err = fmt.Errorf("invalid %s [%s]", key, value)
+		return
+	}
+	*field = value
+}
+
+// WithAppVersion Set the version of the app of the user, such as 8.1.0, reported as app_version in the extended
+// details of the exposures
+func WithAppVersion(appVersion string) Attribution {
+	return func(c *userContext) {
+		setExtension(c, ExtraDataKeyAppVersion, appVersion, &c.extension.appVersion)
+	}
+}
+
+// WithPlatform Set the platform of the user, such as PlatformIOS, reported as platform in the extended details of
+// the exposures
+func WithPlatform(platform string) Attribution {
+	return func(c *userContext) {
+		setExtension(c, ExtraDataKeyPlatform, platform, &c.extension.platform)
+	}
+}
+
+// WithRegion Set the region serving the user, such as ap-southeast-1, reported as region in the extended details of
+// the exposures
+func WithRegion(region string) Attribution {
+	return func(c *userContext) {
+		setExtension(c, ExtraDataKeyRegion, region, &c.extension.region)
+	}
+}
+
+// WithSessionID Set the session of the user, reported as session_id in the extended details of the exposures
+func WithSessionID(sessionID string) Attribution {
+	return func(c *userContext) {
+		setExtension(c, ExtraDataKeySessionID, sessionID, &c.extension.sessionID)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensionFields(t *testing.T) {
+	userCtx := NewUserContext("12345", WithNewUnitID("user1"), WithAppVersion("8.1.0"),
+		WithPlatform(PlatformIOS), WithExpandedData(map[string]string{"k": "v", ExtraDataKeyRegion: "old"}),
+		WithRegion("ap-southeast-1"), WithSessionID("s1")).(*userContext)
+	assert.Nil(t, userCtx.err)
+	assert.Equal(t, map[string]string{newIDKey: "user1", "k": "v", ExtraDataKeyAppVersion: "8.1.0",
+		ExtraDataKeyPlatform: "ios", ExtraDataKeyRegion: "ap-southeast-1", ExtraDataKeySessionID: "s1"},
+		extraDataFromUserCtx(userCtx))
+	assert.Equal(t, "app_version=8.1.0;k=v;new_id=user1;platform=ios;region=ap-southeast-1;session_id=s1",
+		marshalExpandedData(projectID, userCtx))
+
+	userCtx = NewUserContext("12345", WithPlatform(PlatformWeb)).(*userContext)
+	assert.Equal(t, map[string]string{newIDKey: "12345", ExtraDataKeyPlatform: "web"}, extraDataFromUserCtx(userCtx))
+	assert.Equal(t, "new_id=12345;platform=web", marshalExpandedData(projectID, userCtx))
+	assert.NotContains(t, extraDataFromUserCtx(NewUserContext("12345").(*userContext)), ExtraDataKeyPlatform)
+
+	assert.NotNil(t, NewUserContext("12345", WithAppVersion("")).(*userContext).err)
+	assert.NotNil(t, NewUserContext("12345", WithSessionID("a=b;c=d")).(*userContext).err)
+}
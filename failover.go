@@ -0,0 +1,100 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// FailoverEvent A change of the primary endpoint, see WithConfigEndpoints
+type FailoverEvent = internal.FailoverEvent
+
+// EndpointStatus The health and latency of an endpoint, see GetEndpointStatus
+type EndpointStatus = internal.EndpointStatus
+
+// WithConfigEndpoints set the regional endpoints of the cache service instead of the address of the environment,
+// such as https://cache-us.example.com and https://cache-eu.example.com. The endpoints are probed periodically and
+// the requests are sent to the healthy endpoint of the lowest latency, failing over to the others on the connection
+// errors and the 5xx responses. Each failover is passed to WithOnWarning with ReasonEndpointFailover and reported as
+// a failover monitor event. Only the default cache client is routed, not the one of WithRegisterCacheClient
+func WithConfigEndpoints(endpoints ...string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		group, err := internal.NewEndpointGroup("config", endpoints)
+		if err != nil {
+			return errors.Wrap(err, "config endpoints")
+		}
+		config.ConfigEndpoints = group
+		return nil
+	}
+}
+
+// WithEventEndpoints set the interchangeable endpoints of the event reporting sent through EventTransport, the
+// requests to the host of any of them are routed to the healthy endpoint of the lowest latency with the failover,
+// see WithConfigEndpoints. The requests to the other hosts are sent as is
+func WithEventEndpoints(endpoints ...string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		group, err := internal.NewEndpointGroup("event", endpoints)
+		if err != nil {
+			return errors.Wrap(err, "event endpoints")
+		}
+		config.EventEndpoints = group
+		return nil
+	}
+}
+
+// WithEndpointProbeInterval set the interval of the health probes of the endpoints of WithConfigEndpoints and
+// WithEventEndpoints, 30 seconds by default
+func WithEndpointProbeInterval(interval time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if interval <= 0 {
+			return errors.Errorf("interval must be positive")
+		}
+		config.EndpointProbeInterval = interval
+		return nil
+	}
+}
+
+// GetEndpointStatus Get the status of the endpoints of WithConfigEndpoints and WithEventEndpoints, key is config or
+// event, nil if neither is set
+func GetEndpointStatus() map[string][]*EndpointStatus {
+	var result map[string][]*EndpointStatus
+	for _, group := range []*internal.EndpointGroup{internal.C.ConfigEndpoints, internal.C.EventEndpoints} {
+		if group == nil {
+			continue
+		}
+		if result == nil {
+			result = make(map[string][]*EndpointStatus, 2)
+		}
+		result[group.Name()] = group.Status()
+	}
+	return result
+}
+
+// initEndpointGroups Report the failovers of the endpoint groups and start probing them
+func initEndpointGroups(c *internal.GlobalConfig) {
+	if c.ConfigEndpoints == nil && c.EventEndpoints == nil {
+		return
+	}
+	for _, group := range []*internal.EndpointGroup{c.ConfigEndpoints, c.EventEndpoints} {
+		if group != nil {
+			group.OnFailover = onFailover
+		}
+	}
+	if c.EventEndpoints != nil {
+		c.EventEndpoints.HostRoundTripper(baseEventTransport()) // the transport of the probes before any request
+	}
+	internal.StartEndpointProber()
+}
+
+// onFailover Pass the failover to OnWarning and the monitor pipeline
+func onFailover(event *FailoverEvent) {
+	if internal.IsWarningHandled() {
+		internal.ReportWarning(internal.ReasonEndpointFailover, "", errors.Errorf("%s endpoint failover from %s to %s: %s",
+			event.Group, event.From, event.To, event.Reason))
+	}
+	asyncFailover(event)
+}
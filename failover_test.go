@@ -0,0 +1,52 @@
+// Package abc ...
+package abc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventEndpoints(t *testing.T) {
+	defer func(c *internal.GlobalConfig) {
+		internal.C = c
+	}(internal.C)
+	down := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("up"))
+	}))
+	defer up.Close()
+	config := &internal.GlobalConfig{}
+	assert.NotNil(t, WithConfigEndpoints()(config))
+	assert.NotNil(t, WithEventEndpoints("events")(config))
+	assert.NotNil(t, WithEndpointProbeInterval(0)(config))
+	assert.Nil(t, WithEventEndpoints(down.URL, up.URL)(config))
+	var warnings []string
+	assert.Nil(t, WithOnWarning(func(reason ErrorReason, projectID string, err error) {
+		warnings = append(warnings, string(reason)+":"+err.Error())
+	})(config))
+	internal.C = config
+	assert.Nil(t, GetEndpointStatus()["config"])
+	initEndpointGroups(config)
+
+	client := &http.Client{Transport: EventTransport()}
+	resp, err := client.Get(down.URL + "/collect")
+	if assert.Nil(t, err) {
+		body, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, "up", string(body))
+	}
+	status := GetEndpointStatus()["event"]
+	assert.Len(t, status, 2)
+	assert.True(t, status[1].Primary)
+	assert.Len(t, warnings, 1)
+	assert.True(t, strings.HasPrefix(warnings[0], string(ReasonEndpointFailover)+":event endpoint failover"))
+}
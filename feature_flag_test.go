@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/abetterchoice/go-sdk/internal/cache"
 	"github.com/abetterchoice/go-sdk/testdata"
 	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
 	"github.com/stretchr/testify/assert"
@@ -98,3 +99,44 @@ func Test_userContext_GetFeatureFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestFeatureFlagCache(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	remoteConfigCache := cache.GetApplication(projectID).RemoteConfigCache
+	assert.NotNil(t, remoteConfigCache)
+	assert.Equal(t, []string{"tagKey1"}, remoteConfigCache.AttributeKeys["withTag"])
+	assert.Equal(t, []string{}, remoteConfigCache.AttributeKeys["remoteConfig1"])
+	assert.NotContains(t, remoteConfigCache.AttributeKeys, "withExperiment")
+	assert.NotContains(t, remoteConfigCache.AttributeKeys, "bitmapTest")
+
+	ctx := context.Background()
+	for _, unitID := range []string{"u1", "u2"} {
+		flag, err := NewUserContext(unitID, WithTagKV("tagKey1", "ios")).GetFeatureFlag(ctx, projectID, "withTag")
+		assert.Nil(t, err)
+		assert.Equal(t, "withTag-condition1", flag.String())
+	}
+	fingerprint, ok := remoteConfigCache.Fingerprint("withTag", map[string][]string{"tagKey1": {"ios"}})
+	assert.True(t, ok)
+	_, ok = remoteConfigCache.Load(fingerprint)
+	assert.True(t, ok)
+	flag, err := NewUserContext("u1", WithTagKV("tagKey1", "android")).GetFeatureFlag(ctx, projectID, "withTag")
+	assert.Nil(t, err)
+	assert.Equal(t, "withTagDefaultValue", flag.String())
+	// the override list is not cached
+	flag, err = NewUserContext("overrideUnitID", WithTagKV("tagKey1", "ios")).GetFeatureFlag(ctx, projectID, "withTag")
+	assert.Nil(t, err)
+	assert.Equal(t, "hitOverrideResult", flag.String())
+	flag, err = NewUserContext("u3", WithTagKV("tagKey1", "ios")).GetFeatureFlag(ctx, projectID, "withTag")
+	assert.Nil(t, err)
+	assert.Equal(t, "withTag-condition1", flag.String())
+
+	Release()
+	err = Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithRemoteConfigCacheSize(-1))
+	assert.Nil(t, err)
+	assert.Nil(t, cache.GetApplication(projectID).RemoteConfigCache)
+}
@@ -0,0 +1,48 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"strconv"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+// FreezeAssignments Pin the cached configuration of the projectID for the incident response, the refreshes are
+// skipped until UnfreezeAssignments, so that the assignments stay stable while investigating. The experiments,
+// the remote configs and the feature flags keep being evaluated against the pinned configuration.
+// Freezing a frozen projectID has no effect, the freeze does not survive Release. Concurrent and safe
+func FreezeAssignments(projectID string) error {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return internal.UnknownProjectError(projectID)
+	}
+	if cache.Freeze(projectID) {
+		log.Warnf("[projectID=%v]assignments frozen, revision=%v", projectID, application.Revision)
+		internal.RecordAudit(1, internal.AuditActionFreeze, projectID, strconv.FormatBool(false),
+			strconv.FormatBool(true))
+	}
+	return nil
+}
+
+// UnfreezeAssignments Resume the refreshes of the projectID frozen by FreezeAssignments, the latest configuration is
+// fetched on the next round of the refresh. Unfreezing a projectID not frozen has no effect
+func UnfreezeAssignments(projectID string) error {
+	if cache.GetApplication(projectID) == nil {
+		return internal.UnknownProjectError(projectID)
+	}
+	if cache.Unfreeze(projectID) {
+		log.Infof("[projectID=%v]assignments unfrozen", projectID)
+		internal.RecordAudit(1, internal.AuditActionFreeze, projectID, strconv.FormatBool(true),
+			strconv.FormatBool(false))
+	}
+	return nil
+}
+
+// IsAssignmentsFrozen Whether the cached configuration of the projectID is pinned by FreezeAssignments
+func IsAssignmentsFrozen(projectID string) bool {
+	return cache.IsFrozen(projectID)
+}
@@ -0,0 +1,50 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreezeAssignments(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(FreezeAssignments("unknown"), ErrProjectUnknown))
+	assert.True(t, errors.Is(UnfreezeAssignments("unknown"), ErrProjectUnknown))
+	before, err := GetConfigRevision(projectID)
+	assert.Nil(t, err)
+	assert.Nil(t, FreezeAssignments(projectID))
+	assert.Nil(t, FreezeAssignments(projectID))
+	assert.True(t, IsAssignmentsFrozen(projectID))
+	application := cache.GetApplication(projectID)
+	_, err = cache.NewAndSetApplication(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.Same(t, application, cache.GetApplication(projectID))
+	after, err := GetConfigRevision(projectID)
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, list.Data)
+
+	assert.Nil(t, UnfreezeAssignments(projectID))
+	assert.False(t, IsAssignmentsFrozen(projectID))
+	var actions []string
+	for _, record := range AuditLog() {
+		if record.Action == AuditActionFreeze && record.ProjectID == projectID {
+			actions = append(actions, record.Before+"->"+record.After)
+		}
+	}
+	assert.Equal(t, []string{"false->true", "true->false"}, actions[len(actions)-2:])
+	_, err = cache.NewAndSetApplication(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.NotSame(t, application, cache.GetApplication(projectID))
+}
@@ -18,6 +18,10 @@ type ExperimentList struct {
 	// The experimental group hit by unitID in each layer, the key is layerKey,
 	// and the value is the experimental group hit under the layer.
 	Data map[string]*Group
+	// Whether decoded from the baggage, the exposures have been logged by the upstream service
+	isPropagated bool
+	// The values extracted from the ctx the exposures are produced under, see WithContextExtractor
+	contextData map[string]string
 }
 
 // ExperimentResult Experimental offloading results,
@@ -64,6 +68,16 @@ type Group struct {
 	holdoutData map[string]*Group
 }
 
+// NewGroup Create a group of the layer with the parameters outside of the evaluation,
+// such as the assignments programmed in the test doubles, see package abctest. The params are copied
+func NewGroup(layerKey string, key string, params map[string]string) *Group {
+	group := &Group{Key: key, LayerKey: layerKey, params: make(map[string]string, len(params))}
+	for k, v := range params {
+		group.params[k] = v
+	}
+	return group
+}
+
 // SceneIDList Get scene ID list, deep copy
 func (g *Group) SceneIDList() []int64 {
 	if len(g.sceneIDList) == 0 {
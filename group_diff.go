@@ -0,0 +1,163 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// ParamChangeType The kind of the change of a parameter between two groups
+type ParamChangeType string
+
+const (
+	// ParamAdded The parameter is only in the second group
+	ParamAdded ParamChangeType = "added"
+	// ParamRemoved The parameter is only in the first group
+	ParamRemoved ParamChangeType = "removed"
+	// ParamModified The parameter is in both groups with different values
+	ParamModified ParamChangeType = "modified"
+)
+
+// ParamChange A change of a parameter between two groups. The fields of the JSON object parameters are compared one
+// by one, the key of which is the path joined by ".", such as "layout.columns", and the values are JSON encoded
+type ParamChange struct {
+	Key  string          `json:"key"`
+	Type ParamChangeType `json:"type"`
+	// The value in the first group, empty if added
+	Before string `json:"before,omitempty"`
+	// The value in the second group, empty if removed
+	After string `json:"after,omitempty"`
+}
+
+// GroupDiff The differences of the parameters of two groups of a layer, see CompareGroups
+type GroupDiff struct {
+	LayerKey string `json:"layerKey"`
+	GroupA   string `json:"groupA"`
+	GroupB   string `json:"groupB"`
+	// The revision of the configuration the groups are read from, see GetConfigRevision
+	Revision string `json:"revision"`
+	// The changes from GroupA to GroupB in the order of the key, empty if the parameters are the same
+	Changes []*ParamChange `json:"changes"`
+}
+
+// CompareGroups Compare the parameters of the groups of the layer in the locally cached configuration, such as the
+// control and a treatment, so that the release tooling can display exactly what the treatment changes. The groups
+// are the group keys, the default group of the layer is matched by its key as well
+func CompareGroups(projectID string, layerKey string, groupA string, groupB string) (*GroupDiff, error) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	layer, ok := application.LayerIndex[layerKey]
+	if !ok {
+		layer, ok = application.FullFlowLayerIndex[layerKey]
+	}
+	if !ok || layer == nil {
+		return nil, errors.Wrapf(ErrLayerNotFound, "layerKey [%s]", layerKey)
+	}
+	a, err := layerGroup(layer, groupA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := layerGroup(layer, groupB)
+	if err != nil {
+		return nil, err
+	}
+	diff := &GroupDiff{LayerKey: layerKey, GroupA: groupA, GroupB: groupB, Revision: application.Revision,
+		Changes: []*ParamChange{}}
+	for key, before := range a.Params {
+		after, ok := b.Params[key]
+		if !ok {
+			diff.Changes = append(diff.Changes, &ParamChange{Key: key, Type: ParamRemoved, Before: before})
+		} else if before != after {
+			diff.Changes = append(diff.Changes, compareParam(key, before, after)...)
+		}
+	}
+	for key, after := range b.Params {
+		if _, ok := a.Params[key]; !ok {
+			diff.Changes = append(diff.Changes, &ParamChange{Key: key, Type: ParamAdded, After: after})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].Key < diff.Changes[j].Key
+	})
+	return diff, nil
+}
+
+// layerGroup The group of the layer of the key, including the default group of the layer
+func layerGroup(layer *protoccacheserver.Layer, groupKey string) (*protoccacheserver.Group, error) {
+	if layer.Metadata != nil && layer.Metadata.DefaultGroup != nil &&
+		layer.Metadata.DefaultGroup.GroupKey == groupKey {
+		return layer.Metadata.DefaultGroup, nil
+	}
+	var result *protoccacheserver.Group
+	for _, group := range layer.GroupIndex {
+		if group == nil || group.GroupKey != groupKey {
+			continue
+		}
+		if result == nil || group.Id < result.Id { // stable if the key is reused
+			result = group
+		}
+	}
+	if result == nil {
+		return nil, errors.Errorf("group [%s] not found in the layer", groupKey)
+	}
+	return result, nil
+}
+
+// compareParam The changes of the parameter of different values, the fields of the JSON objects are compared
+// one by one, the other values are changed as a whole
+func compareParam(key string, before string, after string) []*ParamChange {
+	var beforeObject, afterObject map[string]interface{}
+	if json.Unmarshal([]byte(before), &beforeObject) != nil || json.Unmarshal([]byte(after), &afterObject) != nil ||
+		beforeObject == nil || afterObject == nil {
+		return []*ParamChange{{Key: key, Type: ParamModified, Before: before, After: after}}
+	}
+	var changes []*ParamChange
+	compareObject(key, beforeObject, afterObject, &changes)
+	if len(changes) == 0 { // the same object of a different encoding, such as the order of the fields
+		return nil
+	}
+	return changes
+}
+
+func compareObject(prefix string, before map[string]interface{}, after map[string]interface{},
+	changes *[]*ParamChange) {
+	for field, beforeValue := range before {
+		key := prefix + "." + field
+		afterValue, ok := after[field]
+		if !ok {
+			*changes = append(*changes, &ParamChange{Key: key, Type: ParamRemoved, Before: encodeJSON(beforeValue)})
+			continue
+		}
+		beforeObject, isBeforeObject := beforeValue.(map[string]interface{})
+		afterObject, isAfterObject := afterValue.(map[string]interface{})
+		if isBeforeObject && isAfterObject {
+			compareObject(key, beforeObject, afterObject, changes)
+			continue
+		}
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			*changes = append(*changes, &ParamChange{Key: key, Type: ParamModified, Before: encodeJSON(beforeValue),
+				After: encodeJSON(afterValue)})
+		}
+	}
+	for field, afterValue := range after {
+		if _, ok := before[field]; !ok {
+			*changes = append(*changes, &ParamChange{Key: prefix + "." + field, Type: ParamAdded,
+				After: encodeJSON(afterValue)})
+		}
+	}
+}
+
+func encodeJSON(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
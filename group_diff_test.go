@@ -0,0 +1,56 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareGroups(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	diff, err := CompareGroups(projectID, "overrideLayer", "100002001", "100002002")
+	assert.Nil(t, err)
+	assert.Equal(t, []*ParamChange{{Key: "key1", Type: ParamModified, Before: "100002001", After: "100002002"}},
+		diff.Changes)
+	assert.NotEmpty(t, diff.Revision)
+	// the default group of the layer
+	diff, err = CompareGroups(projectID, "overrideLayer", "100001001", "100001001")
+	assert.Nil(t, err)
+	assert.Empty(t, diff.Changes)
+	_, err = CompareGroups(projectID, "overrideLayer", "100002001", "notExist")
+	assert.NotNil(t, err)
+	_, err = CompareGroups(projectID, "notExist", "100002001", "100002002")
+	assert.True(t, errors.Is(err, ErrLayerNotFound))
+	_, err = CompareGroups("notExist", "overrideLayer", "100002001", "100002002")
+	assert.NotNil(t, err)
+}
+
+func TestCompareParam(t *testing.T) {
+	changes := compareParam("layout", `{"columns":2,"theme":{"color":"red"},"size":"s"}`,
+		`{"theme":{"color":"blue"},"columns":2,"badge":true}`)
+	for _, change := range changes {
+		switch change.Key {
+		case "layout.theme.color":
+			assert.Equal(t, ParamChange{Key: change.Key, Type: ParamModified, Before: `"red"`, After: `"blue"`}, *change)
+		case "layout.size":
+			assert.Equal(t, ParamRemoved, change.Type)
+		case "layout.badge":
+			assert.Equal(t, ParamAdded, change.Type)
+			assert.Equal(t, "true", change.After)
+		default:
+			t.Errorf("unexpected change %s", change.Key)
+		}
+	}
+	assert.Equal(t, 3, len(changes))
+	assert.Empty(t, compareParam("layout", `{"a":1,"b":2}`, `{"b":2, "a":1}`))
+	assert.Equal(t, []*ParamChange{{Key: "list", Type: ParamModified, Before: "[1]", After: "[2]"}},
+		compareParam("list", "[1]", "[2]"))
+}
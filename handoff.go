@@ -0,0 +1,211 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+// handoffVersion The version of the handoff file, bumped on incompatible changes
+const handoffVersion = 1
+
+// handoffFile The state written by HandoffState for the next process, see WithStateHandoff
+type handoffFile struct {
+	Version int `json:"version"`
+	// The snapshot of each projectID encoded by client.MarshalSnapshot
+	Snapshots []json.RawMessage `json:"snapshots,omitempty"`
+	// The exposures pending in the queues, reported by the next process
+	Spool []*spooledExposure `json:"spool,omitempty"`
+	// The aliases of the in-memory AliasStore, the pairs of the userID and the anonymousID, the oldest first
+	Aliases [][]string `json:"aliases,omitempty"`
+}
+
+var (
+	handoffLock sync.Mutex
+	// handoffRecorder The recorder of the configuration to be handed off, nil if the handoff is disabled
+	handoffRecorder *client.RecordingClient
+	// restoredSpool The exposures handed off by the previous process, reported once the projects are loaded
+	restoredSpool []*spooledExposure
+	// restoredPath The handoff file read by Init, removed once the exposures are taken by takeRestoredSpool
+	restoredPath string
+)
+
+// WithStateHandoff hand off the state of the SDK across the restarts of the binary through the file at path, such as
+// on a volume kept by the rolling restarts. HandoffState, called on SIGTERM before Release, writes the configuration
+// of the projects, the exposures still pending in the queues and the aliases of the in-memory AliasStore to the file.
+// Init reads the file and removes it once the projects are loaded, the exposures are then reported, so that none is
+// lost or logged twice by the restart, and the configuration is served if the cache service cannot be reached at
+// startup, so that the units keep the groups of the previous process instead of falling back to the defaults. The
+// warning of the fallback has ReasonHandoffFallback. The aliases of Alias are the only assignments kept per unit,
+// the others are computed from the configuration. A store of WithAliasStore other than NewMemoryAliasStore is not
+// handed off, it must persist across the restarts by itself. The file is written with the mode 0600, encrypted under
+// WithEncryptionKey if set since it contains the user identifiers and the tokens
+func WithStateHandoff(path string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if len(path) == 0 {
+			return errors.Errorf("path is required")
+		}
+		config.StateHandoffPath = path
+		return nil
+	}
+}
+
+// HandoffState Write the state for the next process to the file of WithStateHandoff, then call Release, such as
+//
+//	<-ctx.Done() // signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	err := abc.HandoffState(context.Background())
+//	abc.Release()
+//
+// The exposures pending in the queues are taken into the file instead of being reported, the ones logged meanwhile
+// are reported as usual. The file is replaced atomically, a failed handoff leaves the previous file
+func HandoffState(ctx context.Context) error {
+	path := internal.C.StateHandoffPath
+	recorder := currentHandoffRecorder()
+	if len(path) == 0 || recorder == nil {
+		return errors.Errorf("state handoff is not enabled, see WithStateHandoff")
+	}
+	state := &handoffFile{Version: handoffVersion}
+	for _, projectID := range internal.C.ProjectIDList {
+		snapshot := recorder.Snapshot(projectID)
+		if snapshot == nil { // not loaded
+			continue
+		}
+		data, err := client.MarshalSnapshot(snapshot)
+		if err != nil {
+			return errors.Wrapf(err, "snapshot [%s]", projectID)
+		}
+		state.Snapshots = append(state.Snapshots, data)
+	}
+	state.Spool = spoolPendingExposures(ctx)
+	if store, ok := aliasStore().(*memoryAliasStore); ok {
+		state.Aliases = store.aliasList()
+	}
+	return writeHandoff(path, internal.C.Cipher, state)
+}
+
+// registerHandoffClient Restore the handoff file of WithStateHandoff and wrap the registered cache client with the
+// recorder of the configuration and the handed off snapshots
+func registerHandoffClient(c *internal.GlobalConfig) {
+	if len(c.StateHandoffPath) == 0 {
+		return
+	}
+	state, err := readHandoff(c.StateHandoffPath, c.Cipher)
+	if err != nil {
+		log.Warnf("restore state handoff %s:%v", c.StateHandoffPath, err)
+		state = &handoffFile{}
+	}
+	snapshots := make([]*client.Snapshot, 0, len(state.Snapshots))
+	for _, data := range state.Snapshots {
+		snapshot, err := client.UnmarshalSnapshot(data)
+		if err != nil {
+			log.Warnf("restore state handoff %s:%v", c.StateHandoffPath, err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if store, ok := aliasStore().(*memoryAliasStore); ok {
+		store.restoreAliases(state.Aliases)
+	}
+	cacheClient := client.GetCacheClient()
+	if len(snapshots) > 0 {
+		cacheClient = client.NewHandoffClient(cacheClient, func(projectID string) bool {
+			return cache.GetApplication(projectID) != nil
+		}, func(projectID string, err error) {
+			log.Warnf("[projectID=%v]cache service failed, serve the handed off snapshot:%v", projectID, err)
+			internal.ReportWarning(internal.ReasonHandoffFallback, projectID,
+				errors.Wrap(err, "serve the handed off snapshot"))
+		}, snapshots...)
+	}
+	recorder := client.NewRecordingClient(cacheClient)
+	client.RegisterCacheClient(recorder)
+	handoffLock.Lock()
+	defer handoffLock.Unlock()
+	handoffRecorder = recorder
+	restoredSpool = state.Spool
+	restoredPath = c.StateHandoffPath
+}
+
+func currentHandoffRecorder() *client.RecordingClient {
+	handoffLock.Lock()
+	defer handoffLock.Unlock()
+	return handoffRecorder
+}
+
+// takeRestoredSpool Take the exposures handed off by the previous process and remove the handoff file, only once, so
+// that the exposures are reported by a single process. The file is kept if Init fails before
+func takeRestoredSpool() []*spooledExposure {
+	handoffLock.Lock()
+	spool, path := restoredSpool, restoredPath
+	restoredSpool, restoredPath = nil, ""
+	handoffLock.Unlock()
+	if len(path) > 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Warnf("remove state handoff %s:%v", path, err)
+		}
+	}
+	return spool
+}
+
+// resetHandoff Called by Release
+func resetHandoff() {
+	handoffLock.Lock()
+	defer handoffLock.Unlock()
+	handoffRecorder = nil
+	restoredSpool = nil
+	restoredPath = ""
+}
+
+// readHandoff Read the handoff file, an empty state if there is no file
+func readHandoff(path string, c *internal.Cipher) (*handoffFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &handoffFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		if data, err = c.Decrypt(data); err != nil {
+			return nil, err
+		}
+	} else if internal.IsEncrypted(data) {
+		return nil, errors.Errorf("state handoff is encrypted, WithEncryptionKey is required")
+	}
+	state := &handoffFile{}
+	if err = json.Unmarshal(data, state); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	if state.Version != handoffVersion {
+		return nil, errors.Errorf("unsupported state handoff version %d", state.Version)
+	}
+	return state, nil
+}
+
+// writeHandoff Write the handoff file, to a temporary file then renamed so that a crash never leaves a truncated file
+func writeHandoff(path string, c *internal.Cipher, state *handoffFile) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	if c != nil {
+		if data, err = c.Encrypt(data); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,128 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateHandoff(t *testing.T) {
+	Release()
+	defer Release()
+	assert.NotNil(t, WithStateHandoff("")(&internal.GlobalConfig{}))
+	assert.NotNil(t, HandoffState(context.Background())) // not enabled
+	recorder := &recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true}
+	metrics.RegisterClient(recorder)
+	defer metrics.RegisterClient(&recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true})
+	shards := exposureShards
+	defer func() {
+		exposureShards = shards
+	}()
+	key := []byte("0123456789abcdef")
+	path := filepath.Join(t.TempDir(), "handoff")
+	evaluate := func() map[string]map[string]int64 {
+		results := make(map[string]map[string]int64)
+		for i := 0; i < 20; i++ {
+			unitID := strconv.Itoa(i)
+			list, err := NewUserContext(unitID).GetExperiments(context.Background(), projectID, WithAutomatic(false))
+			assert.Nil(t, err)
+			results[unitID] = make(map[string]int64)
+			for layerKey, group := range list.Data {
+				results[unitID][layerKey] = group.ID
+			}
+			delete(results[unitID], "doubleHashLayerCityTag") // evaluated in the random order of its groups
+		}
+		return results
+	}
+
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithStateHandoff(path), WithEncryptionKey(key))
+	assert.Nil(t, err)
+	expected := evaluate()
+	exposureShards = newExposureShards(1) // not drained by the consumers
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	exposureShards[0].experimentExposureChan <- &experimentExposure{projectID: projectID, list: list,
+		et: protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL}
+	assert.Nil(t, Alias(context.Background(), "anonymous", "user"))
+	assert.Nil(t, HandoffState(context.Background()))
+	assert.Equal(t, 0, len(exposureShards[0].experimentExposureChan))
+	recorder.lock.Lock()
+	assert.Empty(t, recorder.exposures) // handed off instead of reported
+	recorder.lock.Unlock()
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.True(t, IsEncrypted(data))
+	Release()
+	exposureShards = shards
+
+	// the file is kept by a failed Init
+	err = Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithStateHandoff(path), WithEncryptionKey(key),
+		WithRegisterMetricsPlugin(&failInitMetricsClient{}, &protoc_cache_server.MetricsInitConfig{}))
+	assert.NotNil(t, err)
+	Release()
+	metrics.RegisterClient(recorder)
+	_, err = os.Stat(path)
+	assert.Nil(t, err)
+
+	// the cache service is down at the restart
+	down := client.NewMockClient(gomock.NewController(t))
+	down.EXPECT().GetTabConfigData(gomock.Any(), gomock.Any()).Return(nil, errors.New("down")).AnyTimes()
+	var warnings []ErrorReason
+	err = Init(context.Background(), projectIDList, WithRegisterCacheClient(down),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithStateHandoff(path), WithEncryptionKey(key),
+		WithOnWarning(func(reason ErrorReason, projectID string, err error) {
+			warnings = append(warnings, reason)
+		}))
+	assert.Nil(t, err)
+	assert.Equal(t, []ErrorReason{ReasonHandoffFallback}, warnings)
+	assert.Equal(t, expected, evaluate()) // the same groups as the previous process
+	anonymousID, err := aliasStore().GetAlias(context.Background(), "user")
+	assert.Nil(t, err)
+	assert.Equal(t, "anonymous", anonymousID)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err)) // restored once
+	assert.Eventually(t, func() bool {
+		recorder.lock.Lock()
+		defer recorder.lock.Unlock()
+		for _, exposure := range recorder.exposures {
+			if exposure.UnitId == "12345" {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	Release()
+
+	// no handoff file, the cache service is required again
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithRegisterCacheClient(down),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithStateHandoff(path)))
+}
+
+// failInitMetricsClient Fail the initialization of the default experiment metrics plugin
+type failInitMetricsClient struct {
+	recordMetricsClient
+}
+
+func (f *failInitMetricsClient) Init(ctx context.Context, config *protoc_cache_server.MetricsInitConfig) error {
+	return errors.New("init fail")
+}
@@ -0,0 +1,232 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// The common types of the raw identifiers
+const (
+	IdentifierTypeCookie   = "cookie"
+	IdentifierTypeDeviceID = "device_id"
+	IdentifierTypeUserID   = "user_id"
+)
+
+// Identifier A raw identifier of the request, such as the cookie or the device ID
+type Identifier struct {
+	Type  string
+	Value string
+}
+
+// Identity The canonical identity resolved from the raw identifiers
+type Identity struct {
+	// Required, see NewUserContext
+	UnitID string
+	// Optional, see WithNewUnitID
+	NewUnitID string
+}
+
+// IdentityResolver Map the raw identifiers to the canonical identity before the evaluation, such as through an
+// ID-mapping service. It is called by UserContextBuilder with the timeout and the results are cached
+type IdentityResolver interface {
+	Resolve(ctx context.Context, identifiers []Identifier) (*Identity, error)
+}
+
+// IdentityResolverFunc Adapt the function to IdentityResolver
+type IdentityResolverFunc func(ctx context.Context, identifiers []Identifier) (*Identity, error)
+
+// Resolve Implement IdentityResolver
+func (f IdentityResolverFunc) Resolve(ctx context.Context, identifiers []Identifier) (*Identity, error) {
+	return f(ctx, identifiers)
+}
+
+const (
+	defaultIdentityTimeout   = 100 * time.Millisecond
+	defaultIdentityCacheTTL  = 10 * time.Minute
+	defaultIdentityCacheSize = 10000
+)
+
+// BuilderOption NewUserContextBuilder related options
+type BuilderOption func(b *UserContextBuilder)
+
+// WithIdentityTimeout The timeout of a single resolution, 100ms by default
+func WithIdentityTimeout(timeout time.Duration) BuilderOption {
+	return func(b *UserContextBuilder) {
+		b.timeout = timeout
+	}
+}
+
+// WithIdentityCache The ttl and the maximum number of the cached resolutions, the least recently used ones are
+// evicted once full, 10 minutes and 10000 by default. A non-positive ttl or size disables the cache
+func WithIdentityCache(ttl time.Duration, size int) BuilderOption {
+	return func(b *UserContextBuilder) {
+		b.cacheTTL = ttl
+		b.cacheSize = size
+	}
+}
+
+// WithIdentityFallback Whether to fall back to the first non-empty raw identifier as the unitID when the resolution
+// fails, true by default. The fallback results are not cached
+func WithIdentityFallback(isFallback bool) BuilderOption {
+	return func(b *UserContextBuilder) {
+		b.isFallback = isFallback
+	}
+}
+
+// UserContextBuilder Build the user context from the raw identifiers of the request through the IdentityResolver.
+// Concurrent and safe, the concurrent resolutions of the same identifiers are merged into one
+type UserContextBuilder struct {
+	resolver   IdentityResolver
+	timeout    time.Duration
+	cacheTTL   time.Duration
+	cacheSize  int
+	isFallback bool
+
+	group singleflight.Group
+	lock  sync.Mutex
+	cache map[string]*list.Element
+	// front is the most recently used
+	lru *list.List
+}
+
+type identityEntry struct {
+	key      string
+	identity Identity
+	expireAt time.Time
+}
+
+// NewUserContextBuilder Create the builder of the resolver
+func NewUserContextBuilder(resolver IdentityResolver, opts ...BuilderOption) *UserContextBuilder {
+	b := &UserContextBuilder{
+		resolver:   resolver,
+		timeout:    defaultIdentityTimeout,
+		cacheTTL:   defaultIdentityCacheTTL,
+		cacheSize:  defaultIdentityCacheSize,
+		isFallback: true,
+		cache:      map[string]*list.Element{},
+		lru:        list.New(),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Build Resolve the identifiers and create the user context of the canonical identity, the attributions are
+// applied after the resolved ones, see NewUserContext
+func (b *UserContextBuilder) Build(ctx context.Context, identifiers []Identifier,
+	opts ...Attribution) (Context, error) {
+	identity, err := b.Resolve(ctx, identifiers)
+	if err != nil {
+		return nil, err
+	}
+	if len(identity.NewUnitID) == 0 {
+		return NewUserContext(identity.UnitID, opts...), nil
+	}
+	attributions := make([]Attribution, 0, len(opts)+1)
+	attributions = append(attributions, WithNewUnitID(identity.NewUnitID))
+	return NewUserContext(identity.UnitID, append(attributions, opts...)...), nil
+}
+
+// Resolve Resolve the identifiers through the cache and the resolver, see Build
+func (b *UserContextBuilder) Resolve(ctx context.Context, identifiers []Identifier) (*Identity, error) {
+	key := identifiersKey(identifiers)
+	if len(key) == 0 {
+		return nil, errors.Errorf("identifiers are required")
+	}
+	if identity, ok := b.load(key); ok {
+		return identity, nil
+	}
+	result, err, _ := b.group.Do(key, func() (interface{}, error) {
+		resolveCtx, cancel := context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+		identity, err := b.resolver.Resolve(resolveCtx, identifiers)
+		if err == nil && (identity == nil || len(identity.UnitID) == 0) {
+			err = errors.Errorf("unitID is not resolved")
+		}
+		if err != nil {
+			return nil, err
+		}
+		b.store(key, identity)
+		return identity, nil
+	})
+	if err == nil {
+		identity := *result.(*Identity) // copy, shared by the merged calls
+		return &identity, nil
+	}
+	if !b.isFallback {
+		return nil, errors.Wrap(err, "resolve")
+	}
+	for _, identifier := range identifiers {
+		if len(identifier.Value) > 0 {
+			log.LimitedErrorf("resolveIdentity", "resolve identity fail, fall back to the %s:%v", identifier.Type, err)
+			return &Identity{UnitID: identifier.Value}, nil
+		}
+	}
+	return nil, errors.Wrap(err, "resolve")
+}
+
+func (b *UserContextBuilder) load(key string) (*Identity, bool) {
+	if b.cacheTTL <= 0 || b.cacheSize <= 0 {
+		return nil, false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	element, ok := b.cache[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*identityEntry)
+	if time.Now().After(entry.expireAt) {
+		b.lru.Remove(element)
+		delete(b.cache, key)
+		return nil, false
+	}
+	b.lru.MoveToFront(element)
+	identity := entry.identity
+	return &identity, true
+}
+
+func (b *UserContextBuilder) store(key string, identity *Identity) {
+	if b.cacheTTL <= 0 || b.cacheSize <= 0 {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	entry := &identityEntry{key: key, identity: *identity, expireAt: time.Now().Add(b.cacheTTL)}
+	if element, ok := b.cache[key]; ok {
+		element.Value = entry
+		b.lru.MoveToFront(element)
+		return
+	}
+	b.cache[key] = b.lru.PushFront(entry)
+	for b.lru.Len() > b.cacheSize {
+		oldest := b.lru.Back()
+		b.lru.Remove(oldest)
+		delete(b.cache, oldest.Value.(*identityEntry).key)
+	}
+}
+
+// identifiersKey The cache key of the identifiers regardless of their order, empty if no identifier has a value
+func identifiersKey(identifiers []Identifier) string {
+	parts := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		if len(identifier.Value) == 0 {
+			continue
+		}
+		parts = append(parts, identifier.Type+"\x00"+identifier.Value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\x01")
+}
@@ -0,0 +1,71 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserContextBuilder(t *testing.T) {
+	var calls int32
+	resolver := IdentityResolverFunc(func(ctx context.Context, identifiers []Identifier) (*Identity, error) {
+		atomic.AddInt32(&calls, 1)
+		for _, identifier := range identifiers {
+			switch identifier.Type {
+			case IdentifierTypeCookie:
+				return &Identity{UnitID: "user-" + identifier.Value, NewUnitID: "new-" + identifier.Value}, nil
+			case IdentifierTypeDeviceID:
+				<-ctx.Done() // the mapping service is slow
+				return nil, ctx.Err()
+			}
+		}
+		return &Identity{}, nil
+	})
+	b := NewUserContextBuilder(resolver, WithIdentityTimeout(10*time.Millisecond), WithIdentityCache(time.Hour, 2))
+
+	userCtx, err := b.Build(context.Background(), []Identifier{{Type: IdentifierTypeCookie, Value: "c1"}},
+		WithTagKV("city", "sz"))
+	assert.Nil(t, err)
+	got := userCtx.(*userContext)
+	assert.Equal(t, "user-c1", got.unitID)
+	assert.Equal(t, "new-c1", got.newUnitID)
+	assert.Equal(t, []string{"sz"}, got.tags["city"])
+	_, err = b.Build(context.Background(), []Identifier{{Type: IdentifierTypeCookie, Value: "c1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls)) // cached
+
+	// evicted once full
+	for _, value := range []string{"c2", "c3"} {
+		_, err = b.Resolve(context.Background(), []Identifier{{Type: IdentifierTypeCookie, Value: value}})
+		assert.Nil(t, err)
+	}
+	_, err = b.Resolve(context.Background(), []Identifier{{Type: IdentifierTypeCookie, Value: "c1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// timeout, falls back to the raw identifier
+	identity, err := b.Resolve(context.Background(), []Identifier{{Type: IdentifierTypeDeviceID, Value: "d1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, &Identity{UnitID: "d1"}, identity)
+	// unresolved
+	identity, err = b.Resolve(context.Background(), []Identifier{{Type: IdentifierTypeUserID, Value: "u1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, &Identity{UnitID: "u1"}, identity)
+
+	b = NewUserContextBuilder(resolver, WithIdentityTimeout(10*time.Millisecond), WithIdentityFallback(false),
+		WithIdentityCache(0, 0))
+	_, err = b.Build(context.Background(), []Identifier{{Type: IdentifierTypeDeviceID, Value: "d1"}})
+	assert.NotNil(t, err)
+	_, err = b.Build(context.Background(), []Identifier{{Type: IdentifierTypeCookie}})
+	assert.NotNil(t, err)
+}
+
+func TestIdentifiersKey(t *testing.T) {
+	assert.Equal(t, identifiersKey([]Identifier{{Type: "a", Value: "1"}, {Type: "b", Value: "2"}}),
+		identifiersKey([]Identifier{{Type: "b", Value: "2"}, {Type: "a", Value: "1"}, {Type: "c"}}))
+	assert.Empty(t, identifiersKey(nil))
+}
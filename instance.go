@@ -0,0 +1,197 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval,
+// user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Client An SDK instance bound to a projectID, which can be injected into handler code through context,
+// so that the handler does not need to know the projectID and the default options.
+// The Client itself holds no cache, the SDK still needs to be initialized through Init, concurrent and safe
+type Client struct {
+	projectID string
+	// Attributions applied to every user context created by the client before the ones passed in
+	attributions []Attribution
+	// ExperimentOptions prepended to every experiment API call
+	experimentOptions []ExperimentOption
+}
+
+// ClientOption NewClient related options
+type ClientOption func(c *Client)
+
+// WithClientAttributions Default attributions of the user context created by the client, such as the common tags
+func WithClientAttributions(opts ...Attribution) ClientOption {
+	return func(c *Client) {
+		c.attributions = append(c.attributions, opts...)
+	}
+}
+
+// WithClientExperimentOptions Default options of the experiment API, the options passed in the call take precedence
+func WithClientExperimentOptions(opts ...ExperimentOption) ClientOption {
+	return func(c *Client) {
+		c.experimentOptions = append(c.experimentOptions, opts...)
+	}
+}
+
+// NewClient Create an SDK instance bound to the projectID, the projectID should be passed in Init
+func NewClient(projectID string, opts ...ClientOption) (*Client, error) {
+	if len(projectID) == 0 {
+		return nil, errors.Errorf("projectID is required")
+	}
+	c := &Client{projectID: projectID}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// WithDefaults Derive a scoped client with the preset defaults, for the services that only touch one project and a
+// fixed attribute set, so that their calls omit the boilerplate arguments. The scoped client is bound to the
+// projectID, empty keeps the one of c. The commonAttrs are set as the tags of every user context it creates, the tags
+// passed in NewUserContext take precedence. The experiment calls are filtered by the sceneID, zero adds no filter.
+// The defaults of c are kept and the new ones are applied after them, c is not modified
+func (c *Client) WithDefaults(projectID string, commonAttrs map[string]string, sceneID int64) *Client {
+	scoped := &Client{projectID: c.projectID}
+	if len(projectID) != 0 {
+		scoped.projectID = projectID
+	}
+	scoped.attributions = append(scoped.attributions, c.attributions...)
+	if len(commonAttrs) != 0 {
+		scoped.attributions = append(scoped.attributions, withCommonAttrs(commonAttrs))
+	}
+	scoped.experimentOptions = append(scoped.experimentOptions, c.experimentOptions...)
+	if sceneID != 0 {
+		scoped.experimentOptions = append(scoped.experimentOptions, WithSceneID(sceneID))
+	}
+	return scoped
+}
+
+// withCommonAttrs Set each attribute as a tag of a single value, the map is copied so that the caller can reuse it
+func withCommonAttrs(commonAttrs map[string]string) Attribution {
+	attrs := make(map[string]string, len(commonAttrs))
+	for key, value := range commonAttrs {
+		attrs[key] = value
+	}
+	return func(c *userContext) {
+		for key, value := range attrs {
+			c.tags[key] = []string{value}
+		}
+	}
+}
+
+// ProjectID The projectID bound to the client
+func (c *Client) ProjectID() string {
+	return c.projectID
+}
+
+// NewUserContext Create a user context with the default attributions of the client, see abc.NewUserContext
+func (c *Client) NewUserContext(unitID string, opts ...Attribution) Context {
+	if len(c.attributions) == 0 {
+		return NewUserContext(unitID, opts...)
+	}
+	attributions := make([]Attribution, 0, len(c.attributions)+len(opts))
+	attributions = append(attributions, c.attributions...)
+	return NewUserContext(unitID, append(attributions, opts...)...)
+}
+
+// NewAliasedUserContext Create the user context of the userID through its alias with the default attributions of the
+// client, see abc.NewAliasedUserContext
+func (c *Client) NewAliasedUserContext(ctx context.Context, userID string, opts ...Attribution) Context {
+	attributions := make([]Attribution, 0, len(c.attributions)+len(opts))
+	attributions = append(attributions, c.attributions...)
+	return NewAliasedUserContext(ctx, userID, append(attributions, opts...)...)
+}
+
+// GetExperiment Get the experiment of the layerKey under the bound projectID, see Context.GetExperiment
+func (c *Client) GetExperiment(ctx context.Context, userCtx Context, layerKey string,
+	opts ...ExperimentOption) (*ExperimentResult, error) {
+	return userCtx.GetExperiment(ctx, c.projectID, layerKey, c.mergeExperimentOptions(opts)...)
+}
+
+// GetExperimentWithTrace Get the experiment with the evaluation trace under the bound projectID,
+// see abc.GetExperimentWithTrace
+func (c *Client) GetExperimentWithTrace(ctx context.Context, userCtx Context, layerKey string,
+	opts ...ExperimentOption) (*ExperimentResult, *EvaluationTrace, error) {
+	return GetExperimentWithTrace(ctx, userCtx, c.projectID, layerKey, c.mergeExperimentOptions(opts)...)
+}
+
+// GetExperiments Get all experiments under the bound projectID, see Context.GetExperiments
+func (c *Client) GetExperiments(ctx context.Context, userCtx Context, opts ...ExperimentOption) (*ExperimentList,
+	error) {
+	return userCtx.GetExperiments(ctx, c.projectID, c.mergeExperimentOptions(opts)...)
+}
+
+// GetExperimentsByKeyPattern Get the experiments of the layers matching the pattern under the bound projectID,
+// see Context.GetExperimentsByKeyPattern
+func (c *Client) GetExperimentsByKeyPattern(ctx context.Context, userCtx Context, pattern string,
+	opts ...ExperimentOption) (*ExperimentList, error) {
+	return userCtx.GetExperimentsByKeyPattern(ctx, c.projectID, pattern, c.mergeExperimentOptions(opts)...)
+}
+
+// GetValueByVariantKey Get the parameter value under the bound projectID, see Context.GetValueByVariantKey
+func (c *Client) GetValueByVariantKey(ctx context.Context, userCtx Context, key string,
+	opts ...ExperimentOption) (*ValueResult, error) {
+	return userCtx.GetValueByVariantKey(ctx, c.projectID, key, c.mergeExperimentOptions(opts)...)
+}
+
+// GetFeatureFlag Get the feature flag under the bound projectID, see Context.GetFeatureFlag
+func (c *Client) GetFeatureFlag(ctx context.Context, userCtx Context, key string, opts ...ConfigOption) (
+	*FeatureFlag, error) {
+	return userCtx.GetFeatureFlag(ctx, c.projectID, key, opts...)
+}
+
+// LogExperimentExposure Manually log the experiment exposure under the bound projectID
+func (c *Client) LogExperimentExposure(ctx context.Context, result *ExperimentResult) error {
+	return LogExperimentExposure(ctx, c.projectID, result)
+}
+
+// LogExperimentsExposure Manually log the exposure of the experiment list under the bound projectID
+func (c *Client) LogExperimentsExposure(ctx context.Context, list *ExperimentList) error {
+	return LogExperimentsExposure(ctx, c.projectID, list)
+}
+
+// LogFeatureFlagExposure Manually log the feature flag exposure under the bound projectID
+func (c *Client) LogFeatureFlagExposure(ctx context.Context, featureFlag *FeatureFlag) error {
+	return LogFeatureFlagExposure(ctx, c.projectID, featureFlag)
+}
+
+// GenerateBootstrap Generate the bootstrap payload of the user under the bound projectID, see abc.GenerateBootstrap
+func (c *Client) GenerateBootstrap(ctx context.Context, userCtx Context, opts ...BootstrapOption) ([]byte, error) {
+	if len(c.experimentOptions) == 0 {
+		return GenerateBootstrap(ctx, c.projectID, userCtx, opts...)
+	}
+	merged := make([]BootstrapOption, 0, len(opts)+1)
+	merged = append(merged, WithBootstrapExperimentOptions(c.experimentOptions...))
+	return GenerateBootstrap(ctx, c.projectID, userCtx, append(merged, opts...)...)
+}
+
+// mergeExperimentOptions The default options are executed first, so the options passed in the call overwrite them
+func (c *Client) mergeExperimentOptions(opts []ExperimentOption) []ExperimentOption {
+	if len(c.experimentOptions) == 0 {
+		return opts
+	}
+	result := make([]ExperimentOption, 0, len(c.experimentOptions)+len(opts))
+	result = append(result, c.experimentOptions...)
+	return append(result, opts...)
+}
+
+type clientContextKey struct{}
+
+// WithClient Return a copy of ctx carrying the client, usually called by the middleware
+func WithClient(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, c)
+}
+
+// FromContext Get the client injected through WithClient, ok is false if not injected
+func FromContext(ctx context.Context) (c *Client, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	c, ok = ctx.Value(clientContextKey{}).(*Client)
+	return c, ok && c != nil
+}
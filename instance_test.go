@@ -0,0 +1,110 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		projectID string
+		wantErr   bool
+	}{
+		{name: "projectID is required", projectID: "", wantErr: true},
+		{name: "normal", projectID: projectID, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewClient(tt.projectID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewClient() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil {
+				assert.Equal(t, tt.projectID, got.ProjectID())
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	c, err := NewClient(projectID)
+	assert.Nil(t, err)
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   *Client
+		wantOk bool
+	}{
+		{name: "not injected", ctx: context.Background(), want: nil, wantOk: false},
+		{name: "nil client", ctx: WithClient(context.Background(), nil), want: nil, wantOk: false},
+		{name: "injected", ctx: WithClient(context.Background(), c), want: c, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromContext(tt.ctx)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientGetExperiments(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	c, err := NewClient(projectID, WithClientAttributions(WithTagKV("city", "sz")),
+		WithClientExperimentOptions(WithAutomatic(false), WithIsDisableDMP(true)))
+	assert.Nil(t, err)
+	ctx := WithClient(context.Background(), c)
+	injected, ok := FromContext(ctx)
+	assert.True(t, ok)
+	got, err := injected.GetExperiments(ctx, injected.NewUserContext("12345"))
+	assert.Nil(t, err)
+	want, err := NewUserContext("12345", WithTagKV("city", "sz")).GetExperiments(ctx, projectID,
+		WithAutomatic(false), WithIsDisableDMP(true))
+	assert.Nil(t, err)
+	assert.Equal(t, len(want.Data), len(got.Data))
+	for layerKey, group := range want.Data {
+		if layerKey == "doubleHashLayerCityTag" { // evaluated in the random order of its groups
+			continue
+		}
+		assert.Equal(t, group.ID, got.Data[layerKey].ID)
+	}
+}
+
+func TestClientWithDefaults(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	base, err := NewClient("other", WithClientExperimentOptions(WithAutomatic(false)))
+	assert.Nil(t, err)
+	attrs := map[string]string{"city": "sz"}
+	scoped := base.WithDefaults(projectID, attrs, 0)
+	attrs["city"] = "gz" // copied
+	assert.Equal(t, projectID, scoped.ProjectID())
+	assert.Equal(t, "other", base.ProjectID())
+	assert.Equal(t, "other", base.WithDefaults("", nil, 0).ProjectID())
+
+	userCtx := scoped.NewUserContext("12345").(*userContext)
+	assert.Equal(t, []string{"sz"}, userCtx.tags["city"])
+	userCtx = scoped.NewUserContext("12345", WithTags(map[string][]string{"city": {"bj"}})).(*userContext)
+	assert.Equal(t, []string{"bj"}, userCtx.tags["city"])
+	assert.Empty(t, base.NewUserContext("12345").(*userContext).tags)
+
+	got, err := scoped.GetExperiments(context.Background(), scoped.NewUserContext("12345"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, got.Data)
+	// no experiment of the scene
+	got, err = base.WithDefaults(projectID, nil, 999999).GetExperiments(context.Background(),
+		scoped.NewUserContext("12345"))
+	assert.Nil(t, err)
+	assert.Empty(t, got.Data)
+}
@@ -0,0 +1,13 @@
+// Package internal sdk
+package internal
+
+import "context"
+
+// AliasStore The store of the anonymous-to-known identity aliases, shared by the instances in production so that
+// the alias recorded on the login instance is seen by the others. Concurrent and safe
+type AliasStore interface {
+	// SetAlias Record the anonymousID of the userID, the first alias of the userID is kept
+	SetAlias(ctx context.Context, userID string, anonymousID string) error
+	// GetAlias The anonymousID aliased to the userID, empty if not aliased
+	GetAlias(ctx context.Context, userID string) (string, error)
+}
@@ -0,0 +1,28 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// Assignment An assignment decision of the evaluation, emitted whether or not it is exposed
+type Assignment struct {
+	ProjectID string `json:"projectId"`
+	// Hashed in the exposure privacy mode like the exposures
+	UnitID         string    `json:"unitId"`
+	LayerKey       string    `json:"layerKey"`
+	ExperimentKey  string    `json:"experimentKey"`
+	GroupID        int64     `json:"groupId"`
+	IsDefault      bool      `json:"isDefault"`
+	IsControl      bool      `json:"isControl"`
+	IsOverrideList bool      `json:"isOverrideList"`
+	Time           time.Time `json:"time"`
+	// One of every SamplingInterval units is exported, scale the counts by it
+	SamplingInterval uint32 `json:"samplingInterval"`
+}
+
+// AssignmentSink Receive the sampled assignment decisions in batches, called by a single background goroutine
+type AssignmentSink interface {
+	Export(ctx context.Context, assignments []*Assignment) error
+}
@@ -0,0 +1,60 @@
+// Package internal sdk
+package internal
+
+import (
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+// AttributeDeriver Derive a normalized attribute of the user context from its other attributes, such as the
+// age_bucket from the birth_year or the app_major_version from the app_version, so that the targeting rules
+// read the same normalized attribute in all services
+type AttributeDeriver struct {
+	// The key of the derived attribute, the attribute passed by the caller is kept as is
+	Key string
+	// Derive the values of the attribute, nil or empty leaves the attribute unset. The attributes carry the ones
+	// derived by the previous derivers and must not be modified
+	Derive func(attributes map[string][]string) []string
+}
+
+// DeriveAttributes Run the derivers of C in order on the attributes, the attributes passed are not modified,
+// a copy is returned if any attribute is derived. The panics of the derivers are recovered and recorded, the
+// attribute is left unset
+func DeriveAttributes(attributes map[string][]string) map[string][]string {
+	derivers := C.AttributeDerivers
+	if len(derivers) == 0 {
+		return attributes
+	}
+	result, copied := attributes, false
+	for i := range derivers {
+		deriver := &derivers[i]
+		if len(result[deriver.Key]) > 0 { // passed by the caller or derived by a previous deriver
+			continue
+		}
+		value := deriveAttribute(deriver, result)
+		if len(value) == 0 {
+			continue
+		}
+		if !copied {
+			result = make(map[string][]string, len(attributes)+len(derivers))
+			for key, value := range attributes {
+				result[key] = value
+			}
+			copied = true
+		}
+		result[deriver.Key] = value
+	}
+	return result
+}
+
+func deriveAttribute(deriver *AttributeDeriver, attributes map[string][]string) (value []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := errors.Errorf("derive attribute %s panic:%v", deriver.Key, r)
+			RecordError("AttributeDeriver", err)
+			log.LimitedErrorf("deriveAttribute"+deriver.Key, "%v", err)
+			value = nil
+		}
+	}()
+	return deriver.Derive(attributes)
+}
@@ -0,0 +1,94 @@
+// Package internal sdk
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// auditLogSize The number of audit records kept in memory
+const auditLogSize = 256
+
+// AuditAction The kind of the runtime mutation recorded in the audit log
+type AuditAction string
+
+const (
+	// AuditActionUpdateOptions The runtime options of a projectID are modified
+	AuditActionUpdateOptions AuditAction = "update_options"
+	// AuditActionKillSwitch The reporting of a projectID is disabled or enabled at runtime
+	AuditActionKillSwitch AuditAction = "kill_switch"
+	// AuditActionRotateCredentials The credentials of a projectID are replaced, the values are never recorded
+	AuditActionRotateCredentials AuditAction = "rotate_credentials"
+	// AuditActionFreeze The cached configuration of a projectID is frozen or unfrozen
+	AuditActionFreeze AuditAction = "freeze"
+)
+
+// AuditRecord A runtime mutation made in-process
+type AuditRecord struct {
+	Time      time.Time   `json:"time"`
+	Action    AuditAction `json:"action"`
+	ProjectID string      `json:"projectId"`
+	// The package/file:line of the code that made the mutation
+	Caller string `json:"caller"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+var auditLog = &auditRing{}
+
+// auditRing Fixed size ring of the audit records, the oldest one is overwritten when full
+type auditRing struct {
+	lock    sync.Mutex
+	records [auditLogSize]AuditRecord
+	next    int
+	full    bool
+}
+
+// RecordAudit Keep the mutation in the audit log, skip is the number of stack frames above the caller of
+// RecordAudit to reach the code that made the mutation, concurrent and safe
+func RecordAudit(skip int, action AuditAction, projectID string, before string, after string) {
+	record := AuditRecord{Time: time.Now(), Action: action, ProjectID: projectID, Caller: caller(skip + 2),
+		Before: before, After: after}
+	auditLog.lock.Lock()
+	defer auditLog.lock.Unlock()
+	auditLog.records[auditLog.next] = record
+	auditLog.next = (auditLog.next + 1) % auditLogSize
+	if auditLog.next == 0 {
+		auditLog.full = true
+	}
+}
+
+// AuditLog The audit records, the oldest first, so that the mutations can be replayed in order
+func AuditLog() []*AuditRecord {
+	auditLog.lock.Lock()
+	defer auditLog.lock.Unlock()
+	size, start := auditLog.next, 0
+	if auditLog.full {
+		size, start = auditLogSize, auditLog.next
+	}
+	result := make([]*AuditRecord, 0, size)
+	for i := 0; i < size; i++ {
+		record := auditLog.records[(start+i)%auditLogSize]
+		result = append(result, &record)
+	}
+	return result
+}
+
+// ResetAuditLog Clear the audit log, only used by the tests, the audit log survives Release
+func ResetAuditLog() {
+	auditLog.lock.Lock()
+	defer auditLog.lock.Unlock()
+	auditLog.records = [auditLogSize]AuditRecord{}
+	auditLog.next, auditLog.full = 0, false
+}
+
+func caller(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)), line)
+}
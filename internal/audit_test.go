@@ -0,0 +1,24 @@
+// Package internal sdk
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	ResetAuditLog()
+	defer ResetAuditLog()
+	assert.Equal(t, 0, len(AuditLog()))
+	for i := 0; i < auditLogSize+10; i++ {
+		RecordAudit(0, AuditActionUpdateOptions, "123", fmt.Sprint(i), fmt.Sprint(i+1))
+	}
+	got := AuditLog()
+	assert.Equal(t, auditLogSize, len(got))
+	assert.Equal(t, "10", got[0].Before) // the oldest first
+	assert.Equal(t, fmt.Sprint(auditLogSize+9), got[len(got)-1].Before)
+	assert.True(t, strings.HasPrefix(got[0].Caller, "internal/audit_test.go:"), got[0].Caller)
+}
@@ -0,0 +1,218 @@
+// Package internal sdk
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// BlobPointerKey The only key of the JSON object of a remote config value pointing to a large object, such as
+// {"$blob":{"url":"https://cdn.example.com/model.bin","sha256":"..."}}
+const BlobPointerKey = "$blob"
+
+const (
+	// defaultMaxBlobSize The default maximum size of a large object
+	defaultMaxBlobSize = 64 << 20
+	// defaultBlobCacheSize The default maximum total size of the cached large objects
+	defaultBlobCacheSize = 256 << 20
+	// blobTimeout The timeout of fetching a large object, unless the ctx of the caller is shorter
+	blobTimeout = 30 * time.Second
+)
+
+// ErrBlobIntegrity The content of the large object does not match the checksum of the pointer
+var ErrBlobIntegrity = errors.New("blob checksum mismatch")
+
+// BlobPointer The pointer to a large object stored outside the configuration, such as the multi-MB parameters of
+// an ML model
+type BlobPointer struct {
+	URL string `json:"url"`
+	// The hex encoded SHA-256 of the content, required
+	SHA256 string `json:"sha256"`
+	// The size of the content, optional, checked if set
+	Size int64 `json:"size,omitempty"`
+}
+
+// BlobFetcher Fetch the content of the url of the large object, the content is verified by FetchBlob
+type BlobFetcher func(ctx context.Context, url string) ([]byte, error)
+
+// blobPointerPrefix The cheap check before parsing, so that the ordinary values are not unmarshalled
+var blobPointerPrefix = []byte(`"` + BlobPointerKey + `"`)
+
+// ParseBlobPointer Parse the remote config value as a blob pointer, false if it is an ordinary value
+func ParseBlobPointer(data []byte) (*BlobPointer, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !bytes.Contains(trimmed, blobPointerPrefix) {
+		return nil, false
+	}
+	var value map[string]json.RawMessage
+	if json.Unmarshal(trimmed, &value) != nil || len(value) != 1 {
+		return nil, false
+	}
+	raw, ok := value[BlobPointerKey]
+	if !ok {
+		return nil, false
+	}
+	var pointer BlobPointer
+	if json.Unmarshal(raw, &pointer) != nil || len(pointer.URL) == 0 || len(pointer.SHA256) == 0 {
+		return nil, false
+	}
+	pointer.SHA256 = strings.ToLower(pointer.SHA256)
+	return &pointer, true
+}
+
+// blobEntry A cached large object
+type blobEntry struct {
+	data       []byte
+	accessTime time.Time
+}
+
+var (
+	blobLock sync.Mutex
+	// blobIndex key is the SHA-256 of the content, the pointers of the same content share the entry
+	blobIndex = map[string]*blobEntry{}
+	blobBytes int64
+	blobGroup singleflight.Group
+)
+
+// FetchBlob Get the content of the large object from the cache, or fetch it on the first access and verify it
+// against the checksum and the size of the pointer. The concurrent first accesses share one fetch, the callers
+// wait on it until their ctx is done. The least recently accessed ones are evicted beyond
+// GlobalConfig.BlobCacheSize. The returned content is shared and must not be modified
+func FetchBlob(ctx context.Context, pointer *BlobPointer) ([]byte, error) {
+	if data, ok := loadBlob(pointer.SHA256); ok {
+		return data, nil
+	}
+	result := blobGroup.DoChan(pointer.SHA256, func() (interface{}, error) {
+		if data, ok := loadBlob(pointer.SHA256); ok { // fetched by the previous flight
+			return data, nil
+		}
+		fetchCtx, cancel := context.WithTimeout(context.Background(), blobTimeout)
+		defer cancel()
+		data, err := blobFetcher()(fetchCtx, pointer.URL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetch blob %s", pointer.URL)
+		}
+		if err = verifyBlob(pointer, data); err != nil {
+			return nil, err
+		}
+		storeBlob(pointer.SHA256, data)
+		return data, nil
+	})
+	select {
+	case r := <-result:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func verifyBlob(pointer *BlobPointer, data []byte) error {
+	if int64(len(data)) > maxBlobSize() {
+		return errors.Errorf("blob %s exceeds the maximum size %d", pointer.URL, maxBlobSize())
+	}
+	if pointer.Size > 0 && int64(len(data)) != pointer.Size {
+		return errors.Wrapf(ErrBlobIntegrity, "blob %s size %d, want %d", pointer.URL, len(data), pointer.Size)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != pointer.SHA256 {
+		return errors.Wrapf(ErrBlobIntegrity, "blob %s", pointer.URL)
+	}
+	return nil
+}
+
+func loadBlob(sum string) ([]byte, bool) {
+	blobLock.Lock()
+	defer blobLock.Unlock()
+	entry, ok := blobIndex[sum]
+	if !ok {
+		return nil, false
+	}
+	entry.accessTime = time.Now()
+	return entry.data, true
+}
+
+func storeBlob(sum string, data []byte) {
+	blobLock.Lock()
+	defer blobLock.Unlock()
+	if _, ok := blobIndex[sum]; ok {
+		return
+	}
+	blobIndex[sum] = &blobEntry{data: data, accessTime: time.Now()}
+	blobBytes += int64(len(data))
+	for blobBytes > blobCacheSize() && len(blobIndex) > 1 {
+		var oldest string
+		var oldestTime time.Time
+		for key, entry := range blobIndex {
+			if key != sum && (len(oldest) == 0 || entry.accessTime.Before(oldestTime)) {
+				oldest, oldestTime = key, entry.accessTime
+			}
+		}
+		blobBytes -= int64(len(blobIndex[oldest].data))
+		delete(blobIndex, oldest)
+	}
+}
+
+// ResetBlobs Clear the cached large objects, called by Release
+func ResetBlobs() {
+	blobLock.Lock()
+	defer blobLock.Unlock()
+	blobIndex = map[string]*blobEntry{}
+	blobBytes = 0
+}
+
+func blobFetcher() BlobFetcher {
+	if C.BlobFetcher != nil {
+		return C.BlobFetcher
+	}
+	return fetchBlobHTTP
+}
+
+func maxBlobSize() int64 {
+	if C.MaxBlobSize > 0 {
+		return C.MaxBlobSize
+	}
+	return defaultMaxBlobSize
+}
+
+func blobCacheSize() int64 {
+	if C.BlobCacheSize > 0 {
+		return C.BlobCacheSize
+	}
+	return defaultBlobCacheSize
+}
+
+// fetchBlobHTTP The default BlobFetcher, an http GET of the url, reading at most the maximum size
+func fetchBlobHTTP(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "new request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "do")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("status %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBlobSize()+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "read")
+	}
+	return data, nil
+}
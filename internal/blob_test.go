@@ -0,0 +1,67 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func blobSum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseBlobPointer(t *testing.T) {
+	pointer, ok := ParseBlobPointer([]byte(` {"$blob":{"url":"https://cdn/model.bin","sha256":"ABCD","size":3}}`))
+	assert.True(t, ok)
+	assert.Equal(t, &BlobPointer{URL: "https://cdn/model.bin", SHA256: "abcd", Size: 3}, pointer)
+	for _, value := range []string{"", "123", `"$blob"`, `{"a":"$blob"}`, `{"$blob":{"url":"u"}}`,
+		`{"$blob":{"url":"u","sha256":"s"},"other":1}`, `{"$blob":1}`} {
+		_, ok = ParseBlobPointer([]byte(value))
+		assert.False(t, ok, value)
+	}
+}
+
+func TestFetchBlob(t *testing.T) {
+	defer func() {
+		C = &GlobalConfig{}
+		ResetBlobs()
+	}()
+	var count int32
+	contents := map[string][]byte{"a": []byte("content a"), "b": []byte("content b"), "bad": []byte("tampered")}
+	C = &GlobalConfig{BlobFetcher: func(ctx context.Context, url string) ([]byte, error) {
+		atomic.AddInt32(&count, 1)
+		return contents[url], nil
+	}, BlobCacheSize: 10}
+	pointer := &BlobPointer{URL: "a", SHA256: blobSum(contents["a"])}
+	for i := 0; i < 3; i++ {
+		data, err := FetchBlob(context.Background(), pointer)
+		assert.Nil(t, err)
+		assert.Equal(t, contents["a"], data)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	_, err := FetchBlob(context.Background(), &BlobPointer{URL: "bad", SHA256: blobSum(contents["b"])})
+	assert.True(t, errors.Is(err, ErrBlobIntegrity))
+	_, err = FetchBlob(context.Background(), &BlobPointer{URL: "b", SHA256: blobSum(contents["b"]), Size: 1})
+	assert.True(t, errors.Is(err, ErrBlobIntegrity))
+
+	// the cache holds one of them only, the least recently accessed one is evicted
+	_, err = FetchBlob(context.Background(), &BlobPointer{URL: "b", SHA256: blobSum(contents["b"])})
+	assert.Nil(t, err)
+	count = 0
+	_, err = FetchBlob(context.Background(), pointer)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	C.MaxBlobSize = 4
+	ResetBlobs()
+	_, err = FetchBlob(context.Background(), pointer)
+	assert.NotNil(t, err)
+}
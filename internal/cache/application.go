@@ -7,17 +7,18 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/RoaringBitmap/roaring"
 	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
 	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/internal/tracing"
 	"github.com/abetterchoice/go-sdk/plugin/log"
-	metrics2 "github.com/abetterchoice/go-sdk/plugin/metrics"
 	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
-	"github.com/abetterchoice/protoc_event_server"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 // Application Locally cached entities
@@ -25,16 +26,20 @@ type Application struct {
 	// Project Code
 	ProjectID string
 	Version   string
+	// Content hash of the served configuration, differs whenever the experiments, configs or buckets differ
+	Revision string
+	// The time when the configuration of the current revision was loaded
+	UpdateTime time.Time
 	// Experiment, configuration, switch information
 	TabConfig *protoctabcacheserver.TabConfig
 	// Experimental barrel information
 	ExperimentIDBucketInfoIndex map[int64]*protoctabcacheserver.BucketInfo
-	// roaring bitmap index, bucketInfo type is bitmap, roaring bitmap object will be pre-built
-	ExperimentIDRoaringBitmapIndex map[int64]*roaring.Bitmap
+	// roaring bitmap index, bucketInfo type is bitmap, the bitmap is decoded on first access, see LazyBitmap
+	ExperimentIDRoaringBitmapIndex map[int64]*LazyBitmap
 	// Experimental group bucket information
 	GroupIDBucketInfoIndex map[int64]*protoctabcacheserver.BucketInfo
-	// roaring bitmap index, bucketInfo type is bitmap, roaring bitmap object will be pre-built
-	GroupIDRoaringBitmapIndex map[int64]*roaring.Bitmap
+	// roaring bitmap index, bucketInfo type is bitmap, the bitmap is decoded on first access, see LazyBitmap
+	GroupIDRoaringBitmapIndex map[int64]*LazyBitmap
 	// The layer that accounts for 100% of the overall traffic, key is layerKey, value = specific layer information
 	FullFlowLayerIndex map[string]*protoctabcacheserver.Layer
 	// Experiment layer index, key is layerKey, value is layer
@@ -49,6 +54,27 @@ type Application struct {
 	DMPTagInfo map[protoctabcacheserver.UnitIDType]map[int64]map[string]interface{}
 	// Mapping of parameters to experimental layers
 	VariantKeyLayerMap map[string][]string
+	// Exposure templates of the groups, key is groupID
+	ExposureTemplateIndex map[int64]*ExposureTemplate
+	// The old experiments whose hashing the experiments re-keyed by the migrations keep, key is the ID of the new
+	// experiment, see setupExperimentMigrationIndex
+	ExperimentMigrationIndex map[int64]*protoctabcacheserver.Experiment
+	// Position of the distinct seeds of the seeded hashing in HashSeeds, key is the seed
+	HashSeedIndex map[int64]int
+	// The distinct seeds of the seeded hashing, see setupHashSeedIndex
+	HashSeeds []uint64
+	// The compiled targeting rules of the tag lists of the groups and the remote config conditions,
+	// in the order of the tags of each list, see setupRuleIndex
+	RuleIndex map[*protoctabcacheserver.TagList][]RuleMatcher
+	// The attribute keys of the layers whose evaluation may be memoized, key is layerKey, nil if the memo is
+	// disabled, see setupMemoLayerIndex
+	MemoLayerIndex map[string][]string
+	// The results of the remote configs only depending on the attributes, nil if none, see setupRemoteConfigCache
+	RemoteConfigCache *RemoteConfigCache
+	// Interned keys of the configuration, reused by the next refresh, see internTabConfig
+	internTable map[string]string
+	// Whether the application is built from a snapshot without the cache service, see NewStaticApplication
+	isStatic bool
 	// Whether to preprocess dmp tags
 	PreparedDMPTag bool
 	// Whether to disable the dmp tag, then the abtest traffic will be completely diverted to the local cache,
@@ -70,7 +96,22 @@ const (
 	defaultRefreshInterval = 3
 )
 
-var localApplicationCache sync.Map
+// applicationEntry The slot of a projectID, the refresh replaces the immutable application with a single atomic store
+type applicationEntry struct {
+	application atomic.Value // *Application
+	// The unix nano of the last successful refresh, atomic
+	syncTime int64
+	// Whether the refreshes are refused, see Freeze, atomic
+	frozen int32
+}
+
+var (
+	// localApplicationIndex map[string]*applicationEntry, the map is never modified after being stored,
+	// adding a projectID replaces it with a copy, so that GetApplication reads without any lock
+	localApplicationIndex atomic.Value
+	// localApplicationLock Serialize the writers of localApplicationIndex
+	localApplicationLock sync.Mutex
+)
 
 // InitLocalCache Initialize the local cache, and start an independent asynchronous refresh coroutine for
 // each projectID, and regularly pull the latest data from the remote background cache service to the local
@@ -78,17 +119,12 @@ var localApplicationCache sync.Map
 func InitLocalCache(ctx context.Context, projectIDList []string) error {
 	g, ctx := errgroup.WithContext(ctx)
 	for _, projectID := range projectIDList {
-		if _, ok := localApplicationCache.Load(projectID); ok { // If it exists, it will not be refreshed again
+		if GetApplication(projectID) != nil { // If it exists, it will not be refreshed again
 			continue
 		}
 		bc := projectID
 		g.Go(func() error {
-			_, err := NewAndSetApplication(ctx, bc)
-			if err != nil {
-				return err
-			}
-			go continuousFetch(bc)
-			return nil
+			return loadApplication(ctx, bc, false)
 		})
 	}
 	err := g.Wait()
@@ -98,71 +134,241 @@ func InitLocalCache(ctx context.Context, projectIDList []string) error {
 	return nil
 }
 
+// InitLocalCacheIsolated Initialize the local cache of each projectID independently,
+// the failure of one projectID does not affect the others. The successfully loaded projectID starts the refresh
+// coroutine and can serve immediately, the failed projectID keeps retrying in the background until it is loaded.
+// The returned map key is the failed projectID, value is the error, empty if all succeed
+func InitLocalCacheIsolated(ctx context.Context, projectIDList []string) map[string]error {
+	var (
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[string]error)
+	)
+	for _, projectID := range projectIDList {
+		if GetApplication(projectID) != nil { // If it exists, it will not be refreshed again
+			continue
+		}
+		bc := projectID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := loadApplication(ctx, bc, true)
+			if err == nil {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			result[bc] = err
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+// loadGroup Deduplicate the concurrent cold-start loads of the same projectID
+var loadGroup singleflight.Group
+
+// loadApplication Load the projectID not in the local cache yet and start its refresh coroutine. The concurrent
+// loads of the same projectID, such as Init and RegisterProjectIDs called by many requests at the same time,
+// share a single fetch with the ctx of the first caller, the other callers wait on it until their ctx is done.
+// If retry, the failed projectID keeps retrying in the background, the load in flight decides it
+func loadApplication(ctx context.Context, projectID string, retry bool) error {
+	result := loadGroup.DoChan(projectID, func() (interface{}, error) {
+		if GetApplication(projectID) != nil { // loaded by the previous flight
+			return nil, nil
+		}
+		_, err := NewAndSetApplication(ctx, projectID)
+		if err == nil {
+			superviseFetch(projectID)
+			return nil, nil
+		}
+		if retry {
+			stop := loopStop()
+			internal.Go("load:"+projectID, func(task *internal.Task) {
+				continuousLoad(task, projectID, stop)
+			})
+		}
+		return nil, err
+	})
+	select {
+	case r := <-result:
+		return r.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// continuousLoad Retry loading the projectID that failed to initialize, switch to continuousFetch after success.
+// Exit when stop is closed by Release or the projectID is no longer initialized
+func continuousLoad(task *internal.Task, projectID string, stop <-chan struct{}) {
+	for {
+		task.Heartbeat()
+		if !sleepUntilStop(refreshDuration(projectID), stop) || !isInitialized(projectID) {
+			log.Warnf("stop load %v", projectID)
+			return
+		}
+		_, err := refreshWithTimeout(projectID, stop)
+		if isStopped(stop) {
+			log.Warnf("stop load %v", projectID)
+			return
+		}
+		if err == nil {
+			log.Infof("[projectID=%v]loaded", projectID)
+			continuousFetch(task, projectID, stop)
+			return
+		}
+		log.Log(context.Background(), log.ErrorLevel, "load fail", log.Any("projectID", projectID), log.Err(err))
+		internal.ReportBackgroundError("load:"+projectID, err)
+	}
+}
+
+// isInitialized Whether the projectID is in the projectIDList passed in Init
+func isInitialized(projectID string) bool {
+	for _, id := range internal.C.ProjectIDList {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
 // asyncRefreshLocalCache Asynchronously refresh each projectID local cache
 func asyncRefreshLocalCache(projectIDList []string) {
 	for _, projectID := range projectIDList {
-		if _, ok := localApplicationCache.Load(projectID); ok { // If the cache exists, start the refresh coroutine
-			go continuousFetch(projectID)
+		if GetApplication(projectID) != nil { // If the cache exists, start the refresh coroutine
+			superviseFetch(projectID)
 		}
 	}
 }
 
-// continuousFetch Infinite loop refresh local cache
-func continuousFetch(projectID string) {
+// superviseFetch Start the refresh coroutine of the projectID under supervision,
+// the refresh resumes after a panic instead of silently stopping updating
+func superviseFetch(projectID string) {
+	stop := loopStop()
+	internal.Go("refresh:"+projectID, func(task *internal.Task) {
+		continuousFetch(task, projectID, stop)
+	})
+}
+
+// continuousFetch Infinite loop refresh local cache until stop is closed by Release, task records the activity of
+// each refresh and can be nil
+func continuousFetch(task *internal.Task, projectID string, stop <-chan struct{}) {
 	for {
+		task.Heartbeat()
 		application := GetApplication(projectID)
-		if application == nil { // The local cache does not exist, exit the refresh coroutine
+		if application == nil || isStopped(stop) { // The local cache does not exist, exit the refresh coroutine
 			log.Warnf("stop refresh %v", projectID)
 			return
 		}
 		log.Debugf("[projectID=%v] alive", projectID)
 		start := time.Now()
-		_, err := NewAndSetApplication(context.Background(), projectID)
+		_, err := refreshWithTimeout(projectID, stop)
 		latency := time.Since(start)
+		if isStopped(stop) {
+			log.Warnf("stop refresh %v", projectID)
+			return
+		}
 		if err != nil {
-			log.Errorf("[projectID=%v,latency=%s]newApplication fail:%v", projectID, latency.String(), err)
+			log.Log(context.Background(), log.ErrorLevel, "refresh fail", log.Any("projectID", projectID),
+				log.Any("latency", latency), log.Err(err))
+			internal.ReportBackgroundError("refresh:"+projectID, err)
 		}
 		manualFetchEvent(projectID, latency, err)
-		time.Sleep(time.Duration(refreshInterval(projectID)) * time.Second)
+		if !sleepUntilStop(refreshDuration(projectID), stop) {
+			log.Warnf("stop refresh %v", projectID)
+			return
+		}
 	}
 }
 
-// manualFetchEvent Log local cache refresh events
-func manualFetchEvent(projectID string, latency time.Duration, err error) {
-	application := GetApplication(projectID)
-	if application == nil {
-		return
-	}
-	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
-	if metricsConfig == nil || !metricsConfig.IsEnable {
-		return
-	}
-	sendDataErr := metrics2.LogMonitorEvent(context.Background(), &metrics2.Metadata{
-		MetricsPluginName: metricsConfig.PluginName,
-		TableName:         metricsConfig.Metadata.Name,
-		TableID:           metricsConfig.Metadata.Id,
-		Token:             metricsConfig.Metadata.Token,
-		SamplingInterval:  metricsConfig.ErrSamplingInterval,
-	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
-		{
-			Time:       time.Now().Unix(),
-			Ip:         "",
-			ProjectId:  projectID,
-			EventName:  "refresh",
-			Latency:    float32(latency.Microseconds()), // us
-			StatusCode: env.EventStatus(err),
-			Message:    env.ErrMsg(err),
-			SdkType:    env.SDKType,
-			SdkVersion: env.Version,
-			InvokePath: env.InvokePath(4), // Skip 4 levels of the call stack
-			InputData:  "",
-			OutputData: "",
-			ExtInfo:    nil,
-		},
-	}})
-	if sendDataErr != nil {
-		log.Errorf("logMonitorEvent fail:%v", sendDataErr)
+var (
+	loopStopLock sync.Mutex
+	// loopStopChan Closed by Release, so that the load and refresh loops started before stop, instead of running
+	// next to the loops of the next Init of the same projectIDs
+	loopStopChan = make(chan struct{})
+)
+
+// loopStop The channel closed by the next Release, captured when a loop is started
+func loopStop() <-chan struct{} {
+	loopStopLock.Lock()
+	defer loopStopLock.Unlock()
+	return loopStopChan
+}
+
+// loopStopKey The context key of the stop channel of the loop running the refresh, the application refreshed after
+// stop is closed is discarded, see storeApplication
+type loopStopKey struct{}
+
+func loopStopOf(ctx context.Context) <-chan struct{} {
+	stop, _ := ctx.Value(loopStopKey{}).(<-chan struct{})
+	return stop
+}
+
+func isStopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopLoops Stop the loops started before, called by Release
+func stopLoops() {
+	loopStopLock.Lock()
+	defer loopStopLock.Unlock()
+	close(loopStopChan)
+	loopStopChan = make(chan struct{})
+}
+
+// sleepUntilStop Sleep for d, false if stop is closed meanwhile
+func sleepUntilStop(d time.Duration, stop <-chan struct{}) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// refreshWithTimeout Refresh the local cache, with the runtime refresh timeout of the projectID if set. The refresh is
+// canceled once stop is closed
+func refreshWithTimeout(projectID string, stop <-chan struct{}) (application *Application, err error) {
+	loopCtx, cancel := context.WithCancel(context.WithValue(context.Background(), loopStopKey{}, stop))
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-loopCtx.Done():
+		}
+	}()
+	ctx, span := tracing.Start(loopCtx, "abc.refresh")
+	defer func() {
+		if span.IsRecording() {
+			span.SetAttributes(tracing.KeyProjectID.String(projectID))
+			if application != nil {
+				span.SetAttributes(tracing.KeyVersion.String(application.Version))
+			}
+		}
+		tracing.End(span, err)
+	}()
+	if timeout := internal.RefreshTimeout(projectID); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
+	return NewAndSetApplication(ctx, projectID)
+}
+
+// refreshDuration Local cache refresh interval, the runtime option of the projectID takes precedence
+func refreshDuration(projectID string) time.Duration {
+	if interval := internal.RefreshInterval(projectID); interval > 0 {
+		return interval
+	}
+	return time.Duration(refreshInterval(projectID)) * time.Second
 }
 
 // refreshInterval Local cache refresh interval
@@ -195,22 +401,52 @@ func NewAndSetApplication(ctx context.Context, projectID string) (application *A
 		}
 	}()
 	var modified = true
+	previous := GetApplication(projectID)
+	if previous != nil && IsFrozen(projectID) { // pinned, see Freeze
+		log.Debugf("[projectID=%v] frozen, skip refresh", projectID)
+		return previous, nil
+	}
 	application, modified, err = refreshApplication(ctx, projectID)
 	if err != nil {
 		return nil, errors.Wrap(err, "refreshApplication")
 	}
 	if modified { // The local cache needs to be updated only when data changes
 		log.Infof("[projectID=%v] version=%v", application.ProjectID, application.Version)
-		setApplication(application)
+		if !storeApplication(application, loopStopOf(ctx)) {
+			return nil, errors.Errorf("refresh stopped by release")
+		}
+		reportConfigApplied(previous, application)
+	}
+	if entry, ok := loadApplicationIndex()[projectID]; ok {
+		atomic.StoreInt64(&entry.syncTime, time.Now().UnixNano())
 	}
 	return application, nil
 }
 
+// SyncTime The time of the last successful refresh of the configuration of the projectID, whether it changed or
+// not, zero if never refreshed
+func SyncTime(projectID string) time.Time {
+	entry, ok := loadApplicationIndex()[projectID]
+	if !ok {
+		return time.Time{}
+	}
+	syncTime := atomic.LoadInt64(&entry.syncTime)
+	if syncTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, syncTime)
+}
+
 // refreshApplication Refresh the local cache data application, return cache data,
 // whether the data is updated, error information
 func refreshApplication(ctx context.Context, projectID string) (*Application, bool, error) {
 	application := getLocalCacheWithDefault(projectID)
-	err := setupTabConfig(ctx, application) // Pull cache data
+	// the same client for all the steps of the refresh
+	cacheClient := client.GetCacheClient()
+	if cacheClient == nil {
+		return nil, false, errors.Errorf("cache client is not registered")
+	}
+	err := setupTabConfig(ctx, cacheClient, application) // Pull cache data
 	if err != nil {
 		return nil, false, errors.Wrap(err, "setupTabConfig")
 	}
@@ -218,33 +454,71 @@ func refreshApplication(ctx context.Context, projectID string) (*Application, bo
 		// the silent period will begin when maxRetryTime is reached.
 		return application, false, nil
 	}
-	err = setupLayerIndex(application)
-	if err != nil {
-		return nil, false, errors.Wrap(err, "setupLayerIndex")
-	}
-	err = setupFullFlowLayerIndex(application)
+	err = setupIndexes(ctx, cacheClient, application)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "setupFullFlowLayerIndex")
+		return nil, false, err
 	}
-	err = setupLayerDomainMetadataListIndex(application)
+	revision := application.Revision
+	err = setupRevision(application)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "setupLayerDomainMetadataListIndex")
+		return nil, false, errors.Wrap(err, "setupRevision")
 	}
-	err = setupExperimentBucketInfo(ctx, application)
-	if err != nil {
-		return nil, false, errors.Wrap(err, "setupExperimentBucketInfo")
-	}
-	err = setupGroupBucketInfo(ctx, application)
-	if err != nil {
-		return nil, false, errors.Wrap(err, "setupGroupBucketInfo")
+	if application.Revision != revision {
+		application.UpdateTime = time.Now()
 	}
-	err = setupDMPTagInfo(application)
+	return application, true, nil
+}
+
+// setupIndexes Build the indexes of the configuration. The layer indexes walk the domain tree independently and
+// are built in parallel, then the bucket information is fetched and the indexes derived from the layers are built
+// in parallel. Each step writes its own fields of the application only
+func setupIndexes(ctx context.Context, cacheClient client.Client, application *Application) error {
+	err := setupLayerIndexes(application)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "setupDMPTagInfo")
+		return err
 	}
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return errors.Wrap(setupExperimentBucketInfo(ctx, cacheClient, application), "setupExperimentBucketInfo")
+	})
+	g.Go(func() error {
+		return errors.Wrap(setupGroupBucketInfo(ctx, cacheClient, application), "setupGroupBucketInfo")
+	})
+	g.Go(func() error {
+		return errors.Wrap(setupDMPTagInfo(application), "setupDMPTagInfo")
+	})
+	g.Go(func() error {
+		setupDerivedIndexes(application)
+		return nil
+	})
+	return g.Wait()
+}
+
+// setupLayerIndexes Build the indexes of the layers walking the domain tree, in parallel
+func setupLayerIndexes(application *Application) error {
+	g := &errgroup.Group{}
+	g.Go(func() error {
+		return errors.Wrap(setupLayerIndex(application), "setupLayerIndex")
+	})
+	g.Go(func() error {
+		return errors.Wrap(setupFullFlowLayerIndex(application), "setupFullFlowLayerIndex")
+	})
+	g.Go(func() error {
+		return errors.Wrap(setupLayerDomainMetadataListIndex(application), "setupLayerDomainMetadataListIndex")
+	})
+	return g.Wait()
+}
+
+// setupDerivedIndexes Build the indexes derived from the layer indexes
+func setupDerivedIndexes(application *Application) {
 	setupMetricsInitConfigIndex(application)
 	setupVariantKeyLayerKeyMap(application)
-	return application, true, nil
+	setupExposureTemplateIndex(application)
+	setupExperimentMigrationIndex(application)
+	setupHashSeedIndex(application)
+	setupRuleIndex(application)
+	setupRemoteConfigCache(application)
+	setupMemoLayerIndex(application)
 }
 
 func setupVariantKeyLayerKeyMap(application *Application) {
@@ -500,11 +774,11 @@ func isFullFlowDomain(metadata *protoctabcacheserver.DomainMetadata) bool {
 	return right >= metadata.BucketSize
 }
 
-func setupExperimentBucketInfo(ctx context.Context, application *Application) error {
+func setupExperimentBucketInfo(ctx context.Context, cacheClient client.Client, application *Application) error {
 	if application.retryTime > maxRetryTime {
 		return nil
 	}
-	experimentBucketInfo, err := client.CacheClient.BatchGetExperimentBucketInfo(ctx,
+	experimentBucketInfo, err := cacheClient.BatchGetExperimentBucketInfo(ctx,
 		&protoctabcacheserver.BatchGetExperimentBucketReq{
 			ProjectId:          application.ProjectID,
 			SdkVersion:         env.SDKVersion,
@@ -513,10 +787,19 @@ func setupExperimentBucketInfo(ctx context.Context, application *Application) er
 	if err != nil {
 		return errors.Wrap(err, "batchGetExperimentBucketInfo")
 	}
+	if experimentBucketInfo == nil {
+		return errors.Errorf("invalid experimentBucketInfo")
+	}
 	if experimentBucketInfo.Code != protoctabcacheserver.Code_CODE_SUCCESS {
 		return errors.Errorf("invalid code:%v, message=%s", experimentBucketInfo.Code, experimentBucketInfo.Message)
 	}
-	for experimentID, bucketInfo := range experimentBucketInfo.BucketIndex {
+	applyExperimentBucketInfo(application, experimentBucketInfo.BucketIndex)
+	return nil
+}
+
+// applyExperimentBucketInfo Apply the modified bucket information of the experiments to the indexes
+func applyExperimentBucketInfo(application *Application, bucketIndex map[int64]*protoctabcacheserver.BucketInfo) {
+	for experimentID, bucketInfo := range bucketIndex {
 		if bucketInfo.ModifyType == protoctabcacheserver.ModifyType_MODIFY_DELETE ||
 			bucketInfo.ModifyType == protoctabcacheserver.ModifyType_MODIFY_UNKNOWN {
 			delete(application.ExperimentIDBucketInfoIndex, experimentID) // this is safe
@@ -527,17 +810,11 @@ func setupExperimentBucketInfo(ctx context.Context, application *Application) er
 		if bucketInfo.BucketType != protoctabcacheserver.BucketType_BUCKET_TYPE_BITMAP {
 			continue
 		}
-		bitmap := roaring.New()
-		_, err = bitmap.FromBuffer(bucketInfo.Bitmap)
-		if err != nil {
-			return errors.Wrapf(err, "[experimentID=%d]new bitmap fromBuffer", experimentID)
-		}
-		application.ExperimentIDRoaringBitmapIndex[experimentID] = bitmap
+		application.ExperimentIDRoaringBitmapIndex[experimentID] = NewLazyBitmap(bucketInfo.Bitmap) // decoded on first access
 	}
-	return nil
 }
 
-func setupGroupBucketInfo(ctx context.Context, application *Application) error {
+func setupGroupBucketInfo(ctx context.Context, cacheClient client.Client, application *Application) error {
 	if application.retryTime > maxRetryTime { // 没有数据变化的情况下，达到 maxRetryTime 则进入静默期
 		return nil
 	}
@@ -545,7 +822,7 @@ func setupGroupBucketInfo(ctx context.Context, application *Application) error {
 	if len(groupVersion) == 0 {
 		return nil
 	}
-	groupBucketInfo, err := client.CacheClient.BatchGetGroupBucketInfo(ctx, &protoctabcacheserver.BatchGetGroupBucketReq{
+	groupBucketInfo, err := cacheClient.BatchGetGroupBucketInfo(ctx, &protoctabcacheserver.BatchGetGroupBucketReq{
 		ProjectId:          application.ProjectID,
 		SdkVersion:         env.SDKVersion,
 		BucketVersionIndex: groupVersion,
@@ -553,10 +830,19 @@ func setupGroupBucketInfo(ctx context.Context, application *Application) error {
 	if err != nil {
 		return errors.Wrap(err, "batchGetGroupBucketInfo")
 	}
+	if groupBucketInfo == nil {
+		return errors.Errorf("invalid groupBucketInfo")
+	}
 	if groupBucketInfo.Code != protoctabcacheserver.Code_CODE_SUCCESS {
 		return errors.Errorf("invalid code:%v, message=%s", groupBucketInfo.Code, groupBucketInfo.Message)
 	}
-	for groupID, bucketInfo := range groupBucketInfo.BucketIndex {
+	applyGroupBucketInfo(application, groupBucketInfo.BucketIndex)
+	return nil
+}
+
+// applyGroupBucketInfo Apply the modified bucket information of the groups to the indexes
+func applyGroupBucketInfo(application *Application, bucketIndex map[int64]*protoctabcacheserver.BucketInfo) {
+	for groupID, bucketInfo := range bucketIndex {
 		if bucketInfo.ModifyType == protoctabcacheserver.ModifyType_MODIFY_DELETE ||
 			bucketInfo.ModifyType == protoctabcacheserver.ModifyType_MODIFY_UNKNOWN {
 			delete(application.GroupIDBucketInfoIndex, groupID) // this is safe
@@ -567,18 +853,12 @@ func setupGroupBucketInfo(ctx context.Context, application *Application) error {
 		if bucketInfo.BucketType != protoctabcacheserver.BucketType_BUCKET_TYPE_BITMAP {
 			continue
 		}
-		bitmap := roaring.New()
-		_, err = bitmap.FromBuffer(bucketInfo.Bitmap)
-		if err != nil {
-			return errors.Wrapf(err, "[groupID=%d]new bitmap fromBuffer", groupID)
-		}
-		application.GroupIDRoaringBitmapIndex[groupID] = bitmap
+		application.GroupIDRoaringBitmapIndex[groupID] = NewLazyBitmap(bucketInfo.Bitmap) // decoded on first access
 	}
-	return nil
 }
 
-func setupTabConfig(ctx context.Context, application *Application) error {
-	tabConfigData, err := client.CacheClient.GetTabConfigData(ctx, &protoctabcacheserver.GetTabConfigReq{
+func setupTabConfig(ctx context.Context, cacheClient client.Client, application *Application) error {
+	tabConfigData, err := cacheClient.GetTabConfigData(ctx, &protoctabcacheserver.GetTabConfigReq{
 		ProjectId:  application.ProjectID,
 		Version:    application.Version,
 		SdkVersion: env.SDKVersion,
@@ -599,6 +879,9 @@ func setupTabConfig(ctx context.Context, application *Application) error {
 			return errors.Errorf("invalid tabConfig")
 		}
 		application.retryTime = 0
+		if tabConfigData.TabConfigManager.TabConfig != application.TabConfig { // not served yet
+			internTabConfig(application, tabConfigData.TabConfigManager.TabConfig)
+		}
 		application.TabConfig = tabConfigData.TabConfigManager.TabConfig
 		application.Version = tabConfigData.TabConfigManager.Version
 	}
@@ -702,23 +985,28 @@ func genGroupVersionIndex(application *Application) map[int64]string {
 func getLocalCacheWithDefault(projectID string) *Application {
 	curApplication := GetApplication(projectID)
 	if curApplication == nil {
-		return &Application{
-			ProjectID:                      projectID,
-			Version:                        "",
-			ExperimentIDBucketInfoIndex:    map[int64]*protoctabcacheserver.BucketInfo{},
-			ExperimentIDRoaringBitmapIndex: map[int64]*roaring.Bitmap{},
-			GroupIDBucketInfoIndex:         map[int64]*protoctabcacheserver.BucketInfo{},
-			GroupIDRoaringBitmapIndex:      map[int64]*roaring.Bitmap{},
-			FullFlowLayerIndex:             map[string]*protoctabcacheserver.Layer{},
-			LayerIndex:                     map[string]*protoctabcacheserver.Layer{},
-			VariantKeyLayerMap:             map[string][]string{},
-			DMPTagInfo:                     map[protoctabcacheserver.UnitIDType]map[int64]map[string]interface{}{},
-		}
+		return newEmptyApplication(projectID)
 	}
 	// copy curApplication
 	return getNewApplication(curApplication)
 }
 
+// newEmptyApplication The application of the projectID before the first load
+func newEmptyApplication(projectID string) *Application {
+	return &Application{
+		ProjectID:                      projectID,
+		Version:                        "",
+		ExperimentIDBucketInfoIndex:    map[int64]*protoctabcacheserver.BucketInfo{},
+		ExperimentIDRoaringBitmapIndex: map[int64]*LazyBitmap{},
+		GroupIDBucketInfoIndex:         map[int64]*protoctabcacheserver.BucketInfo{},
+		GroupIDRoaringBitmapIndex:      map[int64]*LazyBitmap{},
+		FullFlowLayerIndex:             map[string]*protoctabcacheserver.Layer{},
+		LayerIndex:                     map[string]*protoctabcacheserver.Layer{},
+		VariantKeyLayerMap:             map[string][]string{},
+		DMPTagInfo:                     map[protoctabcacheserver.UnitIDType]map[int64]map[string]interface{}{},
+	}
+}
+
 // getNewApplication gets a concurrently safe application, regenerates a new map object,
 // but retains the original pointer of value
 // Here you can directly use json marshal unmarshal to make a deep copy of the data to ensure concurrent safety,
@@ -728,6 +1016,8 @@ func getNewApplication(curApplication *Application) *Application {
 	return &Application{
 		ProjectID:                      curApplication.ProjectID,
 		Version:                        curApplication.Version,
+		Revision:                       curApplication.Revision,
+		UpdateTime:                     curApplication.UpdateTime,
 		TabConfig:                      curApplication.TabConfig,
 		ExperimentIDBucketInfoIndex:    getNewBucketInfoIndex(curApplication.ExperimentIDBucketInfoIndex),
 		ExperimentIDRoaringBitmapIndex: getNewRoaringBitmapIndex(curApplication.ExperimentIDRoaringBitmapIndex),
@@ -737,6 +1027,12 @@ func getNewApplication(curApplication *Application) *Application {
 		LayerIndex:                     curApplication.LayerIndex,
 		DMPTagInfo:                     curApplication.DMPTagInfo,
 		VariantKeyLayerMap:             curApplication.VariantKeyLayerMap,
+		ExposureTemplateIndex:          curApplication.ExposureTemplateIndex,
+		ExperimentMigrationIndex:       curApplication.ExperimentMigrationIndex,
+		HashSeedIndex:                  curApplication.HashSeedIndex,
+		HashSeeds:                      curApplication.HashSeeds,
+		RuleIndex:                      curApplication.RuleIndex,
+		internTable:                    curApplication.internTable,
 		PreparedDMPTag:                 curApplication.PreparedDMPTag,
 		DisableDMPTag:                  curApplication.DisableDMPTag,
 		retryTime:                      curApplication.retryTime,
@@ -752,35 +1048,73 @@ func getNewBucketInfoIndex(
 	return result
 }
 
-func getNewRoaringBitmapIndex(curIndex map[int64]*roaring.Bitmap) map[int64]*roaring.Bitmap {
-	var result = make(map[int64]*roaring.Bitmap, len(curIndex))
+func getNewRoaringBitmapIndex(curIndex map[int64]*LazyBitmap) map[int64]*LazyBitmap {
+	var result = make(map[int64]*LazyBitmap, len(curIndex))
 	for k, v := range curIndex {
 		result[k] = v
 	}
 	return result
 }
 
-// GetApplication TODO
+// GetApplication Get the local cache of the projectID, return nil if not loaded. Lock-free, the returned
+// application is an immutable snapshot and must not be modified
 func GetApplication(projectID string) *Application {
-	result, ok := localApplicationCache.Load(projectID)
+	entry, ok := loadApplicationIndex()[projectID]
 	if !ok {
 		return nil
 	}
-	application, ok := result.(*Application)
+	application, ok := entry.application.Load().(*Application)
 	if !ok {
 		return nil
 	}
 	return application
 }
 
+func loadApplicationIndex() map[string]*applicationEntry {
+	index, _ := localApplicationIndex.Load().(map[string]*applicationEntry)
+	return index
+}
+
 func setApplication(application *Application) {
+	storeApplication(application, nil)
+}
+
+// storeApplication Store the application, unless stop is closed, so that the loops stopped by Release never store
+// the application back after the release. Return false if discarded
+func storeApplication(application *Application, stop <-chan struct{}) bool {
 	if application == nil {
-		return
+		return true
+	}
+	if entry, ok := loadApplicationIndex()[application.ProjectID]; ok && !isStopped(stop) { // swap the pointer only
+		entry.application.Store(application)
+		return true
+	}
+	localApplicationLock.Lock()
+	defer localApplicationLock.Unlock()
+	if isStopped(stop) { // checked under the lock taken by Release after closing stop
+		return false
+	}
+	index := loadApplicationIndex()
+	if entry, ok := index[application.ProjectID]; ok { // added by another writer
+		entry.application.Store(application)
+		return true
 	}
-	localApplicationCache.Store(application.ProjectID, application)
+	var newIndex = make(map[string]*applicationEntry, len(index)+1)
+	for projectID, entry := range index {
+		newIndex[projectID] = entry
+	}
+	entry := &applicationEntry{}
+	entry.application.Store(application)
+	newIndex[application.ProjectID] = entry
+	localApplicationIndex.Store(newIndex)
+	return true
 }
 
-// Release TODO
+// Release Clear the local cache of all projectIDs and stop the load and refresh coroutines
 func Release() {
-	localApplicationCache = sync.Map{}
+	stopLoops()
+	localApplicationLock.Lock()
+	defer localApplicationLock.Unlock()
+	localApplicationIndex.Store(map[string]*applicationEntry{})
+	resetSharedContent()
 }
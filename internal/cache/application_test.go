@@ -3,14 +3,20 @@ package cache
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/RoaringBitmap/roaring"
+	"github.com/abetterchoice/go-sdk/internal"
 	"github.com/abetterchoice/go-sdk/internal/client"
 	"github.com/abetterchoice/go-sdk/testdata"
 	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 )
 
 var (
@@ -50,7 +56,7 @@ func TestGetApplication(t *testing.T) {
 		ProjectID: "mock123",
 	})
 	setApplication(nil)
-	localApplicationCache.Store("fake123", "fake123")
+	localApplicationIndex.Store(map[string]*applicationEntry{"fake123": {}, "mock123": loadApplicationIndex()["mock123"]})
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if got := GetApplication(tt.args.projectID); !reflect.DeepEqual(got, tt.want) {
@@ -62,9 +68,10 @@ func TestGetApplication(t *testing.T) {
 
 func TestInitLocalCache(t *testing.T) {
 	defer func() {
-		client.CacheClient = nil
+		releaseLoops(t) // stop the loops before the client is unregistered
+		client.RegisterCacheClient(nil)
 	}()
-	client.CacheClient = testdata.MockCacheClient(t)
+	client.RegisterCacheClient(testdata.MockCacheClient(t))
 	type args struct {
 		ctx           context.Context
 		projectIDList []string
@@ -94,9 +101,10 @@ func TestInitLocalCache(t *testing.T) {
 
 func TestInitLocalCacheFailure(t *testing.T) {
 	defer func() {
-		client.CacheClient = nil
+		releaseLoops(t) // stop the loops before the client is unregistered
+		client.RegisterCacheClient(nil)
 	}()
-	client.CacheClient = testdata.MockFakeCacheClient(t)
+	client.RegisterCacheClient(testdata.MockFakeCacheClient(t))
 	type args struct {
 		ctx           context.Context
 		projectIDList []string
@@ -164,6 +172,45 @@ func TestRelease(t *testing.T) {
 	}
 }
 
+// releaseLoops Release and wait for the loops of the previous loads to exit, so that they do not race the next test
+func releaseLoops(t *testing.T) {
+	Release()
+	assert.Eventually(t, func() bool {
+		for _, task := range internal.Tasks() {
+			if strings.HasPrefix(task.Name, "refresh:") || strings.HasPrefix(task.Name, "load:") {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReleaseStopsLoops(t *testing.T) {
+	defer func() {
+		releaseLoops(t)
+		client.RegisterCacheClient(nil)
+	}()
+	running := func(name string) bool {
+		for _, task := range internal.Tasks() {
+			if task.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	client.RegisterCacheClient(testdata.MockCacheClient(t))
+	assert.Nil(t, InitLocalCache(context.Background(), projectIDList))
+	assert.True(t, running("refresh:123"))
+	client.RegisterCacheClient(testdata.MockFakeCacheClient(t))
+	assert.NotEmpty(t, InitLocalCacheIsolated(context.Background(), []string{"mock123"}))
+	assert.True(t, running("load:mock123"))
+	Release()
+	// not left running next to the loops of the next Init
+	assert.Eventually(t, func() bool {
+		return !running("refresh:123") && !running("load:mock123")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func Test_asyncRefreshLocalCache(t *testing.T) {
 	type args struct {
 		projectIDList []string
@@ -193,7 +240,7 @@ func Test_continuousFetch(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			continuousFetch(tt.args.projectID)
+			continuousFetch(nil, tt.args.projectID, loopStop())
 		})
 	}
 }
@@ -300,12 +347,12 @@ func Test_getNewBucketInfoIndex(t *testing.T) {
 
 func Test_getNewRoaringBitmapIndex(t *testing.T) {
 	type args struct {
-		curIndex map[int64]*roaring.Bitmap
+		curIndex map[int64]*LazyBitmap
 	}
 	tests := []struct {
 		name string
 		args args
-		want map[int64]*roaring.Bitmap
+		want map[int64]*LazyBitmap
 	}{
 		// TODO: Add test cases.
 	}
@@ -713,13 +760,25 @@ func Test_setupExperimentBucketInfo(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := setupExperimentBucketInfo(tt.args.ctx, tt.args.application); (err != nil) != tt.wantErr {
+			if err := setupExperimentBucketInfo(tt.args.ctx, client.GetCacheClient(), tt.args.application); (err != nil) != tt.wantErr {
 				t.Errorf("setupExperimentBucketInfo() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestSetupBucketInfoNilResponse(t *testing.T) {
+	cacheClient := client.NewMockClient(gomock.NewController(t))
+	cacheClient.EXPECT().BatchGetExperimentBucketInfo(gomock.Any(), gomock.Any()).Return(nil, nil)
+	cacheClient.EXPECT().BatchGetGroupBucketInfo(gomock.Any(), gomock.Any()).Return(nil, nil)
+	application := newEmptyApplication("123")
+	assert.EqualError(t, setupExperimentBucketInfo(context.Background(), cacheClient, application),
+		"invalid experimentBucketInfo")
+	application.GroupIDBucketInfoIndex[1] = &protoctabcacheserver.BucketInfo{}
+	assert.EqualError(t, setupGroupBucketInfo(context.Background(), cacheClient, application),
+		"invalid groupBucketInfo")
+}
+
 func Test_setupFillFlowLayerIndexDomain(t *testing.T) {
 	type args struct {
 		domain *protoctabcacheserver.Domain
@@ -780,7 +839,7 @@ func Test_setupGroupBucketInfo(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := setupGroupBucketInfo(tt.args.ctx, tt.args.application); (err != nil) != tt.wantErr {
+			if err := setupGroupBucketInfo(tt.args.ctx, client.GetCacheClient(), tt.args.application); (err != nil) != tt.wantErr {
 				t.Errorf("setupGroupBucketInfo() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -847,7 +906,7 @@ func Test_setupTabConfig(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := setupTabConfig(tt.args.ctx, tt.args.application); (err != nil) != tt.wantErr {
+			if err := setupTabConfig(tt.args.ctx, client.GetCacheClient(), tt.args.application); (err != nil) != tt.wantErr {
 				t.Errorf("setupTabConfig() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -873,3 +932,82 @@ func Test_validateTabConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestSetApplicationConcurrent(t *testing.T) {
+	defer Release()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		projectID := fmt.Sprintf("concurrent%d", i%2)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				setApplication(&Application{ProjectID: projectID, Version: fmt.Sprint(j)})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := GetApplication(projectID); got != nil && got.ProjectID != projectID {
+					t.Errorf("GetApplication() = %v, want %v", got.ProjectID, projectID)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 2; i++ {
+		if got := GetApplication(fmt.Sprintf("concurrent%d", i)); got == nil || got.Version != "99" {
+			t.Errorf("GetApplication() = %+v, want version 99", got)
+		}
+	}
+}
+
+// countCacheClient Count the config fetches, each fetch takes a while so that the loads overlap
+type countCacheClient struct {
+	client.Client
+	count int32
+}
+
+func (c *countCacheClient) GetTabConfigData(ctx context.Context, req *protoctabcacheserver.GetTabConfigReq) (
+	*protoctabcacheserver.GetTabConfigResp, error) {
+	atomic.AddInt32(&c.count, 1)
+	time.Sleep(50 * time.Millisecond)
+	return c.Client.GetTabConfigData(ctx, req)
+}
+
+func TestInitLocalCacheConcurrent(t *testing.T) {
+	defer func() {
+		releaseLoops(t)
+		client.RegisterCacheClient(nil)
+	}()
+	start := time.Now()
+	cacheClient := &countCacheClient{Client: testdata.MockCacheClient(t)}
+	client.RegisterCacheClient(cacheClient)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				err = InitLocalCache(context.Background(), projectIDList)
+			} else {
+				err = InitLocalCacheIsolated(context.Background(), projectIDList)[projectID]
+			}
+			if err != nil {
+				t.Errorf("InitLocalCache() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.NotNil(t, GetApplication(projectID))
+	// a single load, and the first round of the single refresh coroutine at most
+	assert.True(t, atomic.LoadInt32(&cacheClient.count) <= 2)
+	var refreshers int
+	for _, task := range internal.Tasks() {
+		if task.Name == "refresh:"+projectID && !task.StartTime.Before(start) {
+			refreshers++
+		}
+	}
+	assert.Equal(t, 1, refreshers)
+}
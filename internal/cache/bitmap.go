@@ -0,0 +1,49 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"sync"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+// LazyBitmap The roaring bitmap of a bitmap bucket, the refresh only keeps the serialized buffer and the bitmap is
+// decoded on first access, so that the bitmaps of the rarely hit groups, such as the whitelists,
+// cost nothing on refresh. Concurrent and safe, the buffer must not be modified
+type LazyBitmap struct {
+	once   sync.Once
+	buffer []byte
+	bitmap *roaring.Bitmap
+	err    error
+}
+
+// NewLazyBitmap Create a lazy bitmap of the serialized buffer, see roaring.Bitmap.FromBuffer
+func NewLazyBitmap(buffer []byte) *LazyBitmap {
+	return &LazyBitmap{buffer: buffer}
+}
+
+// Bitmap Decode the bitmap on first access, the error of an invalid buffer is kept for the later accesses
+func (b *LazyBitmap) Bitmap() (*roaring.Bitmap, error) {
+	b.once.Do(func() {
+		bitmap := roaring.New()
+		_, err := bitmap.FromBuffer(b.buffer)
+		if err != nil {
+			b.err = errors.Wrap(err, "fromBuffer")
+			return
+		}
+		b.bitmap = bitmap
+	})
+	return b.bitmap, b.err
+}
+
+// ContainsInt Whether the bitmap contains x, false if the buffer is invalid
+func (b *LazyBitmap) ContainsInt(x int) bool {
+	bitmap, err := b.Bitmap()
+	if err != nil {
+		log.LimitedErrorf("lazyBitmap", "invalid bitmap:%v", err)
+		return false
+	}
+	return bitmap.ContainsInt(x)
+}
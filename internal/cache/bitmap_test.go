@@ -0,0 +1,40 @@
+// Package cache ...
+package cache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyBitmap(t *testing.T) {
+	tests := []struct {
+		name    string
+		buffer  []byte
+		x       int
+		want    bool
+		wantErr bool
+	}{
+		{name: "hit", buffer: testdata.MockGenBitmap(0, 100), x: 99, want: true},
+		{name: "miss", buffer: testdata.MockGenBitmap(0, 100), x: 100, want: false},
+		{name: "invalid buffer", buffer: []byte("invalid"), x: 1, want: false, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bitmap := NewLazyBitmap(tt.buffer)
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ { // decoded once by the concurrent first accesses
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					assert.Equal(t, tt.want, bitmap.ContainsInt(tt.x))
+				}()
+			}
+			wg.Wait()
+			_, err := bitmap.Bitmap()
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// reportConfigApplied Pass the change of the applied application to OnConfigApplied, nothing is reported if the
+// revision is the same. The previous one is nil on the first load
+func reportConfigApplied(previous *Application, application *Application) {
+	if internal.C.OnConfigApplied == nil {
+		return
+	}
+	if previous != nil && previous.Revision == application.Revision {
+		return
+	}
+	internal.ReportConfigApplied(diffApplication(previous, application))
+}
+
+// diffApplication The keys of the layers and the feature flags that differ between the applications, encoded through
+// encoding/json for the same reason as setupRevision
+func diffApplication(previous *Application, application *Application) *internal.ConfigChange {
+	change := &internal.ConfigChange{
+		ProjectID:  application.ProjectID,
+		Version:    application.Version,
+		Revision:   application.Revision,
+		UpdateTime: application.UpdateTime,
+	}
+	if previous == nil {
+		previous = &Application{}
+	}
+	change.PreviousRevision = previous.Revision
+	before, after := layerFingerprints(previous), layerFingerprints(application)
+	change.ChangedLayerKeys = diffFingerprints(before, after)
+	change.ChangedFeatureFlagKeys = diffFingerprints(featureFlagFingerprints(previous),
+		featureFlagFingerprints(application))
+	return change
+}
+
+// layerFingerprints key is layerKey, value is the encoding of the layer and the buckets of its experiments and groups
+func layerFingerprints(application *Application) map[string][]byte {
+	result := make(map[string][]byte, len(application.LayerIndex))
+	for layerKey, layer := range application.LayerIndex {
+		experimentBuckets := map[int64]*protoctabcacheserver.BucketInfo{}
+		groupBuckets := map[int64]*protoctabcacheserver.BucketInfo{}
+		if layer != nil {
+			for experimentID := range layer.ExperimentIndex {
+				experimentBuckets[experimentID] = application.ExperimentIDBucketInfoIndex[experimentID]
+			}
+			for groupID := range layer.GroupIndex {
+				groupBuckets[groupID] = application.GroupIDBucketInfoIndex[groupID]
+			}
+		}
+		data, err := json.Marshal([]interface{}{layer, experimentBuckets, groupBuckets})
+		if err != nil { // considered changed
+			data = nil
+		}
+		result[layerKey] = data
+	}
+	return result
+}
+
+// featureFlagFingerprints key is the feature flag key, value is the encoding of the feature flag
+func featureFlagFingerprints(application *Application) map[string][]byte {
+	if application.TabConfig == nil || application.TabConfig.ConfigData == nil {
+		return nil
+	}
+	result := make(map[string][]byte, len(application.TabConfig.ConfigData.RemoteConfigIndex))
+	for key, remoteConfig := range application.TabConfig.ConfigData.RemoteConfigIndex {
+		data, err := json.Marshal(remoteConfig)
+		if err != nil {
+			data = nil
+		}
+		result[key] = data
+	}
+	return result
+}
+
+// diffFingerprints The sorted keys added, removed or changed
+func diffFingerprints(before map[string][]byte, after map[string][]byte) []string {
+	var result []string
+	for key, data := range after {
+		previous, ok := before[key]
+		if !ok || data == nil || previous == nil || !bytes.Equal(previous, data) {
+			result = append(result, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			result = append(result, key)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,67 @@
+// Package cache ...
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+func Test_diffApplication(t *testing.T) {
+	newApplication := func(revision string, bucket []byte, flagValue []byte) *Application {
+		return &Application{
+			ProjectID: "123",
+			Revision:  revision,
+			TabConfig: &protoctabcacheserver.TabConfig{ConfigData: &protoctabcacheserver.RemoteConfigData{
+				RemoteConfigIndex: map[string]*protoctabcacheserver.RemoteConfig{
+					"flag":  {Key: "flag", DefaultValue: flagValue},
+					"other": {Key: "other"},
+				},
+			}},
+			LayerIndex: map[string]*protoctabcacheserver.Layer{
+				"layer": {GroupIndex: map[int64]*protoctabcacheserver.Group{1: {Id: 1}}},
+				"other": {GroupIndex: map[int64]*protoctabcacheserver.Group{2: {Id: 2}}},
+			},
+			GroupIDBucketInfoIndex: map[int64]*protoctabcacheserver.BucketInfo{
+				1: {BucketType: protoctabcacheserver.BucketType_BUCKET_TYPE_BITMAP, Bitmap: bucket},
+			},
+		}
+	}
+	removed := newApplication("r3", []byte{1}, []byte("a"))
+	delete(removed.LayerIndex, "other")
+	delete(removed.TabConfig.ConfigData.RemoteConfigIndex, "other")
+	tests := []struct {
+		name             string
+		previous         *Application
+		application      *Application
+		wantLayerKeys    []string
+		wantFeatureFlags []string
+	}{
+		{name: "first load", previous: nil, application: newApplication("r1", []byte{1}, []byte("a")),
+			wantLayerKeys: []string{"layer", "other"}, wantFeatureFlags: []string{"flag", "other"}},
+		{name: "same", previous: newApplication("r1", []byte{1}, []byte("a")),
+			application: newApplication("r1", []byte{1}, []byte("a"))},
+		{name: "bucket", previous: newApplication("r1", []byte{1}, []byte("a")),
+			application: newApplication("r2", []byte{2}, []byte("a")), wantLayerKeys: []string{"layer"}},
+		{name: "feature flag", previous: newApplication("r1", []byte{1}, []byte("a")),
+			application: newApplication("r2", []byte{1}, []byte("b")), wantFeatureFlags: []string{"flag"}},
+		{name: "removed", previous: newApplication("r1", []byte{1}, []byte("a")), application: removed,
+			wantLayerKeys: []string{"other"}, wantFeatureFlags: []string{"other"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffApplication(tt.previous, tt.application)
+			if got.Revision != tt.application.Revision || got.ProjectID != "123" {
+				t.Errorf("diffApplication() = %+v", got)
+			}
+			if !reflect.DeepEqual(got.ChangedLayerKeys, tt.wantLayerKeys) {
+				t.Errorf("diffApplication() ChangedLayerKeys = %v, want %v", got.ChangedLayerKeys, tt.wantLayerKeys)
+			}
+			if !reflect.DeepEqual(got.ChangedFeatureFlagKeys, tt.wantFeatureFlags) {
+				t.Errorf("diffApplication() ChangedFeatureFlagKeys = %v, want %v", got.ChangedFeatureFlagKeys,
+					tt.wantFeatureFlags)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// minSharedContentSize The payloads shorter than it are not worth an entry of the shared content, the short keys
+// are deduplicated by the stringInterner of each projectID
+const minSharedContentSize = 64
+
+// ruleKey The content of a compiled targeting rule
+type ruleKey struct {
+	tagType  protoctabcacheserver.TagType
+	operator protoctabcacheserver.Operator
+	value    string
+}
+
+type sharedValue struct {
+	value string // the content of the string, or the SHA-256 of the bytes
+	data  []byte // the content of the bytes
+	refs  int
+}
+
+type sharedRule struct {
+	matcher RuleMatcher
+	refs    int
+}
+
+// sharedContent The payloads and the compiled targeting rules of the configurations of all projectIDs, addressed by
+// their content. The projectIDs sharing identical config fragments, such as the same params or allowlists, hold one
+// copy of each, so that the memory scales with the unique content instead of the number of projectIDs.
+// Each projectID registers the content of its latest configuration, the content no longer registered by any
+// projectID is dropped from the table and freed once the configurations holding it are released
+var sharedContent = &contentTable{}
+
+type contentTable struct {
+	lock          sync.Mutex
+	values        map[string]*sharedValue // key is the content of the strings or the SHA-256 of the bytes
+	rules         map[ruleKey]*sharedRule
+	projectValues map[string]map[string]*sharedValue
+	projectRules  map[string]map[ruleKey]*sharedRule
+}
+
+// contentSession The content used by the configuration of a projectID being built, registered by commit.
+// The session of an empty owner, such as the static configuration, reuses the registered content only
+type contentSession struct {
+	table  *contentTable
+	owner  string
+	values map[string]*sharedValue
+	rules  map[ruleKey]*sharedRule
+}
+
+func (t *contentTable) begin(owner string) *contentSession {
+	return &contentSession{table: t, owner: owner, values: map[string]*sharedValue{},
+		rules: map[ruleKey]*sharedRule{}}
+}
+
+// loadValue The shared value of the content, stored if not yet and the session has an owner, the caller holds the lock
+func (s *contentSession) loadValue(content string) (*sharedValue, bool) {
+	t := s.table
+	entry, ok := t.values[content]
+	if !ok {
+		if len(s.owner) == 0 {
+			return nil, false
+		}
+		if t.values == nil {
+			t.values = map[string]*sharedValue{}
+		}
+		entry = &sharedValue{value: content}
+		t.values[content] = entry
+	}
+	if len(s.owner) > 0 {
+		s.values[entry.value] = entry
+	}
+	return entry, true
+}
+
+// str The shared copy of the string
+func (s *contentSession) str(value string) string {
+	if len(value) < minSharedContentSize {
+		return value
+	}
+	s.table.lock.Lock()
+	defer s.table.lock.Unlock()
+	entry, ok := s.loadValue(value)
+	if !ok {
+		return value
+	}
+	return entry.value
+}
+
+// bytes The shared copy of the bytes, which must not be modified. The bytes are addressed by their SHA-256, the
+// hashes never collide with the strings, which are longer
+func (s *contentSession) bytes(value []byte) []byte {
+	if len(value) < minSharedContentSize {
+		return value
+	}
+	sum := sha256.Sum256(value)
+	s.table.lock.Lock()
+	defer s.table.lock.Unlock()
+	entry, ok := s.loadValue(string(sum[:]))
+	if !ok {
+		return value
+	}
+	if entry.data == nil {
+		entry.data = value
+	}
+	return entry.data
+}
+
+// rule The shared compiled rule of the content, compiled by compile if not yet
+func (s *contentSession) rule(key ruleKey, compile func() RuleMatcher) RuleMatcher {
+	t := s.table
+	t.lock.Lock()
+	entry, ok := t.rules[key]
+	t.lock.Unlock()
+	if !ok {
+		matcher := compile() // outside the lock, compiling a rule may take a while
+		if len(s.owner) == 0 {
+			return matcher
+		}
+		t.lock.Lock()
+		if entry, ok = t.rules[key]; !ok { // not compiled by another projectID meanwhile
+			if t.rules == nil {
+				t.rules = map[ruleKey]*sharedRule{}
+			}
+			entry = &sharedRule{matcher: matcher}
+			t.rules[key] = entry
+		}
+		t.lock.Unlock()
+	}
+	if len(s.owner) > 0 {
+		s.rules[key] = entry
+	}
+	return entry.matcher
+}
+
+// commitValues Register the values used by the session as the ones of the owner, replacing the previous ones
+func (s *contentSession) commitValues() {
+	if len(s.owner) == 0 {
+		return
+	}
+	t := s.table
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.projectValues == nil {
+		t.projectValues = map[string]map[string]*sharedValue{}
+	}
+	previous := t.projectValues[s.owner]
+	for key, entry := range s.values {
+		if _, ok := previous[key]; ok {
+			continue
+		}
+		current, ok := t.values[key]
+		if !ok { // dropped by another projectID or reset before the commit
+			if t.values == nil {
+				t.values = map[string]*sharedValue{}
+			}
+			current = entry
+			t.values[key] = current
+		}
+		current.refs++
+	}
+	for key := range previous {
+		if _, ok := s.values[key]; ok {
+			continue
+		}
+		if entry, ok := t.values[key]; ok {
+			if entry.refs--; entry.refs <= 0 {
+				delete(t.values, key)
+			}
+		}
+	}
+	t.projectValues[s.owner] = s.values
+}
+
+// commitRules Register the rules used by the session as the ones of the owner, replacing the previous ones
+func (s *contentSession) commitRules() {
+	if len(s.owner) == 0 {
+		return
+	}
+	t := s.table
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.projectRules == nil {
+		t.projectRules = map[string]map[ruleKey]*sharedRule{}
+	}
+	previous := t.projectRules[s.owner]
+	for key, entry := range s.rules {
+		if _, ok := previous[key]; ok {
+			continue
+		}
+		current, ok := t.rules[key]
+		if !ok { // dropped by another projectID or reset before the commit
+			if t.rules == nil {
+				t.rules = map[ruleKey]*sharedRule{}
+			}
+			current = entry
+			t.rules[key] = current
+		}
+		current.refs++
+	}
+	for key := range previous {
+		if _, ok := s.rules[key]; ok {
+			continue
+		}
+		if entry, ok := t.rules[key]; ok {
+			if entry.refs--; entry.refs <= 0 {
+				delete(t.rules, key)
+			}
+		}
+	}
+	t.projectRules[s.owner] = s.rules
+}
+
+// SharedContentStats The statistics of the content shared by the configurations of all projectIDs
+type SharedContentStats struct {
+	// The number of the unique payloads and their total size in bytes
+	Values int   `json:"values"`
+	Bytes  int64 `json:"bytes"`
+	// The number of the unique compiled targeting rules
+	Rules int `json:"rules"`
+	// The number of the payloads and the rules used by the configurations, counting each projectID using them
+	References int `json:"references"`
+}
+
+// GetSharedContentStats The statistics of the shared content, see sharedContent
+func GetSharedContentStats() *SharedContentStats {
+	t := sharedContent
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	stats := &SharedContentStats{Values: len(t.values), Rules: len(t.rules)}
+	for _, entry := range t.values {
+		if entry.data != nil {
+			stats.Bytes += int64(len(entry.data))
+		} else {
+			stats.Bytes += int64(len(entry.value))
+		}
+		stats.References += entry.refs
+	}
+	for _, entry := range t.rules {
+		stats.References += entry.refs
+	}
+	return stats
+}
+
+// resetSharedContent Clear the shared content, called by Release
+func resetSharedContent() {
+	t := sharedContent
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.values, t.rules, t.projectValues, t.projectRules = nil, nil, nil, nil
+}
@@ -0,0 +1,84 @@
+// Package cache ...
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedContent(t *testing.T) {
+	resetSharedContent()
+	defer resetSharedContent()
+	params := strings.Repeat("p", minSharedContentSize)
+	value := strings.Repeat("v", minSharedContentSize)
+	allowlist := strings.Repeat("1;", minSharedContentSize)
+	newApplication := func(projectID string, params string) *Application {
+		tagList := &protoctabcacheserver.TagList{TagList: []*protoctabcacheserver.Tag{{Key: "uin",
+			TagType: protoctabcacheserver.TagType_TAG_TYPE_STRING, Operator: protoctabcacheserver.Operator_OPERATOR_IN,
+			Value: newString(allowlist)}}}
+		layer := &protoctabcacheserver.Layer{
+			Metadata: &protoctabcacheserver.LayerMetadata{Key: "layer"},
+			GroupIndex: map[int64]*protoctabcacheserver.Group{1: {Params: map[string]string{"p": newString(params)},
+				IssueInfo: &protoctabcacheserver.IssueInfo{
+					TagListGroup: []*protoctabcacheserver.TagList{tagList}}}},
+		}
+		tabConfig := &protoctabcacheserver.TabConfig{
+			ExperimentData: &protoctabcacheserver.ExperimentData{GlobalDomain: &protoctabcacheserver.Domain{
+				MultiLayerDomainList: []*protoctabcacheserver.MultiLayerDomain{{
+					LayerList: []*protoctabcacheserver.Layer{layer}}}}},
+			ConfigData: &protoctabcacheserver.RemoteConfigData{
+				RemoteConfigIndex: map[string]*protoctabcacheserver.RemoteConfig{
+					"config": {Key: "config", DefaultValue: []byte(value)},
+				},
+			},
+		}
+		application := &Application{ProjectID: projectID, TabConfig: tabConfig,
+			LayerIndex: map[string]*protoctabcacheserver.Layer{"layer": layer}}
+		internTabConfig(application, tabConfig)
+		setupRuleIndex(application)
+		return application
+	}
+	paramsOf := func(application *Application) string {
+		return application.LayerIndex["layer"].GroupIndex[1].Params["p"]
+	}
+	defaultValueOf := func(application *Application) []byte {
+		return application.TabConfig.ConfigData.RemoteConfigIndex["config"].DefaultValue
+	}
+	a := newApplication("a", params)
+	b := newApplication("b", params)
+	assert.Equal(t, stringData(paramsOf(a)), stringData(paramsOf(b)))
+	assert.Same(t, &defaultValueOf(a)[0], &defaultValueOf(b)[0])
+	tagA := a.LayerIndex["layer"].GroupIndex[1].IssueInfo.TagListGroup[0].TagList[0]
+	tagB := b.LayerIndex["layer"].GroupIndex[1].IssueInfo.TagListGroup[0].TagList[0]
+	assert.Equal(t, stringData(tagA.Value), stringData(tagB.Value))
+	assert.True(t, b.RuleIndex[b.LayerIndex["layer"].GroupIndex[1].IssueInfo.TagListGroup[0]][0]([]string{"1"}))
+	// params, default value and allowlist once each, the compiled allowlist once, all used by both
+	assert.Equal(t, &SharedContentStats{Values: 3, Bytes: int64(len(params) + len(value) + len(allowlist)),
+		Rules: 1, References: 8}, GetSharedContentStats())
+
+	// the static configuration reuses the shared content without registering
+	static := &Application{ProjectID: "c", isStatic: true}
+	tabConfig := &protoctabcacheserver.TabConfig{ConfigData: &protoctabcacheserver.RemoteConfigData{
+		RemoteConfigIndex: map[string]*protoctabcacheserver.RemoteConfig{
+			"config": {Key: "config", DefaultValue: []byte(value)},
+			"other":  {Key: "other", DefaultValue: []byte(strings.Repeat("o", minSharedContentSize))},
+		}}}
+	internTabConfig(static, tabConfig)
+	assert.Same(t, &defaultValueOf(a)[0], &tabConfig.ConfigData.RemoteConfigIndex["config"].DefaultValue[0])
+	assert.Equal(t, 3, GetSharedContentStats().Values)
+
+	// the content no longer used by any projectID is dropped
+	changed := strings.Repeat("c", minSharedContentSize)
+	newApplication("a", changed)
+	assert.Equal(t, 4, GetSharedContentStats().Values)
+	newApplication("b", changed)
+	stats := GetSharedContentStats()
+	assert.Equal(t, 3, stats.Values)
+	assert.Equal(t, int64(len(changed)+len(value)+len(allowlist)), stats.Bytes)
+
+	Release()
+	assert.Equal(t, &SharedContentStats{}, GetSharedContentStats())
+}
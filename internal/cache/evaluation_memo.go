@@ -0,0 +1,58 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"sort"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// setupMemoLayerIndex Find the layers whose evaluation may be memoized, nil if the memo is disabled. The evaluation
+// of the layer must only depend on the unit and the values of the attribute keys: no holdout, and no dmp tag or
+// time-window tag
+func setupMemoLayerIndex(application *Application) {
+	application.MemoLayerIndex = nil
+	if internal.C.EvaluationMemoSize <= 0 || internal.C.EvaluationMemoTTL <= 0 {
+		return
+	}
+	var index = make(map[string][]string, len(application.LayerIndex))
+	for layerKey, layer := range application.LayerIndex {
+		if keys, ok := layerAttributeKeys(layer); ok {
+			index[layerKey] = keys
+		}
+	}
+	application.MemoLayerIndex = index
+}
+
+// layerAttributeKeys The attribute keys the targeting of the groups of the layer depends on, sorted, ok if the
+// evaluation of the layer may be memoized
+func layerAttributeKeys(layer *protoctabcacheserver.Layer) ([]string, bool) {
+	if layer == nil || layer.Metadata == nil || len(layer.Metadata.HoldoutLayerKeys) != 0 {
+		return nil, false
+	}
+	var keys = make(map[string]bool)
+	for _, group := range layer.GroupIndex {
+		if group == nil || group.IssueInfo == nil {
+			continue
+		}
+		for _, tagList := range group.IssueInfo.TagListGroup {
+			if tagList == nil {
+				continue
+			}
+			for _, tag := range tagList.TagList {
+				if tag == nil || tag.TagType == protoctabcacheserver.TagType_TAG_TYPE_DMP ||
+					tag.Key == internal.NowAttributeKey {
+					return nil, false
+				}
+				keys[tag.Key] = true
+			}
+		}
+	}
+	var result = make([]string, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result, true
+}
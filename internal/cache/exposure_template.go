@@ -0,0 +1,44 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+)
+
+// ExposureTemplate The exposure fields that are constant per group, pre-computed when the configuration is loaded,
+// so that the exposure reporting only fills the fields of the user
+type ExposureTemplate struct {
+	GroupID       int64
+	LayerKey      string
+	ExperimentKey string
+	// The reported unit type, the decimal unitID type or the name of the custom type, see internal.UnitIDTypeString
+	UnitType string
+}
+
+// setupExposureTemplateIndex Build the exposure templates of all groups of the layers, including the holdout layers
+func setupExposureTemplateIndex(application *Application) {
+	var index = make(map[int64]*ExposureTemplate)
+	for _, layer := range application.LayerIndex {
+		for _, group := range layer.GroupIndex {
+			if group == nil {
+				continue
+			}
+			index[group.Id] = &ExposureTemplate{
+				GroupID:       group.Id,
+				LayerKey:      group.LayerKey,
+				ExperimentKey: group.ExperimentKey,
+				UnitType:      internal.UnitIDTypeString(int32(group.UnitIdType)),
+			}
+		}
+	}
+	application.ExposureTemplateIndex = index
+}
+
+// GetExposureTemplate Get the exposure template of the group, return nil if the configuration is not loaded or the
+// group is not in the layers, such as the group built by the SDK
+func GetExposureTemplate(application *Application, groupID int64) *ExposureTemplate {
+	if application == nil {
+		return nil
+	}
+	return application.ExposureTemplateIndex[groupID]
+}
@@ -0,0 +1,31 @@
+// Package cache ...
+package cache
+
+import (
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+func Test_setupExposureTemplateIndex(t *testing.T) {
+	application := &Application{
+		LayerIndex: map[string]*protoctabcacheserver.Layer{
+			"layer": {GroupIndex: map[int64]*protoctabcacheserver.Group{
+				1: {Id: 1, LayerKey: "layer", ExperimentKey: "exp",
+					UnitIdType: protoctabcacheserver.UnitIDType_UNIT_ID_TYPE_NEW_ID},
+				2: nil,
+			}},
+		},
+	}
+	setupExposureTemplateIndex(application)
+	got := GetExposureTemplate(application, 1)
+	if got == nil || got.LayerKey != "layer" || got.ExperimentKey != "exp" || got.UnitType != "2" {
+		t.Errorf("GetExposureTemplate() = %+v", got)
+	}
+	if got = GetExposureTemplate(application, 2); got != nil {
+		t.Errorf("GetExposureTemplate() = %+v, want nil", got)
+	}
+	if got = GetExposureTemplate(nil, 1); got != nil {
+		t.Errorf("GetExposureTemplate() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,36 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"sync/atomic"
+)
+
+// Freeze Pin the cached configuration of the projectID, the refreshes are skipped until Unfreeze, so that the
+// assignments stay stable while investigating an incident. Return whether the frozen state changed,
+// false if the projectID is not in the local cache. Release clears the frozen states with the cache
+func Freeze(projectID string) bool {
+	entry, ok := loadApplicationIndex()[projectID]
+	if !ok {
+		return false
+	}
+	return atomic.CompareAndSwapInt32(&entry.frozen, 0, 1)
+}
+
+// Unfreeze Resume the refreshes of the projectID pinned by Freeze, the next round of the refresh coroutine fetches
+// the latest configuration. Return whether the frozen state changed
+func Unfreeze(projectID string) bool {
+	entry, ok := loadApplicationIndex()[projectID]
+	if !ok {
+		return false
+	}
+	return atomic.CompareAndSwapInt32(&entry.frozen, 1, 0)
+}
+
+// IsFrozen Whether the cached configuration of the projectID is pinned by Freeze
+func IsFrozen(projectID string) bool {
+	entry, ok := loadApplicationIndex()[projectID]
+	if !ok {
+		return false
+	}
+	return atomic.LoadInt32(&entry.frozen) == 1
+}
@@ -0,0 +1,44 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeze(t *testing.T) {
+	defer func() {
+		client.RegisterCacheClient(nil)
+		Release()
+	}()
+	cacheClient := &countCacheClient{Client: testdata.MockCacheClient(t)}
+	client.RegisterCacheClient(cacheClient)
+	assert.False(t, Freeze(projectID)) // not loaded yet
+	application, err := NewAndSetApplication(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.True(t, Freeze(projectID))
+	assert.False(t, Freeze(projectID))
+	assert.True(t, IsFrozen(projectID))
+	count := atomic.LoadInt32(&cacheClient.count)
+	got, err := NewAndSetApplication(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.Same(t, application, got)
+	assert.Same(t, application, GetApplication(projectID))
+	assert.Equal(t, count, atomic.LoadInt32(&cacheClient.count))
+
+	assert.True(t, Unfreeze(projectID))
+	assert.False(t, Unfreeze(projectID))
+	assert.False(t, IsFrozen(projectID))
+	_, err = NewAndSetApplication(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.Equal(t, count+1, atomic.LoadInt32(&cacheClient.count))
+
+	assert.True(t, Freeze(projectID))
+	Release()
+	assert.False(t, IsFrozen(projectID))
+}
@@ -0,0 +1,65 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// IsSeededHashMethod Whether the hash of the method depends on the seed, only the BKDR hash, which is also the
+// default of the unknown methods, uses the seed
+func IsSeededHashMethod(hashMethod protoctabcacheserver.HashMethod) bool {
+	switch hashMethod {
+	case protoctabcacheserver.HashMethod_HASH_METHOD_AP, protoctabcacheserver.HashMethod_HASH_METHOD_DJB,
+		protoctabcacheserver.HashMethod_HASH_METHOD_NEW, protoctabcacheserver.HashMethod_HASH_METHOD_NEW_MD5:
+		return false
+	default:
+		return true
+	}
+}
+
+// setupHashSeedIndex Collect the distinct seeds of the seeded hashing of the domains, layers and experiments,
+// so that the evaluation of all layers hashes the unitID under all seeds in one batch
+func setupHashSeedIndex(application *Application) {
+	var (
+		index = make(map[int64]int)
+		seeds []uint64
+	)
+	add := func(hashMethod protoctabcacheserver.HashMethod, seed int64) {
+		if !IsSeededHashMethod(hashMethod) {
+			return
+		}
+		if _, ok := index[seed]; ok {
+			return
+		}
+		index[seed] = len(seeds)
+		seeds = append(seeds, uint64(seed))
+	}
+	addLayer := func(layer *protoctabcacheserver.Layer) {
+		if layer == nil || layer.Metadata == nil {
+			return
+		}
+		add(layer.Metadata.HashMethod, layer.Metadata.HashSeed)
+		for _, experiment := range layer.ExperimentIndex {
+			if experiment != nil {
+				add(experiment.HashMethod, experiment.HashSeed)
+			}
+		}
+	}
+	for _, metadataList := range application.LayerDomainMetadataListIndex {
+		for _, metadata := range metadataList {
+			if metadata != nil {
+				add(metadata.HashMethod, metadata.HashSeed)
+			}
+		}
+	}
+	for _, layer := range application.LayerIndex {
+		addLayer(layer)
+	}
+	if application.TabConfig != nil && application.TabConfig.ExperimentData != nil &&
+		application.TabConfig.ExperimentData.HoldoutData != nil {
+		for _, layer := range application.TabConfig.ExperimentData.HoldoutData.HoldoutLayerIndex {
+			addLayer(layer)
+		}
+	}
+	application.HashSeedIndex, application.HashSeeds = index, seeds
+}
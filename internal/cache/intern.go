@@ -0,0 +1,194 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// stringInterner Deduplicate the keys of the configuration, the same key repeated in the layers, groups and
+// conditions shares one backing memory. The strings of the previous configuration are reused first,
+// so the keys of the refreshed configuration point to the memory already held, and only the strings of the
+// current configuration are kept, the table does not grow with the number of refreshes
+type stringInterner struct {
+	previous map[string]string
+	current  map[string]string
+	// The payloads are shared with the configurations of the other projectIDs, see sharedContent
+	content *contentSession
+}
+
+func newStringInterner(previous map[string]string, content *contentSession) *stringInterner {
+	return &stringInterner{previous: previous, current: make(map[string]string, len(previous)), content: content}
+}
+
+func (i *stringInterner) intern(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	if v, ok := i.current[s]; ok {
+		return v
+	}
+	if v, ok := i.previous[s]; ok {
+		s = v
+	}
+	i.current[s] = s
+	return s
+}
+
+func (i *stringInterner) internList(list []string) {
+	for j := range list {
+		list[j] = i.intern(list[j])
+	}
+}
+
+// internParams The assignment of an existing key replaces the stored key with the interned one, and the value
+// with the shared one
+func (i *stringInterner) internParams(m map[string]string) {
+	for key, value := range m {
+		m[i.intern(key)] = i.content.str(value)
+	}
+}
+
+// internTabConfig Intern the keys of the newly fetched configuration before it is served, the configuration
+// must not be read concurrently. The intern table is kept on the application for the next refresh, the payloads
+// are replaced with the ones of the same content shared across the projectIDs
+func internTabConfig(application *Application, tabConfig *protoctabcacheserver.TabConfig) {
+	i := newStringInterner(application.internTable, sharedContent.begin(contentOwner(application)))
+	if experimentData := tabConfig.ExperimentData; experimentData != nil {
+		i.internDomain(experimentData.GlobalDomain)
+		if experimentData.HoldoutData != nil {
+			for _, layer := range experimentData.HoldoutData.HoldoutLayerIndex {
+				i.internLayer(layer)
+			}
+		}
+		for _, layerToGroupID := range experimentData.OverrideList {
+			if layerToGroupID == nil {
+				continue
+			}
+			for key, value := range layerToGroupID.LayerToGroupId {
+				layerToGroupID.LayerToGroupId[i.intern(key)] = value
+			}
+		}
+	}
+	if tabConfig.ConfigData != nil {
+		for _, remoteConfig := range tabConfig.ConfigData.RemoteConfigIndex {
+			i.internRemoteConfig(remoteConfig)
+		}
+	}
+	application.internTable = i.current
+	i.content.commitValues()
+}
+
+// contentOwner The owner of the shared content of the application, empty for the static configurations, which
+// reuse the shared content without registering their own
+func contentOwner(application *Application) string {
+	if application.isStatic {
+		return ""
+	}
+	return application.ProjectID
+}
+
+func (i *stringInterner) internDomain(domain *protoctabcacheserver.Domain) {
+	if domain == nil {
+		return
+	}
+	i.internDomainMetadata(domain.Metadata)
+	for _, holdoutDomain := range domain.HoldoutDomainList {
+		if holdoutDomain == nil {
+			continue
+		}
+		i.internDomainMetadata(holdoutDomain.Metadata)
+		for _, layer := range holdoutDomain.LayerList {
+			i.internLayer(layer)
+		}
+	}
+	for _, multiLayerDomain := range domain.MultiLayerDomainList {
+		if multiLayerDomain == nil {
+			continue
+		}
+		i.internDomainMetadata(multiLayerDomain.Metadata)
+		for _, layer := range multiLayerDomain.LayerList {
+			i.internLayer(layer)
+		}
+	}
+	for _, subdomain := range domain.DomainList {
+		i.internDomain(subdomain)
+	}
+}
+
+func (i *stringInterner) internDomainMetadata(metadata *protoctabcacheserver.DomainMetadata) {
+	if metadata == nil {
+		return
+	}
+	metadata.Key = i.intern(metadata.Key)
+}
+
+func (i *stringInterner) internLayer(layer *protoctabcacheserver.Layer) {
+	if layer == nil {
+		return
+	}
+	if layer.Metadata != nil {
+		layer.Metadata.Key = i.intern(layer.Metadata.Key)
+		i.internList(layer.Metadata.HoldoutLayerKeys)
+		i.internGroup(layer.Metadata.DefaultGroup)
+	}
+	for _, group := range layer.GroupIndex {
+		i.internGroup(group)
+	}
+	for _, experiment := range layer.ExperimentIndex {
+		if experiment == nil {
+			continue
+		}
+		experiment.Key = i.intern(experiment.Key)
+	}
+}
+
+func (i *stringInterner) internGroup(group *protoctabcacheserver.Group) {
+	if group == nil {
+		return
+	}
+	group.GroupKey = i.intern(group.GroupKey)
+	group.ExperimentKey = i.intern(group.ExperimentKey)
+	group.LayerKey = i.intern(group.LayerKey)
+	i.internParams(group.Params)
+	i.internIssueInfo(group.IssueInfo)
+}
+
+func (i *stringInterner) internIssueInfo(issueInfo *protoctabcacheserver.IssueInfo) {
+	if issueInfo == nil {
+		return
+	}
+	for _, tagList := range issueInfo.TagListGroup {
+		if tagList == nil {
+			continue
+		}
+		for _, tag := range tagList.TagList {
+			if tag == nil {
+				continue
+			}
+			tag.Key = i.intern(tag.Key)
+			tag.Value = i.content.str(tag.Value)
+		}
+	}
+}
+
+func (i *stringInterner) internRemoteConfig(remoteConfig *protoctabcacheserver.RemoteConfig) {
+	if remoteConfig == nil {
+		return
+	}
+	remoteConfig.Key = i.intern(remoteConfig.Key)
+	i.internList(remoteConfig.HoldoutLayerKeys)
+	remoteConfig.DefaultValue = i.content.bytes(remoteConfig.DefaultValue)
+	for unitID, value := range remoteConfig.OverrideList {
+		remoteConfig.OverrideList[unitID] = i.content.bytes(value)
+	}
+	for _, condition := range remoteConfig.ConditionList {
+		if condition == nil {
+			continue
+		}
+		condition.Key = i.intern(condition.Key)
+		condition.ExperimentKey = i.intern(condition.ExperimentKey)
+		condition.ConfigKey = i.intern(condition.ConfigKey)
+		condition.Value = i.content.bytes(condition.Value)
+		i.internIssueInfo(condition.IssueInfo)
+	}
+}
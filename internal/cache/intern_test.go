@@ -0,0 +1,68 @@
+// Package cache ...
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// newString A string with its own backing memory
+func newString(s string) string {
+	return string([]byte(s))
+}
+
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func Test_internTabConfig(t *testing.T) {
+	newTabConfig := func() *protoctabcacheserver.TabConfig {
+		return &protoctabcacheserver.TabConfig{
+			ExperimentData: &protoctabcacheserver.ExperimentData{
+				GlobalDomain: &protoctabcacheserver.Domain{
+					MultiLayerDomainList: []*protoctabcacheserver.MultiLayerDomain{{
+						LayerList: []*protoctabcacheserver.Layer{{
+							Metadata: &protoctabcacheserver.LayerMetadata{Key: newString("layer")},
+							GroupIndex: map[int64]*protoctabcacheserver.Group{
+								1: {LayerKey: newString("layer"), Params: map[string]string{newString("color"): "red"}},
+								2: {LayerKey: newString("layer"), Params: map[string]string{newString("color"): "blue"}},
+							},
+						}},
+					}},
+				},
+			},
+			ConfigData: &protoctabcacheserver.RemoteConfigData{
+				RemoteConfigIndex: map[string]*protoctabcacheserver.RemoteConfig{
+					"config": {Key: newString("config")},
+				},
+			},
+		}
+	}
+	application := &Application{}
+	tabConfig := newTabConfig()
+	internTabConfig(application, tabConfig)
+	layer := tabConfig.ExperimentData.GlobalDomain.MultiLayerDomainList[0].LayerList[0]
+	want := stringData(layer.Metadata.Key)
+	for id, group := range layer.GroupIndex {
+		if stringData(group.LayerKey) != want {
+			t.Errorf("group %v layerKey not interned", id)
+		}
+		for key := range group.Params {
+			if stringData(key) != stringData(application.internTable["color"]) {
+				t.Errorf("group %v param key not interned", id)
+			}
+		}
+	}
+	// the refreshed configuration reuses the strings of the previous one
+	refreshed := newTabConfig()
+	internTabConfig(application, refreshed)
+	if stringData(refreshed.ExperimentData.GlobalDomain.MultiLayerDomainList[0].LayerList[0].Metadata.Key) != want {
+		t.Errorf("refreshed layerKey not interned")
+	}
+	if len(application.internTable) != 3 { // layer, color, config
+		t.Errorf("internTable = %v", application.internTable)
+	}
+}
@@ -0,0 +1,203 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"strings"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// The built-in operators of the BCP-47 locale targeting of the string tags, beyond the operators of the protocol.
+// The tag values of the unit are locales such as "zh-Hant-TW", "en_US" or "pt-BR.UTF-8", parsed leniently, and the
+// config value is the list of the locales, languages, scripts or regions separated by ;
+const (
+	// OperatorLocale The locale falls back to one of the config locales, the fallback chain drops the region then the
+	// script, with the script inferred from the language and the region if absent, so that "zh-Hant" matches "zh-TW"
+	// and "zh-Hant-HK" but not "zh-CN", "zh" matches all of them, and "*" matches any valid locale
+	OperatorLocale protoctabcacheserver.Operator = 100
+	// OperatorLanguage The language of the locale is one of the config languages, such as "en;pt"
+	OperatorLanguage protoctabcacheserver.Operator = 101
+	// OperatorRegion The region of the locale is one of the config regions, such as "US;GB;419", the locales without
+	// a region never match
+	OperatorRegion protoctabcacheserver.Operator = 102
+	// OperatorScript The script of the locale, explicit or inferred, is one of the config scripts, such as "Hant"
+	OperatorScript protoctabcacheserver.Operator = 103
+)
+
+var localeOperatorNames = map[protoctabcacheserver.Operator]string{
+	OperatorLocale:   "OPERATOR_LOCALE",
+	OperatorLanguage: "OPERATOR_LANGUAGE",
+	OperatorRegion:   "OPERATOR_REGION",
+	OperatorScript:   "OPERATOR_SCRIPT",
+}
+
+// likelyScripts The scripts of the languages written in more than one script commonly, key is language or
+// language-REGION. The other languages have no inferred script, their locales match the config locales with a
+// script only if they carry the script explicitly
+var likelyScripts = map[string]string{
+	"zh":    "Hans",
+	"zh-TW": "Hant",
+	"zh-HK": "Hant",
+	"zh-MO": "Hant",
+	"sr":    "Cyrl",
+	"sr-ME": "Latn",
+	"pa":    "Guru",
+	"pa-PK": "Arab",
+	"uz":    "Latn",
+	"uz-AF": "Arab",
+	"az":    "Latn",
+	"bs":    "Latn",
+	"mn":    "Cyrl",
+}
+
+// OperatorName The name of the operator, including the built-in locale operators
+func OperatorName(operator protoctabcacheserver.Operator) string {
+	if name, ok := localeOperatorNames[operator]; ok {
+		return name
+	}
+	return operator.String()
+}
+
+// IsLocaleOperator Whether the operator is one of the built-in locale operators, see OperatorLocale
+func IsLocaleOperator(operator protoctabcacheserver.Operator) bool {
+	_, ok := localeOperatorNames[operator]
+	return ok
+}
+
+// locale The subtags of a BCP-47 locale used by the targeting, the extlangs, variants and extensions are ignored
+type locale struct {
+	language string // lower case
+	script   string // title case, explicit or inferred
+	region   string // upper case
+}
+
+// parseLocale Parse the locale leniently, the _ separator and the POSIX suffixes such as ".UTF-8" are accepted.
+// The script is not inferred, see inferScript
+func parseLocale(value string) (*locale, bool) {
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	subtags := strings.Split(strings.Replace(strings.TrimSpace(value), "_", "-", -1), "-")
+	if len(subtags[0]) < 2 || len(subtags[0]) > 8 || !isAlpha(subtags[0]) {
+		return nil, false
+	}
+	result := &locale{language: strings.ToLower(subtags[0])}
+	for i, subtag := range subtags[1:] {
+		switch {
+		case len(subtag) == 1: // the extensions and the private use
+			return result, true
+		case len(subtag) == 3 && isAlpha(subtag) && i == 0 && len(subtags[0]) <= 3: // extlang
+		case len(subtag) == 4 && isAlpha(subtag) && len(result.script) == 0 && len(result.region) == 0:
+			result.script = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		case (len(subtag) == 2 && isAlpha(subtag) || len(subtag) == 3 && isDigit(subtag)) && len(result.region) == 0:
+			result.region = strings.ToUpper(subtag)
+		case len(subtag) < 4 || len(subtag) > 8: // not a variant
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+// inferScript Fill the script inferred from the language and the region if absent, see likelyScripts
+func (l *locale) inferScript() *locale {
+	if len(l.script) > 0 {
+		return l
+	}
+	if script, ok := likelyScripts[l.language+"-"+l.region]; ok && len(l.region) > 0 {
+		l.script = script
+	} else {
+		l.script = likelyScripts[l.language]
+	}
+	return l
+}
+
+// fallbacks The fallback chain of the locale in lower case, such as zh-hant-tw, zh-tw, zh-hant, zh
+func (l *locale) fallbacks() []string {
+	language, script, region := l.language, strings.ToLower(l.script), strings.ToLower(l.region)
+	chain := make([]string, 0, 4)
+	if len(script) > 0 && len(region) > 0 {
+		chain = append(chain, language+"-"+script+"-"+region)
+	}
+	if len(region) > 0 {
+		chain = append(chain, language+"-"+region)
+	}
+	if len(script) > 0 {
+		chain = append(chain, language+"-"+script)
+	}
+	return append(chain, language)
+}
+
+// key The canonical form of the config locale in lower case
+func (l *locale) key() string {
+	key := l.language
+	if len(l.script) > 0 {
+		key += "-" + strings.ToLower(l.script)
+	}
+	if len(l.region) > 0 {
+		key += "-" + strings.ToLower(l.region)
+	}
+	return key
+}
+
+// compileLocaleRule Compile the rule of the built-in locale operators, the config values are normalized once
+func compileLocaleRule(operator protoctabcacheserver.Operator, configValue string) RuleMatcher {
+	set := make(map[string]bool)
+	for _, value := range strings.Split(configValue, ruleSplitSeg) {
+		value = strings.TrimSpace(value)
+		if operator == OperatorLocale && value != "*" {
+			config, ok := parseLocale(value) // without the inferred script, which the chain of the unit carries
+			if !ok {
+				continue
+			}
+			value = config.key()
+		}
+		set[strings.ToLower(value)] = true
+	}
+	var match func(l *locale) bool
+	switch operator {
+	case OperatorLocale:
+		match = func(l *locale) bool {
+			if set["*"] {
+				return true
+			}
+			for _, key := range l.fallbacks() {
+				if set[key] {
+					return true
+				}
+			}
+			return false
+		}
+	case OperatorLanguage:
+		match = func(l *locale) bool { return set[l.language] }
+	case OperatorRegion:
+		match = func(l *locale) bool { return len(l.region) > 0 && set[strings.ToLower(l.region)] }
+	case OperatorScript:
+		match = func(l *locale) bool { return len(l.script) > 0 && set[strings.ToLower(l.script)] }
+	default:
+		return never
+	}
+	return func(unitTagValue []string) bool {
+		return matchAll(unitTagValue, func(value string) bool {
+			l, ok := parseLocale(value)
+			return ok && match(l.inferScript())
+		})
+	}
+}
+
+func isAlpha(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
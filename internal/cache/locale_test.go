@@ -0,0 +1,67 @@
+// Package cache ...
+package cache
+
+import (
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocale(t *testing.T) {
+	for value, want := range map[string]*locale{
+		"en":               {language: "en"},
+		"en_us":            {language: "en", region: "US"},
+		"pt-BR.UTF-8":      {language: "pt", region: "BR"},
+		"zh-hant-tw":       {language: "zh", script: "Hant", region: "TW"},
+		"es-419":           {language: "es", region: "419"},
+		"zh-yue-HK":        {language: "zh", region: "HK"},
+		"de-DE-1996":       {language: "de", region: "DE"},
+		"en-US-u-ca-greg":  {language: "en", region: "US"},
+		"en-x":             {language: "en"},
+		"sr-Latn-RS@latin": {language: "sr", script: "Latn", region: "RS"},
+	} {
+		l, ok := parseLocale(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, want, l, value)
+	}
+	for _, value := range []string{"", "e", "1en", "en--US", "en-US-ab"} {
+		_, ok := parseLocale(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestCompileLocaleRule(t *testing.T) {
+	stringType := protoctabcacheserver.TagType_TAG_TYPE_STRING
+	cases := []struct {
+		operator    protoctabcacheserver.Operator
+		configValue string
+		hits        []string
+		misses      []string
+	}{
+		{OperatorLocale, "zh-Hant", []string{"zh-TW", "zh_HK", "zh-Hant", "zh-Hant-CN"}, []string{"zh-CN", "zh", "en"}},
+		{OperatorLocale, "zh", []string{"zh", "zh-CN", "zh-TW", "zh-Hans-SG"}, []string{"en", "yue"}},
+		{OperatorLocale, "en-GB;pt", []string{"en-GB", "en_gb.UTF-8", "pt", "pt-BR"}, []string{"en", "en-US"}},
+		{OperatorLocale, "zh-TW", []string{"zh-TW", "zh-Hant-TW"}, []string{"zh-HK", "zh-Hant"}},
+		{OperatorLocale, "sr-Latn", []string{"sr-Latn-RS", "sr-ME"}, []string{"sr", "sr-RS"}},
+		{OperatorLocale, "*", []string{"en", "fr-CA"}, []string{"", "1"}},
+		{OperatorLanguage, "en;PT", []string{"en", "en-US", "pt_BR"}, []string{"fr", "e"}},
+		{OperatorRegion, "us;419", []string{"en-US", "es-419"}, []string{"en", "en-GB"}},
+		{OperatorScript, "Hant", []string{"zh-TW", "zh-Hant-CN"}, []string{"zh-CN", "en-US"}},
+	}
+	for _, c := range cases {
+		matcher := CompileRule(stringType, c.operator, c.configValue)
+		for _, value := range c.hits {
+			assert.Truef(t, matcher([]string{value}), "%s %s %s", OperatorName(c.operator), c.configValue, value)
+		}
+		for _, value := range c.misses {
+			assert.Falsef(t, matcher([]string{value}), "%s %s %s", OperatorName(c.operator), c.configValue, value)
+		}
+	}
+	matcher := CompileRule(stringType, OperatorLanguage, "en")
+	assert.True(t, matcher([]string{"en-US", "en-GB"}))
+	assert.False(t, matcher([]string{"en-US", "fr"})) // each of the values, like the other string rules
+	assert.False(t, matcher(nil))
+	assert.Equal(t, "OPERATOR_LOCALE", OperatorName(OperatorLocale))
+	assert.Equal(t, "OPERATOR_EQ", OperatorName(protoctabcacheserver.Operator_OPERATOR_EQ))
+}
@@ -0,0 +1,49 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// setupExperimentMigrationIndex Index the old experiments of the migrations of the projectID by the IDs of the new
+// experiments, see internal.GlobalConfig.ExperimentKeyMigrations. The old experiment must stay in the configuration,
+// it needs no traffic, so that its hashing is known. The migrations whose experiments are missing or whose bucket
+// sizes differ are skipped, the new experiments are hashed as usual
+func setupExperimentMigrationIndex(application *Application) {
+	migrations := internal.C.ExperimentKeyMigrations[application.ProjectID]
+	if len(migrations) == 0 {
+		application.ExperimentMigrationIndex = nil
+		return
+	}
+	var experimentIndex = make(map[string]*protoctabcacheserver.Experiment)
+	for _, layer := range application.LayerIndex {
+		if layer == nil {
+			continue
+		}
+		for _, experiment := range layer.ExperimentIndex {
+			if experiment != nil && len(experiment.Key) > 0 {
+				experimentIndex[experiment.Key] = experiment
+			}
+		}
+	}
+	var index = make(map[int64]*protoctabcacheserver.Experiment, len(migrations))
+	for oldKey, newKey := range migrations {
+		oldExperiment, newExperiment := experimentIndex[oldKey], experimentIndex[newKey]
+		if oldExperiment == nil || newExperiment == nil {
+			log.LimitedErrorf("experimentMigration:"+application.ProjectID+":"+oldKey,
+				"[projectID=%v]experiment migration %v -> %v skipped, experiment not found", application.ProjectID,
+				oldKey, newKey)
+			continue
+		}
+		if oldExperiment.BucketSize != newExperiment.BucketSize {
+			log.LimitedErrorf("experimentMigration:"+application.ProjectID+":"+oldKey,
+				"[projectID=%v]experiment migration %v -> %v skipped, bucket size %v != %v", application.ProjectID,
+				oldKey, newKey, oldExperiment.BucketSize, newExperiment.BucketSize)
+			continue
+		}
+		index[newExperiment.Id] = oldExperiment
+	}
+	application.ExperimentMigrationIndex = index
+}
@@ -0,0 +1,54 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package cache Local cache implementation
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	metrics2 "github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+// manualFetchEvent Log local cache refresh events
+func manualFetchEvent(projectID string, latency time.Duration, err error) {
+	application := GetApplication(projectID)
+	if application == nil {
+		return
+	}
+	metricsConfig := application.TabConfig.ControlData.EventMetricsConfig
+	if metricsConfig == nil || !metricsConfig.IsEnable {
+		return
+	}
+	sendDataErr := metrics2.LogMonitorEvent(context.Background(), &metrics2.Metadata{
+		MetricsPluginName: metricsConfig.PluginName,
+		TableName:         metricsConfig.Metadata.Name,
+		TableID:           metricsConfig.Metadata.Id,
+		Token:             internal.EventToken(projectID, metricsConfig.Metadata.Token),
+		SamplingInterval:  metricsConfig.ErrSamplingInterval,
+	}, &protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+		{
+			Time:       time.Now().Unix(),
+			Ip:         "",
+			ProjectId:  projectID,
+			EventName:  "refresh",
+			Latency:    float32(latency.Microseconds()), // us
+			StatusCode: env.EventStatus(err),
+			Message:    env.ErrMsg(err),
+			SdkType:    env.SDKType,
+			SdkVersion: env.Version,
+			InvokePath: env.InvokePath(4), // Skip 4 levels of the call stack
+			InputData:  "",
+			OutputData: "",
+			ExtInfo:    internal.EventExtInfo(nil),
+		},
+	}})
+	if sendDataErr != nil {
+		log.Errorf("logMonitorEvent fail:%v", sendDataErr)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build abc_lite || abc_wasm
+// +build abc_lite abc_wasm
+
+// Package cache Local cache implementation
+package cache
+
+import "time"
+
+// manualFetchEvent The monitor events are compiled out in the lite build mode
+func manualFetchEvent(projectID string, latency time.Duration, err error) {}
@@ -0,0 +1,138 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// defaultRemoteConfigCacheSize The default maximum number of the cached results of the remote configs of
+// an application, see GlobalConfig.RemoteConfigCacheSize
+const defaultRemoteConfigCacheSize = 10000
+
+// RemoteConfigCache The results of the remote configs whose conditions only depend on the attributes of the unit,
+// keyed by the attribute fingerprint. It belongs to the application, so the refresh of the configuration drops it
+type RemoteConfigCache struct {
+	// The attribute keys the conditions depend on, sorted, key is the config key.
+	// The remote configs not in it are not cacheable
+	AttributeKeys map[string][]string
+	size          int64
+	count         int64
+	results       sync.Map
+}
+
+// setupRemoteConfigCache Find the cacheable remote configs, nil if the cache is disabled or none is cacheable
+func setupRemoteConfigCache(application *Application) {
+	application.RemoteConfigCache = nil
+	size := int64(internal.C.RemoteConfigCacheSize)
+	if size < 0 || application.TabConfig == nil || application.TabConfig.ConfigData == nil {
+		return
+	}
+	if size == 0 {
+		size = defaultRemoteConfigCacheSize
+	}
+	var attributeKeys = make(map[string][]string)
+	for key, config := range application.TabConfig.ConfigData.RemoteConfigIndex {
+		if keys, ok := remoteConfigAttributeKeys(config); ok {
+			attributeKeys[key] = keys
+		}
+	}
+	if len(attributeKeys) == 0 {
+		return
+	}
+	application.RemoteConfigCache = &RemoteConfigCache{AttributeKeys: attributeKeys, size: size}
+}
+
+// remoteConfigAttributeKeys The attribute keys the conditions of the config depend on, ok if the evaluation only
+// depends on them: no holdout, every condition takes the full traffic without binding an experiment, and no dmp tag
+// or time-window tag.
+// The override list depends on the unitID and is checked before the cache
+func remoteConfigAttributeKeys(config *protoctabcacheserver.RemoteConfig) ([]string, bool) {
+	if config == nil || len(config.HoldoutLayerKeys) != 0 {
+		return nil, false
+	}
+	var keys = make(map[string]bool)
+	for _, condition := range config.ConditionList {
+		if condition == nil || condition.IssueInfo == nil || len(condition.ExperimentKey) != 0 {
+			return nil, false
+		}
+		bucketInfo := condition.BucketInfo
+		if bucketInfo == nil || bucketInfo.BucketType != protoctabcacheserver.BucketType_BUCKET_TYPE_RANGE ||
+			bucketInfo.TrafficRange == nil || bucketInfo.TrafficRange.Left > 1 ||
+			bucketInfo.TrafficRange.Right < condition.BucketSize || condition.BucketSize <= 0 {
+			return nil, false
+		}
+		switch condition.IssueInfo.IssueType {
+		case protoctabcacheserver.IssueType_ISSUE_TYPE_PERCENTAGE:
+		case protoctabcacheserver.IssueType_ISSUE_TYPE_TAG, protoctabcacheserver.IssueType_ISSUE_TYPE_CITY_TAG:
+			for _, tagList := range condition.IssueInfo.TagListGroup {
+				if tagList == nil {
+					continue
+				}
+				for _, tag := range tagList.TagList {
+					// the time-window targeting depends on the time of the evaluation
+					if tag == nil || tag.TagType == protoctabcacheserver.TagType_TAG_TYPE_DMP ||
+						tag.Key == internal.NowAttributeKey {
+						return nil, false
+					}
+					keys[tag.Key] = true
+				}
+			}
+		default:
+			return nil, false
+		}
+	}
+	var result = make([]string, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result, true
+}
+
+// Fingerprint The cache key of the config key and the values of the attribute keys of the unit, ok if cacheable
+func (c *RemoteConfigCache) Fingerprint(key string, attributes map[string][]string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	attributeKeys, ok := c.AttributeKeys[key]
+	if !ok {
+		return "", false
+	}
+	var sb strings.Builder
+	sb.WriteString(key)
+	for _, attributeKey := range attributeKeys {
+		sb.WriteByte('\x00')
+		for i, value := range attributes[attributeKey] {
+			if i > 0 {
+				sb.WriteByte('\x01')
+			}
+			sb.WriteString(value)
+		}
+	}
+	return sb.String(), true
+}
+
+// Load Get the cached result of the fingerprint
+func (c *RemoteConfigCache) Load(fingerprint string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	return c.results.Load(fingerprint)
+}
+
+// Store Cache the result of the fingerprint, the result must not be modified afterwards.
+// Nothing is cached once the cache is full, until the refresh of the configuration
+func (c *RemoteConfigCache) Store(fingerprint string, result interface{}) {
+	if c == nil || atomic.LoadInt64(&c.count) >= c.size {
+		return
+	}
+	if _, loaded := c.results.LoadOrStore(fingerprint, result); !loaded {
+		atomic.AddInt64(&c.count, 1)
+	}
+}
@@ -0,0 +1,70 @@
+// Package cache ...
+package cache
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteConfigAttributeKeys(t *testing.T) {
+	newCondition := func(left, right int64, tags ...*protoctabcacheserver.Tag) *protoctabcacheserver.Condition {
+		issueInfo := &protoctabcacheserver.IssueInfo{IssueType: protoctabcacheserver.IssueType_ISSUE_TYPE_PERCENTAGE}
+		if len(tags) > 0 {
+			issueInfo = &protoctabcacheserver.IssueInfo{IssueType: protoctabcacheserver.IssueType_ISSUE_TYPE_TAG,
+				TagListGroup: []*protoctabcacheserver.TagList{{TagList: tags}}}
+		}
+		return &protoctabcacheserver.Condition{BucketSize: 100, IssueInfo: issueInfo,
+			BucketInfo: &protoctabcacheserver.BucketInfo{BucketType: protoctabcacheserver.BucketType_BUCKET_TYPE_RANGE,
+				TrafficRange: &protoctabcacheserver.TrafficRange{Left: left, Right: right}}}
+	}
+	country := &protoctabcacheserver.Tag{Key: "country", TagType: protoctabcacheserver.TagType_TAG_TYPE_STRING}
+	version := &protoctabcacheserver.Tag{Key: "version", TagType: protoctabcacheserver.TagType_TAG_TYPE_VERSION}
+	dmp := &protoctabcacheserver.Tag{Key: "dmp", TagType: protoctabcacheserver.TagType_TAG_TYPE_DMP}
+
+	keys, ok := remoteConfigAttributeKeys(&protoctabcacheserver.RemoteConfig{ConditionList: []*protoctabcacheserver.Condition{
+		newCondition(1, 100, version, country), newCondition(1, 100, country), newCondition(1, 100)}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"country", "version"}, keys)
+	_, ok = remoteConfigAttributeKeys(&protoctabcacheserver.RemoteConfig{
+		ConditionList: []*protoctabcacheserver.Condition{newCondition(1, 50, country)}}) // percentage rollout
+	assert.False(t, ok)
+	_, ok = remoteConfigAttributeKeys(&protoctabcacheserver.RemoteConfig{
+		ConditionList: []*protoctabcacheserver.Condition{newCondition(1, 100, dmp)}})
+	assert.False(t, ok)
+	now := &protoctabcacheserver.Tag{Key: internal.NowAttributeKey, TagType: protoctabcacheserver.TagType_TAG_TYPE_NUMBER}
+	_, ok = remoteConfigAttributeKeys(&protoctabcacheserver.RemoteConfig{
+		ConditionList: []*protoctabcacheserver.Condition{newCondition(1, 100, now)}}) // time window
+	assert.False(t, ok)
+	_, ok = remoteConfigAttributeKeys(&protoctabcacheserver.RemoteConfig{HoldoutLayerKeys: []string{"holdout"}})
+	assert.False(t, ok)
+}
+
+func TestRemoteConfigCache(t *testing.T) {
+	defer func() {
+		internal.C.RemoteConfigCacheSize = 0
+	}()
+	internal.C.RemoteConfigCacheSize = 1
+	application := &Application{TabConfig: &protoctabcacheserver.TabConfig{
+		ConfigData: &protoctabcacheserver.RemoteConfigData{RemoteConfigIndex: map[string]*protoctabcacheserver.RemoteConfig{
+			"config": {}}}}}
+	setupRemoteConfigCache(application)
+	c := application.RemoteConfigCache
+	fingerprint1, ok := c.Fingerprint("config", map[string][]string{"k": {"v"}})
+	assert.True(t, ok)
+	_, ok = c.Fingerprint("notCacheable", nil)
+	assert.False(t, ok)
+	c.Store(fingerprint1, 1)
+	c.Store("full", 2)
+	result, ok := c.Load(fingerprint1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, result)
+	_, ok = c.Load("full")
+	assert.False(t, ok)
+
+	var nilCache *RemoteConfigCache
+	_, ok = nilCache.Fingerprint("config", nil)
+	assert.False(t, ok)
+}
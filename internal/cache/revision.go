@@ -0,0 +1,32 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// revisionLength The number of hex characters kept of the sha256 revision hash
+const revisionLength = 16
+
+// setupRevision Compute the content hash of the configuration actually served, including the tab config and the
+// bucket information fetched separately, so that the replicas serving the same version but different buckets
+// can be told apart.
+// encoding/json sorts the map keys and does not touch the internal state of the messages served concurrently,
+// unlike the proto reflection
+func setupRevision(application *Application) error {
+	h := sha256.New()
+	encoder := json.NewEncoder(h)
+	for _, v := range []interface{}{application.Version, application.TabConfig,
+		application.ExperimentIDBucketInfoIndex, application.GroupIDBucketInfoIndex} {
+		err := encoder.Encode(v)
+		if err != nil {
+			return errors.Wrap(err, "encode")
+		}
+	}
+	application.Revision = hex.EncodeToString(h.Sum(nil))[:revisionLength]
+	return nil
+}
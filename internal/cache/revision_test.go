@@ -0,0 +1,47 @@
+// Package cache ...
+package cache
+
+import (
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+func Test_setupRevision(t *testing.T) {
+	newApplication := func(version string, bucket []byte) *Application {
+		return &Application{
+			Version:   version,
+			TabConfig: &protoctabcacheserver.TabConfig{},
+			GroupIDBucketInfoIndex: map[int64]*protoctabcacheserver.BucketInfo{
+				2: {BucketType: protoctabcacheserver.BucketType_BUCKET_TYPE_BITMAP, Bitmap: bucket},
+				1: nil,
+			},
+		}
+	}
+	tests := []struct {
+		name      string
+		a         *Application
+		b         *Application
+		wantEqual bool
+	}{
+		{name: "same", a: newApplication("1", []byte{1}), b: newApplication("1", []byte{1}), wantEqual: true},
+		{name: "version", a: newApplication("1", []byte{1}), b: newApplication("2", []byte{1}), wantEqual: false},
+		{name: "bucket", a: newApplication("1", []byte{1}), b: newApplication("1", []byte{2}), wantEqual: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := setupRevision(tt.a); err != nil {
+				t.Fatalf("setupRevision() error = %v", err)
+			}
+			if err := setupRevision(tt.b); err != nil {
+				t.Fatalf("setupRevision() error = %v", err)
+			}
+			if len(tt.a.Revision) != revisionLength {
+				t.Errorf("setupRevision() revision = %v", tt.a.Revision)
+			}
+			if (tt.a.Revision == tt.b.Revision) != tt.wantEqual {
+				t.Errorf("setupRevision() a = %v, b = %v, wantEqual %v", tt.a.Revision, tt.b.Revision, tt.wantEqual)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"regexp"
+	"strings"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/tagutil"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ruleSplitSeg      = ";" // the separator of the multiple config values, the same as tagutil
+	ruleRangeSplitSeg = ":" // the separator of the left and right of a number range, the same as tagutil
+)
+
+// RuleMatcher Whether the tag values of the unit hit a compiled targeting rule
+type RuleMatcher func(unitTagValue []string) bool
+
+// CompileRule Compile a targeting rule with its config value parsed once, so that the evaluation does not
+// split, parse or compile the config value again. The result is the same as tagutil.IsHit, the rules that are
+// not compiled natively evaluate with tagutil.IsHit. The dmp tags are evaluated at runtime and not compiled.
+// The built-in locale operators of the string tags, unknown to tagutil, are compiled here only, see OperatorLocale
+func CompileRule(tagType protoctabcacheserver.TagType, operator protoctabcacheserver.Operator,
+	configValue string) RuleMatcher {
+	var matcher RuleMatcher
+	switch tagType {
+	case protoctabcacheserver.TagType_TAG_TYPE_STRING:
+		matcher = compileStringRule(operator, configValue)
+	case protoctabcacheserver.TagType_TAG_TYPE_NUMBER:
+		matcher = compileNumberRule(operator, configValue)
+	case protoctabcacheserver.TagType_TAG_TYPE_SET:
+		matcher = compileSetRule(operator, configValue)
+	}
+	if matcher != nil {
+		return matcher
+	}
+	return func(unitTagValue []string) bool {
+		return tagutil.IsHit(tagType, operator, unitTagValue, configValue)
+	}
+}
+
+// matchAll Whether all the tag values satisfy match, false if the unit does not carry the tag
+func matchAll(unitTagValue []string, match func(value string) bool) bool {
+	if len(unitTagValue) == 0 {
+		return false
+	}
+	for i := range unitTagValue {
+		if !match(unitTagValue[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func never([]string) bool {
+	return false
+}
+
+func compileStringRule(operator protoctabcacheserver.Operator, configValue string) RuleMatcher {
+	var match func(value string) bool
+	switch operator {
+	case protoctabcacheserver.Operator_OPERATOR_EQ:
+		match = func(value string) bool { return value == configValue }
+	case protoctabcacheserver.Operator_OPERATOR_NE:
+		match = func(value string) bool { return value != configValue }
+	case protoctabcacheserver.Operator_OPERATOR_LT:
+		match = func(value string) bool { return value < configValue }
+	case protoctabcacheserver.Operator_OPERATOR_LTE:
+		match = func(value string) bool { return value <= configValue }
+	case protoctabcacheserver.Operator_OPERATOR_GT:
+		match = func(value string) bool { return value > configValue }
+	case protoctabcacheserver.Operator_OPERATOR_GTE:
+		match = func(value string) bool { return value >= configValue }
+	case protoctabcacheserver.Operator_OPERATOR_REGULAR:
+		re, err := regexp.Compile(configValue)
+		if err != nil { // tagutil never matches an invalid expression
+			return never
+		}
+		match = re.MatchString
+	case protoctabcacheserver.Operator_OPERATOR_IN:
+		set := newRuleValueSet(configValue)
+		match = func(value string) bool { return set[value] }
+	case protoctabcacheserver.Operator_OPERATOR_NOT_IN:
+		set := newRuleValueSet(configValue)
+		match = func(value string) bool { return !set[value] }
+	case OperatorLocale, OperatorLanguage, OperatorRegion, OperatorScript:
+		return compileLocaleRule(operator, configValue)
+	default:
+		return nil
+	}
+	return func(unitTagValue []string) bool {
+		return matchAll(unitTagValue, match)
+	}
+}
+
+func compileNumberRule(operator protoctabcacheserver.Operator, configValue string) RuleMatcher {
+	var match func(value decimal.Decimal) bool
+	switch operator {
+	case protoctabcacheserver.Operator_OPERATOR_EQ, protoctabcacheserver.Operator_OPERATOR_NE,
+		protoctabcacheserver.Operator_OPERATOR_LT, protoctabcacheserver.Operator_OPERATOR_LTE,
+		protoctabcacheserver.Operator_OPERATOR_GT, protoctabcacheserver.Operator_OPERATOR_GTE:
+		number, err := decimal.NewFromString(configValue)
+		if err != nil {
+			return never
+		}
+		match = compileNumberCompare(operator, number)
+	case protoctabcacheserver.Operator_OPERATOR_IN, protoctabcacheserver.Operator_OPERATOR_NOT_IN:
+		configValueList := strings.Split(configValue, ruleSplitSeg)
+		numberList := make([]decimal.Decimal, len(configValueList))
+		for i := range configValueList {
+			number, err := decimal.NewFromString(configValueList[i])
+			if err != nil {
+				return never
+			}
+			numberList[i] = number
+		}
+		isIn := operator == protoctabcacheserver.Operator_OPERATOR_IN
+		match = func(value decimal.Decimal) bool {
+			for i := range numberList {
+				if numberList[i].Equal(value) {
+					return isIn
+				}
+			}
+			return !isIn
+		}
+	case protoctabcacheserver.Operator_OPERATOR_LORO, protoctabcacheserver.Operator_OPERATOR_LORC,
+		protoctabcacheserver.Operator_OPERATOR_LCRO, protoctabcacheserver.Operator_OPERATOR_LCRC:
+		configValueRange := strings.Split(configValue, ruleRangeSplitSeg)
+		if len(configValueRange) != 2 {
+			return never
+		}
+		left, err := decimal.NewFromString(configValueRange[0])
+		if err != nil {
+			return never
+		}
+		right, err := decimal.NewFromString(configValueRange[1])
+		if err != nil {
+			return never
+		}
+		leftClosed := operator == protoctabcacheserver.Operator_OPERATOR_LCRO ||
+			operator == protoctabcacheserver.Operator_OPERATOR_LCRC
+		rightClosed := operator == protoctabcacheserver.Operator_OPERATOR_LORC ||
+			operator == protoctabcacheserver.Operator_OPERATOR_LCRC
+		match = func(value decimal.Decimal) bool {
+			leftCmp, rightCmp := value.Cmp(left), value.Cmp(right)
+			return (leftCmp > 0 || leftClosed && leftCmp == 0) && (rightCmp < 0 || rightClosed && rightCmp == 0)
+		}
+	default:
+		return nil
+	}
+	return func(unitTagValue []string) bool {
+		return matchAll(unitTagValue, func(value string) bool {
+			number, err := decimal.NewFromString(value)
+			return err == nil && match(number)
+		})
+	}
+}
+
+func compileNumberCompare(operator protoctabcacheserver.Operator,
+	number decimal.Decimal) func(value decimal.Decimal) bool {
+	switch operator {
+	case protoctabcacheserver.Operator_OPERATOR_EQ:
+		return number.Equal
+	case protoctabcacheserver.Operator_OPERATOR_NE:
+		return func(value decimal.Decimal) bool { return !value.Equal(number) }
+	case protoctabcacheserver.Operator_OPERATOR_LT:
+		return func(value decimal.Decimal) bool { return value.LessThan(number) }
+	case protoctabcacheserver.Operator_OPERATOR_LTE:
+		return func(value decimal.Decimal) bool { return value.LessThanOrEqual(number) }
+	case protoctabcacheserver.Operator_OPERATOR_GT:
+		return func(value decimal.Decimal) bool { return value.GreaterThan(number) }
+	default:
+		return func(value decimal.Decimal) bool { return value.GreaterThanOrEqual(number) }
+	}
+}
+
+func compileSetRule(operator protoctabcacheserver.Operator, configValue string) RuleMatcher {
+	configValueList := strings.Split(configValue, ruleSplitSeg)
+	set := newRuleValueSet(configValue)
+	isSubset := func(unitTagValue []string) bool {
+		for i := range unitTagValue { // the empty set is a subset of any set
+			if !set[unitTagValue[i]] {
+				return false
+			}
+		}
+		return true
+	}
+	isSuperset := func(unitTagValue []string) bool {
+		if len(configValue) == 0 { // tagutil always hits the empty config value
+			return true
+		}
+		for i := range configValueList {
+			if !containsRuleValue(unitTagValue, configValueList[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	switch operator {
+	case protoctabcacheserver.Operator_OPERATOR_SUB_SET:
+		return isSubset
+	case protoctabcacheserver.Operator_OPERATOR_SUPER_SET:
+		return isSuperset
+	case protoctabcacheserver.Operator_OPERATOR_EQ:
+		return func(unitTagValue []string) bool {
+			return isSubset(unitTagValue) && isSuperset(unitTagValue)
+		}
+	}
+	return nil
+}
+
+// newRuleValueSet The set of the config values separated by ;
+func newRuleValueSet(configValue string) map[string]bool {
+	configValueList := strings.Split(configValue, ruleSplitSeg)
+	set := make(map[string]bool, len(configValueList))
+	for _, value := range configValueList {
+		set[value] = true
+	}
+	return set
+}
+
+func containsRuleValue(unitTagValue []string, value string) bool {
+	for i := range unitTagValue {
+		if unitTagValue[i] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// setupRuleIndex Compile the targeting rules of the groups and the remote config conditions,
+// the rules of a tag list are compiled in the order of the tags, nil for the dmp tags
+func setupRuleIndex(application *Application) {
+	ruleIndex := make(map[*protoctabcacheserver.TagList][]RuleMatcher)
+	content := sharedContent.begin(contentOwner(application))
+	for _, layer := range application.LayerIndex {
+		compileLayerRules(ruleIndex, content, layer)
+	}
+	tabConfig := application.TabConfig
+	if tabConfig.ExperimentData != nil && tabConfig.ExperimentData.HoldoutData != nil {
+		for _, layer := range tabConfig.ExperimentData.HoldoutData.HoldoutLayerIndex {
+			compileLayerRules(ruleIndex, content, layer)
+		}
+	}
+	if tabConfig.ConfigData != nil {
+		for _, remoteConfig := range tabConfig.ConfigData.RemoteConfigIndex {
+			if remoteConfig == nil {
+				continue
+			}
+			for _, condition := range remoteConfig.ConditionList {
+				if condition != nil {
+					compileIssueInfoRules(ruleIndex, content, condition.IssueInfo)
+				}
+			}
+		}
+	}
+	content.commitRules()
+	application.RuleIndex = ruleIndex
+}
+
+func compileLayerRules(ruleIndex map[*protoctabcacheserver.TagList][]RuleMatcher, content *contentSession,
+	layer *protoctabcacheserver.Layer) {
+	if layer == nil {
+		return
+	}
+	for _, group := range layer.GroupIndex {
+		if group != nil {
+			compileIssueInfoRules(ruleIndex, content, group.IssueInfo)
+		}
+	}
+}
+
+// compileIssueInfoRules Compile the rules of the tag lists, the rules of the same content compiled for the other
+// projectIDs are reused, see sharedContent
+func compileIssueInfoRules(ruleIndex map[*protoctabcacheserver.TagList][]RuleMatcher, content *contentSession,
+	issueInfo *protoctabcacheserver.IssueInfo) {
+	if issueInfo == nil {
+		return
+	}
+	for _, tagList := range issueInfo.TagListGroup {
+		if tagList == nil {
+			continue
+		}
+		matcherList := make([]RuleMatcher, len(tagList.TagList))
+		for i, tag := range tagList.TagList {
+			if tag == nil || tag.TagType == protoctabcacheserver.TagType_TAG_TYPE_DMP {
+				continue
+			}
+			tagType, operator, value := tag.TagType, tag.Operator, tag.Value
+			matcherList[i] = content.rule(ruleKey{tagType: tagType, operator: operator, value: value},
+				func() RuleMatcher {
+					return CompileRule(tagType, operator, value)
+				})
+		}
+		ruleIndex[tagList] = matcherList
+	}
+}
@@ -0,0 +1,162 @@
+// Package cache ...
+package cache
+
+import (
+	"fmt"
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/tagutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// ruleOperators The operators of each tag type evaluated by tagutil, plus an unsupported one
+var ruleOperators = map[protoctabcacheserver.TagType][]protoctabcacheserver.Operator{
+	protoctabcacheserver.TagType_TAG_TYPE_STRING: {
+		protoctabcacheserver.Operator_OPERATOR_EQ, protoctabcacheserver.Operator_OPERATOR_NE,
+		protoctabcacheserver.Operator_OPERATOR_LT, protoctabcacheserver.Operator_OPERATOR_LTE,
+		protoctabcacheserver.Operator_OPERATOR_GT, protoctabcacheserver.Operator_OPERATOR_GTE,
+		protoctabcacheserver.Operator_OPERATOR_REGULAR, protoctabcacheserver.Operator_OPERATOR_IN,
+		protoctabcacheserver.Operator_OPERATOR_NOT_IN, protoctabcacheserver.Operator_OPERATOR_SUB_SET,
+	},
+	protoctabcacheserver.TagType_TAG_TYPE_NUMBER: {
+		protoctabcacheserver.Operator_OPERATOR_EQ, protoctabcacheserver.Operator_OPERATOR_NE,
+		protoctabcacheserver.Operator_OPERATOR_LT, protoctabcacheserver.Operator_OPERATOR_LTE,
+		protoctabcacheserver.Operator_OPERATOR_GT, protoctabcacheserver.Operator_OPERATOR_GTE,
+		protoctabcacheserver.Operator_OPERATOR_IN, protoctabcacheserver.Operator_OPERATOR_NOT_IN,
+		protoctabcacheserver.Operator_OPERATOR_LORO, protoctabcacheserver.Operator_OPERATOR_LORC,
+		protoctabcacheserver.Operator_OPERATOR_LCRO, protoctabcacheserver.Operator_OPERATOR_LCRC,
+		protoctabcacheserver.Operator_OPERATOR_REGULAR,
+	},
+	protoctabcacheserver.TagType_TAG_TYPE_SET: {
+		protoctabcacheserver.Operator_OPERATOR_SUB_SET, protoctabcacheserver.Operator_OPERATOR_SUPER_SET,
+		protoctabcacheserver.Operator_OPERATOR_EQ, protoctabcacheserver.Operator_OPERATOR_IN,
+	},
+	protoctabcacheserver.TagType_TAG_TYPE_VERSION: {
+		protoctabcacheserver.Operator_OPERATOR_EQ, protoctabcacheserver.Operator_OPERATOR_GT,
+		protoctabcacheserver.Operator_OPERATOR_LCRO,
+	},
+	protoctabcacheserver.TagType_TAG_TYPE_BOOLEAN: {protoctabcacheserver.Operator_OPERATOR_EQ},
+	protoctabcacheserver.TagType_TAG_TYPE_EMPTY: {
+		protoctabcacheserver.Operator_OPERATOR_EMPTY, protoctabcacheserver.Operator_OPERATOR_NOT_EMPTY,
+	},
+}
+
+func TestCompileRule(t *testing.T) {
+	configValues := []string{"", "a", "b", "a;b", "a;b;c", "^a", "[", "1", "1.5", "1;2;3", "1;x", "1:3", "3:1",
+		"1:x", "1:2:3", "1.2.3", "1.2.0:2.0.0", "true", "false"}
+	unitTagValues := [][]string{nil, {}, {""}, {"a"}, {"b"}, {"a", "b"}, {"b", "a", "c"}, {"abc"}, {"1"},
+		{"1.5"}, {"2"}, {"3"}, {"1", "2"}, {"x"}, {"1", "x"}, {"1.2.3"}, {"1.3.0"}, {"true"}, {"false"}}
+	for tagType, operators := range ruleOperators {
+		for _, operator := range operators {
+			for _, configValue := range configValues {
+				matcher := CompileRule(tagType, operator, configValue)
+				for _, unitTagValue := range unitTagValues {
+					want := tagutil.IsHit(tagType, operator, unitTagValue, configValue)
+					assert.Equalf(t, want, matcher(unitTagValue), "%v %v %q %q",
+						tagType, operator, configValue, unitTagValue)
+				}
+			}
+		}
+	}
+}
+
+func TestSetupRuleIndex(t *testing.T) {
+	stringTag := &protoctabcacheserver.Tag{Key: "k", TagType: protoctabcacheserver.TagType_TAG_TYPE_STRING,
+		Operator: protoctabcacheserver.Operator_OPERATOR_IN, Value: "a;b"}
+	dmpTag := &protoctabcacheserver.Tag{Key: "dmp", TagType: protoctabcacheserver.TagType_TAG_TYPE_DMP,
+		Operator: protoctabcacheserver.Operator_OPERATOR_TRUE, Value: "1"}
+	groupTagList := &protoctabcacheserver.TagList{TagList: []*protoctabcacheserver.Tag{dmpTag, stringTag}}
+	holdoutTagList := &protoctabcacheserver.TagList{TagList: []*protoctabcacheserver.Tag{stringTag}}
+	conditionTagList := &protoctabcacheserver.TagList{TagList: []*protoctabcacheserver.Tag{stringTag}}
+	newLayer := func(tagList *protoctabcacheserver.TagList) *protoctabcacheserver.Layer {
+		return &protoctabcacheserver.Layer{GroupIndex: map[int64]*protoctabcacheserver.Group{
+			1: {IssueInfo: &protoctabcacheserver.IssueInfo{
+				TagListGroup: []*protoctabcacheserver.TagList{tagList},
+			}},
+			2: {}, // no issue info
+		}}
+	}
+	application := &Application{
+		LayerIndex: map[string]*protoctabcacheserver.Layer{"layer": newLayer(groupTagList), "nil": nil},
+		TabConfig: &protoctabcacheserver.TabConfig{
+			ExperimentData: &protoctabcacheserver.ExperimentData{
+				HoldoutData: &protoctabcacheserver.HoldoutData{
+					HoldoutLayerIndex: map[string]*protoctabcacheserver.Layer{"holdout": newLayer(holdoutTagList)},
+				},
+			},
+			ConfigData: &protoctabcacheserver.RemoteConfigData{
+				RemoteConfigIndex: map[string]*protoctabcacheserver.RemoteConfig{
+					"config": {ConditionList: []*protoctabcacheserver.Condition{
+						{IssueInfo: &protoctabcacheserver.IssueInfo{
+							TagListGroup: []*protoctabcacheserver.TagList{conditionTagList},
+						}},
+						nil,
+					}},
+				},
+			},
+		},
+	}
+	setupRuleIndex(application)
+	assert.Equal(t, 3, len(application.RuleIndex))
+	matcherList := application.RuleIndex[groupTagList]
+	assert.Equal(t, 2, len(matcherList))
+	assert.Nil(t, matcherList[0]) // the dmp tags are evaluated at runtime
+	assert.True(t, matcherList[1]([]string{"b"}))
+	assert.False(t, matcherList[1]([]string{"c"}))
+	assert.NotNil(t, application.RuleIndex[holdoutTagList][0])
+	assert.NotNil(t, application.RuleIndex[conditionTagList][0])
+}
+
+// ruleHeavyTags The targeting rules of a rule-heavy group, the parsing of the config values dominates
+func ruleHeavyTags() []*protoctabcacheserver.Tag {
+	var tags []*protoctabcacheserver.Tag
+	for i := 0; i < 4; i++ {
+		tags = append(tags,
+			&protoctabcacheserver.Tag{TagType: protoctabcacheserver.TagType_TAG_TYPE_STRING,
+				Operator: protoctabcacheserver.Operator_OPERATOR_REGULAR, Value: `^user-[0-9]+$`},
+			&protoctabcacheserver.Tag{TagType: protoctabcacheserver.TagType_TAG_TYPE_STRING,
+				Operator: protoctabcacheserver.Operator_OPERATOR_IN, Value: "cn;us;sg;jp;kr;de;fr;gb;user-1"},
+			&protoctabcacheserver.Tag{TagType: protoctabcacheserver.TagType_TAG_TYPE_NUMBER,
+				Operator: protoctabcacheserver.Operator_OPERATOR_LCRO, Value: fmt.Sprintf("0:%d", 100+i)},
+			&protoctabcacheserver.Tag{TagType: protoctabcacheserver.TagType_TAG_TYPE_SET,
+				Operator: protoctabcacheserver.Operator_OPERATOR_SUPER_SET, Value: "a;b"},
+		)
+	}
+	return tags
+}
+
+// BenchmarkRule Evaluate the rules of a rule-heavy group interpreted by tagutil and compiled at config load
+func BenchmarkRule(b *testing.B) {
+	tags := ruleHeavyTags()
+	values := map[protoctabcacheserver.TagType][]string{
+		protoctabcacheserver.TagType_TAG_TYPE_STRING: {"user-1"},
+		protoctabcacheserver.TagType_TAG_TYPE_NUMBER: {"42"},
+		protoctabcacheserver.TagType_TAG_TYPE_SET:    {"a", "b", "c"},
+	}
+	b.Run("interpreted", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, tag := range tags {
+				if !tagutil.IsHit(tag.TagType, tag.Operator, values[tag.TagType], tag.Value) {
+					b.Fatal("not hit")
+				}
+			}
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		matcherList := make([]RuleMatcher, len(tags))
+		for i, tag := range tags {
+			matcherList[i] = CompileRule(tag.TagType, tag.Operator, tag.Value)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for j, tag := range tags {
+				if !matcherList[j](values[tag.TagType]) {
+					b.Fatal("not hit")
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,48 @@
+// Package cache Local cache implementation
+package cache
+
+import (
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/pkg/errors"
+)
+
+// NewStaticApplication Build the application from the snapshot without the cache service, which is neither set nor
+// refreshed, for the stateless evaluation. The indexes are the same as the ones built by NewAndSetApplication
+// from the same responses
+func NewStaticApplication(snapshot *client.Snapshot) (*Application, error) {
+	if snapshot == nil || len(snapshot.ProjectID) == 0 {
+		return nil, errors.Errorf("projectID is required")
+	}
+	if snapshot.TabConfig == nil || !validateTabConfig(snapshot.TabConfig) {
+		return nil, errors.Errorf("invalid tabConfig")
+	}
+	application := newEmptyApplication(snapshot.ProjectID)
+	application.isStatic = true
+	tabConfigManager := snapshot.TabConfig.TabConfigManager
+	internTabConfig(application, tabConfigManager.TabConfig)
+	application.TabConfig = tabConfigManager.TabConfig
+	application.Version = tabConfigManager.Version
+	err := setupLayerIndexes(application)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.ExperimentBucket != nil {
+		applyExperimentBucketInfo(application, snapshot.ExperimentBucket.BucketIndex)
+	}
+	if snapshot.GroupBucket != nil {
+		applyGroupBucketInfo(application, snapshot.GroupBucket.BucketIndex)
+	}
+	err = setupDMPTagInfo(application)
+	if err != nil {
+		return nil, errors.Wrap(err, "setupDMPTagInfo")
+	}
+	setupDerivedIndexes(application)
+	err = setupRevision(application)
+	if err != nil {
+		return nil, errors.Wrap(err, "setupRevision")
+	}
+	application.UpdateTime = time.Now()
+	return application, nil
+}
@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/abetterchoice/go-sdk/env"
@@ -57,11 +58,14 @@ const (
 	KeyES = "X-ES"
 )
 
-var (
-	// CacheClient Default background cache service client,
-	// Init defaults to using tab formal environment background cache service
-	CacheClient Client = nil
-)
+// cacheClient The registered background cache service client *cacheClientHolder, Init defaults to using tab formal
+// environment background cache service. It is read by the refresh coroutines and replaced by Init, so it is atomic
+var cacheClient atomic.Value
+
+// cacheClientHolder Hold the client in cacheClient, which does not store nil
+type cacheClientHolder struct {
+	client Client
+}
 
 func transport(addr string) http.RoundTripper {
 	if len(addr) == 0 {
@@ -101,9 +105,19 @@ func NewTABCacheClient(opts ...Option) Client {
 	return client
 }
 
-// RegisterCacheClient register client
+// RegisterCacheClient register client, nil unregisters it
 func RegisterCacheClient(client Client) {
-	CacheClient = client
+	cacheClient.Store(&cacheClientHolder{client: client})
+}
+
+// GetCacheClient The registered client, nil if none is registered. Read it once per refresh, so that all the
+// requests of a refresh are sent with the same client
+func GetCacheClient() Client {
+	holder, _ := cacheClient.Load().(*cacheClientHolder)
+	if holder == nil {
+		return nil
+	}
+	return holder.client
 }
 
 // Option client option
@@ -123,6 +137,13 @@ func WithEnvType(envType env.Type) Option {
 	}
 }
 
+// WithAddr Set the address of the cache service, such as a relay proxy, instead of the one of the environment
+func WithAddr(addr string) Option {
+	return func(client *tabCacheClient) {
+		client.addr = strings.TrimRight(addr, "/")
+	}
+}
+
 // tabCacheClient Background cache service implementation
 type tabCacheClient struct {
 	httpClient *http.Client
@@ -151,12 +172,17 @@ func (c *tabCacheClient) BatchGetExperimentBucketInfo(ctx context.Context,
 	for key, value := range headers {
 		httpReq.Header.Set(key, value)
 	}
-	authHeader(httpReq)
-	httpReq.Header.Set(KeyToken, internal.C.SecretKey)
+	secretKey, err := internal.ConfigSecretKey(ctx, req.ProjectId)
+	if err != nil {
+		return nil, errors.Wrap(err, "configSecretKey")
+	}
+	authHeader(httpReq, secretKey)
+	sent := time.Now()
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, errors.Wrap(err, "http do")
 	}
+	internal.RecordClockResponse(resp, sent)
 	defer resp.Body.Close()
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -192,12 +218,17 @@ func (c *tabCacheClient) BatchGetGroupBucketInfo(ctx context.Context,
 	for key, value := range headers {
 		httpReq.Header.Set(key, value)
 	}
-	authHeader(httpReq)
-	httpReq.Header.Set(KeyToken, internal.C.SecretKey)
+	secretKey, err := internal.ConfigSecretKey(ctx, req.ProjectId)
+	if err != nil {
+		return nil, errors.Wrap(err, "configSecretKey")
+	}
+	authHeader(httpReq, secretKey)
+	sent := time.Now()
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, errors.Wrap(err, "http do")
 	}
+	internal.RecordClockResponse(resp, sent)
 	defer resp.Body.Close()
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -230,12 +261,17 @@ func (c *tabCacheClient) GetTabConfigData(ctx context.Context, req *protoctabcac
 	for key, value := range headers {
 		httpReq.Header.Set(key, value)
 	}
-	authHeader(httpReq)
-	httpReq.Header.Set(KeyToken, internal.C.SecretKey)
+	secretKey, err := internal.ConfigSecretKey(ctx, req.ProjectId)
+	if err != nil {
+		return nil, errors.Wrap(err, "configSecretKey")
+	}
+	authHeader(httpReq, secretKey)
+	sent := time.Now()
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, errors.Wrap(err, "http do")
 	}
+	internal.RecordClockResponse(resp, sent)
 	defer resp.Body.Close()
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -258,6 +294,12 @@ var headers = map[string]string{
 	"X-Tab-Rpc-ServiceName": "opensource.tab.cache_server.APIServer",
 }
 
+// ValidateSecretKey Check the format of the secretKey, the accessKey is carried in its payload
+func ValidateSecretKey(secretKey string) error {
+	_, err := getAK(secretKey)
+	return err
+}
+
 func mustGetAK(secretKey string) string {
 	ak, _ := getAK(secretKey)
 	return ak
@@ -284,10 +326,12 @@ func genSign(secretKey, ak, timestamp string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(secretKey+ak+timestamp)))
 }
 
-func authHeader(req *http.Request) {
-	ak := mustGetAK(internal.C.SecretKey)
-	now := strconv.FormatInt(time.Now().Unix(), 10)
+// authHeader Set the authentication header, secretKey is resolved per projectID to support hot rotation
+func authHeader(req *http.Request, secretKey string) {
+	ak := mustGetAK(secretKey)
+	now := strconv.FormatInt(internal.Now().Unix(), 10)
+	req.Header.Set(KeyToken, secretKey)
 	req.Header.Set(KeyAK, ak)
 	req.Header.Set(KeyET, now)
-	req.Header.Set(KeyES, genSign(internal.C.SecretKey, ak, now))
+	req.Header.Set(KeyES, genSign(secretKey, ak, now))
 }
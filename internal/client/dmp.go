@@ -94,8 +94,11 @@ func (c *tabDMPClient) BatchGetDMPResultHTTP(ctx context.Context, req *protocdmp
 	for key, value := range dmpHeaders {
 		httpReq.Header.Set(key, value)
 	}
-	authHeader(httpReq)
-	httpReq.Header.Set(KeyToken, internal.C.SecretKey)
+	secretKey, err := internal.ConfigSecretKey(ctx, dmpProjectID(req))
+	if err != nil {
+		return nil, errors.Wrap(err, "configSecretKey")
+	}
+	authHeader(httpReq, secretKey)
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, errors.Wrap(err, "http do")
@@ -116,6 +119,14 @@ func (c *tabDMPClient) BatchGetDMPResultHTTP(ctx context.Context, req *protocdmp
 	return result, nil
 }
 
+// dmpProjectID The projectID used to resolve credentials, requests in one batch belong to the same projectID
+func dmpProjectID(req *protocdmpproxyserver.BatchGetDMPTagResultReq) string {
+	if req == nil || len(req.ReqList) == 0 || req.ReqList[0] == nil {
+		return ""
+	}
+	return req.ReqList[0].ProjectId
+}
+
 var dmpHeaders = map[string]string{
 	"Content-Type":          "application/proto",
 	"X-Tab-Rpc-ServiceName": "opensource.tab.dmp_proxy_server.APIServer",
@@ -0,0 +1,86 @@
+// Package client TODO
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// maxRequestSize The maximum size of the body of a request to the handler
+const maxRequestSize = 1 << 20
+
+// Authorizer Authorize the request of the projectID to the handler, the rejected requests get 401
+type Authorizer func(r *http.Request, projectID string) error
+
+// NewHandler Serve the protocol of the cache service from the client, such as a relay proxy serving the responses
+// recorded from the cache service to the SDK instances, which reach it as the cache service through WithAddr.
+// The requests rejected by authorize, if not nil, get 401, the errors of the client get 503
+func NewHandler(c Client, authorize Authorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case getTabConfigURI:
+			req := &protoctabcacheserver.GetTabConfigReq{}
+			serve(w, r, req, authorize, func(ctx context.Context) (proto.Message, error) {
+				return c.GetTabConfigData(ctx, req)
+			})
+		case batchGetExperimentBucketInfoURI:
+			req := &protoctabcacheserver.BatchGetExperimentBucketReq{}
+			serve(w, r, req, authorize, func(ctx context.Context) (proto.Message, error) {
+				return c.BatchGetExperimentBucketInfo(ctx, req)
+			})
+		case batchGetGroupBucketInfoURI:
+			req := &protoctabcacheserver.BatchGetGroupBucketReq{}
+			serve(w, r, req, authorize, func(ctx context.Context) (proto.Message, error) {
+				return c.BatchGetGroupBucketInfo(ctx, req)
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// projectRequest The requests of the cache service, all of which carry the projectID
+type projectRequest interface {
+	proto.Message
+	GetProjectId() string
+}
+
+func serve(w http.ResponseWriter, r *http.Request, req projectRequest, authorize Authorizer,
+	call func(ctx context.Context) (proto.Message, error)) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, errors.Wrap(err, "read body").Error(), http.StatusBadRequest)
+		return
+	}
+	if err = proto.Unmarshal(body, req); err != nil {
+		http.Error(w, errors.Wrap(err, "proto unmarshal").Error(), http.StatusBadRequest)
+		return
+	}
+	if authorize != nil {
+		if err = authorize(r, req.GetProjectId()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+	resp, err := call(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		http.Error(w, errors.Wrap(err, "proto marshal").Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", headers["Content-Type"])
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,66 @@
+// Package client ...
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	defer func(tabConfig, experimentBucket, groupBucket string) { // replaced by the mock servers of the other tests
+		getTabConfigURI, batchGetExperimentBucketInfoURI, batchGetGroupBucketInfoURI = tabConfig, experimentBucket,
+			groupBucket
+	}(getTabConfigURI, batchGetExperimentBucketInfoURI, batchGetGroupBucketInfoURI)
+	getTabConfigURI, batchGetExperimentBucketInfoURI, batchGetGroupBucketInfoURI = "/GetTabConfig",
+		"/BatchGetExperimentBucketInfo", "/BatchGetGroupBucketInfo"
+	c := NewMockClient(gomock.NewController(t))
+	handler := NewHandler(c, func(r *http.Request, projectID string) error {
+		if projectID == "denied" {
+			return errors.New("denied")
+		}
+		return nil
+	})
+	serve := func(method string, path string, req proto.Message) *httptest.ResponseRecorder {
+		body, _ := proto.Marshal(req)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, bytes.NewReader(body)))
+		return recorder
+	}
+
+	resp := &protoctabcacheserver.GetTabConfigResp{Code: protoctabcacheserver.Code_CODE_SUCCESS, Message: "ok"}
+	c.EXPECT().GetTabConfigData(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context,
+		req *protoctabcacheserver.GetTabConfigReq) (*protoctabcacheserver.GetTabConfigResp, error) {
+		assert.Equal(t, "123", req.ProjectId)
+		return resp, nil
+	})
+	recorder := serve(http.MethodPost, getTabConfigURI, &protoctabcacheserver.GetTabConfigReq{ProjectId: "123"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, headers["Content-Type"], recorder.Header().Get("Content-Type"))
+	got := &protoctabcacheserver.GetTabConfigResp{}
+	assert.Nil(t, proto.Unmarshal(recorder.Body.Bytes(), got))
+	assert.Equal(t, "ok", got.Message)
+
+	c.EXPECT().BatchGetGroupBucketInfo(gomock.Any(), gomock.Any()).Return(nil, errors.New("not loaded"))
+	recorder = serve(http.MethodPost, batchGetGroupBucketInfoURI,
+		&protoctabcacheserver.BatchGetGroupBucketReq{ProjectId: "123"})
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	recorder = serve(http.MethodPost, batchGetExperimentBucketInfoURI,
+		&protoctabcacheserver.BatchGetExperimentBucketReq{ProjectId: "denied"})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, getTabConfigURI, nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/unknown", nil).Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, getTabConfigURI, bytes.NewReader([]byte{0xff})))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
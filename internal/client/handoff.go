@@ -0,0 +1,88 @@
+// Package client TODO
+package client
+
+import (
+	"context"
+	"sync"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// handoffClient Serve the snapshots handed off by the previous process while the primary client fails to load the
+// projects, so that the process starts on the configuration of the previous one instead of failing Init
+type handoffClient struct {
+	primary   Client
+	snapshots Client
+	// Whether the projectID is loaded already, the refreshes of the loaded projects are never served by the snapshots
+	loaded     func(projectID string) bool
+	onFallback func(projectID string, err error)
+	lock       sync.Mutex
+	// The projectIDs whose tab config is served by the snapshots, the buckets of the same load are served as well
+	served map[string]bool
+}
+
+// NewHandoffClient Wrap the primary client with the snapshots handed off by the previous process, consulted when the
+// primary fails to load a projectID not loaded yet. onFallback, if not nil, is called with the error of the primary
+// each time a tab config is served by the snapshots
+func NewHandoffClient(primary Client, loaded func(projectID string) bool, onFallback func(projectID string, err error),
+	snapshots ...*Snapshot) Client {
+	return &handoffClient{primary: primary, snapshots: NewSnapshotClient(snapshots...), loaded: loaded,
+		onFallback: onFallback, served: map[string]bool{}}
+}
+
+// GetTabConfigData Implement Client
+func (c *handoffClient) GetTabConfigData(ctx context.Context, req *protoctabcacheserver.GetTabConfigReq) (
+	*protoctabcacheserver.GetTabConfigResp, error) {
+	resp, err := c.primary.GetTabConfigData(ctx, req)
+	if err == nil {
+		c.setServed(req.ProjectId, false)
+		return resp, nil
+	}
+	if errors.Is(err, context.Canceled) || c.loaded(req.ProjectId) {
+		return resp, err
+	}
+	resp, snapshotErr := c.snapshots.GetTabConfigData(ctx, req)
+	if snapshotErr != nil {
+		return nil, errors.Wrapf(snapshotErr, "handoff, primary:%v", err)
+	}
+	c.setServed(req.ProjectId, true)
+	if c.onFallback != nil {
+		c.onFallback(req.ProjectId, err)
+	}
+	return resp, nil
+}
+
+// BatchGetExperimentBucketInfo Implement Client, served by the snapshots if the tab config is
+func (c *handoffClient) BatchGetExperimentBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetExperimentBucketReq) (*protoctabcacheserver.BatchGetExperimentBucketResp, error) {
+	if c.isServed(req.ProjectId) {
+		return c.snapshots.BatchGetExperimentBucketInfo(ctx, req)
+	}
+	return c.primary.BatchGetExperimentBucketInfo(ctx, req)
+}
+
+// BatchGetGroupBucketInfo Implement Client, served by the snapshots if the tab config is
+func (c *handoffClient) BatchGetGroupBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetGroupBucketReq) (*protoctabcacheserver.BatchGetGroupBucketResp, error) {
+	if c.isServed(req.ProjectId) {
+		return c.snapshots.BatchGetGroupBucketInfo(ctx, req)
+	}
+	return c.primary.BatchGetGroupBucketInfo(ctx, req)
+}
+
+func (c *handoffClient) setServed(projectID string, served bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if served {
+		c.served[projectID] = true
+		return
+	}
+	delete(c.served, projectID)
+}
+
+func (c *handoffClient) isServed(projectID string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.served[projectID]
+}
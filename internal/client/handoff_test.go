@@ -0,0 +1,58 @@
+// Package client ...
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandoffClient(t *testing.T) {
+	controller := gomock.NewController(t)
+	primary := NewMockClient(controller)
+	down := errors.New("down")
+	snapshot := &Snapshot{ProjectID: "123",
+		TabConfig:        &protoctabcacheserver.GetTabConfigResp{Message: "handoff"},
+		ExperimentBucket: &protoctabcacheserver.BatchGetExperimentBucketResp{Message: "handoff"}}
+	loaded := map[string]bool{}
+	var fallbacks []string
+	c := NewHandoffClient(primary, func(projectID string) bool { return loaded[projectID] },
+		func(projectID string, err error) { fallbacks = append(fallbacks, projectID) }, snapshot)
+	req := &protoctabcacheserver.GetTabConfigReq{ProjectId: "123"}
+	bucketReq := &protoctabcacheserver.BatchGetExperimentBucketReq{ProjectId: "123"}
+
+	// the cache service is down at startup
+	primary.EXPECT().GetTabConfigData(gomock.Any(), req).Return(nil, down)
+	resp, err := c.GetTabConfigData(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, "handoff", resp.Message)
+	bucket, err := c.BatchGetExperimentBucketInfo(context.Background(), bucketReq) // the same load
+	assert.Nil(t, err)
+	assert.Equal(t, "handoff", bucket.Message)
+	assert.Equal(t, []string{"123"}, fallbacks)
+
+	// the refreshes of the loaded project are not served by the snapshots
+	loaded["123"] = true
+	primary.EXPECT().GetTabConfigData(gomock.Any(), req).Return(nil, down)
+	_, err = c.GetTabConfigData(context.Background(), req)
+	assert.Equal(t, down, err)
+	fromPrimary := &protoctabcacheserver.GetTabConfigResp{Message: "primary"}
+	primary.EXPECT().GetTabConfigData(gomock.Any(), req).Return(fromPrimary, nil)
+	resp, err = c.GetTabConfigData(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, fromPrimary, resp)
+	primary.EXPECT().BatchGetExperimentBucketInfo(gomock.Any(), bucketReq).Return(nil, down)
+	_, err = c.BatchGetExperimentBucketInfo(context.Background(), bucketReq)
+	assert.Equal(t, down, err)
+
+	// not in the snapshots
+	other := &protoctabcacheserver.GetTabConfigReq{ProjectId: "456"}
+	primary.EXPECT().GetTabConfigData(gomock.Any(), other).Return(nil, down)
+	_, err = c.GetTabConfigData(context.Background(), other)
+	assert.EqualError(t, err, "handoff, primary:down: projectID [456] is not in the snapshots")
+	assert.Len(t, fallbacks, 1)
+}
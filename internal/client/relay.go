@@ -0,0 +1,92 @@
+// Package client TODO
+package client
+
+import (
+	"context"
+	"sync/atomic"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// relayClient Read through to the relay once the primary client has failed threshold times in a row. Each request
+// is still sent to the primary first, so that the primary takes over again as soon as it recovers
+type relayClient struct {
+	primary   Client
+	relay     Client
+	threshold int32
+	failures  int32 // the consecutive failures of the primary, atomic
+	// Called when the relay starts serving after the failures of the primary, nil ignores it
+	onFallback func(err error)
+}
+
+// NewRelayClient Wrap the primary client with the relay, such as a relay proxy serving the cached configuration of
+// the cache service for many SDK instances, consulted once the primary has failed threshold times in a row.
+// onFallback, if not nil, is called with the error of the primary each time the relay starts serving
+func NewRelayClient(primary Client, relay Client, threshold int, onFallback func(err error)) Client {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &relayClient{primary: primary, relay: relay, threshold: int32(threshold), onFallback: onFallback}
+}
+
+// GetTabConfigData Implement Client
+func (c *relayClient) GetTabConfigData(ctx context.Context, req *protoctabcacheserver.GetTabConfigReq) (
+	*protoctabcacheserver.GetTabConfigResp, error) {
+	resp, err := c.primary.GetTabConfigData(ctx, req)
+	if !c.fallback(err) {
+		return resp, err
+	}
+	resp, relayErr := c.relay.GetTabConfigData(ctx, req)
+	return resp, c.relayed(err, relayErr)
+}
+
+// BatchGetExperimentBucketInfo Implement Client
+func (c *relayClient) BatchGetExperimentBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetExperimentBucketReq) (*protoctabcacheserver.BatchGetExperimentBucketResp, error) {
+	resp, err := c.primary.BatchGetExperimentBucketInfo(ctx, req)
+	if !c.fallback(err) {
+		return resp, err
+	}
+	resp, relayErr := c.relay.BatchGetExperimentBucketInfo(ctx, req)
+	return resp, c.relayed(err, relayErr)
+}
+
+// BatchGetGroupBucketInfo Implement Client
+func (c *relayClient) BatchGetGroupBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetGroupBucketReq) (*protoctabcacheserver.BatchGetGroupBucketResp, error) {
+	resp, err := c.primary.BatchGetGroupBucketInfo(ctx, req)
+	if !c.fallback(err) {
+		return resp, err
+	}
+	resp, relayErr := c.relay.BatchGetGroupBucketInfo(ctx, req)
+	return resp, c.relayed(err, relayErr)
+}
+
+// fallback Count the result of the primary, whether the relay is consulted. The cancellation of the caller is not
+// a failure of the primary
+func (c *relayClient) fallback(err error) bool {
+	if err == nil {
+		atomic.StoreInt32(&c.failures, 0)
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	failures := atomic.AddInt32(&c.failures, 1)
+	if failures < c.threshold {
+		return false
+	}
+	if failures == c.threshold && c.onFallback != nil {
+		c.onFallback(err)
+	}
+	return true
+}
+
+// relayed The error of the request served by the relay, nil if the relay succeeded
+func (c *relayClient) relayed(err error, relayErr error) error {
+	if relayErr == nil {
+		return nil
+	}
+	return errors.Wrapf(relayErr, "relay, primary:%v", err)
+}
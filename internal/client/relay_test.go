@@ -0,0 +1,54 @@
+// Package client ...
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelayClient(t *testing.T) {
+	controller := gomock.NewController(t)
+	primary, relay := NewMockClient(controller), NewMockClient(controller)
+	down := errors.New("down")
+	fromRelay := &protoctabcacheserver.GetTabConfigResp{Message: "relay"}
+	var fallbacks []error
+	c := NewRelayClient(primary, relay, 2, func(err error) { fallbacks = append(fallbacks, err) })
+	req := &protoctabcacheserver.GetTabConfigReq{ProjectId: "123"}
+
+	primary.EXPECT().GetTabConfigData(gomock.Any(), req).Return(nil, down).Times(3)
+	relay.EXPECT().GetTabConfigData(gomock.Any(), req).Return(fromRelay, nil).Times(2)
+	_, err := c.GetTabConfigData(context.Background(), req) // below the threshold
+	assert.Equal(t, down, err)
+	resp, err := c.GetTabConfigData(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, fromRelay, resp)
+	resp, err = c.GetTabConfigData(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, fromRelay, resp)
+	assert.Equal(t, []error{down}, fallbacks) // once per outage
+
+	// the primary recovers
+	fromPrimary := &protoctabcacheserver.GetTabConfigResp{Message: "primary"}
+	primary.EXPECT().GetTabConfigData(gomock.Any(), req).Return(fromPrimary, nil)
+	resp, err = c.GetTabConfigData(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, fromPrimary, resp)
+	primary.EXPECT().BatchGetGroupBucketInfo(gomock.Any(), gomock.Any()).Return(nil, down)
+	_, err = c.BatchGetGroupBucketInfo(context.Background(), &protoctabcacheserver.BatchGetGroupBucketReq{})
+	assert.Equal(t, down, err) // the failures are counted again from zero
+
+	primary.EXPECT().BatchGetExperimentBucketInfo(gomock.Any(), gomock.Any()).Return(nil, down)
+	relay.EXPECT().BatchGetExperimentBucketInfo(gomock.Any(), gomock.Any()).Return(nil, errors.New("relay down"))
+	_, err = c.BatchGetExperimentBucketInfo(context.Background(), &protoctabcacheserver.BatchGetExperimentBucketReq{})
+	assert.EqualError(t, err, "relay, primary:down: relay down")
+	assert.Len(t, fallbacks, 2)
+
+	primary.EXPECT().GetTabConfigData(gomock.Any(), req).Return(nil, context.Canceled)
+	_, err = c.GetTabConfigData(context.Background(), req) // not a failure of the primary
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,226 @@
+// Package client TODO
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// snapshotVersion The version of the snapshot encoding, bumped on incompatible changes
+const snapshotVersion = 1
+
+// Snapshot The responses of the cache service for a project, enough to rebuild the local cache of the project
+// without the cache service, such as the local evaluation of abcctl
+type Snapshot struct {
+	ProjectID        string
+	TabConfig        *protoctabcacheserver.GetTabConfigResp
+	ExperimentBucket *protoctabcacheserver.BatchGetExperimentBucketResp
+	// nil if the project has no group bucket
+	GroupBucket *protoctabcacheserver.BatchGetGroupBucketResp
+}
+
+// snapshotFile The encoding of the snapshot, the responses are kept in the wire format of the cache service
+type snapshotFile struct {
+	Version          int    `json:"version"`
+	ProjectID        string `json:"projectId"`
+	TabConfig        []byte `json:"tabConfig"`
+	ExperimentBucket []byte `json:"experimentBucket,omitempty"`
+	GroupBucket      []byte `json:"groupBucket,omitempty"`
+}
+
+// MarshalSnapshot Encode the snapshot, see UnmarshalSnapshot
+func MarshalSnapshot(snapshot *Snapshot) ([]byte, error) {
+	if snapshot == nil || snapshot.TabConfig == nil {
+		return nil, errors.Errorf("tabConfig is required")
+	}
+	file := &snapshotFile{Version: snapshotVersion, ProjectID: snapshot.ProjectID}
+	var err error
+	if file.TabConfig, err = proto.Marshal(snapshot.TabConfig); err != nil {
+		return nil, errors.Wrap(err, "tabConfig")
+	}
+	if snapshot.ExperimentBucket != nil {
+		if file.ExperimentBucket, err = proto.Marshal(snapshot.ExperimentBucket); err != nil {
+			return nil, errors.Wrap(err, "experimentBucket")
+		}
+	}
+	if snapshot.GroupBucket != nil {
+		if file.GroupBucket, err = proto.Marshal(snapshot.GroupBucket); err != nil {
+			return nil, errors.Wrap(err, "groupBucket")
+		}
+	}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// UnmarshalSnapshot Decode the snapshot encoded by MarshalSnapshot
+func UnmarshalSnapshot(data []byte) (*Snapshot, error) {
+	file := &snapshotFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	if file.Version != snapshotVersion {
+		return nil, errors.Errorf("unsupported snapshot version %d", file.Version)
+	}
+	if len(file.ProjectID) == 0 {
+		return nil, errors.Errorf("projectId is required")
+	}
+	snapshot := &Snapshot{ProjectID: file.ProjectID, TabConfig: &protoctabcacheserver.GetTabConfigResp{}}
+	if err := proto.Unmarshal(file.TabConfig, snapshot.TabConfig); err != nil {
+		return nil, errors.Wrap(err, "tabConfig")
+	}
+	if len(file.ExperimentBucket) > 0 {
+		snapshot.ExperimentBucket = &protoctabcacheserver.BatchGetExperimentBucketResp{}
+		if err := proto.Unmarshal(file.ExperimentBucket, snapshot.ExperimentBucket); err != nil {
+			return nil, errors.Wrap(err, "experimentBucket")
+		}
+	}
+	if len(file.GroupBucket) > 0 {
+		snapshot.GroupBucket = &protoctabcacheserver.BatchGetGroupBucketResp{}
+		if err := proto.Unmarshal(file.GroupBucket, snapshot.GroupBucket); err != nil {
+			return nil, errors.Wrap(err, "groupBucket")
+		}
+	}
+	return snapshot, nil
+}
+
+// RecordingClient The client recording the successful responses of the wrapped client into the snapshots,
+// register it as the cache client, then take the snapshots once the projects are loaded. Concurrent and safe
+type RecordingClient struct {
+	client    Client
+	lock      sync.Mutex
+	snapshots map[string]*Snapshot
+}
+
+// NewRecordingClient Create the client recording the responses of the client
+func NewRecordingClient(client Client) *RecordingClient {
+	return &RecordingClient{client: client, snapshots: map[string]*Snapshot{}}
+}
+
+// Snapshot The last responses recorded for the projectID, nil if the tab config is not fetched yet
+func (r *RecordingClient) Snapshot(projectID string) *Snapshot {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	snapshot, ok := r.snapshots[projectID]
+	if !ok || snapshot.TabConfig == nil {
+		return nil
+	}
+	result := *snapshot
+	return &result
+}
+
+func (r *RecordingClient) record(projectID string, fn func(snapshot *Snapshot)) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	snapshot, ok := r.snapshots[projectID]
+	if !ok {
+		snapshot = &Snapshot{ProjectID: projectID}
+		r.snapshots[projectID] = snapshot
+	}
+	fn(snapshot)
+}
+
+// GetTabConfigData Get and record the tab config, the same version responses are not recorded
+func (r *RecordingClient) GetTabConfigData(ctx context.Context, req *protoctabcacheserver.GetTabConfigReq) (
+	*protoctabcacheserver.GetTabConfigResp, error) {
+	resp, err := r.client.GetTabConfigData(ctx, req)
+	if err != nil || resp == nil || resp.Code != protoctabcacheserver.Code_CODE_SUCCESS {
+		return resp, err
+	}
+	r.record(req.ProjectId, func(snapshot *Snapshot) {
+		snapshot.TabConfig = resp
+	})
+	return resp, nil
+}
+
+// BatchGetExperimentBucketInfo Get and record the experiment bucket
+func (r *RecordingClient) BatchGetExperimentBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetExperimentBucketReq) (*protoctabcacheserver.BatchGetExperimentBucketResp, error) {
+	resp, err := r.client.BatchGetExperimentBucketInfo(ctx, req)
+	if err != nil || resp == nil || resp.Code != protoctabcacheserver.Code_CODE_SUCCESS {
+		return resp, err
+	}
+	r.record(req.ProjectId, func(snapshot *Snapshot) {
+		snapshot.ExperimentBucket = resp
+	})
+	return resp, nil
+}
+
+// BatchGetGroupBucketInfo Get and record the group bucket
+func (r *RecordingClient) BatchGetGroupBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetGroupBucketReq) (*protoctabcacheserver.BatchGetGroupBucketResp, error) {
+	resp, err := r.client.BatchGetGroupBucketInfo(ctx, req)
+	if err != nil || resp == nil || resp.Code != protoctabcacheserver.Code_CODE_SUCCESS {
+		return resp, err
+	}
+	r.record(req.ProjectId, func(snapshot *Snapshot) {
+		snapshot.GroupBucket = resp
+	})
+	return resp, nil
+}
+
+// snapshotClient The client serving the snapshots instead of the cache service
+type snapshotClient struct {
+	index map[string]*Snapshot
+}
+
+// NewSnapshotClient Create the client serving the snapshots, one per projectID,
+// the projects are served with the same version as long as the process runs
+func NewSnapshotClient(snapshots ...*Snapshot) Client {
+	c := &snapshotClient{index: make(map[string]*Snapshot, len(snapshots))}
+	for _, snapshot := range snapshots {
+		c.index[snapshot.ProjectID] = snapshot
+	}
+	return c
+}
+
+func (c *snapshotClient) snapshot(projectID string) (*Snapshot, error) {
+	snapshot, ok := c.index[projectID]
+	if !ok {
+		return nil, errors.Errorf("projectID [%s] is not in the snapshots", projectID)
+	}
+	return snapshot, nil
+}
+
+// GetTabConfigData The tab config of the snapshot, the same version once the version is served
+func (c *snapshotClient) GetTabConfigData(ctx context.Context, req *protoctabcacheserver.GetTabConfigReq) (
+	*protoctabcacheserver.GetTabConfigResp, error) {
+	snapshot, err := c.snapshot(req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if manager := snapshot.TabConfig.TabConfigManager; len(req.Version) > 0 && manager != nil &&
+		req.Version == manager.Version {
+		return &protoctabcacheserver.GetTabConfigResp{Code: protoctabcacheserver.Code_CODE_SAME_VERSION}, nil
+	}
+	return snapshot.TabConfig, nil
+}
+
+// BatchGetExperimentBucketInfo The experiment bucket of the snapshot
+func (c *snapshotClient) BatchGetExperimentBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetExperimentBucketReq) (*protoctabcacheserver.BatchGetExperimentBucketResp, error) {
+	snapshot, err := c.snapshot(req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.ExperimentBucket == nil {
+		return &protoctabcacheserver.BatchGetExperimentBucketResp{Code: protoctabcacheserver.Code_CODE_SUCCESS}, nil
+	}
+	return snapshot.ExperimentBucket, nil
+}
+
+// BatchGetGroupBucketInfo The group bucket of the snapshot
+func (c *snapshotClient) BatchGetGroupBucketInfo(ctx context.Context,
+	req *protoctabcacheserver.BatchGetGroupBucketReq) (*protoctabcacheserver.BatchGetGroupBucketResp, error) {
+	snapshot, err := c.snapshot(req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.GroupBucket == nil {
+		return &protoctabcacheserver.BatchGetGroupBucketResp{Code: protoctabcacheserver.Code_CODE_SUCCESS}, nil
+	}
+	return snapshot.GroupBucket, nil
+}
@@ -0,0 +1,56 @@
+// Package client ...
+package client
+
+import (
+	"context"
+	"testing"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	tabConfig := &protoctabcacheserver.GetTabConfigResp{Code: protoctabcacheserver.Code_CODE_SUCCESS,
+		TabConfigManager: &protoctabcacheserver.TabConfigManager{ProjectId: "p", Version: "v1"}}
+	experimentBucket := &protoctabcacheserver.BatchGetExperimentBucketResp{Code: protoctabcacheserver.Code_CODE_SUCCESS,
+		BucketIndex: map[int64]*protoctabcacheserver.BucketInfo{1: {Version: "b1"}}}
+	mockClient := NewMockClient(gomock.NewController(t))
+	mockClient.EXPECT().GetTabConfigData(gomock.Any(), gomock.Any()).Return(tabConfig, nil)
+	mockClient.EXPECT().BatchGetExperimentBucketInfo(gomock.Any(), gomock.Any()).Return(experimentBucket, nil)
+	ctx := context.Background()
+	recorder := NewRecordingClient(mockClient)
+	assert.Nil(t, recorder.Snapshot("p"))
+	_, err := recorder.GetTabConfigData(ctx, &protoctabcacheserver.GetTabConfigReq{ProjectId: "p"})
+	assert.Nil(t, err)
+	_, err = recorder.BatchGetExperimentBucketInfo(ctx, &protoctabcacheserver.BatchGetExperimentBucketReq{ProjectId: "p"})
+	assert.Nil(t, err)
+	data, err := MarshalSnapshot(recorder.Snapshot("p"))
+	assert.Nil(t, err)
+
+	snapshot, err := UnmarshalSnapshot(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "p", snapshot.ProjectID)
+	assert.True(t, proto.Equal(tabConfig, snapshot.TabConfig))
+	assert.True(t, proto.Equal(experimentBucket, snapshot.ExperimentBucket))
+	assert.Nil(t, snapshot.GroupBucket)
+
+	c := NewSnapshotClient(snapshot)
+	resp, err := c.GetTabConfigData(ctx, &protoctabcacheserver.GetTabConfigReq{ProjectId: "p"})
+	assert.Nil(t, err)
+	assert.Equal(t, protoctabcacheserver.Code_CODE_SUCCESS, resp.Code)
+	resp, err = c.GetTabConfigData(ctx, &protoctabcacheserver.GetTabConfigReq{ProjectId: "p", Version: "v1"})
+	assert.Nil(t, err)
+	assert.Equal(t, protoctabcacheserver.Code_CODE_SAME_VERSION, resp.Code)
+	groupBucket, err := c.BatchGetGroupBucketInfo(ctx, &protoctabcacheserver.BatchGetGroupBucketReq{ProjectId: "p"})
+	assert.Nil(t, err)
+	assert.Equal(t, protoctabcacheserver.Code_CODE_SUCCESS, groupBucket.Code)
+	_, err = c.GetTabConfigData(ctx, &protoctabcacheserver.GetTabConfigReq{ProjectId: "unknown"})
+	assert.NotNil(t, err)
+
+	_, err = UnmarshalSnapshot([]byte(`{"version":2,"projectId":"p"}`))
+	assert.NotNil(t, err)
+	_, err = MarshalSnapshot(&Snapshot{ProjectID: "p"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,137 @@
+// Package client TODO
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Middleware Wrap the RoundTripper of control-plane requests, such as adding proxy, retry, request signing
+// and distributed tracing headers
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// WithMiddleware Wrap the transport of the http client of the cache service, see WithTransportMiddleware
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(client *tabCacheClient) {
+		client.httpClient = wrapHTTPClient(client.httpClient, middlewares)
+	}
+}
+
+// WithDialer Dial the connections of the cache service through the dial, such as the egress control, see
+// dialHTTPClient
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(client *tabCacheClient) {
+		client.httpClient = dialHTTPClient(client.httpClient, dial)
+	}
+}
+
+// WithDMPHTTPClient Set http client of the dmp client, customize timeout and proxy
+func WithDMPHTTPClient(client *http.Client) DMPOption {
+	return func(dmpClient *tabDMPClient) {
+		dmpClient.httpClient = client
+	}
+}
+
+// WithDMPMiddleware Wrap the transport of the http client of the dmp client
+func WithDMPMiddleware(middlewares ...Middleware) DMPOption {
+	return func(dmpClient *tabDMPClient) {
+		dmpClient.httpClient = wrapHTTPClient(dmpClient.httpClient, middlewares)
+	}
+}
+
+// WithDMPDialer Dial the connections of the dmp client through the dial
+func WithDMPDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) DMPOption {
+	return func(dmpClient *tabDMPClient) {
+		dmpClient.httpClient = dialHTTPClient(dmpClient.httpClient, dial)
+	}
+}
+
+// WithProxy Send the requests of the cache service through the proxy
+func WithProxy(proxy func(req *http.Request) (*url.URL, error)) Option {
+	return func(client *tabCacheClient) {
+		client.httpClient = proxyHTTPClient(client.httpClient, proxy)
+	}
+}
+
+// WithDMPProxy Send the requests of the dmp client through the proxy
+func WithDMPProxy(proxy func(req *http.Request) (*url.URL, error)) DMPOption {
+	return func(dmpClient *tabDMPClient) {
+		dmpClient.httpClient = proxyHTTPClient(dmpClient.httpClient, proxy)
+	}
+}
+
+// dialHTTPClient Return a copy of the client whose transport dials through the dial, see transportHTTPClient
+func dialHTTPClient(client *http.Client, dial func(ctx context.Context, network, addr string) (net.Conn,
+	error)) *http.Client {
+	return transportHTTPClient(client, func(transport *http.Transport) {
+		transport.DialContext = dial
+		transport.DialTLSContext = nil
+	})
+}
+
+// proxyHTTPClient Return a copy of the client whose transport sends through the proxy, see transportHTTPClient
+func proxyHTTPClient(client *http.Client, proxy func(req *http.Request) (*url.URL, error)) *http.Client {
+	return transportHTTPClient(client, func(transport *http.Transport) {
+		transport.Proxy = proxy
+	})
+}
+
+// transportHTTPClient Return a copy of the client whose transport is cloned and modified. The modification is
+// enforced, so the transport other than *http.Transport, which cannot be modified, fails all requests
+func transportHTTPClient(client *http.Client, modify func(transport *http.Transport)) *http.Client {
+	var result = &http.Client{}
+	if client != nil {
+		*result = *client
+	}
+	next := result.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	transport, ok := next.(*http.Transport)
+	if !ok {
+		result.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.Errorf("the transport %T cannot be controlled", next)
+		})
+		return result
+	}
+	transport = transport.Clone()
+	modify(transport)
+	result.Transport = transport
+	return result
+}
+
+// roundTripperFunc Adapt the function to http.RoundTripper
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip Implement http.RoundTripper
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// wrapHTTPClient Return a copy of the client whose transport is wrapped by the middlewares,
+// the first middleware is the outermost. The client passed in is not modified, it may be shared by the caller
+func wrapHTTPClient(client *http.Client, middlewares []Middleware) *http.Client {
+	if len(middlewares) == 0 {
+		return client
+	}
+	var result = &http.Client{}
+	if client != nil {
+		*result = *client
+	}
+	next := result.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] == nil {
+			continue
+		}
+		next = middlewares[i](next)
+	}
+	result.Transport = next
+	return result
+}
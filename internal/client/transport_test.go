@@ -0,0 +1,108 @@
+// Package client ...
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	protoctabcacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordMiddleware(name string, record *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*record = append(*record, name)
+			req.Header.Set("X-Mock-"+name, name)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	defer func(uri string) { getTabConfigURI = uri }(getTabConfigURI)
+	getTabConfigURI = mockGetTabConfig(t).URL
+	var record []string
+	httpClient := &http.Client{Timeout: time.Second}
+	c := NewTABCacheClient(WithHTTPClient(httpClient),
+		WithMiddleware(recordMiddleware("outer", &record), nil, recordMiddleware("inner", &record)))
+	cacheClient, ok := c.(*tabCacheClient)
+	if !ok {
+		t.Fatalf("NewTABCacheClient() = %T, want *tabCacheClient", c)
+	}
+	cacheClient.addr = ""
+	_, err := c.GetTabConfigData(context.TODO(), &protoctabcacheserver.GetTabConfigReq{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, record)
+	assert.Nil(t, httpClient.Transport) // the client passed in is not modified
+	assert.Equal(t, time.Second, cacheClient.httpClient.Timeout)
+}
+
+func TestWrapHTTPClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Second}
+	tests := []struct {
+		name        string
+		client      *http.Client
+		middlewares []Middleware
+		wantSame    bool
+	}{
+		{name: "no middleware", client: httpClient, middlewares: nil, wantSame: true},
+		{name: "nil client", client: nil, middlewares: []Middleware{func(next http.RoundTripper) http.RoundTripper {
+			return next
+		}}},
+		{name: "normal", client: httpClient, middlewares: []Middleware{func(next http.RoundTripper) http.RoundTripper {
+			return next
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapHTTPClient(tt.client, tt.middlewares)
+			assert.Equal(t, tt.wantSame, got == tt.client)
+			if !tt.wantSame {
+				assert.Equal(t, http.DefaultTransport, got.Transport)
+			}
+		})
+	}
+}
+
+func TestDialHTTPClient(t *testing.T) {
+	defer func(uri string) { getTabConfigURI = uri }(getTabConfigURI)
+	server := mockGetTabConfig(t)
+	defer server.Close()
+	getTabConfigURI = "http://abc.invalid" + server.URL[len("http://127.0.0.1"):] // resolved by the dial only
+	var dialed []string
+	c := NewTABCacheClient(WithDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}))
+	c.(*tabCacheClient).addr = ""
+	_, err := c.GetTabConfigData(context.TODO(), &protoctabcacheserver.GetTabConfigReq{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(dialed))
+	assert.Contains(t, dialed[0], "abc.invalid:")
+
+	// the dialing of the custom transport cannot be controlled, fail closed
+	c = NewTABCacheClient(WithHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}),
+		WithDialer((&net.Dialer{}).DialContext))
+	c.(*tabCacheClient).addr = ""
+	_, err = c.GetTabConfigData(context.TODO(), &protoctabcacheserver.GetTabConfigReq{})
+	assert.NotNil(t, err)
+}
+
+func TestProxyHTTPClient(t *testing.T) {
+	defer func(uri string) { getTabConfigURI = uri }(getTabConfigURI)
+	server := mockGetTabConfig(t) // serves as the proxy
+	defer server.Close()
+	getTabConfigURI = "http://abc.invalid/GetTabConfig"
+	proxyURL, _ := url.Parse(server.URL)
+	c := NewTABCacheClient(WithProxy(http.ProxyURL(proxyURL)))
+	c.(*tabCacheClient).addr = ""
+	_, err := c.GetTabConfigData(context.TODO(), &protoctabcacheserver.GetTabConfigReq{})
+	assert.Nil(t, err)
+	_, ok := NewDMPClient(WithDMPProxy(http.ProxyURL(proxyURL))).(*tabDMPClient).httpClient.Transport.(*http.Transport)
+	assert.True(t, ok)
+}
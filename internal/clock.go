@@ -0,0 +1,116 @@
+// Package internal sdk
+package internal
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NowAttributeKey The attribute key of the current unix seconds for the time-window targeting, such as a number tag
+// of the key in [start, end). Filled with the skew corrected time unless passed by the caller, see Now
+const NowAttributeKey = "abc_now"
+
+const (
+	// clockSampleSize The number of the latest samples the skew is the median of
+	clockSampleSize = 9
+	// maxClockSampleRTT The samples of a longer round trip are dropped, the error of the estimation is up to half of it
+	maxClockSampleRTT = 2 * time.Second
+)
+
+// ClockSkew The estimated offset of the local clock from the control plane, see RecordClockSample
+type ClockSkew struct {
+	// The control plane clock minus the local clock, positive if the local clock is behind
+	Offset time.Duration `json:"offset"`
+	// Whether the offset exceeds GlobalConfig.ClockSkewTolerance and is applied by Now
+	Applied bool `json:"applied"`
+	// The number of the samples the offset is estimated from, zero if none
+	Samples int `json:"samples"`
+	// The time of the latest sample
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+var clock = struct {
+	sync.RWMutex
+	samples    []time.Duration
+	offset     time.Duration
+	updateTime time.Time
+}{}
+
+// RecordClockResponse Record the clock sample of the Date header of the control plane response to the request sent
+// at sent, ignored if the header is missing or invalid
+func RecordClockResponse(resp *http.Response, sent time.Time) {
+	if resp == nil {
+		return
+	}
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	RecordClockSample(serverTime, sent, time.Now())
+}
+
+// RecordClockSample Record a sample of the offset in the NTP fashion, the server time is taken at the midpoint of the
+// round trip from sent to received. The Date header has the precision of a second, the server time is taken at the
+// middle of its second
+func RecordClockSample(serverTime time.Time, sent time.Time, received time.Time) {
+	rtt := received.Sub(sent)
+	if rtt < 0 || rtt > maxClockSampleRTT {
+		return
+	}
+	offset := serverTime.Add(500 * time.Millisecond).Sub(sent.Add(rtt / 2))
+	clock.Lock()
+	defer clock.Unlock()
+	clock.samples = append(clock.samples, offset)
+	if len(clock.samples) > clockSampleSize {
+		clock.samples = clock.samples[len(clock.samples)-clockSampleSize:]
+	}
+	sorted := make([]time.Duration, len(clock.samples))
+	copy(sorted, clock.samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	clock.offset = sorted[len(sorted)/2] // the median is robust to the delayed responses
+	clock.updateTime = received
+}
+
+// CurrentClockSkew The estimated offset of the local clock
+func CurrentClockSkew() *ClockSkew {
+	clock.RLock()
+	defer clock.RUnlock()
+	return &ClockSkew{Offset: clock.offset, Applied: isSkewApplied(clock.offset), Samples: len(clock.samples),
+		UpdateTime: clock.updateTime}
+}
+
+// Now The current time corrected by the estimated offset if it exceeds GlobalConfig.ClockSkewTolerance, the local
+// time if the correction is disabled or the offset is within the tolerance
+func Now() time.Time {
+	now := time.Now()
+	if C.ClockSkewTolerance <= 0 {
+		return now
+	}
+	clock.RLock()
+	offset := clock.offset
+	clock.RUnlock()
+	if !isSkewApplied(offset) {
+		return now
+	}
+	return now.Add(offset)
+}
+
+func isSkewApplied(offset time.Duration) bool {
+	if C.ClockSkewTolerance <= 0 {
+		return false
+	}
+	return offset > C.ClockSkewTolerance || offset < -C.ClockSkewTolerance
+}
+
+// ResetClock Clear the samples, called by Release
+func ResetClock() {
+	clock.Lock()
+	defer clock.Unlock()
+	clock.samples = nil
+	clock.offset = 0
+	clock.updateTime = time.Time{}
+}
@@ -0,0 +1,53 @@
+// Package internal ...
+package internal
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockSkew(t *testing.T) {
+	defer ResetClock()
+	defer func(tolerance time.Duration) { C.ClockSkewTolerance = tolerance }(C.ClockSkewTolerance)
+	C.ClockSkewTolerance = 0
+	assert.Equal(t, 0, CurrentClockSkew().Samples)
+
+	sent := time.Now()
+	received := sent.Add(200 * time.Millisecond)
+	// the server clock is one minute ahead, the Date header truncates to the second
+	server := sent.Add(100*time.Millisecond + time.Minute).Truncate(time.Second)
+	RecordClockSample(server, sent, received)
+	RecordClockSample(server, sent, sent.Add(time.Minute)) // dropped, the round trip is too long
+	RecordClockSample(server, sent, sent.Add(-time.Second))
+	skew := CurrentClockSkew()
+	assert.Equal(t, 1, skew.Samples)
+	assert.InDelta(t, float64(time.Minute), float64(skew.Offset), float64(time.Second))
+	assert.False(t, skew.Applied)
+	assert.WithinDuration(t, time.Now(), Now(), time.Second) // the correction is disabled
+
+	C.ClockSkewTolerance = 5 * time.Second
+	assert.True(t, CurrentClockSkew().Applied)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), Now(), 2*time.Second)
+	C.ClockSkewTolerance = 2 * time.Minute
+	assert.False(t, CurrentClockSkew().Applied)
+	assert.WithinDuration(t, time.Now(), Now(), time.Second)
+
+	// the median resists the outlier
+	for i := 0; i < 4; i++ {
+		RecordClockSample(server, sent, received)
+	}
+	RecordClockSample(server.Add(time.Hour), sent, received)
+	assert.InDelta(t, float64(time.Minute), float64(CurrentClockSkew().Offset), float64(time.Second))
+
+	ResetClock()
+	RecordClockResponse(&http.Response{Header: http.Header{}}, sent)
+	assert.Equal(t, 0, CurrentClockSkew().Samples)
+	RecordClockResponse(&http.Response{Header: http.Header{"Date": {time.Now().UTC().Format(http.TimeFormat)}}},
+		time.Now())
+	assert.Equal(t, 1, CurrentClockSkew().Samples)
+	assert.InDelta(t, 0, float64(CurrentClockSkew().Offset), float64(time.Second))
+	RecordClockResponse(nil, sent)
+}
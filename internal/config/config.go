@@ -5,10 +5,10 @@ package config
 import (
 	"context"
 
+	"github.com/abetterchoice/go-sdk/internal"
 	"github.com/abetterchoice/go-sdk/internal/cache"
 	"github.com/abetterchoice/go-sdk/internal/experiment"
 	"github.com/abetterchoice/go-sdk/plugin/log"
-	"github.com/abetterchoice/hashutil"
 	"github.com/abetterchoice/protoc_cache_server"
 	"github.com/pkg/errors"
 )
@@ -35,16 +35,40 @@ type Value struct {
 func (e *executor) GetRemoteConfig(ctx context.Context, projectID string, key string,
 	options *experiment.Options) (*Value,
 	error) {
-	application := cache.GetApplication(projectID)
+	application := experiment.GetApplication(projectID, options)
 	if application == nil {
-		return nil, errors.Errorf("projectID [%s] not found", projectID)
+		return nil, internal.UnknownProjectError(projectID)
 	}
 	options.Application = application
 	remoteConfig, ok := application.TabConfig.ConfigData.RemoteConfigIndex[key]
 	if !ok || remoteConfig == nil {
 		return nil, errors.Errorf("remoteConfig[%s] not found", key)
 	}
-	return e.getRemoteConfigValue(ctx, remoteConfig, options)
+	fingerprint, cacheable := e.fingerprint(application, key, remoteConfig, options)
+	if cacheable {
+		if result, ok := application.RemoteConfigCache.Load(fingerprint); ok {
+			return result.(*Value), nil
+		}
+	}
+	value, err := e.getRemoteConfigValue(ctx, remoteConfig, options)
+	if err == nil && cacheable && len(options.TimedOut) == 0 {
+		application.RemoteConfigCache.Store(fingerprint, value)
+	}
+	return value, err
+}
+
+// fingerprint The key of the result in the remote config cache of the application, ok if the result only depends
+// on the attributes of the unit, see cache.RemoteConfigCache. The traced evaluations and the units in the override
+// list are not cached
+func (e *executor) fingerprint(application *cache.Application, key string, config *protoc_cache_server.RemoteConfig,
+	options *experiment.Options) (string, bool) {
+	if application.RemoteConfigCache == nil || options.Trace != nil {
+		return "", false
+	}
+	if _, _, ok := e.processOverrideList(config, options); ok {
+		return "", false
+	}
+	return application.RemoteConfigCache.Fingerprint(key, options.AttributeTag)
 }
 
 func (e *executor) getRemoteConfigValue(ctx context.Context, config *protoc_cache_server.RemoteConfig,
@@ -55,6 +79,11 @@ func (e *executor) getRemoteConfigValue(ctx context.Context, config *protoc_cach
 			UnitIDType: unitIDType}, nil
 	}
 	holdoutExp, err := e.checkCaughtByHoldout(ctx, config.HoldoutLayerKeys, options)
+	if errors.Cause(err) == experiment.ErrRuleTimeout {
+		options.TimedOut = append(options.TimedOut, config.Key)
+		return &Value{Data: config.DefaultValue, IsDefault: true, RemoteConfig: config,
+			UnitIDType: protoc_cache_server.UnitIDType_UNIT_ID_TYPE_DEFAULT}, nil
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "checkCaughtByHoldout")
 	}
@@ -74,6 +103,11 @@ func (e *executor) getRemoteConfigValue(ctx context.Context, config *protoc_cach
 	for _, condition := range config.ConditionList {
 		unitIDType = condition.UnitIdType
 		value, hit, err := e.processCondition(ctx, condition, options)
+		if errors.Cause(err) == experiment.ErrRuleTimeout {
+			// the remaining conditions are not evaluated either, the default value is served
+			options.TimedOut = append(options.TimedOut, config.Key)
+			break
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -126,7 +160,7 @@ func (e *executor) processOverrideList(config *protoc_cache_server.RemoteConfig,
 
 func (e *executor) processCondition(ctx context.Context, condition *protoc_cache_server.Condition,
 	options *experiment.Options) (*Value, bool, error) {
-	bucketNum := hashutil.GetBucketNum(condition.HashMethod, e.getHashSource(condition.UnitIdType, options),
+	bucketNum := experiment.GetBucketNum(options, condition.HashMethod, e.getHashSource(condition.UnitIdType, options),
 		condition.HashSeed, condition.BucketSize)
 	if !e.isHitConditionBucketInfo(bucketNum, condition.BucketInfo) {
 		return nil, false, nil
@@ -196,5 +230,5 @@ func (e *executor) getHashSource(unitType protoc_cache_server.UnitIDType, option
 	if unitType == protoc_cache_server.UnitIDType_UNIT_ID_TYPE_NEW_ID {
 		return options.NewDecisionID
 	}
-	return options.DecisionID
+	return internal.SaltedHashSource(int32(unitType), options.DecisionID)
 }
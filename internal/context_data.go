@@ -0,0 +1,44 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ContextExtractor Extract the values carried by the ctx of the evaluations and the manual exposures, such as the
+// trace ID and the tenant ID, they are merged into the extended details of the exposures and the monitor events
+type ContextExtractor func(ctx context.Context) map[string]string
+
+// ContextData The values extracted from the ctx by the GlobalConfig.ContextExtractors in the order of registration,
+// the later ones override the earlier ones, nil if none. The panics of the extractors are recorded and skipped
+func ContextData(ctx context.Context) map[string]string {
+	if len(C.ContextExtractors) == 0 || ctx == nil {
+		return nil
+	}
+	var result map[string]string
+	for _, extractor := range C.ContextExtractors {
+		data := extractContext(ctx, extractor)
+		if len(data) == 0 {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string, len(data))
+		}
+		for key, value := range data {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func extractContext(ctx context.Context, extractor ContextExtractor) (data map[string]string) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			RecordError("ContextExtractor", errors.Errorf("context extractor panic:%v", recovered))
+			data = nil
+		}
+	}()
+	return extractor(ctx)
+}
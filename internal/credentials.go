@@ -0,0 +1,104 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// Credentials The credentials used by a single projectID, they take precedence over the secretKey passed in Init.
+// Fields left empty fall back to the Init configuration or the token delivered by the remote configuration.
+type Credentials struct {
+	// secretKey used for control plane authentication, such as fetching configuration and dmp
+	SecretKey string `json:"-"`
+	// Token carried in the reporting metadata, passed through to the metrics plugin
+	EventToken string `json:"-"`
+}
+
+var (
+	// credentialsIndex key is projectID, value is *Credentials. The value is never modified after being stored,
+	// rotation replaces the pointer, so readers always see a consistent pair of secretKey and eventToken.
+	credentialsIndex sync.Map
+	// credentialsLock Serialize the rotations, which merge into the stored credentials
+	credentialsLock sync.Mutex
+)
+
+// SetCredentials Rotate the credentials of the projectID, the empty fields keep the credentials rotated before.
+// nil clears the rotated credentials. Concurrent and safe
+func SetCredentials(projectID string, credentials *Credentials) {
+	credentialsLock.Lock()
+	defer credentialsLock.Unlock()
+	if credentials == nil {
+		credentialsIndex.Delete(projectID)
+		return
+	}
+	c := *credentials // copy, the caller may reuse the instance
+	if stored := GetCredentials(projectID); stored != nil {
+		if len(c.SecretKey) == 0 {
+			c.SecretKey = stored.SecretKey
+		}
+		if len(c.EventToken) == 0 {
+			c.EventToken = stored.EventToken
+		}
+	}
+	credentialsIndex.Store(projectID, &c)
+}
+
+// GetCredentials Get the credentials set through SetCredentials, return nil if not set
+func GetCredentials(projectID string) *Credentials {
+	result, ok := credentialsIndex.Load(projectID)
+	if !ok {
+		return nil
+	}
+	credentials, ok := result.(*Credentials)
+	if !ok {
+		return nil
+	}
+	return credentials
+}
+
+// SecretKey The secretKey of the projectID, fall back to the global secretKey if not rotated
+func SecretKey(projectID string) string {
+	credentials := GetCredentials(projectID)
+	if credentials != nil && len(credentials.SecretKey) != 0 {
+		return credentials.SecretKey
+	}
+	return C.SecretKey
+}
+
+// ConfigSecretKey The secretKey of the control-plane requests of the projectID. The rotated secretKey takes precedence
+// over the token of the ConfigTokenSource, which takes precedence over the global secretKey
+func ConfigSecretKey(ctx context.Context, projectID string) (string, error) {
+	credentials := GetCredentials(projectID)
+	if credentials != nil && len(credentials.SecretKey) != 0 {
+		return credentials.SecretKey, nil
+	}
+	token, ok, err := SourceToken(ctx, TokenKindConfig, projectID)
+	if ok {
+		return token, err
+	}
+	return C.SecretKey, nil
+}
+
+// EventToken The reporting token of the projectID. The rotated token takes precedence over the token of the
+// EventTokenSource, fall back to the token of the remote configuration if neither is set or the source fails
+func EventToken(projectID string, token string) string {
+	credentials := GetCredentials(projectID)
+	if credentials != nil && len(credentials.EventToken) != 0 {
+		return credentials.EventToken
+	}
+	if sourced, ok, err := SourceToken(context.Background(), TokenKindEvent, projectID); ok && err == nil {
+		return sourced
+	}
+	return token
+}
+
+// ResetCredentials Clear all rotated credentials, called by Release
+func ResetCredentials() {
+	credentialsLock.Lock()
+	defer credentialsLock.Unlock()
+	credentialsIndex.Range(func(key, value interface{}) bool {
+		credentialsIndex.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,34 @@
+// Package internal sdk
+package internal
+
+// DeliveryGuarantee How hard the reporting of the exposures of a projectID tries before giving up
+type DeliveryGuarantee string
+
+// const ...
+const (
+	// DeliveryBestEffort The exposures may be discarded by the full queue, the load shedding or a plugin failure,
+	// the evaluation is never slowed down. The default
+	DeliveryBestEffort DeliveryGuarantee = "best_effort"
+	// DeliveryAtLeastOnce The automatic exposures wait for the room of the full queue instead of being discarded,
+	// they are not shed, and the failed plugin calls are retried. The exposures failing all the attempts or finding
+	// the queue still full are kept in memory and redelivered in the background, and handed off by HandoffState.
+	// They are lost only if the process exits without the handoff or too many batches are waiting
+	DeliveryAtLeastOnce DeliveryGuarantee = "at_least_once"
+	// DeliveryBlocking The automatic exposures are reported in the calling goroutine, which waits until the plugin
+	// acknowledges them, the failed plugin calls are retried
+	DeliveryBlocking DeliveryGuarantee = "blocking"
+)
+
+// Delivery The delivery guarantee of the projectID, DeliveryBestEffort if not set
+func Delivery(projectID string) DeliveryGuarantee {
+	options := GetProjectOptions(projectID)
+	if options == nil || len(options.DeliveryGuarantee) == 0 {
+		return DeliveryBestEffort
+	}
+	return options.DeliveryGuarantee
+}
+
+// IsDeliveryGuaranteed Whether the exposures of the projectID must not be discarded locally, see DeliveryAtLeastOnce
+func IsDeliveryGuaranteed(projectID string) bool {
+	return Delivery(projectID) != DeliveryBestEffort
+}
@@ -0,0 +1,107 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DialFunc The dialer of the connections to the backends, the same as net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// EgressPolicy The egress control of the connections to the backends, for the egress-restricted environments.
+// The pinned endpoints are dialed at the explicit addresses instead of the resolved ones, and the connections to
+// an IP outside the allowlist fail closed. Only the dialing is changed, TLS still verifies the host of the endpoint
+type EgressPolicy struct {
+	// key is the host or host:port of the endpoint, value is the IP, host, IP:port or host:port dialed instead.
+	// The port of the endpoint is kept if the value has none
+	Pins map[string]string
+	// The networks the dialed IPs must be in, all IPs are allowed if empty
+	Allowlist []*net.IPNet
+}
+
+// egressDialer The dialer of the connections, the same as the default transport of net/http
+var egressDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+// IsEmpty Whether the policy changes nothing, nil is empty
+func (p *EgressPolicy) IsEmpty() bool {
+	return p == nil || (len(p.Pins) == 0 && len(p.Allowlist) == 0)
+}
+
+// Pin The address dialed for the addr of the endpoint
+func (p *EgressPolicy) Pin(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid addr %s", addr)
+	}
+	pinned, ok := p.Pins[addr]
+	if !ok {
+		pinned, ok = p.Pins[host]
+	}
+	if !ok {
+		return addr, nil
+	}
+	if _, _, err := net.SplitHostPort(pinned); err == nil {
+		return pinned, nil
+	}
+	return net.JoinHostPort(pinned, port), nil
+}
+
+// IsAllowed Whether the ip is in the allowlist
+func (p *EgressPolicy) IsAllowed(ip net.IP) bool {
+	if len(p.Allowlist) == 0 {
+		return true
+	}
+	for _, network := range p.Allowlist {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// DialContext Dial the addr through the pins and the allowlist. The host dialed is resolved and the allowed IPs are
+// dialed in order, it fails closed if no IP is allowed
+func (p *EgressPolicy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if p.IsEmpty() {
+		return egressDialer.DialContext(ctx, network, addr)
+	}
+	pinned, err := p.Pin(addr)
+	if err != nil {
+		return nil, err
+	}
+	host, port, err := net.SplitHostPort(pinned)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pin %s", pinned)
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, errors.Wrapf(err, "lookup %s", host)
+		}
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+	var lastErr error
+	for _, ip := range ips {
+		if !p.IsAllowed(ip) {
+			continue
+		}
+		conn, err := egressDialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, errors.Errorf("egress to %s (%s) is not allowed, resolved %v", addr, pinned, ips)
+}
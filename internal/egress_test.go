@@ -0,0 +1,63 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEgressPolicy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	_, private, _ := net.ParseCIDR("10.0.0.0/8")
+	p := &EgressPolicy{
+		Pins:      map[string]string{"cache.abetterchoice.ai": "127.0.0.1", "dmp.invalid:443": "127.0.0.1:" + port},
+		Allowlist: []*net.IPNet{loopback},
+	}
+	pinned, err := p.Pin("cache.abetterchoice.ai:" + port)
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:"+port, pinned)
+	pinned, err = p.Pin("dmp.invalid:443")
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:"+port, pinned)
+	pinned, err = p.Pin("other.invalid:443")
+	assert.Nil(t, err)
+	assert.Equal(t, "other.invalid:443", pinned)
+
+	conn, err := p.DialContext(context.Background(), "tcp", "cache.abetterchoice.ai:"+port)
+	if assert.Nil(t, err) {
+		_ = conn.Close()
+	}
+	conn, err = p.DialContext(context.Background(), "tcp", "dmp.invalid:443")
+	if assert.Nil(t, err) {
+		_ = conn.Close()
+	}
+	// outside the allowlist, fail closed
+	p.Allowlist = []*net.IPNet{private}
+	_, err = p.DialContext(context.Background(), "tcp", "cache.abetterchoice.ai:"+port)
+	assert.NotNil(t, err)
+	assert.True(t, p.IsAllowed(net.ParseIP("10.1.2.3")))
+	assert.False(t, p.IsAllowed(net.ParseIP("127.0.0.1")))
+
+	var empty *EgressPolicy
+	assert.True(t, empty.IsEmpty())
+	conn, err = empty.DialContext(context.Background(), "tcp", listener.Addr().String())
+	if assert.Nil(t, err) {
+		_ = conn.Close()
+	}
+}
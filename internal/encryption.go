@@ -0,0 +1,106 @@
+// Package internal sdk
+package internal
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// encryptionVersion The version of the encryption format, bumped on incompatible changes
+const encryptionVersion = 1
+
+// encryptionMagic The prefix of the encrypted data, followed by the version, the nonce and the sealed data
+var encryptionMagic = []byte("ABCENC")
+
+// KeySource Resolve the AES key of the data at rest, such as from the keychain of the OS or a secret manager,
+// the key is 16, 24 or 32 bytes for AES-128, AES-192 or AES-256
+type KeySource func() ([]byte, error)
+
+// Cipher Encrypt the data at rest with AES-GCM, such as the snapshots and the sampled traces written to the disk.
+// Concurrent and safe
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher Create the cipher of the AES key, 16, 24 or 32 bytes
+func NewCipher(key []byte) (*Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "aes")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "gcm")
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// header The authenticated prefix of the encrypted data
+func (c *Cipher) header() []byte {
+	return append(append([]byte{}, encryptionMagic...), encryptionVersion)
+}
+
+// Encrypt Seal the plaintext with a random nonce, the result is recognized by IsEncrypted
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	header := c.header()
+	result := make([]byte, len(header)+c.aead.NonceSize(), len(header)+c.aead.NonceSize()+len(plaintext)+
+		c.aead.Overhead())
+	copy(result, header)
+	nonce := result[len(header):]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "nonce")
+	}
+	return c.aead.Seal(result, nonce, plaintext, header), nil
+}
+
+// Decrypt Open the data sealed by Encrypt, the data modified or sealed by another key is rejected
+func (c *Cipher) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, errors.Errorf("data is not encrypted")
+	}
+	header := c.header()
+	if data[len(encryptionMagic)] != encryptionVersion {
+		return nil, errors.Errorf("unsupported encryption version %d", data[len(encryptionMagic)])
+	}
+	if len(data) < len(header)+c.aead.NonceSize() {
+		return nil, errors.Errorf("encrypted data is truncated")
+	}
+	nonce := data[len(header) : len(header)+c.aead.NonceSize()]
+	plaintext, err := c.aead.Open(nil, nonce, data[len(header)+c.aead.NonceSize():], header)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt, wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted Whether the data is sealed by Cipher.Encrypt
+func IsEncrypted(data []byte) bool {
+	return len(data) > len(encryptionMagic) && bytes.HasPrefix(data, encryptionMagic)
+}
+
+// ParseKey Decode the key of a key file or an environment variable, in hex, in base64 or raw, the surrounding spaces
+// are ignored
+func ParseKey(text []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(text)
+	if key, err := hex.DecodeString(string(trimmed)); err == nil && isKeySize(len(key)) {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil && isKeySize(len(key)) {
+		return key, nil
+	}
+	if isKeySize(len(text)) {
+		return text, nil
+	}
+	return nil, errors.Errorf("invalid key, 16, 24 or 32 bytes in hex, base64 or raw are required")
+}
+
+func isKeySize(size int) bool {
+	return size == 16 || size == 24 || size == 32
+}
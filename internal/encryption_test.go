@@ -0,0 +1,56 @@
+// Package internal ...
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCipher(t *testing.T) {
+	_, err := NewCipher([]byte("short"))
+	assert.NotNil(t, err)
+	key := bytes.Repeat([]byte{1}, 32)
+	c, err := NewCipher(key)
+	assert.Nil(t, err)
+	plaintext := []byte(`{"unitId":"12345"}`)
+	data, err := c.Encrypt(plaintext)
+	assert.Nil(t, err)
+	assert.True(t, IsEncrypted(data))
+	assert.False(t, IsEncrypted(plaintext))
+	assert.False(t, bytes.Contains(data, []byte("12345")))
+	another, err := c.Encrypt(plaintext)
+	assert.Nil(t, err)
+	assert.NotEqual(t, data, another) // random nonce
+	decrypted, err := c.Decrypt(data)
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = c.Decrypt(tampered)
+	assert.NotNil(t, err)
+	other, err := NewCipher(bytes.Repeat([]byte{2}, 16))
+	assert.Nil(t, err)
+	_, err = other.Decrypt(data)
+	assert.NotNil(t, err)
+	_, err = c.Decrypt(plaintext)
+	assert.EqualError(t, err, "data is not encrypted")
+	_, err = c.Decrypt(data[:len(encryptionMagic)+3])
+	assert.EqualError(t, err, "encrypted data is truncated")
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0xab}, 32)
+	for _, text := range []string{hex.EncodeToString(key) + "\n", base64.StdEncoding.EncodeToString(key),
+		string(key)} {
+		parsed, err := ParseKey([]byte(text))
+		assert.Nil(t, err)
+		assert.Equal(t, key, parsed)
+	}
+	_, err := ParseKey([]byte("too short"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,42 @@
+// Package internal sdk
+package internal
+
+import (
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotInitialized Init has not been called, or the SDK has been released
+	ErrNotInitialized = errors.New("sdk is not initialized")
+	// ErrProjectUnknown The configuration of the projectID is not loaded, usually it is not passed in Init
+	ErrProjectUnknown = errors.New("project is unknown")
+	// ErrConfigStale The configuration of the projectID has not been refreshed within GlobalConfig.MaxConfigAge
+	ErrConfigStale = errors.New("config is stale")
+	// ErrRateLimited The evaluation or the exposure exceeds the limits of the projectID, see ProjectOptions.EvaluationQPS
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// ProjectError The error of a projectID, wrapping ErrProjectUnknown or ErrConfigStale, errors.As gets the projectID
+type ProjectError struct {
+	ProjectID string
+	Err       error
+}
+
+// Error ...
+func (e *ProjectError) Error() string {
+	return "projectID [" + e.ProjectID + "]: " + e.Err.Error()
+}
+
+// Unwrap ...
+func (e *ProjectError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownProjectError The error of the projectID whose configuration is not found, ErrNotInitialized if Init has
+// not been called
+func UnknownProjectError(projectID string) error {
+	if len(C.ProjectIDList) == 0 {
+		return errors.WithStack(ErrNotInitialized)
+	}
+	return errors.WithStack(&ProjectError{ProjectID: projectID, Err: ErrProjectUnknown})
+}
@@ -0,0 +1,41 @@
+// Package internal sdk
+package internal
+
+import (
+	"github.com/google/uuid"
+)
+
+// EventIDKey The key of the unique ID of each exposure and monitor event in the extended details, see IsEnableEventID
+const EventIDKey = "event_id"
+
+// NewEventID A random UUID identifying one exposure or monitor event
+func NewEventID() string {
+	return uuid.New().String()
+}
+
+// WithEventID Add a new event ID to the extended details of a monitor event if enabled, extInfo is modified and may
+// be nil
+func WithEventID(extInfo map[string]string) map[string]string {
+	if !C.IsEnableEventID {
+		return extInfo
+	}
+	if extInfo == nil {
+		extInfo = make(map[string]string, 1)
+	}
+	extInfo[EventIDKey] = NewEventID()
+	return extInfo
+}
+
+// ExtraDataWithEventID Copy the extra data shared by the exposures of a list and add a new event ID if enabled,
+// the shared extra data is not modified
+func ExtraDataWithEventID(extraData map[string]string) map[string]string {
+	if !C.IsEnableEventID {
+		return extraData
+	}
+	result := make(map[string]string, len(extraData)+1)
+	for key, value := range extraData {
+		result[key] = value
+	}
+	result[EventIDKey] = NewEventID()
+	return result
+}
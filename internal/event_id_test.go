@@ -0,0 +1,24 @@
+// Package internal sdk
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithEventID(t *testing.T) {
+	defer func() {
+		C = &GlobalConfig{}
+	}()
+	shared := map[string]string{"k": "v"}
+	assert.Nil(t, WithEventID(nil))
+	assert.Equal(t, shared, ExtraDataWithEventID(shared))
+
+	C = &GlobalConfig{IsEnableEventID: true}
+	assert.Len(t, WithEventID(nil)[EventIDKey], 36)
+	extraData := ExtraDataWithEventID(shared)
+	assert.Equal(t, "v", extraData["k"])
+	assert.NotEqual(t, extraData[EventIDKey], ExtraDataWithEventID(shared)[EventIDKey])
+	assert.Equal(t, map[string]string{"k": "v"}, shared) // not modified
+}
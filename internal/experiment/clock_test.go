@@ -0,0 +1,45 @@
+// Package experiment ...
+package experiment
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNowAttribute(t *testing.T) {
+	defer internal.ResetClock()
+	defer func(tolerance time.Duration) { internal.C.ClockSkewTolerance = tolerance }(internal.C.ClockSkewTolerance)
+	now := time.Now().Unix()
+	window := strconv.FormatInt(now-60, 10) + ":" + strconv.FormatInt(now+60, 10)
+	tagListGroup := []*protoccacheserver.TagList{{TagList: []*protoccacheserver.Tag{{
+		Key:      internal.NowAttributeKey,
+		TagType:  protoccacheserver.TagType_TAG_TYPE_NUMBER,
+		Operator: protoccacheserver.Operator_OPERATOR_LCRO,
+		Value:    window,
+	}}}}
+	hit, err := IsHitTag(context.Background(), tagListGroup, &Options{})
+	assert.Nil(t, err)
+	assert.True(t, hit)
+	// passed by the caller
+	hit, err = IsHitTag(context.Background(), tagListGroup, &Options{AttributeTag: map[string][]string{
+		internal.NowAttributeKey: {strconv.FormatInt(now+3600, 10)}}})
+	assert.Nil(t, err)
+	assert.False(t, hit)
+
+	// the local clock is an hour behind the control plane
+	sent := time.Now()
+	internal.RecordClockSample(sent.Add(time.Hour), sent, sent)
+	hit, err = IsHitTag(context.Background(), tagListGroup, &Options{})
+	assert.Nil(t, err)
+	assert.True(t, hit) // the correction is disabled
+	internal.C.ClockSkewTolerance = time.Minute
+	hit, err = IsHitTag(context.Background(), tagListGroup, &Options{})
+	assert.Nil(t, err)
+	assert.False(t, hit)
+}
@@ -4,12 +4,13 @@ package experiment
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
 	"github.com/abetterchoice/go-sdk/internal/cache"
 	"github.com/abetterchoice/go-sdk/internal/client"
 	"github.com/abetterchoice/go-sdk/plugin/log"
-	"github.com/abetterchoice/hashutil"
 	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
 	"github.com/abetterchoice/protoc_dmp_proxy_server"
 	"github.com/abetterchoice/tagutil"
@@ -36,7 +37,7 @@ type Experiment struct {
 func (e *executor) VariantKey2LayerKey(projectID, variantKey string) ([]string, error) {
 	application := cache.GetApplication(projectID)
 	if application == nil {
-		return nil, errors.Errorf("projectID [%s] not found", projectID)
+		return nil, internal.UnknownProjectError(projectID)
 	}
 	return application.VariantKeyLayerMap[variantKey], nil
 }
@@ -45,7 +46,7 @@ func (e *executor) VariantKey2LayerKey(projectID, variantKey string) ([]string,
 func (e *executor) GetVariantValue(projectID, layerKey, variantKey string) ([]byte, error) {
 	application := cache.GetApplication(projectID)
 	if application == nil {
-		return nil, errors.Errorf("projectID [%s] not found", projectID)
+		return nil, internal.UnknownProjectError(projectID)
 	}
 	layer, ok := application.LayerIndex[layerKey]
 	if !ok {
@@ -62,24 +63,44 @@ func (e *executor) GetVariantValue(projectID, layerKey, variantKey string) ([]by
 	return nil, errors.Errorf("default parameter of layer[%s] does not exist parameter %s", layerKey, variantKey)
 }
 
+var (
+	// ErrLayerNotFound The layer of the layer filter does not exist, wrapped by the errors of GetExperiments
+	ErrLayerNotFound = errors.New("layer not found")
+)
+
 // GetExperiments Get the set of experiment information that the user hits under the conditions specified by options
 func (e *executor) GetExperiments(ctx context.Context, projectID string, options *Options) (map[string]*Experiment,
 	error) {
-	application := cache.GetApplication(projectID)
+	application := GetApplication(projectID, options)
 	if application == nil {
-		return nil, errors.Errorf("projectID [%s] not found", projectID)
+		return nil, internal.UnknownProjectError(projectID)
 	}
 	err := e.fillOptions(ctx, application, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "fillOptions")
 	}
+	if len(options.LayerKeyPatterns) > 0 && !e.expandLayerKeyPatterns(application, options) {
+		return map[string]*Experiment{}, nil
+	}
 	layers, flag, err := e.layersCanBeHit(ctx, application, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "layersCanBeHit")
 	}
 	if flag {
-		return e.getMultiLayerExperiments(ctx, layers, options)
+		if options.LayerResult == nil {
+			options.LayerResult = make(map[string]*Experiment, len(layers))
+		}
+		for key := range options.LayerResult {
+			delete(options.LayerResult, key)
+		}
+		return e.fillMultiLayerExperiments(ctx, layers, options, options.LayerResult)
 	}
+	// all layers are evaluated, hash the user in one batch
+	if options.Hashes == nil {
+		options.Hashes = &HashBatch{}
+	}
+	options.Hashes.Reset(application)
+	defer options.Hashes.Release()
 	result, err := e.getDomainExperiments(ctx, application.TabConfig.ExperimentData.GlobalDomain, options)
 	if err != nil {
 		return nil, errors.Wrapf(err, "getDomainExperiments")
@@ -118,7 +139,7 @@ func (e *executor) layersCanBeHit(ctx context.Context, application *cache.Applic
 	if len(options.LayerKeys) == 0 {
 		return nil, false, nil
 	}
-	var result = make([]*protoccacheserver.Layer, 0, len(options.LayerKeys))
+	var result = options.HitLayers[:0]
 	for layerKey := range options.LayerKeys {
 		layer, err := e.checkLayerCanBeHit(ctx, application, layerKey, options)
 		if err != nil {
@@ -128,6 +149,7 @@ func (e *executor) layersCanBeHit(ctx context.Context, application *cache.Applic
 			result = append(result, layer)
 		}
 	}
+	options.HitLayers = result
 	return result, true, nil
 }
 
@@ -136,23 +158,40 @@ func (e *executor) checkLayerCanBeHit(ctx context.Context, application *cache.Ap
 	error) {
 	layer, ok := application.FullFlowLayerIndex[layerKey]
 	if ok {
+		if options.Trace != nil {
+			options.Trace.add(&TraceStep{Type: TraceStepLayerLookup, LayerKey: layerKey, Passed: true,
+				Message: "full flow layer"})
+		}
 		return layer, nil
 	}
 	layer, ok = application.LayerIndex[layerKey]
 	if !ok || layer == nil {
-		return nil, errors.Errorf("invalid layerKey=%s", layerKey)
+		if options.Trace != nil {
+			options.Trace.add(&TraceStep{Type: TraceStepLayerLookup, LayerKey: layerKey, Message: "layer not found"})
+		}
+		return nil, errors.Wrapf(ErrLayerNotFound, "invalid layerKey=%s", layerKey)
 	}
 	holdoutExp, err := e.checkCaughtByHoldout(ctx, application, layer, options)
+	if errors.Cause(err) == ErrRuleTimeout {
+		return layer, nil // falls back to the default group of the layer, see safeLayerExperiment
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "checkCaughtByHoldout")
 	}
 	if holdoutExp != nil {
+		if options.Trace != nil {
+			options.Trace.add(&TraceStep{Type: TraceStepLayerLookup, LayerKey: layerKey, Passed: true,
+				Message: "caught by holdout"})
+		}
 		return application.LayerIndex[layerKey], nil
 	}
 	flag, err := isHitLayer(application, layerKey, options)
 	if err != nil {
 		return nil, errors.Wrap(err, "isHitLayer")
 	}
+	if options.Trace != nil {
+		options.Trace.add(&TraceStep{Type: TraceStepLayerLookup, LayerKey: layerKey, Passed: flag})
+	}
 	if flag {
 		return application.LayerIndex[layerKey], nil
 	}
@@ -178,7 +217,11 @@ func (e *executor) checkCaughtByHoldout(ctx context.Context, application *cache.
 				return nil, err
 			}
 			options.HoldoutLayerResult[holdoutLayerKey] = experiment
-			if experiment != nil && !experiment.IsDefault && experiment.IsControl {
+			caught := experiment != nil && !experiment.IsDefault && experiment.IsControl
+			if options.Trace != nil {
+				traceHoldout(holdoutLayerKey, experiment, caught, options)
+			}
+			if caught {
 				return experiment, nil
 			}
 		} else if holdoutExp != nil && !holdoutExp.IsDefault && holdoutExp.IsControl {
@@ -196,10 +239,16 @@ func isHitLayer(application *cache.Application, layerKey string, options *Option
 	}
 	bucketNum := int64(0)
 	for i, domainMetadata := range domainMetadataList {
-		if i != 0 && !isHitTraffic(bucketNum, domainMetadata) {
-			return false, nil
+		if i != 0 {
+			hit := isHitTraffic(bucketNum, domainMetadata)
+			if options.Trace != nil {
+				traceDomain(bucketNum, hit, domainMetadata, layerKey, options)
+			}
+			if !hit {
+				return false, nil
+			}
 		}
-		bucketNum = hashutil.GetBucketNum(domainMetadata.HashMethod, getHashSource(domainMetadata.UnitIdType, options),
+		bucketNum = GetBucketNum(options, domainMetadata.HashMethod, getHashSource(domainMetadata.UnitIdType, options),
 			domainMetadata.HashSeed, domainMetadata.BucketSize)
 	}
 	return true, nil
@@ -230,6 +279,9 @@ func (e *executor) preparedDMP(ctx context.Context, application *cache.Applicati
 			if len(tagSet) <= 1 {
 				continue
 			}
+			if primedTagSet(application.ProjectID, unitIDType, platformCode, tagSet, options) {
+				continue
+			}
 			batchGetDMPTagResultReq.ReqList = append(batchGetDMPTagResultReq.ReqList,
 				&protoc_dmp_proxy_server.GetDMPTagResultReq{
 					ProjectId:       application.ProjectID,
@@ -295,7 +347,7 @@ func (e *executor) setOverrideList(application *cache.Application, options *Opti
 
 func (e *executor) getDomainExperiments(ctx context.Context, domain *protoccacheserver.Domain,
 	options *Options) (map[string]*Experiment, error) {
-	bucketNum := hashutil.GetBucketNum(domain.Metadata.HashMethod,
+	bucketNum := GetBucketNum(options, domain.Metadata.HashMethod,
 		getHashSource(domain.Metadata.UnitIdType, options),
 		domain.Metadata.HashSeed, domain.Metadata.BucketSize)
 	for _, holdoutDomain := range domain.HoldoutDomainList {
@@ -341,9 +393,14 @@ func (e *executor) getMultiLayerDomainExperiments(ctx context.Context,
 
 func (e *executor) getMultiLayerExperiments(ctx context.Context, layerList []*protoccacheserver.Layer,
 	options *Options) (map[string]*Experiment, error) {
-	var result = make(map[string]*Experiment)
+	return e.fillMultiLayerExperiments(ctx, layerList, options, make(map[string]*Experiment))
+}
+
+// fillMultiLayerExperiments Evaluate the layers into the result, which is returned
+func (e *executor) fillMultiLayerExperiments(ctx context.Context, layerList []*protoccacheserver.Layer,
+	options *Options, result map[string]*Experiment) (map[string]*Experiment, error) {
 	for _, layer := range layerList {
-		g, err := e.getLayerExperimentWithDefault(ctx, layer, options)
+		g, err := e.safeLayerExperiment(ctx, layer, options)
 		if err != nil {
 			return nil, errors.Wrap(err, "GetLayerExperiment")
 		}
@@ -351,6 +408,10 @@ func (e *executor) getMultiLayerExperiments(ctx context.Context, layerList []*pr
 			continue
 		}
 		result[layer.Metadata.Key] = g
+		if options.Trace != nil {
+			options.Trace.add(&TraceStep{Type: TraceStepResult, LayerKey: layer.Metadata.Key, Passed: !g.IsDefault,
+				ExperimentKey: g.ExperimentKey, GroupID: g.Id})
+		}
 		// set holdout data
 		e.setHoldout2Experiment(g, layer, options)
 	}
@@ -406,7 +467,8 @@ func (e *executor) getHoldoutLayerKey(holdoutLayerKey string, options *Options,
 func (e *executor) isLayerFilterPass(ctx context.Context, layer *protoccacheserver.Layer, options *Options) bool {
 	return layerKeyFilter(ctx, layer, options) &&
 		sceneIDListFilter(ctx, layer, options) &&
-		experimentKeyFilter(ctx, layer, options)
+		experimentKeyFilter(ctx, layer, options) &&
+		experimentTagFilter(ctx, layer, options)
 }
 
 func (e *executor) getLayerExperimentWithDefault(ctx context.Context, layer *protoccacheserver.Layer,
@@ -415,20 +477,49 @@ func (e *executor) getLayerExperimentWithDefault(ctx context.Context, layer *pro
 		return nil, nil
 	}
 	if !e.isLayerFilterPass(ctx, layer, options) {
+		if options.Trace != nil {
+			options.Trace.add(&TraceStep{Type: TraceStepFilter, LayerKey: layer.Metadata.Key,
+				Message: "filtered out by the scene, layer, experiment key or experiment tag options"})
+		}
 		return nil, nil
 	}
+	if isTimedOut(options) {
+		return nil, ErrRuleTimeout
+	}
+	key, memoizable := memoKey(layer, options)
+	if memoizable {
+		if experiment, ok := memo.load(key, options.Application.Revision); ok {
+			return experiment, nil
+		}
+	}
 	experiment, err := e.GetLayerExperiment(ctx, layer, options)
 	if err != nil {
 		return nil, err
 	}
-	if experiment != nil {
-		return experiment, nil
+	if experiment == nil {
+		experiment = e.defaultLayerExperiment(layer, options)
+	}
+	if memoizable {
+		memo.store(key, options.Application.Revision, experiment) // shared, not modified as there is no holdout
 	}
+	return experiment, nil
+}
+
+// defaultLayerExperiment The default group of the layer, or the system default group if the layer has none
+func (e *executor) defaultLayerExperiment(layer *protoccacheserver.Layer, options *Options) *Experiment {
 	if layer.Metadata.DefaultGroup != nil {
-		return &Experiment{Group: layer.Metadata.DefaultGroup}, nil
+		if options.Trace != nil {
+			options.Trace.add(&TraceStep{Type: TraceStepDefault, LayerKey: layer.Metadata.Key, Passed: true,
+				GroupID: layer.Metadata.DefaultGroup.Id, Message: "default group of the layer"})
+		}
+		return &Experiment{Group: layer.Metadata.DefaultGroup}
 	}
 	if len(layer.GroupIndex) == 0 {
-		return nil, nil
+		return nil
+	}
+	if options.Trace != nil {
+		options.Trace.add(&TraceStep{Type: TraceStepDefault, LayerKey: layer.Metadata.Key, Passed: true,
+			GroupID: e.defaultSystemGlobalGroupID(options), Message: "system default group"})
 	}
 	return &Experiment{
 		Group: &protoccacheserver.Group{
@@ -439,7 +530,7 @@ func (e *executor) getLayerExperimentWithDefault(ctx context.Context, layer *pro
 			LayerKey:  layer.Metadata.Key,
 		},
 		IsOverrideList: false,
-	}, nil
+	}
 }
 
 func (e *executor) defaultSystemGlobalGroupID(options *Options) int64 {
@@ -454,6 +545,10 @@ func (e *executor) GetLayerExperiment(ctx context.Context, layer *protoccacheser
 	options *Options) (*Experiment, error) {
 	overrideGroup := e.getLayerOverrideExperiment(layer, options)
 	if overrideGroup != nil {
+		if options.Trace != nil {
+			options.Trace.add(&TraceStep{Type: TraceStepOverride, LayerKey: layer.Metadata.Key, Passed: true,
+				GroupID: overrideGroup.Id})
+		}
 		return overrideGroup, nil
 	}
 	holdoutExp, err := e.checkCaughtByHoldout(ctx, options.Application, layer, options)
@@ -490,9 +585,12 @@ func (e *executor) getLayerOverrideExperiment(layer *protoccacheserver.Layer, op
 
 func (e *executor) getSingleHashLayerExperiment(ctx context.Context, layer *protoccacheserver.Layer,
 	options *Options) (*Experiment, error) {
-	bucketNum := hashutil.GetBucketNum(layer.Metadata.HashMethod,
+	bucketNum := GetBucketNum(options, layer.Metadata.HashMethod,
 		getHashSource(layer.Metadata.UnitIdType, options),
 		layer.Metadata.HashSeed, layer.Metadata.BucketSize)
+	if options.Trace != nil {
+		traceHash(layer.Metadata.Key, "", bucketNum, layer.Metadata.BucketSize, options)
+	}
 	for _, group := range layer.GroupIndex {
 		if group.IsDefault {
 			continue
@@ -516,27 +614,54 @@ func (e *executor) getSingleHashLayerExperiment(ctx context.Context, layer *prot
 	return nil, nil
 }
 
-// IsHitTag Whether the tag is hit
+// IsHitTag Whether the tag is hit, the rules compiled at config load are used if any, see cache.CompileRule
 func IsHitTag(ctx context.Context, tagListGroup []*protoccacheserver.TagList, options *Options) (bool, error) {
 	if len(tagListGroup) == 0 {
 		return true, nil
 	}
+	if options.Timing != nil {
+		defer func(start time.Time) {
+			options.Timing.RuleEval += time.Since(start)
+		}(time.Now())
+	}
 	for _, tagList := range tagListGroup {
 		isHit := true
-		for _, tag := range tagList.TagList {
+		var matcherList []cache.RuleMatcher
+		if options.Application != nil {
+			matcherList = options.Application.RuleIndex[tagList] // nil if not compiled, the rules are interpreted
+		}
+		for i, tag := range tagList.TagList {
+			if isTimedOut(options) {
+				return false, ErrRuleTimeout
+			}
 			if tag.TagType == protoccacheserver.TagType_TAG_TYPE_DMP {
 				dmpFlag, err := isHitDMP(ctx, tag, options)
 				if err != nil {
 					return false, errors.Wrap(err, "isHitDMP")
 				}
-				if dmpFlag && tag.Operator == protoccacheserver.Operator_OPERATOR_FALSE ||
-					!dmpFlag && tag.Operator == protoccacheserver.Operator_OPERATOR_TRUE {
+				dmpHit := !(dmpFlag && tag.Operator == protoccacheserver.Operator_OPERATOR_FALSE ||
+					!dmpFlag && tag.Operator == protoccacheserver.Operator_OPERATOR_TRUE)
+				if options.Trace != nil {
+					traceRule(tag, dmpHit, options)
+				}
+				if !dmpHit {
 					isHit = false
 					break
 				}
 				continue
 			}
-			if !tagutil.IsHit(tag.TagType, tag.Operator, options.AttributeTag[tag.Key], tag.Value) {
+			var tagHit bool
+			if matcherList != nil {
+				tagHit = matcherList[i](attributeValue(tag.Key, options))
+			} else if cache.IsLocaleOperator(tag.Operator) {
+				tagHit = cache.CompileRule(tag.TagType, tag.Operator, tag.Value)(attributeValue(tag.Key, options))
+			} else {
+				tagHit = tagutil.IsHit(tag.TagType, tag.Operator, attributeValue(tag.Key, options), tag.Value)
+			}
+			if options.Trace != nil {
+				traceRule(tag, tagHit, options)
+			}
+			if !tagHit {
 				isHit = false
 				break
 			}
@@ -548,6 +673,16 @@ func IsHitTag(ctx context.Context, tagListGroup []*protoccacheserver.TagList, op
 	return false, nil
 }
 
+// attributeValue The values of the attribute of the key, internal.NowAttributeKey is filled with the current unix
+// seconds corrected by the clock skew unless passed by the caller
+func attributeValue(key string, options *Options) []string {
+	value := options.AttributeTag[key]
+	if len(value) == 0 && key == internal.NowAttributeKey {
+		return []string{strconv.FormatInt(internal.Now().Unix(), 10)}
+	}
+	return value
+}
+
 func isHitDMP(ctx context.Context, tag *protoccacheserver.Tag, options *Options) (bool, error) {
 	if options.IsDisableDMP { // 禁用 结果都为 false
 		return false, nil
@@ -556,6 +691,13 @@ func isHitDMP(ctx context.Context, tag *protoccacheserver.Tag, options *Options)
 	if ok {
 		return flag, nil
 	}
+	if options.Application != nil {
+		flag, ok = loadPrimedDMP(options.Application.ProjectID,
+			dmpTagResultKeyFormat(tag.UnitIdType, tag.DmpPlatform, tag.Value, options))
+		if ok {
+			return flag, nil
+		}
+	}
 	flag, err := client.IsHitDMP(ctx, &protoc_dmp_proxy_server.GetDMPTagResultReq{}, tag.Value)
 	if err != nil {
 		return false, err
@@ -573,6 +715,14 @@ func dmpTagResultKeyFormat(unitIDType protoccacheserver.UnitIDType, dmpPlatformC
 }
 
 func (e *executor) isHitGroupBucketInfo(group *protoccacheserver.Group, bucketNum int64, options *Options) bool {
+	hit := e.hitGroupBucketInfo(group, bucketNum, options)
+	if options.Trace != nil {
+		e.traceGroupBucket(group, bucketNum, hit, options)
+	}
+	return hit
+}
+
+func (e *executor) hitGroupBucketInfo(group *protoccacheserver.Group, bucketNum int64, options *Options) bool {
 	bucketInfo, ok := options.Application.GroupIDBucketInfoIndex[group.Id]
 	if !ok {
 		return false
@@ -618,11 +768,18 @@ func (e *executor) isHitExperimentBucketInfo(experiment *protoccacheserver.Exper
 
 func (e *executor) getDoubleHashLayerExperiment(ctx context.Context, layer *protoccacheserver.Layer,
 	options *Options) (*Experiment, error) {
-	bucketNum := hashutil.GetBucketNum(layer.Metadata.HashMethod,
+	bucketNum := GetBucketNum(options, layer.Metadata.HashMethod,
 		getHashSource(layer.Metadata.UnitIdType, options),
 		layer.Metadata.HashSeed, layer.Metadata.BucketSize)
+	if options.Trace != nil {
+		traceHash(layer.Metadata.Key, "", bucketNum, layer.Metadata.BucketSize, options)
+	}
 	for _, experiment := range layer.ExperimentIndex {
-		if !e.isHitExperimentBucketInfo(experiment, bucketNum, options) {
+		hit := e.isHitExperimentBucketInfo(experiment, bucketNum, options)
+		if options.Trace != nil && experiment.Id != 0 {
+			e.traceExperimentBucket(experiment, layer.Metadata.Key, bucketNum, hit, options)
+		}
+		if !hit {
 			continue
 		}
 		return e.getExperimentGroup(ctx, experiment, layer, options)
@@ -632,8 +789,15 @@ func (e *executor) getDoubleHashLayerExperiment(ctx context.Context, layer *prot
 
 func (e *executor) getExperimentGroup(ctx context.Context, experiment *protoccacheserver.Experiment,
 	layer *protoccacheserver.Layer, options *Options) (*Experiment, error) {
-	expBucketNum := hashutil.GetBucketNum(experiment.HashMethod,
-		getHashSource(layer.Metadata.UnitIdType, options), experiment.HashSeed, experiment.BucketSize)
+	hashing := experiment
+	if migrated, ok := options.Application.ExperimentMigrationIndex[experiment.Id]; ok {
+		hashing = migrated // re-keyed, the units keep the buckets of the old experiment
+	}
+	expBucketNum := GetBucketNum(options, hashing.HashMethod,
+		getHashSource(layer.Metadata.UnitIdType, options), hashing.HashSeed, hashing.BucketSize)
+	if options.Trace != nil {
+		traceHash(layer.Metadata.Key, experiment.Key, expBucketNum, experiment.BucketSize, options)
+	}
 	switch experiment.IssueType {
 	case protoccacheserver.IssueType_ISSUE_TYPE_PERCENTAGE:
 		return e.getPercentageExperimentGroup(experiment, expBucketNum, layer, options)
@@ -714,7 +878,7 @@ func getHashSource(unitType protoccacheserver.UnitIDType, options *Options) stri
 	if unitType == protoccacheserver.UnitIDType_UNIT_ID_TYPE_NEW_ID {
 		return options.NewDecisionID
 	}
-	return options.DecisionID
+	return internal.SaltedHashSource(int32(unitType), options.DecisionID)
 }
 
 func getUnitID(unitType protoccacheserver.UnitIDType, options *Options) string {
@@ -37,3 +37,17 @@ func experimentKeyFilter(ctx context.Context, layer *protoccacheserver.Layer, op
 	}
 	return false
 }
+
+func experimentTagFilter(ctx context.Context, layer *protoccacheserver.Layer, options *Options) bool {
+	if len(options.ExperimentTags) == 0 {
+		return true
+	}
+	for _, experiment := range layer.ExperimentIndex {
+		for _, tag := range options.ExperimentTagIndex[experiment.Key] {
+			if options.ExperimentTags[tag] {
+				return true
+			}
+		}
+	}
+	return false
+}
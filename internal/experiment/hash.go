@@ -0,0 +1,120 @@
+// Package experiment abtest Experimental diversion related implementation
+package experiment
+
+import (
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/hashutil"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// HashBatch The hashes of the evaluation of all layers. The hash inputs of the layers only differ by the seed,
+// so the seeded hashes of a source are computed under all seeds of the application in one pass over the source,
+// and the unseeded hashes are computed once per method. The buffers are reused by the pooled options,
+// not concurrent safe
+type HashBatch struct {
+	active    bool
+	seedIndex map[int64]int
+	seeds     []uint64
+	// at most the decisionID and the newDecisionID
+	seeded   []seededHashes
+	unseeded []unseededHash
+}
+
+type seededHashes struct {
+	source string
+	hashes []uint64
+}
+
+type unseededHash struct {
+	hashMethod protoccacheserver.HashMethod
+	source     string
+	hash       uint64
+}
+
+// Reset Start the batch of an evaluation under the application, the hashes of the last evaluation are dropped
+func (b *HashBatch) Reset(application *cache.Application) {
+	b.active = true
+	b.seedIndex, b.seeds = application.HashSeedIndex, application.HashSeeds
+	b.seeded, b.unseeded = b.seeded[:0], b.unseeded[:0]
+}
+
+// Release End the batch, the hashes are computed directly until the next Reset. Nil safe
+func (b *HashBatch) Release() {
+	if b == nil {
+		return
+	}
+	b.active = false
+	b.seedIndex, b.seeds = nil, nil
+}
+
+// hashNum Same as hashutil.GetHashNum
+func (b *HashBatch) hashNum(hashMethod protoccacheserver.HashMethod, source string, seed int64) uint64 {
+	if b == nil || !b.active {
+		return hashutil.GetHashNum(hashMethod, source, uint64(seed))
+	}
+	if !cache.IsSeededHashMethod(hashMethod) {
+		for i := range b.unseeded {
+			if b.unseeded[i].hashMethod == hashMethod && b.unseeded[i].source == source {
+				return b.unseeded[i].hash
+			}
+		}
+		hash := hashutil.GetHashNum(hashMethod, source, uint64(seed))
+		b.unseeded = append(b.unseeded, unseededHash{hashMethod: hashMethod, source: source, hash: hash})
+		return hash
+	}
+	position, ok := b.seedIndex[seed]
+	if !ok { // not in the configuration of the application, such as the seed of a test
+		return hashutil.GetHashNum(hashMethod, source, uint64(seed))
+	}
+	for i := range b.seeded {
+		if b.seeded[i].source == source {
+			return b.seeded[i].hashes[position]
+		}
+	}
+	if len(b.seeded) < cap(b.seeded) { // reuse the buffer of the last evaluation
+		b.seeded = b.seeded[:len(b.seeded)+1]
+	} else {
+		b.seeded = append(b.seeded, seededHashes{})
+	}
+	batch := &b.seeded[len(b.seeded)-1]
+	batch.source = source
+	if cap(batch.hashes) < len(b.seeds) {
+		batch.hashes = make([]uint64, len(b.seeds))
+	}
+	batch.hashes = batch.hashes[:len(b.seeds)]
+	bkdrBatch(source, b.seeds, batch.hashes)
+	return batch.hashes[position]
+}
+
+// bkdrBatch Compute hashutil.BKDR of the source under each seed into hashes, which has the same length as seeds.
+// The loop over the seeds is innermost, the hashes of the seeds are independent of each other and can be computed
+// in parallel by the CPU
+func bkdrBatch(source string, seeds []uint64, hashes []uint64) {
+	for k := range hashes {
+		hashes[k] = 0
+	}
+	length := len(source)
+	for i, j := 0, 0; j < length; i++ {
+		c := uint64(source[j])
+		for k, seed := range seeds {
+			hashes[k] = hashes[k]*seed + c
+		}
+		j++
+		if j < length {
+			c = uint64(source[j])
+			if (i & 1) == 0 {
+				for k, hash := range hashes {
+					hashes[k] = hash ^ ((hash << 7) ^ c ^ (hash >> 3))
+				}
+			} else {
+				for k, hash := range hashes {
+					hashes[k] = hash ^ ^((hash << 11) ^ c ^ (hash >> 5))
+				}
+			}
+			j++
+		}
+	}
+	for k := range hashes {
+		hashes[k] &= 0x7FFFFFFF
+	}
+}
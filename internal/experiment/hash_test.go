@@ -0,0 +1,57 @@
+// Package experiment ...
+package experiment
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/hashutil"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_bkdrBatch(t *testing.T) {
+	seeds := []uint64{0, 1, 23579, 5080801, 1 << 40}
+	for _, source := range []string{"", "1", "12", "12345", "5f1c2e9a-8d3b-4c1a-9e7f-123456789abc"} {
+		hashes := make([]uint64, len(seeds))
+		bkdrBatch(source, seeds, hashes)
+		for k, seed := range seeds {
+			assert.Equal(t, hashutil.BKDR(source, seed), hashes[k], "source=%v seed=%v", source, seed)
+		}
+	}
+}
+
+func TestHashBatch(t *testing.T) {
+	application := &cache.Application{
+		HashSeedIndex: map[int64]int{23579: 0, 5080801: 1},
+		HashSeeds:     []uint64{23579, 5080801},
+	}
+	var batch *HashBatch // nil batch computes directly
+	methods := []protoccacheserver.HashMethod{protoccacheserver.HashMethod_HASH_METHOD_BKDR,
+		protoccacheserver.HashMethod_HASH_METHOD_AP, protoccacheserver.HashMethod_HASH_METHOD_DJB,
+		protoccacheserver.HashMethod_HASH_METHOD_NEW, protoccacheserver.HashMethod_HASH_METHOD_NEW_MD5,
+		protoccacheserver.HashMethod_HASH_METHOD_UNKNOWN}
+	check := func() {
+		for round := 0; round < 2; round++ { // the second round is served from the batch
+			for _, method := range methods {
+				for _, seed := range []int64{23579, 5080801, 7} {
+					for _, source := range []string{"12345", "67890"} {
+						assert.Equal(t, hashutil.GetHashNum(method, source, uint64(seed)),
+							batch.hashNum(method, source, seed), "method=%v seed=%v source=%v", method, seed, source)
+					}
+				}
+			}
+		}
+	}
+	check()
+	batch = &HashBatch{}
+	batch.Reset(application)
+	check()
+	assert.Equal(t, 2, len(batch.seeded))
+	assert.Equal(t, 8, len(batch.unseeded))
+	batch.Reset(application) // the buffers are reused
+	assert.Equal(t, 0, len(batch.seeded))
+	check()
+	batch.Release()
+	check()
+}
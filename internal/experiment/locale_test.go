@@ -0,0 +1,28 @@
+// Package experiment ...
+package experiment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleTag(t *testing.T) {
+	tagListGroup := []*protoccacheserver.TagList{{TagList: []*protoccacheserver.Tag{{
+		Key:      "locale",
+		TagType:  protoccacheserver.TagType_TAG_TYPE_STRING,
+		Operator: cache.OperatorLocale,
+		Value:    "zh-Hant;en-GB",
+	}}}}
+	for value, want := range map[string]bool{"zh_TW": true, "en-GB": true, "zh-CN": false, "en": false} {
+		// interpreted without the compiled rules
+		options := &Options{AttributeTag: map[string][]string{"locale": {value}}, Trace: &Trace{}}
+		hit, err := IsHitTag(context.Background(), tagListGroup, options)
+		assert.Nil(t, err)
+		assert.Equal(t, want, hit, value)
+		assert.Equal(t, "locale OPERATOR_LOCALE zh-Hant;en-GB", options.Trace.Steps[0].Rule)
+	}
+}
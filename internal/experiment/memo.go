@@ -0,0 +1,118 @@
+// Package experiment abtest
+package experiment
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// memoEntry The memoized evaluation of a layer of a unit
+type memoEntry struct {
+	key string
+	// The revision of the configuration of the evaluation, the entries of other revisions are not served
+	revision   string
+	experiment *Experiment
+	expireAt   time.Time
+}
+
+// evaluationMemo The bounded LRU of the layer evaluations of the process shared by all goroutines, so that the
+// repeated evaluations of the active units are served without being evaluated, see GlobalConfig.EvaluationMemoSize
+type evaluationMemo struct {
+	lock  sync.Mutex
+	cache map[string]*list.Element
+	// front is the most recently used
+	lru *list.List
+}
+
+var memo = newEvaluationMemo()
+
+func newEvaluationMemo() *evaluationMemo {
+	return &evaluationMemo{cache: map[string]*list.Element{}, lru: list.New()}
+}
+
+// ResetMemo Drop the memoized evaluations, called by Release
+func ResetMemo() {
+	memo.lock.Lock()
+	defer memo.lock.Unlock()
+	memo.cache = map[string]*list.Element{}
+	memo.lru.Init()
+}
+
+// MemoLen The number of the memoized evaluations, including the expired ones not evicted yet
+func MemoLen() int {
+	memo.lock.Lock()
+	defer memo.lock.Unlock()
+	return memo.lru.Len()
+}
+
+func (m *evaluationMemo) load(key string, revision string) (*Experiment, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	element, ok := m.cache[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoEntry)
+	if entry.revision != revision || time.Now().After(entry.expireAt) {
+		m.lru.Remove(element)
+		delete(m.cache, key)
+		return nil, false
+	}
+	m.lru.MoveToFront(element)
+	return entry.experiment, true
+}
+
+func (m *evaluationMemo) store(key string, revision string, experiment *Experiment) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	entry := &memoEntry{key: key, revision: revision, experiment: experiment,
+		expireAt: time.Now().Add(internal.C.EvaluationMemoTTL)}
+	if element, ok := m.cache[key]; ok {
+		element.Value = entry
+		m.lru.MoveToFront(element)
+		return
+	}
+	m.cache[key] = m.lru.PushFront(entry)
+	for m.lru.Len() > internal.C.EvaluationMemoSize {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.cache, oldest.Value.(*memoEntry).key)
+	}
+}
+
+// memoKey The key of the evaluation of the layer in the memo, ok if it may be memoized, see
+// cache.Application.MemoLayerIndex. The traced evaluations and the units in the override list of the layer are not
+// memoized
+func memoKey(layer *protoccacheserver.Layer, options *Options) (string, bool) {
+	if options.Application == nil || options.Application.MemoLayerIndex == nil || options.Trace != nil {
+		return "", false
+	}
+	attributeKeys, ok := options.Application.MemoLayerIndex[layer.Metadata.Key]
+	if !ok {
+		return "", false
+	}
+	if _, ok := options.OverrideList[layer.Metadata.Key]; ok {
+		return "", false
+	}
+	var sb strings.Builder
+	for _, part := range []string{options.Application.ProjectID, layer.Metadata.Key, options.UnitID,
+		options.DecisionID, options.NewUnitID, options.NewDecisionID} {
+		sb.WriteString(part)
+		sb.WriteByte('\x00')
+	}
+	for _, attributeKey := range attributeKeys {
+		for i, value := range options.AttributeTag[attributeKey] {
+			if i > 0 {
+				sb.WriteByte('\x01')
+			}
+			sb.WriteString(value)
+		}
+		sb.WriteByte('\x00')
+	}
+	return sb.String(), true
+}
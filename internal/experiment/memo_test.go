@@ -0,0 +1,71 @@
+// Package experiment ...
+package experiment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluationMemo(t *testing.T) {
+	defer func(c *internal.GlobalConfig) { internal.C = c }(internal.C)
+	defer ResetMemo()
+	internal.C = &internal.GlobalConfig{EvaluationMemoSize: 2, EvaluationMemoTTL: 50 * time.Millisecond}
+	experiment := &Experiment{Group: &protoccacheserver.Group{Id: 1}}
+	memo.store("a", "r1", experiment)
+	got, ok := memo.load("a", "r1")
+	assert.True(t, ok)
+	assert.Equal(t, experiment, got)
+	_, ok = memo.load("a", "r2") // refreshed to a new revision
+	assert.False(t, ok)
+	assert.Equal(t, 0, MemoLen())
+
+	memo.store("a", "r1", experiment)
+	memo.store("b", "r1", nil)
+	_, _ = memo.load("a", "r1")
+	memo.store("c", "r1", experiment) // b is the least recently used
+	assert.Equal(t, 2, MemoLen())
+	_, ok = memo.load("b", "r1")
+	assert.False(t, ok)
+	_, ok = memo.load("a", "r1")
+	assert.True(t, ok)
+	time.Sleep(60 * time.Millisecond)
+	_, ok = memo.load("a", "r1") // expired
+	assert.False(t, ok)
+}
+
+func TestMemoKey(t *testing.T) {
+	layer := &protoccacheserver.Layer{Metadata: &protoccacheserver.LayerMetadata{Key: "layer"}}
+	application := &cache.Application{ProjectID: "123", MemoLayerIndex: map[string][]string{"layer": {"country"}}}
+	options := &Options{Application: application, UnitID: "u1", DecisionID: "u1",
+		AttributeTag: map[string][]string{"country": {"us"}, "app_version": {"1.0"}}}
+	key, ok := memoKey(layer, options)
+	assert.True(t, ok)
+	options.AttributeTag["app_version"] = []string{"2.0"} // not targeted by the layer
+	other, _ := memoKey(layer, options)
+	assert.Equal(t, key, other)
+	options.AttributeTag["country"] = []string{"ca"}
+	other, _ = memoKey(layer, options)
+	assert.NotEqual(t, key, other)
+	options.UnitID = "u2"
+	other, _ = memoKey(layer, options)
+	assert.NotEqual(t, key, other)
+
+	options.OverrideList = map[string]int64{"layer": 1}
+	_, ok = memoKey(layer, options)
+	assert.False(t, ok)
+	options.OverrideList = nil
+	options.Trace = &Trace{}
+	_, ok = memoKey(layer, options)
+	assert.False(t, ok)
+	options.Trace = nil
+	_, ok = memoKey(&protoccacheserver.Layer{Metadata: &protoccacheserver.LayerMetadata{Key: "unknown"}}, options)
+	assert.False(t, ok)
+	options.Application = &cache.Application{}
+	_, ok = memoKey(layer, options)
+	assert.False(t, ok)
+}
@@ -2,23 +2,36 @@
 package experiment
 
 import (
+	"time"
+
 	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
 )
 
 // Options abtest experiment diversion related options, life cycle for each abtest diversion session
 type Options struct {
 	// Whether TAB automatically records exposure
 	IsExposureLoggingAutomatic bool `json:"ela,omitempty"`
+	// Whether IsExposureLoggingAutomatic is specified by the caller, which takes precedence over the
+	// automatic exposure of the scenes, see ProjectOptions.SceneAutomatic
+	IsExposureLoggingAutomaticSet bool `json:"-"`
 	// Scene ID, used for filtering, returns the hit experiment groups in the lower layers of these scenes,
 	// key is sceneID, value is whether it passes, if sceneIDs is empty, all passes
 	SceneIDs map[int64]bool `json:"sceneIDs,omitempty"`
 	// Layer key, used for filtering, returns the hit experiment groups under these layers,
 	// key is layerKey, value is whether it passes, if layerKeys is empty, all passes
 	LayerKeys map[string]bool `json:"layerKeys,omitempty"`
+	// The glob patterns of the layer keys, the layers matching any of them are added to LayerKeys, see path.Match
+	LayerKeyPatterns []string `json:"layerKeyPatterns,omitempty"`
 	// Experiment key, used for filtering, returns the experimental group hit in this experiment.
 	// Since there are multiple experiments in the same layer, if the user hits other experiments in the same layer,
 	// the experimental groups of other experiments will not be returned, but the default experiment will be returned.
 	ExperimentKeys map[string]bool `json:"experimentKeys,omitempty"`
+	// Experiment tag, used for filtering, returns the experimental group hit in the layers having an experiment
+	// carrying one of these tags, key is tag, value is whether it passes, if experimentTags is empty, all passes
+	ExperimentTags map[string]bool `json:"experimentTags,omitempty"`
+	// The tags of the experiments of the projectID, key is experimentKey, see ProjectOptions.ExperimentTags
+	ExperimentTagIndex map[string][]string `json:"-"`
 	// Whether to pre-process the dmp tag. If enabled, if there is a dmp tag under the business,
 	// rpc will first access the dmp service to get the hit or not result. Enabled by default
 	IsPreparedDMPTag bool `json:"isPreparedDmpTag,omitempty"`
@@ -50,6 +63,26 @@ type Options struct {
 	NewDecisionID string `json:"newDecisionId,omitempty"`
 	// Cache data snapshot
 	Application *cache.Application `json:"-"`
+	// The configuration of the stateless evaluation, the local cache of the projectID is used if nil
+	StaticApplication *cache.Application `json:"-"`
 	// The result of the holdout layer hit. If it is nil, it means that it is not held out.
 	HoldoutLayerResult map[string]*Experiment `json:"-"`
+	// Evaluation trace, if not nil, each step of the evaluation is recorded into it
+	Trace *Trace `json:"-"`
+	// Latency breakdown, if not nil, the cache lookup, rule evaluation and hashing are timed into it
+	Timing *Timing `json:"-"`
+	// The panics recovered from the evaluation of the layers, each layer panicking falls back to its default group,
+	// see EvaluationPanic
+	Panics []*EvaluationPanic `json:"-"`
+	// The time after which the rules are no longer evaluated, zero is unlimited, see ErrRuleTimeout
+	Deadline time.Time `json:"-"`
+	// The keys of the layers and the remote configs that fell back to the default as the Deadline was exceeded
+	TimedOut []string `json:"-"`
+	// Hashes of the evaluation of all layers, reused by the pooled options, see HashBatch
+	Hashes *HashBatch `json:"-"`
+	// The layers of the layer filter that can be hit, reused by the pooled options
+	HitLayers []*protoccacheserver.Layer `json:"-"`
+	// The result of the layer filter, reused by the pooled options, so it is only valid until the next GetExperiments
+	// with the options
+	LayerResult map[string]*Experiment `json:"-"`
 }
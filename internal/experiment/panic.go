@@ -0,0 +1,55 @@
+// Package experiment abtest
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// EvaluationPanic A panic recovered from the evaluation of a layer, such as a nil field of a malformed configuration
+type EvaluationPanic struct {
+	LayerKey string
+	// The value passed to panic
+	Value string
+	// The stack of the goroutine at the panic
+	Stack string
+}
+
+// safeLayerExperiment Evaluate the layer like getLayerExperimentWithDefault, a panic of the evaluation is recovered
+// into options.Panics and falls back to the default group of the layer, so that a malformed layer never crashes
+// the service nor fails the other layers. The layer exceeding the deadline of the evaluation falls back the same way
+func (e *executor) safeLayerExperiment(ctx context.Context, layer *protoccacheserver.Layer,
+	options *Options) (result *Experiment, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		var layerKey string
+		if layer != nil && layer.Metadata != nil {
+			layerKey = layer.Metadata.Key
+		}
+		options.Panics = append(options.Panics, &EvaluationPanic{LayerKey: layerKey,
+			Value: fmt.Sprint(recovered), Stack: string(debug.Stack())})
+		result, err = e.safeDefaultLayerExperiment(layer, options), nil
+	}()
+	result, err = e.getLayerExperimentWithDefault(ctx, layer, options)
+	if errors.Cause(err) == ErrRuleTimeout {
+		return e.timedOutLayerExperiment(layer, options), nil
+	}
+	return result, err
+}
+
+// safeDefaultLayerExperiment The default group of the layer, nil if the layer is too malformed to have one
+func (e *executor) safeDefaultLayerExperiment(layer *protoccacheserver.Layer, options *Options) (result *Experiment) {
+	defer func() {
+		if recover() != nil {
+			result = nil
+		}
+	}()
+	return e.defaultLayerExperiment(layer, options)
+}
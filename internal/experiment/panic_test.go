@@ -0,0 +1,37 @@
+// Package experiment ...
+package experiment
+
+import (
+	"context"
+	"testing"
+
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeLayerExperiment(t *testing.T) {
+	e := &executor{}
+	defaultGroup := &protoccacheserver.Group{Id: 9, IsDefault: true}
+	// the nil experiment of the malformed layer panics in the experiment key filter
+	layer := &protoccacheserver.Layer{
+		Metadata:        &protoccacheserver.LayerMetadata{Key: "layer", DefaultGroup: defaultGroup},
+		GroupIndex:      map[int64]*protoccacheserver.Group{1: {Id: 1}},
+		ExperimentIndex: map[int64]*protoccacheserver.Experiment{1: nil},
+	}
+	options := &Options{ExperimentKeys: map[string]bool{"experiment": true}}
+	result, err := e.safeLayerExperiment(context.Background(), layer, options)
+	assert.Nil(t, err)
+	assert.Equal(t, defaultGroup, result.Group)
+	assert.Equal(t, 1, len(options.Panics))
+	assert.Equal(t, "layer", options.Panics[0].LayerKey)
+	assert.NotEmpty(t, options.Panics[0].Stack)
+
+	// no metadata, no default group either
+	layer = &protoccacheserver.Layer{GroupIndex: map[int64]*protoccacheserver.Group{1: {Id: 1}}}
+	options = &Options{}
+	result, err = e.safeLayerExperiment(context.Background(), layer, options)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, 1, len(options.Panics))
+	assert.Empty(t, options.Panics[0].LayerKey)
+}
@@ -0,0 +1,37 @@
+// Package experiment abtest
+package experiment
+
+import (
+	"path"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// MatchLayerKeyPatterns Whether the layerKey matches any of the glob patterns, see path.Match
+func MatchLayerKeyPatterns(patterns []string, layerKey string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, layerKey); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// expandLayerKeyPatterns Add the layers of the application matching options.LayerKeyPatterns to the layer filter,
+// false if the layer filter is still empty, in which case no layer is evaluated instead of all
+func (e *executor) expandLayerKeyPatterns(application *cache.Application, options *Options) bool {
+	for _, index := range []map[string]*protoccacheserver.Layer{application.LayerIndex,
+		application.FullFlowLayerIndex} {
+		for layerKey := range index {
+			if !MatchLayerKeyPatterns(options.LayerKeyPatterns, layerKey) {
+				continue
+			}
+			if options.LayerKeys == nil {
+				options.LayerKeys = make(map[string]bool)
+			}
+			options.LayerKeys[layerKey] = true
+		}
+	}
+	return len(options.LayerKeys) > 0
+}
@@ -0,0 +1,191 @@
+// Package experiment abtest
+package experiment
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_dmp_proxy_server"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultPrimeTTL The default time the primed dmp tag results are kept, see GlobalConfig.PrimeTTL
+	DefaultPrimeTTL = 10 * time.Minute
+	// primeBatchSize The number of the dmp requests of a unit, a platform and a unitID type in a BatchGetDMPTagResult
+	primeBatchSize = 100
+	// maxPrimedDMPResults Nothing is primed once the number of the primed results reaches it, until they expire
+	maxPrimedDMPResults = 1 << 22
+)
+
+// primedDMPResult The primed result of a dmp tag of a unit
+type primedDMPResult struct {
+	hit bool
+	// unix nano after which the result is no longer used
+	expiry int64
+}
+
+var (
+	// primedDMPResults key is projectID and the key of dmpTagResultKeyFormat joined by \x00
+	primedDMPResults = map[string]*primedDMPResult{}
+	primedDMPLock    sync.RWMutex
+	// the number of the primed results, atomic, so that the evaluations skip the lock if nothing is primed
+	primedDMPCount int64
+)
+
+func primeTTL() time.Duration {
+	if internal.C.PrimeTTL > 0 {
+		return internal.C.PrimeTTL
+	}
+	return DefaultPrimeTTL
+}
+
+// PrimeDMP Fetch the dmp tag results of the units for all the dmp tags of the application in batches, and keep them
+// for GlobalConfig.PrimeTTL, so that the evaluations of the units do not call the dmp one by one. The unitIDs are
+// used as the ids of all the unit id types. The batches failing are skipped and the first error is returned
+func PrimeDMP(ctx context.Context, projectID string, unitIDs []string) error {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return internal.UnknownProjectError(projectID)
+	}
+	if len(application.DMPTagInfo) == 0 {
+		return nil
+	}
+	sweepPrimedDMP()
+	expiry := time.Now().Add(primeTTL()).UnixNano()
+	var firstErr error
+	var batch []*protoc_dmp_proxy_server.GetDMPTagResultReq
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := primeDMPBatch(ctx, projectID, batch, expiry); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		batch = batch[:0]
+	}
+	for _, unitID := range unitIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for unitIDType, platformDMPTagIndex := range application.DMPTagInfo {
+			for platformCode, tagSet := range platformDMPTagIndex {
+				if len(tagSet) == 0 {
+					continue
+				}
+				batch = append(batch, &protoc_dmp_proxy_server.GetDMPTagResultReq{
+					ProjectId:       projectID,
+					UnitId:          unitID,
+					UnitType:        int64(unitIDType),
+					SdkVersion:      env.SDKVersion,
+					DmpPlatformCode: protoc_dmp_proxy_server.DMPPlatform(platformCode),
+					TagList:         convertMap2Array(tagSet),
+				})
+				if len(batch) >= primeBatchSize {
+					flush()
+				}
+			}
+		}
+	}
+	flush()
+	return firstErr
+}
+
+func primeDMPBatch(ctx context.Context, projectID string, batch []*protoc_dmp_proxy_server.GetDMPTagResultReq,
+	expiry int64) error {
+	resp, err := client.DC.BatchGetDMPTagResult(ctx, &protoc_dmp_proxy_server.BatchGetDMPTagResultReq{ReqList: batch})
+	if err != nil {
+		return errors.Wrap(err, "batchGetDMPTagResult")
+	}
+	for _, result := range resp.RespList {
+		if result == nil {
+			continue
+		}
+		for tagKey, statusCode := range result.DmpResult {
+			key := primedDMPKey(projectID, dmpTagResultKeyFormat(protoccacheserver.UnitIDType(result.UnitType),
+				int64(result.DmpPlatformCode), tagKey, &Options{UnitID: result.UnitId, NewUnitID: result.UnitId}))
+			storePrimedDMP(key, &primedDMPResult{
+				hit:    statusCode == protoc_dmp_proxy_server.StatusCode_STATUS_CODE_HIT,
+				expiry: expiry,
+			})
+		}
+	}
+	return nil
+}
+
+func primedDMPKey(projectID string, dmpTagResultKey string) string {
+	return projectID + "\x00" + dmpTagResultKey
+}
+
+func storePrimedDMP(key string, result *primedDMPResult) {
+	primedDMPLock.Lock()
+	defer primedDMPLock.Unlock()
+	if _, ok := primedDMPResults[key]; !ok && len(primedDMPResults) >= maxPrimedDMPResults {
+		return
+	}
+	primedDMPResults[key] = result
+	atomic.StoreInt64(&primedDMPCount, int64(len(primedDMPResults)))
+}
+
+// loadPrimedDMP The primed result of the key of dmpTagResultKeyFormat, false if not primed or expired
+func loadPrimedDMP(projectID string, dmpTagResultKey string) (bool, bool) {
+	if atomic.LoadInt64(&primedDMPCount) == 0 {
+		return false, false
+	}
+	primedDMPLock.RLock()
+	result, ok := primedDMPResults[primedDMPKey(projectID, dmpTagResultKey)]
+	primedDMPLock.RUnlock()
+	if !ok || time.Now().UnixNano() > result.expiry { // the expired ones are removed by the next prime
+		return false, false
+	}
+	return result.hit, true
+}
+
+// sweepPrimedDMP Remove the expired results
+func sweepPrimedDMP() {
+	now := time.Now().UnixNano()
+	primedDMPLock.Lock()
+	defer primedDMPLock.Unlock()
+	for key, result := range primedDMPResults {
+		if now > result.expiry {
+			delete(primedDMPResults, key)
+		}
+	}
+	atomic.StoreInt64(&primedDMPCount, int64(len(primedDMPResults)))
+}
+
+// ResetPrimedDMP Clear all primed results, called by Release
+func ResetPrimedDMP() {
+	primedDMPLock.Lock()
+	defer primedDMPLock.Unlock()
+	primedDMPResults = map[string]*primedDMPResult{}
+	atomic.StoreInt64(&primedDMPCount, 0)
+}
+
+// primedTagSet Fill the dmp tag results of the tags of the unit into options.DMPTagResult if all of them are primed
+func primedTagSet(projectID string, unitIDType protoccacheserver.UnitIDType, platformCode int64,
+	tagSet map[string]interface{}, options *Options) bool {
+	if atomic.LoadInt64(&primedDMPCount) == 0 {
+		return false
+	}
+	var results = make(map[string]bool, len(tagSet))
+	for tagKey := range tagSet {
+		key := dmpTagResultKeyFormat(unitIDType, platformCode, tagKey, options)
+		hit, ok := loadPrimedDMP(projectID, key)
+		if !ok {
+			return false
+		}
+		results[key] = hit
+	}
+	for key, hit := range results {
+		options.DMPTagResult[key] = hit
+	}
+	return true
+}
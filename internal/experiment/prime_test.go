@@ -0,0 +1,72 @@
+// Package experiment ...
+package experiment
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_dmp_proxy_server"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingDMPClient struct {
+	calls int64
+}
+
+func (c *countingDMPClient) BatchGetDMPTagResult(ctx context.Context,
+	req *protoc_dmp_proxy_server.BatchGetDMPTagResultReq) (*protoc_dmp_proxy_server.BatchGetDMPTagResultResp, error) {
+	atomic.AddInt64(&c.calls, 1)
+	return testdata.MockEmptyDMPClient.BatchGetDMPTagResult(ctx, req)
+}
+
+func TestPrimeDMP(t *testing.T) {
+	mockInitLocalCache(t)
+	defer ResetPrimedDMP()
+	defer client.RegisterDMPClient(testdata.MockEmptyDMPClient)
+	dmpClient := &countingDMPClient{}
+	client.RegisterDMPClient(dmpClient)
+	assert.NotNil(t, PrimeDMP(context.Background(), "unknown", []string{"u1"}))
+
+	application := cache.GetApplication(projectID)
+	assert.NotEmpty(t, application.DMPTagInfo)
+	assert.Nil(t, PrimeDMP(context.Background(), projectID, []string{"u1", "u2"}))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&dmpClient.calls)) // in one batch
+	var tag *protoccacheserver.Tag
+	for unitIDType, platformDMPTagIndex := range application.DMPTagInfo {
+		for platformCode, tagSet := range platformDMPTagIndex {
+			for tagKey := range tagSet {
+				tag = &protoccacheserver.Tag{UnitIdType: unitIDType, DmpPlatform: platformCode, Value: tagKey}
+			}
+		}
+	}
+	options := &Options{UnitID: "u1", Application: application}
+	_, ok := loadPrimedDMP(projectID, dmpTagResultKeyFormat(tag.UnitIdType, tag.DmpPlatform, tag.Value, options))
+	assert.True(t, ok)
+	_, err := isHitDMP(context.Background(), tag, options)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&dmpClient.calls)) // served by the primed result
+	_, err = isHitDMP(context.Background(), tag, &Options{UnitID: "u3", Application: application})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&dmpClient.calls))
+
+	storePrimedDMP(primedDMPKey(projectID, "expired"), &primedDMPResult{expiry: time.Now().Add(-time.Second).UnixNano()})
+	_, ok = loadPrimedDMP(projectID, "expired")
+	assert.False(t, ok)
+	sweepPrimedDMP()
+	primedDMPLock.RLock()
+	assert.NotContains(t, primedDMPResults, primedDMPKey(projectID, "expired"))
+	primedDMPLock.RUnlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, PrimeDMP(ctx, projectID, []string{"u4"}))
+	ResetPrimedDMP()
+	_, ok = loadPrimedDMP(projectID, dmpTagResultKeyFormat(tag.UnitIdType, tag.DmpPlatform, tag.Value, options))
+	assert.False(t, ok)
+}
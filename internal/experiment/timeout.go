@@ -0,0 +1,28 @@
+// Package experiment abtest
+package experiment
+
+import (
+	"time"
+
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// ErrRuleTimeout The evaluation exceeded the time budget of Options.Deadline. The rules are checked against the
+// deadline between the layers and the targeting conditions, a layer exceeding it falls back to its default group
+var ErrRuleTimeout = errors.New("rule evaluation timeout")
+
+// isTimedOut Whether the deadline of the evaluation is exceeded, false if unlimited
+func isTimedOut(options *Options) bool {
+	return !options.Deadline.IsZero() && !time.Now().Before(options.Deadline)
+}
+
+// timedOutLayerExperiment Record the layer into options.TimedOut and fall back to its default group
+func (e *executor) timedOutLayerExperiment(layer *protoccacheserver.Layer, options *Options) *Experiment {
+	options.TimedOut = append(options.TimedOut, layer.Metadata.Key)
+	if options.Trace != nil {
+		options.Trace.add(&TraceStep{Type: TraceStepDefault, LayerKey: layer.Metadata.Key,
+			Message: "evaluation time budget exceeded"})
+	}
+	return e.safeDefaultLayerExperiment(layer, options)
+}
@@ -0,0 +1,39 @@
+// Package experiment ...
+package experiment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleTimeout(t *testing.T) {
+	e := &executor{}
+	defaultGroup := &protoccacheserver.Group{Id: 9, IsDefault: true}
+	layer := &protoccacheserver.Layer{
+		Metadata:   &protoccacheserver.LayerMetadata{Key: "layer", DefaultGroup: defaultGroup},
+		GroupIndex: map[int64]*protoccacheserver.Group{1: {Id: 1}},
+	}
+	options := &Options{Deadline: time.Now().Add(-time.Millisecond)}
+	result, err := e.safeLayerExperiment(context.Background(), layer, options)
+	assert.Nil(t, err)
+	assert.Equal(t, defaultGroup, result.Group)
+	assert.Equal(t, []string{"layer"}, options.TimedOut)
+
+	// filtered out before the deadline is checked
+	options = &Options{Deadline: time.Now().Add(-time.Millisecond), LayerKeys: map[string]bool{"other": true}}
+	result, err = e.safeLayerExperiment(context.Background(), layer, options)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+	assert.Empty(t, options.TimedOut)
+
+	tagListGroup := []*protoccacheserver.TagList{{TagList: []*protoccacheserver.Tag{{Key: "city"}}}}
+	_, err = IsHitTag(context.Background(), tagListGroup, &Options{Deadline: time.Now().Add(-time.Millisecond)})
+	assert.Equal(t, ErrRuleTimeout, errors.Cause(err))
+	_, err = IsHitTag(context.Background(), tagListGroup, &Options{Deadline: time.Now().Add(time.Hour)})
+	assert.Nil(t, err)
+}
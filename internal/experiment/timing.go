@@ -0,0 +1,49 @@
+// Package experiment abtest Experimental diversion related implementation
+package experiment
+
+import (
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/hashutil"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// Timing Latency breakdown of a single evaluation, set Options.Timing to measure it.
+// The rule evaluation includes the dmp requests, not concurrent safe
+type Timing struct {
+	CacheLookup time.Duration
+	RuleEval    time.Duration
+	Hashing     time.Duration
+}
+
+// GetApplication Get the local cache of the projectID, the lookup is timed if options.Timing is not nil.
+// options.StaticApplication takes precedence if set
+func GetApplication(projectID string, options *Options) *cache.Application {
+	if options != nil && options.StaticApplication != nil {
+		return options.StaticApplication
+	}
+	if options == nil || options.Timing == nil {
+		return cache.GetApplication(projectID)
+	}
+	start := time.Now()
+	application := cache.GetApplication(projectID)
+	options.Timing.CacheLookup += time.Since(start)
+	return application
+}
+
+// GetBucketNum Same as hashutil.GetBucketNum, the hash is taken from options.Hashes if the batch is active,
+// and the hashing is timed if options.Timing is not nil
+func GetBucketNum(options *Options, hashMethod protoccacheserver.HashMethod, source string, seed int64,
+	bucketSize int64) int64 {
+	if options == nil {
+		return hashutil.GetBucketNum(hashMethod, source, seed, bucketSize)
+	}
+	if options.Timing == nil {
+		return int64(options.Hashes.hashNum(hashMethod, source, seed))%bucketSize + 1
+	}
+	start := time.Now()
+	bucketNum := int64(options.Hashes.hashNum(hashMethod, source, seed))%bucketSize + 1
+	options.Timing.Hashing += time.Since(start)
+	return bucketNum
+}
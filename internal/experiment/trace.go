@@ -0,0 +1,157 @@
+// Package experiment abtest Experimental diversion related implementation
+package experiment
+
+import (
+	"fmt"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+// TraceStepType Type of the evaluation trace step
+type TraceStepType string
+
+// const ...
+const (
+	// TraceStepLayerLookup Whether the layer exists and the traffic of the domains it belongs to is hit
+	TraceStepLayerLookup TraceStepType = "layer_lookup"
+	// TraceStepDomain Hash check of the domain the layer belongs to
+	TraceStepDomain TraceStepType = "domain"
+	// TraceStepHoldout Whether the user is caught by the control group of the holdout layer
+	TraceStepHoldout TraceStepType = "holdout"
+	// TraceStepOverride Whether the user is in the whitelist of the layer
+	TraceStepOverride TraceStepType = "override"
+	// TraceStepFilter Scene, layer and experiment key filters of the options
+	TraceStepFilter TraceStepType = "filter"
+	// TraceStepHash Hash value of the layer or the experiment
+	TraceStepHash TraceStepType = "hash"
+	// TraceStepBucket Whether the hash value is in the bucket range of the experiment or the group
+	TraceStepBucket TraceStepType = "bucket"
+	// TraceStepRule Evaluation of a single targeting rule
+	TraceStepRule TraceStepType = "rule"
+	// TraceStepDefault The layer falls back to the default group
+	TraceStepDefault TraceStepType = "default"
+	// TraceStepResult The group finally assigned in the layer
+	TraceStepResult TraceStepType = "result"
+)
+
+// TraceRange Closed bucket range [Left, Right]
+type TraceRange struct {
+	Left  int64 `json:"left"`
+	Right int64 `json:"right"`
+}
+
+// TraceStep A single step of the evaluation, Passed means the condition of the step is hit,
+// such as the hash value is in the bucket range or the rule matches
+type TraceStep struct {
+	Type          TraceStepType `json:"type"`
+	LayerKey      string        `json:"layerKey,omitempty"`
+	ExperimentKey string        `json:"experimentKey,omitempty"`
+	GroupID       int64         `json:"groupId,omitempty"`
+	Passed        bool          `json:"passed"`
+	HashValue     int64         `json:"hashValue,omitempty"`
+	BucketSize    int64         `json:"bucketSize,omitempty"`
+	Ranges        []TraceRange  `json:"ranges,omitempty"`
+	Rule          string        `json:"rule,omitempty"`
+	Message       string        `json:"message,omitempty"`
+}
+
+// Trace Step-by-step record of a single evaluation, used to explain why a user gets the assignment.
+// Set Options.Trace to record the steps, it is not concurrent safe and bound to a single evaluation
+type Trace struct {
+	Steps []*TraceStep `json:"steps"`
+}
+
+// add Append the step, nil trace records nothing.
+// The callers on the evaluation path check options.Trace first, the step escapes to the heap even if not added
+func (t *Trace) add(step *TraceStep) {
+	if t == nil {
+		return
+	}
+	t.Steps = append(t.Steps, step)
+}
+
+func (e *executor) traceGroupBucket(group *protoccacheserver.Group, bucketNum int64, hit bool, options *Options) {
+	step := &TraceStep{
+		Type:          TraceStepBucket,
+		LayerKey:      group.LayerKey,
+		ExperimentKey: group.ExperimentKey,
+		GroupID:       group.Id,
+		Passed:        hit,
+		HashValue:     bucketNum,
+	}
+	bucketInfo, ok := options.Application.GroupIDBucketInfoIndex[group.Id]
+	if ok {
+		traceBucketInfo(step, bucketInfo)
+	}
+	options.Trace.add(step)
+}
+
+func (e *executor) traceExperimentBucket(experiment *protoccacheserver.Experiment, layerKey string, bucketNum int64,
+	hit bool, options *Options) {
+	step := &TraceStep{
+		Type:          TraceStepBucket,
+		LayerKey:      layerKey,
+		ExperimentKey: experiment.Key,
+		Passed:        hit,
+		HashValue:     bucketNum,
+	}
+	bucketInfo, ok := options.Application.ExperimentIDBucketInfoIndex[experiment.Id]
+	if ok {
+		traceBucketInfo(step, bucketInfo)
+	}
+	options.Trace.add(step)
+}
+
+func traceBucketInfo(step *TraceStep, bucketInfo *protoccacheserver.BucketInfo) {
+	switch bucketInfo.BucketType {
+	case protoccacheserver.BucketType_BUCKET_TYPE_RANGE:
+		if bucketInfo.TrafficRange != nil {
+			step.Ranges = []TraceRange{{Left: bucketInfo.TrafficRange.Left, Right: bucketInfo.TrafficRange.Right}}
+		}
+	case protoccacheserver.BucketType_BUCKET_TYPE_BITMAP:
+		step.Message = "bitmap bucket"
+	}
+}
+
+func traceDomain(bucketNum int64, hit bool, metadata *protoccacheserver.DomainMetadata, layerKey string,
+	options *Options) {
+	step := &TraceStep{
+		Type:      TraceStepDomain,
+		LayerKey:  layerKey,
+		Passed:    hit,
+		HashValue: bucketNum,
+		Message:   "domain " + metadata.Key,
+	}
+	for _, traffic := range metadata.TrafficRangeList {
+		step.Ranges = append(step.Ranges, TraceRange{Left: traffic.Left, Right: traffic.Right})
+	}
+	options.Trace.add(step)
+}
+
+func traceHash(layerKey, experimentKey string, bucketNum, bucketSize int64, options *Options) {
+	options.Trace.add(&TraceStep{
+		Type:          TraceStepHash,
+		LayerKey:      layerKey,
+		ExperimentKey: experimentKey,
+		Passed:        true,
+		HashValue:     bucketNum,
+		BucketSize:    bucketSize,
+	})
+}
+
+func traceRule(tag *protoccacheserver.Tag, hit bool, options *Options) {
+	options.Trace.add(&TraceStep{
+		Type:   TraceStepRule,
+		Passed: hit,
+		Rule:   fmt.Sprintf("%s %s %s", tag.Key, cache.OperatorName(tag.Operator), tag.Value),
+	})
+}
+
+func traceHoldout(holdoutLayerKey string, experiment *Experiment, caught bool, options *Options) {
+	step := &TraceStep{Type: TraceStepHoldout, LayerKey: holdoutLayerKey, Passed: caught}
+	if experiment != nil {
+		step.ExperimentKey, step.GroupID = experiment.ExperimentKey, experiment.Id
+	}
+	options.Trace.add(step)
+}
@@ -0,0 +1,375 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultEndpointProbeInterval The interval of the health probes of the endpoints
+	defaultEndpointProbeInterval = 30 * time.Second
+	// endpointProbeTimeout The timeout of a single health probe
+	endpointProbeTimeout = 5 * time.Second
+	// endpointLatencySwitchRatio The primary is switched to a healthy endpoint only if its latency is below the ratio
+	// of the latency of the primary, so that the similar endpoints do not flap
+	endpointLatencySwitchRatio = 0.7
+	// endpointLatencyWeight The weight of the new sample in the moving average of the latency
+	endpointLatencyWeight = 0.3
+)
+
+// FailoverEvent A change of the primary endpoint of an EndpointGroup
+type FailoverEvent struct {
+	// The name of the group, such as config or event
+	Group string `json:"group"`
+	// The previous and the new primary endpoint
+	From string `json:"from"`
+	To   string `json:"to"`
+	// unhealthy or latency
+	Reason string `json:"reason"`
+	// The error of the previous primary if unhealthy
+	Err error `json:"-"`
+}
+
+// FailoverHandler Callback of the change of the primary endpoint, it is called on the request path, so it should
+// return quickly
+type FailoverHandler func(event *FailoverEvent)
+
+// EndpointStatus The health and latency of an endpoint, see EndpointGroup.Status
+type EndpointStatus struct {
+	URL       string        `json:"url"`
+	Primary   bool          `json:"primary"`
+	Healthy   bool          `json:"healthy"`
+	Latency   time.Duration `json:"latency"`
+	LastError string        `json:"lastError,omitempty"`
+}
+
+// endpoint One endpoint of the group
+type endpoint struct {
+	url *url.URL
+	// whether the last request or probe succeeded, atomic
+	unhealthy int32
+	// moving average of the latency in nanoseconds, 0 if not measured, atomic
+	latency int64
+	// the error string of the last failure
+	lastError atomic.Value
+}
+
+// EndpointGroup The interchangeable endpoints of a backend, such as the regional replicas of the cache service.
+// The requests are sent to the primary, the healthy endpoint of the lowest latency, and fail over to the other
+// endpoints in order of health and latency. The health and the latency are measured by the requests and the
+// periodic probes, see StartEndpointProber
+type EndpointGroup struct {
+	name      string
+	endpoints []*endpoint
+	// the index of the primary endpoint, atomic
+	primary int32
+	// the transport of the probes, the one wrapped by RoundTripper
+	probeTransport atomic.Value
+	// serialize the selections of the primary
+	mu sync.Mutex
+	// OnFailover Called when the primary changes
+	OnFailover FailoverHandler
+}
+
+// NewEndpointGroup Create the group of the endpoints, each is the scheme and the host of the backend url, such as
+// https://cache-eu.example.com. The first endpoint is the primary until the latencies are measured
+func NewEndpointGroup(name string, endpoints []string) (*EndpointGroup, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.Errorf("endpoints are required")
+	}
+	group := &EndpointGroup{name: name}
+	seen := make(map[string]bool, len(endpoints))
+	for _, raw := range endpoints {
+		endpointURL, err := url.Parse(strings.TrimSuffix(raw, "/"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid endpoint %s", raw)
+		}
+		if (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || len(endpointURL.Host) == 0 {
+			return nil, errors.Errorf("invalid endpoint %s, an http or https url is required", raw)
+		}
+		if seen[endpointURL.Host] {
+			return nil, errors.Errorf("duplicate endpoint %s", raw)
+		}
+		seen[endpointURL.Host] = true
+		group.endpoints = append(group.endpoints, &endpoint{url: endpointURL})
+	}
+	return group, nil
+}
+
+// Name The name of the group
+func (g *EndpointGroup) Name() string {
+	return g.name
+}
+
+// Primary The url of the primary endpoint
+func (g *EndpointGroup) Primary() string {
+	return g.endpoints[atomic.LoadInt32(&g.primary)].url.String()
+}
+
+// Status The status of the endpoints in the configured order
+func (g *EndpointGroup) Status() []*EndpointStatus {
+	primary := int(atomic.LoadInt32(&g.primary))
+	result := make([]*EndpointStatus, 0, len(g.endpoints))
+	for i, e := range g.endpoints {
+		lastError, _ := e.lastError.Load().(string)
+		result = append(result, &EndpointStatus{
+			URL:       e.url.String(),
+			Primary:   i == primary,
+			Healthy:   atomic.LoadInt32(&e.unhealthy) == 0,
+			Latency:   time.Duration(atomic.LoadInt64(&e.latency)),
+			LastError: lastError,
+		})
+	}
+	return result
+}
+
+// contains Whether the host is the host of one of the endpoints
+func (g *EndpointGroup) contains(host string) bool {
+	for _, e := range g.endpoints {
+		if e.url.Host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// order The indexes of the endpoints in the order of the attempts, the primary first,
+// then the healthy ones by latency, then the unhealthy ones
+func (g *EndpointGroup) order() []int {
+	primary := int(atomic.LoadInt32(&g.primary))
+	result := make([]int, 0, len(g.endpoints))
+	result = append(result, primary)
+	for i := range g.endpoints {
+		if i != primary {
+			result = append(result, i)
+		}
+	}
+	others := result[1:]
+	sort.SliceStable(others, func(i, j int) bool {
+		a, b := g.endpoints[others[i]], g.endpoints[others[j]]
+		aUnhealthy, bUnhealthy := atomic.LoadInt32(&a.unhealthy), atomic.LoadInt32(&b.unhealthy)
+		if aUnhealthy != bUnhealthy {
+			return aUnhealthy < bUnhealthy
+		}
+		return atomic.LoadInt64(&a.latency) < atomic.LoadInt64(&b.latency)
+	})
+	return result
+}
+
+// observe Record the result of a request or a probe to the endpoint, and select the primary again
+func (g *EndpointGroup) observe(index int, latency time.Duration, err error) {
+	e := g.endpoints[index]
+	if err != nil {
+		atomic.StoreInt32(&e.unhealthy, 1)
+		e.lastError.Store(err.Error())
+	} else {
+		atomic.StoreInt32(&e.unhealthy, 0)
+		previous := atomic.LoadInt64(&e.latency)
+		sample := int64(latency)
+		if previous > 0 {
+			sample = int64(float64(previous)*(1-endpointLatencyWeight) + float64(sample)*endpointLatencyWeight)
+		}
+		atomic.StoreInt64(&e.latency, sample)
+	}
+	g.selectPrimary()
+}
+
+// selectPrimary Switch the primary to the healthy endpoint of the lowest latency if the primary is unhealthy,
+// or if its latency is much lower, see endpointLatencySwitchRatio
+func (g *EndpointGroup) selectPrimary() {
+	g.mu.Lock()
+	primary := int(atomic.LoadInt32(&g.primary))
+	current := g.endpoints[primary]
+	best := -1
+	for i, e := range g.endpoints {
+		if i == primary || atomic.LoadInt32(&e.unhealthy) != 0 {
+			continue
+		}
+		if best < 0 || atomic.LoadInt64(&e.latency) < atomic.LoadInt64(&g.endpoints[best].latency) {
+			best = i
+		}
+	}
+	var event *FailoverEvent
+	if best >= 0 {
+		if atomic.LoadInt32(&current.unhealthy) != 0 {
+			lastError, _ := current.lastError.Load().(string)
+			event = &FailoverEvent{Reason: "unhealthy", Err: errors.New(lastError)}
+		} else if bestLatency, currentLatency := atomic.LoadInt64(&g.endpoints[best].latency),
+			atomic.LoadInt64(&current.latency); bestLatency > 0 && currentLatency > 0 &&
+			float64(bestLatency) < float64(currentLatency)*endpointLatencySwitchRatio {
+			event = &FailoverEvent{Reason: "latency"}
+		}
+	}
+	if event != nil {
+		atomic.StoreInt32(&g.primary, int32(best))
+		event.Group, event.From, event.To = g.name, current.url.String(), g.endpoints[best].url.String()
+	}
+	g.mu.Unlock()
+	if event != nil && g.OnFailover != nil {
+		g.OnFailover(event)
+	}
+}
+
+// RoundTripper Wrap the transport so that all requests are sent to the endpoints of the group, with the failover on
+// the connection errors and the 5xx responses. The body of the request is replayed through GetBody, the requests
+// without it are not retried
+func (g *EndpointGroup) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	return g.roundTripper(next, true)
+}
+
+// HostRoundTripper The same as RoundTripper, but only the requests to the hosts of the endpoints are routed, the
+// others are sent as is
+func (g *EndpointGroup) HostRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return g.roundTripper(next, false)
+}
+
+func (g *EndpointGroup) roundTripper(next http.RoundTripper, all bool) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	g.probeTransport.Store(&next)
+	return failoverRoundTripper(func(req *http.Request) (*http.Response, error) {
+		if !all && !g.contains(req.URL.Host) {
+			return next.RoundTrip(req)
+		}
+		return g.roundTrip(next, req)
+	})
+}
+
+// roundTrip Send the request to the endpoints in order until one succeeds, the 5xx response of the last attempt
+// is returned as is
+func (g *EndpointGroup) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	var (
+		resp    *http.Response
+		lastErr error
+	)
+	for attempt, index := range g.order() {
+		if attempt > 0 {
+			if (req.Body != nil && req.GetBody == nil) || req.Context().Err() != nil {
+				break // the body cannot be replayed, or canceled by the caller
+			}
+			if resp != nil {
+				_, _ = io.Copy(ioutil.Discard, resp.Body)
+				_ = resp.Body.Close()
+				resp = nil
+			}
+		}
+		attemptReq, err := g.rewrite(req, index, attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		resp, lastErr = next.RoundTrip(attemptReq)
+		if lastErr == nil && resp.StatusCode < http.StatusInternalServerError {
+			g.observe(index, time.Since(start), nil)
+			return resp, nil
+		}
+		if req.Context().Err() != nil { // not the fault of the endpoint
+			break
+		}
+		failure := lastErr
+		if failure == nil {
+			failure = errors.Errorf("http status %s", resp.Status)
+		}
+		g.observe(index, 0, failure)
+	}
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// rewrite Copy the request to the endpoint, the path of the endpoint url is the prefix of the path of the request.
+// The body is replayed for the retries
+func (g *EndpointGroup) rewrite(req *http.Request, index int, replay bool) (*http.Request, error) {
+	target := g.endpoints[index].url
+	result := req.Clone(req.Context())
+	result.URL.Scheme = target.Scheme
+	result.URL.Host = target.Host
+	if len(target.Path) > 0 {
+		result.URL.Path = target.Path + result.URL.Path
+		result.URL.RawPath = ""
+	}
+	result.Host = "" // the host of the url
+	if replay && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "getBody")
+		}
+		result.Body = body
+	}
+	return result, nil
+}
+
+// Probe Send a HEAD request to each endpoint through the wrapped transport, any response below 500 is healthy
+func (g *EndpointGroup) Probe(ctx context.Context) {
+	transport, ok := g.probeTransport.Load().(*http.RoundTripper)
+	if !ok {
+		return // not used by any client
+	}
+	for i, e := range g.endpoints {
+		probeCtx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+		req, err := http.NewRequestWithContext(probeCtx, http.MethodHead, e.url.String()+"/", nil)
+		if err != nil {
+			cancel()
+			continue
+		}
+		start := time.Now()
+		resp, err := (*transport).RoundTrip(req)
+		latency := time.Since(start)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				err = errors.Errorf("http status %s", resp.Status)
+			}
+		}
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		g.observe(i, latency, err)
+	}
+}
+
+// failoverRoundTripper Adapt the function to http.RoundTripper
+type failoverRoundTripper func(req *http.Request) (*http.Response, error)
+
+// RoundTrip Implement http.RoundTripper
+func (f failoverRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var endpointProberOnce sync.Once
+
+// StartEndpointProber Start the prober of the endpoint groups of the global configuration, only one prober is
+// started across Init and Release, which reads the groups and the interval of the current configuration every round
+func StartEndpointProber() {
+	endpointProberOnce.Do(func() {
+		Go("endpointProber", func(task *Task) {
+			for {
+				task.Heartbeat()
+				interval := C.EndpointProbeInterval
+				if interval <= 0 {
+					interval = defaultEndpointProbeInterval
+				}
+				time.Sleep(interval)
+				for _, group := range []*EndpointGroup{C.ConfigEndpoints, C.EventEndpoints} {
+					if group != nil {
+						group.Probe(context.Background())
+					}
+				}
+			}
+		})
+	})
+}
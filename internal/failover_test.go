@@ -0,0 +1,116 @@
+// Package internal ...
+package internal
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointGroup(t *testing.T) {
+	var downFail int32 = 1
+	var downCount int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&downCount, 1)
+		if atomic.LoadInt32(&downFail) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("down"))
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte("up:"+r.URL.Path+":"), body...))
+	}))
+	defer up.Close()
+
+	_, err := NewEndpointGroup("config", nil)
+	assert.NotNil(t, err)
+	_, err = NewEndpointGroup("config", []string{"cache.example.com"})
+	assert.NotNil(t, err)
+	_, err = NewEndpointGroup("config", []string{down.URL, down.URL + "/"})
+	assert.NotNil(t, err)
+	group, err := NewEndpointGroup("config", []string{down.URL, up.URL + "/prefix"})
+	assert.Nil(t, err)
+	var events []*FailoverEvent
+	group.OnFailover = func(event *FailoverEvent) {
+		events = append(events, event)
+	}
+	assert.Equal(t, down.URL, group.Primary())
+	httpClient := &http.Client{Transport: group.RoundTripper(nil)}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://cache.example.com/api", bytes.NewReader([]byte("body")))
+	resp, err := httpClient.Do(req)
+	assert.Nil(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "up:/prefix/api:body", string(body)) // failed over with the body replayed
+	assert.Equal(t, up.URL+"/prefix", group.Primary())
+	assert.Len(t, events, 1)
+	assert.Equal(t, &FailoverEvent{Group: "config", From: down.URL, To: up.URL + "/prefix", Reason: "unhealthy",
+		Err: events[0].Err}, events[0])
+	assert.Contains(t, events[0].Err.Error(), "503")
+	status := group.Status()
+	assert.False(t, status[0].Healthy)
+	assert.True(t, status[1].Primary)
+	assert.NotEmpty(t, status[0].LastError)
+
+	// the primary is kept while healthy, the recovered endpoint is only probed
+	atomic.StoreInt32(&downFail, 0)
+	count := atomic.LoadInt32(&downCount)
+	resp, err = httpClient.Get("https://cache.example.com/api")
+	assert.Nil(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, count, atomic.LoadInt32(&downCount))
+	group.Probe(context.Background())
+	assert.True(t, group.Status()[0].Healthy)
+	assert.Equal(t, count+1, atomic.LoadInt32(&downCount))
+
+	// all endpoints fail, the last error or response is returned
+	atomic.StoreInt32(&downFail, 1)
+	up.Close()
+	resp, err = httpClient.Get("https://cache.example.com/api")
+	if err == nil {
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+	assert.False(t, group.Status()[0].Healthy)
+	assert.False(t, group.Status()[1].Healthy)
+
+	// only the hosts of the endpoints are routed
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("other"))
+	}))
+	defer other.Close()
+	resp, err = (&http.Client{Transport: group.HostRoundTripper(nil)}).Get(other.URL)
+	assert.Nil(t, err)
+	body, _ = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "other", string(body))
+}
+
+func TestEndpointGroupLatency(t *testing.T) {
+	group, err := NewEndpointGroup("event", []string{"https://a.example.com", "https://b.example.com"})
+	assert.Nil(t, err)
+	var events []*FailoverEvent
+	group.OnFailover = func(event *FailoverEvent) {
+		events = append(events, event)
+	}
+	group.observe(0, 100*time.Millisecond, nil)
+	group.observe(1, 80*time.Millisecond, nil) // similar, not switched
+	assert.Equal(t, "https://a.example.com", group.Primary())
+	assert.Empty(t, events)
+	group.observe(1, 10*time.Millisecond, nil) // the moving average is 59ms
+	assert.Equal(t, "https://b.example.com", group.Primary())
+	assert.Equal(t, []*FailoverEvent{{Group: "event", From: "https://a.example.com", To: "https://b.example.com",
+		Reason: "latency"}}, events)
+	assert.Equal(t, []int{1, 0}, group.order())
+}
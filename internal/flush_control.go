@@ -0,0 +1,128 @@
+// Package internal sdk
+package internal
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// flushWindowSize The number of flushes measured by a round of the adaptive flush control,
+// the batch size and the concurrency move once per round
+const flushWindowSize = 32
+
+// FlushController The AIMD control of the exposure flushes, which tunes the batch size and the number of the
+// concurrent flushes by the p99 latency of the recent flushes. While the p99 is within the target, the batch size
+// grows additively up to the maximum, then the concurrency grows by one per round. Once the p99 exceeds the target,
+// both are halved. Concurrent and safe
+type FlushController struct {
+	lock           sync.Mutex
+	cond           *sync.Cond
+	target         time.Duration
+	maxBatch       int
+	maxConcurrency int
+	batchStep      int
+	batch          int
+	concurrency    int
+	inflight       int
+	window         []time.Duration
+	p99            time.Duration
+}
+
+// NewFlushController Create the control starting from the batch size 1 and the maximum concurrency,
+// which are the flushes without the control
+func NewFlushController(target time.Duration, maxBatch int, maxConcurrency int) *FlushController {
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	batchStep := maxBatch / 16
+	if batchStep < 1 {
+		batchStep = 1
+	}
+	c := &FlushController{
+		target:         target,
+		maxBatch:       maxBatch,
+		maxConcurrency: maxConcurrency,
+		batchStep:      batchStep,
+		batch:          1,
+		concurrency:    maxConcurrency,
+		window:         make([]time.Duration, 0, flushWindowSize),
+	}
+	c.cond = sync.NewCond(&c.lock)
+	return c
+}
+
+// IsConfiguredWith Whether the control is created with the target and the maximum batch size
+func (c *FlushController) IsConfiguredWith(target time.Duration, maxBatch int) bool {
+	return c.target == target && c.maxBatch == maxBatch
+}
+
+// BatchSize The current number of the items of a flush
+func (c *FlushController) BatchSize() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.batch
+}
+
+// Acquire Wait until the number of the running flushes is below the current concurrency,
+// each Acquire must be followed by a Release
+func (c *FlushController) Acquire() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for c.inflight >= c.concurrency {
+		c.cond.Wait()
+	}
+	c.inflight++
+}
+
+// Release Finish a flush with its latency, and adjust the batch size and the concurrency once the round is measured
+func (c *FlushController) Release(latency time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.inflight--
+	c.window = append(c.window, latency)
+	if len(c.window) >= flushWindowSize {
+		c.adjust()
+	}
+	c.cond.Broadcast()
+}
+
+func (c *FlushController) adjust() {
+	sort.Slice(c.window, func(i, j int) bool {
+		return c.window[i] < c.window[j]
+	})
+	c.p99 = c.window[(len(c.window)*99-1)/100]
+	c.window = c.window[:0]
+	if c.p99 > c.target {
+		c.batch = halve(c.batch)
+		c.concurrency = halve(c.concurrency)
+		return
+	}
+	if c.batch < c.maxBatch {
+		c.batch += c.batchStep
+		if c.batch > c.maxBatch {
+			c.batch = c.maxBatch
+		}
+		return
+	}
+	if c.concurrency < c.maxConcurrency {
+		c.concurrency++
+	}
+}
+
+func halve(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return n / 2
+}
+
+// Stats The current batch size, concurrency and the p99 latency of the last round
+func (c *FlushController) Stats() (batch int, concurrency int, p99 time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.batch, c.concurrency, c.p99
+}
@@ -0,0 +1,68 @@
+// Package internal sdk
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flushRound Measure a round of flushes of the latency
+func flushRound(c *FlushController, latency time.Duration) {
+	for i := 0; i < flushWindowSize; i++ {
+		c.Acquire()
+		c.Release(latency)
+	}
+}
+
+func TestFlushController(t *testing.T) {
+	c := NewFlushController(10*time.Millisecond, 32, 4)
+	assert.True(t, c.IsConfiguredWith(10*time.Millisecond, 32))
+	assert.False(t, c.IsConfiguredWith(10*time.Millisecond, 16))
+	tests := []struct {
+		name            string
+		latency         time.Duration
+		wantBatch       int
+		wantConcurrency int
+	}{
+		{name: "within target grows the batch", latency: time.Millisecond, wantBatch: 3, wantConcurrency: 4},
+		{name: "over target halves both", latency: 20 * time.Millisecond, wantBatch: 1, wantConcurrency: 2},
+		{name: "halved down to 1", latency: 20 * time.Millisecond, wantBatch: 1, wantConcurrency: 1},
+		{name: "within target again", latency: time.Millisecond, wantBatch: 3, wantConcurrency: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flushRound(c, tt.latency)
+			batch, concurrency, p99 := c.Stats()
+			assert.Equal(t, tt.wantBatch, batch)
+			assert.Equal(t, tt.wantConcurrency, concurrency)
+			assert.Equal(t, tt.latency, p99)
+			assert.Equal(t, batch, c.BatchSize())
+		})
+	}
+	for i := 0; i < 20; i++ { // the batch reaches the maximum before the concurrency grows
+		flushRound(c, time.Millisecond)
+	}
+	batch, concurrency, _ := c.Stats()
+	assert.Equal(t, 32, batch)
+	assert.Equal(t, 4, concurrency)
+}
+
+func TestFlushControllerAcquire(t *testing.T) {
+	c := NewFlushController(time.Millisecond, 1, 1)
+	c.Acquire()
+	acquired := make(chan struct{})
+	go func() {
+		c.Acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired over the concurrency")
+	case <-time.After(20 * time.Millisecond):
+	}
+	c.Release(0)
+	<-acquired
+	c.Release(0)
+}
@@ -2,7 +2,12 @@
 package internal
 
 import (
+	"io"
+	"net/http"
+	"time"
+
 	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal/tracing"
 	"github.com/abetterchoice/protoc_cache_server"
 )
 
@@ -31,6 +36,139 @@ type GlobalConfig struct {
 	RegionCode string `json:"regionCode"`
 	// secretKey, used for authentication
 	SecretKey string `json:"secretKey"`
+	// http client of the control-plane requests, such as fetching configuration and dmp, nil uses the default
+	HTTPClient *http.Client `json:"-"`
+	// The source of the expiring secretKeys of the control-plane requests, nil uses SecretKey
+	ConfigTokenSource TokenSource `json:"-"`
+	// The source of the expiring reporting tokens, nil uses the token of the remote configuration
+	EventTokenSource TokenSource `json:"-"`
+	// How long before the expiry the tokens are refreshed in the background, 1 minute by default
+	TokenRefreshAhead time.Duration `json:"tokenRefreshAhead,omitempty"`
+	// The proxy of the control-plane requests, see ConfigProxy
+	ConfigProxy string `json:"configProxy,omitempty"`
+	// The proxy of the event reporting requests, see EventProxy
+	EventProxy string `json:"eventProxy,omitempty"`
+	// The egress control of the connections of the control-plane requests, nil dials as usual
+	Egress *EgressPolicy `json:"-"`
+	// The regional endpoints of the cache service with the failover, nil uses the address of the environment
+	ConfigEndpoints *EndpointGroup `json:"-"`
+	// The interchangeable endpoints of the event reporting sent through the event transport, nil disables the failover
+	EventEndpoints *EndpointGroup `json:"-"`
+	// The address of the relay proxy serving the cached configuration, consulted once the requests to the cache
+	// service fail RelayFailureThreshold times in a row, empty disables it
+	RelayAddr string `json:"relayAddr,omitempty"`
+	// The number of the consecutive failures of the cache service before the relay is consulted
+	RelayFailureThreshold int `json:"relayFailureThreshold,omitempty"`
+	// Interval of the health probes of the endpoints, zero uses the default 30 seconds
+	EndpointProbeInterval time.Duration `json:"endpointProbeInterval,omitempty"`
+	// Wrap the transport of the control-plane requests, the first one is the outermost
+	TransportMiddlewares []func(next http.RoundTripper) http.RoundTripper `json:"-"`
+	// Create spans for evaluation, refresh and exposure reporting if not nil
+	TracerProvider tracing.TracerProvider `json:"-"`
+	// Whether to allow partial success of Init. If enabled, the failure of one projectID does not affect the others,
+	// default false
+	IsPartialInit bool `json:"isPartialInit"`
+	// Callback of background task errors, such as local cache refresh failure and panic of the background goroutine
+	OnBackgroundError BackgroundErrorHandler `json:"-"`
+	// The scheduler of the background tasks, nil runs each task in its own goroutine
+	Scheduler Scheduler `json:"-"`
+	// Callback of the errors that lose data, such as exposure of a projectID whose config is not loaded
+	OnError ErrorHandler `json:"-"`
+	// Callback of the misconfiguration that does not fail the API, such as missing exposure reporting config
+	OnWarning ErrorHandler `json:"-"`
+	// Callback of the configuration revision applied to the local cache
+	OnConfigApplied ConfigAppliedHandler `json:"-"`
+	// The store of the anonymous-to-known identity aliases, nil uses the in-memory store
+	AliasStore AliasStore `json:"-"`
+	// The salts of the projects in the exposure privacy mode, key is projectID, the unitIDs of the exposures of the
+	// projects are hashed with the salts before reporting
+	UnitIDHashSalts map[string]string `json:"-"`
+	// The migrations of the re-keyed experiments, key is projectID, value maps the old experiment keys to the new,
+	// the new experiments are hashed as the old ones, see cache.Application.ExperimentMigrationIndex
+	ExperimentKeyMigrations map[string]map[string]string `json:"experimentKeyMigrations,omitempty"`
+	// The encoding of the expanded data of the remote config exposures, empty uses the legacy key=value;key=value
+	ExpandedDataEncoding string `json:"expandedDataEncoding"`
+	// The registered custom unitID types, key is the unitID type
+	UnitIDTypes map[int32]*UnitIDTypeInfo `json:"unitIdTypes,omitempty"`
+	// The maximum number of the cached results of the remote configs only depending on the attributes of each
+	// configuration revision, zero uses the default 10000, negative disables the cache
+	RemoteConfigCacheSize int `json:"remoteConfigCacheSize"`
+	// The maximum number of the memoized layer evaluations of the process, zero disables the memo, see
+	// EvaluationMemoTTL
+	EvaluationMemoSize int `json:"evaluationMemoSize"`
+	// The time the memoized layer evaluations are served, the refresh of the configuration invalidates them before
+	EvaluationMemoTTL time.Duration `json:"evaluationMemoTTL"`
+	// The file of the state handed off across the restarts of the binary, see WithStateHandoff, empty disables it
+	StateHandoffPath string `json:"stateHandoffPath,omitempty"`
+	// The sink of the sampled assignment decisions, nil disables the assignment export
+	AssignmentSink AssignmentSink `json:"-"`
+	// One of every AssignmentSamplingInterval units has its assignment decisions exported
+	AssignmentSamplingInterval uint32 `json:"assignmentSamplingInterval"`
+	// Interval of the exposure loss report event, zero uses the default 1 minute, negative disables the report
+	LossReportInterval time.Duration `json:"lossReportInterval"`
+	// Whether to add a unique event_id to the extended details of each exposure and monitor event, so that the
+	// duplicates of the at-least-once delivery can be dropped downstream, default false
+	IsEnableEventID bool `json:"isEnableEventId"`
+	// The extractors of the values of the ctx merged into the extended details of the exposures and the monitor
+	// events, see ContextData
+	ContextExtractors []ContextExtractor `json:"-"`
+	// Whether to disable the call site capture of the monitor events, default false
+	IsDisableInvokePath bool `json:"isDisableInvokePath"`
+	// The number of frames of the call site capture, zero uses the default 1
+	InvokePathDepth int `json:"invokePathDepth"`
+	// Evaluations and exposure flushes exceeding the threshold emit a slow_op monitor event, zero disables it
+	SlowOpThreshold time.Duration `json:"slowOpThreshold"`
+	// The time budget of each evaluation, the layers not evaluated within it fall back to the default groups,
+	// zero disables it
+	EvaluationTimeout time.Duration `json:"evaluationTimeout"`
+	// The estimated offset of the local clock from the control plane beyond which the time-based targeting and the
+	// request signatures use the corrected time, zero disables the correction, see Now
+	ClockSkewTolerance time.Duration `json:"clockSkewTolerance"`
+	// The time the dmp tag results primed by PrimeAssignments are kept, zero uses the default 10 minutes
+	PrimeTTL time.Duration `json:"primeTTL"`
+	// Fetch the large objects pointed by the remote configs, nil uses an http GET, see FetchBlob
+	BlobFetcher BlobFetcher `json:"-"`
+	// The maximum size of a large object, zero uses the default 64MB
+	MaxBlobSize int64 `json:"maxBlobSize,omitempty"`
+	// The maximum total size of the cached large objects, zero uses the default 256MB
+	BlobCacheSize int64 `json:"blobCacheSize,omitempty"`
+	// The fraction of the evaluations of GetExperiments whose full traces are written to TraceWriter, zero disables it
+	TraceSamplingRate float64 `json:"traceSamplingRate,omitempty"`
+	// The writer of the sampled traces in JSONL, such as a rotating file
+	TraceWriter io.Writer `json:"-"`
+	// The cipher of the data written at rest by the SDK, such as the sampled traces, nil writes the plaintext
+	Cipher *Cipher `json:"-"`
+	// The derivers of the attributes run in order on the attributes of each user context, see AttributeDeriver
+	AttributeDerivers []AttributeDeriver `json:"-"`
+	// The evaluations of the projectIDs whose configuration has not been refreshed successfully within it fail with
+	// ErrConfigStale, zero disables it
+	MaxConfigAge time.Duration `json:"maxConfigAge"`
+	// The sliding window of the sample ratio mismatch detection, zero disables the detection
+	SRMWindow time.Duration `json:"srmWindow"`
+	// The p-value of the chi-square test of the realized group counts below which a srm_alert is emitted
+	SRMThreshold float64 `json:"srmThreshold"`
+	// The window of the unused variant detection, zero disables the detection
+	UnusedVariantWindow time.Duration `json:"unusedVariantWindow"`
+	// Interval of the flag usage report event, zero disables the report
+	UsageReportInterval time.Duration `json:"usageReportInterval"`
+	// Interval of the config revision report event, zero disables the report
+	ConfigRevisionReportInterval time.Duration `json:"configRevisionReportInterval"`
+	// Whether to disable the reuse of the exposure and monitor event messages, for debugging the plugins, default false
+	IsDisableMessagePool bool `json:"isDisableMessagePool"`
+	// The busy fraction of one CPU that the exposure consumers may take, such as 0.1, exceeding it sheds the reporting
+	// load, zero disables the CPU guardrail
+	GuardrailCPUBudget float64 `json:"guardrailCpuBudget"`
+	// The heap bytes of the process above which the reporting load is shed, zero disables the memory guardrail
+	GuardrailMemoryBudget uint64 `json:"guardrailMemoryBudget"`
+	// The target p99 latency of the experiment exposure flushes, the batch size and the concurrency of the flushes
+	// are tuned to stay within it, zero disables the adaptive flush and every exposure is flushed alone
+	AdaptiveFlushTarget time.Duration `json:"adaptiveFlushTarget"`
+	// The maximum number of the exposures of a flush tuned by the adaptive flush
+	AdaptiveFlushMaxBatch int `json:"adaptiveFlushMaxBatch"`
+	// Whether to run in the deterministic simulation mode, see SimulationSeed
+	IsSimulation bool `json:"isSimulation"`
+	// The seed of the PRNG driving the sampling decisions in the simulation mode
+	SimulationSeed int64 `json:"simulationSeed"`
 }
 
 // C global configuration related instances, no need to lock,
@@ -0,0 +1,89 @@
+// Package internal sdk
+package internal
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal/random"
+)
+
+// MaxShedLevel The maximum load shedding level, the sampling intervals are multiplied by 64 at most
+const MaxShedLevel = 6
+
+var (
+	// shedLevel The current load shedding level, 0 means no shedding
+	shedLevel int32
+	// busyNanos The busy time of the exposure consumers since the last round of the guardrail
+	busyNanos int64
+)
+
+// GuardrailUsage The resource usage measured by a round of the guardrail
+type GuardrailUsage struct {
+	// The busy fraction of one CPU of the exposure consumers
+	CPU float64
+	// The heap bytes of the process
+	HeapBytes uint64
+}
+
+// IsGuardrailEnabled Whether any budget of the guardrail is set
+func IsGuardrailEnabled() bool {
+	return C.GuardrailCPUBudget > 0 || C.GuardrailMemoryBudget > 0
+}
+
+// RecordBusy Add the busy time of an exposure consumer since start, concurrent and safe
+func RecordBusy(start time.Time) {
+	atomic.AddInt64(&busyNanos, int64(time.Since(start)))
+}
+
+// TakeBusy Get and clear the busy time recorded since the last call
+func TakeBusy() time.Duration {
+	return time.Duration(atomic.SwapInt64(&busyNanos, 0))
+}
+
+// ShedLevel The current load shedding level
+func ShedLevel() int {
+	return int(atomic.LoadInt32(&shedLevel))
+}
+
+// UpdateShedLevel Raise the shed level by one if the usage exceeds any budget, lower it by one if the usage is
+// below half of every budget, otherwise keep it, so that the level does not flap around the budget.
+// Return the new level
+func UpdateShedLevel(usage GuardrailUsage) int {
+	level := ShedLevel()
+	cpuBudget, memoryBudget := C.GuardrailCPUBudget, C.GuardrailMemoryBudget
+	over := (cpuBudget > 0 && usage.CPU > cpuBudget) || (memoryBudget > 0 && usage.HeapBytes > memoryBudget)
+	under := (cpuBudget <= 0 || usage.CPU < cpuBudget/2) && (memoryBudget == 0 || usage.HeapBytes < memoryBudget/2)
+	switch {
+	case over && level < MaxShedLevel:
+		level++
+	case under && level > 0:
+		level--
+	}
+	atomic.StoreInt32(&shedLevel, int32(level))
+	return level
+}
+
+// ShedSampled Whether the item survives the load shedding, one in 2^level items is kept
+func ShedSampled() bool {
+	level := ShedLevel()
+	if level == 0 {
+		return true
+	}
+	return random.Int63n(1<<uint(level)) == 0
+}
+
+// ShedCapacity The capacity of the queue shrunk by the load shedding, at least 1
+func ShedCapacity(capacity int) int {
+	shrunk := capacity >> uint(ShedLevel())
+	if shrunk < 1 {
+		return 1
+	}
+	return shrunk
+}
+
+// ResetGuardrail Clear the shed level and the busy time, called by Release
+func ResetGuardrail() {
+	atomic.StoreInt32(&shedLevel, 0)
+	atomic.StoreInt64(&busyNanos, 0)
+}
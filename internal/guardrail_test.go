@@ -0,0 +1,42 @@
+// Package internal sdk
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateShedLevel(t *testing.T) {
+	defer func() {
+		C = &GlobalConfig{}
+		ResetGuardrail()
+	}()
+	C = &GlobalConfig{GuardrailCPUBudget: 0.1, GuardrailMemoryBudget: 1000}
+	tests := []struct {
+		name  string
+		usage GuardrailUsage
+		want  int
+	}{
+		{name: "under budget", usage: GuardrailUsage{CPU: 0.01, HeapBytes: 100}, want: 0},
+		{name: "cpu over budget", usage: GuardrailUsage{CPU: 0.2, HeapBytes: 100}, want: 1},
+		{name: "memory over budget", usage: GuardrailUsage{CPU: 0.01, HeapBytes: 2000}, want: 2},
+		{name: "between half and budget keeps the level", usage: GuardrailUsage{CPU: 0.08, HeapBytes: 100}, want: 2},
+		{name: "under half of the budgets", usage: GuardrailUsage{CPU: 0.01, HeapBytes: 100}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UpdateShedLevel(tt.usage))
+			assert.Equal(t, tt.want, ShedLevel())
+		})
+	}
+	for i := 0; i < 2*MaxShedLevel; i++ {
+		UpdateShedLevel(GuardrailUsage{CPU: 1})
+	}
+	assert.Equal(t, MaxShedLevel, ShedLevel())
+	assert.Equal(t, 100>>MaxShedLevel, ShedCapacity(100))
+	assert.Equal(t, 1, ShedCapacity(10))
+	ResetGuardrail()
+	assert.Equal(t, 100, ShedCapacity(100))
+	assert.True(t, ShedSampled())
+}
@@ -0,0 +1,115 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+// ErrorReason The typed reason of an error or warning passed to OnError and OnWarning
+type ErrorReason string
+
+const (
+	// ReasonConfigNotLoaded The local cache of the projectID is not loaded, usually the projectID is not passed in Init
+	ReasonConfigNotLoaded ErrorReason = "config_not_loaded"
+	// ReasonMetricsConfigMissing The remote configuration has no exposure reporting config, the exposure is dropped
+	ReasonMetricsConfigMissing ErrorReason = "metrics_config_missing"
+	// ReasonMetricsPluginNotFound The metrics plugin named by the reporting config is not registered
+	ReasonMetricsPluginNotFound ErrorReason = "metrics_plugin_not_found"
+	// ReasonQueueFull The asynchronous reporting queue is full, the exposure is dropped
+	ReasonQueueFull ErrorReason = "queue_full"
+	// ReasonReportFailure The metrics plugin failed to report the exposure
+	ReasonReportFailure ErrorReason = "report_failure"
+	// ReasonTokenFailure The TokenSource failed to acquire or refresh the token
+	ReasonTokenFailure ErrorReason = "token_failure"
+	// ReasonEndpointFailover The primary endpoint of the control plane or the event reporting changed, a warning
+	ReasonEndpointFailover ErrorReason = "endpoint_failover"
+	// ReasonRuleTimeout The evaluation exceeded its time budget, the remaining layers or the remote config fell back
+	// to the default, a warning
+	ReasonRuleTimeout ErrorReason = "rule_timeout"
+	// ReasonRateLimit The evaluation exceeded the limits of the projectID and failed with ErrRateLimited, a warning
+	ReasonRateLimit ErrorReason = "rate_limit"
+	// ReasonRelayFallback The cache service kept failing and the configuration is fetched from the relay, a warning
+	ReasonRelayFallback ErrorReason = "relay_fallback"
+	// ReasonHandoffFallback The cache service failed to load the projectID at startup and the snapshot handed off by
+	// the previous process is served, a warning
+	ReasonHandoffFallback ErrorReason = "handoff_fallback"
+)
+
+// ErrorHandler Callback of OnError and OnWarning. It may be called on the hot path, so it should return quickly
+type ErrorHandler func(reason ErrorReason, projectID string, err error)
+
+// ReportError Keep the error in the recent errors and pass it to the registered OnError,
+// a panic inside the callback is recovered
+func ReportError(reason ErrorReason, projectID string, err error) {
+	if err == nil {
+		return
+	}
+	RecordError(string(reason)+":"+projectID, err)
+	callErrorHandler(C.OnError, reason, projectID, err)
+}
+
+// ReportWarning Pass the warning to the registered OnWarning, a panic inside the callback is recovered. The callers on
+// the hot path check IsWarningHandled first, so that the warning is not built for nothing
+func ReportWarning(reason ErrorReason, projectID string, err error) {
+	if err == nil {
+		return
+	}
+	callErrorHandler(C.OnWarning, reason, projectID, err)
+}
+
+// IsWarningHandled Whether OnWarning is registered
+func IsWarningHandled() bool {
+	return C.OnWarning != nil
+}
+
+func callErrorHandler(handler ErrorHandler, reason ErrorReason, projectID string, err error) {
+	if handler == nil {
+		return
+	}
+	defer func() {
+		recoverErr := recover()
+		if recoverErr != nil {
+			log.Log(context.Background(), log.ErrorLevel, "error handler panic", log.Any("projectID", projectID),
+				log.Any("reason", reason), log.Any("recover", recoverErr))
+		}
+	}()
+	handler(reason, projectID, err)
+}
+
+// ConfigChange The configuration revision applied to the local cache of a projectID, passed to OnConfigApplied
+type ConfigChange struct {
+	ProjectID string `json:"projectId"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	// The revision replaced, empty on the first load
+	PreviousRevision string `json:"previousRevision"`
+	// The layers whose groups, parameters or traffic changed, including the added and the removed ones, sorted
+	ChangedLayerKeys []string `json:"changedLayerKeys,omitempty"`
+	// The feature flags whose content changed, including the added and the removed ones, sorted
+	ChangedFeatureFlagKeys []string `json:"changedFeatureFlagKeys,omitempty"`
+	// The time when the revision was applied
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// ConfigAppliedHandler Callback of OnConfigApplied
+type ConfigAppliedHandler func(change *ConfigChange)
+
+// ReportConfigApplied Pass the applied configuration to the registered OnConfigApplied,
+// a panic inside the callback is recovered
+func ReportConfigApplied(change *ConfigChange) {
+	handler := C.OnConfigApplied
+	if handler == nil || change == nil {
+		return
+	}
+	defer func() {
+		recoverErr := recover()
+		if recoverErr != nil {
+			log.Log(context.Background(), log.ErrorLevel, "config applied handler panic",
+				log.Any("projectID", change.ProjectID), log.Any("recover", recoverErr))
+		}
+	}()
+	handler(change)
+}
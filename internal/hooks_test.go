@@ -0,0 +1,61 @@
+// Package internal sdk
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportError(t *testing.T) {
+	defer func(c *GlobalConfig) { C = c }(C)
+	defer ResetRecentErrors()
+	tests := []struct {
+		name       string
+		hasHandler bool
+		err        error
+		want       int
+		wantRecent int
+	}{
+		{name: "no handler", hasHandler: false, err: errors.New("mock err"), want: 0, wantRecent: 1},
+		{name: "nil err", hasHandler: true, err: nil, want: 0, wantRecent: 0},
+		{name: "normal", hasHandler: true, err: errors.New("mock err"), want: 1, wantRecent: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetRecentErrors()
+			var got []ErrorReason
+			C = &GlobalConfig{}
+			if tt.hasHandler {
+				C.OnError = func(reason ErrorReason, projectID string, err error) { got = append(got, reason) }
+				C.OnWarning = func(reason ErrorReason, projectID string, err error) { panic("should not be called") }
+			}
+			ReportError(ReasonQueueFull, "123", tt.err)
+			assert.Equal(t, tt.want, len(got))
+			assert.Equal(t, tt.wantRecent, len(RecentErrors()))
+		})
+	}
+}
+
+func TestReportWarning(t *testing.T) {
+	defer func(c *GlobalConfig) { C = c }(C)
+	defer ResetRecentErrors()
+	ResetRecentErrors()
+	var got ErrorReason
+	C = &GlobalConfig{OnWarning: func(reason ErrorReason, projectID string, err error) {
+		got = reason
+		panic("recovered")
+	}}
+	ReportWarning(ReasonMetricsConfigMissing, "123", errors.New("mock err"))
+	assert.Equal(t, ReasonMetricsConfigMissing, got)
+	assert.Equal(t, 0, len(RecentErrors())) // warnings are not kept
+}
+
+func TestIsWarningHandled(t *testing.T) {
+	defer func(c *GlobalConfig) { C = c }(C)
+	C = &GlobalConfig{}
+	assert.False(t, IsWarningHandled())
+	C.OnWarning = func(reason ErrorReason, projectID string, err error) {}
+	assert.True(t, IsWarningHandled())
+}
@@ -0,0 +1,85 @@
+// Package internal sdk
+package internal
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// LossReason The reason why the exposure is not reported
+type LossReason string
+
+// const ...
+const (
+	// LossReasonSampling Discarded by the exposure sampling
+	LossReasonSampling LossReason = "sampling"
+	// LossReasonRateLimit Discarded by the reporting rate limit
+	LossReasonRateLimit LossReason = "rate_limit"
+	// LossReasonQueueFull Discarded because the asynchronous reporting queue is full
+	LossReasonQueueFull LossReason = "queue_full"
+	// LossReasonFailure The metrics plugin failed to report
+	LossReasonFailure LossReason = "failure"
+	// LossReasonShed Discarded by the load shedding of the guardrail, see GlobalConfig.GuardrailCPUBudget
+	LossReasonShed LossReason = "shed"
+)
+
+// LossReasons All loss reasons
+var LossReasons = []LossReason{LossReasonSampling, LossReasonRateLimit, LossReasonQueueFull, LossReasonFailure,
+	LossReasonShed}
+
+type lossCounter struct {
+	counts [5]uint64 // the same order as LossReasons
+}
+
+// lossIndex key is projectID, value is *lossCounter
+var lossIndex sync.Map
+
+// RecordLoss Count the exposures of the projectID discarded for the reason, concurrent and safe
+func RecordLoss(projectID string, reason LossReason, count int) {
+	if count <= 0 {
+		return
+	}
+	index := lossReasonIndex(reason)
+	if index < 0 {
+		return
+	}
+	counter, ok := lossIndex.Load(projectID)
+	if !ok {
+		counter, _ = lossIndex.LoadOrStore(projectID, &lossCounter{})
+	}
+	atomic.AddUint64(&counter.(*lossCounter).counts[index], uint64(count))
+}
+
+// LossCounts The cumulative number of the discarded exposures of the projectID by reason since Init,
+// the reasons without loss are omitted
+func LossCounts(projectID string) map[LossReason]uint64 {
+	result := make(map[LossReason]uint64)
+	counter, ok := lossIndex.Load(projectID)
+	if !ok {
+		return result
+	}
+	for i, reason := range LossReasons {
+		count := atomic.LoadUint64(&counter.(*lossCounter).counts[i])
+		if count > 0 {
+			result[reason] = count
+		}
+	}
+	return result
+}
+
+// ResetLoss Clear all loss counters, called by Release
+func ResetLoss() {
+	lossIndex.Range(func(key, value interface{}) bool {
+		lossIndex.Delete(key)
+		return true
+	})
+}
+
+func lossReasonIndex(reason LossReason) int {
+	for i := range LossReasons {
+		if LossReasons[i] == reason {
+			return i
+		}
+	}
+	return -1
+}
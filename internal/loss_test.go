@@ -0,0 +1,22 @@
+// Package internal sdk
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordLoss(t *testing.T) {
+	ResetLoss()
+	defer ResetLoss()
+	assert.Equal(t, map[LossReason]uint64{}, LossCounts("123"))
+	RecordLoss("123", LossReasonSampling, 3)
+	RecordLoss("123", LossReasonSampling, 2)
+	RecordLoss("123", LossReasonQueueFull, 1)
+	RecordLoss("123", LossReasonFailure, 0)
+	RecordLoss("123", LossReason("unknown"), 1)
+	RecordLoss("456", LossReasonFailure, 1)
+	assert.Equal(t, map[LossReason]uint64{LossReasonSampling: 5, LossReasonQueueFull: 1}, LossCounts("123"))
+	assert.Equal(t, map[LossReason]uint64{LossReasonFailure: 1}, LossCounts("456"))
+}
@@ -0,0 +1,236 @@
+// Package internal sdk
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// ProjectOptions Runtime options of a single projectID, which take precedence over the remote configuration.
+// The zero value of each field means not set, and the remote configuration or the default value is used
+type ProjectOptions struct {
+	// Sampling interval of the exposure reporting, such as experiment, feature flag and remote config exposure
+	ExposureSamplingInterval uint32 `json:"exposureSamplingInterval"`
+	// Sampling interval of the monitor event reporting, the error events keep the remote error sampling interval
+	EventSamplingInterval uint32 `json:"eventSamplingInterval"`
+	// Local cache refresh interval
+	RefreshInterval time.Duration `json:"refreshInterval"`
+	// Timeout of each local cache refresh
+	RefreshTimeout time.Duration `json:"refreshTimeout"`
+	// The maximum number of pending items in the asynchronous reporting queue, the exposure of the projectID is
+	// discarded when the queue exceeds it. It can only be smaller than the queue capacity
+	QueueSize int `json:"queueSize"`
+	// How hard the reporting of the exposures tries before giving up, empty uses DeliveryBestEffort
+	DeliveryGuarantee DeliveryGuarantee `json:"deliveryGuarantee,omitempty"`
+	// Whether the Log* APIs return ErrBackpressure without reporting while the exposure reporting is saturated
+	IsBackpressure bool `json:"isBackpressure"`
+	// Kill switch of the exposure reporting of the projectID, see GlobalConfig.IsDisableReport
+	IsDisableReport bool `json:"isDisableReport"`
+	// Whether the experiments of the scenes log the exposures automatically, key is sceneID. It overrides the
+	// default automatic exposure but not WithAutomatic of the evaluation. The experiment of several scenes is
+	// automatic only if none of its scenes is disabled
+	SceneAutomatic map[int64]bool `json:"sceneAutomatic,omitempty"`
+	// The tags of the experiments, key is experimentKey, such as the product area or the platform of the experiment,
+	// used by the experiment tag filter of the evaluation
+	ExperimentTags map[string][]string `json:"experimentTags,omitempty"`
+	// What GetExperiment returns when the layer or the projectID is missing or no group is hit, empty uses
+	// MissBehaviorError
+	MissBehavior MissBehavior `json:"missBehavior,omitempty"`
+	// The params of the default groups of MissBehaviorDefault, key is layerKey
+	MissDefaults map[string]map[string]string `json:"missDefaults,omitempty"`
+	// The maximum evaluations per second of the projectID, the evaluations above it fail with ErrRateLimited
+	EvaluationQPS float64 `json:"evaluationQPS,omitempty"`
+	// The maximum number of the evaluations of the projectID in progress at the same time
+	MaxConcurrentEvaluations int `json:"maxConcurrentEvaluations,omitempty"`
+	// The maximum exposures per second of the projectID, the exposures above it are discarded
+	ExposureQPS float64 `json:"exposureQPS,omitempty"`
+}
+
+// MissBehavior What GetExperiment returns on a miss
+type MissBehavior string
+
+const (
+	// MissBehaviorError The missing layer or projectID returns the error, no group hit returns nil, the default
+	MissBehaviorError MissBehavior = "error"
+	// MissBehaviorZero A miss returns a default group of the layer without params, the param getters of which
+	// return the zero values with env.ErrParamKeyNotFound
+	MissBehaviorZero MissBehavior = "zero"
+	// MissBehaviorDefault A miss returns the default group of the layer registered in MissDefaults, or the one of
+	// MissBehaviorZero if none is registered
+	MissBehaviorDefault MissBehavior = "default"
+)
+
+// projectOptionsIndex key is projectID, value is *ProjectOptions. The value is never modified after being stored
+var (
+	projectOptionsIndex sync.Map
+	// projectOptionsLock Serialize the updates, so that the read-modify-write of UpdateProjectOptions loses none
+	projectOptionsLock sync.Mutex
+)
+
+// UpdateProjectOptions Apply update to a copy of the runtime options of the projectID and store it, unless update
+// fails. Return the options before, nil if not set, and after the update. Concurrent and safe, the concurrent updates
+// of the same projectID are applied one after another
+func UpdateProjectOptions(projectID string, update func(options *ProjectOptions) error) (*ProjectOptions,
+	*ProjectOptions, error) {
+	projectOptionsLock.Lock()
+	defer projectOptionsLock.Unlock()
+	before := GetProjectOptions(projectID)
+	options := &ProjectOptions{}
+	if before != nil {
+		options = before.Copy()
+	}
+	if err := update(options); err != nil {
+		return nil, nil, err
+	}
+	projectOptionsIndex.Store(projectID, options.Copy()) // the options may keep the maps of the caller
+	return before, options, nil
+}
+
+// SetProjectOptions Replace the runtime options of the projectID, nil clears them, concurrent and safe
+func SetProjectOptions(projectID string, options *ProjectOptions) {
+	projectOptionsLock.Lock()
+	defer projectOptionsLock.Unlock()
+	if options == nil {
+		projectOptionsIndex.Delete(projectID)
+		return
+	}
+	projectOptionsIndex.Store(projectID, options.Copy()) // the caller may reuse the instance
+}
+
+// Copy Deep copy the options
+func (o *ProjectOptions) Copy() *ProjectOptions {
+	result := *o
+	if o.SceneAutomatic != nil {
+		result.SceneAutomatic = make(map[int64]bool, len(o.SceneAutomatic))
+		for sceneID, isAutomatic := range o.SceneAutomatic {
+			result.SceneAutomatic[sceneID] = isAutomatic
+		}
+	}
+	if o.ExperimentTags != nil {
+		result.ExperimentTags = make(map[string][]string, len(o.ExperimentTags))
+		for experimentKey, tags := range o.ExperimentTags {
+			result.ExperimentTags[experimentKey] = append([]string(nil), tags...)
+		}
+	}
+	if o.MissDefaults != nil {
+		result.MissDefaults = make(map[string]map[string]string, len(o.MissDefaults))
+		for layerKey, params := range o.MissDefaults {
+			result.MissDefaults[layerKey] = make(map[string]string, len(params))
+			for key, value := range params {
+				result.MissDefaults[layerKey][key] = value
+			}
+		}
+	}
+	return &result
+}
+
+// GetProjectOptions Get the runtime options of the projectID, return nil if not set
+func GetProjectOptions(projectID string) *ProjectOptions {
+	result, ok := projectOptionsIndex.Load(projectID)
+	if !ok {
+		return nil
+	}
+	options, ok := result.(*ProjectOptions)
+	if !ok {
+		return nil
+	}
+	return options
+}
+
+// ResetProjectOptions Clear all runtime options, called by Release
+func ResetProjectOptions() {
+	projectOptionsLock.Lock()
+	defer projectOptionsLock.Unlock()
+	projectOptionsIndex.Range(func(key, value interface{}) bool {
+		projectOptionsIndex.Delete(key)
+		return true
+	})
+}
+
+// ExposureSamplingInterval The exposure sampling interval of the projectID, fall back to interval if not set
+func ExposureSamplingInterval(projectID string, interval uint32) uint32 {
+	options := GetProjectOptions(projectID)
+	if options != nil && options.ExposureSamplingInterval != 0 {
+		return options.ExposureSamplingInterval
+	}
+	return interval
+}
+
+// EventSamplingInterval The monitor event sampling interval of the projectID, fall back to interval if not set
+func EventSamplingInterval(projectID string, interval uint32) uint32 {
+	options := GetProjectOptions(projectID)
+	if options != nil && options.EventSamplingInterval != 0 {
+		return options.EventSamplingInterval
+	}
+	return interval
+}
+
+// RefreshInterval The local cache refresh interval of the projectID, return 0 if not set
+func RefreshInterval(projectID string) time.Duration {
+	options := GetProjectOptions(projectID)
+	if options == nil {
+		return 0
+	}
+	return options.RefreshInterval
+}
+
+// RefreshTimeout The local cache refresh timeout of the projectID, return 0 if not set
+func RefreshTimeout(projectID string) time.Duration {
+	options := GetProjectOptions(projectID)
+	if options == nil {
+		return 0
+	}
+	return options.RefreshTimeout
+}
+
+// QueueSize The maximum number of pending reporting items of the projectID, return 0 if not set
+func QueueSize(projectID string) int {
+	options := GetProjectOptions(projectID)
+	if options == nil {
+		return 0
+	}
+	return options.QueueSize
+}
+
+// IsReportDisabled Whether the exposure reporting of the projectID is disabled globally or by the kill switch
+func IsReportDisabled(projectID string) bool {
+	if C.IsDisableReport {
+		return true
+	}
+	options := GetProjectOptions(projectID)
+	return options != nil && options.IsDisableReport
+}
+
+// IsBackpressureEnabled Whether the Log* APIs of the projectID signal the saturated reporting
+func IsBackpressureEnabled(projectID string) bool {
+	options := GetProjectOptions(projectID)
+	return options != nil && options.IsBackpressure
+}
+
+// SceneAutomatic The automatic exposure of the scenes of the projectID, return nil if not set
+func SceneAutomatic(projectID string) map[int64]bool {
+	options := GetProjectOptions(projectID)
+	if options == nil {
+		return nil
+	}
+	return options.SceneAutomatic
+}
+
+// ExperimentTags The tags of the experiments of the projectID, return nil if not set
+func ExperimentTags(projectID string) map[string][]string {
+	options := GetProjectOptions(projectID)
+	if options == nil {
+		return nil
+	}
+	return options.ExperimentTags
+}
+
+// Miss The miss behavior of the projectID and the params of the default group of the layer registered for it.
+// The params must not be modified
+func Miss(projectID string, layerKey string) (MissBehavior, map[string]string) {
+	options := GetProjectOptions(projectID)
+	if options == nil || len(options.MissBehavior) == 0 {
+		return MissBehaviorError, nil
+	}
+	return options.MissBehavior, options.MissDefaults[layerKey]
+}
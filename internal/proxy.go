@@ -0,0 +1,73 @@
+// Package internal sdk
+package internal
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// The environment variables of the proxies of each kind of endpoint, they take precedence over the standard
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY, and are overridden by the proxies set programmatically
+const (
+	EnvConfigProxy = "ABC_CONFIG_PROXY"
+	EnvEventProxy  = "ABC_EVENT_PROXY"
+)
+
+// ProxyDirect The proxy value of connecting directly, ignoring the environment variables
+const ProxyDirect = "direct"
+
+// ProxyFunc The proxy of the requests, the same as http.Transport.Proxy
+type ProxyFunc func(req *http.Request) (*url.URL, error)
+
+// ParseProxy Parse the proxy value, an http, https or socks5 url, or ProxyDirect
+func ParseProxy(value string) (ProxyFunc, error) {
+	if value == ProxyDirect {
+		return func(req *http.Request) (*url.URL, error) {
+			return nil, nil
+		}, nil
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy %s", value)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, errors.Errorf("invalid proxy %s, the scheme must be http, https, socks5 or socks5h", value)
+	}
+	if len(proxyURL.Host) == 0 {
+		return nil, errors.Errorf("invalid proxy %s, the host is required", value)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// ConfigProxy The proxy of the control-plane requests, such as fetching the configuration and the dmp, nil if
+// neither GlobalConfig.ConfigProxy nor ABC_CONFIG_PROXY is set, in which case the transport is not changed
+func ConfigProxy(config *GlobalConfig) (ProxyFunc, error) {
+	return endpointProxy(config.ConfigProxy, EnvConfigProxy)
+}
+
+// EventProxy The proxy of the event reporting requests, see ConfigProxy. Falls back to the standard environment
+// variables if neither GlobalConfig.EventProxy nor ABC_EVENT_PROXY is set
+func EventProxy(config *GlobalConfig) (ProxyFunc, error) {
+	proxy, err := endpointProxy(config.EventProxy, EnvEventProxy)
+	if err != nil || proxy != nil {
+		return proxy, err
+	}
+	return http.ProxyFromEnvironment, nil
+}
+
+func endpointProxy(value string, envKey string) (ProxyFunc, error) {
+	if len(value) > 0 {
+		return ParseProxy(value)
+	}
+	value = os.Getenv(envKey)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	proxy, err := ParseProxy(value)
+	return proxy, errors.Wrap(err, envKey)
+}
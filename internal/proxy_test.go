@@ -0,0 +1,52 @@
+// Package internal sdk
+package internal
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointProxy(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://cache.abetterchoice.ai", nil)
+	for _, value := range []string{"ftp://proxy:21", "http://", "://"} {
+		_, err := ParseProxy(value)
+		assert.NotNil(t, err, value)
+	}
+	proxy, err := ParseProxy(ProxyDirect)
+	assert.Nil(t, err)
+	proxyURL, _ := proxy(req)
+	assert.Nil(t, proxyURL)
+
+	t.Setenv(EnvConfigProxy, "")
+	t.Setenv(EnvEventProxy, "")
+	config := &GlobalConfig{}
+	proxy, err = ConfigProxy(config)
+	assert.Nil(t, err)
+	assert.Nil(t, proxy) // the transport is not changed
+	proxy, err = EventProxy(config)
+	assert.Nil(t, err)
+	assert.NotNil(t, proxy) // the standard environment variables
+
+	t.Setenv(EnvConfigProxy, "socks5://config-proxy:1080")
+	t.Setenv(EnvEventProxy, "http://event-proxy:3128")
+	proxy, err = ConfigProxy(config)
+	assert.Nil(t, err)
+	proxyURL, _ = proxy(req)
+	assert.Equal(t, "socks5://config-proxy:1080", proxyURL.String())
+	proxy, err = EventProxy(config)
+	assert.Nil(t, err)
+	proxyURL, _ = proxy(req)
+	assert.Equal(t, "http://event-proxy:3128", proxyURL.String())
+
+	config.EventProxy = "http://override:8080" // the programmatic one takes precedence
+	proxy, err = EventProxy(config)
+	assert.Nil(t, err)
+	proxyURL, _ = proxy(req)
+	assert.Equal(t, "http://override:8080", proxyURL.String())
+
+	t.Setenv(EnvConfigProxy, "invalid")
+	_, err = ConfigProxy(config)
+	assert.NotNil(t, err)
+}
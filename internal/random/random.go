@@ -0,0 +1,51 @@
+// Package random The randomness of the SDK, such as the sampling decisions, which is driven by a seeded PRNG in the
+// deterministic simulation mode, see abc.WithSimulation
+package random
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// seeded Whether the seeded source is used, read without the lock on the hot path
+	seeded int32
+	lock   sync.Mutex
+	// source The seeded source, math/rand.Rand is not concurrent and safe
+	source *rand.Rand
+)
+
+// Seed Drive the randomness by a PRNG seeded by seed, the same sequence of the calls gets the same results
+func Seed(seed int64) {
+	lock.Lock()
+	defer lock.Unlock()
+	source = rand.New(rand.NewSource(seed))
+	atomic.StoreInt32(&seeded, 1)
+}
+
+// Reset Go back to the global source of math/rand
+func Reset() {
+	lock.Lock()
+	defer lock.Unlock()
+	atomic.StoreInt32(&seeded, 0)
+	source = nil
+}
+
+// IsSeeded Whether the randomness is driven by the seeded PRNG
+func IsSeeded() bool {
+	return atomic.LoadInt32(&seeded) == 1
+}
+
+// Int63n A non-negative random number in [0,n), see rand.Int63n
+func Int63n(n int64) int64 {
+	if !IsSeeded() {
+		return rand.Int63n(n)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if source == nil { // reset in between
+		return rand.Int63n(n)
+	}
+	return source.Int63n(n)
+}
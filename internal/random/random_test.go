@@ -0,0 +1,33 @@
+// Package random ...
+package random
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sequence() []int64 {
+	result := make([]int64, 100)
+	for i := range result {
+		result[i] = Int63n(1000)
+	}
+	return result
+}
+
+func TestSeed(t *testing.T) {
+	defer Reset()
+	assert.False(t, IsSeeded())
+	Seed(42)
+	assert.True(t, IsSeeded())
+	first := sequence()
+	Seed(42)
+	assert.Equal(t, first, sequence())
+	Seed(43)
+	assert.NotEqual(t, first, sequence())
+	Reset()
+	assert.False(t, IsSeeded())
+	for _, n := range sequence() {
+		assert.True(t, n >= 0 && n < 1000)
+	}
+}
@@ -0,0 +1,106 @@
+// Package internal sdk
+package internal
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// tokenBucket The rate limiter of a kind of a projectID, the burst is one second of the rate
+type tokenBucket struct {
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// allow Whether n more events are allowed at the rate. The events are allowed as long as a token is left and may
+// overdraw the bucket, so that a batch larger than the burst is not refused forever, the debt delays the next ones
+func (b *tokenBucket) allow(rate float64, n int, now time.Time) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	burst := math.Max(rate, 1)
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// projectLimiter The limits state of a projectID, each projectID has its own, so that a projectID reaching its
+// limits does not take the capacity of the others
+type projectLimiter struct {
+	// the evaluations in progress, atomic
+	inflight   int32
+	evaluation tokenBucket
+	exposure   tokenBucket
+}
+
+// limiterIndex key is projectID, value is *projectLimiter
+var limiterIndex sync.Map
+
+func projectLimiterOf(projectID string) *projectLimiter {
+	if value, ok := limiterIndex.Load(projectID); ok {
+		return value.(*projectLimiter)
+	}
+	value, _ := limiterIndex.LoadOrStore(projectID, &projectLimiter{})
+	return value.(*projectLimiter)
+}
+
+// AcquireEvaluation Check the evaluation of the projectID against ProjectOptions.EvaluationQPS and
+// ProjectOptions.MaxConcurrentEvaluations, an error wrapping ErrRateLimited if exceeded. If acquired, the evaluation
+// takes a concurrency slot and ReleaseEvaluation must be called when it finishes
+func AcquireEvaluation(projectID string) (acquired bool, err error) {
+	options := GetProjectOptions(projectID)
+	if options == nil || (options.EvaluationQPS <= 0 && options.MaxConcurrentEvaluations <= 0) {
+		return false, nil
+	}
+	limiter := projectLimiterOf(projectID)
+	if options.MaxConcurrentEvaluations > 0 {
+		if atomic.AddInt32(&limiter.inflight, 1) > int32(options.MaxConcurrentEvaluations) {
+			atomic.AddInt32(&limiter.inflight, -1)
+			return false, errors.Wrapf(ErrRateLimited, "[projectID=%s]more than %d concurrent evaluations",
+				projectID, options.MaxConcurrentEvaluations)
+		}
+		acquired = true
+	}
+	if options.EvaluationQPS > 0 && !limiter.evaluation.allow(options.EvaluationQPS, 1, time.Now()) {
+		if acquired {
+			atomic.AddInt32(&limiter.inflight, -1)
+		}
+		return false, errors.Wrapf(ErrRateLimited, "[projectID=%s]more than %v evaluations per second",
+			projectID, options.EvaluationQPS)
+	}
+	return acquired, nil
+}
+
+// ReleaseEvaluation Release the concurrency slot taken by AcquireEvaluation
+func ReleaseEvaluation(projectID string) {
+	atomic.AddInt32(&projectLimiterOf(projectID).inflight, -1)
+}
+
+// AllowExposures Whether the count exposures of the projectID are within ProjectOptions.ExposureQPS
+func AllowExposures(projectID string, count int) bool {
+	options := GetProjectOptions(projectID)
+	if options == nil || options.ExposureQPS <= 0 || count <= 0 {
+		return true
+	}
+	return projectLimiterOf(projectID).exposure.allow(options.ExposureQPS, count, time.Now())
+}
+
+// ResetRateLimits Clear the limits state of all projectIDs, called by Release
+func ResetRateLimits() {
+	limiterIndex.Range(func(key, value interface{}) bool {
+		limiterIndex.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,66 @@
+// Package internal sdk
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	bucket := &tokenBucket{}
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		assert.True(t, bucket.allow(2, 1, now))
+	}
+	assert.False(t, bucket.allow(2, 1, now))
+	assert.True(t, bucket.allow(2, 1, now.Add(500*time.Millisecond)))
+	// a batch larger than the burst overdraws the bucket
+	assert.True(t, bucket.allow(2, 5, now.Add(2*time.Second)))
+	assert.False(t, bucket.allow(2, 1, now.Add(3*time.Second)))
+	assert.True(t, bucket.allow(2, 1, now.Add(4*time.Second)))
+}
+
+func TestAcquireEvaluation(t *testing.T) {
+	defer ResetProjectOptions()
+	defer ResetRateLimits()
+	acquired, err := AcquireEvaluation("123")
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	SetProjectOptions("123", &ProjectOptions{MaxConcurrentEvaluations: 2})
+	for i := 0; i < 2; i++ {
+		acquired, err = AcquireEvaluation("123")
+		assert.Nil(t, err)
+		assert.True(t, acquired)
+	}
+	_, err = AcquireEvaluation("123")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	acquired, err = AcquireEvaluation("456") // the other projectIDs are not affected
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	ReleaseEvaluation("123")
+	acquired, err = AcquireEvaluation("123")
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+
+	ResetRateLimits()
+	SetProjectOptions("123", &ProjectOptions{EvaluationQPS: 1, MaxConcurrentEvaluations: 10})
+	acquired, err = AcquireEvaluation("123")
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+	_, err = AcquireEvaluation("123")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, int32(1), projectLimiterOf("123").inflight) // the rejected one releases its slot
+}
+
+func TestAllowExposures(t *testing.T) {
+	defer ResetProjectOptions()
+	defer ResetRateLimits()
+	assert.True(t, AllowExposures("123", 100))
+	SetProjectOptions("123", &ProjectOptions{ExposureQPS: 10})
+	assert.True(t, AllowExposures("123", 10))
+	assert.False(t, AllowExposures("123", 1))
+	assert.True(t, AllowExposures("456", 1))
+}
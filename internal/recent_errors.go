@@ -0,0 +1,65 @@
+// Package internal sdk
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// recentErrorsSize The number of recent errors kept in memory
+const recentErrorsSize = 100
+
+// ErrorRecord An error happened inside the SDK, such as the refresh failure and the reporting failure
+type ErrorRecord struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"` // the background task or the API where the error happened
+	Message string    `json:"message"`
+}
+
+var recentErrors = &errorRing{}
+
+// errorRing Fixed size ring of the recent errors, the oldest one is overwritten when full
+type errorRing struct {
+	lock    sync.Mutex
+	records [recentErrorsSize]ErrorRecord
+	next    int
+	full    bool
+}
+
+// RecordError Keep the error in the recent errors, concurrent and safe
+func RecordError(source string, err error) {
+	if err == nil {
+		return
+	}
+	recentErrors.lock.Lock()
+	defer recentErrors.lock.Unlock()
+	recentErrors.records[recentErrors.next] = ErrorRecord{Time: time.Now(), Source: source, Message: err.Error()}
+	recentErrors.next = (recentErrors.next + 1) % recentErrorsSize
+	if recentErrors.next == 0 {
+		recentErrors.full = true
+	}
+}
+
+// RecentErrors The recent errors, the newest first
+func RecentErrors() []*ErrorRecord {
+	recentErrors.lock.Lock()
+	defer recentErrors.lock.Unlock()
+	size := recentErrors.next
+	if recentErrors.full {
+		size = recentErrorsSize
+	}
+	result := make([]*ErrorRecord, 0, size)
+	for i := 1; i <= size; i++ {
+		record := recentErrors.records[(recentErrors.next-i+recentErrorsSize)%recentErrorsSize]
+		result = append(result, &record)
+	}
+	return result
+}
+
+// ResetRecentErrors Clear the recent errors, called by Release
+func ResetRecentErrors() {
+	recentErrors.lock.Lock()
+	defer recentErrors.lock.Unlock()
+	recentErrors.records = [recentErrorsSize]ErrorRecord{}
+	recentErrors.next, recentErrors.full = 0, false
+}
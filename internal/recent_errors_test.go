@@ -0,0 +1,24 @@
+// Package internal sdk
+package internal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentErrors(t *testing.T) {
+	ResetRecentErrors()
+	defer ResetRecentErrors()
+	RecordError("nil", nil)
+	assert.Equal(t, 0, len(RecentErrors()))
+	for i := 0; i < recentErrorsSize+10; i++ {
+		RecordError("test", fmt.Errorf("err%d", i))
+	}
+	got := RecentErrors()
+	assert.Equal(t, recentErrorsSize, len(got))
+	assert.Equal(t, fmt.Sprintf("err%d", recentErrorsSize+9), got[0].Message)
+	assert.Equal(t, "err10", got[len(got)-1].Message)
+	assert.Equal(t, "test", got[0].Source)
+}
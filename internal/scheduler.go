@@ -0,0 +1,19 @@
+// Package internal sdk
+package internal
+
+// Scheduler Run the background tasks of the SDK, such as the local cache refresher and the exposure consumers,
+// see Go. The default runs each task in its own goroutine, the hosts without threads or timers, such as the envoy
+// wasm filters, register one that drives the tasks from the callbacks of the host or rejects them.
+// Built with the build tag abc_wasm, the default rejects all tasks
+type Scheduler interface {
+	// Schedule Run the task, return an error if the task is not run, in which case it is not listed in Tasks
+	Schedule(name string, run func()) error
+}
+
+// scheduler The scheduler of the background tasks
+func scheduler() Scheduler {
+	if C.Scheduler != nil {
+		return C.Scheduler
+	}
+	return defaultScheduler
+}
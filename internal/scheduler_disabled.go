@@ -0,0 +1,17 @@
+//go:build abc_wasm
+// +build abc_wasm
+
+// Package internal sdk
+package internal
+
+import "github.com/pkg/errors"
+
+// disabledScheduler Reject all tasks, the local cache is loaded once by Init and not refreshed
+type disabledScheduler struct{}
+
+// Schedule Implement Scheduler
+func (disabledScheduler) Schedule(name string, run func()) error {
+	return errors.Errorf("background task [%s] is disabled in the wasm mode", name)
+}
+
+var defaultScheduler Scheduler = disabledScheduler{}
@@ -0,0 +1,16 @@
+//go:build !abc_wasm
+// +build !abc_wasm
+
+// Package internal sdk
+package internal
+
+// goroutineScheduler Run each task in its own goroutine
+type goroutineScheduler struct{}
+
+// Schedule Implement Scheduler
+func (goroutineScheduler) Schedule(name string, run func()) error {
+	go run()
+	return nil
+}
+
+var defaultScheduler Scheduler = goroutineScheduler{}
@@ -0,0 +1,155 @@
+// Package internal sdk
+package internal
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+// BackgroundErrorHandler Callback of background task errors, task is the task name, such as refresh:projectID
+type BackgroundErrorHandler func(task string, err error)
+
+var (
+	// MinRestartBackoff The first restart interval after a background task panics,
+	// doubled after each consecutive panic
+	MinRestartBackoff = time.Second
+	// MaxRestartBackoff The upper limit of the restart interval. If the task has run longer than it before panicking,
+	// the interval is reset to MinRestartBackoff
+	MaxRestartBackoff = time.Minute
+)
+
+// Task A background task owned by the SDK, such as the local cache refresher and the exposure consumer
+type Task struct {
+	id        int64
+	name      string
+	startTime time.Time
+	// unix nano of the last activity, atomic
+	lastActive int64
+	// number of restarts after panic, atomic
+	restarts int64
+}
+
+// TaskInfo The snapshot of a background task, see Tasks
+type TaskInfo struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	StartTime  time.Time `json:"startTime"`
+	LastActive time.Time `json:"lastActive"`
+	Restarts   int64     `json:"restarts"`
+}
+
+var (
+	// taskIndex key is the task id, value is *Task, the task is removed after it exits
+	taskIndex sync.Map
+	taskID    int64
+)
+
+// Heartbeat Record the activity of the task, called by the task in each loop, nil is safe
+func (t *Task) Heartbeat() {
+	if t == nil {
+		return
+	}
+	atomic.StoreInt64(&t.lastActive, time.Now().UnixNano())
+}
+
+// Tasks List all running background tasks, sorted by start order
+func Tasks() []*TaskInfo {
+	var result []*TaskInfo
+	taskIndex.Range(func(key, value interface{}) bool {
+		task, ok := value.(*Task)
+		if !ok {
+			return true
+		}
+		result = append(result, &TaskInfo{
+			ID:         task.id,
+			Name:       task.name,
+			StartTime:  task.startTime,
+			LastActive: time.Unix(0, atomic.LoadInt64(&task.lastActive)),
+			Restarts:   atomic.LoadInt64(&task.restarts),
+		})
+		return true
+	})
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Go Start a supervised background task. If fn panics, the panic is recovered and reported through
+// OnBackgroundError, and fn is restarted after an exponential backoff; the task exits when fn returns normally.
+// The task is listed in Tasks until it exits, fn should call task.Heartbeat in each loop.
+// The task is run by the registered Scheduler, the task rejected by it is not run at all
+func Go(name string, fn func(task *Task)) {
+	now := time.Now()
+	task := &Task{id: atomic.AddInt64(&taskID, 1), name: name, startTime: now, lastActive: now.UnixNano()}
+	taskIndex.Store(task.id, task)
+	err := scheduler().Schedule(name, func() {
+		supervise(task, fn)
+	})
+	if err != nil {
+		taskIndex.Delete(task.id)
+		log.Warnf("[task=%v]not scheduled:%v", name, err)
+	}
+}
+
+func supervise(task *Task, fn func(task *Task)) {
+	defer taskIndex.Delete(task.id)
+	backoff := MinRestartBackoff
+	for {
+		start := time.Now()
+		err := runRecovered(task, fn)
+		if err == nil {
+			return
+		}
+		log.Errorf("[task=%v]panic, restart after %s:%v", task.name, backoff.String(), err)
+		ReportBackgroundError(task.name, err)
+		if time.Since(start) >= MaxRestartBackoff {
+			backoff = MinRestartBackoff
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > MaxRestartBackoff {
+			backoff = MaxRestartBackoff
+		}
+		atomic.AddInt64(&task.restarts, 1)
+		task.Heartbeat()
+	}
+}
+
+// runRecovered Run fn, if fn panics, return the panic information and stack as an error
+func runRecovered(task *Task, fn func(task *Task)) (err error) {
+	defer func() {
+		recoverErr := recover()
+		if recoverErr != nil {
+			body := make([]byte, 1<<10)
+			runtime.Stack(body, false)
+			err = fmt.Errorf("recoverErr:%v\n%s", recoverErr, body)
+		}
+	}()
+	fn(task)
+	return nil
+}
+
+// ReportBackgroundError Keep the error of the background task in the recent errors and
+// pass it to the registered OnBackgroundError,
+// a panic inside the callback is recovered and does not affect the background task
+func ReportBackgroundError(task string, err error) {
+	RecordError(task, err)
+	handler := C.OnBackgroundError
+	if handler == nil || err == nil {
+		return
+	}
+	defer func() {
+		recoverErr := recover()
+		if recoverErr != nil {
+			log.Errorf("[task=%v]onBackgroundError recoverErr:%v", task, recoverErr)
+		}
+	}()
+	handler(task, err)
+}
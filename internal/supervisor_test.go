@@ -0,0 +1,148 @@
+// Package internal sdk
+package internal
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGo(t *testing.T) {
+	defer func(min, max time.Duration, c *GlobalConfig) {
+		MinRestartBackoff, MaxRestartBackoff, C = min, max, c
+	}(MinRestartBackoff, MaxRestartBackoff, C)
+	MinRestartBackoff, MaxRestartBackoff = time.Millisecond, 4*time.Millisecond
+	var (
+		lock  sync.Mutex
+		tasks []string
+		runs  int
+		done  = make(chan struct{})
+	)
+	C = &GlobalConfig{OnBackgroundError: func(task string, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		tasks = append(tasks, task)
+		panic("handler panic is recovered")
+	}}
+	Go("test", func(task *Task) {
+		runs++
+		if runs <= 3 {
+			panic("bad data")
+		}
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task not restarted")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []string{"test", "test", "test"}, tasks)
+	assert.Equal(t, 4, runs)
+}
+
+func TestReportBackgroundError(t *testing.T) {
+	defer func(c *GlobalConfig) { C = c }(C)
+	tests := []struct {
+		name       string
+		hasHandler bool
+		err        error
+		want       int
+	}{
+		{name: "no handler", hasHandler: false, err: errors.New("mock err"), want: 0},
+		{name: "nil err", hasHandler: true, err: nil, want: 0},
+		{name: "normal", hasHandler: true, err: errors.New("mock err"), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got int
+			C = &GlobalConfig{}
+			if tt.hasHandler {
+				C.OnBackgroundError = func(task string, err error) { got++ }
+			}
+			ReportBackgroundError("test", tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTasks(t *testing.T) {
+	var (
+		heartbeat = make(chan struct{})
+		exit      = make(chan struct{})
+		done      = make(chan struct{})
+	)
+	Go("testTasks", func(task *Task) {
+		defer close(done)
+		<-heartbeat
+		task.Heartbeat()
+		heartbeat <- struct{}{}
+		<-exit
+	})
+	find := func() *TaskInfo {
+		for _, info := range Tasks() {
+			if info.Name == "testTasks" {
+				return info
+			}
+		}
+		return nil
+	}
+	info := find()
+	if assert.NotNil(t, info) {
+		assert.Equal(t, int64(0), info.Restarts)
+		assert.Equal(t, info.StartTime.UnixNano(), info.LastActive.UnixNano())
+	}
+	time.Sleep(time.Millisecond)
+	heartbeat <- struct{}{}
+	<-heartbeat
+	if info = find(); assert.NotNil(t, info) {
+		assert.True(t, info.LastActive.After(info.StartTime))
+	}
+	close(exit)
+	<-done
+	assert.Eventually(t, func() bool { return find() == nil }, time.Second, time.Millisecond)
+}
+
+// schedulerFunc Adapt the function to Scheduler
+type schedulerFunc func(name string, run func()) error
+
+func (f schedulerFunc) Schedule(name string, run func()) error {
+	return f(name, run)
+}
+
+func TestScheduler(t *testing.T) {
+	defer func() {
+		C.Scheduler = nil
+	}()
+	var runs []func()
+	C.Scheduler = schedulerFunc(func(name string, run func()) error {
+		if name == "rejected" {
+			return errors.New("rejected")
+		}
+		runs = append(runs, run) // driven by the host
+		return nil
+	})
+	ran := false
+	Go("driven", func(task *Task) {
+		ran = true
+	})
+	Go("rejected", func(task *Task) {
+		t.Error("rejected task should not run")
+	})
+	names := map[string]bool{}
+	for _, info := range Tasks() {
+		names[info.Name] = true
+	}
+	assert.True(t, names["driven"])
+	assert.False(t, names["rejected"])
+	assert.Equal(t, 1, len(runs))
+	runs[0]()
+	assert.True(t, ran)
+	for _, info := range Tasks() {
+		assert.NotEqual(t, "driven", info.Name) // exited
+	}
+}
@@ -0,0 +1,30 @@
+// Package internal sdk
+package internal
+
+import (
+	"github.com/abetterchoice/go-sdk/env"
+)
+
+// WithSystemMetadata Add the metadata of env.SystemMetadata to the extended details of an exposure or a monitor event
+// without overriding the existing keys, extInfo is modified and may be nil
+func WithSystemMetadata(extInfo map[string]string) map[string]string {
+	metadata := env.SystemMetadata()
+	if len(metadata) == 0 {
+		return extInfo
+	}
+	if extInfo == nil {
+		extInfo = make(map[string]string, len(metadata)+1)
+	}
+	for key, value := range metadata {
+		if _, ok := extInfo[key]; !ok {
+			extInfo[key] = value
+		}
+	}
+	return extInfo
+}
+
+// EventExtInfo The extended details of a monitor event, the system metadata and the event ID are added, see
+// WithSystemMetadata and WithEventID, extInfo is modified and may be nil
+func EventExtInfo(extInfo map[string]string) map[string]string {
+	return WithEventID(WithSystemMetadata(extInfo))
+}
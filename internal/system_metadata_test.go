@@ -0,0 +1,23 @@
+// Package internal sdk
+package internal
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSystemMetadata(t *testing.T) {
+	defer env.RegisterSystemMetadataProvider(nil)
+	assert.Nil(t, WithSystemMetadata(nil))
+	assert.Nil(t, EventExtInfo(nil))
+
+	env.RegisterSystemMetadataProvider(env.SystemMetadataProviderFunc(func() map[string]string {
+		return map[string]string{env.MetadataKeyPodName: "pod-1", "tenant": "system"}
+	}))
+	assert.Equal(t, map[string]string{env.MetadataKeyPodName: "pod-1", "tenant": "system"}, EventExtInfo(nil))
+	extInfo := WithSystemMetadata(map[string]string{"tenant": "t1"})
+	assert.Equal(t, map[string]string{env.MetadataKeyPodName: "pod-1", "tenant": "t1"}, extInfo) // not overridden
+	assert.Equal(t, "system", env.SystemMetadata()["tenant"])                                    // not modified
+}
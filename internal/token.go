@@ -0,0 +1,147 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// Token A credential acquired from a TokenSource, such as an OAuth2 access token
+type Token struct {
+	// The secretKey of the control plane or the token of the reporting, see TokenSource
+	Value string
+	// The time after which the token is no longer used, zero never expires
+	Expiry time.Time
+}
+
+// TokenSource Acquire the expiring credentials of the projectID instead of a static one, such as through the OAuth2
+// client credentials flow. The tokens are cached until they expire and refreshed ahead, the source is called
+// concurrently for different projectIDs only
+type TokenSource interface {
+	Token(ctx context.Context, projectID string) (*Token, error)
+}
+
+// TokenKind The use of the token
+type TokenKind string
+
+const (
+	// TokenKindConfig The secretKey of the control-plane requests, such as fetching the configuration and the dmp
+	TokenKindConfig TokenKind = "config"
+	// TokenKindEvent The token carried in the reporting metadata
+	TokenKindEvent TokenKind = "event"
+)
+
+const (
+	// defaultTokenRefreshAhead The token is refreshed in the background this long before it expires
+	defaultTokenRefreshAhead = time.Minute
+	// tokenTimeout The timeout of a single acquisition
+	tokenTimeout = 10 * time.Second
+)
+
+// tokenEntry The cached token of a kind and a projectID
+type tokenEntry struct {
+	token *Token
+	// whether a refresh ahead is in flight, atomic
+	refreshing int32
+}
+
+var (
+	// tokenIndex key is kind:projectID, value is *tokenEntry, replaced on each refresh
+	tokenIndex sync.Map
+	tokenGroup singleflight.Group
+)
+
+// tokenSource The source of the kind, nil if not set
+func tokenSource(kind TokenKind) TokenSource {
+	if kind == TokenKindConfig {
+		return C.ConfigTokenSource
+	}
+	return C.EventTokenSource
+}
+
+func tokenRefreshAhead() time.Duration {
+	if C.TokenRefreshAhead > 0 {
+		return C.TokenRefreshAhead
+	}
+	return defaultTokenRefreshAhead
+}
+
+// SourceToken Get the token of the kind and the projectID from the cache or the TokenSource, false if no source is
+// set. The expired token is acquired again synchronously, the token about to expire is returned while it is
+// refreshed in the background. The failures are reported to OnError with ReasonTokenFailure
+func SourceToken(ctx context.Context, kind TokenKind, projectID string) (string, bool, error) {
+	if tokenSource(kind) == nil {
+		return "", false, nil
+	}
+	key := string(kind) + ":" + projectID
+	now := time.Now()
+	if value, ok := tokenIndex.Load(key); ok {
+		entry := value.(*tokenEntry)
+		expiry := entry.token.Expiry
+		if expiry.IsZero() || now.Before(expiry) {
+			if !expiry.IsZero() && expiry.Sub(now) <= tokenRefreshAhead() &&
+				atomic.CompareAndSwapInt32(&entry.refreshing, 0, 1) {
+				go func() {
+					// refreshed once per token, the refreshed token replaces the entry, retried on failure
+					if _, err := refreshToken(context.Background(), kind, projectID); err != nil {
+						atomic.StoreInt32(&entry.refreshing, 0)
+					}
+				}()
+			}
+			return entry.token.Value, true, nil
+		}
+	}
+	token, err := refreshToken(ctx, kind, projectID)
+	if err != nil {
+		return "", true, err
+	}
+	return token.Value, true, nil
+}
+
+// refreshToken Acquire the token from the source and cache it, the concurrent refreshes of the same key share one
+// acquisition, the callers wait on it until their ctx is done
+func refreshToken(ctx context.Context, kind TokenKind, projectID string) (*Token, error) {
+	key := string(kind) + ":" + projectID
+	result := tokenGroup.DoChan(key, func() (interface{}, error) {
+		source := tokenSource(kind)
+		if source == nil {
+			return nil, errors.Errorf("token source of %s is not set", kind)
+		}
+		acquireCtx, cancel := context.WithTimeout(context.Background(), tokenTimeout)
+		defer cancel()
+		token, err := source.Token(acquireCtx, projectID)
+		if err == nil && (token == nil || len(token.Value) == 0) {
+			err = errors.Errorf("empty token")
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "acquire %s token", kind)
+			ReportError(ReasonTokenFailure, projectID, err)
+			return nil, err
+		}
+		copied := *token // the source may reuse the instance
+		tokenIndex.Store(key, &tokenEntry{token: &copied})
+		return &copied, nil
+	})
+	select {
+	case r := <-result:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(*Token), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ResetTokens Clear all cached tokens, called by Release
+func ResetTokens() {
+	tokenIndex.Range(func(key, value interface{}) bool {
+		tokenIndex.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,87 @@
+// Package internal sdk
+package internal
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenSourceFunc func(ctx context.Context, projectID string) (*Token, error)
+
+func (f tokenSourceFunc) Token(ctx context.Context, projectID string) (*Token, error) {
+	return f(ctx, projectID)
+}
+
+func TestSourceToken(t *testing.T) {
+	defer func(c *GlobalConfig) {
+		C = c
+		ResetTokens()
+		ResetCredentials()
+	}(C)
+	var (
+		calls   int32
+		expiry  = time.Now().Add(time.Hour).UnixNano() // atomic, read by the refresh ahead
+		failing int32
+		reasons []ErrorReason
+	)
+	C = &GlobalConfig{SecretKey: "static", TokenRefreshAhead: time.Minute,
+		OnError: func(reason ErrorReason, projectID string, err error) {
+			reasons = append(reasons, reason)
+		},
+		ConfigTokenSource: tokenSourceFunc(func(ctx context.Context, projectID string) (*Token, error) {
+			n := atomic.AddInt32(&calls, 1)
+			if atomic.LoadInt32(&failing) == 1 {
+				return nil, errors.New("unavailable")
+			}
+			return &Token{Value: projectID + "-" + string(rune('0'+n)),
+				Expiry: time.Unix(0, atomic.LoadInt64(&expiry))}, nil
+		})}
+	ctx := context.Background()
+	secretKey, err := ConfigSecretKey(ctx, "123")
+	assert.Nil(t, err)
+	assert.Equal(t, "123-1", secretKey)
+	secretKey, _ = ConfigSecretKey(ctx, "123")
+	assert.Equal(t, "123-1", secretKey) // cached
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// about to expire, the current token is used while refreshed ahead
+	atomic.StoreInt64(&expiry, time.Now().Add(30*time.Second).UnixNano())
+	ResetTokens()
+	secretKey, _ = ConfigSecretKey(ctx, "123")
+	assert.Equal(t, "123-2", secretKey)
+	atomic.StoreInt64(&expiry, time.Now().Add(time.Hour).UnixNano()) // the refreshed token is not refreshed again
+	secretKey, _ = ConfigSecretKey(ctx, "123")
+	assert.Equal(t, "123-2", secretKey)
+	assert.Eventually(t, func() bool {
+		secretKey, _ = ConfigSecretKey(ctx, "123")
+		return secretKey == "123-3"
+	}, time.Second, time.Millisecond)
+
+	// expired and the source fails, fail and report
+	atomic.StoreInt64(&expiry, time.Now().Add(-time.Second).UnixNano())
+	atomic.StoreInt32(&failing, 1)
+	ResetTokens()
+	_, err = ConfigSecretKey(ctx, "123")
+	assert.NotNil(t, err)
+	assert.Contains(t, reasons, ReasonTokenFailure)
+
+	// the rotated secretKey takes precedence, the event token falls back without source
+	SetCredentials("123", &Credentials{SecretKey: "rotated"})
+	secretKey, err = ConfigSecretKey(ctx, "123")
+	assert.Nil(t, err)
+	assert.Equal(t, "rotated", secretKey)
+	assert.Equal(t, "remote", EventToken("123", "remote"))
+	C.ConfigTokenSource = nil
+	secretKey, _ = ConfigSecretKey(ctx, "456")
+	assert.Equal(t, "static", secretKey)
+
+	C.EventTokenSource = tokenSourceFunc(func(ctx context.Context, projectID string) (*Token, error) {
+		return &Token{Value: "event"}, nil
+	})
+	assert.Equal(t, "event", EventToken("456", "remote"))
+}
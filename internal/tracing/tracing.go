@@ -0,0 +1,94 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package tracing OpenTelemetry tracing of the sdk, disabled unless a TracerProvider is supplied at Init
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName The name of the tracer, which identifies the spans created by the sdk
+const instrumentationName = "github.com/abetterchoice/go-sdk"
+
+// Attribute keys of the spans
+const (
+	KeyProjectID     = attribute.Key("abc.project_id")
+	KeyLayerKey      = attribute.Key("abc.layer_key")
+	KeyGroupID       = attribute.Key("abc.group_id")
+	KeyConfigKey     = attribute.Key("abc.config_key")
+	KeyConfigLoaded  = attribute.Key("abc.config_loaded")
+	KeyVersion       = attribute.Key("abc.version")
+	KeyExposureCount = attribute.Key("abc.exposure_count")
+)
+
+// TracerProvider The provider of the tracer of the sdk
+type TracerProvider = trace.TracerProvider
+
+// Span The span created by Start
+type Span = trace.Span
+
+// tracerHolder atomic.Value requires a consistent concrete type
+type tracerHolder struct {
+	tracer trace.Tracer
+}
+
+var (
+	tracerValue atomic.Value
+	// noopSpan Returned when tracing is disabled, End and other methods do nothing
+	noopSpan = trace.SpanFromContext(context.Background())
+)
+
+// SetTracerProvider Enable tracing through the TracerProvider, nil disables tracing
+func SetTracerProvider(provider TracerProvider) {
+	if provider == nil {
+		tracerValue.Store(tracerHolder{})
+		return
+	}
+	tracerValue.Store(tracerHolder{tracer: provider.Tracer(instrumentationName,
+		trace.WithInstrumentationVersion(env.Version))})
+}
+
+// Enabled Whether tracing is enabled, used to skip building attributes on the hot path
+func Enabled() bool {
+	holder, ok := tracerValue.Load().(tracerHolder)
+	return ok && holder.tracer != nil
+}
+
+// Start Create a span as the child of the span in ctx. If tracing is disabled, ctx is returned as is
+// together with a span that does nothing, without allocation
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, Span) {
+	holder, ok := tracerValue.Load().(tracerHolder)
+	if !ok || holder.tracer == nil {
+		return ctx, noopSpan
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return holder.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartWithKey Create a span with the projectID and key attributes, the attributes are only built if enabled,
+// so it can be used on the evaluation hot path
+func StartWithKey(ctx context.Context, name string, projectID string, key attribute.Key,
+	value string) (context.Context, Span) {
+	if !Enabled() {
+		return ctx, noopSpan
+	}
+	return Start(ctx, name, KeyProjectID.String(projectID), key.String(value))
+}
+
+// End Record the error if not nil and end the span
+func End(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,81 @@
+//go:build abc_lite || abc_wasm
+// +build abc_lite abc_wasm
+
+// Package tracing OpenTelemetry tracing of the sdk, compiled out in the lite build mode together with OpenTelemetry,
+// the spans do nothing
+package tracing
+
+import "context"
+
+// Attribute keys of the spans
+const (
+	KeyProjectID     = Key("abc.project_id")
+	KeyLayerKey      = Key("abc.layer_key")
+	KeyGroupID       = Key("abc.group_id")
+	KeyConfigKey     = Key("abc.config_key")
+	KeyConfigLoaded  = Key("abc.config_loaded")
+	KeyVersion       = Key("abc.version")
+	KeyExposureCount = Key("abc.exposure_count")
+)
+
+// Key The attribute key of the spans
+type Key string
+
+// KeyValue The attribute of the spans, discarded
+type KeyValue struct{}
+
+// String The string attribute of the key
+func (k Key) String(value string) KeyValue {
+	return KeyValue{}
+}
+
+// Int The int attribute of the key
+func (k Key) Int(value int) KeyValue {
+	return KeyValue{}
+}
+
+// Int64 The int64 attribute of the key
+func (k Key) Int64(value int64) KeyValue {
+	return KeyValue{}
+}
+
+// Bool The bool attribute of the key
+func (k Key) Bool(value bool) KeyValue {
+	return KeyValue{}
+}
+
+// TracerProvider Never set in the lite build mode
+type TracerProvider interface{}
+
+// Span The span created by Start, does nothing
+type Span struct{}
+
+// SetAttributes Do nothing
+func (Span) SetAttributes(attrs ...KeyValue) {}
+
+// IsRecording Always false
+func (Span) IsRecording() bool {
+	return false
+}
+
+// SetTracerProvider Do nothing
+func SetTracerProvider(provider TracerProvider) {}
+
+// Enabled Always false
+func Enabled() bool {
+	return false
+}
+
+// Start Return ctx as is together with a span that does nothing
+func Start(ctx context.Context, name string, attrs ...KeyValue) (context.Context, Span) {
+	return ctx, Span{}
+}
+
+// StartWithKey Return ctx as is together with a span that does nothing
+func StartWithKey(ctx context.Context, name string, projectID string, key Key,
+	value string) (context.Context, Span) {
+	return ctx, Span{}
+}
+
+// End Do nothing
+func End(span Span, err error) {}
@@ -0,0 +1,38 @@
+// Package internal sdk
+package internal
+
+import "strconv"
+
+// UnitIDTypeInfo A custom unitID type registered by the caller, see GlobalConfig.UnitIDTypes
+type UnitIDTypeInfo struct {
+	// The value of the unitID type configured on the platform
+	Type int32 `json:"type"`
+	// The symbolic name reported in the exposures instead of the decimal value
+	Name string `json:"name"`
+	// Prepended to the decisionID hashed by the layers of the type, empty means no salt
+	Salt string `json:"salt,omitempty"`
+}
+
+// LookupUnitIDType The registered custom unitID type, false if not registered
+func LookupUnitIDType(unitIDType int32) (*UnitIDTypeInfo, bool) {
+	info, ok := C.UnitIDTypes[unitIDType]
+	return info, ok
+}
+
+// UnitIDTypeString The format of the unitID type in the exposures, the name of the registered custom types,
+// the decimal value otherwise
+func UnitIDTypeString(unitIDType int32) string {
+	if info, ok := C.UnitIDTypes[unitIDType]; ok {
+		return info.Name
+	}
+	return strconv.FormatInt(int64(unitIDType), 10)
+}
+
+// SaltedHashSource The source hashed by the layers of the unitID type, the decisionID prepended by the salt
+// of the registered custom type
+func SaltedHashSource(unitIDType int32, decisionID string) string {
+	if info, ok := C.UnitIDTypes[unitIDType]; ok && len(info.Salt) > 0 {
+		return info.Salt + decisionID
+	}
+	return decisionID
+}
@@ -0,0 +1,83 @@
+// Package internal sdk
+package internal
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxUsageKeys The maximum number of the distinct keys tracked of each projectID, the keys read beyond it are not
+// tracked, which bounds the memory when the callers read the keys built from the user input
+const maxUsageKeys = 10000
+
+// KeyUsage The reads of a remote config or feature flag key
+type KeyUsage struct {
+	Key string `json:"key"`
+	// The number of the reads since Init
+	Count uint64 `json:"count"`
+	// The time of the last read
+	LastRead time.Time `json:"lastRead"`
+}
+
+type usageCounter struct {
+	count    uint64
+	lastRead int64 // unix nano
+}
+
+type projectUsage struct {
+	size int64
+	keys sync.Map // key is the config key, value is *usageCounter
+}
+
+// usageIndex key is projectID, value is *projectUsage
+var usageIndex sync.Map
+
+// RecordUsage Count a read of the key of the projectID, concurrent and safe
+func RecordUsage(projectID string, key string, now time.Time) {
+	value, ok := usageIndex.Load(projectID)
+	if !ok {
+		value, _ = usageIndex.LoadOrStore(projectID, &projectUsage{})
+	}
+	usage := value.(*projectUsage)
+	counter, ok := usage.keys.Load(key)
+	if !ok {
+		if atomic.LoadInt64(&usage.size) >= maxUsageKeys {
+			return
+		}
+		var loaded bool
+		counter, loaded = usage.keys.LoadOrStore(key, &usageCounter{})
+		if !loaded {
+			atomic.AddInt64(&usage.size, 1)
+		}
+	}
+	atomic.AddUint64(&counter.(*usageCounter).count, 1)
+	atomic.StoreInt64(&counter.(*usageCounter).lastRead, now.UnixNano())
+}
+
+// Usage The reads of the keys of the projectID since Init, key is the config key
+func Usage(projectID string) map[string]*KeyUsage {
+	result := make(map[string]*KeyUsage)
+	value, ok := usageIndex.Load(projectID)
+	if !ok {
+		return result
+	}
+	value.(*projectUsage).keys.Range(func(key, value interface{}) bool {
+		counter := value.(*usageCounter)
+		result[key.(string)] = &KeyUsage{
+			Key:      key.(string),
+			Count:    atomic.LoadUint64(&counter.count),
+			LastRead: time.Unix(0, atomic.LoadInt64(&counter.lastRead)),
+		}
+		return true
+	})
+	return result
+}
+
+// ResetUsage Clear all usage counters, called by Release
+func ResetUsage() {
+	usageIndex.Range(func(key, value interface{}) bool {
+		usageIndex.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,35 @@
+// Package internal sdk
+package internal
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordUsage(t *testing.T) {
+	ResetUsage()
+	defer ResetUsage()
+	assert.Equal(t, map[string]*KeyUsage{}, Usage("123"))
+	now := time.Unix(100, 0)
+	RecordUsage("123", "flag1", now)
+	RecordUsage("123", "flag1", now.Add(time.Second))
+	RecordUsage("123", "flag2", now)
+	RecordUsage("456", "flag1", now)
+	assert.Equal(t, map[string]*KeyUsage{
+		"flag1": {Key: "flag1", Count: 2, LastRead: now.Add(time.Second)},
+		"flag2": {Key: "flag2", Count: 1, LastRead: now},
+	}, Usage("123"))
+	assert.Equal(t, 1, len(Usage("456")))
+
+	for i := 0; i < maxUsageKeys; i++ {
+		RecordUsage("789", strconv.Itoa(i), now)
+	}
+	RecordUsage("789", "beyond", now)
+	RecordUsage("789", "0", now) // the tracked keys are still counted
+	usage := Usage("789")
+	assert.Equal(t, maxUsageKeys, len(usage))
+	assert.Equal(t, uint64(2), usage["0"].Count)
+}
@@ -0,0 +1,23 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/internal/cache"
+)
+
+// The built-in operators of the BCP-47 locale targeting of the string tags, configured on the platform by their
+// values. The unit passes its locale as is, such as WithTagKV("locale", "zh_TW") or the Accept-Language of the
+// request, without normalizing it, see cache.OperatorLocale
+const (
+	// OperatorLocale The locale falls back to one of the config locales, such as "zh-Hant;pt-BR", the fallback chain
+	// drops the region then the script, with the script inferred for the languages written in several scripts
+	OperatorLocale = cache.OperatorLocale
+	// OperatorLanguage The language of the locale is one of the config languages, such as "en;pt"
+	OperatorLanguage = cache.OperatorLanguage
+	// OperatorRegion The region of the locale is one of the config regions, such as "US;GB;419"
+	OperatorRegion = cache.OperatorRegion
+	// OperatorScript The script of the locale, explicit or inferred, is one of the config scripts, such as "Hant"
+	OperatorScript = cache.OperatorScript
+)
@@ -0,0 +1,81 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	mp "github.com/abetterchoice/go-sdk/plugin/metrics"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// WithRegisterMetricsPlugin register monitoring and reporting plug-in
+func WithRegisterMetricsPlugin(client mp.Client, initConfig *protoccacheserver.MetricsInitConfig) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if client == nil {
+			return fmt.Errorf("client should not be nil")
+		}
+		mp.RegisterClient(client)
+		// it is used for subsequent initialization.
+		// some initialization may rely on cached data,
+		// so the monitoring and reporting component is initialized after the cache is successfully pulled.
+		config.MetricsPluginInitConfig[client.Name()] = initConfig
+		return nil
+	}
+}
+
+// initCustomMetricsPlugin TODO
+// initialize the registered monitoring and reporting plug-ins one by one
+func initCustomMetricsPlugin(ctx context.Context, config *internal.GlobalConfig) error {
+	// traverse all registered monitoring and reporting plug-ins
+	return mp.WalkFunc(func(name string, client mp.Client) error {
+		initConfig, ok := config.MetricsPluginInitConfig[name]
+		if !ok {
+			return nil
+		}
+		// If the user explicitly passes in the initialization parameters,
+		// the user-defined passed parameters will be used directly.
+		err := client.Init(ctx, initConfig)
+		if err != nil {
+			return errors.Wrapf(err, "init metrics plugin [%v]", name)
+		}
+		return nil
+	})
+}
+
+// initMetricsPlugin TODO
+// Initialize monitoring plugins provided by remote configuration.
+func initMetricsPlugin(ctx context.Context, config *internal.GlobalConfig) error {
+	return mp.WalkFunc(func(name string, client mp.Client) error {
+		// traverse all projectIDs and initialize related metrics plugin
+		// different projectIDs may have the same metrics plugin, and the same plugin may be initialized multiple times.
+		// it is necessary to ensure that the monitoring and reporting initConfig of projectIDList is consistent.
+		// if inconsistent, the initConfig will be randomly initialized.
+		for _, projectID := range internal.C.ProjectIDList {
+			application := cache.GetApplication(projectID)
+			if application == nil {
+				continue
+			}
+			for pluginName, initConfig := range application.MetricsPluginInitConfigIndex {
+				if name != pluginName {
+					continue
+				}
+				_, ok := config.MetricsPluginInitConfig[pluginName]
+				if ok { // The custom plug-in has been initialized and does not need to be initialized again.
+					continue
+				}
+				// Initialize the client according to the initialization parameters of the remote configuration
+				return client.Init(ctx, initConfig)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,12 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval,
+// user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	_ "github.com/abetterchoice/metrics-pubsub" // metrics-pubsub TODO
+)
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GRPCOption NewGRPC related options
+type GRPCOption func(o *grpcOptions)
+
+type grpcOptions struct {
+	// The metadata keys carrying the unitID, the first non-empty one is taken
+	unitIDKeys []string
+	unitIDFunc func(ctx context.Context, fullMethod string) string
+	// key is the metadata key, value is the tag key
+	tagKeys      map[string]string
+	attributions func(ctx context.Context, fullMethod string) []abc.Attribution
+	// Log the exposures at the evaluation rather than at the completion of the RPC
+	isLogImmediately bool
+}
+
+// WithUnitIDMetadata Take the unitID from the first non-empty incoming metadata of the keys
+func WithUnitIDMetadata(keys ...string) GRPCOption {
+	return func(o *grpcOptions) {
+		for _, key := range keys {
+			o.unitIDKeys = append(o.unitIDKeys, strings.ToLower(key)) // the metadata keys are lowercase
+		}
+	}
+}
+
+// WithGRPCUnitIDFunc Take the unitID from the function, such as from the auth info, used when none of the metadata is
+// set
+func WithGRPCUnitIDFunc(fn func(ctx context.Context, fullMethod string) string) GRPCOption {
+	return func(o *grpcOptions) {
+		o.unitIDFunc = fn
+	}
+}
+
+// WithTagMetadata Set the tag of the user context from the incoming metadata, the values of the metadata separated by
+// commas are the values of the tag
+func WithTagMetadata(key string, tagKey string) GRPCOption {
+	return func(o *grpcOptions) {
+		if o.tagKeys == nil {
+			o.tagKeys = map[string]string{}
+		}
+		o.tagKeys[strings.ToLower(key)] = tagKey
+	}
+}
+
+// WithGRPCAttributions Add the attributions of the RPC to the user context, after the default ones of the client
+func WithGRPCAttributions(fn func(ctx context.Context, fullMethod string) []abc.Attribution) GRPCOption {
+	return func(o *grpcOptions) {
+		o.attributions = fn
+	}
+}
+
+// WithLogImmediately Log the exposures once evaluated through the scope instead of at the completion of the RPC,
+// such as for the long-lived streams
+func WithLogImmediately(isLogImmediately bool) GRPCOption {
+	return func(o *grpcOptions) {
+		o.isLogImmediately = isLogImmediately
+	}
+}
+
+// GRPC The gRPC server interceptors, mirroring HTTP
+type GRPC struct {
+	client  *abc.Client
+	options *grpcOptions
+}
+
+// NewGRPC Create the gRPC server interceptors of the client. Concurrent and safe
+func NewGRPC(client *abc.Client, opts ...GRPCOption) *GRPC {
+	o := &grpcOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &GRPC{client: client, options: o}
+}
+
+// UnaryServerInterceptor The scope of each RPC is in the context through ScopeFromContext, and its exposures are
+// logged once the handler returns
+func (m *GRPC) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, scope := m.begin(ctx, info.FullMethod)
+		defer m.end(ctx, scope)
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor The scope of each stream is in the context of the stream through ScopeFromContext, and its
+// exposures are logged once the handler returns
+func (m *GRPC) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx, scope := m.begin(ss.Context(), info.FullMethod)
+		defer m.end(ctx, scope)
+		return handler(srv, &scopedServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (m *GRPC) begin(ctx context.Context, fullMethod string) (context.Context, *Scope) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	scope := NewScope(m.client, m.unitID(ctx, md, fullMethod), m.attributions(ctx, md, fullMethod)...)
+	if m.options.isLogImmediately {
+		_ = scope.Flush(ctx) // nothing pending yet, the evaluations afterwards are logged at once
+	}
+	return NewContext(ctx, scope), scope
+}
+
+func (m *GRPC) end(ctx context.Context, scope *Scope) {
+	if err := scope.Flush(ctx); err != nil {
+		log.LimitedErrorf("middlewareFlush"+m.client.ProjectID(), "flush exposures of [%s] fail:%v",
+			m.client.ProjectID(), err)
+	}
+}
+
+func (m *GRPC) unitID(ctx context.Context, md metadata.MD, fullMethod string) string {
+	for _, key := range m.options.unitIDKeys {
+		for _, unitID := range md.Get(key) {
+			if len(unitID) > 0 {
+				return unitID
+			}
+		}
+	}
+	if m.options.unitIDFunc != nil {
+		return m.options.unitIDFunc(ctx, fullMethod)
+	}
+	return ""
+}
+
+func (m *GRPC) attributions(ctx context.Context, md metadata.MD, fullMethod string) []abc.Attribution {
+	var result []abc.Attribution
+	if len(m.options.tagKeys) > 0 {
+		tags := make(map[string][]string, len(m.options.tagKeys))
+		for key, tagKey := range m.options.tagKeys {
+			appendTagValues(tags, tagKey, md.Get(key))
+		}
+		if len(tags) > 0 {
+			result = append(result, abc.WithTags(tags))
+		}
+	}
+	if m.options.attributions != nil {
+		result = append(result, m.options.attributions(ctx, fullMethod)...)
+	}
+	return result
+}
+
+// scopedServerStream The server stream carrying the context of the scope
+type scopedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context The context of the stream carrying the scope
+func (s *scopedServerStream) Context() context.Context {
+	return s.ctx
+}
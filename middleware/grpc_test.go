@@ -0,0 +1,86 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGRPC(t *testing.T) {
+	defer abc.Release()
+	recorder := initRecorder(t)
+	client, err := abc.NewClient("123")
+	assert.Nil(t, err)
+	m := NewGRPC(client, WithUnitIDMetadata("X-Unit-ID"), WithTagMetadata("X-Country", "country"),
+		WithGRPCAttributions(func(ctx context.Context, fullMethod string) []abc.Attribution {
+			return []abc.Attribution{abc.WithTagKV("method", fullMethod)}
+		}))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-unit-id", "12345",
+		"x-country", "us,sg"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/abc.Test/Unary"}
+	var loggedBeforeCompletion int
+	_, err = m.UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{},
+		error) {
+		scope, ok := ScopeFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "12345", scope.UnitID())
+		for i := 0; i < 2; i++ {
+			_, err := scope.GetExperiment(ctx, "overrideLayer")
+			assert.Nil(t, err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		loggedBeforeCompletion = len(recorder.Exposures().Exposures)
+		return nil, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, loggedBeforeCompletion)
+	assert.Eventually(t, func() bool {
+		return len(recorder.Exposures().Exposures) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// stream, logged immediately
+	recorder.Reset()
+	m = NewGRPC(client, WithUnitIDMetadata("x-unit-id"), WithLogImmediately(true))
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/abc.Test/Stream"}
+	err = m.StreamServerInterceptor()(nil, &mockServerStream{ctx: ctx}, streamInfo,
+		func(srv interface{}, stream grpc.ServerStream) error {
+			scope, ok := ScopeFromContext(stream.Context())
+			assert.True(t, ok)
+			_, err := scope.GetExperiment(stream.Context(), "overrideLayer")
+			assert.Nil(t, err)
+			assert.Eventually(t, func() bool {
+				return len(recorder.Exposures().Exposures) == 1
+			}, 5*time.Second, 10*time.Millisecond)
+			return nil
+		})
+	assert.Nil(t, err)
+}
+
+func TestGRPCUnitID(t *testing.T) {
+	client, err := abc.NewClient("123")
+	assert.Nil(t, err)
+	m := NewGRPC(client, WithUnitIDMetadata("x-unit-id"),
+		WithGRPCUnitIDFunc(func(ctx context.Context, fullMethod string) string {
+			return fullMethod
+		}))
+	assert.Equal(t, "1", m.unitID(context.Background(), metadata.Pairs("x-unit-id", "1"), "/a"))
+	assert.Equal(t, "/a", m.unitID(context.Background(), metadata.Pairs("x-unit-id", ""), "/a"))
+	assert.Equal(t, "/a", m.unitID(context.Background(), nil, "/a"))
+}
@@ -0,0 +1,148 @@
+// Package middleware The middleware injecting the SDK into the request context
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+// Option NewHTTP related options
+type Option func(o *options)
+
+type options struct {
+	// The headers carrying the unitID, the first non-empty one is taken
+	unitIDHeaders []string
+	unitIDCookie  string
+	unitIDFunc    func(r *http.Request) string
+	// key is the header, value is the tag key
+	tagHeaders   map[string]string
+	attributions func(r *http.Request) []abc.Attribution
+}
+
+// WithUnitIDHeader Take the unitID from the first non-empty header of the names
+func WithUnitIDHeader(names ...string) Option {
+	return func(o *options) {
+		o.unitIDHeaders = append(o.unitIDHeaders, names...)
+	}
+}
+
+// WithUnitIDCookie Take the unitID from the cookie, used when none of the headers is set
+func WithUnitIDCookie(name string) Option {
+	return func(o *options) {
+		o.unitIDCookie = name
+	}
+}
+
+// WithUnitIDFunc Take the unitID from the function, such as from the session, used when neither the headers nor the
+// cookie is set
+func WithUnitIDFunc(fn func(r *http.Request) string) Option {
+	return func(o *options) {
+		o.unitIDFunc = fn
+	}
+}
+
+// WithTagHeader Set the tag of the user context from the header, the values of the header separated by commas are
+// the values of the tag, such as X-Country to country
+func WithTagHeader(header string, tagKey string) Option {
+	return func(o *options) {
+		if o.tagHeaders == nil {
+			o.tagHeaders = map[string]string{}
+		}
+		o.tagHeaders[header] = tagKey
+	}
+}
+
+// WithAttributions Add the attributions of the request to the user context, after the default ones of the client
+func WithAttributions(fn func(r *http.Request) []abc.Attribution) Option {
+	return func(o *options) {
+		o.attributions = fn
+	}
+}
+
+// HTTP The net/http middleware, see NewHTTP
+type HTTP struct {
+	client  *abc.Client
+	options *options
+}
+
+// NewHTTP Create the net/http middleware of the client. Concurrent and safe
+func NewHTTP(client *abc.Client, opts ...Option) *HTTP {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &HTTP{client: client, options: o}
+}
+
+// Handler Wrap the handler, the scope of each request is in the request context through ScopeFromContext, and its
+// exposures are logged once the handler returns
+func (m *HTTP) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, scope := m.Begin(r)
+		defer m.End(r, scope)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Begin Create the scope of the request and return the request carrying it, the adapters of the other frameworks
+// call Begin before the handler and End after it
+func (m *HTTP) Begin(r *http.Request) (*http.Request, *Scope) {
+	scope := NewScope(m.client, m.unitID(r), m.attributions(r)...)
+	return r.WithContext(NewContext(r.Context(), scope)), scope
+}
+
+// End Log the exposures of the scope, the errors are logged rather than returned since the response is written
+func (m *HTTP) End(r *http.Request, scope *Scope) {
+	if err := scope.Flush(r.Context()); err != nil {
+		log.LimitedErrorf("middlewareFlush"+m.client.ProjectID(), "flush exposures of [%s] fail:%v",
+			m.client.ProjectID(), err)
+	}
+}
+
+func (m *HTTP) unitID(r *http.Request) string {
+	for _, name := range m.options.unitIDHeaders {
+		if unitID := r.Header.Get(name); len(unitID) > 0 {
+			return unitID
+		}
+	}
+	if len(m.options.unitIDCookie) > 0 {
+		if cookie, err := r.Cookie(m.options.unitIDCookie); err == nil && len(cookie.Value) > 0 {
+			return cookie.Value
+		}
+	}
+	if m.options.unitIDFunc != nil {
+		return m.options.unitIDFunc(r)
+	}
+	return ""
+}
+
+func (m *HTTP) attributions(r *http.Request) []abc.Attribution {
+	var result []abc.Attribution
+	if len(m.options.tagHeaders) > 0 {
+		tags := make(map[string][]string, len(m.options.tagHeaders))
+		for header, tagKey := range m.options.tagHeaders {
+			appendTagValues(tags, tagKey, r.Header.Values(header))
+		}
+		if len(tags) > 0 {
+			result = append(result, abc.WithTags(tags))
+		}
+	}
+	if m.options.attributions != nil {
+		result = append(result, m.options.attributions(r)...)
+	}
+	return result
+}
+
+// appendTagValues Append the values separated by commas to the tag
+func appendTagValues(tags map[string][]string, tagKey string, values []string) {
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); len(v) > 0 {
+				tags[tagKey] = append(tags[tagKey], v)
+			}
+		}
+	}
+}
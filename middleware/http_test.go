@@ -0,0 +1,98 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/abetterchoice/go-sdk/abctest"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+// initRecorder Init the SDK with the test data, the exposures are recorded
+func initRecorder(t *testing.T) *abctest.MetricsRecorder {
+	recorder := abctest.NewMetricsRecorder("pubsub") // the default experiment metrics plugin of the test data
+	metrics.RegisterClient(recorder)
+	err := abc.Init(context.Background(), []string{"123"}, abc.WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		abc.WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	return recorder
+}
+
+func TestHTTP(t *testing.T) {
+	defer abc.Release()
+	recorder := initRecorder(t)
+	client, err := abc.NewClient("123")
+	assert.Nil(t, err)
+	m := NewHTTP(client, WithUnitIDHeader("X-Unit-ID"), WithUnitIDCookie("uid"), WithTagHeader("X-Country", "country"))
+	var scope *Scope
+	var loggedBeforeResponse int
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ok bool
+		scope, ok = ScopeFromContext(r.Context())
+		assert.True(t, ok)
+		injected, ok := abc.FromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, client, injected)
+		for i := 0; i < 3; i++ { // logged once
+			_, err := scope.GetExperiment(r.Context(), "overrideLayer")
+			assert.Nil(t, err)
+		}
+		_, err := scope.GetFeatureFlag(r.Context(), "remoteConfig1")
+		assert.Nil(t, err)
+		time.Sleep(50 * time.Millisecond)
+		loggedBeforeResponse = len(recorder.Exposures().Exposures)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "uid", Value: "12345"})
+	req.Header.Add("X-Country", "us, sg")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "12345", scope.UnitID())
+	assert.Equal(t, 0, loggedBeforeResponse)
+	assert.Eventually(t, func() bool {
+		return len(recorder.Exposures().Exposures) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "12345", recorder.Exposures().Exposures[0].UnitId)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Unit-ID", "54321")
+	req.AddCookie(&http.Cookie{Name: "uid", Value: "12345"})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "54321", scope.UnitID())
+
+	// evaluated after the flush, logged at once
+	recorder.Reset()
+	_, err = scope.GetExperiment(context.Background(), "overrideLayer")
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		return len(recorder.Exposures().Exposures) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestHTTPAttributions(t *testing.T) {
+	client, err := abc.NewClient("123")
+	assert.Nil(t, err)
+	m := NewHTTP(client, WithTagHeader("X-Country", "country"), WithUnitIDFunc(func(r *http.Request) string {
+		return r.URL.Query().Get("uid")
+	}), WithAttributions(func(r *http.Request) []abc.Attribution {
+		return []abc.Attribution{abc.WithTagKV("path", r.URL.Path)}
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/home?uid=1", nil)
+	req.Header.Add("X-Country", "us, sg")
+	req.Header.Add("X-Country", "cn")
+	assert.Equal(t, "1", m.unitID(req))
+	assert.Equal(t, 2, len(m.attributions(req)))
+	assert.Equal(t, 1, len(m.attributions(httptest.NewRequest(http.MethodGet, "/", nil))))
+	_, ok := ScopeFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,190 @@
+// Package middleware The middleware injecting the SDK into the request context. The middleware builds the user context
+// of the request, stores the abc.Client and the request-scoped Scope in the context, and logs the exposures of the
+// experiments evaluated through the Scope once the response is written, for example:
+//
+//	client, _ := abc.NewClient("projectID")
+//	http.Handle("/", middleware.NewHTTP(client, middleware.WithUnitIDHeader("X-User-ID")).Handler(handler))
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		scope, _ := middleware.ScopeFromContext(r.Context())
+//		experiment, err := scope.GetExperiment(r.Context(), "layerKey")
+//	}
+//
+// The gRPC servers use the interceptors of NewGRPC the same way.
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	abc "github.com/abetterchoice/go-sdk"
+	"github.com/pkg/errors"
+)
+
+// Scope The experiment context of a request, the user context of the unit and the exposures pending until the
+// response. The experiments and the feature flags evaluated through the Scope are not logged automatically but by
+// Flush, once per layerKey and feature flag key however many times they are evaluated, the lists of GetExperiments
+// are logged as they are. Concurrent and safe
+type Scope struct {
+	client  *abc.Client
+	unitID  string
+	userCtx abc.Context
+
+	lock sync.Mutex
+	// key is layerKey
+	experiments  map[string]*abc.ExperimentResult
+	lists        []*abc.ExperimentList
+	featureFlags map[string]*abc.FeatureFlag
+	isFlushed    bool
+}
+
+// NewScope Create the scope of the unit, usually called by the middleware, the attributions follow the default ones
+// of the client
+func NewScope(client *abc.Client, unitID string, opts ...abc.Attribution) *Scope {
+	return &Scope{
+		client:       client,
+		unitID:       unitID,
+		userCtx:      client.NewUserContext(unitID, opts...),
+		experiments:  map[string]*abc.ExperimentResult{},
+		featureFlags: map[string]*abc.FeatureFlag{},
+	}
+}
+
+type scopeContextKey struct{}
+
+// NewContext Return a copy of ctx carrying the scope and its client, see abc.FromContext
+func NewContext(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(abc.WithClient(ctx, scope.client), scopeContextKey{}, scope)
+}
+
+// ScopeFromContext Get the scope injected by the middleware, ok is false if not injected
+func ScopeFromContext(ctx context.Context) (scope *Scope, ok bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	scope, ok = ctx.Value(scopeContextKey{}).(*Scope)
+	return scope, ok && scope != nil
+}
+
+// Client The client of the scope
+func (s *Scope) Client() *abc.Client {
+	return s.client
+}
+
+// UnitID The unitID of the request, empty if the request carries none
+func (s *Scope) UnitID() string {
+	return s.unitID
+}
+
+// UserContext The user context of the request, the exposures of the experiments evaluated through it directly are
+// not deferred
+func (s *Scope) UserContext() abc.Context {
+	return s.userCtx
+}
+
+// GetExperiment Get the experiment of the layerKey, the exposure is deferred to Flush, see abc.Client.GetExperiment
+func (s *Scope) GetExperiment(ctx context.Context, layerKey string,
+	opts ...abc.ExperimentOption) (*abc.ExperimentResult, error) {
+	result, err := s.client.GetExperiment(ctx, s.userCtx, layerKey, deferExposure(opts)...)
+	if err != nil || result == nil || result.Group == nil {
+		return result, err
+	}
+	if !s.pend(func() {
+		if _, ok := s.experiments[layerKey]; !ok {
+			s.experiments[layerKey] = result
+		}
+	}) {
+		return result, s.client.LogExperimentExposure(ctx, result)
+	}
+	return result, nil
+}
+
+// GetExperiments Get the experiments of all layers, the exposures are deferred to Flush,
+// see abc.Client.GetExperiments
+func (s *Scope) GetExperiments(ctx context.Context, opts ...abc.ExperimentOption) (*abc.ExperimentList, error) {
+	list, err := s.client.GetExperiments(ctx, s.userCtx, deferExposure(opts)...)
+	if err != nil || list == nil {
+		return list, err
+	}
+	if !s.pend(func() {
+		s.lists = append(s.lists, list)
+	}) {
+		return list, s.client.LogExperimentsExposure(ctx, list)
+	}
+	return list, nil
+}
+
+// GetFeatureFlag Get the feature flag, the exposure is deferred to Flush, see abc.Client.GetFeatureFlag
+func (s *Scope) GetFeatureFlag(ctx context.Context, key string, opts ...abc.ConfigOption) (*abc.FeatureFlag,
+	error) {
+	featureFlag, err := s.client.GetFeatureFlag(ctx, s.userCtx, key, deferExposure(opts)...)
+	if err != nil || featureFlag == nil {
+		return featureFlag, err
+	}
+	if !s.pend(func() {
+		if _, ok := s.featureFlags[key]; !ok {
+			s.featureFlags[key] = featureFlag
+		}
+	}) {
+		return featureFlag, s.client.LogFeatureFlagExposure(ctx, featureFlag)
+	}
+	return featureFlag, nil
+}
+
+// pend Keep the exposure until Flush, false if the scope is flushed already and the exposure should be logged now
+func (s *Scope) pend(fn func()) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.isFlushed {
+		return false
+	}
+	fn()
+	return true
+}
+
+// Flush Log the exposures of the experiments and the feature flags evaluated through the scope, called by the
+// middleware once the response is written. Only the first call logs, the evaluations afterwards are logged at once
+func (s *Scope) Flush(ctx context.Context) error {
+	s.lock.Lock()
+	if s.isFlushed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.isFlushed = true
+	experiments, lists, featureFlags := s.experiments, s.lists, s.featureFlags
+	s.lock.Unlock()
+	logged := map[string]bool{}
+	var errs []error
+	for _, list := range lists {
+		if err := s.client.LogExperimentsExposure(ctx, list); err != nil {
+			errs = append(errs, err)
+		}
+		for layerKey := range list.Data {
+			logged[layerKey] = true
+		}
+	}
+	for layerKey, result := range experiments {
+		if logged[layerKey] {
+			continue
+		}
+		if err := s.client.LogExperimentExposure(ctx, result); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, featureFlag := range featureFlags {
+		if err := s.client.LogFeatureFlagExposure(ctx, featureFlag); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Wrapf(errs[0], "%d of the exposures failed", len(errs))
+	}
+	return nil
+}
+
+// deferExposure The options of the evaluation without the automatic exposure, the options passed in are overwritten
+func deferExposure(opts []abc.ExperimentOption) []abc.ExperimentOption {
+	result := make([]abc.ExperimentOption, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, abc.WithAutomatic(false))
+}
@@ -0,0 +1,49 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// WithExperimentKeyMigrations set the migrations of the experiments of the projectID recreated with new keys, the
+// key of migrations is the old experiment key and the value is the new one. The new experiment is hashed with the
+// method and the seed of the old one, so that the units keep their buckets and the groups allocated the same buckets
+// keep the same units instead of reshuffling. The old experiment must stay in the configuration, without traffic,
+// until the migration is removed, and both must have the same bucket size, the migration is skipped otherwise.
+// The results and the exposures carry the new keys. Calling it again adds to the migrations of the projectID
+func WithExperimentKeyMigrations(projectID string, migrations map[string]string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if len(projectID) == 0 {
+			return errors.Errorf("projectID is required")
+		}
+		merged := make(map[string]string, len(config.ExperimentKeyMigrations[projectID])+len(migrations))
+		for oldKey, newKey := range config.ExperimentKeyMigrations[projectID] {
+			merged[oldKey] = newKey
+		}
+		for oldKey, newKey := range migrations {
+			merged[oldKey] = newKey
+		}
+		targets := make(map[string]string, len(merged))
+		for oldKey, newKey := range merged {
+			if len(oldKey) == 0 || len(newKey) == 0 || oldKey == newKey {
+				return errors.Errorf("invalid migration %q -> %q", oldKey, newKey)
+			}
+			if _, ok := merged[newKey]; ok {
+				return errors.Errorf("chained migration %q -> %q -> %q, migrate to the last key directly", oldKey,
+					newKey, merged[newKey])
+			}
+			if previous, ok := targets[newKey]; ok {
+				return errors.Errorf("both %q and %q migrate to %q", previous, oldKey, newKey)
+			}
+			targets[newKey] = oldKey
+		}
+		if config.ExperimentKeyMigrations == nil {
+			config.ExperimentKeyMigrations = make(map[string]map[string]string)
+		}
+		config.ExperimentKeyMigrations[projectID] = merged
+		return nil
+	}
+}
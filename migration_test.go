@@ -0,0 +1,124 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+// migrationSnapshot The layer of the experiment checkout recreated as checkout_v2 with a new seed, the traffic is
+// allocated to the experiment of trafficID, the groups of both experiments split the buckets in halves
+func migrationSnapshot(t *testing.T, trafficID int64) *ConfigSnapshot {
+	group := func(id int64, experimentID int64, experimentKey string) *protoccacheserver.Group {
+		return &protoccacheserver.Group{Id: id, GroupKey: strconv.FormatInt(id, 10), ExperimentId: experimentID,
+			ExperimentKey: experimentKey, LayerKey: "checkout_layer", IsControl: id%10 == 1,
+			IssueInfo: &protoccacheserver.IssueInfo{IssueType: protoccacheserver.IssueType_ISSUE_TYPE_PERCENTAGE}}
+	}
+	experiment := func(id int64, key string, seed int64) *protoccacheserver.Experiment {
+		return &protoccacheserver.Experiment{Id: id, Key: key, HashMethod: protoccacheserver.HashMethod_HASH_METHOD_BKDR,
+			HashSeed: seed, BucketSize: 100, IssueType: protoccacheserver.IssueType_ISSUE_TYPE_PERCENTAGE,
+			GroupIdIndex: map[int64]bool{id*10 + 1: true, id*10 + 2: true}}
+	}
+	layer := &protoccacheserver.Layer{
+		Metadata: &protoccacheserver.LayerMetadata{Key: "checkout_layer",
+			HashType: protoccacheserver.HashType_HASH_TYPE_DOUBLE, HashMethod: protoccacheserver.HashMethod_HASH_METHOD_BKDR,
+			HashSeed: 5713, BucketSize: 100},
+		GroupIndex: map[int64]*protoccacheserver.Group{11: group(11, 1, "checkout"), 12: group(12, 1, "checkout"),
+			21: group(21, 2, "checkout_v2"), 22: group(22, 2, "checkout_v2")},
+		ExperimentIndex: map[int64]*protoccacheserver.Experiment{1: experiment(1, "checkout", 1111),
+			2: experiment(2, "checkout_v2", 2222)},
+	}
+	domainMetadata := func(key string, domainType protoccacheserver.DomainType) *protoccacheserver.DomainMetadata {
+		return &protoccacheserver.DomainMetadata{Key: key, DomainType: domainType,
+			HashMethod: protoccacheserver.HashMethod_HASH_METHOD_BKDR, HashSeed: 5080801, BucketSize: 100,
+			TrafficRangeList: []*protoccacheserver.TrafficRange{{Left: 1, Right: 100}}}
+	}
+	tabConfig := &protoccacheserver.TabConfig{
+		ExperimentData: &protoccacheserver.ExperimentData{
+			DefaultGroupId: -1,
+			GlobalDomain: &protoccacheserver.Domain{
+				Metadata: domainMetadata("globalDomain", protoccacheserver.DomainType_DOMAIN_TYPE_DOMAIN),
+				MultiLayerDomainList: []*protoccacheserver.MultiLayerDomain{{
+					Metadata:  domainMetadata("multiDomain", protoccacheserver.DomainType_DOMAIN_TYPE_MULTILAYER),
+					LayerList: []*protoccacheserver.Layer{layer},
+				}},
+			},
+		},
+		ConfigData:  &protoccacheserver.RemoteConfigData{},
+		ControlData: &protoccacheserver.ControlData{RefreshInterval: 3},
+	}
+	bucket := func(left int64, right int64) *protoccacheserver.BucketInfo {
+		return &protoccacheserver.BucketInfo{BucketType: protoccacheserver.BucketType_BUCKET_TYPE_RANGE,
+			TrafficRange: &protoccacheserver.TrafficRange{Left: left, Right: right},
+			ModifyType:   protoccacheserver.ModifyType_MODIFY_UPDATE}
+	}
+	snapshot, err := newConfigSnapshot(&client.Snapshot{ProjectID: projectID,
+		TabConfig: &protoccacheserver.GetTabConfigResp{Code: protoccacheserver.Code_CODE_SUCCESS,
+			TabConfigManager: &protoccacheserver.TabConfigManager{ProjectId: projectID, TabConfig: tabConfig}},
+		ExperimentBucket: &protoccacheserver.BatchGetExperimentBucketResp{
+			BucketIndex: map[int64]*protoccacheserver.BucketInfo{trafficID: bucket(1, 100)}},
+		GroupBucket: &protoccacheserver.BatchGetGroupBucketResp{BucketIndex: map[int64]*protoccacheserver.BucketInfo{
+			11: bucket(1, 50), 12: bucket(51, 100), 21: bucket(1, 50), 22: bucket(51, 100)}},
+	})
+	assert.Nil(t, err)
+	return snapshot
+}
+
+func TestWithExperimentKeyMigrations(t *testing.T) {
+	Release()
+	defer Release()
+	ctx := context.Background()
+	evaluate := func(snapshot *ConfigSnapshot) map[string]*ExperimentResult {
+		results := make(map[string]*ExperimentResult)
+		for i := 0; i < 1000; i++ {
+			unitID := strconv.Itoa(i)
+			result, err := EvaluateWithConfig(ctx, snapshot, NewUserContext(unitID), "checkout_layer")
+			assert.Nil(t, err)
+			results[unitID] = result
+		}
+		return results
+	}
+	before := evaluate(migrationSnapshot(t, 1))
+	reshuffled := 0
+	for unitID, result := range evaluate(migrationSnapshot(t, 2)) {
+		assert.Equal(t, "checkout_v2", result.ExperimentKey)
+		if result.ID != before[unitID].ID+10 {
+			reshuffled++
+		}
+	}
+	assert.InDelta(t, 500, reshuffled, 100) // the new seed reshuffles about half of the units
+
+	assert.Nil(t, WithExperimentKeyMigrations(projectID, map[string]string{"checkout": "checkout_v2"})(internal.C))
+	for unitID, result := range evaluate(migrationSnapshot(t, 2)) {
+		assert.Equal(t, "checkout_v2", result.ExperimentKey)
+		assert.Equal(t, before[unitID].ID+10, result.ID, unitID) // the units stay in the equivalent groups
+	}
+	// the migrations of the other projects are not applied
+	internal.C.ExperimentKeyMigrations = map[string]map[string]string{"other": {"checkout": "checkout_v2"}}
+	assert.Equal(t, reshuffled, func() int {
+		count := 0
+		for unitID, result := range evaluate(migrationSnapshot(t, 2)) {
+			if result.ID != before[unitID].ID+10 {
+				count++
+			}
+		}
+		return count
+	}())
+
+	config := &internal.GlobalConfig{}
+	assert.NotNil(t, WithExperimentKeyMigrations("", map[string]string{"a": "b"})(config))
+	assert.NotNil(t, WithExperimentKeyMigrations(projectID, map[string]string{"a": "a"})(config))
+	assert.NotNil(t, WithExperimentKeyMigrations(projectID, map[string]string{"a": ""})(config))
+	assert.NotNil(t, WithExperimentKeyMigrations(projectID, map[string]string{"a": "c", "b": "c"})(config))
+	assert.Nil(t, WithExperimentKeyMigrations(projectID, map[string]string{"a": "b"})(config))
+	assert.EqualError(t, WithExperimentKeyMigrations(projectID, map[string]string{"b": "c"})(config),
+		`chained migration "a" -> "b" -> "c", migrate to the last key directly`)
+	assert.Nil(t, WithExperimentKeyMigrations(projectID, map[string]string{"x": "y"})(config))
+	assert.Equal(t, map[string]string{"a": "b", "x": "y"}, config.ExperimentKeyMigrations[projectID])
+}
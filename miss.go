@@ -0,0 +1,87 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/pkg/errors"
+)
+
+// ErrLayerNotFound The layer passed to GetExperiment, WithLayerKey, GetLayerBucketSpec, GetExperimentMetadata or
+// CompareGroups does not exist in the configuration of the projectID, errors.Is(err, ErrLayerNotFound) tells it apart
+// from the other failures
+var ErrLayerNotFound = experiment.ErrLayerNotFound
+
+// MissBehavior What GetExperiment returns when the layer or the projectID is missing, or no group of the layer is hit
+type MissBehavior = internal.MissBehavior
+
+// const ...
+const (
+	MissBehaviorError   = internal.MissBehaviorError
+	MissBehaviorZero    = internal.MissBehaviorZero
+	MissBehaviorDefault = internal.MissBehaviorDefault
+)
+
+// WithMissBehavior set what GetExperiment of the projectID returns on a miss, so that the callers need no nil check.
+// MissBehaviorError returns ErrLayerNotFound or the error of the missing projectID, and nil if no group is hit,
+// the default. MissBehaviorZero returns a default group of the layer without params. MissBehaviorDefault returns
+// the default group registered by WithMissDefault. The groups returned on a miss are never exposed
+func WithMissBehavior(behavior MissBehavior) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		switch behavior {
+		case "", MissBehaviorError, MissBehaviorZero, MissBehaviorDefault:
+		default:
+			return errors.Errorf("invalid miss behavior:%s", behavior)
+		}
+		options.MissBehavior = behavior
+		return nil
+	}
+}
+
+// WithMissDefault register the params of the default group of the layer returned on a miss with
+// MissBehaviorDefault, nil params removes the registration
+func WithMissDefault(layerKey string, params map[string]string) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		if len(layerKey) == 0 {
+			return errors.Errorf("layerKey is required")
+		}
+		if params == nil {
+			delete(options.MissDefaults, layerKey)
+			return nil
+		}
+		if options.MissDefaults == nil {
+			options.MissDefaults = map[string]map[string]string{}
+		}
+		copied := make(map[string]string, len(params))
+		for key, value := range params {
+			copied[key] = value
+		}
+		options.MissDefaults[layerKey] = copied
+		return nil
+	}
+}
+
+// isMiss Whether the error of the evaluation is a missing layer or projectID
+func isMiss(err error) bool {
+	return errors.Is(err, experiment.ErrLayerNotFound) || errors.Is(err, internal.ErrProjectUnknown) ||
+		errors.Is(err, internal.ErrNotInitialized)
+}
+
+// missExperiment The result of GetExperiment on a miss of the layer by the miss behavior of the projectID, err is
+// the error of the missing layer or projectID, nil if no group is hit
+func missExperiment(projectID string, layerKey string, err error) (*ExperimentResult, error) {
+	behavior, params := internal.Miss(projectID, layerKey)
+	switch behavior {
+	case MissBehaviorZero:
+		params = nil
+	case MissBehaviorDefault:
+	default:
+		return nil, err
+	}
+	group := NewGroup(layerKey, env.DefaultGlobalGroupKey, params)
+	group.ID, group.IsDefault = env.DefaultGlobalGroupID, true
+	return &ExperimentResult{Group: group}, nil // without the userCtx, not exposed
+}
@@ -0,0 +1,50 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissBehavior(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	_, err = userCtx.GetExperiment(context.Background(), projectID, "notExist")
+	assert.True(t, errors.Is(err, ErrLayerNotFound))
+
+	assert.NotNil(t, UpdateOptions(projectID, WithMissBehavior("panic")))
+	assert.NotNil(t, UpdateOptions(projectID, WithMissDefault("", nil)))
+	assert.Nil(t, UpdateOptions(projectID, WithMissBehavior(MissBehaviorZero)))
+	result, err := userCtx.GetExperiment(context.Background(), projectID, "notExist")
+	assert.Nil(t, err)
+	assert.True(t, result.IsDefault)
+	assert.Equal(t, "notExist", result.LayerKey)
+	_, err = result.GetInt64("limit")
+	assert.Equal(t, env.ErrParamKeyNotFound, err)
+	assert.Nil(t, LogExperimentExposure(context.Background(), projectID, result))
+
+	assert.Nil(t, UpdateOptions(projectID, WithMissBehavior(MissBehaviorDefault),
+		WithMissDefault("notExist", map[string]string{"limit": "10"})))
+	result, err = userCtx.GetExperiment(context.Background(), projectID, "notExist")
+	assert.Nil(t, err)
+	limit, err := result.GetInt64("limit")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), limit)
+	// the missing projectID of the same behavior
+	assert.Nil(t, UpdateOptions("notExist", WithMissBehavior(MissBehaviorDefault)))
+	result, err = userCtx.GetExperiment(context.Background(), "notExist", "layer")
+	assert.Nil(t, err)
+	assert.Empty(t, result.Params())
+	// nil params removes the registration
+	assert.Nil(t, UpdateOptions(projectID, WithMissDefault("notExist", nil)))
+	assert.Empty(t, GetOptions(projectID).MissDefaults)
+}
@@ -0,0 +1,44 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+// evaluationPanic A panic recovered from an evaluation API, reported as an evaluation_panic monitor event
+type evaluationPanic struct {
+	projectID string
+	op        string
+	panic     *experiment.EvaluationPanic
+}
+
+// recoverEvaluation Recover the panic of the evaluation API op into err, so that the evaluation never crashes the
+// service. Must be deferred by the API directly, after the deferred events reading err
+func recoverEvaluation(projectID string, op string, err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	*err = errors.Errorf("%s panic:%v", op, recovered)
+	reportPanics(projectID, op, []*experiment.EvaluationPanic{{Value: fmt.Sprint(recovered),
+		Stack: string(debug.Stack())}})
+}
+
+// reportPanics Record and report the panics recovered from the evaluation, the layers of which fell back to the
+// default groups
+func reportPanics(projectID string, op string, panics []*experiment.EvaluationPanic) {
+	for _, p := range panics {
+		err := errors.Errorf("%s panic of the layer [%s]:%s", op, p.LayerKey, p.Value)
+		internal.RecordError(op+":"+projectID, err)
+		log.LimitedErrorf("evaluationPanic"+projectID, "[projectID=%v]%v\n%s", projectID, err, p.Stack)
+		asyncEvaluationPanic(&evaluationPanic{projectID: projectID, op: op, panic: p})
+	}
+}
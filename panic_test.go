@@ -0,0 +1,35 @@
+// Package abc ...
+package abc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverEvaluation(t *testing.T) {
+	internal.ResetRecentErrors()
+	defer internal.ResetRecentErrors()
+	evaluate := func() (err error) {
+		defer recoverEvaluation(projectID, "GetExperiments", &err)
+		var options map[string]bool
+		options["layer"] = true // nil map
+		return nil
+	}
+	err := evaluate()
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "GetExperiments panic:"))
+	records := RecentErrors()
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "GetExperiments:"+projectID, records[0].Source)
+
+	evaluate = func() (err error) {
+		defer recoverEvaluation(projectID, "GetExperiments", &err)
+		return errors.New("not recovered")
+	}
+	assert.Equal(t, "not recovered", evaluate().Error())
+	assert.Equal(t, 1, len(RecentErrors()))
+}
@@ -0,0 +1,92 @@
+// Package log logger
+package log
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultLimitInterval The default interval of the rate-limited error log of the same key
+	defaultLimitInterval = 10 * time.Second
+	// maxLimitKeys Avoid unlimited growth of the keys, such as keys containing the unitID by mistake
+	maxLimitKeys = 1 << 10
+)
+
+type limitState struct {
+	last       time.Time
+	suppressed int64
+}
+
+var (
+	limitLock     sync.Mutex
+	limitInterval = defaultLimitInterval
+	limitIndex    = make(map[string]*limitState)
+)
+
+// SetLimitInterval Set the interval of the rate-limited error log, at most one log per interval for the same key.
+// Zero or negative disables rate limiting
+func SetLimitInterval(interval time.Duration) {
+	limitLock.Lock()
+	defer limitLock.Unlock()
+	limitInterval = interval
+	limitIndex = make(map[string]*limitState)
+}
+
+// LimitedErrorf Rate-limited Errorf for repetitive errors, such as the reporting failure of each exposure.
+// The error of the same key is logged at most once per interval, with the number of suppressed logs appended
+func LimitedErrorf(key string, format string, args ...interface{}) {
+	if loggerLevel > ErrorLevel {
+		return
+	}
+	suppressed, ok := allow(key, time.Now())
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		defaultLogger.Errorf(format+" (%d similar logs suppressed)", append(args, suppressed)...)
+		return
+	}
+	defaultLogger.Errorf(format, args...)
+}
+
+// LimitedError Rate-limited structured error log for repetitive errors, the structured variant of LimitedErrorf.
+// The fields of ctx are merged as Entry does, the number of suppressed logs is appended as the suppressed field
+func LimitedError(ctx context.Context, key string, msg string, fields ...Field) {
+	if loggerLevel > ErrorLevel {
+		return
+	}
+	suppressed, ok := allow(key, time.Now())
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		fields = append(fields[:len(fields):len(fields)], Any("suppressed", suppressed))
+	}
+	(&Entry{}).log(ctx, ErrorLevel, msg, fields)
+}
+
+// allow Whether the log of the key is allowed at now, and return the number of suppressed logs since the last one
+func allow(key string, now time.Time) (int64, bool) {
+	limitLock.Lock()
+	defer limitLock.Unlock()
+	if limitInterval <= 0 {
+		return 0, true
+	}
+	state, ok := limitIndex[key]
+	if !ok {
+		if len(limitIndex) >= maxLimitKeys {
+			limitIndex = make(map[string]*limitState)
+		}
+		limitIndex[key] = &limitState{last: now}
+		return 0, true
+	}
+	if now.Sub(state.last) < limitInterval {
+		state.suppressed++
+		return 0, false
+	}
+	suppressed := state.suppressed
+	state.last, state.suppressed = now, 0
+	return suppressed, true
+}
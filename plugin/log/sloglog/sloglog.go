@@ -0,0 +1,90 @@
+//go:build go1.21
+// +build go1.21
+
+// Package sloglog Adapter of the standard log/slog logger for the SDK log plugin, requires go1.21
+package sloglog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/abetterchoice/go-sdk/plugin/log"
+)
+
+// Logger Implement both log.Logger and log.StructuredLogger on top of slog
+type Logger struct {
+	logger *slog.Logger
+}
+
+// New Create the adapter, nil uses slog.Default
+func New(logger *slog.Logger) *Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Logger{logger: logger}
+}
+
+// Register Register the slog logger as both the printf-style and the structured logger of the SDK
+func Register(logger *slog.Logger) {
+	adapter := New(logger)
+	log.RegisterLogger(adapter)
+	log.RegisterStructuredLogger(adapter)
+}
+
+// Log Implement log.StructuredLogger
+func (l *Logger) Log(ctx context.Context, level log.Level, msg string, fields ...log.Field) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	l.logger.LogAttrs(ctx, slogLevel(level), msg, attrs...)
+}
+
+// Info ...
+func (l *Logger) Info(args ...interface{}) { l.logger.Info(fmt.Sprint(args...)) }
+
+// Infof ...
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logger.Info(fmt.Sprintf(format, args...))
+}
+
+// Warn ...
+func (l *Logger) Warn(args ...interface{}) { l.logger.Warn(fmt.Sprint(args...)) }
+
+// Warnf ...
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logger.Warn(fmt.Sprintf(format, args...))
+}
+
+// Error ...
+func (l *Logger) Error(args ...interface{}) { l.logger.Error(fmt.Sprint(args...)) }
+
+// Errorf ...
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logger.Error(fmt.Sprintf(format, args...))
+}
+
+// Debug ...
+func (l *Logger) Debug(args ...interface{}) { l.logger.Debug(fmt.Sprint(args...)) }
+
+// Debugf ...
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logger.Debug(fmt.Sprintf(format, args...))
+}
+
+func slogLevel(level log.Level) slog.Level {
+	switch level {
+	case log.DebugLevel:
+		return slog.LevelDebug
+	case log.InfoLevel:
+		return slog.LevelInfo
+	case log.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
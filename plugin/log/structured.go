@@ -0,0 +1,161 @@
+// Package log logger
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Field Structured log field
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Any Create a structured log field
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err Create the error field, the key is error
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// StructuredLogger Leveled structured logger, adapters for zap, zerolog and slog are provided in the subpackages.
+// ctx may carry fields attached by ContextWithFields, which are already merged into fields
+type StructuredLogger interface {
+	Log(ctx context.Context, level Level, msg string, fields ...Field)
+}
+
+var structuredLogger StructuredLogger
+
+// RegisterStructuredLogger Register the structured logger. If not registered,
+// the structured logs are formatted as msg key=value and printed to the logger registered by RegisterLogger
+func RegisterStructuredLogger(logger StructuredLogger) {
+	structuredLogger = logger
+}
+
+type fieldsContextKey struct{}
+
+// ContextWithFields Attach fields to ctx, such as the requestID, which are logged with every structured log using ctx
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	current := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(current)+len(fields))
+	merged = append(merged, current...)
+	return context.WithValue(ctx, fieldsContextKey{}, append(merged, fields...))
+}
+
+// FieldsFromContext Get the fields attached by ContextWithFields
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey{}).([]Field)
+	return fields
+}
+
+// Entry Structured logger with bound fields, concurrent and safe
+type Entry struct {
+	fields []Field
+}
+
+// With Create an entry with the bound fields
+func With(fields ...Field) *Entry {
+	return (&Entry{}).With(fields...)
+}
+
+// With Create a new entry with the fields appended, the current entry is not modified
+func (e *Entry) With(fields ...Field) *Entry {
+	merged := make([]Field, 0, len(e.fields)+len(fields))
+	merged = append(merged, e.fields...)
+	return &Entry{fields: append(merged, fields...)}
+}
+
+// Debug Structured debug log
+func (e *Entry) Debug(ctx context.Context, msg string, fields ...Field) {
+	e.log(ctx, DebugLevel, msg, fields)
+}
+
+// Info Structured info log
+func (e *Entry) Info(ctx context.Context, msg string, fields ...Field) {
+	e.log(ctx, InfoLevel, msg, fields)
+}
+
+// Warn Structured warn log
+func (e *Entry) Warn(ctx context.Context, msg string, fields ...Field) {
+	e.log(ctx, WarnLevel, msg, fields)
+}
+
+// Error Structured error log
+func (e *Entry) Error(ctx context.Context, msg string, fields ...Field) {
+	e.log(ctx, ErrorLevel, msg, fields)
+}
+
+func (e *Entry) log(ctx context.Context, level Level, msg string, fields []Field) {
+	if loggerLevel > level {
+		return
+	}
+	contextFields := FieldsFromContext(ctx)
+	all := make([]Field, 0, len(contextFields)+len(e.fields)+len(fields))
+	all = append(append(append(all, contextFields...), e.fields...), fields...)
+	Log(ctx, level, msg, all...)
+}
+
+// Log Print the structured log at the level, the fields of ctx are not merged, use Entry to merge them
+func Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	if loggerLevel > level {
+		return
+	}
+	if structuredLogger != nil {
+		structuredLogger.Log(ctx, level, msg, fields...)
+		return
+	}
+	line := formatFields(msg, fields)
+	switch level {
+	case DebugLevel:
+		defaultLogger.Debugf("%s", line)
+	case InfoLevel:
+		defaultLogger.Infof("%s", line)
+	case WarnLevel:
+		defaultLogger.Warnf("%s", line)
+	default:
+		defaultLogger.Errorf("%s", line)
+	}
+}
+
+// formatFields Format the structured log as msg key1=value1 key2=value2
+func formatFields(msg string, fields []Field) string {
+	if len(fields) == 0 {
+		return msg
+	}
+	var builder strings.Builder
+	builder.WriteString(msg)
+	for _, field := range fields {
+		builder.WriteString(" ")
+		builder.WriteString(field.Key)
+		builder.WriteString("=")
+		builder.WriteString(fmt.Sprint(field.Value))
+	}
+	return builder.String()
+}
+
+// String Level name
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,105 @@
+// Package log logger
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordLogger struct {
+	levels []Level
+	msgs   []string
+	fields [][]Field
+}
+
+func (r *recordLogger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	r.levels = append(r.levels, level)
+	r.msgs = append(r.msgs, msg)
+	r.fields = append(r.fields, fields)
+}
+
+func TestEntry(t *testing.T) {
+	recorder := &recordLogger{}
+	RegisterStructuredLogger(recorder)
+	SetLoggerLevel(InfoLevel)
+	defer func() {
+		RegisterStructuredLogger(nil)
+		SetLoggerLevel(NotLogLevel)
+	}()
+	ctx := ContextWithFields(context.Background(), Any("requestID", "r1"))
+	entry := With(Any("projectID", "123"))
+	entry.Debug(ctx, "ignored")
+	entry.With(Any("layerKey", "layer")).Error(ctx, "fail", Err(context.Canceled))
+	entry.Info(ctx, "ok")
+	assert.Equal(t, []Level{ErrorLevel, InfoLevel}, recorder.levels)
+	assert.Equal(t, []string{"fail", "ok"}, recorder.msgs)
+	assert.Equal(t, []Field{Any("requestID", "r1"), Any("projectID", "123"), Any("layerKey", "layer"),
+		Err(context.Canceled)}, recorder.fields[0])
+	assert.Equal(t, []Field{Any("requestID", "r1"), Any("projectID", "123")}, recorder.fields[1])
+}
+
+func TestFormatFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    string
+		fields []Field
+		want   string
+	}{
+		{name: "no fields", msg: "hello", want: "hello"},
+		{name: "fields", msg: "hello", fields: []Field{Any("a", 1), Any("b", "x")}, want: "hello a=1 b=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatFields(tt.msg, tt.fields))
+		})
+	}
+}
+
+func TestAllow(t *testing.T) {
+	SetLimitInterval(time.Second)
+	defer SetLimitInterval(defaultLimitInterval)
+	now := time.Now()
+	suppressed, ok := allow("key", now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), suppressed)
+	for i := 0; i < 3; i++ {
+		_, ok = allow("key", now.Add(time.Millisecond))
+		assert.False(t, ok)
+	}
+	_, ok = allow("other", now)
+	assert.True(t, ok)
+	suppressed, ok = allow("key", now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), suppressed)
+
+	SetLimitInterval(0)
+	for i := 0; i < 3; i++ {
+		_, ok = allow("key", now)
+		assert.True(t, ok)
+	}
+}
+
+func TestLimitedError(t *testing.T) {
+	recorder := &recordLogger{}
+	RegisterStructuredLogger(recorder)
+	SetLoggerLevel(InfoLevel)
+	SetLimitInterval(time.Hour)
+	defer func() {
+		RegisterStructuredLogger(nil)
+		SetLoggerLevel(NotLogLevel)
+		SetLimitInterval(defaultLimitInterval)
+	}()
+	ctx := ContextWithFields(context.Background(), Any("requestID", "r1"))
+	for i := 0; i < 3; i++ {
+		LimitedError(ctx, "key", "fail", Any("projectID", "123"))
+	}
+	assert.Equal(t, []Level{ErrorLevel}, recorder.levels)
+	assert.Equal(t, []Field{Any("requestID", "r1"), Any("projectID", "123")}, recorder.fields[0])
+
+	SetLimitInterval(0)
+	LimitedError(ctx, "key", "fail")
+	assert.Equal(t, 2, len(recorder.levels))
+}
@@ -0,0 +1,18 @@
+module github.com/abetterchoice/go-sdk/plugin/log/zaplog
+
+go 1.17
+
+require (
+	github.com/abetterchoice/go-sdk v0.0.0-00010101000000-000000000000
+	go.uber.org/zap v1.21.0
+)
+
+require (
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+)
+
+replace (
+	github.com/abetterchoice/go-sdk => ../../..
+	github.com/golang/protobuf => github.com/golang/protobuf v1.4.3
+)
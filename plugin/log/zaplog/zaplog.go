@@ -0,0 +1,77 @@
+// Package zaplog Adapter of the zap logger for the SDK log plugin
+package zaplog
+
+import (
+	"context"
+
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logger Implement both log.Logger and log.StructuredLogger on top of zap
+type Logger struct {
+	logger *zap.Logger
+	sugar  *zap.SugaredLogger
+}
+
+// New Create the adapter, the caller depth is adjusted so that the caller of the SDK is reported
+func New(logger *zap.Logger) *Logger {
+	logger = logger.WithOptions(zap.AddCallerSkip(2))
+	return &Logger{logger: logger, sugar: logger.Sugar()}
+}
+
+// Register Register the zap logger as both the printf-style and the structured logger of the SDK
+func Register(logger *zap.Logger) {
+	adapter := New(logger)
+	log.RegisterLogger(adapter)
+	log.RegisterStructuredLogger(adapter)
+}
+
+// Log Implement log.StructuredLogger
+func (l *Logger) Log(ctx context.Context, level log.Level, msg string, fields ...log.Field) {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		zapFields = append(zapFields, zap.Any(field.Key, field.Value))
+	}
+	if entry := l.logger.Check(zapLevel(level), msg); entry != nil {
+		entry.Write(zapFields...)
+	}
+}
+
+// Info ...
+func (l *Logger) Info(args ...interface{}) { l.sugar.Info(args...) }
+
+// Infof ...
+func (l *Logger) Infof(format string, args ...interface{}) { l.sugar.Infof(format, args...) }
+
+// Warn ...
+func (l *Logger) Warn(args ...interface{}) { l.sugar.Warn(args...) }
+
+// Warnf ...
+func (l *Logger) Warnf(format string, args ...interface{}) { l.sugar.Warnf(format, args...) }
+
+// Error ...
+func (l *Logger) Error(args ...interface{}) { l.sugar.Error(args...) }
+
+// Errorf ...
+func (l *Logger) Errorf(format string, args ...interface{}) { l.sugar.Errorf(format, args...) }
+
+// Debug ...
+func (l *Logger) Debug(args ...interface{}) { l.sugar.Debug(args...) }
+
+// Debugf ...
+func (l *Logger) Debugf(format string, args ...interface{}) { l.sugar.Debugf(format, args...) }
+
+func zapLevel(level log.Level) zapcore.Level {
+	switch level {
+	case log.DebugLevel:
+		return zapcore.DebugLevel
+	case log.InfoLevel:
+		return zapcore.InfoLevel
+	case log.WarnLevel:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
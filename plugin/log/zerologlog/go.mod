@@ -0,0 +1,19 @@
+module github.com/abetterchoice/go-sdk/plugin/log/zerologlog
+
+go 1.17
+
+require (
+	github.com/abetterchoice/go-sdk v0.0.0-00010101000000-000000000000
+	github.com/rs/zerolog v1.29.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.12 // indirect
+	github.com/mattn/go-isatty v0.0.14 // indirect
+	golang.org/x/sys v0.6.0 // indirect
+)
+
+replace (
+	github.com/abetterchoice/go-sdk => ../../..
+	github.com/golang/protobuf => github.com/golang/protobuf v1.4.3
+)
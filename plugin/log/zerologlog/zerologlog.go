@@ -0,0 +1,77 @@
+// Package zerologlog Adapter of the zerolog logger for the SDK log plugin
+package zerologlog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/rs/zerolog"
+)
+
+// Logger Implement both log.Logger and log.StructuredLogger on top of zerolog
+type Logger struct {
+	logger zerolog.Logger
+}
+
+// New Create the adapter
+func New(logger zerolog.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// Register Register the zerolog logger as both the printf-style and the structured logger of the SDK
+func Register(logger zerolog.Logger) {
+	adapter := New(logger)
+	log.RegisterLogger(adapter)
+	log.RegisterStructuredLogger(adapter)
+}
+
+// Log Implement log.StructuredLogger
+func (l *Logger) Log(ctx context.Context, level log.Level, msg string, fields ...log.Field) {
+	event := l.logger.WithLevel(zerologLevel(level))
+	for _, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			event = event.AnErr(field.Key, err)
+			continue
+		}
+		event = event.Interface(field.Key, field.Value)
+	}
+	event.Msg(msg)
+}
+
+// Info ...
+func (l *Logger) Info(args ...interface{}) { l.logger.Info().Msg(fmt.Sprint(args...)) }
+
+// Infof ...
+func (l *Logger) Infof(format string, args ...interface{}) { l.logger.Info().Msgf(format, args...) }
+
+// Warn ...
+func (l *Logger) Warn(args ...interface{}) { l.logger.Warn().Msg(fmt.Sprint(args...)) }
+
+// Warnf ...
+func (l *Logger) Warnf(format string, args ...interface{}) { l.logger.Warn().Msgf(format, args...) }
+
+// Error ...
+func (l *Logger) Error(args ...interface{}) { l.logger.Error().Msg(fmt.Sprint(args...)) }
+
+// Errorf ...
+func (l *Logger) Errorf(format string, args ...interface{}) { l.logger.Error().Msgf(format, args...) }
+
+// Debug ...
+func (l *Logger) Debug(args ...interface{}) { l.logger.Debug().Msg(fmt.Sprint(args...)) }
+
+// Debugf ...
+func (l *Logger) Debugf(format string, args ...interface{}) { l.logger.Debug().Msgf(format, args...) }
+
+func zerologLevel(level log.Level) zerolog.Level {
+	switch level {
+	case log.DebugLevel:
+		return zerolog.DebugLevel
+	case log.InfoLevel:
+		return zerolog.InfoLevel
+	case log.WarnLevel:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
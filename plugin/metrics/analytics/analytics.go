@@ -0,0 +1,228 @@
+// Package analytics Export the exposures as the events of the analytics products, such as the Google Analytics 4
+// Measurement Protocol, so that the marketing side analysis can join on the experiment assignments
+package analytics
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/pkg/errors"
+)
+
+// DefaultEventName The name of the exported exposure event, the recommended experiment event of Google Analytics 4
+const DefaultEventName = "experience_impression"
+
+// Event The analytics event converted from an exposure
+type Event struct {
+	// The pseudonymous identifier of the device or browser, required by Google Analytics 4
+	ClientID string
+	// The identifier of the signed-in user, optional
+	UserID string
+	// Unix microseconds of the exposure
+	TimestampMicros int64
+	Name            string
+	Params          map[string]interface{}
+}
+
+// Sink The analytics product receiving the events, such as NewGA4
+type Sink interface {
+	// Send Send the events, called synchronously by the exposure consumer of the SDK
+	Send(ctx context.Context, events []*Event) error
+}
+
+// Option NewExporter and Wrap related options
+type Option func(o *options)
+
+type options struct {
+	name      string
+	eventName string
+	clientID  func(exposure *protoc_event_server.Exposure) string
+	userID    func(exposure *protoc_event_server.Exposure) string
+	// keys of the extra data of the exposure exported as the event params
+	extraDataKeys []string
+}
+
+// WithName The plugin name of NewExporter, the remote configuration routes the exposures to the plugin of the name,
+// "ga4" by default
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithEventName The name of the exported event, DefaultEventName by default
+func WithEventName(eventName string) Option {
+	return func(o *options) {
+		o.eventName = eventName
+	}
+}
+
+// WithClientID Take the client ID of the event from the exposure, the unitID by default. The client ID should be the
+// same as the one of the analytics tag of the web or mobile client for the events to join
+func WithClientID(fn func(exposure *protoc_event_server.Exposure) string) Option {
+	return func(o *options) {
+		o.clientID = fn
+	}
+}
+
+// WithUserID Take the user ID of the event from the exposure, not set by default
+func WithUserID(fn func(exposure *protoc_event_server.Exposure) string) Option {
+	return func(o *options) {
+		o.userID = fn
+	}
+}
+
+// WithExtraDataKeys Export the extra data of the keys as the event params
+func WithExtraDataKeys(keys ...string) Option {
+	return func(o *options) {
+		o.extraDataKeys = append(o.extraDataKeys, keys...)
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{name: "ga4", eventName: DefaultEventName}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Exporter The metrics plugin exporting the exposures to the sink, the other events are ignored
+type Exporter struct {
+	sink    Sink
+	options *options
+}
+
+// NewExporter Create the metrics plugin exporting the exposures to the sink, register it through
+// metrics.RegisterClient. To export in addition to the existing plugin, see Wrap
+func NewExporter(sink Sink, opts ...Option) *Exporter {
+	return &Exporter{sink: sink, options: newOptions(opts)}
+}
+
+// Name Implement metrics.Client
+func (e *Exporter) Name() string {
+	return e.options.name
+}
+
+// Init Implement metrics.Client
+func (e *Exporter) Init(ctx context.Context, config *protoc_cache_server.MetricsInitConfig) error {
+	return nil
+}
+
+// LogExposure Implement metrics.Client, the exposures are converted and sent to the sink
+func (e *Exporter) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	events := e.Convert(exposureGroup)
+	if len(events) == 0 {
+		return nil
+	}
+	return errors.Wrap(e.sink.Send(ctx, events), "send")
+}
+
+// LogEvent Implement metrics.Client, ignored
+func (e *Exporter) LogEvent(ctx context.Context, metadata *metrics.Metadata,
+	eventGroup *protoc_event_server.EventGroup) error {
+	return nil
+}
+
+// LogMonitorEvent Implement metrics.Client, ignored
+func (e *Exporter) LogMonitorEvent(ctx context.Context, metadata *metrics.Metadata,
+	monitorEventGroup *protoc_event_server.MonitorEventGroup) error {
+	return nil
+}
+
+// SendData Implement metrics.Client, ignored
+func (e *Exporter) SendData(ctx context.Context, metadata *metrics.Metadata, data [][]string) error {
+	return nil
+}
+
+// RetainsMessages Implement metrics.MessageRetainer, the exposures are converted before LogExposure returns
+func (e *Exporter) RetainsMessages() bool {
+	return false
+}
+
+// Convert Convert the exposures to the events, the exposures without the client ID are skipped
+func (e *Exporter) Convert(exposureGroup *protoc_event_server.ExposureGroup) []*Event {
+	if exposureGroup == nil {
+		return nil
+	}
+	events := make([]*Event, 0, len(exposureGroup.Exposures))
+	for _, exposure := range exposureGroup.Exposures {
+		if exposure == nil {
+			continue
+		}
+		event := &Event{
+			ClientID:        exposure.UnitId,
+			TimestampMicros: exposure.Time * 1e6,
+			Name:            e.options.eventName,
+			Params: map[string]interface{}{
+				// the variant identifier of the recommended experiment event
+				"exp_variant_string": "ABC-" + exposure.ExpKey + "-" + strconv.FormatInt(exposure.GroupId, 10),
+				"abc_project_id":     exposure.ProjectId,
+				"abc_layer_key":      exposure.LayerKey,
+				"abc_experiment_key": exposure.ExpKey,
+				"abc_group_id":       strconv.FormatInt(exposure.GroupId, 10),
+			},
+		}
+		if e.options.clientID != nil {
+			event.ClientID = e.options.clientID(exposure)
+		}
+		if len(event.ClientID) == 0 {
+			continue
+		}
+		if e.options.userID != nil {
+			event.UserID = e.options.userID(exposure)
+		}
+		for _, key := range e.options.extraDataKeys {
+			if value, ok := exposure.ExtraData[key]; ok {
+				event.Params[key] = value
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// wrapped The plugin forwarding everything to the next plugin and exporting the exposures in addition
+type wrapped struct {
+	metrics.Client
+	exporter *Exporter
+}
+
+// Wrap Export the exposures to the sink in addition to the next plugin, the name of the next plugin is kept,
+// such as metrics.RegisterClient(analytics.Wrap(pubsubClient, sink)). The exposures are logged to the next plugin
+// first, the export errors are returned only if the next plugin succeeds
+func Wrap(next metrics.Client, sink Sink, opts ...Option) metrics.Client {
+	return &wrapped{Client: next, exporter: NewExporter(sink, opts...)}
+}
+
+// LogExposure Implement metrics.Client
+func (w *wrapped) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	events := w.exporter.Convert(exposureGroup) // before the next plugin, which may retain and reuse the messages
+	if err := w.Client.LogExposure(ctx, metadata, exposureGroup); err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return errors.Wrap(w.exporter.sink.Send(ctx, events), "send")
+}
+
+// RetainsMessages Implement metrics.MessageRetainer, the same as the next plugin
+func (w *wrapped) RetainsMessages() bool {
+	retainer, ok := w.Client.(metrics.MessageRetainer)
+	return !ok || retainer.RetainsMessages()
+}
+
+// SupportedSchemaVersion Implement metrics.SchemaNegotiator, the same as the next plugin
+func (w *wrapped) SupportedSchemaVersion() uint32 {
+	negotiator, ok := w.Client.(metrics.SchemaNegotiator)
+	if !ok {
+		return metrics.CurrentSchemaVersion
+	}
+	return negotiator.SupportedSchemaVersion()
+}
@@ -0,0 +1,138 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSink struct {
+	events []*Event
+}
+
+func (s *recordingSink) Send(ctx context.Context, events []*Event) error {
+	s.events = append(s.events, events...)
+	return nil
+}
+
+type nextClient struct {
+	metrics.Client
+	exposures int
+	err       error
+}
+
+func (c *nextClient) Name() string {
+	return "pubsub"
+}
+
+func (c *nextClient) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	c.exposures += len(exposureGroup.Exposures)
+	return c.err
+}
+
+func newExposureGroup(n int) *protoc_event_server.ExposureGroup {
+	group := &protoc_event_server.ExposureGroup{}
+	for i := 0; i < n; i++ {
+		group.Exposures = append(group.Exposures, &protoc_event_server.Exposure{
+			UnitId:    "u" + strconv.Itoa(i%2),
+			GroupId:   100,
+			ProjectId: "123",
+			Time:      1700000000,
+			LayerKey:  "layer",
+			ExpKey:    "exp",
+			ExtraData: map[string]string{"page": "home"},
+		})
+	}
+	return group
+}
+
+func TestExporter(t *testing.T) {
+	sink := &recordingSink{}
+	exporter := NewExporter(sink, WithExtraDataKeys("page", "missing"),
+		WithUserID(func(exposure *protoc_event_server.Exposure) string {
+			return "user-" + exposure.UnitId
+		}))
+	assert.Equal(t, "ga4", exporter.Name())
+	assert.False(t, exporter.RetainsMessages())
+	assert.Nil(t, exporter.LogExposure(context.Background(), &metrics.Metadata{}, newExposureGroup(1)))
+	assert.Equal(t, []*Event{{
+		ClientID:        "u0",
+		UserID:          "user-u0",
+		TimestampMicros: 1700000000 * 1e6,
+		Name:            DefaultEventName,
+		Params: map[string]interface{}{
+			"exp_variant_string": "ABC-exp-100",
+			"abc_project_id":     "123",
+			"abc_layer_key":      "layer",
+			"abc_experiment_key": "exp",
+			"abc_group_id":       "100",
+			"page":               "home",
+		},
+	}}, sink.events)
+
+	exporter = NewExporter(sink, WithName("analytics"), WithEventName("abc_exposure"),
+		WithClientID(func(exposure *protoc_event_server.Exposure) string {
+			return exposure.ExtraData["ga_client_id"]
+		}))
+	assert.Equal(t, "analytics", exporter.Name())
+	assert.Empty(t, exporter.Convert(newExposureGroup(2))) // no client ID
+	assert.Empty(t, exporter.Convert(nil))
+}
+
+func TestWrap(t *testing.T) {
+	sink := &recordingSink{}
+	next := &nextClient{}
+	client := Wrap(next, sink)
+	assert.Equal(t, "pubsub", client.Name())
+	assert.True(t, client.(metrics.MessageRetainer).RetainsMessages())
+	assert.Nil(t, client.LogExposure(context.Background(), &metrics.Metadata{}, newExposureGroup(3)))
+	assert.Equal(t, 3, next.exposures)
+	assert.Equal(t, 3, len(sink.events))
+
+	next.err = assert.AnError
+	assert.Equal(t, assert.AnError, client.LogExposure(context.Background(), &metrics.Metadata{},
+		newExposureGroup(1)))
+	assert.Equal(t, 3, len(sink.events))
+}
+
+func TestGA4(t *testing.T) {
+	var lock sync.Mutex
+	var requests []*ga4Request
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "G-TEST", r.URL.Query().Get("measurement_id"))
+		assert.Equal(t, "secret", r.URL.Query().Get("api_secret"))
+		request := &ga4Request{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(request))
+		lock.Lock()
+		requests = append(requests, request)
+		lock.Unlock()
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	exporter := NewExporter(NewGA4("G-TEST", "secret", WithGA4Endpoint(server.URL),
+		WithGA4HTTPClient(server.Client())))
+	assert.Nil(t, exporter.LogExposure(context.Background(), &metrics.Metadata{}, newExposureGroup(60)))
+	// u0 and u1 of 30 events each, split into 25 and 5
+	assert.Equal(t, 4, len(requests))
+	counts := map[string]int{}
+	for _, request := range requests {
+		assert.LessOrEqual(t, len(request.Events), ga4MaxEvents)
+		counts[request.ClientID] += len(request.Events)
+		assert.Equal(t, DefaultEventName, request.Events[0].Name)
+		assert.Equal(t, "ABC-exp-100", request.Events[0].Params["exp_variant_string"])
+	}
+	assert.Equal(t, map[string]int{"u0": 30, "u1": 30}, counts)
+
+	status = http.StatusBadRequest
+	assert.NotNil(t, exporter.LogExposure(context.Background(), &metrics.Metadata{}, newExposureGroup(1)))
+}
@@ -0,0 +1,117 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// GA4Endpoint The collection endpoint of the Google Analytics 4 Measurement Protocol
+	GA4Endpoint = "https://www.google-analytics.com/mp/collect"
+	// GA4DebugEndpoint The validation endpoint, the events sent to it are validated but not collected
+	GA4DebugEndpoint = "https://www.google-analytics.com/debug/mp/collect"
+	// ga4MaxEvents The maximum number of the events of a request
+	ga4MaxEvents = 25
+)
+
+// GA4Option NewGA4 related options
+type GA4Option func(g *GA4)
+
+// WithGA4Endpoint The endpoint of the Measurement Protocol, such as GA4DebugEndpoint or the EU endpoint
+func WithGA4Endpoint(endpoint string) GA4Option {
+	return func(g *GA4) {
+		g.endpoint = endpoint
+	}
+}
+
+// WithGA4HTTPClient The http client sending the events, http.DefaultClient by default
+func WithGA4HTTPClient(client *http.Client) GA4Option {
+	return func(g *GA4) {
+		g.client = client
+	}
+}
+
+// GA4 The sink sending the events to a web stream of Google Analytics 4 through the Measurement Protocol
+type GA4 struct {
+	measurementID string
+	apiSecret     string
+	endpoint      string
+	client        *http.Client
+}
+
+// NewGA4 Create the sink of the web stream of the measurementID, the apiSecret is created under the stream
+func NewGA4(measurementID string, apiSecret string, opts ...GA4Option) *GA4 {
+	g := &GA4{measurementID: measurementID, apiSecret: apiSecret, endpoint: GA4Endpoint, client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+type ga4Request struct {
+	ClientID        string      `json:"client_id"`
+	UserID          string      `json:"user_id,omitempty"`
+	TimestampMicros int64       `json:"timestamp_micros,omitempty"`
+	Events          []*ga4Event `json:"events"`
+}
+
+type ga4Event struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// Send Implement Sink, the events of the same client are sent in the requests of at most 25 events
+func (g *GA4) Send(ctx context.Context, events []*Event) error {
+	var requests []*ga4Request
+	index := map[string]*ga4Request{} // key is the client ID and the user ID
+	for _, event := range events {
+		key := event.ClientID + "\x00" + event.UserID
+		request, ok := index[key]
+		if !ok || len(request.Events) >= ga4MaxEvents {
+			request = &ga4Request{ClientID: event.ClientID, UserID: event.UserID,
+				TimestampMicros: event.TimestampMicros}
+			index[key] = request
+			requests = append(requests, request)
+		}
+		request.Events = append(request.Events, &ga4Event{Name: event.Name, Params: event.Params})
+	}
+	for _, request := range requests {
+		if err := g.post(ctx, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *GA4) post(ctx context.Context, request *ga4Request) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	query := url.Values{}
+	query.Set("measurement_id", g.measurementID)
+	query.Set("api_secret", g.apiSecret)
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint+"?"+query.Encode(),
+		bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "newRequest")
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	response, err := g.client.Do(httpRequest)
+	if err != nil {
+		return errors.Wrap(err, "do")
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body) // reuse the connection
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return nil
+}
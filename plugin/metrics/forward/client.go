@@ -0,0 +1,75 @@
+package forward
+
+import (
+	"context"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Client The metrics plugin of the instance forwarding the batches to the sidecar, it takes the name of the plugin
+// of the remote configuration so that the batches routed to the plugin are forwarded instead
+type Client struct {
+	name string
+	conn grpc.ClientConnInterface
+}
+
+// NewClient Create the forwarding plugin of the name through the connection to the sidecar
+func NewClient(name string, conn grpc.ClientConnInterface) *Client {
+	return &Client{name: name, conn: conn}
+}
+
+// Register Register the forwarding plugins of the names, it should be called before abc.Init so that the plugins of
+// the names are not initialized on the instance
+func Register(conn grpc.ClientConnInterface, names ...string) {
+	for _, name := range names {
+		metrics.RegisterClient(NewClient(name, conn))
+	}
+}
+
+// Name Implement metrics.Client
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Init Implement metrics.Client, the plugin is initialized on the sidecar, nothing to do
+func (c *Client) Init(ctx context.Context, config *protoc_cache_server.MetricsInitConfig) error {
+	return nil
+}
+
+// LogExposure Implement metrics.Client
+func (c *Client) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	return c.invoke(ctx, "LogExposure", metadata, exposureGroup)
+}
+
+// LogEvent Implement metrics.Client
+func (c *Client) LogEvent(ctx context.Context, metadata *metrics.Metadata,
+	eventGroup *protoc_event_server.EventGroup) error {
+	return c.invoke(ctx, "LogEvent", metadata, eventGroup)
+}
+
+// LogMonitorEvent Implement metrics.Client
+func (c *Client) LogMonitorEvent(ctx context.Context, metadata *metrics.Metadata,
+	monitorEventGroup *protoc_event_server.MonitorEventGroup) error {
+	return c.invoke(ctx, "LogMonitorEvent", metadata, monitorEventGroup)
+}
+
+// SendData Implement metrics.Client
+func (c *Client) SendData(ctx context.Context, metadata *metrics.Metadata, data [][]string) error {
+	return c.invoke(ctx, "SendData", metadata, encodeData(data))
+}
+
+// RetainsMessages Implement metrics.MessageRetainer, the messages are encoded before the call returns
+func (c *Client) RetainsMessages() bool {
+	return false
+}
+
+func (c *Client) invoke(ctx context.Context, method string, metadata *metrics.Metadata, req interface{}) error {
+	err := c.conn.Invoke(outgoingContext(ctx, metadata), "/"+ServiceName+"/"+method, req, &emptypb.Empty{})
+	return errors.Wrapf(err, "forward %s", method)
+}
@@ -0,0 +1,177 @@
+// Package forward Forward the exposures of the SDK instances to a designated sidecar over gRPC, the sidecar holds the
+// metrics plugins and their credentials and reports on behalf of the instances, so that the short-lived jobs do not
+// each need the plugin credentials.
+//
+// The sidecar registers the real plugins, calls abc.Init with the projects so that the plugins are initialized, and
+// serves the forwarded batches:
+//
+//	go forward.ListenAndServe(ctx, "127.0.0.1:9090")
+//
+// Each job registers the forwarding plugins under the plugin names of the remote configuration before abc.Init:
+//
+//	conn, err := grpc.Dial("127.0.0.1:9090", grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	forward.Register(conn, "pubsub")
+package forward
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName The name of the gRPC service of the sidecar
+const ServiceName = "abc.forward.v1.Forwarder"
+
+// The keys of the gRPC metadata carrying the metrics.Metadata of a batch
+const (
+	mdPluginName = "abc-metrics-plugin"
+	mdTableName  = "abc-table-name"
+	mdTableID    = "abc-table-id"
+	mdToken      = "abc-token"
+	// the schema version of the payload, the sidecar downconverts it to the version of its plugin
+	mdSchemaVersion = "abc-schema-version"
+	// the sampling interval the batch was sampled with by the instance
+	mdSampledInterval = "abc-sampled-interval"
+)
+
+// forwarderServer The methods of the service, the batches are sampled by the instances already
+type forwarderServer interface {
+	logExposure(ctx context.Context, metadata *metrics.Metadata, group *protoc_event_server.ExposureGroup) error
+	logEvent(ctx context.Context, metadata *metrics.Metadata, group *protoc_event_server.EventGroup) error
+	logMonitorEvent(ctx context.Context, metadata *metrics.Metadata,
+		group *protoc_event_server.MonitorEventGroup) error
+	sendData(ctx context.Context, metadata *metrics.Metadata, data [][]string) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*forwarderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "LogExposure", Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			return handle(srv, ctx, dec, interceptor, "LogExposure", &protoc_event_server.ExposureGroup{},
+				func(s forwarderServer, ctx context.Context, md *metrics.Metadata, req interface{}) error {
+					return s.logExposure(ctx, md, req.(*protoc_event_server.ExposureGroup))
+				})
+		}},
+		{MethodName: "LogEvent", Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			return handle(srv, ctx, dec, interceptor, "LogEvent", &protoc_event_server.EventGroup{},
+				func(s forwarderServer, ctx context.Context, md *metrics.Metadata, req interface{}) error {
+					return s.logEvent(ctx, md, req.(*protoc_event_server.EventGroup))
+				})
+		}},
+		{MethodName: "LogMonitorEvent", Handler: func(srv interface{}, ctx context.Context,
+			dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			return handle(srv, ctx, dec, interceptor, "LogMonitorEvent", &protoc_event_server.MonitorEventGroup{},
+				func(s forwarderServer, ctx context.Context, md *metrics.Metadata, req interface{}) error {
+					return s.logMonitorEvent(ctx, md, req.(*protoc_event_server.MonitorEventGroup))
+				})
+		}},
+		{MethodName: "SendData", Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			return handle(srv, ctx, dec, interceptor, "SendData", &structpb.ListValue{},
+				func(s forwarderServer, ctx context.Context, md *metrics.Metadata, req interface{}) error {
+					return s.sendData(ctx, md, decodeData(req.(*structpb.ListValue)))
+				})
+		}},
+	},
+	Metadata: "forward.go",
+}
+
+// handle Decode the request and call the method through the interceptor, the metadata of the batch is taken from the
+// incoming gRPC metadata
+func handle(srv interface{}, ctx context.Context, dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor, method string, req interface{},
+	call func(s forwarderServer, ctx context.Context, md *metrics.Metadata, req interface{}) error) (interface{},
+	error) {
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := call(srv.(forwarderServer), ctx, metadataFromIncoming(ctx), req); err != nil {
+			return nil, err
+		}
+		return &emptypb.Empty{}, nil
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + method},
+		handler)
+}
+
+// outgoingContext The context carrying the metadata of the batch to the sidecar
+func outgoingContext(ctx context.Context, md *metrics.Metadata) context.Context {
+	if md == nil {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, mdPluginName, md.MetricsPluginName, mdTableName, md.TableName,
+		mdTableID, md.TableID, mdToken, md.Token, mdSchemaVersion, strconv.FormatUint(uint64(md.SchemaVersion), 10),
+		mdSampledInterval, strconv.FormatUint(uint64(md.SampledInterval), 10))
+}
+
+// metadataFromIncoming The metadata of the batch forwarded by the instance, always reported since the instance has
+// sampled the batch
+func metadataFromIncoming(ctx context.Context) *metrics.Metadata {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	schemaVersion, _ := strconv.ParseUint(first(mdSchemaVersion), 10, 32)
+	sampledInterval, _ := strconv.ParseUint(first(mdSampledInterval), 10, 32)
+	return &metrics.Metadata{
+		MetricsPluginName: first(mdPluginName),
+		TableName:         first(mdTableName),
+		TableID:           first(mdTableID),
+		Token:             first(mdToken),
+		SamplingInterval:  1,
+		SchemaVersion:     uint32(schemaVersion), // 0 from the instances before the schema versions, the current one
+		SampledInterval:   uint32(sampledInterval),
+	}
+}
+
+// encodeData Encode the rows of SendData as a list of the lists of strings
+func encodeData(data [][]string) *structpb.ListValue {
+	rows := make([]*structpb.Value, 0, len(data))
+	for _, row := range data {
+		values := make([]*structpb.Value, 0, len(row))
+		for _, v := range row {
+			values = append(values, structpb.NewStringValue(v))
+		}
+		rows = append(rows, structpb.NewListValue(&structpb.ListValue{Values: values}))
+	}
+	return &structpb.ListValue{Values: rows}
+}
+
+// decodeData The rows encoded by encodeData, the values that are not strings are formatted
+func decodeData(list *structpb.ListValue) [][]string {
+	data := make([][]string, 0, len(list.GetValues()))
+	for _, rowValue := range list.GetValues() {
+		values := rowValue.GetListValue().GetValues()
+		row := make([]string, 0, len(values))
+		for _, v := range values {
+			switch kind := v.GetKind().(type) {
+			case *structpb.Value_StringValue:
+				row = append(row, kind.StringValue)
+			case *structpb.Value_NumberValue:
+				row = append(row, strconv.FormatFloat(kind.NumberValue, 'f', -1, 64))
+			case *structpb.Value_BoolValue:
+				row = append(row, strconv.FormatBool(kind.BoolValue))
+			default:
+				row = append(row, "")
+			}
+		}
+		data = append(data, row)
+	}
+	return data
+}
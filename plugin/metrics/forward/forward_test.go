@@ -0,0 +1,82 @@
+package forward
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/abctest"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcmetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialSidecar Serve the sidecar in memory and return the connection of the instances
+func dialSidecar(t *testing.T) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	NewServer().Register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.Dial()
+		}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestForward(t *testing.T) {
+	// the sidecar and the instance share the plugin registry in the test, the instance forwards to the plugin
+	// of another name to avoid forwarding to itself
+	recorder := abctest.NewMetricsRecorder("forwardRecorder")
+	metrics.RegisterClient(recorder)
+	client := NewClient("pubsub", dialSidecar(t))
+	assert.Equal(t, "pubsub", client.Name())
+	assert.False(t, client.RetainsMessages())
+	assert.Nil(t, client.Init(context.Background(), nil))
+	metadata := &metrics.Metadata{MetricsPluginName: "forwardRecorder", TableName: "table", TableID: "1",
+		Token: "token", SamplingInterval: 100}
+
+	err := client.LogExposure(context.Background(), metadata, &protoc_event_server.ExposureGroup{
+		Exposures: []*protoc_event_server.Exposure{{UnitId: "u1", GroupId: 1}, {UnitId: "u2", GroupId: 2}}})
+	assert.Nil(t, err)
+	exposures := recorder.Exposures().Exposures
+	assert.Equal(t, 2, len(exposures))
+	assert.Equal(t, "u2", exposures[1].UnitId)
+
+	err = client.LogMonitorEvent(context.Background(), metadata, &protoc_event_server.MonitorEventGroup{
+		Events: []*protoc_event_server.MonitorEvent{{ProjectId: "123"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(recorder.MonitorEvents().Events))
+
+	err = client.SendData(context.Background(), metadata, [][]string{{"a", "b"}, {}, {"c"}})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {}, {"c"}}, recorder.Data())
+
+	assert.Nil(t, client.LogEvent(context.Background(), metadata, &protoc_event_server.EventGroup{}))
+
+	metadata.MetricsPluginName = "notRegistered"
+	err = client.LogExposure(context.Background(), metadata, &protoc_event_server.ExposureGroup{
+		Exposures: []*protoc_event_server.Exposure{{UnitId: "u1"}}})
+	assert.NotNil(t, err)
+}
+
+func TestMetadata(t *testing.T) {
+	ctx := outgoingContext(context.Background(), &metrics.Metadata{MetricsPluginName: "p", TableName: "n",
+		TableID: "i", Token: "t", SamplingInterval: 1, SchemaVersion: metrics.SchemaVersion1, SampledInterval: 10})
+	assert.Equal(t, context.Background(), outgoingContext(context.Background(), nil))
+	outgoing, _ := grpcmetadata.FromOutgoingContext(ctx)
+	got := metadataFromIncoming(grpcmetadata.NewIncomingContext(context.Background(), outgoing))
+	assert.Equal(t, &metrics.Metadata{MetricsPluginName: "p", TableName: "n", TableID: "i", Token: "t",
+		SamplingInterval: 1, SchemaVersion: metrics.SchemaVersion1, SampledInterval: 10}, got)
+}
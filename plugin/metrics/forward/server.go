@@ -0,0 +1,87 @@
+package forward
+
+import (
+	"context"
+	"net"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server The service of the sidecar reporting the forwarded batches through the plugins registered on the sidecar
+type Server struct{}
+
+// NewServer Create the service, see Register and ListenAndServe
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Register Register the service to the gRPC server, such as the server already run by the sidecar
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&serviceDesc, s)
+}
+
+// ListenAndServe Serve the forwarded batches on the address until ctx is done. The service has no authentication,
+// bind it to the loopback or pass the credentials through the server options
+func ListenAndServe(ctx context.Context, address string, opts ...grpc.ServerOption) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return errors.Wrap(err, "listen")
+	}
+	server := grpc.NewServer(opts...)
+	NewServer().Register(server)
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	return server.Serve(listener)
+}
+
+func (s *Server) logExposure(ctx context.Context, metadata *metrics.Metadata,
+	group *protoc_event_server.ExposureGroup) error {
+	if err := checkPlugin(metadata); err != nil {
+		return err
+	}
+	return metrics.LogExposure(ctx, metadata, group)
+}
+
+func (s *Server) logEvent(ctx context.Context, metadata *metrics.Metadata,
+	group *protoc_event_server.EventGroup) error {
+	if err := checkPlugin(metadata); err != nil {
+		return err
+	}
+	if group == nil || len(group.Events) == 0 {
+		return nil
+	}
+	client, _ := metrics.GetClient(metadata.MetricsPluginName)
+	return client.LogEvent(ctx, metadata, group)
+}
+
+func (s *Server) logMonitorEvent(ctx context.Context, metadata *metrics.Metadata,
+	group *protoc_event_server.MonitorEventGroup) error {
+	if err := checkPlugin(metadata); err != nil {
+		return err
+	}
+	return metrics.LogMonitorEvent(ctx, metadata, group)
+}
+
+func (s *Server) sendData(ctx context.Context, metadata *metrics.Metadata, data [][]string) error {
+	if err := checkPlugin(metadata); err != nil {
+		return err
+	}
+	return metrics.SendData(ctx, metadata, data)
+}
+
+// checkPlugin The batches of the plugins not registered on the sidecar are rejected rather than dropped, so that the
+// instances count them as lost
+func checkPlugin(metadata *metrics.Metadata) error {
+	if _, ok := metrics.GetClient(metadata.MetricsPluginName); !ok {
+		return status.Errorf(codes.FailedPrecondition, "metrics plugin [%s] not registered on the sidecar",
+			metadata.MetricsPluginName)
+	}
+	return nil
+}
@@ -8,4 +8,8 @@ type Metadata struct {
 	TableID           string `json:"tableId"`           // Specific table ID
 	Token             string `json:"token"`             // Token
 	SamplingInterval  uint32 `json:"samplingInterval"`  // Sampling interval
+	SchemaVersion     uint32 `json:"schemaVersion"`     // Schema version of the payload, 0 is CurrentSchemaVersion
+	// The sampling interval the exposures were sampled with by the SDK, one of every SampledInterval is kept, so that
+	// the plugins can weight the exposures. SamplingInterval is 1 once the SDK has sampled, 0 if not sampled yet
+	SampledInterval uint32 `json:"sampledInterval,omitempty"`
 }
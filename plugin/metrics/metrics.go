@@ -4,10 +4,10 @@ package metrics
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"runtime"
 	"sync"
 
+	"github.com/abetterchoice/go-sdk/internal/random"
 	"github.com/abetterchoice/go-sdk/plugin/log"
 	"github.com/abetterchoice/protoc_cache_server"
 	"github.com/abetterchoice/protoc_event_server"
@@ -100,6 +100,37 @@ func RegisterLogExposureHook(handler func(metadata *Metadata, group *protoc_even
 	logExposureHook = handler
 }
 
+// MessageRetainer Optional interface of the plugin, a plugin that does not retain the messages after LogExposure
+// and LogMonitorEvent return, for example the plugin encodes the messages synchronously,
+// returns false so that the SDK can reuse the messages. The plugins that do not implement it are considered
+// to retain the messages, such as the plugins that queue them for asynchronous sending
+type MessageRetainer interface {
+	// RetainsMessages Whether the plugin still references the messages after the logging call returns
+	RetainsMessages() bool
+}
+
+// Flusher Optional interface of the plugin queueing the messages for asynchronous sending, Flush sends the queued
+// messages and returns the error of the delivery, so that the acceptance of the messages can be verified,
+// such as by abc.SelfTest
+type Flusher interface {
+	// Flush Send the queued messages until they are accepted by the backend or ctx is done
+	Flush(ctx context.Context) error
+}
+
+// IsMessageReusable Whether the messages passed to the plugin can be reused after the logging call returns,
+// it is false when the plugin retains the messages or the exposure hook is registered
+func IsMessageReusable(pluginName string) bool {
+	if logExposureHook != nil {
+		return false
+	}
+	c, ok := GetClient(pluginName)
+	if !ok {
+		return true // not reported
+	}
+	retainer, ok := c.(MessageRetainer)
+	return ok && !retainer.RetainsMessages()
+}
+
 // SendData sends data, reports in multiple channels. If the passed clientNames have been registered,
 // it will be reported Here, the plug-in interface is directly called for reporting. Generally,
 // monitoring reporting components have asynchronous reporting functions,
@@ -132,6 +163,8 @@ func SendData(ctx context.Context, metadata *Metadata, data [][]string) (err err
 	if !ok {
 		return nil
 	}
+	// the rows are downconverted by the SDK, which knows their columns, see NegotiateSchemaVersion
+	metadata.SchemaVersion = negotiateSchemaVersion(metadata.MetricsPluginName, c, metadata.SchemaVersion)
 	return c.SendData(ctx, metadata, data)
 }
 
@@ -167,6 +200,9 @@ func LogExposure(ctx context.Context, metadata *Metadata, group *protoc_event_se
 	if !ok {
 		return nil
 	}
+	version := negotiateSchemaVersion(metadata.MetricsPluginName, c, metadata.SchemaVersion)
+	downconvertExposureGroup(group, schemaVersion(metadata), version)
+	metadata.SchemaVersion = version
 	return c.LogExposure(ctx, metadata, group)
 }
 
@@ -194,6 +230,9 @@ func LogMonitorEvent(ctx context.Context, metadata *Metadata, group *protoc_even
 	if !ok {
 		return nil
 	}
+	version := negotiateSchemaVersion(metadata.MetricsPluginName, c, metadata.SchemaVersion)
+	downconvertMonitorEventGroup(group, schemaVersion(metadata), version)
+	metadata.SchemaVersion = version
 	return c.LogMonitorEvent(ctx, metadata, group)
 }
 
@@ -203,7 +242,7 @@ func SamplingResult(interval uint32) bool {
 		return false
 	}
 	if interval > 1 {
-		randValue := rand.Int63n(int64(interval))
+		randValue := random.Int63n(int64(interval))
 		if randValue != int64(interval)-1 {
 			return false
 		}
@@ -294,3 +294,43 @@ var (
 	// Deprecated: for test
 	EmptyMetricsClient = &empty{}
 )
+
+type retainer struct {
+	empty
+	name     string
+	retained bool
+}
+
+func (a retainer) Name() string {
+	return a.name
+}
+
+func (a retainer) RetainsMessages() bool {
+	return a.retained
+}
+
+func TestIsMessageReusable(t *testing.T) {
+	defer func() {
+		clientFactory = make(map[string]Client)
+	}()
+	RegisterClient(EmptyMetricsClient)
+	RegisterClient(&retainer{name: "retained", retained: true})
+	RegisterClient(&retainer{name: "released", retained: false})
+	tests := []struct {
+		name       string
+		pluginName string
+		want       bool
+	}{
+		{name: "not registered", pluginName: "n", want: true},
+		{name: "not implemented", pluginName: "empty", want: false},
+		{name: "retained", pluginName: "retained", want: false},
+		{name: "released", pluginName: "released", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMessageReusable(tt.pluginName); got != tt.want {
+				t.Errorf("IsMessageReusable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
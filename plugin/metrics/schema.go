@@ -0,0 +1,100 @@
+// Package metrics
+package metrics
+
+import (
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+// The versions of the schema of the reported payloads, carried in Metadata.SchemaVersion. The payloads are
+// downconverted to the version supported by the plugin, so that the SDK can be upgraded before the pipeline
+const (
+	// SchemaVersion1 The payloads without the event IDs, the expanded data column of the remote config rows is in
+	// the legacy encoding
+	SchemaVersion1 uint32 = 1
+	// SchemaVersion2 The extended details of the exposures and the monitor events carry the event_id if enabled, the
+	// expanded data column of the remote config rows is in the encoding of abc.WithExpandedDataEncoding
+	SchemaVersion2 uint32 = 2
+	// CurrentSchemaVersion The version of the payloads built by the SDK
+	CurrentSchemaVersion = SchemaVersion2
+)
+
+// SchemaNegotiator Optional interface of the plugin, a plugin consuming an older schema returns the newest version
+// it supports, and the payloads are downconverted to it instead of failing. The plugins that do not implement it
+// are considered to support the current version, unless registered by RegisterSchemaVersion
+type SchemaNegotiator interface {
+	// SupportedSchemaVersion The newest schema version the plugin consumes
+	SupportedSchemaVersion() uint32
+}
+
+var schemaVersions sync.Map // key is the plugin name, value is the uint32 registered by RegisterSchemaVersion
+
+// RegisterSchemaVersion Register the newest schema version supported by the plugin of the name, for the plugins that
+// cannot implement SchemaNegotiator, such as the third-party ones. It takes precedence over SchemaNegotiator,
+// 0 removes the registration
+func RegisterSchemaVersion(pluginName string, version uint32) {
+	if version == 0 {
+		schemaVersions.Delete(pluginName)
+		return
+	}
+	schemaVersions.Store(pluginName, version)
+}
+
+// NegotiateSchemaVersion The schema version of the payloads of the version passed to the plugin of the name, the older
+// one of the version and the newest version supported by the plugin. 0 is the current version
+func NegotiateSchemaVersion(pluginName string, version uint32) uint32 {
+	c, _ := GetClient(pluginName)
+	return negotiateSchemaVersion(pluginName, c, version)
+}
+
+func negotiateSchemaVersion(pluginName string, c Client, version uint32) uint32 {
+	if version == 0 {
+		version = CurrentSchemaVersion
+	}
+	var supported uint32
+	if value, ok := schemaVersions.Load(pluginName); ok {
+		supported = value.(uint32)
+	} else if negotiator, ok := c.(SchemaNegotiator); ok {
+		supported = negotiator.SupportedSchemaVersion()
+	} else {
+		return version
+	}
+	if supported < SchemaVersion1 {
+		supported = SchemaVersion1
+	}
+	if supported < version {
+		return supported
+	}
+	return version
+}
+
+// downconvertExposureGroup Convert the exposures from the schema version to the older one in place
+func downconvertExposureGroup(group *protoc_event_server.ExposureGroup, from uint32, to uint32) {
+	if from < SchemaVersion2 || to >= SchemaVersion2 {
+		return
+	}
+	for _, exposure := range group.Exposures {
+		// the extra data with the event ID is a copy of each exposure, see internal.ExtraDataWithEventID
+		delete(exposure.ExtraData, internal.EventIDKey)
+	}
+}
+
+// downconvertMonitorEventGroup Convert the monitor events from the schema version to the older one in place
+func downconvertMonitorEventGroup(group *protoc_event_server.MonitorEventGroup, from uint32, to uint32) {
+	if from < SchemaVersion2 || to >= SchemaVersion2 {
+		return
+	}
+	for _, event := range group.Events {
+		delete(event.ExtInfo, internal.EventIDKey)
+	}
+}
+
+// schemaVersion The schema version of the payload of the metadata, 0 is the current version
+func schemaVersion(metadata *Metadata) uint32 {
+	if metadata.SchemaVersion == 0 {
+		return CurrentSchemaVersion
+	}
+	return metadata.SchemaVersion
+}
@@ -0,0 +1,83 @@
+// Package metrics ...
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaPlugin struct {
+	empty
+	name      string
+	supported uint32
+	metadata  *Metadata
+	exposures *protoc_event_server.ExposureGroup
+	events    *protoc_event_server.MonitorEventGroup
+}
+
+func (p *schemaPlugin) Name() string {
+	return p.name
+}
+
+func (p *schemaPlugin) SupportedSchemaVersion() uint32 {
+	return p.supported
+}
+
+func (p *schemaPlugin) LogExposure(ctx context.Context, metadata *Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	p.metadata, p.exposures = metadata, exposureGroup
+	return nil
+}
+
+func (p *schemaPlugin) LogMonitorEvent(ctx context.Context, metadata *Metadata,
+	eventGroup *protoc_event_server.MonitorEventGroup) error {
+	p.metadata, p.events = metadata, eventGroup
+	return nil
+}
+
+func TestNegotiateSchemaVersion(t *testing.T) {
+	defer func() {
+		clientFactory = make(map[string]Client)
+		RegisterSchemaVersion("empty", 0)
+	}()
+	RegisterClient(EmptyMetricsClient)
+	old := &schemaPlugin{name: "old", supported: SchemaVersion1}
+	RegisterClient(old)
+	RegisterClient(&schemaPlugin{name: "newer", supported: CurrentSchemaVersion + 1})
+	assert.Equal(t, CurrentSchemaVersion, NegotiateSchemaVersion("empty", 0))
+	assert.Equal(t, CurrentSchemaVersion, NegotiateSchemaVersion("n", 0))
+	assert.Equal(t, SchemaVersion1, NegotiateSchemaVersion("old", 0))
+	assert.Equal(t, CurrentSchemaVersion, NegotiateSchemaVersion("newer", CurrentSchemaVersion))
+	assert.Equal(t, SchemaVersion1, NegotiateSchemaVersion("newer", SchemaVersion1))
+	RegisterSchemaVersion("empty", SchemaVersion1)
+	assert.Equal(t, SchemaVersion1, NegotiateSchemaVersion("empty", 0))
+
+	ctx := context.Background()
+	err := LogExposure(ctx, &Metadata{MetricsPluginName: "old", SamplingInterval: 1},
+		&protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{
+			{UnitId: "u1", ExtraData: map[string]string{internal.EventIDKey: "id", "k": "v"}},
+			{UnitId: "u2"},
+		}})
+	assert.Nil(t, err)
+	assert.Equal(t, SchemaVersion1, old.metadata.SchemaVersion)
+	assert.Equal(t, map[string]string{"k": "v"}, old.exposures.Exposures[0].ExtraData)
+	err = LogMonitorEvent(ctx, &Metadata{MetricsPluginName: "old", SamplingInterval: 1},
+		&protoc_event_server.MonitorEventGroup{Events: []*protoc_event_server.MonitorEvent{
+			{EventName: "exp", ExtInfo: map[string]string{internal.EventIDKey: "id"}},
+		}})
+	assert.Nil(t, err)
+	assert.Empty(t, old.events.Events[0].ExtInfo)
+
+	old.supported = CurrentSchemaVersion // upgraded, nothing is converted
+	err = LogExposure(ctx, &Metadata{MetricsPluginName: "old", SamplingInterval: 1},
+		&protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{
+			{UnitId: "u1", ExtraData: map[string]string{internal.EventIDKey: "id"}},
+		}})
+	assert.Nil(t, err)
+	assert.Equal(t, CurrentSchemaVersion, old.metadata.SchemaVersion)
+	assert.Equal(t, "id", old.exposures.Exposures[0].ExtraData[internal.EventIDKey])
+}
@@ -0,0 +1,202 @@
+// Package stream Let the embedding application pull the payloads of the SDK instead of the SDK pushing them to the
+// metrics plugins, so that the host services with their own batching and delivery infrastructure fully control the
+// delivery. Register the stream under the plugin name of the remote configuration before abc.Init and consume it:
+//
+//	s := stream.Register("pubsub", 1024)
+//	for {
+//		records, err := s.Read(ctx, buffer)
+//		if err != nil {
+//			return err // io.EOF once closed and drained
+//		}
+//		deliver(buffer[:records])
+//	}
+//
+// The stream is bounded, the logging calls of the SDK wait for the room while the application does not pull, so that
+// the exposure queues of the SDK fill up and the backpressure applies, see abc.ErrBackpressure
+package stream
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/pkg/errors"
+)
+
+// DefaultSize The default number of the records the stream holds before the logging calls wait
+const DefaultSize = 1024
+
+// ErrClosed The stream is closed, the logging calls fail with it
+var ErrClosed = errors.New("stream is closed")
+
+// Kind The kind of the payload of a record
+type Kind string
+
+const (
+	// KindExposure The record of the exposures, see Record.Exposures
+	KindExposure Kind = "exposure"
+	// KindEvent The record of the events, see Record.Events
+	KindEvent Kind = "event"
+	// KindMonitorEvent The record of the monitor events, see Record.MonitorEvents
+	KindMonitorEvent Kind = "monitor_event"
+	// KindData The record of the rows of SendData, such as the remote config exposures, see Record.Data
+	KindData Kind = "data"
+)
+
+// Record A payload logged by the SDK, one of the payloads is set by the Kind. The record belongs to the application
+// once pulled, the SDK does not reuse it
+type Record struct {
+	Kind Kind
+	// The destination of the payload, such as the table and the token
+	Metadata      *metrics.Metadata
+	Exposures     *protoc_event_server.ExposureGroup
+	Events        *protoc_event_server.EventGroup
+	MonitorEvents *protoc_event_server.MonitorEventGroup
+	Data          [][]string
+}
+
+// Stream The metrics plugin queueing the payloads for the application to pull, concurrent and safe
+type Stream struct {
+	name      string
+	records   chan *Record
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// New Create the stream of the plugin name holding at most size records, zero uses DefaultSize,
+// register it through metrics.RegisterClient
+func New(name string, size int) *Stream {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Stream{name: name, records: make(chan *Record, size), closed: make(chan struct{})}
+}
+
+// Register Create the stream of the plugin name and register it, see New
+func Register(name string, size int) *Stream {
+	s := New(name, size)
+	metrics.RegisterClient(s)
+	return s
+}
+
+// Next Pull the next record, waiting until one is logged or ctx is done. io.EOF once the stream is closed and drained
+func (s *Stream) Next(ctx context.Context) (*Record, error) {
+	select {
+	case record := <-s.records:
+		return record, nil
+	default:
+	}
+	select {
+	case record := <-s.records:
+		return record, nil
+	case <-s.closed:
+		select { // drain the records logged before the close
+		case record := <-s.records:
+			return record, nil
+		default:
+			return nil, io.EOF
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Read Pull up to len(records) records into records, waiting for the first one like Next and then taking the queued
+// ones without waiting, so that the application can batch. It returns the number of the records read, io.EOF once
+// the stream is closed and drained
+func (s *Stream) Read(ctx context.Context, records []*Record) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	record, err := s.Next(ctx)
+	if err != nil {
+		return 0, err
+	}
+	records[0] = record
+	n := 1
+	for ; n < len(records); n++ {
+		select {
+		case record := <-s.records:
+			records[n] = record
+		default:
+			return n, nil
+		}
+	}
+	return n, nil
+}
+
+// Len The number of the records waiting to be pulled
+func (s *Stream) Len() int {
+	return len(s.records)
+}
+
+// Close Close the stream, the logging calls fail with ErrClosed, including the ones waiting for the room, and the
+// records queued can still be pulled. Idempotent
+func (s *Stream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
+
+// Name Implement metrics.Client
+func (s *Stream) Name() string {
+	return s.name
+}
+
+// Init Implement metrics.Client, nothing to do
+func (s *Stream) Init(ctx context.Context, config *protoc_cache_server.MetricsInitConfig) error {
+	return nil
+}
+
+// LogExposure Implement metrics.Client, the exposures are queued
+func (s *Stream) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	return s.push(ctx, &Record{Kind: KindExposure, Metadata: metadata, Exposures: exposureGroup})
+}
+
+// LogEvent Implement metrics.Client, the events are queued
+func (s *Stream) LogEvent(ctx context.Context, metadata *metrics.Metadata,
+	eventGroup *protoc_event_server.EventGroup) error {
+	return s.push(ctx, &Record{Kind: KindEvent, Metadata: metadata, Events: eventGroup})
+}
+
+// LogMonitorEvent Implement metrics.Client, the monitor events are queued
+func (s *Stream) LogMonitorEvent(ctx context.Context, metadata *metrics.Metadata,
+	monitorEventGroup *protoc_event_server.MonitorEventGroup) error {
+	return s.push(ctx, &Record{Kind: KindMonitorEvent, Metadata: metadata, MonitorEvents: monitorEventGroup})
+}
+
+// SendData Implement metrics.Client, the rows are queued
+func (s *Stream) SendData(ctx context.Context, metadata *metrics.Metadata, data [][]string) error {
+	return s.push(ctx, &Record{Kind: KindData, Metadata: metadata, Data: data})
+}
+
+// RetainsMessages Implement metrics.MessageRetainer, the messages are handed to the application
+func (s *Stream) RetainsMessages() bool {
+	return true
+}
+
+// push Queue the record, waiting for the room until ctx is done or the stream is closed
+func (s *Stream) push(ctx context.Context, record *Record) error {
+	select {
+	case <-s.closed:
+		return ErrClosed
+	default:
+	}
+	if record.Metadata != nil { // the record must not share the metadata with the caller
+		metadata := *record.Metadata
+		record.Metadata = &metadata
+	}
+	select {
+	case s.records <- record:
+		return nil
+	case <-s.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "stream is full")
+	}
+}
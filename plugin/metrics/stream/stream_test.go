@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	s := Register("stream_test", 2)
+	client, ok := metrics.GetClient("stream_test")
+	assert.True(t, ok)
+	assert.Equal(t, s, client)
+	assert.True(t, s.RetainsMessages())
+
+	ctx := context.Background()
+	metadata := &metrics.Metadata{TableName: "exposure"}
+	group := &protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{{UnitId: "u1"}}}
+	assert.Nil(t, s.LogExposure(ctx, metadata, group))
+	assert.Nil(t, s.SendData(ctx, metadata, [][]string{{"row"}}))
+	assert.Equal(t, 2, s.Len())
+
+	// full, waits for the room until ctx is done
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, s.LogMonitorEvent(timeoutCtx, metadata, &protoc_event_server.MonitorEventGroup{}))
+
+	records := make([]*Record, 4)
+	n, err := s.Read(ctx, records)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, KindExposure, records[0].Kind)
+	assert.Equal(t, group, records[0].Exposures)
+	assert.Equal(t, "exposure", records[0].Metadata.TableName)
+	assert.NotSame(t, metadata, records[0].Metadata)
+	assert.Equal(t, KindData, records[1].Kind)
+	assert.Equal(t, [][]string{{"row"}}, records[1].Data)
+
+	timeoutCtx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = s.Next(timeoutCtx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the waiting logging call is released by a pull
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_, _ = s.Next(ctx)
+	}()
+	assert.Nil(t, s.LogEvent(ctx, metadata, &protoc_event_server.EventGroup{}))
+	assert.Nil(t, s.LogEvent(ctx, metadata, &protoc_event_server.EventGroup{}))
+	assert.Nil(t, s.LogEvent(ctx, metadata, &protoc_event_server.EventGroup{}))
+
+	assert.Nil(t, s.Close())
+	assert.Nil(t, s.Close())
+	assert.Equal(t, ErrClosed, s.LogEvent(ctx, metadata, &protoc_event_server.EventGroup{}))
+	n, err = s.Read(ctx, records)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n) // drained after the close
+	assert.Equal(t, KindEvent, records[0].Kind)
+	_, err = s.Next(ctx)
+	assert.Equal(t, io.EOF, err)
+	n, err = s.Read(ctx, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,381 @@
+// Package uds Write the payloads of the SDK to a local agent over a Unix domain socket, such as the telemetry daemon
+// of the node, so that the agent holds the egress credentials instead of each pod. Register the client under the
+// plugin name of the remote configuration before abc.Init:
+//
+//	client := uds.Register("pubsub", "/var/run/telemetry/abc.sock")
+//	defer client.Close()
+//
+// Each payload is written as a frame, see ReadFrame for the agents in Go:
+//
+//	| length uint32 | version uint8 | kind uint8 | metadata length uint16 | metadata JSON | payload |
+//
+// The integers are big endian, length counts the bytes after itself. The payload is the protobuf wire format of the
+// group of the kind, the JSON array of the rows for KindData. A frame interrupted by a failed write is written again
+// on the next connection from its start, the agent drops the partial frame at the end of a connection.
+//
+// The frames are queued and written in order by a single connection. The queue is bounded, the logging calls wait for
+// the room while the agent is down or slow, so that the exposure queues of the SDK fill up and the backpressure
+// applies, see abc.ErrBackpressure
+package uds
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FrameVersion The version of the frame encoding, bumped on incompatible changes
+	FrameVersion = 1
+	// DefaultQueueSize The default number of the frames queued before the logging calls wait
+	DefaultQueueSize = 1024
+	// DefaultWriteTimeout The default timeout of writing a frame to the agent
+	DefaultWriteTimeout = time.Second
+	// MaxFrameSize The maximum size of a frame read by ReadFrame
+	MaxFrameSize = 64 << 20
+
+	maxRetryInterval = 5 * time.Second
+	headerSize       = 4 // version, kind and metadata length
+)
+
+// ErrClosed The client is closed, the logging calls fail with it
+var ErrClosed = errors.New("uds client is closed")
+
+// Kind The kind of the payload of a frame
+type Kind uint8
+
+const (
+	// KindExposure The payload is an ExposureGroup
+	KindExposure Kind = 1
+	// KindEvent The payload is an EventGroup
+	KindEvent Kind = 2
+	// KindMonitorEvent The payload is a MonitorEventGroup
+	KindMonitorEvent Kind = 3
+	// KindData The payload is the JSON array of the rows of SendData, such as the remote config exposures
+	KindData Kind = 4
+)
+
+// Frame A frame decoded by ReadFrame
+type Frame struct {
+	Kind     Kind
+	Metadata *metrics.Metadata
+	Payload  []byte
+}
+
+// Option The option of the client
+type Option func(c *Client)
+
+// WithQueueSize The number of the frames queued before the logging calls wait, DefaultQueueSize by default
+func WithQueueSize(size int) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.queueSize = size
+		}
+	}
+}
+
+// WithWriteTimeout The timeout of writing a frame, the connection is closed and dialed again on the timeout,
+// DefaultWriteTimeout by default
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		if timeout > 0 {
+			c.writeTimeout = timeout
+		}
+	}
+}
+
+// WithRetryInterval The first interval of dialing the agent again after a failure, doubled up to 5s,
+// 100ms by default
+func WithRetryInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		if interval > 0 {
+			c.retryInterval = interval
+		}
+	}
+}
+
+// Client The metrics plugin writing the payloads to the agent listening on the socket path, concurrent and safe
+type Client struct {
+	name          string
+	path          string
+	queueSize     int
+	writeTimeout  time.Duration
+	retryInterval time.Duration
+
+	frames    chan []byte
+	pending   int64 // queued or being written
+	lastErr   atomic.Value
+	conn      net.Conn // owned by the writer goroutine
+	closed    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New Create the client of the plugin name writing to the socket path, register it through metrics.RegisterClient.
+// The agent is dialed by the first write, Close stops the client
+func New(name string, path string, opts ...Option) *Client {
+	c := &Client{
+		name:          name,
+		path:          path,
+		queueSize:     DefaultQueueSize,
+		writeTimeout:  DefaultWriteTimeout,
+		retryInterval: 100 * time.Millisecond,
+		closed:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.frames = make(chan []byte, c.queueSize)
+	go c.run()
+	return c
+}
+
+// Register Create the client of the plugin name and register it, see New
+func Register(name string, path string, opts ...Option) *Client {
+	c := New(name, path, opts...)
+	metrics.RegisterClient(c)
+	return c
+}
+
+// Len The number of the frames waiting to be written
+func (c *Client) Len() int {
+	return len(c.frames)
+}
+
+// Close Stop the client, the frames queued are written once more without retry, the logging calls fail with
+// ErrClosed, including the ones waiting for the room. Idempotent
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	<-c.done
+	return nil
+}
+
+// Name Implement metrics.Client
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Init Implement metrics.Client, nothing to do
+func (c *Client) Init(ctx context.Context, config *protoc_cache_server.MetricsInitConfig) error {
+	return nil
+}
+
+// LogExposure Implement metrics.Client, the exposures are queued
+func (c *Client) LogExposure(ctx context.Context, metadata *metrics.Metadata,
+	exposureGroup *protoc_event_server.ExposureGroup) error {
+	return c.pushMessage(ctx, KindExposure, metadata, exposureGroup)
+}
+
+// LogEvent Implement metrics.Client, the events are queued
+func (c *Client) LogEvent(ctx context.Context, metadata *metrics.Metadata,
+	eventGroup *protoc_event_server.EventGroup) error {
+	return c.pushMessage(ctx, KindEvent, metadata, eventGroup)
+}
+
+// LogMonitorEvent Implement metrics.Client, the monitor events are queued
+func (c *Client) LogMonitorEvent(ctx context.Context, metadata *metrics.Metadata,
+	monitorEventGroup *protoc_event_server.MonitorEventGroup) error {
+	return c.pushMessage(ctx, KindMonitorEvent, metadata, monitorEventGroup)
+}
+
+// SendData Implement metrics.Client, the rows are queued
+func (c *Client) SendData(ctx context.Context, metadata *metrics.Metadata, data [][]string) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "json marshal")
+	}
+	return c.push(ctx, KindData, metadata, payload)
+}
+
+// RetainsMessages Implement metrics.MessageRetainer, the messages are encoded before the logging calls return
+func (c *Client) RetainsMessages() bool {
+	return false
+}
+
+// Flush Implement metrics.Flusher, wait until the frames queued are written to the agent, the last error of the
+// writes is returned if ctx is done before
+func (c *Client) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&c.pending) > 0 {
+		select {
+		case <-ctx.Done():
+			if err, ok := c.lastErr.Load().(error); ok {
+				return errors.Wrap(err, "flush")
+			}
+			return errors.Wrap(ctx.Err(), "flush")
+		case <-c.done:
+			return ErrClosed
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (c *Client) pushMessage(ctx context.Context, kind Kind, metadata *metrics.Metadata, message proto.Message) error {
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		return errors.Wrap(err, "proto marshal")
+	}
+	return c.push(ctx, kind, metadata, payload)
+}
+
+// push Queue the frame of the payload, waiting for the room until ctx is done or the client is closed
+func (c *Client) push(ctx context.Context, kind Kind, metadata *metrics.Metadata, payload []byte) error {
+	select {
+	case <-c.closed:
+		return ErrClosed
+	default:
+	}
+	frame, err := encodeFrame(kind, metadata, payload)
+	if err != nil {
+		return err
+	}
+	atomic.AddInt64(&c.pending, 1)
+	select {
+	case c.frames <- frame:
+		return nil
+	case <-c.closed:
+		err = ErrClosed
+	case <-ctx.Done():
+		err = errors.Wrap(ctx.Err(), "uds queue is full")
+	}
+	atomic.AddInt64(&c.pending, -1)
+	return err
+}
+
+func (c *Client) run() {
+	defer close(c.done)
+	defer func() {
+		if c.conn != nil {
+			_ = c.conn.Close()
+		}
+	}()
+	for {
+		select {
+		case frame := <-c.frames:
+			c.write(frame, true)
+		case <-c.closed:
+			for {
+				select {
+				case frame := <-c.frames:
+					if !c.write(frame, false) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write Write the frame to the agent, dialing it if not connected. With retry, the frame is written again until it
+// succeeds or the client is closed, it is counted as lost otherwise
+func (c *Client) write(frame []byte, retry bool) bool {
+	defer atomic.AddInt64(&c.pending, -1)
+	interval := c.retryInterval
+	for {
+		err := c.writeOnce(frame)
+		if err == nil {
+			return true
+		}
+		c.lastErr.Store(err)
+		log.LimitedErrorf("udsWrite", "uds write to %s fail:%v", c.path, err)
+		if !retry {
+			return false
+		}
+		select {
+		case <-c.closed:
+			return false
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
+
+func (c *Client) writeOnce(frame []byte) error {
+	if c.conn == nil {
+		conn, err := net.DialTimeout("unix", c.path, c.writeTimeout)
+		if err != nil {
+			return errors.Wrap(err, "dial")
+		}
+		c.conn = conn
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if _, err := c.conn.Write(frame); err != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+		return errors.Wrap(err, "write")
+	}
+	return nil
+}
+
+func encodeFrame(kind Kind, metadata *metrics.Metadata, payload []byte) ([]byte, error) {
+	if metadata == nil {
+		metadata = &metrics.Metadata{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "metadata")
+	}
+	if len(metadataJSON) > 0xFFFF {
+		return nil, errors.Errorf("metadata of %d bytes is too long", len(metadataJSON))
+	}
+	length := headerSize + len(metadataJSON) + len(payload)
+	frame := make([]byte, 4, 4+length)
+	binary.BigEndian.PutUint32(frame, uint32(length))
+	frame = append(frame, FrameVersion, byte(kind), 0, 0)
+	binary.BigEndian.PutUint16(frame[6:8], uint16(len(metadataJSON)))
+	frame = append(frame, metadataJSON...)
+	return append(frame, payload...), nil
+}
+
+// ReadFrame Read the next frame written by the client, io.EOF at the end of the connection between the frames,
+// io.ErrUnexpectedEOF if the frame is partial
+func ReadFrame(r io.Reader) (*Frame, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(prefix[:])
+	if length < headerSize || length > MaxFrameSize {
+		return nil, errors.Errorf("invalid frame length %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if data[0] != FrameVersion {
+		return nil, errors.Errorf("unsupported frame version %d", data[0])
+	}
+	metadataLength := int(binary.BigEndian.Uint16(data[2:4]))
+	if headerSize+metadataLength > len(data) {
+		return nil, errors.Errorf("invalid metadata length %d", metadataLength)
+	}
+	frame := &Frame{Kind: Kind(data[1]), Metadata: &metrics.Metadata{}, Payload: data[headerSize+metadataLength:]}
+	if err := json.Unmarshal(data[headerSize:headerSize+metadataLength], frame.Metadata); err != nil {
+		return nil, errors.Wrap(err, "metadata")
+	}
+	return frame, nil
+}
@@ -0,0 +1,118 @@
+package uds
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// agent Accept the connections on the path and decode the frames into the channel
+func agent(t *testing.T, path string) (net.Listener, chan *Frame) {
+	listener, err := net.Listen("unix", path)
+	assert.Nil(t, err)
+	frames := make(chan *Frame, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				for {
+					frame, err := ReadFrame(conn)
+					if err != nil {
+						return
+					}
+					frames <- frame
+				}
+			}()
+		}
+	}()
+	return listener, frames
+}
+
+func TestClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	c := Register("uds_test", path, WithQueueSize(2), WithRetryInterval(time.Millisecond))
+	defer c.Close()
+	client, ok := metrics.GetClient("uds_test")
+	assert.True(t, ok)
+	assert.Equal(t, c, client)
+	assert.True(t, metrics.IsMessageReusable("uds_test"))
+
+	// the agent is down, the logging calls wait once the queue is full
+	ctx := context.Background()
+	metadata := &metrics.Metadata{TableName: "exposure", Token: "token"}
+	group := &protoc_event_server.ExposureGroup{Exposures: []*protoc_event_server.Exposure{{UnitId: "u1"}}}
+	assert.Nil(t, c.LogExposure(ctx, metadata, group))
+	assert.Nil(t, c.SendData(ctx, metadata, [][]string{{"row"}}))
+	assert.Nil(t, c.LogEvent(ctx, metadata, &protoc_event_server.EventGroup{})) // the one being written
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.NotNil(t, c.LogMonitorEvent(timeoutCtx, metadata, &protoc_event_server.MonitorEventGroup{}))
+	assert.NotNil(t, c.Flush(timeoutCtx))
+
+	listener, frames := agent(t, path)
+	defer listener.Close()
+	flushCtx, cancelFlush := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelFlush()
+	assert.Nil(t, c.Flush(flushCtx))
+	assert.Equal(t, 0, c.Len())
+
+	// written in order
+	frame := <-frames
+	assert.Equal(t, KindExposure, frame.Kind)
+	assert.Equal(t, "exposure", frame.Metadata.TableName)
+	assert.Equal(t, "token", frame.Metadata.Token)
+	decoded := &protoc_event_server.ExposureGroup{}
+	assert.Nil(t, proto.Unmarshal(frame.Payload, decoded))
+	assert.Equal(t, "u1", decoded.Exposures[0].UnitId)
+	frame = <-frames
+	assert.Equal(t, KindData, frame.Kind)
+	var rows [][]string
+	assert.Nil(t, json.Unmarshal(frame.Payload, &rows))
+	assert.Equal(t, [][]string{{"row"}}, rows)
+	assert.Equal(t, KindEvent, (<-frames).Kind)
+
+	assert.Nil(t, c.Close())
+	assert.Nil(t, c.Close())
+	assert.Equal(t, ErrClosed, c.LogEvent(ctx, metadata, &protoc_event_server.EventGroup{}))
+}
+
+func TestReadFrame(t *testing.T) {
+	frame, err := encodeFrame(KindMonitorEvent, nil, []byte("payload"))
+	assert.Nil(t, err)
+	reader, writer := net.Pipe()
+	go func() {
+		_, _ = writer.Write(frame)
+		_, _ = writer.Write(frame[:len(frame)-1]) // interrupted
+		writer.Close()
+	}()
+	decoded, err := ReadFrame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, KindMonitorEvent, decoded.Kind)
+	assert.Equal(t, []byte("payload"), decoded.Payload)
+	_, err = ReadFrame(reader)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = ReadFrame(reader)
+	assert.Equal(t, io.EOF, err)
+
+	frame[4] = FrameVersion + 1
+	reader, writer = net.Pipe()
+	go func() {
+		_, _ = writer.Write(frame)
+		writer.Close()
+	}()
+	_, err = ReadFrame(reader)
+	assert.EqualError(t, err, "unsupported frame version 2")
+}
@@ -0,0 +1,46 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/pkg/errors"
+)
+
+// PrimeAssignments Warm the caches of the evaluations of a batch of known units of the projectID, such as the
+// recipients before a push-notification blast, so that their evaluations do not stampede the dmp with cold lookups.
+// The dmp tag results of the units are fetched in batches for all the dmp tags of the configuration and kept for
+// WithPrimeTTL, the local evaluation itself needs no warming. The empty unitIDs are skipped, the batches failing are
+// skipped and the first error is returned, the units of which are evaluated as usual
+func PrimeAssignments(ctx context.Context, projectID string, unitIDs []string) error {
+	if internal.C.IsDisableDMP {
+		return nil
+	}
+	var valid = make([]string, 0, len(unitIDs))
+	var seen = make(map[string]bool, len(unitIDs))
+	for _, unitID := range unitIDs {
+		if len(unitID) == 0 || seen[unitID] {
+			continue
+		}
+		seen[unitID] = true
+		valid = append(valid, unitID)
+	}
+	return errors.Wrap(experiment.PrimeDMP(ctx, projectID, valid), "prime")
+}
+
+// WithPrimeTTL set the time the dmp tag results primed by PrimeAssignments are kept, after which the evaluations
+// call the dmp again, zero uses the default 10 minutes
+func WithPrimeTTL(ttl time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if ttl < 0 {
+			return errors.Errorf("invalid ttl:%v", ttl)
+		}
+		config.PrimeTTL = ttl
+		return nil
+	}
+}
@@ -0,0 +1,27 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrimeAssignments(t *testing.T) {
+	Release()
+	defer Release()
+	assert.NotNil(t, WithPrimeTTL(-time.Second)(&internal.GlobalConfig{}))
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithPrimeTTL(time.Minute))
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(PrimeAssignments(context.Background(), "unknown", []string{"12345"}), ErrProjectUnknown))
+	assert.Nil(t, PrimeAssignments(context.Background(), projectID, []string{"12345", "", "12345", "54321"}))
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, list.Data)
+}
@@ -0,0 +1,42 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// WithUnitIDHashSalt Turn on the exposure privacy mode of the projectID, the unitIDs of the exposures, including
+// the decisionID and the new_id, are hashed by HMAC-SHA256 with the salt before reporting, so that the analytics
+// warehouse never receives the raw identifiers. The same unitID always has the same hash under the same salt,
+// changing the salt breaks the join with the exposures before. The evaluation is not affected
+func WithUnitIDHashSalt(projectID string, salt string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if len(projectID) == 0 || len(salt) == 0 {
+			return errors.Errorf("projectID and salt are required")
+		}
+		if config.UnitIDHashSalts == nil {
+			config.UnitIDHashSalts = make(map[string]string)
+		}
+		config.UnitIDHashSalts[projectID] = salt
+		return nil
+	}
+}
+
+// hashUnitID The unitID reported in the exposures of the projectID, hashed with the salt of the projectID if in
+// the privacy mode, see WithUnitIDHashSalt
+func hashUnitID(projectID string, unitID string) string {
+	salt, ok := internal.C.UnitIDHashSalts[projectID]
+	if !ok || len(unitID) == 0 {
+		return unitID
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	_, _ = mac.Write([]byte(unitID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
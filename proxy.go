@@ -0,0 +1,99 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// ProxyDirect The proxy of connecting directly, ignoring the proxy environment variables
+const ProxyDirect = internal.ProxyDirect
+
+// WithConfigProxy set the proxy of the control-plane requests, such as fetching the configuration and the dmp.
+// The proxy is an http, https or socks5 url, or ProxyDirect. It takes precedence over the environment variable
+// ABC_CONFIG_PROXY, which takes precedence over env.CacheServerSocket5Addr and env.DMPServerSocket5Addr. If none is
+// set, the standard HTTPS_PROXY, HTTP_PROXY and NO_PROXY are honored
+func WithConfigProxy(proxy string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if _, err := internal.ParseProxy(proxy); err != nil {
+			return err
+		}
+		config.ConfigProxy = proxy
+		return nil
+	}
+}
+
+// WithEventProxy set the proxy of the event reporting requests sent through EventTransport, independently of
+// WithConfigProxy. It takes precedence over the environment variable ABC_EVENT_PROXY, if neither is set, the standard
+// proxy environment variables are honored
+func WithEventProxy(proxy string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if _, err := internal.ParseProxy(proxy); err != nil {
+			return err
+		}
+		config.EventProxy = proxy
+		return nil
+	}
+}
+
+var (
+	eventTransportOnce sync.Once
+	eventTransport     *http.Transport
+)
+
+// EventTransport The transport of the event reporting requests, which sends through the proxy of WithEventProxy,
+// dials through DialEgress and fails over among WithEventEndpoints. Pass it to the metrics plugins sending the events
+// over http, such as analytics.WithGA4HTTPClient(&http.Client{Transport: abc.EventTransport()}). The proxy, the egress
+// control and the endpoints are looked up on each request, so it can be created before Init
+func EventTransport() http.RoundTripper {
+	return eventRoundTripper
+}
+
+// baseEventTransport The transport of the event reporting requests without the failover of the event endpoints
+func baseEventTransport() *http.Transport {
+	eventTransportOnce.Do(func() {
+		eventTransport = http.DefaultTransport.(*http.Transport).Clone()
+		eventTransport.Proxy = func(req *http.Request) (*url.URL, error) {
+			proxy, err := internal.EventProxy(internal.C)
+			if err != nil {
+				return nil, err
+			}
+			return proxy(req)
+		}
+		eventTransport.DialContext = DialEgress
+	})
+	return eventTransport
+}
+
+// eventRoundTripper Route the requests through the event endpoints of the current configuration, if any
+var eventRoundTripper = transportFunc(func(req *http.Request) (*http.Response, error) {
+	if group := internal.C.EventEndpoints; group != nil {
+		return group.HostRoundTripper(baseEventTransport()).RoundTrip(req)
+	}
+	return baseEventTransport().RoundTrip(req)
+})
+
+// transportFunc Adapt the function to http.RoundTripper
+type transportFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip Implement http.RoundTripper
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// validateProxies Validate the proxies of the environment variables, which are not validated by the options
+func validateProxies(config *internal.GlobalConfig) error {
+	if _, err := internal.ConfigProxy(config); err != nil {
+		return errors.Wrap(err, "configProxy")
+	}
+	if _, err := internal.EventProxy(config); err != nil {
+		return errors.Wrap(err, "eventProxy")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package abc ...
+package abc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventTransport(t *testing.T) {
+	defer func(c *internal.GlobalConfig) {
+		internal.C = c
+	}(internal.C)
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		proxied = append(proxied, request.URL.String())
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+	config := &internal.GlobalConfig{}
+	assert.Nil(t, WithEventProxy(proxy.URL)(config))
+	assert.NotNil(t, WithEventProxy("invalid")(config))
+	assert.NotNil(t, WithConfigProxy("ftp://proxy")(config))
+	internal.C = config
+
+	client := &http.Client{Transport: EventTransport()}
+	resp, err := client.Get("http://events.invalid/collect")
+	if assert.Nil(t, err) {
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+	assert.Equal(t, []string{"http://events.invalid/collect"}, proxied)
+
+	t.Setenv(internal.EnvConfigProxy, "invalid")
+	assert.NotNil(t, validateProxies(config))
+}
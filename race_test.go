@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package abc
+
+// raceEnabled The race detector drops the items put into the sync.Pool randomly, so the allocations are not guarded
+const raceEnabled = true
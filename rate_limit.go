@@ -0,0 +1,70 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// WithEvaluationQPS set the maximum evaluations per second of the projectID, such as GetExperiments and
+// GetRemoteConfig, the evaluations above it fail with ErrRateLimited. Each projectID is limited on its own budget,
+// there is no budget shared between the projectIDs, so that a tenant of a multi-tenant gateway is only capped by the
+// limit set for it. Bursts of one second are allowed, zero is unlimited, default unlimited
+func WithEvaluationQPS(qps float64) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		if qps < 0 {
+			return errors.Errorf("evaluation qps should not be negative")
+		}
+		options.EvaluationQPS = qps
+		return nil
+	}
+}
+
+// WithMaxConcurrentEvaluations set the maximum number of the evaluations of the projectID in progress at the same
+// time, the evaluations above it fail with ErrRateLimited instead of waiting, see WithEvaluationQPS.
+// Zero is unlimited, default unlimited
+func WithMaxConcurrentEvaluations(limit int) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		if limit < 0 {
+			return errors.Errorf("concurrent evaluations should not be negative")
+		}
+		options.MaxConcurrentEvaluations = limit
+		return nil
+	}
+}
+
+// WithExposureQPS set the maximum exposures per second of the projectID, both automatic and manual. The exposures
+// above it are discarded and counted into the rate_limit loss, the Log* APIs return ErrRateLimited, so that the
+// exposures of a tenant taking the reporting queues are capped by the limit set for it. Zero is unlimited,
+// default unlimited
+func WithExposureQPS(qps float64) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		if qps < 0 {
+			return errors.Errorf("exposure qps should not be negative")
+		}
+		options.ExposureQPS = qps
+		return nil
+	}
+}
+
+// acquireEvaluation Check the evaluation against the limits of the projectID, the rejections are passed to
+// WithOnWarning. If acquired, internal.ReleaseEvaluation must be called when the evaluation finishes
+func acquireEvaluation(projectID string) (bool, error) {
+	acquired, err := internal.AcquireEvaluation(projectID)
+	if err != nil {
+		internal.ReportWarning(internal.ReasonRateLimit, projectID, err)
+	}
+	return acquired, err
+}
+
+// checkExposureRate ErrRateLimited if the count exposures exceed the exposure limit of the projectID, the discarded
+// exposures are counted into the loss
+func checkExposureRate(projectID string, count int) error {
+	if internal.AllowExposures(projectID, count) {
+		return nil
+	}
+	internal.RecordLoss(projectID, internal.LossReasonRateLimit, count)
+	return errors.Wrapf(ErrRateLimited, "[projectID=%s]exposure rate limit exceeded", projectID)
+}
@@ -0,0 +1,48 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	Release()
+	defer Release()
+	var reasons []ErrorReason
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient),
+		WithOnWarning(func(reason ErrorReason, projectID string, err error) {
+			reasons = append(reasons, reason)
+		}))
+	assert.Nil(t, err)
+	assert.NotNil(t, WithEvaluationQPS(-1)(&internal.ProjectOptions{}))
+	assert.NotNil(t, WithMaxConcurrentEvaluations(-1)(&internal.ProjectOptions{}))
+	assert.NotNil(t, WithExposureQPS(-1)(&internal.ProjectOptions{}))
+	assert.Nil(t, UpdateOptions(projectID, WithEvaluationQPS(1)))
+	userCtx := NewUserContext("12345")
+	list, err := userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, list.Data)
+	_, err = userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	_, err = userCtx.GetRemoteConfig(context.Background(), projectID, "test")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, []ErrorReason{ReasonRateLimit, ReasonRateLimit}, reasons)
+
+	// set after the evaluations, so that the bucket is full whether the build reports exposures or not
+	assert.Nil(t, UpdateOptions(projectID, WithExposureQPS(1)))
+	assert.Nil(t, checkExposureRate(projectID, 1))
+	assert.True(t, errors.Is(checkExposureRate(projectID, 1), ErrRateLimited))
+	assert.Equal(t, uint64(1), GetLossCounts(projectID)[LossReasonRateLimit])
+	assert.Nil(t, checkExposureRate("456", 1)) // the other projectIDs are not affected
+
+	assert.Nil(t, UpdateOptions(projectID, WithEvaluationQPS(0)))
+	_, err = userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+}
@@ -0,0 +1,61 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"net/url"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+// WithRelay set the relay proxy of the configuration, such as https://abc-relay.internal:8080, serving the cached
+// configuration of the cache service for many SDK instances. Once the requests to the cache service fail
+// failureThreshold times in a row, such as when the control plane is down, the failing requests are read through
+// to the relay, each request is still sent to the cache service first, so that it takes over again as soon as it
+// recovers. Each fallback is passed to WithOnWarning with ReasonRelayFallback. A threshold of 1 also serves Init
+// from the relay if the cache service is down at startup. The relay speaks the protocol of the cache service and
+// receives the same credentials, the proxy, the egress control and the transport middlewares apply to it as well
+func WithRelay(addr string, failureThreshold int) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		relayURL, err := url.Parse(addr)
+		if err != nil || len(relayURL.Host) == 0 || (relayURL.Scheme != "http" && relayURL.Scheme != "https") {
+			return errors.Errorf("invalid relay addr:%s", addr)
+		}
+		if failureThreshold < 1 {
+			return errors.Errorf("invalid failureThreshold:%d", failureThreshold)
+		}
+		config.RelayAddr = addr
+		config.RelayFailureThreshold = failureThreshold
+		return nil
+	}
+}
+
+// registerRelayClient Wrap the registered cache client with the relay of WithRelay
+func registerRelayClient(c *internal.GlobalConfig) {
+	if len(c.RelayAddr) == 0 {
+		return
+	}
+	opts := []client.Option{client.WithAddr(c.RelayAddr)}
+	if c.HTTPClient != nil {
+		opts = append(opts, client.WithHTTPClient(c.HTTPClient))
+	}
+	if proxy, _ := internal.ConfigProxy(c); proxy != nil {
+		opts = append(opts, client.WithProxy(proxy))
+	}
+	if !c.Egress.IsEmpty() {
+		opts = append(opts, client.WithDialer(c.Egress.DialContext))
+	}
+	// without the routing of the config endpoints, which sends to the cache service
+	opts = append(opts, client.WithMiddleware(transportMiddlewares(c)...))
+	relay := client.NewTABCacheClient(opts...)
+	client.RegisterCacheClient(client.NewRelayClient(client.GetCacheClient(), relay, c.RelayFailureThreshold,
+		func(err error) {
+			log.Warnf("cache service failed %d times, fall back to the relay %s:%v", c.RelayFailureThreshold,
+				c.RelayAddr, err)
+			internal.ReportWarning(internal.ReasonRelayFallback, "", errors.Wrap(err, "fall back to the relay"))
+		}))
+}
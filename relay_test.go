@@ -0,0 +1,63 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelay(t *testing.T) {
+	Release()
+	defer Release()
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithRelay("relay:8080", 1)))
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithRelay("http://relay:8080", 0)))
+
+	cached := testdata.MockCacheClient(t) // the configuration cached by the relay
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var resp proto.Message
+		var err error
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/GetTabConfig"):
+			req := &protoccacheserver.GetTabConfigReq{}
+			_ = proto.Unmarshal(body, req)
+			resp, err = cached.GetTabConfigData(r.Context(), &protoccacheserver.GetTabConfigReq{ // comparable
+				ProjectId: req.ProjectId, UpdateType: req.UpdateType, SdkVersion: req.SdkVersion})
+		case strings.HasSuffix(r.URL.Path, "/BatchGetExperimentBucket"):
+			resp, err = cached.BatchGetExperimentBucketInfo(r.Context(), nil)
+		default:
+			resp, err = cached.BatchGetGroupBucketInfo(r.Context(), nil)
+		}
+		assert.Nil(t, err)
+		data, _ := proto.Marshal(resp)
+		_, _ = w.Write(data)
+	}))
+	defer relay.Close()
+	down := client.NewMockClient(gomock.NewController(t))
+	down.EXPECT().GetTabConfigData(gomock.Any(), gomock.Any()).Return(nil, errors.New("down")).AnyTimes()
+	down.EXPECT().BatchGetExperimentBucketInfo(gomock.Any(), gomock.Any()).Return(nil, errors.New("down")).AnyTimes()
+	down.EXPECT().BatchGetGroupBucketInfo(gomock.Any(), gomock.Any()).Return(nil, errors.New("down")).AnyTimes()
+	var reasons []ErrorReason
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(down),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithRelay(relay.URL+"/", 1),
+		WithOnWarning(func(reason ErrorReason, projectID string, err error) {
+			reasons = append(reasons, reason)
+		}))
+	assert.Nil(t, err) // served by the relay
+	assert.Equal(t, []ErrorReason{ReasonRelayFallback}, reasons)
+	list, err := NewUserContext("12345").GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, list.Data)
+}
@@ -9,13 +9,13 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/abetterchoice/go-sdk/env"
 	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
 	"github.com/abetterchoice/go-sdk/internal/config"
 	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/abetterchoice/go-sdk/internal/tracing"
 	"github.com/abetterchoice/go-sdk/plugin/log"
 	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
-	"github.com/abetterchoice/protoc_event_server"
 	"github.com/pkg/errors"
 )
 
@@ -23,39 +23,85 @@ import (
 // GetConfig gets the configuration hit by the user, specifies the projectID and configuration key
 func (c *userContext) GetRemoteConfig(ctx context.Context, projectID string, key string,
 	opts ...ConfigOption) (result *ConfigResult, err error) {
-	options := defaultExperimentOptions // Copy, defaultExperimentOptions remains unchanged as template
+	options := getExperimentOptions()
+	defer putExperimentOptions(options) // after the events below, which read the options
+	ctx, span := tracing.StartWithKey(ctx, "abc.GetRemoteConfig", projectID, tracing.KeyConfigKey, key)
+	defer func() {
+		if span.IsRecording() {
+			var groupID int64
+			if result != nil && result.Experiment != nil {
+				groupID = result.Experiment.ID
+			}
+			span.SetAttributes(tracing.KeyGroupID.Int64(groupID),
+				tracing.KeyConfigLoaded.Bool(cache.GetApplication(projectID) != nil))
+		}
+		tracing.End(span, err)
+	}()
 	defer func(startTime time.Time) {
 		latency := time.Since(startTime)
-		if options.IsExposureLoggingAutomatic && !internal.C.IsDisableReport {
-			exposureErr := asyncExposureRemoteConfig(projectID, result, protoc_event_server.ExposureType_EXPOSURE_TYPE_AUTOMATIC)
+		if options.Timing != nil && latency >= internal.C.SlowOpThreshold {
+			asyncSlowOp(newEvaluationSlowOp(projectID, "GetRemoteConfig", latency, options.Timing))
+		}
+		contextData := internal.ContextData(ctx)
+		if options.IsExposureLoggingAutomatic && !internal.IsReportDisabled(projectID) {
+			exposureErr := asyncExposureRemoteConfig(projectID, withConfigContextData(result, contextData),
+				automaticExposure)
 			if exposureErr != nil {
-				log.Errorf("[projectID=%v]asyncExposureRemoteConfig fail:%v", projectID, exposureErr)
+				log.LimitedError(ctx, "asyncExposureRemoteConfig"+projectID, "queue exposure fail",
+					log.Any("projectID", projectID), log.Any("configKey", key), log.Err(exposureErr))
 			}
 		}
-		exposureErr := asyncExposureRemoteConfigEvent(projectID, result, latency, env.JSONString(&options), err)
+		exposureErr := asyncExposureRemoteConfigEvent(projectID, withConfigContextData(result, contextData), latency,
+			options, err)
 		if exposureErr != nil {
-			log.Errorf("[projectID=%v]exposureRemoteConfigEvent fail:%v", projectID, exposureErr)
+			log.LimitedError(ctx, "exposureRemoteConfigEvent"+projectID, "queue evaluation event fail",
+				log.Any("projectID", projectID), log.Any("configKey", key), log.Err(exposureErr))
 		}
 	}(time.Now())
+	defer recoverEvaluation(projectID, "GetRemoteConfig", &err)
 	if c.err != nil {
 		return nil, c.err
 	}
-	c.fillOption(&options)
+	if err := checkConfigAge(projectID); err != nil {
+		return nil, err
+	}
+	acquired, err := acquireEvaluation(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if acquired {
+		defer internal.ReleaseEvaluation(projectID)
+	}
+	internal.RecordUsage(projectID, key, time.Now())
+	c.fillOption(options)
+	setEvaluationDeadline(options)
+	if internal.C.SlowOpThreshold > 0 {
+		options.Timing = &experiment.Timing{}
+	}
 	for _, opt := range opts {
-		err := opt(&options)
+		err := opt(options)
 		if err != nil {
 			return nil, errors.Wrap(err, "opt")
 		}
 	}
-	configValue, err := config.Executor.GetRemoteConfig(ctx, projectID, key, &options)
+	configValue, err := config.Executor.GetRemoteConfig(ctx, projectID, key, options)
+	if len(options.Panics) > 0 {
+		reportPanics(projectID, "GetRemoteConfig", options.Panics)
+	}
+	reportRuleTimeouts(projectID, "GetRemoteConfig", options.TimedOut)
 	if err != nil {
 		return nil, err
 	}
+	data, pointer, err := resolveBlob(ctx, configValue.Data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config [%s]", key)
+	}
 	return &ConfigResult{
 		userCtx: c,
 		Config: &Config{
 			Key:            key,
-			Value:          &Value{data: configValue.Data},
+			Value:          &Value{data: data},
+			blobPointer:    pointer,
 			IsOverrideList: configValue.IsOverrideList,
 			IsDefault:      configValue.IsDefault,
 			Experiment:     convertGroup2Experiment(configValue.Experiment),
@@ -93,6 +139,8 @@ func WithIsDisableDMPConfigOpt(isDisableDMP bool) ConfigOption {
 type ConfigResult struct {
 	userCtx *userContext `json:"-"`
 	*Config
+	// The values extracted from the ctx the exposures are produced under, see WithContextExtractor
+	contextData map[string]string
 }
 
 // Config configuration information
@@ -117,6 +165,9 @@ type Config struct {
 
 	// Account system
 	unitIDType protoccacheserver.UnitIDType `json:"-"`
+
+	// The original value pointing to the large object of the data, nil if the value is not a blob pointer
+	blobPointer []byte
 }
 
 // Byte gets the specific configuration data. The original data is a snapshot of the local cache.
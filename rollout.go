@@ -0,0 +1,44 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"math"
+
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+)
+
+const (
+	// RolloutBucketSize The buckets of RolloutPercent, the resolution of the percent is 0.01
+	RolloutBucketSize = 10000
+	// RolloutGroupKey The experiment key of the exposures of the units in the rollout, see LogRolloutExposure
+	RolloutGroupKey = "rollout"
+	// HoldbackGroupKey The experiment key of the exposures of the units held back, see LogRolloutExposure
+	HoldbackGroupKey = "holdback"
+)
+
+// rolloutSpec The hashing parameters of all the rollouts, the key salts the unitID so that the rollouts of the
+// different keys are independent
+var rolloutSpec = BucketSpec{HashMethod: protoccacheserver.HashMethod_HASH_METHOD_NEW_MD5,
+	BucketSize: RolloutBucketSize}
+
+// RolloutBucket The bucket of the unitID in the rollout of the key, in [1, RolloutBucketSize]:
+//
+//	bucket = ComputeBucket(unitID, BucketSpec{HASH_METHOD_NEW_MD5, 0, RolloutBucketSize}, key + ":")
+func RolloutBucket(key string, unitID string) int64 {
+	bucket, _ := ComputeBucket(unitID, rolloutSpec, key+":")
+	return bucket
+}
+
+// RolloutPercent Whether the unitID is in the first percent of the rollout of the key, for the progressive delivery
+// gated by the code rather than by an experiment or a feature flag of the platform. It needs neither Init nor the
+// local cache, and nothing is reported, see LogRolloutExposure. The percent is in [0, 100] with the resolution of
+// 0.01, the units in the rollout stay in it as the percent grows, and the same unitID gets the same result in all
+// the SDK languages
+func RolloutPercent(key string, unitID string, percent float64) bool {
+	if len(unitID) == 0 || !(percent > 0) { // NaN included
+		return false
+	}
+	return RolloutBucket(key, unitID) <= int64(math.Round(math.Min(percent, 100)*RolloutBucketSize/100))
+}
@@ -0,0 +1,44 @@
+// Package abc ...
+package abc
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloutPercent(t *testing.T) {
+	previous := map[string]bool{}
+	for _, percent := range []float64{0, 1, 10, 50, 100} {
+		hits := 0
+		for i := 0; i < 10000; i++ {
+			unitID := strconv.Itoa(i)
+			hit := RolloutPercent("new_checkout", unitID, percent)
+			if previous[unitID] {
+				assert.True(t, hit, "%s left the rollout at %v", unitID, percent) // stays as the percent grows
+			}
+			if hit {
+				hits++
+			}
+			previous[unitID] = hit
+		}
+		assert.InDelta(t, percent*100, hits, 200, "percent %v", percent)
+	}
+	assert.False(t, RolloutPercent("new_checkout", "", 100))
+	assert.False(t, RolloutPercent("new_checkout", "12345", math.NaN()))
+	assert.True(t, RolloutPercent("new_checkout", "12345", 1000))
+
+	// the keys are independent
+	same := 0
+	for i := 0; i < 10000; i++ {
+		if RolloutPercent("a", strconv.Itoa(i), 50) == RolloutPercent("b", strconv.Itoa(i), 50) {
+			same++
+		}
+	}
+	assert.InDelta(t, 5000, same, 300)
+	bucket := RolloutBucket("new_checkout", "12345")
+	assert.True(t, bucket >= 1 && bucket <= RolloutBucketSize)
+	assert.Equal(t, bucket <= 2550, RolloutPercent("new_checkout", "12345", 25.5))
+}
@@ -0,0 +1,173 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval,
+// user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// ProjectOptions Runtime options of a projectID, see UpdateOptions
+type ProjectOptions = internal.ProjectOptions
+
+// RuntimeOption Runtime option, modify the reporting and refresh parameters of a projectID without reinitializing
+type RuntimeOption func(options *internal.ProjectOptions) error
+
+// minRefreshInterval Avoid putting too much pressure on the backend cache service
+const minRefreshInterval = time.Second
+
+// UpdateOptions Modify the runtime options of the projectID, such as driven by the ops console, concurrent and safe.
+// The options are applied on the basis of the current runtime options, and take effect on the next reporting
+// or refresh. Setting an option to zero restores the remote configuration. If any option is invalid,
+// no option takes effect. The runtime options are cleared by Release
+func UpdateOptions(projectID string, opts ...RuntimeOption) error {
+	if len(projectID) == 0 {
+		return errors.Errorf("projectID is required")
+	}
+	before, options, err := internal.UpdateProjectOptions(projectID, func(options *internal.ProjectOptions) error {
+		for _, opt := range opts {
+			if err := opt(options); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		before = &internal.ProjectOptions{}
+	}
+	log.Infof("[projectID=%v]options updated:%+v", projectID, *options)
+	internal.RecordAudit(1, internal.AuditActionUpdateOptions, projectID, env.JSONString(before),
+		env.JSONString(options))
+	if before.IsDisableReport != options.IsDisableReport {
+		internal.RecordAudit(1, internal.AuditActionKillSwitch, projectID,
+			strconv.FormatBool(before.IsDisableReport), strconv.FormatBool(options.IsDisableReport))
+	}
+	return nil
+}
+
+// GetOptions Get the runtime options of the projectID, modifying the returned value has no effect
+func GetOptions(projectID string) ProjectOptions {
+	options := internal.GetProjectOptions(projectID)
+	if options == nil {
+		return ProjectOptions{}
+	}
+	return *options.Copy()
+}
+
+// WithExposureSamplingInterval set the exposure sampling interval, report one of every interval exposures
+func WithExposureSamplingInterval(interval uint32) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		options.ExposureSamplingInterval = interval
+		return nil
+	}
+}
+
+// WithEventSamplingInterval set the sampling interval of monitor events, error events are not affected
+func WithEventSamplingInterval(interval uint32) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		options.EventSamplingInterval = interval
+		return nil
+	}
+}
+
+// WithRefreshInterval set the local cache refresh interval, which is at least 1 second
+func WithRefreshInterval(interval time.Duration) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		if interval != 0 && interval < minRefreshInterval {
+			return errors.Errorf("refresh interval should be at least %v", minRefreshInterval)
+		}
+		options.RefreshInterval = interval
+		return nil
+	}
+}
+
+// WithRefreshTimeout set the timeout of each local cache refresh
+func WithRefreshTimeout(timeout time.Duration) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		if timeout < 0 {
+			return errors.Errorf("refresh timeout should not be negative")
+		}
+		options.RefreshTimeout = timeout
+		return nil
+	}
+}
+
+// WithQueueSize set the maximum number of pending items of the asynchronous reporting queue,
+// the exposure of the projectID is discarded when exceeded. It can only shrink the queue,
+// the queue capacity is fixed at startup, see ExperimentExposureChanSize
+func WithQueueSize(size int) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		if size < 0 {
+			return errors.Errorf("queue size should not be negative")
+		}
+		options.QueueSize = size
+		return nil
+	}
+}
+
+// WithDeliveryGuarantee set how hard the exposure reporting of the projectID tries before giving up, such as
+// DeliveryAtLeastOnce for the experiments whose analysis cannot tolerate the loss. Empty restores DeliveryBestEffort
+func WithDeliveryGuarantee(guarantee DeliveryGuarantee) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		switch guarantee {
+		case "", DeliveryBestEffort, DeliveryAtLeastOnce, DeliveryBlocking:
+			options.DeliveryGuarantee = guarantee
+			return nil
+		}
+		return errors.Errorf("invalid delivery guarantee [%s]", guarantee)
+	}
+}
+
+// WithReportDisabled Kill switch of the exposure reporting of the projectID, the evaluation is not affected.
+// Each flip is recorded in the audit log, see AuditLog
+func WithReportDisabled(disable bool) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		options.IsDisableReport = disable
+		return nil
+	}
+}
+
+// WithSceneAutomatic set whether the experiments of the scenes log the exposures automatically, key is sceneID,
+// such as the manual exposure of the scenes rendered lazily. It overrides the default automatic exposure,
+// WithAutomatic of the evaluation still takes precedence. nil restores the default of all scenes
+func WithSceneAutomatic(sceneAutomatic map[int64]bool) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		options.SceneAutomatic = sceneAutomatic
+		return nil
+	}
+}
+
+// WithExperimentTagIndex set the tags of the experiments, key is experimentKey, such as "checkout" or "mobile",
+// for the experiment tag filter of the evaluation, see WithExperimentTags. nil clears the tags
+func WithExperimentTagIndex(experimentTags map[string][]string) RuntimeOption {
+	return func(options *internal.ProjectOptions) error {
+		options.ExperimentTags = experimentTags
+		return nil
+	}
+}
+
+// isQueueFull Whether the pending items of the queue exceed the runtime queue size of the projectID
+func isQueueFull(projectID string, pending int) bool {
+	queueSize := internal.QueueSize(projectID)
+	return queueSize > 0 && pending >= queueSize
+}
+
+// eventSamplingInterval Select the monitor event sampling interval based on error,
+// the runtime option of the projectID only replaces the sampling interval of the normal event
+func eventSamplingInterval(projectID string, metricsConfig *protoc_cache_server.MetricsConfig, err error) uint32 {
+	if err != nil {
+		return metricsConfig.ErrSamplingInterval
+	}
+	return internal.EventSamplingInterval(projectID, metricsConfig.SamplingInterval)
+}
@@ -0,0 +1,131 @@
+// Package abc ...
+package abc
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateOptions(t *testing.T) {
+	defer Release()
+	type args struct {
+		projectID string
+		opts      []RuntimeOption
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    ProjectOptions
+		wantErr bool
+	}{
+		{
+			name:    "projectID is required",
+			args:    args{projectID: ""},
+			wantErr: true,
+		},
+		{
+			name: "normal",
+			args: args{projectID: projectID, opts: []RuntimeOption{WithExposureSamplingInterval(10),
+				WithRefreshInterval(5 * time.Second), WithQueueSize(100)}},
+			want: ProjectOptions{ExposureSamplingInterval: 10, RefreshInterval: 5 * time.Second, QueueSize: 100},
+		},
+		{
+			name: "merge with current",
+			args: args{projectID: projectID, opts: []RuntimeOption{WithEventSamplingInterval(2),
+				WithRefreshTimeout(time.Second), WithQueueSize(0)}},
+			want: ProjectOptions{ExposureSamplingInterval: 10, EventSamplingInterval: 2,
+				RefreshInterval: 5 * time.Second, RefreshTimeout: time.Second},
+		},
+		{
+			name: "invalid refresh interval",
+			args: args{projectID: projectID, opts: []RuntimeOption{WithExposureSamplingInterval(1), WithRefreshInterval(time.Millisecond)}},
+			want: ProjectOptions{ExposureSamplingInterval: 10, EventSamplingInterval: 2,
+				RefreshInterval: 5 * time.Second, RefreshTimeout: time.Second},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UpdateOptions(tt.args.projectID, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, GetOptions(tt.args.projectID))
+		})
+	}
+}
+
+func TestUpdateOptionsAudit(t *testing.T) {
+	defer Release()
+	internal.ResetAuditLog()
+	defer internal.ResetAuditLog()
+	assert.Nil(t, UpdateOptions(projectID, WithReportDisabled(true)))
+	assert.True(t, internal.IsReportDisabled(projectID))
+	assert.Nil(t, UpdateOptions(projectID, WithQueueSize(10)))
+	assert.Nil(t, SetCredentials(projectID, &Credentials{SecretKey: testSecretKey}))
+	var actions []AuditAction
+	for _, record := range AuditLog() {
+		actions = append(actions, record.Action)
+		assert.Equal(t, projectID, record.ProjectID)
+		assert.True(t, strings.Contains(record.Caller, "/runtime_options_test.go:"), record.Caller)
+		assert.NotContains(t, record.After, testSecretKey)
+	}
+	assert.Equal(t, []AuditAction{AuditActionUpdateOptions, AuditActionKillSwitch, AuditActionUpdateOptions,
+		AuditActionRotateCredentials}, actions)
+	assert.Equal(t, "secretKey", AuditLog()[3].After)
+}
+
+func TestWithSceneAutomatic(t *testing.T) {
+	defer Release()
+	sceneAutomatic := map[int64]bool{1: false}
+	assert.Nil(t, UpdateOptions(projectID, WithSceneAutomatic(sceneAutomatic)))
+	sceneAutomatic[2] = false // copied
+	options := GetOptions(projectID)
+	assert.Equal(t, map[int64]bool{1: false}, options.SceneAutomatic)
+	options.SceneAutomatic[3] = false
+	assert.Nil(t, UpdateOptions(projectID, WithQueueSize(10)))
+	assert.Equal(t, map[int64]bool{1: false}, GetOptions(projectID).SceneAutomatic)
+}
+
+func TestIsQueueFull(t *testing.T) {
+	defer Release()
+	assert.False(t, isQueueFull(projectID, 1<<10))
+	assert.Nil(t, UpdateOptions(projectID, WithQueueSize(10)))
+	assert.False(t, isQueueFull(projectID, 9))
+	assert.True(t, isQueueFull(projectID, 10))
+	assert.False(t, isQueueFull("other", 10))
+}
+
+func TestEventSamplingInterval(t *testing.T) {
+	defer Release()
+	metricsConfig := &protoc_cache_server.MetricsConfig{SamplingInterval: 100, ErrSamplingInterval: 1}
+	assert.Equal(t, uint32(100), eventSamplingInterval(projectID, metricsConfig, nil))
+	assert.Nil(t, UpdateOptions(projectID, WithEventSamplingInterval(10)))
+	assert.Equal(t, uint32(10), eventSamplingInterval(projectID, metricsConfig, nil))
+	assert.Equal(t, uint32(1), eventSamplingInterval(projectID, metricsConfig, errors.New("mock err")))
+}
+
+func TestUpdateOptionsConcurrent(t *testing.T) {
+	defer Release()
+	increment := func(options *internal.ProjectOptions) error {
+		options.QueueSize++
+		return nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, UpdateOptions(projectID, increment))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, GetOptions(projectID).QueueSize) // no update is lost
+}
@@ -0,0 +1,64 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/pkg/errors"
+)
+
+// SelfTest is an end-to-end smoke test of the initialized SDK against a sandbox projectID, for the deployment
+// pipelines to check a release in place. It checks that the configuration of the projectID is loaded, runs a sample
+// evaluation without exposure, logs a test exposure marked by the abc_validate extended field to the default
+// experiment table of the projectID, or the table of WithSandboxTable, and verifies the exposure is accepted by the
+// plugin. The plugins queueing the exposures are flushed if they implement metrics.Flusher, otherwise the acceptance
+// of LogExposure is verified only. WithValidateInitOptions is ignored.
+// err is only returned when the SDK is not initialized, step failures are recorded in the report.
+func SelfTest(ctx context.Context, projectID string, opts ...ValidateOption) (*SetupReport, error) {
+	if len(internal.C.ProjectIDList) == 0 {
+		return nil, errors.Wrap(ErrNotInitialized, "call Init before SelfTest")
+	}
+	c := &validateConfig{unitID: defaultValidateUnitID}
+	for _, opt := range opts {
+		opt(c)
+	}
+	projectReport := &ProjectSetupReport{ProjectID: projectID}
+	report := &SetupReport{Passed: false, Projects: map[string]*ProjectSetupReport{projectID: projectReport}}
+	var updateLatency time.Duration
+	if application := cache.GetApplication(projectID); application != nil {
+		updateLatency = time.Since(application.UpdateTime) // the age of the configuration
+	}
+	if !validateFetchConfig(projectReport, updateLatency, errors.Errorf("projectID [%s] is not passed in Init",
+		projectID)) {
+		return report, nil
+	}
+	list, ok := validateEvaluate(ctx, projectReport, c.unitID)
+	if !ok {
+		return report, nil
+	}
+	sandboxTable := c.sandboxTable
+	if sandboxTable == nil {
+		sandboxTable = defaultExperimentTable(projectID)
+	}
+	if !validateExposure(ctx, projectReport, list, sandboxTable) {
+		return report, nil
+	}
+	report.Passed = verifyExposure(ctx, projectReport)
+	return report, nil
+}
+
+// defaultExperimentTable The table of the default experiment metrics config of the projectID, the missing config
+// fails the exposure step
+func defaultExperimentTable(projectID string) *exposureTable {
+	table := &exposureTable{}
+	metricsConfig := cache.GetApplication(projectID).TabConfig.ControlData.DefaultExperimentMetricsConfig
+	if metricsConfig != nil && metricsConfig.Metadata != nil {
+		table.name, table.id = metricsConfig.Metadata.Name, metricsConfig.Metadata.Id
+	}
+	return table
+}
@@ -0,0 +1,67 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// flushMetricsClient Record the exposures and fail the flush with err, see metrics.Flusher
+type flushMetricsClient struct {
+	recordMetricsClient
+	err error
+}
+
+func (f *flushMetricsClient) Flush(ctx context.Context) error {
+	return f.err
+}
+
+func TestSelfTest(t *testing.T) {
+	Release()
+	defer Release()
+	ctx := context.Background()
+	_, err := SelfTest(ctx, projectID)
+	assert.NotNil(t, err) // not initialized
+
+	client := &flushMetricsClient{recordMetricsClient: recordMetricsClient{Client: testdata.EmptyMetricsClient}}
+	metrics.RegisterClient(client)
+	defer metrics.RegisterClient(&recordMetricsClient{Client: testdata.EmptyMetricsClient, retained: true})
+	err = Init(ctx, projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+
+	report, err := SelfTest(ctx, projectID, WithValidateUnitID("12345"))
+	assert.Nil(t, err)
+	assert.True(t, report.Passed)
+	var steps []string
+	for _, step := range report.Projects[projectID].Steps {
+		steps = append(steps, step.Name)
+		assert.True(t, step.Passed, step.Message)
+	}
+	assert.Equal(t, []string{SetupStepFetchConfig, SetupStepEvaluate, SetupStepExposure, SetupStepVerify}, steps)
+	assert.NotEmpty(t, client.exposures)
+	for _, exposure := range client.exposures {
+		assert.Equal(t, "1", exposure.ExtraData[validateExposureKey])
+	}
+
+	client.err = errors.New("sandbox rejected")
+	report, err = SelfTest(ctx, projectID, WithValidateUnitID("12345"))
+	assert.Nil(t, err)
+	assert.False(t, report.Passed)
+	verify := report.Projects[projectID].Steps[3]
+	assert.False(t, verify.Passed)
+	assert.Equal(t, "sandbox rejected", verify.Message)
+
+	report, err = SelfTest(ctx, "notInit")
+	assert.Nil(t, err)
+	assert.False(t, report.Passed)
+	assert.Len(t, report.Projects["notInit"].Steps, 1)
+}
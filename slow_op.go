@@ -0,0 +1,39 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+)
+
+// Breakdown keys of the slow_op event
+const (
+	slowOpCacheLookup = "cache_lookup"
+	slowOpRuleEval    = "rule_eval"
+	slowOpHashing     = "hashing"
+	slowOpPluginSend  = "plugin_send"
+)
+
+// slowOp An evaluation or an exposure flush exceeding the slow operation threshold
+type slowOp struct {
+	projectID string
+	op        string
+	latency   time.Duration
+	breakdown map[string]time.Duration
+}
+
+func newEvaluationSlowOp(projectID string, op string, latency time.Duration, timing *experiment.Timing) *slowOp {
+	return &slowOp{
+		projectID: projectID,
+		op:        op,
+		latency:   latency,
+		breakdown: map[string]time.Duration{
+			slowOpCacheLookup: timing.CacheLookup,
+			slowOpRuleEval:    timing.RuleEval,
+			slowOpHashing:     timing.Hashing,
+		},
+	}
+}
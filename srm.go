@@ -0,0 +1,181 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"math"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultSRMThreshold The default p-value below which the realized group counts mismatch the weights
+	defaultSRMThreshold = 0.001
+	// srmMinExpected The minimum expected count of each group for the chi-square approximation to hold
+	srmMinExpected = 5
+)
+
+// WithSRMDetector enable the local sample ratio mismatch detection. The groups assigned by the evaluations of the
+// process are counted per experiment over the sliding window, and a srm_alert monitor event is emitted when the
+// p-value of the chi-square test of the counts against the traffic weights of the groups is below the threshold,
+// such as 0.001, zero uses the default 0.001. The alert of an experiment is emitted at most once per window.
+// Zero window disables the detection, default disabled.
+func WithSRMDetector(window time.Duration, threshold float64) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if window < 0 || threshold < 0 || threshold >= 1 {
+			return errors.Errorf("invalid srm window %v or threshold %v", window, threshold)
+		}
+		if threshold == 0 {
+			threshold = defaultSRMThreshold
+		}
+		config.SRMWindow = window
+		config.SRMThreshold = threshold
+		return nil
+	}
+}
+
+// srmResult The chi-square test of the realized group counts of an experiment
+type srmResult struct {
+	chiSquare float64
+	pValue    float64
+	// The expected counts of the groups by their weights, key is groupID
+	expected map[int64]float64
+}
+
+// srmTest Test the counts against the weights, both keyed by groupID, false if the test does not apply, such as a
+// single group or an expected count too small for the approximation
+func srmTest(counts map[int64]uint64, weights map[int64]float64) (*srmResult, bool) {
+	if len(weights) < 2 {
+		return nil, false
+	}
+	var total uint64
+	var totalWeight float64
+	for groupID, weight := range weights {
+		total += counts[groupID]
+		totalWeight += weight
+	}
+	if total == 0 || totalWeight <= 0 {
+		return nil, false
+	}
+	result := &srmResult{expected: make(map[int64]float64, len(weights))}
+	for groupID, weight := range weights {
+		expected := float64(total) * weight / totalWeight
+		if expected < srmMinExpected {
+			return nil, false
+		}
+		diff := float64(counts[groupID]) - expected
+		result.chiSquare += diff * diff / expected
+		result.expected[groupID] = expected
+	}
+	result.pValue = chiSquarePValue(result.chiSquare, len(weights)-1)
+	return result, true
+}
+
+// chiSquarePValue The upper tail probability of the chi-square distribution of the degrees of freedom
+func chiSquarePValue(x float64, degrees int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return upperIncompleteGamma(float64(degrees)/2, x/2)
+}
+
+// upperIncompleteGamma The regularized upper incomplete gamma function Q(a, x), by the series for x < a+1 and by
+// the continued fraction otherwise, see Numerical Recipes 6.2
+func upperIncompleteGamma(a float64, x float64) float64 {
+	const (
+		maxIterations = 200
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+	if x < a+1 {
+		term, sum := 1/a, 1/a
+		for n := 1; n < maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h * prefix
+}
+
+// experimentWeights The traffic weights of the non-default groups of the experiment of the layer, which are the
+// number of the buckets of the groups, key is groupID
+func experimentWeights(application *cache.Application, layerKey string, experimentKey string) map[int64]float64 {
+	layer, ok := application.LayerIndex[layerKey]
+	if !ok {
+		return nil
+	}
+	weights := make(map[int64]float64)
+	for groupID, group := range layer.GroupIndex {
+		if group.IsDefault || group.ExperimentKey != experimentKey {
+			continue
+		}
+		if weight := groupWeight(application, groupID); weight > 0 {
+			weights[groupID] = weight
+		}
+	}
+	return weights
+}
+
+// groupWeight The number of the buckets of the group, zero if unknown
+func groupWeight(application *cache.Application, groupID int64) float64 {
+	return float64(bucketCount(application.GroupIDBucketInfoIndex[groupID],
+		application.GroupIDRoaringBitmapIndex[groupID]))
+}
+
+// bucketCount The number of the buckets of the bucket information, the bitmap is decoded by the lazyBitmap,
+// zero if unknown
+func bucketCount(bucketInfo *protoccacheserver.BucketInfo, lazyBitmap *cache.LazyBitmap) int64 {
+	if bucketInfo == nil {
+		return 0
+	}
+	switch bucketInfo.BucketType {
+	case protoccacheserver.BucketType_BUCKET_TYPE_RANGE:
+		if bucketInfo.TrafficRange == nil || bucketInfo.TrafficRange.Right < bucketInfo.TrafficRange.Left {
+			return 0
+		}
+		return bucketInfo.TrafficRange.Right - bucketInfo.TrafficRange.Left + 1
+	case protoccacheserver.BucketType_BUCKET_TYPE_BITMAP:
+		if lazyBitmap == nil {
+			return 0
+		}
+		bitmap, err := lazyBitmap.Bitmap()
+		if err != nil {
+			return 0
+		}
+		return int64(bitmap.GetCardinality())
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+// Package abc ...
+package abc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChiSquarePValue(t *testing.T) {
+	tests := []struct {
+		x       float64
+		degrees int
+		want    float64
+	}{
+		{x: 0, degrees: 1, want: 1},
+		{x: 3.841459, degrees: 1, want: 0.05},
+		{x: 10.827566, degrees: 1, want: 0.001},
+		{x: 5.991465, degrees: 2, want: 0.05},
+		{x: 7.814728, degrees: 3, want: 0.05},
+		{x: 1, degrees: 4, want: 0.909796},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, chiSquarePValue(tt.x, tt.degrees), 1e-6, "x=%v degrees=%v", tt.x, tt.degrees)
+	}
+}
+
+func TestSRMTest(t *testing.T) {
+	weights := map[int64]float64{1: 1000, 2: 1000}
+	result, ok := srmTest(map[int64]uint64{1: 500, 2: 500}, weights)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, result.chiSquare)
+	assert.Equal(t, 1.0, result.pValue)
+	result, ok = srmTest(map[int64]uint64{1: 600, 2: 400}, weights)
+	assert.True(t, ok)
+	assert.InDelta(t, 40, result.chiSquare, 1e-9)
+	assert.True(t, result.pValue < defaultSRMThreshold)
+	assert.Equal(t, map[int64]float64{1: 500, 2: 500}, result.expected)
+
+	_, ok = srmTest(map[int64]uint64{1: 4, 2: 4}, weights) // too few
+	assert.False(t, ok)
+	_, ok = srmTest(map[int64]uint64{1: 100}, map[int64]float64{1: 1000})
+	assert.False(t, ok)
+}
+
+func TestWithSRMDetector(t *testing.T) {
+	assert.NotNil(t, WithSRMDetector(-time.Second, 0)(&internal.GlobalConfig{}))
+	assert.NotNil(t, WithSRMDetector(time.Minute, 1)(&internal.GlobalConfig{}))
+	config := &internal.GlobalConfig{}
+	assert.Nil(t, WithSRMDetector(time.Minute, 0)(config))
+	assert.Equal(t, defaultSRMThreshold, config.SRMThreshold)
+}
@@ -0,0 +1,123 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// TabConfig The configuration of a project served by the cache service, see NewConfigSnapshot
+type TabConfig = protoccacheserver.TabConfig
+
+// ConfigSnapshot The immutable configuration of a project for EvaluateWithConfig, built once and shared by the
+// concurrent evaluations, such as across the invocations of a warm serverless function
+type ConfigSnapshot struct {
+	application *cache.Application
+}
+
+// NewConfigSnapshot Build the snapshot of the projectID from the tabConfig. The snapshot has no bucket information,
+// the experiments allocated by the buckets on the platform are only hit through ParseConfigSnapshot. The tabConfig
+// is owned by the snapshot and must not be modified afterwards
+func NewConfigSnapshot(projectID string, tabConfig *TabConfig) (*ConfigSnapshot, error) {
+	return newConfigSnapshot(&client.Snapshot{ProjectID: projectID,
+		TabConfig: &protoccacheserver.GetTabConfigResp{
+			Code:             protoccacheserver.Code_CODE_SUCCESS,
+			TabConfigManager: &protoccacheserver.TabConfigManager{ProjectId: projectID, TabConfig: tabConfig},
+		}})
+}
+
+// ParseConfigSnapshot Build the snapshot from the data written by `abcctl snapshot`, including the bucket
+// information
+func ParseConfigSnapshot(data []byte) (*ConfigSnapshot, error) {
+	if IsEncrypted(data) {
+		return nil, errors.Errorf("snapshot is encrypted, see ParseEncryptedConfigSnapshot")
+	}
+	snapshot, err := client.UnmarshalSnapshot(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshalSnapshot")
+	}
+	return newConfigSnapshot(snapshot)
+}
+
+// ParseEncryptedConfigSnapshot Same as ParseConfigSnapshot, the data is encrypted under the key, such as by
+// `abcctl fetch -key-file`, see Encrypt
+func ParseEncryptedConfigSnapshot(data []byte, key []byte) (*ConfigSnapshot, error) {
+	data, err := Decrypt(key, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt")
+	}
+	return ParseConfigSnapshot(data)
+}
+
+func newConfigSnapshot(snapshot *client.Snapshot) (*ConfigSnapshot, error) {
+	application, err := cache.NewStaticApplication(snapshot)
+	if err != nil {
+		return nil, errors.Wrap(err, "newStaticApplication")
+	}
+	return &ConfigSnapshot{application: application}, nil
+}
+
+// ProjectID The projectID of the snapshot
+func (s *ConfigSnapshot) ProjectID() string {
+	return s.application.ProjectID
+}
+
+// Revision The content hash of the snapshot, the same as Revision of the SDK serving the same configuration
+func (s *ConfigSnapshot) Revision() string {
+	return s.application.Revision
+}
+
+// EvaluateWithConfig Evaluate the layer of the user against the config instead of the local cache, it needs neither
+// Init nor the cache service. Stateless, nothing is reported, including the automatic exposures, and the dmp tags are
+// not hit. Log the exposures through LogExperimentsExposure of an initialized SDK if needed.
+// The result is the same as GetExperiment of the SDK serving the same configuration otherwise
+func EvaluateWithConfig(ctx context.Context, config *ConfigSnapshot, userCtx Context, layerKey string,
+	opts ...ExperimentOption) (result *ExperimentResult, err error) {
+	if config == nil {
+		return nil, errors.Errorf("config is required")
+	}
+	c, ok := userCtx.(*userContext)
+	if !ok {
+		return nil, errors.Errorf("userCtx must be created by NewUserContext")
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	projectID := config.ProjectID()
+	defer recoverEvaluation(projectID, "EvaluateWithConfig", &err)
+	options := defaultExperimentOptions // not pooled, the options are not shared with the local cache evaluations
+	c.fillOption(&options)
+	setEvaluationDeadline(&options)
+	options.IsDisableDMP = true
+	options.StaticApplication = config.application
+	for _, opt := range opts {
+		err := opt(&options)
+		if err != nil {
+			return nil, errors.Wrap(err, "opt")
+		}
+	}
+	if options.LayerKeys == nil {
+		options.LayerKeys = make(map[string]bool, 1)
+	}
+	options.LayerKeys[layerKey] = true
+	experimentList, err := experiment.Executor.GetExperiments(ctx, projectID, &options)
+	reportRuleTimeouts(projectID, "EvaluateWithConfig", options.TimedOut)
+	if err != nil {
+		return nil, err
+	}
+	e := experimentList[layerKey]
+	if holdoutGroup := options.HoldoutLayerResult[layerKey]; holdoutGroup != nil {
+		e = holdoutGroup // the same as getExperiments
+	}
+	if e == nil {
+		return nil, nil
+	}
+	return &ExperimentResult{userCtx: c, Group: convertGroup2Experiment(e)}, nil
+}
@@ -0,0 +1,63 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateWithConfig(t *testing.T) {
+	Release()
+	defer Release()
+	recorder := client.NewRecordingClient(testdata.MockCacheClient(t))
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(recorder),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	data, err := client.MarshalSnapshot(recorder.Snapshot(projectID))
+	assert.Nil(t, err)
+	config, err := ParseConfigSnapshot(data)
+	assert.Nil(t, err)
+	revision, err := GetConfigRevision(projectID)
+	assert.Nil(t, err)
+	assert.Equal(t, revision.Revision, config.Revision())
+	assert.Equal(t, projectID, config.ProjectID())
+
+	ctx := context.Background()
+	expected := map[string]int64{}
+	for i := 0; i < 100; i++ {
+		unitID := fmt.Sprintf("unit%d", i)
+		want, err := NewUserContext(unitID).GetExperiment(ctx, projectID, "overrideLayer", WithAutomatic(false))
+		assert.Nil(t, err)
+		got, err := EvaluateWithConfig(ctx, config, NewUserContext(unitID), "overrideLayer")
+		assert.Nil(t, err)
+		assert.Equal(t, want == nil, got == nil)
+		if want != nil && got != nil {
+			assert.Equal(t, want.ID, got.ID)
+			expected[unitID] = want.ID
+		}
+	}
+	assert.NotEmpty(t, expected)
+
+	Release() // stateless, neither Init nor the local cache is needed
+	for unitID, groupID := range expected {
+		got, err := EvaluateWithConfig(ctx, config, NewUserContext(unitID), "overrideLayer")
+		assert.Nil(t, err)
+		assert.Equal(t, groupID, got.ID)
+	}
+	_, err = EvaluateWithConfig(ctx, config, NewUserContext("unit0"), "notExistLayer")
+	assert.NotNil(t, err)
+	_, err = EvaluateWithConfig(ctx, nil, NewUserContext("unit0"), "overrideLayer")
+	assert.NotNil(t, err)
+	_, err = EvaluateWithConfig(ctx, config, NewUserContext(""), "overrideLayer")
+	assert.NotNil(t, err)
+	_, err = NewConfigSnapshot(projectID, nil)
+	assert.NotNil(t, err)
+	config, err = NewConfigSnapshot(projectID, testdata.NormalTabConfig)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, config.Revision())
+}
@@ -0,0 +1,303 @@
+// Package testdata Test data Do not use
+package testdata
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/abetterchoice/go-sdk/internal/client"
+	"github.com/abetterchoice/protoc_cache_server"
+	"github.com/golang/mock/gomock"
+)
+
+// syntheticBucketSize The bucket size of the synthetic layers
+const syntheticBucketSize = 10000
+
+// SyntheticTagKey The key of the i-th targeting rule of the synthetic groups, the rule is hit by the tag value
+// SyntheticTagValue, the same for all groups
+func SyntheticTagKey(i int) string {
+	return "tag" + strconv.Itoa(i)
+}
+
+// SyntheticTagValue The tag value hitting the targeting rules of the synthetic groups
+const SyntheticTagValue = "hit"
+
+// SyntheticLayerKey The key of the i-th synthetic layer
+func SyntheticLayerKey(i int) string {
+	return "syntheticLayer" + strconv.Itoa(i)
+}
+
+// SyntheticSceneID The ID of the i-th synthetic scene
+func SyntheticSceneID(i int) int64 {
+	return int64(i + 1)
+}
+
+// SyntheticFeatureFlagKey The key of the i-th synthetic feature flag
+func SyntheticFeatureFlagKey(i int) string {
+	return "syntheticFlag" + strconv.Itoa(i)
+}
+
+// SyntheticFuzzValues The tag values the random targeting rules are drawn from, see SyntheticSpec.Seed.
+// The units of the fuzzing draw their tag values from the same list, so that the rules are hit now and then
+var SyntheticFuzzValues = []string{"", "a", "b", "a;b", "hit", "1", "2", "1;2;3", "1:10", "x:y", "1.2.3",
+	"1.0.0:2.0.0", "true", "false", "^a", "["}
+
+// syntheticFuzzOperators The operators of each tag type of the random targeting rules, including the invalid pairs
+var syntheticFuzzOperators = map[protoc_cache_server.TagType][]protoc_cache_server.Operator{
+	protoc_cache_server.TagType_TAG_TYPE_STRING: {
+		protoc_cache_server.Operator_OPERATOR_EQ, protoc_cache_server.Operator_OPERATOR_NE,
+		protoc_cache_server.Operator_OPERATOR_REGULAR, protoc_cache_server.Operator_OPERATOR_IN,
+		protoc_cache_server.Operator_OPERATOR_NOT_IN, protoc_cache_server.Operator_OPERATOR_GT,
+	},
+	protoc_cache_server.TagType_TAG_TYPE_NUMBER: {
+		protoc_cache_server.Operator_OPERATOR_EQ, protoc_cache_server.Operator_OPERATOR_LT,
+		protoc_cache_server.Operator_OPERATOR_IN, protoc_cache_server.Operator_OPERATOR_LCRO,
+		protoc_cache_server.Operator_OPERATOR_LORC, protoc_cache_server.Operator_OPERATOR_REGULAR,
+	},
+	protoc_cache_server.TagType_TAG_TYPE_SET: {
+		protoc_cache_server.Operator_OPERATOR_SUB_SET, protoc_cache_server.Operator_OPERATOR_SUPER_SET,
+		protoc_cache_server.Operator_OPERATOR_EQ,
+	},
+	protoc_cache_server.TagType_TAG_TYPE_VERSION: {
+		protoc_cache_server.Operator_OPERATOR_EQ, protoc_cache_server.Operator_OPERATOR_GT,
+		protoc_cache_server.Operator_OPERATOR_LCRO,
+	},
+	protoc_cache_server.TagType_TAG_TYPE_BOOLEAN: {protoc_cache_server.Operator_OPERATOR_EQ},
+	protoc_cache_server.TagType_TAG_TYPE_EMPTY: {
+		protoc_cache_server.Operator_OPERATOR_EMPTY, protoc_cache_server.Operator_OPERATOR_NOT_EMPTY,
+	},
+}
+
+// syntheticFuzzTagTypes The tag types of the random targeting rules, in a stable order for the seed
+var syntheticFuzzTagTypes = []protoc_cache_server.TagType{
+	protoc_cache_server.TagType_TAG_TYPE_STRING, protoc_cache_server.TagType_TAG_TYPE_NUMBER,
+	protoc_cache_server.TagType_TAG_TYPE_SET, protoc_cache_server.TagType_TAG_TYPE_VERSION,
+	protoc_cache_server.TagType_TAG_TYPE_BOOLEAN, protoc_cache_server.TagType_TAG_TYPE_EMPTY,
+}
+
+// SyntheticSpec The shape of the synthetic configuration, see GenerateSyntheticTabConfig
+type SyntheticSpec struct {
+	// The number of the single-hash layers under one multi-layer domain
+	Layers int
+	// The number of the groups of each layer, evenly sharing the traffic
+	Groups int
+	// The number of the targeting rules of each group and each feature flag condition
+	Rules int
+	// The number of the scenes, the i-th layer is bound to the scene SyntheticSceneID(i % Scenes), 0 binds none
+	Scenes int
+	// The number of the feature flags, each has a condition with the targeting rules and a default value
+	FeatureFlags int
+	// The seed of the random shape for the fuzzing, 0 for the fixed shape. The fixed rules are all hit by
+	// SyntheticTagValue, while the random ones draw the tag types, the operators and the values from
+	// SyntheticFuzzValues, and the groups split the traffic at random points. The same seed generates the same
+	// configuration
+	Seed int64
+}
+
+// SyntheticTabConfig Generate the configuration of layers single-hash layers under one multi-layer domain,
+// each layer has groups groups evenly sharing the traffic, and every group has rules targeting rules on the string tags, all hit by SyntheticTagValue.
+// The group IDs follow the rule of the test data, {{layer}}{{group}}, the returned map is the group bucket info.
+// Deprecated: Test data Do not use
+func SyntheticTabConfig(layers, groups, rules int) (*protoc_cache_server.TabConfig,
+	map[int64]*protoc_cache_server.BucketInfo) {
+	return GenerateSyntheticTabConfig(SyntheticSpec{Layers: layers, Groups: groups, Rules: rules})
+}
+
+// GenerateSyntheticTabConfig Generate the configuration of the shape, which drives the load tests with the large
+// configurations and the fuzzing of the parsing and the evaluation. The returned map is the group bucket info.
+// Deprecated: Test data Do not use
+func GenerateSyntheticTabConfig(spec SyntheticSpec) (*protoc_cache_server.TabConfig,
+	map[int64]*protoc_cache_server.BucketInfo) {
+	var r *rand.Rand
+	if spec.Seed != 0 {
+		r = rand.New(rand.NewSource(spec.Seed))
+	}
+	var layerList = make([]*protoc_cache_server.Layer, 0, spec.Layers)
+	var groupBucketInfo = make(map[int64]*protoc_cache_server.BucketInfo, spec.Layers*spec.Groups)
+	for i := 0; i < spec.Layers; i++ {
+		layerKey := SyntheticLayerKey(i)
+		layer := &protoc_cache_server.Layer{
+			Metadata: &protoc_cache_server.LayerMetadata{
+				Key:        layerKey,
+				HashType:   protoc_cache_server.HashType_HASH_TYPE_SINGLE,
+				HashMethod: protoc_cache_server.HashMethod_HASH_METHOD_BKDR,
+				HashSeed:   int64(23579 + i),
+				UnitIdType: protoc_cache_server.UnitIDType_UNIT_ID_TYPE_DEFAULT,
+				BucketSize: syntheticBucketSize,
+			},
+			GroupIndex:      make(map[int64]*protoc_cache_server.Group, spec.Groups),
+			ExperimentIndex: make(map[int64]*protoc_cache_server.Experiment, spec.Groups),
+		}
+		var sceneIDList []int64
+		if spec.Scenes > 0 {
+			sceneIDList = []int64{SyntheticSceneID(i % spec.Scenes)}
+			layer.Metadata.SceneIdList = sceneIDList
+		}
+		bounds := syntheticTrafficBounds(r, spec.Groups)
+		for j := 0; j < spec.Groups; j++ {
+			groupID := int64((i+1)*1000000 + j + 1)
+			experimentID := groupID // one experiment per group, the layer is single hash
+			layer.GroupIndex[groupID] = &protoc_cache_server.Group{
+				Id:            groupID,
+				GroupKey:      strconv.FormatInt(groupID, 10),
+				ExperimentId:  experimentID,
+				ExperimentKey: strconv.FormatInt(experimentID, 10),
+				Params:        map[string]string{layerKey: strconv.FormatInt(groupID, 10)},
+				IsControl:     j == 0,
+				LayerKey:      layerKey,
+				IssueInfo:     syntheticIssueInfo(r, spec.Rules),
+				SceneIdList:   sceneIDList,
+				UnitIdType:    protoc_cache_server.UnitIDType_UNIT_ID_TYPE_DEFAULT,
+			}
+			layer.ExperimentIndex[experimentID] = &protoc_cache_server.Experiment{
+				Id:           experimentID,
+				Key:          strconv.FormatInt(experimentID, 10),
+				GroupIdIndex: map[int64]bool{groupID: true},
+			}
+			groupBucketInfo[groupID] = &protoc_cache_server.BucketInfo{
+				BucketType: protoc_cache_server.BucketType_BUCKET_TYPE_RANGE,
+				TrafficRange: &protoc_cache_server.TrafficRange{
+					Left:  bounds[j] + 1,
+					Right: bounds[j+1],
+				},
+				ModifyType: protoc_cache_server.ModifyType_MODIFY_UPDATE,
+			}
+		}
+		layerList = append(layerList, layer)
+	}
+	domainMetadata := func(key string, domainType protoc_cache_server.DomainType) *protoc_cache_server.DomainMetadata {
+		return &protoc_cache_server.DomainMetadata{
+			Key:              key,
+			DomainType:       domainType,
+			HashMethod:       protoc_cache_server.HashMethod_HASH_METHOD_BKDR,
+			HashSeed:         5080801,
+			UnitIdType:       protoc_cache_server.UnitIDType_UNIT_ID_TYPE_DEFAULT,
+			BucketSize:       100,
+			TrafficRangeList: []*protoc_cache_server.TrafficRange{{Left: 1, Right: 100}},
+		}
+	}
+	return &protoc_cache_server.TabConfig{
+		ExperimentData: &protoc_cache_server.ExperimentData{
+			DefaultGroupId: -1,
+			GlobalDomain: &protoc_cache_server.Domain{
+				Metadata: domainMetadata("globalDomain", protoc_cache_server.DomainType_DOMAIN_TYPE_DOMAIN),
+				MultiLayerDomainList: []*protoc_cache_server.MultiLayerDomain{{
+					Metadata:  domainMetadata("multiDomain", protoc_cache_server.DomainType_DOMAIN_TYPE_MULTILAYER),
+					LayerList: layerList,
+				}},
+			},
+		},
+		ConfigData:  syntheticConfigData(r, spec),
+		ControlData: &protoc_cache_server.ControlData{RefreshInterval: 3},
+	}, groupBucketInfo
+}
+
+// syntheticTrafficBounds The bounds of the traffic ranges of the groups, the j-th group takes (bounds[j], bounds[j+1]]
+func syntheticTrafficBounds(r *rand.Rand, groups int) []int64 {
+	bounds := make([]int64, groups+1)
+	for j := range bounds {
+		bounds[j] = int64(j * syntheticBucketSize / groups)
+	}
+	if r == nil || groups < 2 {
+		return bounds
+	}
+	cuts := r.Perm(syntheticBucketSize - 1)[:groups-1] // distinct, so that every group has traffic
+	sort.Ints(cuts)
+	for j, cut := range cuts {
+		bounds[j+1] = int64(cut + 1)
+	}
+	return bounds
+}
+
+func syntheticConfigData(r *rand.Rand, spec SyntheticSpec) *protoc_cache_server.RemoteConfigData {
+	configData := &protoc_cache_server.RemoteConfigData{
+		RemoteConfigIndex: make(map[string]*protoc_cache_server.RemoteConfig, spec.FeatureFlags),
+	}
+	for i := 0; i < spec.FeatureFlags; i++ {
+		key := SyntheticFeatureFlagKey(i)
+		var sceneIDList []int64
+		if spec.Scenes > 0 {
+			sceneIDList = []int64{SyntheticSceneID(i % spec.Scenes)}
+		}
+		configData.RemoteConfigIndex[key] = &protoc_cache_server.RemoteConfig{
+			Key:          key,
+			DefaultValue: []byte("false"),
+			Version:      "1",
+			SceneIdList:  sceneIDList,
+			Type:         protoc_cache_server.RemoteConfigValueType_REMOTE_CONFIG_VALUE_TYPE_BOOL,
+			ConditionList: []*protoc_cache_server.Condition{{
+				Id:         int64(i + 1),
+				Key:        key + "Condition",
+				Value:      []byte("true"),
+				HashMethod: protoc_cache_server.HashMethod_HASH_METHOD_BKDR,
+				HashSeed:   int64(13579 + i),
+				BucketSize: 100,
+				BucketInfo: &protoc_cache_server.BucketInfo{
+					BucketType:   protoc_cache_server.BucketType_BUCKET_TYPE_RANGE,
+					TrafficRange: &protoc_cache_server.TrafficRange{Left: 1, Right: 100},
+				},
+				UnitIdType: protoc_cache_server.UnitIDType_UNIT_ID_TYPE_DEFAULT,
+				IssueInfo:  syntheticIssueInfo(r, spec.Rules),
+				ConfigKey:  key,
+			}},
+		}
+	}
+	return configData
+}
+
+func syntheticIssueInfo(r *rand.Rand, rules int) *protoc_cache_server.IssueInfo {
+	if rules == 0 {
+		return &protoc_cache_server.IssueInfo{IssueType: protoc_cache_server.IssueType_ISSUE_TYPE_PERCENTAGE}
+	}
+	var tagList = make([]*protoc_cache_server.Tag, 0, rules)
+	for i := 0; i < rules; i++ {
+		tag := &protoc_cache_server.Tag{
+			Key:        SyntheticTagKey(i),
+			TagType:    protoc_cache_server.TagType_TAG_TYPE_STRING,
+			Operator:   protoc_cache_server.Operator_OPERATOR_EQ,
+			Value:      SyntheticTagValue,
+			UnitIdType: protoc_cache_server.UnitIDType_UNIT_ID_TYPE_DEFAULT,
+		}
+		if r != nil {
+			tag.TagType = syntheticFuzzTagTypes[r.Intn(len(syntheticFuzzTagTypes))]
+			operators := syntheticFuzzOperators[tag.TagType]
+			tag.Operator = operators[r.Intn(len(operators))]
+			tag.Value = SyntheticFuzzValues[r.Intn(len(SyntheticFuzzValues))]
+		}
+		tagList = append(tagList, tag)
+	}
+	return &protoc_cache_server.IssueInfo{
+		IssueType:    protoc_cache_server.IssueType_ISSUE_TYPE_TAG,
+		TagListGroup: []*protoc_cache_server.TagList{{TagList: tagList}},
+	}
+}
+
+// MockSyntheticCacheClient The cache client serving the synthetic configuration, see SyntheticTabConfig
+// Deprecated: Test data Do not use
+func MockSyntheticCacheClient(t gomock.TestReporter, layers, groups, rules int) *client.MockClient {
+	tabConfig, groupBucketInfo := SyntheticTabConfig(layers, groups, rules)
+	return MockCacheClientWithData(t, tabConfig, map[int64]*protoc_cache_server.BucketInfo{}, groupBucketInfo)
+}
+
+// SyntheticSnapshot The snapshot of the project serving the synthetic configuration of the shape, such as the input
+// of the local evaluation and the relay in the load tests, see GenerateSyntheticTabConfig
+// Deprecated: Test data Do not use
+func SyntheticSnapshot(projectID string, spec SyntheticSpec) *client.Snapshot {
+	tabConfig, groupBucketInfo := GenerateSyntheticTabConfig(spec)
+	return &client.Snapshot{
+		ProjectID: projectID,
+		TabConfig: &protoc_cache_server.GetTabConfigResp{
+			Code: protoc_cache_server.Code_CODE_SUCCESS,
+			TabConfigManager: &protoc_cache_server.TabConfigManager{
+				ProjectId:  projectID,
+				Version:    "synthetic-" + strconv.FormatInt(spec.Seed, 10),
+				UpdateType: protoc_cache_server.UpdateType_UPDATE_TYPE_COMPLETE,
+				TabConfig:  tabConfig,
+			},
+		},
+		ExperimentBucket: &protoc_cache_server.BatchGetExperimentBucketResp{Code: protoc_cache_server.Code_CODE_SUCCESS},
+		GroupBucket: &protoc_cache_server.BatchGetGroupBucketResp{Code: protoc_cache_server.Code_CODE_SUCCESS,
+			BucketIndex: groupBucketInfo},
+	}
+}
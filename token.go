@@ -0,0 +1,66 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/pkg/errors"
+)
+
+// Token An expiring credential acquired by a TokenSource
+type Token = internal.Token
+
+// TokenSource The source of the expiring credentials of a projectID, see WithConfigTokenSource
+type TokenSource = internal.TokenSource
+
+// TokenSourceFunc Adapt the function to TokenSource
+type TokenSourceFunc func(ctx context.Context, projectID string) (*Token, error)
+
+// Token Implement TokenSource
+func (f TokenSourceFunc) Token(ctx context.Context, projectID string) (*Token, error) {
+	return f(ctx, projectID)
+}
+
+// WithConfigTokenSource acquire the secretKeys of the control-plane requests, such as fetching the configuration
+// and the dmp, from the source instead of the static WithSecretKey. The tokens are cached until they expire and
+// refreshed in the background ahead of the expiry, see WithTokenRefreshAhead. A request fails if the token is expired
+// and cannot be acquired, the failures are passed to WithOnError with ReasonTokenFailure.
+// The secretKey of SetCredentials still takes precedence
+func WithConfigTokenSource(source TokenSource) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if source == nil {
+			return errors.Errorf("source is required")
+		}
+		config.ConfigTokenSource = source
+		return nil
+	}
+}
+
+// WithEventTokenSource acquire the tokens carried in the reporting metadata from the source instead of the token of
+// the remote configuration, independently of WithConfigTokenSource. The reporting falls back to the token of the
+// remote configuration if the token cannot be acquired. The eventToken of SetCredentials still takes precedence
+func WithEventTokenSource(source TokenSource) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if source == nil {
+			return errors.Errorf("source is required")
+		}
+		config.EventTokenSource = source
+		return nil
+	}
+}
+
+// WithTokenRefreshAhead set how long before the expiry the tokens of the token sources are refreshed in the
+// background, the current token is used meanwhile. 1 minute by default
+func WithTokenRefreshAhead(ahead time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if ahead <= 0 {
+			return errors.Errorf("ahead must be positive")
+		}
+		config.TokenRefreshAhead = ahead
+		return nil
+	}
+}
@@ -0,0 +1,261 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/internal/experiment"
+	"github.com/abetterchoice/go-sdk/internal/random"
+	"github.com/abetterchoice/go-sdk/plugin/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultTraceFileMaxSize The default size of a trace file before it is rotated
+	defaultTraceFileMaxSize = 100 << 20
+	// defaultTraceFileMaxBackups The default number of the rotated trace files kept
+	defaultTraceFileMaxBackups = 5
+	// traceSamplingPrecision The precision of the sampling rate
+	traceSamplingPrecision = 1 << 30
+)
+
+// WithTraceSampling write the full traces of a sampled fraction of the evaluations of GetExperiments to w, one
+// SampledTrace in JSON per line, such as a TraceFile, for the offline debugging of why a unit got a group at a
+// given time. The traces contain the attributes of the user context, the steps of the evaluation and the result.
+// The traces are written in the calling goroutine, the writes are serialized and the failures are logged.
+// The lines are encrypted under WithEncryptionKey, see DecryptTraceLine.
+// The rate is in (0, 1], zero disables it, default disabled
+func WithTraceSampling(rate float64, w io.Writer) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if rate < 0 || rate > 1 {
+			return errors.Errorf("invalid rate:%v", rate)
+		}
+		if rate > 0 && w == nil {
+			return errors.Errorf("writer is required")
+		}
+		config.TraceSamplingRate = rate
+		config.TraceWriter = w
+		return nil
+	}
+}
+
+// SampledTrace An evaluation written by WithTraceSampling
+type SampledTrace struct {
+	Time       time.Time           `json:"time"`
+	ProjectID  string              `json:"projectId"`
+	UnitID     string              `json:"unitId"`
+	DecisionID string              `json:"decisionId,omitempty"`
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// The version and the revision of the configuration used by the evaluation
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	// The groups hit, key is layerKey
+	Results   map[string]*SampledGroup `json:"results"`
+	Steps     []*TraceStep             `json:"steps"`
+	LatencyUs int64                    `json:"latencyUs"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// SampledGroup The group hit by the sampled evaluation
+type SampledGroup struct {
+	ExperimentKey string `json:"experimentKey"`
+	GroupKey      string `json:"groupKey"`
+	GroupID       int64  `json:"groupId"`
+	IsDefault     bool   `json:"isDefault"`
+}
+
+// traceWriteLock Serialize the writes of the sampled traces, the writer may not be safe for concurrent use
+var traceWriteLock sync.Mutex
+
+// isTraceSampled Whether the full trace of the evaluation is sampled
+func isTraceSampled() bool {
+	rate := internal.C.TraceSamplingRate
+	if rate <= 0 || internal.C.TraceWriter == nil {
+		return false
+	}
+	return rate >= 1 || random.Int63n(traceSamplingPrecision) < int64(rate*traceSamplingPrecision)
+}
+
+// writeSampledTrace Write the trace of the sampled evaluation, the options are read before they are recycled
+func writeSampledTrace(projectID string, options *experiment.Options, result *ExperimentList,
+	latency time.Duration, err error) {
+	trace := &SampledTrace{
+		Time:       time.Now(),
+		ProjectID:  projectID,
+		UnitID:     options.UnitID,
+		DecisionID: options.DecisionID,
+		Attributes: options.AttributeTag,
+		Results:    map[string]*SampledGroup{},
+		LatencyUs:  latency.Microseconds(),
+	}
+	application := options.Application
+	if application == nil {
+		application = cache.GetApplication(projectID)
+	}
+	if application != nil {
+		trace.Version, trace.Revision = application.Version, application.Revision
+	}
+	if options.Trace != nil {
+		trace.Steps = options.Trace.Steps
+	}
+	if result != nil {
+		for layerKey, group := range result.Data {
+			if group == nil {
+				continue
+			}
+			trace.Results[layerKey] = &SampledGroup{ExperimentKey: group.ExperimentKey, GroupKey: group.Key,
+				GroupID: group.ID, IsDefault: group.IsDefault}
+		}
+	}
+	if err != nil {
+		trace.Error = err.Error()
+	}
+	line, marshalErr := json.Marshal(trace)
+	if marshalErr != nil {
+		log.LimitedErrorf("writeSampledTrace", "marshal sampled trace fail:%v", marshalErr)
+		return
+	}
+	if c := internal.C.Cipher; c != nil { // one encrypted record per line, see DecryptTraceLine
+		if line, marshalErr = encryptLine(c, line); marshalErr != nil {
+			log.LimitedErrorf("writeSampledTrace", "encrypt sampled trace fail:%v", marshalErr)
+			return
+		}
+	}
+	line = append(line, '\n')
+	traceWriteLock.Lock()
+	defer traceWriteLock.Unlock()
+	if w := internal.C.TraceWriter; w != nil {
+		if _, writeErr := w.Write(line); writeErr != nil {
+			internal.RecordError("TraceSampling", writeErr)
+			log.LimitedErrorf("writeSampledTrace", "write sampled trace fail:%v", writeErr)
+		}
+	}
+}
+
+// TraceFileOption NewTraceFile related options
+type TraceFileOption func(f *TraceFile)
+
+// WithTraceFileMaxSize The size of the trace file in bytes before it is rotated, 100MB by default
+func WithTraceFileMaxSize(size int64) TraceFileOption {
+	return func(f *TraceFile) {
+		f.maxSize = size
+	}
+}
+
+// WithTraceFileMaxBackups The number of the rotated trace files kept, the oldest ones are removed, 5 by default
+func WithTraceFileMaxBackups(count int) TraceFileOption {
+	return func(f *TraceFile) {
+		f.maxBackups = count
+	}
+}
+
+// TraceFile A local file rotated by size for WithTraceSampling. When a write would exceed the maximum size, the
+// file is renamed to path.1, the previous path.1 to path.2 and so on, and a new file is created at path.
+// Safe for concurrent use
+type TraceFile struct {
+	lock       sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewTraceFile Open the trace file at path for appending, created if not exists
+func NewTraceFile(path string, opts ...TraceFileOption) (*TraceFile, error) {
+	if len(path) == 0 {
+		return nil, errors.Errorf("path is required")
+	}
+	f := &TraceFile{path: path, maxSize: defaultTraceFileMaxSize, maxBackups: defaultTraceFileMaxBackups}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.maxSize <= 0 || f.maxBackups < 0 {
+		return nil, errors.Errorf("invalid max size %d or max backups %d", f.maxSize, f.maxBackups)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write Append p to the file, rotating it first if p would exceed the maximum size. A single write larger than the
+// maximum size is written to a file of its own
+func (f *TraceFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file == nil {
+		return 0, errors.Errorf("trace file %s is closed", f.path)
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Wrap(err, "write")
+}
+
+// Close Close the file, the writes after it fail
+func (f *TraceFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return errors.Wrap(err, "close")
+}
+
+func (f *TraceFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "open")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "stat")
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate Shift the backups, the one beyond maxBackups is overwritten, then reopen the file at path
+func (f *TraceFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return errors.Wrap(err, "close")
+	}
+	f.file = nil
+	if f.maxBackups == 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "remove")
+		}
+		return f.open()
+	}
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(f.path, i), backupPath(f.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "rename")
+		}
+	}
+	if err := os.Rename(f.path, backupPath(f.path, 1)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "rename")
+	}
+	return f.open()
+}
+
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
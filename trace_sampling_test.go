@@ -0,0 +1,79 @@
+// Package abc ...
+package abc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceSampling(t *testing.T) {
+	Release()
+	defer Release()
+	buffer := &bytes.Buffer{}
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithTraceSampling(1.5, buffer)))
+	assert.NotNil(t, Init(context.Background(), projectIDList, WithTraceSampling(0.5, nil)))
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithTraceSampling(1, buffer))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345", WithTags(map[string][]string{"city": {"sz"}}))
+	list, err := userCtx.GetExperiments(context.Background(), projectID, WithAutomatic(false))
+	assert.Nil(t, err)
+	scanner := bufio.NewScanner(buffer)
+	assert.True(t, scanner.Scan())
+	trace := &SampledTrace{}
+	assert.Nil(t, json.Unmarshal(scanner.Bytes(), trace))
+	assert.False(t, scanner.Scan()) // one line per evaluation
+	assert.Equal(t, projectID, trace.ProjectID)
+	assert.Equal(t, "12345", trace.UnitID)
+	assert.Equal(t, []string{"sz"}, trace.Attributes["city"])
+	assert.NotEmpty(t, trace.Steps)
+	assert.Equal(t, len(list.Data), len(trace.Results))
+	for layerKey, group := range list.Data {
+		assert.Equal(t, group.Key, trace.Results[layerKey].GroupKey)
+		assert.Equal(t, group.ID, trace.Results[layerKey].GroupID)
+	}
+}
+
+func TestTraceFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trace")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "trace.jsonl")
+	_, err = NewTraceFile(path, WithTraceFileMaxSize(0))
+	assert.NotNil(t, err)
+	f, err := NewTraceFile(path, WithTraceFileMaxSize(10), WithTraceFileMaxBackups(2))
+	assert.Nil(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		n, err := f.Write([]byte(line))
+		assert.Nil(t, err)
+		assert.Equal(t, len(line), n)
+	}
+	assert.Nil(t, f.Close())
+	_, err = f.Write([]byte("closed\n"))
+	assert.NotNil(t, err)
+	for path, content := range map[string]string{path: "fourth\n", path + ".1": "third\n", path + ".2": "second\n"} {
+		data, err := ioutil.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, content, string(data))
+	}
+	_, err = os.Stat(path + ".3") // the oldest is removed
+	assert.True(t, os.IsNotExist(err))
+
+	f, err = NewTraceFile(path, WithTraceFileMaxSize(10)) // appended after a restart
+	assert.Nil(t, err)
+	_, err = f.Write([]byte("a\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "fourth\na\n", string(data))
+}
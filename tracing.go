@@ -0,0 +1,22 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"github.com/abetterchoice/go-sdk/internal"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracerProvider enable OpenTelemetry tracing, spans are created for GetExperiment, GetRemoteConfig,
+// local cache refresh and exposure reporting, with attributes such as projectID, layerKey, groupID and whether the
+// configuration of the projectID is loaded
+func WithTracerProvider(provider trace.TracerProvider) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.TracerProvider = provider
+		return nil
+	}
+}
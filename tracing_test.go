@@ -0,0 +1,52 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal/tracing"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	defer Release()
+	recorder := tracetest.NewSpanRecorder()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	result, err := userCtx.GetExperiment(context.Background(), projectID, "multiLayer2", WithAutomatic(false))
+	assert.Nil(t, err)
+	_, err = userCtx.GetExperiment(context.Background(), "notExist", "multiLayer2", WithAutomatic(false))
+	assert.NotNil(t, err)
+	var spans []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "abc.GetExperiment" { // the refresh goroutine may also create spans
+			spans = append(spans, span)
+		}
+	}
+	if !assert.Equal(t, 2, len(spans)) {
+		return
+	}
+	attrs := make(map[string]interface{})
+	for _, attr := range spans[0].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	assert.Equal(t, projectID, attrs[string(tracing.KeyProjectID)])
+	assert.Equal(t, "multiLayer2", attrs[string(tracing.KeyLayerKey)])
+	assert.Equal(t, result.ID, attrs[string(tracing.KeyGroupID)])
+	assert.Equal(t, true, attrs[string(tracing.KeyConfigLoaded)])
+	assert.Equal(t, "Error", spans[1].Status().Code.String())
+
+	Release()
+	_, span := tracing.Start(context.Background(), "disabled")
+	assert.False(t, span.IsRecording())
+}
@@ -0,0 +1,63 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/pkg/errors"
+)
+
+// UnitIDTypeInfo A custom unitID type registered by WithUnitIDType
+type UnitIDTypeInfo = internal.UnitIDTypeInfo
+
+// unitIDTypeNamePattern The names of the custom unitID types, such as device_id or household
+var unitIDTypeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// WithUnitIDType register the custom unitID type configured on the platform for the products of several identities,
+// such as 3 for the device and 4 for the household. The exposures of the layers of the type report the name, such as
+// device_id, instead of the decimal value, so that they are self-documenting. The layers of the type hash the
+// decisionID prepended by the salt, empty means no salt. Changing the salt reshuffles the assignments of the type.
+// The built-in types 0 to 2 can not be registered, the names are lowercase letters, digits and underscores
+func WithUnitIDType(unitIDType int32, name string, salt string) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if _, ok := protoccacheserver.UnitIDType_name[unitIDType]; ok || unitIDType < 0 {
+			return errors.Errorf("unitIDType %d is reserved", unitIDType)
+		}
+		if !unitIDTypeNamePattern.MatchString(name) {
+			return errors.Errorf("invalid unitIDType name [%s]", name)
+		}
+		for _, info := range config.UnitIDTypes {
+			if info.Name == name && info.Type != unitIDType {
+				return errors.Errorf("unitIDType name [%s] is registered by %d", name, info.Type)
+			}
+		}
+		if config.UnitIDTypes == nil {
+			config.UnitIDTypes = make(map[int32]*internal.UnitIDTypeInfo)
+		}
+		config.UnitIDTypes[unitIDType] = &internal.UnitIDTypeInfo{Type: unitIDType, Name: name, Salt: salt}
+		return nil
+	}
+}
+
+// UnitIDTypeName Get the name of the unitID type, the registered name of the custom types, such as device_id,
+// or the lowercase name of the built-in types, such as unit_id_type_default, see WithUnitIDType
+func UnitIDTypeName(unitIDType protoccacheserver.UnitIDType) string {
+	if info, ok := internal.LookupUnitIDType(int32(unitIDType)); ok {
+		return info.Name
+	}
+	return strings.ToLower(unitIDType.String())
+}
+
+// remoteConfigUnitIDType The format of the unitID type in the remote config exposures, the name of the registered
+// custom types and the enumeration name otherwise
+func remoteConfigUnitIDType(unitIDType protoccacheserver.UnitIDType) string {
+	if info, ok := internal.LookupUnitIDType(int32(unitIDType)); ok {
+		return info.Name
+	}
+	return unitIDType.String()
+}
@@ -0,0 +1,33 @@
+// Package abc ...
+package abc
+
+import (
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	protoccacheserver "github.com/abetterchoice/protoc_cache_server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithUnitIDType(t *testing.T) {
+	defer Release()
+	config := &internal.GlobalConfig{}
+	assert.NotNil(t, WithUnitIDType(1, "device_id", "")(config)) // built-in
+	assert.NotNil(t, WithUnitIDType(-1, "device_id", "")(config))
+	assert.NotNil(t, WithUnitIDType(3, "Device ID", "")(config))
+	assert.Nil(t, WithUnitIDType(3, "device_id", "d:")(config))
+	assert.NotNil(t, WithUnitIDType(4, "device_id", "")(config)) // duplicated name
+	assert.Nil(t, WithUnitIDType(4, "household", "")(config))
+
+	internal.C = config
+	assert.Equal(t, "device_id", UnitIDTypeName(3))
+	assert.Equal(t, "unit_id_type_new_id", UnitIDTypeName(protoccacheserver.UnitIDType_UNIT_ID_TYPE_NEW_ID))
+	assert.Equal(t, "5", UnitIDTypeName(5))
+	assert.Equal(t, "device_id", remoteConfigUnitIDType(3))
+	assert.Equal(t, "UNIT_ID_TYPE_DEFAULT", remoteConfigUnitIDType(protoccacheserver.UnitIDType_UNIT_ID_TYPE_DEFAULT))
+	assert.Equal(t, "household", internal.UnitIDTypeString(4))
+	assert.Equal(t, "1", internal.UnitIDTypeString(1))
+	assert.Equal(t, "d:u1", internal.SaltedHashSource(3, "u1"))
+	assert.Equal(t, "u1", internal.SaltedHashSource(4, "u1"))
+	assert.Equal(t, "u1", internal.SaltedHashSource(1, "u1"))
+}
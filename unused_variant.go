@@ -0,0 +1,164 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/pkg/errors"
+)
+
+// UnusedVariant A group with traffic never returned by the evaluations of the process over the window, although
+// its layer is evaluated, such as the group of broken targeting or a misconfigured traffic split
+type UnusedVariant struct {
+	LayerKey      string `json:"layerKey"`
+	ExperimentKey string `json:"experimentKey"`
+	GroupID       int64  `json:"groupId"`
+	GroupKey      string `json:"groupKey"`
+	// The time when the group was last returned, zero if never
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// WithUnusedVariantReport enable the unused variant detection. The groups returned by the evaluations of the process
+// are tracked, and every window the non-default groups with traffic which are not returned over the window while
+// their layers are, are reported as a monitor event named unused_variant, see GetUnusedVariants.
+// Zero disables the detection, default disabled
+func WithUnusedVariantReport(window time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		if window < 0 {
+			return errors.Errorf("invalid unused variant window %v", window)
+		}
+		config.UnusedVariantWindow = window
+		return nil
+	}
+}
+
+// variantUsage The time when the layers and the groups of a projectID were last returned
+type variantUsage struct {
+	start  int64    // unix nano of the first evaluation tracked
+	layers sync.Map // key is layerKey, value is *int64 of unix nano
+	groups sync.Map // key is groupID, value is *int64 of unix nano
+
+	lock sync.Mutex
+	// The time since when the groups are known by the detection, the groups created after the first check are
+	// known since the check finding them, key is groupID
+	knownSince map[int64]time.Time
+}
+
+// variantIndex key is projectID, value is *variantUsage
+var variantIndex sync.Map
+
+// resetVariants Clear the tracked groups, called by Release
+func resetVariants() {
+	variantIndex.Range(func(key, value interface{}) bool {
+		variantIndex.Delete(key)
+		return true
+	})
+}
+
+// recordVariants Track the layers and the groups returned by the evaluation, the override lists are not drawn by
+// the traffic and only mark the layers
+func recordVariants(projectID string, list *ExperimentList) {
+	if internal.C.UnusedVariantWindow <= 0 || list == nil || len(list.Data) == 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	value, ok := variantIndex.Load(projectID)
+	if !ok {
+		value, _ = variantIndex.LoadOrStore(projectID, &variantUsage{start: now, knownSince: map[int64]time.Time{}})
+	}
+	usage := value.(*variantUsage)
+	for layerKey, group := range list.Data {
+		if group == nil {
+			continue
+		}
+		storeLastSeen(&usage.layers, layerKey, now)
+		if !group.IsDefault && !group.IsOverrideList && group.ID > 0 {
+			storeLastSeen(&usage.groups, group.ID, now)
+		}
+	}
+}
+
+func storeLastSeen(index *sync.Map, key interface{}, now int64) {
+	value, ok := index.Load(key)
+	if !ok {
+		seen := now
+		if value, ok = index.LoadOrStore(key, &seen); !ok {
+			return
+		}
+	}
+	atomic.StoreInt64(value.(*int64), now)
+}
+
+func loadLastSeen(index *sync.Map, key interface{}) int64 {
+	value, ok := index.Load(key)
+	if !ok {
+		return 0
+	}
+	return atomic.LoadInt64(value.(*int64))
+}
+
+// GetUnusedVariants Get the groups of the projectID with traffic not returned by the evaluations of the process over
+// the window while their layers are, in the order of the group ID, see WithUnusedVariantReport. A group is only
+// reported after it has been known for a window, so that the groups created recently are not reported
+func GetUnusedVariants(projectID string) ([]*UnusedVariant, error) {
+	window := internal.C.UnusedVariantWindow
+	if window <= 0 {
+		return nil, errors.Errorf("unused variant detection is disabled")
+	}
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	value, ok := variantIndex.Load(projectID)
+	if !ok {
+		return nil, nil // not evaluated yet
+	}
+	return value.(*variantUsage).unused(application, window, time.Now()), nil
+}
+
+func (u *variantUsage) unused(application *cache.Application, window time.Duration, now time.Time) []*UnusedVariant {
+	since := now.Add(-window).UnixNano()
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	isFirstCheck := len(u.knownSince) == 0
+	var result []*UnusedVariant
+	for layerKey, layer := range application.LayerIndex {
+		for groupID, group := range layer.GroupIndex {
+			if group.IsDefault || groupWeight(application, groupID) <= 0 {
+				continue
+			}
+			knownSince, ok := u.knownSince[groupID]
+			if !ok {
+				knownSince = now
+				if isFirstCheck {
+					knownSince = time.Unix(0, u.start)
+				}
+				u.knownSince[groupID] = knownSince
+			}
+			if now.Sub(knownSince) < window || loadLastSeen(&u.layers, layerKey) < since {
+				continue // new, or the layer is not evaluated by the process
+			}
+			lastSeen := loadLastSeen(&u.groups, groupID)
+			if lastSeen >= since {
+				continue
+			}
+			variant := &UnusedVariant{LayerKey: layerKey, ExperimentKey: group.ExperimentKey, GroupID: groupID,
+				GroupKey: group.GroupKey}
+			if lastSeen > 0 {
+				variant.LastSeen = time.Unix(0, lastSeen)
+			}
+			result = append(result, variant)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GroupID < result[j].GroupID
+	})
+	return result
+}
@@ -0,0 +1,52 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUnusedVariants(t *testing.T) {
+	Release()
+	defer Release()
+	_, err := GetUnusedVariants(projectID)
+	assert.NotNil(t, err) // disabled
+	err = Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient), WithUnusedVariantReport(time.Hour))
+	assert.Nil(t, err)
+	variants, err := GetUnusedVariants(projectID)
+	assert.Nil(t, err)
+	assert.Empty(t, variants) // not evaluated yet
+
+	recordVariants(projectID, &ExperimentList{Data: map[string]*Group{
+		"overrideLayer": {ID: 100002001, ExperimentKey: "100002", LayerKey: "overrideLayer"},
+		"multiLayer2":   {ID: 101002001, ExperimentKey: "101002", LayerKey: "multiLayer2", IsOverrideList: true},
+	}})
+	variants, err = GetUnusedVariants(projectID)
+	assert.Nil(t, err)
+	assert.Empty(t, variants) // known for less than a window
+
+	value, _ := variantIndex.Load(projectID)
+	usage := value.(*variantUsage)
+	usage.knownSince = map[int64]time.Time{}
+	usage.start = time.Now().Add(-2 * time.Hour).UnixNano()
+	variants = usage.unused(cache.GetApplication(projectID), time.Hour, time.Now())
+	groupIDs := make([]int64, 0, len(variants))
+	for _, variant := range variants {
+		groupIDs = append(groupIDs, variant.GroupID)
+	}
+	// the other groups of the evaluated layers, the override list does not count as returned
+	assert.Equal(t, []int64{100002002, 100003001, 100003002, 101002001, 101002002, 101003001, 101003002}, groupIDs)
+	assert.Equal(t, "overrideLayer", variants[0].LayerKey)
+	assert.True(t, variants[0].LastSeen.IsZero())
+
+	resetVariants()
+	variants, err = GetUnusedVariants(projectID)
+	assert.Nil(t, err)
+	assert.Empty(t, variants)
+}
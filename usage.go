@@ -0,0 +1,76 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+)
+
+// KeyUsage The reads of a remote config or feature flag key by the process
+type KeyUsage = internal.KeyUsage
+
+// UsageReport The usage of the remote configs and feature flags of a projectID by the process since Init,
+// to find the dead flags to clean up
+type UsageReport struct {
+	ProjectID string `json:"projectId"`
+	// The revision of the configuration the keys are compared with, see GetConfigRevision
+	Revision string `json:"revision"`
+	// The keys read by the process and found in the configuration, in the order of the key
+	Read []*KeyUsage `json:"read"`
+	// The keys of the configuration never read by the process, which are dead in the process
+	Unread []string `json:"unread"`
+	// The keys read by the process but missing in the configuration, such as the flags deleted on the platform
+	// whose reads are left in the code
+	Unknown []*KeyUsage `json:"unknown"`
+}
+
+// WithUsageReportInterval set the interval of the flag usage report event. Each replica periodically reports the
+// number of the read, unread and unknown keys of each projectID as a monitor event named flag_usage, with the unread
+// and unknown keys, see GetUsageReport. Zero disables the report event, default disabled
+func WithUsageReportInterval(interval time.Duration) InitOption {
+	return func(config *internal.GlobalConfig) error {
+		config.UsageReportInterval = interval
+		return nil
+	}
+}
+
+// GetUsageReport Get the usage of the remote configs and feature flags of the projectID by the process since Init.
+// The usage of a single process only covers the code paths it runs, merge the reports of all services before
+// removing a flag
+func GetUsageReport(projectID string) (*UsageReport, error) {
+	application := cache.GetApplication(projectID)
+	if application == nil {
+		return nil, internal.UnknownProjectError(projectID)
+	}
+	usage := internal.Usage(projectID)
+	report := &UsageReport{ProjectID: projectID, Revision: application.Revision}
+	if application.TabConfig != nil && application.TabConfig.ConfigData != nil {
+		for key := range application.TabConfig.ConfigData.RemoteConfigIndex {
+			keyUsage, ok := usage[key]
+			if !ok {
+				report.Unread = append(report.Unread, key)
+				continue
+			}
+			report.Read = append(report.Read, keyUsage)
+			delete(usage, key)
+		}
+	}
+	for _, keyUsage := range usage {
+		report.Unknown = append(report.Unknown, keyUsage)
+	}
+	sort.Strings(report.Unread)
+	sortKeyUsage(report.Read)
+	sortKeyUsage(report.Unknown)
+	return report, nil
+}
+
+func sortKeyUsage(usage []*KeyUsage) {
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Key < usage[j].Key
+	})
+}
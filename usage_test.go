@@ -0,0 +1,38 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUsageReport(t *testing.T) {
+	Release()
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	userCtx := NewUserContext("12345")
+	for i := 0; i < 2; i++ {
+		_, err = userCtx.GetFeatureFlag(context.Background(), projectID, "remoteConfig1")
+		assert.Nil(t, err)
+	}
+	_, err = userCtx.GetRemoteConfig(context.Background(), projectID, "deletedFlag")
+	assert.NotNil(t, err)
+
+	report, err := GetUsageReport(projectID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(report.Read))
+	assert.Equal(t, "remoteConfig1", report.Read[0].Key)
+	assert.Equal(t, uint64(2), report.Read[0].Count)
+	assert.Equal(t, 1, len(report.Unknown))
+	assert.Equal(t, "deletedFlag", report.Unknown[0].Key)
+	assert.Contains(t, report.Unread, "bitmapTest")
+	assert.NotContains(t, report.Unread, "remoteConfig1")
+
+	_, err = GetUsageReport("notExist")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,168 @@
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultValidateUnitID The unitID used by the sample evaluation if not specified
+	defaultValidateUnitID = "abc_validate_setup"
+	// validateExposureKey The test exposure carries this key in the extended field,
+	// the downstream can filter the test data through it
+	validateExposureKey = "abc_validate"
+)
+
+// const Step names of the validation report
+const (
+	SetupStepFetchConfig = "fetchConfig"
+	SetupStepEvaluate    = "evaluate"
+	SetupStepExposure    = "exposure"
+	SetupStepVerify      = "verify"
+)
+
+// SetupReport The structured result of ValidateSetup, Passed is true only if all projectID steps pass
+type SetupReport struct {
+	Passed   bool                           `json:"passed"`
+	Projects map[string]*ProjectSetupReport `json:"projects"`
+}
+
+// ProjectSetupReport The validation result of a single projectID
+type ProjectSetupReport struct {
+	ProjectID string `json:"projectId"`
+	// The cached configuration version
+	Version string `json:"version"`
+	// Number of experiment layers and remote configurations in the fetched configuration
+	LayerCount        int          `json:"layerCount"`
+	RemoteConfigCount int          `json:"remoteConfigCount"`
+	Steps             []*SetupStep `json:"steps"`
+}
+
+// SetupStep The result of a validation step. If the step is skipped, Passed is true and Skipped is true
+type SetupStep struct {
+	Name    string        `json:"name"`
+	Passed  bool          `json:"passed"`
+	Skipped bool          `json:"skipped"`
+	Latency time.Duration `json:"latency"`
+	Message string        `json:"message"`
+}
+
+// ValidateOption ValidateSetup related options
+type ValidateOption func(c *validateConfig)
+
+type validateConfig struct {
+	initOptions  []InitOption
+	unitID       string
+	sandboxTable *exposureTable
+}
+
+// exposureTable The table the test exposure is logged to
+type exposureTable struct {
+	name string
+	id   string
+}
+
+// WithValidateInitOptions The InitOption used to initialize the SDK during validation,
+// usually the same as the options used by the service
+func WithValidateInitOptions(opts ...InitOption) ValidateOption {
+	return func(c *validateConfig) {
+		c.initOptions = append(c.initOptions, opts...)
+	}
+}
+
+// WithValidateUnitID The unitID used by the sample evaluation
+func WithValidateUnitID(unitID string) ValidateOption {
+	return func(c *validateConfig) {
+		c.unitID = unitID
+	}
+}
+
+// WithSandboxTable Send the test exposure to the sandbox table through the plugin of
+// the default experiment metrics config. If not set, the exposure step is skipped
+// to avoid polluting the production table.
+func WithSandboxTable(tableName, tableID string) ValidateOption {
+	return func(c *validateConfig) {
+		c.sandboxTable = &exposureTable{name: tableName, id: tableID}
+	}
+}
+
+// ValidateSetup is a dry run of the SDK setup, for use in CI/staging before promoting a release.
+// It initializes the SDK with the given options, fetches the configuration of each projectID,
+// runs a sample evaluation without exposure, and logs a test exposure to the sandbox table.
+// The SDK is released before returning, so it must not be called after Init in the same process.
+// err is only returned when the validation can not be carried out, step failures are recorded in the report.
+func ValidateSetup(ctx context.Context, projectIDList []string, opts ...ValidateOption) (*SetupReport, error) {
+	if len(projectIDList) == 0 {
+		return nil, errors.Errorf("projectIDList is required")
+	}
+	if len(internal.C.ProjectIDList) != 0 {
+		return nil, errors.Errorf("sdk has been initialized, ValidateSetup must run in a separate process")
+	}
+	c := &validateConfig{unitID: defaultValidateUnitID}
+	for _, opt := range opts {
+		opt(c)
+	}
+	report := &SetupReport{Passed: true, Projects: make(map[string]*ProjectSetupReport, len(projectIDList))}
+	for _, projectID := range projectIDList {
+		report.Projects[projectID] = &ProjectSetupReport{ProjectID: projectID}
+	}
+	defer Release()
+	start := time.Now()
+	initErr := Init(ctx, projectIDList, c.initOptions...)
+	for _, projectID := range projectIDList {
+		projectReport := report.Projects[projectID]
+		if !validateFetchConfig(projectReport, time.Since(start), initErr) {
+			report.Passed = false
+			continue
+		}
+		list, ok := validateEvaluate(ctx, projectReport, c.unitID)
+		if !ok {
+			report.Passed = false
+			continue
+		}
+		if !validateExposure(ctx, projectReport, list, c.sandboxTable) {
+			report.Passed = false
+		}
+	}
+	return report, nil
+}
+
+func validateFetchConfig(report *ProjectSetupReport, latency time.Duration, initErr error) bool {
+	step := &SetupStep{Name: SetupStepFetchConfig, Latency: latency}
+	report.Steps = append(report.Steps, step)
+	application := cache.GetApplication(report.ProjectID)
+	if application == nil {
+		step.Message = fmt.Sprintf("configuration not loaded:%v", initErr)
+		return false
+	}
+	report.Version = application.Version
+	report.LayerCount = len(application.LayerIndex)
+	report.RemoteConfigCount = len(application.TabConfig.ConfigData.RemoteConfigIndex)
+	step.Passed = true
+	return true
+}
+
+func validateEvaluate(ctx context.Context, report *ProjectSetupReport, unitID string) (*ExperimentList, bool) {
+	step := &SetupStep{Name: SetupStepEvaluate}
+	report.Steps = append(report.Steps, step)
+	start := time.Now()
+	list, err := NewUserContext(unitID).GetExperiments(ctx, report.ProjectID, WithAutomatic(false),
+		WithIsDisableDMP(true))
+	step.Latency = time.Since(start)
+	if err != nil {
+		step.Message = env.ErrMsg(err)
+		return nil, false
+	}
+	step.Passed = true
+	step.Message = fmt.Sprintf("%d layers hit", len(list.Data))
+	return list, true
+}
@@ -0,0 +1,98 @@
+//go:build !abc_lite && !abc_wasm
+// +build !abc_lite,!abc_wasm
+
+// Package abc provides a set of APIs for external use, including APIs for ABC system initialization.
+// It also encompasses functionalities such as traffic distribution for A/B experiments,
+// user configuration data retrieval, user feature flag management, exposure data reporting, and logger registration.
+package abc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abetterchoice/go-sdk/env"
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/internal/cache"
+	"github.com/abetterchoice/go-sdk/plugin/metrics"
+	"github.com/abetterchoice/protoc_event_server"
+)
+
+func validateExposure(ctx context.Context, report *ProjectSetupReport, list *ExperimentList,
+	sandboxTable *exposureTable) bool {
+	step := &SetupStep{Name: SetupStepExposure}
+	report.Steps = append(report.Steps, step)
+	if sandboxTable == nil {
+		step.Passed, step.Skipped, step.Message = true, true, "sandbox table not set"
+		return true
+	}
+	application := cache.GetApplication(report.ProjectID)
+	metricsConfig := application.TabConfig.ControlData.DefaultExperimentMetricsConfig
+	if metricsConfig == nil || metricsConfig.Metadata == nil {
+		step.Message = "default experiment metrics config not found"
+		return false
+	}
+	if _, ok := metrics.GetClient(metricsConfig.PluginName); !ok {
+		step.Message = fmt.Sprintf("metrics plugin [%s] not registered", metricsConfig.PluginName)
+		return false
+	}
+	group := &protoc_event_server.ExposureGroup{}
+	uploadTime := time.Now().Unix()
+	extraData := extraDataFromUserCtx(list.userCtx)
+	if extraData == nil {
+		extraData = make(map[string]string, 1)
+	}
+	extraData[validateExposureKey] = "1"
+	for _, e := range list.Data {
+		group.Exposures = append(group.Exposures, convertExperimentV2(report.ProjectID, e, list.userCtx,
+			protoc_event_server.ExposureType_EXPOSURE_TYPE_MANUAL, uploadTime, extraData,
+			cache.GetExposureTemplate(application, e.ID)))
+	}
+	if len(group.Exposures) == 0 {
+		step.Passed, step.Skipped, step.Message = true, true, "no layer hit"
+		return true
+	}
+	metadata := metrics.Metadata{MetricsPluginName: metricsConfig.PluginName, TableName: sandboxTable.name,
+		TableID: sandboxTable.id, Token: internal.EventToken(report.ProjectID, metricsConfig.Metadata.Token),
+		SamplingInterval: 1}
+	start := time.Now()
+	err := metrics.LogExposure(ctx, &metadata, group)
+	step.Latency = time.Since(start)
+	if err != nil {
+		step.Message = env.ErrMsg(err)
+		return false
+	}
+	step.Passed = true
+	step.Message = fmt.Sprintf("%d exposures accepted", len(group.Exposures))
+	return true
+}
+
+// verifyExposure Verify the test exposure of the exposure step is accepted, by flushing the plugin if it implements
+// metrics.Flusher
+func verifyExposure(ctx context.Context, report *ProjectSetupReport) bool {
+	step := &SetupStep{Name: SetupStepVerify}
+	report.Steps = append(report.Steps, step)
+	if exposureStep := report.Steps[len(report.Steps)-2]; exposureStep.Skipped {
+		step.Passed, step.Skipped, step.Message = true, true, "no test exposure"
+		return true
+	}
+	metricsConfig := cache.GetApplication(report.ProjectID).TabConfig.ControlData.DefaultExperimentMetricsConfig
+	client, _ := metrics.GetClient(metricsConfig.PluginName) // checked by the exposure step
+	flusher, ok := client.(metrics.Flusher)
+	if !ok {
+		step.Passed, step.Skipped = true, true
+		step.Message = fmt.Sprintf("metrics plugin [%s] does not implement metrics.Flusher, accepted by LogExposure",
+			metricsConfig.PluginName)
+		return true
+	}
+	start := time.Now()
+	err := flusher.Flush(ctx)
+	step.Latency = time.Since(start)
+	if err != nil {
+		step.Message = env.ErrMsg(err)
+		return false
+	}
+	step.Passed = true
+	step.Message = "flushed"
+	return true
+}
@@ -0,0 +1,85 @@
+// Package abc ...
+package abc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abetterchoice/go-sdk/internal"
+	"github.com/abetterchoice/go-sdk/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSetup(t *testing.T) {
+	Release()
+	ctx := context.Background()
+	type args struct {
+		projectIDList []string
+		opts          []ValidateOption
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantErr    bool
+		wantPassed bool
+		wantSteps  []string
+	}{
+		{
+			name:    "projectIDList is required",
+			args:    args{},
+			wantErr: true,
+		},
+		{
+			name: "pass",
+			args: args{
+				projectIDList: projectIDList,
+				opts: []ValidateOption{WithValidateUnitID("12345"), WithValidateInitOptions(
+					WithRegisterCacheClient(testdata.MockCacheClient(t)),
+					WithRegisterDMPClient(testdata.MockEmptyDMPClient),
+					WithRegisterMetricsPlugin(testdata.EmptyMetricsClient, nil))},
+			},
+			wantPassed: true,
+			wantSteps:  []string{SetupStepFetchConfig, SetupStepEvaluate, SetupStepExposure},
+		},
+		{
+			name: "fetch config fail",
+			args: args{
+				projectIDList: projectIDList,
+				opts: []ValidateOption{WithValidateInitOptions(
+					WithRegisterCacheClient(testdata.MockFakeCacheClient(t)),
+					WithRegisterDMPClient(testdata.MockEmptyDMPClient))},
+			},
+			wantPassed: false,
+			wantSteps:  []string{SetupStepFetchConfig},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateSetup(ctx, tt.args.projectIDList, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSetup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, 0, len(internal.C.ProjectIDList)) // released
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tt.wantPassed, got.Passed)
+			var steps []string
+			for _, step := range got.Projects[projectID].Steps {
+				steps = append(steps, step.Name)
+			}
+			assert.Equal(t, tt.wantSteps, steps)
+		})
+	}
+}
+
+func TestValidateSetupInitialized(t *testing.T) {
+	defer Release()
+	err := Init(context.Background(), projectIDList, WithRegisterCacheClient(testdata.MockCacheClient(t)),
+		WithRegisterDMPClient(testdata.MockEmptyDMPClient))
+	assert.Nil(t, err)
+	_, err = ValidateSetup(context.Background(), projectIDList)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(internal.C.ProjectIDList)) // not released by the failed validation
+}
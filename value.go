@@ -100,6 +100,13 @@ type Value struct {
 	data []byte
 }
 
+// NewValue Create a parameter value outside of the evaluation, such as in the test doubles, the data is copied
+func NewValue(data []byte) *Value {
+	v := &Value{data: make([]byte, len(data))}
+	copy(v.data, data)
+	return v
+}
+
 // Bytes Get specific configuration data. The original data is a snapshot of the local cache. To avoid tampering, a new copy of the data is copied here each time.
 func (v *Value) Bytes() []byte {
 	var result = make([]byte, len(v.data))